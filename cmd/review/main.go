@@ -0,0 +1,190 @@
+// Command review runs the review pipeline (diff extraction, context collection and review)
+// once, without the HTTP server, and prints the result. It reviews a local diff against a
+// working tree, for pre-push reviews and prompt tuning, or a pull request by URL. It reads the
+// same config file (CONFIG_PATH) and secrets as the server; nothing is posted or stored.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"path/filepath"
+
+	"pr-review-automation/internal/client"
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/pipeline"
+	"pr-review-automation/internal/processor"
+)
+
+const usage = `Usage:
+  review -diff FILE [-dir DIR] [flags]   Review a unified diff ("-" reads stdin)
+  review -pr URL [flags]                 Review a pull request
+
+Flags:
+`
+
+type options struct {
+	diff        string
+	dir         string
+	prURL       string
+	title       string
+	description string
+	model       string
+	direct      bool
+	format      string
+	verbose     bool
+}
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "review:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, stdin io.Reader, out io.Writer) error {
+	var opts options
+	fs := flag.NewFlagSet("review", flag.ContinueOnError)
+	fs.Usage = func() {
+		fmt.Fprint(fs.Output(), usage)
+		fs.PrintDefaults()
+	}
+	fs.StringVar(&opts.diff, "diff", "", "Unified diff file to review, e.g. the output of git diff main...")
+	fs.StringVar(&opts.dir, "dir", ".", "Working tree the diff applies to; changed files are read from it as context")
+	fs.StringVar(&opts.prURL, "pr", "", "Pull request URL (Bitbucket Server, Bitbucket Cloud, GitHub, GitLab or Gitea)")
+	fs.StringVar(&opts.title, "title", "", "Title of the change, shown to the model (-diff only)")
+	fs.StringVar(&opts.description, "description", "", "Description of the change, shown to the model (-diff only)")
+	fs.StringVar(&opts.model, "model", "", "LLM model instead of llm.model")
+	fs.BoolVar(&opts.direct, "direct", false, "Review all changes in one call, without degradation or chunking")
+	fs.StringVar(&opts.format, "format", "markdown", "Output format: markdown or json")
+	fs.BoolVar(&opts.verbose, "v", false, "Log pipeline progress to stderr")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if (opts.diff == "") == (opts.prURL == "") {
+		fs.Usage()
+		return errors.New("exactly one of -diff and -pr is required")
+	}
+	if opts.format != "markdown" && opts.format != "json" {
+		return fmt.Errorf("unknown format %q", opts.format)
+	}
+
+	level := slog.LevelWarn
+	if opts.verbose {
+		level = slog.LevelInfo
+	}
+	slog.SetDefault(slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{Level: level})))
+
+	cfg := config.LoadConfig()
+	cfg.LocalDiff = opts.diff != ""
+	if err := cfg.Validate(); err != nil {
+		return fmt.Errorf("configuration: %w", err)
+	}
+
+	mcpClient := client.NewMCPClient(cfg)
+	defer mcpClient.Close()
+
+	var pr *domain.PullRequest
+	if opts.diff != "" {
+		diff, err := readDiff(opts.diff, stdin)
+		if err != nil {
+			return err
+		}
+		pr = localPullRequest(opts)
+		mcpClient.SetProviderBackend(domain.ProviderLocal, client.NewLocalBackend(diff, opts.dir))
+	} else {
+		var err error
+		if pr, err = parsePRURL(opts.prURL); err != nil {
+			return err
+		}
+		for provider, backend := range client.ProviderBackends(cfg) {
+			mcpClient.SetProviderBackend(provider, backend)
+		}
+		if pr.Provider == "" {
+			if err := mcpClient.InitializeConnections(); err != nil {
+				return fmt.Errorf("connect to mcp servers: %w", err)
+			}
+		}
+	}
+	if opts.model != "" || opts.direct {
+		pr.Overrides = &domain.ReviewOverrides{Model: opts.model, Direct: opts.direct}
+	}
+	ctx = domain.WithProvider(ctx, pr.Provider)
+	if pr.Provider != domain.ProviderLocal {
+		processor.ResolvePullRequest(ctx, mcpClient, pr)
+	}
+
+	llm, err := client.NewLLM(cfg)
+	if err != nil {
+		return fmt.Errorf("create llm: %w", err)
+	}
+	promptLoader := pipeline.NewPromptLoader(cfg.Prompts.Dir)
+	promptLoader.SetRawSchemaProvider(mcpClient)
+	reviewer := pipeline.NewPipelineAdapter(cfg, mcpClient, llm, promptLoader)
+	for _, route := range cfg.LLM.Routes {
+		routeLLM, err := client.NewTargetLLM(cfg, route.LLMTarget)
+		if err != nil {
+			return fmt.Errorf("create llm route %s: %w", route.Model, err)
+		}
+		reviewer.AddModelRoute(route, routeLLM)
+	}
+
+	result, err := reviewer.ReviewPR(ctx, &domain.ReviewRequest{PR: pr})
+	if err != nil {
+		return err
+	}
+	if opts.format == "json" {
+		enc := json.NewEncoder(out)
+		enc.SetIndent("", "  ")
+		return enc.Encode(result)
+	}
+	_, err = io.WriteString(out, renderMarkdown(pr, result))
+	return err
+}
+
+// readDiff reads the diff file, or stdin for "-"
+func readDiff(path string, stdin io.Reader) (string, error) {
+	var data []byte
+	var err error
+	if path == "-" {
+		data, err = io.ReadAll(stdin)
+	} else {
+		data, err = os.ReadFile(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("read diff: %w", err)
+	}
+	if len(data) == 0 {
+		return "", errors.New("the diff is empty")
+	}
+	return string(data), nil
+}
+
+// localPullRequest describes a local diff as a pull request of the working tree's repository
+func localPullRequest(opts options) *domain.PullRequest {
+	repo := "local"
+	if abs, err := filepath.Abs(opts.dir); err == nil && filepath.Base(abs) != string(filepath.Separator) {
+		repo = filepath.Base(abs)
+	}
+	title := opts.title
+	if title == "" {
+		title = "Local changes"
+	}
+	return &domain.PullRequest{
+		ID:          "0",
+		ProjectKey:  domain.ProviderLocal,
+		RepoSlug:    repo,
+		Title:       title,
+		Description: opts.description,
+		Provider:    domain.ProviderLocal,
+	}
+}
@@ -0,0 +1,115 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/domain"
+)
+
+func TestParsePRURL(t *testing.T) {
+	tests := []struct {
+		url                         string
+		provider, project, repo, id string
+	}{
+		{"https://bitbucket.example.com/projects/PAY/repos/api/pull-requests/42/overview", "", "PAY", "api", "42"},
+		{"https://bitbucket.org/acme/api/pull-requests/9", domain.ProviderBitbucketCloud, "acme", "api", "9"},
+		{"https://github.com/acme/api/pull/7/files", domain.ProviderGitHub, "acme", "api", "7"},
+		{"https://gitlab.com/acme/platform/api/-/merge_requests/5", domain.ProviderGitLab, "acme/platform", "api", "5"},
+		{"https://git.example.com/acme/api/pulls/3", domain.ProviderGitea, "acme", "api", "3"},
+	}
+	for _, tt := range tests {
+		pr, err := parsePRURL(tt.url)
+		if err != nil {
+			t.Errorf("%s: %v", tt.url, err)
+			continue
+		}
+		if pr.Provider != tt.provider || pr.ProjectKey != tt.project || pr.RepoSlug != tt.repo || pr.ID != tt.id || pr.WebURL != tt.url {
+			t.Errorf("%s: got %+v", tt.url, pr)
+		}
+	}
+
+	for _, bad := range []string{"not a url", "https://github.com/acme/api", "https://github.com/acme/api/pull/abc"} {
+		if _, err := parsePRURL(bad); err == nil {
+			t.Errorf("%s: expected an error", bad)
+		}
+	}
+}
+
+func TestRun_LocalDiff(t *testing.T) {
+	var prompt string
+	llm := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		prompt += string(body)
+		review := `{"comments":[{"path":"main.go","line":3,"severity":"WARNING","message":"Handle the error"}],"score":85,"summary":"One issue."}`
+		content, _ := json.Marshal(review)
+		w.Header().Set("Content-Type", "application/json")
+		fmt.Fprintf(w, `{"id":"1","object":"chat.completion","model":"test-model","choices":[{"index":0,"finish_reason":"stop","message":{"role":"assistant","content":%s}}],"usage":{"prompt_tokens":10,"completion_tokens":5,"total_tokens":15}}`, content)
+	}))
+	defer llm.Close()
+
+	dir := t.TempDir()
+	prompts, _ := filepath.Abs("../../prompts")
+	configPath := filepath.Join(dir, "config.yaml")
+	if err := os.WriteFile(configPath, []byte("llm:\n  endpoint: "+llm.URL+"\n  model: test-model\nprompts:\n  dir: "+prompts+"\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("CONFIG_PATH", configPath)
+	t.Setenv("LLM_API_KEY", "test")
+
+	tree := filepath.Join(dir, "tree")
+	if err := os.MkdirAll(tree, 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(tree, "main.go"), []byte("package main\n\nfunc run() { os.Remove(\"x\") }\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	diff := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -1,2 +1,3 @@\n package main\n \n+func run() { os.Remove(\"x\") }\n"
+
+	var out bytes.Buffer
+	err := run(context.Background(), []string{"-diff", "-", "-dir", tree, "-title", "Add run", "-format", "json"}, strings.NewReader(diff), &out)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var result domain.ReviewResult
+	if err := json.Unmarshal(out.Bytes(), &result); err != nil {
+		t.Fatalf("output is not a review result: %v\n%s", err, out.String())
+	}
+	if result.Score != 85 || len(result.Comments) != 1 || result.Comments[0].File != "main.go" {
+		t.Errorf("unexpected result %+v", result)
+	}
+	if !strings.Contains(prompt, "os.Remove") {
+		t.Error("the diff did not reach the model")
+	}
+
+	out.Reset()
+	if err := run(context.Background(), []string{"-diff", "-", "-dir", tree, "-title", "Add run"}, strings.NewReader(diff), &out); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"# Review: Add run", "**Score:** 85/100", "### main.go", "- **WARNING** (line 3): Handle the error"} {
+		if !strings.Contains(out.String(), want) {
+			t.Errorf("markdown missing %q:\n%s", want, out.String())
+		}
+	}
+}
+
+func TestRun_Usage(t *testing.T) {
+	for _, args := range [][]string{
+		{},
+		{"-diff", "a.diff", "-pr", "https://github.com/acme/api/pull/1"},
+		{"-diff", "a.diff", "-format", "html"},
+	} {
+		if err := run(context.Background(), args, strings.NewReader(""), io.Discard); err == nil {
+			t.Errorf("run %v succeeded", args)
+		}
+	}
+}
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"fmt"
+	"strings"
+
+	"pr-review-automation/internal/domain"
+)
+
+// renderMarkdown formats a review result for reading in a terminal or pasting into notes
+func renderMarkdown(pr *domain.PullRequest, result *domain.ReviewResult) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Review: %s\n\n", pr.Title)
+	fmt.Fprintf(&b, "**Score:** %d/100 · **Findings:** %d · **Model:** %s", result.Score, len(result.Comments), result.Model)
+	if u := result.Usage; u != nil {
+		fmt.Fprintf(&b, " · **Tokens:** %d", u.PromptTokens+u.CompletionTokens)
+	}
+	b.WriteString("\n\n")
+	if summary := strings.TrimSpace(result.Summary); summary != "" {
+		b.WriteString(summary + "\n\n")
+	}
+
+	if len(result.Comments) == 0 {
+		return b.String()
+	}
+	b.WriteString("## Findings\n")
+	file := ""
+	for _, c := range result.Comments {
+		if c.File != file {
+			file = c.File
+			fmt.Fprintf(&b, "\n### %s\n\n", file)
+		}
+		location := "file"
+		if c.Line > 0 {
+			location = fmt.Sprintf("line %d", int(c.Line))
+			if c.EndLine > int(c.Line) {
+				location = fmt.Sprintf("lines %d-%d", int(c.Line), c.EndLine)
+			}
+		}
+		severity := c.Severity
+		if severity == "" {
+			severity = domain.CommentSeverityInfo
+		}
+		fmt.Fprintf(&b, "- **%s** (%s): %s\n", severity, location, strings.TrimSpace(c.Comment))
+		if c.Suggestion != "" {
+			fmt.Fprintf(&b, "\n  ```suggestion\n%s\n  ```\n", indent(strings.TrimRight(c.Suggestion, "\n"), "  "))
+		}
+	}
+	return b.String()
+}
+
+func indent(s, prefix string) string {
+	return prefix + strings.ReplaceAll(s, "\n", "\n"+prefix)
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"pr-review-automation/internal/domain"
+)
+
+// parsePRURL identifies a pull request from its web URL:
+//
+//	https://bitbucket.example.com/projects/PROJ/repos/repo/pull-requests/42   Bitbucket Server
+//	https://bitbucket.org/workspace/repo/pull-requests/42                     Bitbucket Cloud
+//	https://github.com/owner/repo/pull/42                                     GitHub
+//	https://gitlab.com/group/subgroup/project/-/merge_requests/42             GitLab
+//	https://gitea.example.com/owner/repo/pulls/42                             Gitea, Forgejo
+func parsePRURL(raw string) (*domain.PullRequest, error) {
+	u, err := url.Parse(raw)
+	if err != nil || u.Host == "" {
+		return nil, fmt.Errorf("invalid pull request url %q", raw)
+	}
+	parts := strings.Split(strings.Trim(u.Path, "/"), "/")
+	pr := &domain.PullRequest{WebURL: raw}
+
+	for i, part := range parts {
+		if i+1 >= len(parts) {
+			break
+		}
+		id := parts[i+1]
+		switch {
+		case part == "pull-requests" && i >= 4 && parts[i-4] == "projects" && parts[i-2] == "repos":
+			pr.ProjectKey, pr.RepoSlug = parts[i-3], parts[i-1]
+		case part == "pull-requests" && i == 2:
+			pr.Provider, pr.ProjectKey, pr.RepoSlug = domain.ProviderBitbucketCloud, parts[0], parts[1]
+		case part == "pull" && i == 2:
+			pr.Provider, pr.ProjectKey, pr.RepoSlug = domain.ProviderGitHub, parts[0], parts[1]
+		case part == "merge_requests" && i >= 3 && parts[i-1] == "-":
+			pr.Provider, pr.ProjectKey, pr.RepoSlug = domain.ProviderGitLab, strings.Join(parts[:i-2], "/"), parts[i-2]
+		case part == "pulls" && i == 2:
+			pr.Provider, pr.ProjectKey, pr.RepoSlug = domain.ProviderGitea, parts[0], parts[1]
+		default:
+			continue
+		}
+		if _, err := strconv.Atoi(id); err != nil {
+			return nil, fmt.Errorf("invalid pull request id %q in %s", id, raw)
+		}
+		pr.ID = id
+		return pr, nil
+	}
+	return nil, fmt.Errorf("unrecognized pull request url %q", raw)
+}
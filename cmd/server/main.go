@@ -8,23 +8,42 @@ import (
 	"net/http"
 	"os"
 	"os/signal"
+	"slices"
 	"strings"
 	"syscall"
 	"time"
 
 	"gopkg.in/natefinch/lumberjack.v2"
 
+	"pr-review-automation/internal/api"
+	"pr-review-automation/internal/budget"
 	"pr-review-automation/internal/client"
 	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/event"
+	"pr-review-automation/internal/fault"
 	"pr-review-automation/internal/filter/bitbucket"
+	"pr-review-automation/internal/filter/gitlab"
+	"pr-review-automation/internal/linter"
+	"pr-review-automation/internal/llm"
 	"pr-review-automation/internal/pipeline"
+	"pr-review-automation/internal/poller"
 	"pr-review-automation/internal/processor"
+	"pr-review-automation/internal/progress"
+	"pr-review-automation/internal/queue"
+	"pr-review-automation/internal/retrieval"
+	"pr-review-automation/internal/rules"
+	"pr-review-automation/internal/scope"
 	"pr-review-automation/internal/storage"
+	"pr-review-automation/internal/tokenizer"
 	"pr-review-automation/internal/webhook"
 
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
+// cancelTimeout is how long shutdown waits for cancelled reviews to unwind
+const cancelTimeout = 10 * time.Second
+
 func main() {
 
 	// Load configuration first
@@ -41,24 +60,35 @@ func main() {
 	defer logCleanup()
 	slog.SetDefault(logger)
 
+	// Simulated dependency failures for resilience testing in staging
+	faults := fault.New(cfg.FaultInjection)
+	if faults != nil {
+		slog.Warn("fault injection enabled, never use in production", "faults", len(cfg.FaultInjection.Faults), "header", faults.Header())
+	}
+
 	// Initialize clients
 	mcpClient := client.NewMCPClient(cfg)
 
 	// Create LLM once at startup
-	llm, err := client.NewLLM(cfg)
+	llmClient, err := client.NewLLM(cfg)
 	if err != nil {
 		slog.Error("create llm failed", "error", err)
 		os.Exit(1)
 	}
 
 	// Verify LLM connection
-	if checker, ok := llm.(interface{ Ping(context.Context) error }); ok {
+	if checker, ok := llmClient.(interface{ Ping(context.Context) error }); ok {
 		if err := checker.Ping(context.Background()); err != nil {
 			slog.Error("llm health check failed", "error", err)
 			os.Exit(1)
 		}
 	}
 
+	if cfg.IsLocalLLM() || slices.ContainsFunc(cfg.LLM.Fallbacks, func(t config.LLMTarget) bool { return t.Provider == config.LLMProviderLocal }) {
+		probeJSONFormat(cfg, llmClient, cfg.LLM.Model)
+	}
+	gateCapabilities(cfg, llmClient)
+
 	// Initialize Filters
 	bbPayloadFilter := bitbucket.NewPayloadFilter()
 	bbResponseFilter := bitbucket.NewResponseFilter(cfg.Pipeline.ResponseMaxStringLen)
@@ -68,6 +98,14 @@ func main() {
 	mcpClient.SetResponseFilter("jira", bbResponseFilter)
 	mcpClient.SetResponseFilter("confluence", bbResponseFilter)
 
+	// GitHub, GitLab, Gitea and Bitbucket Cloud pull requests use their REST APIs for the SCM tool calls
+	for provider, backend := range client.ProviderBackends(cfg) {
+		mcpClient.SetProviderBackend(provider, backend)
+	}
+	// Diffs submitted through POST /api/v1/reviews/patch have no code host
+	patches := client.NewPatchBackend()
+	mcpClient.SetProviderBackend(domain.ProviderLocal, patches)
+
 	// Create a context for initialization
 	if err := mcpClient.InitializeConnections(); err != nil {
 		slog.Error("init mcp failed", "error", err)
@@ -75,31 +113,168 @@ func main() {
 	}
 	defer mcpClient.Close()
 
+	// Chunk sizes and degradation thresholds count tokens with the model's vocabulary when configured
+	for _, tc := range cfg.LLM.Tokenizers {
+		bpe, err := tokenizer.LoadBPE(tc.File)
+		if err != nil {
+			slog.Error("load tokenizer failed", "file", tc.File, "error", err)
+			os.Exit(1)
+		}
+		tokenizer.Register(tc.Models, bpe)
+		slog.Info("tokenizer loaded", "file", tc.File, "models", tc.Models)
+	}
+	tokenizer.SetDefault(tokenizer.For(cfg.LLM.Model))
+
 	// Initialize Prompt Loader (Pipeline version)
 	promptLoader := pipeline.NewPromptLoader(cfg.Prompts.Dir)
 	promptLoader.SetRawSchemaProvider(mcpClient)
 
+	// Fail fast on template, threshold and model mistakes
+	models, _ := llmClient.(pipeline.ModelLister)
+	if err := pipeline.ValidateConfig(context.Background(), cfg, promptLoader, models); err != nil {
+		slog.Error("pipeline config invalid", "error", err)
+		os.Exit(1)
+	}
+
+	// Precompile prompts and optionally warm up the model before accepting webhooks
+	warmupCtx, warmupCancel := context.WithTimeout(context.Background(), cfg.LLMTimeout())
+	err = pipeline.Warmup(warmupCtx, cfg, promptLoader, llmClient)
+	warmupCancel()
+	if err != nil {
+		slog.Error("pipeline warm-up failed", "error", err)
+		os.Exit(1)
+	}
+
 	// Initialize PR review agent using Pipeline Adapter
-	prReviewer := pipeline.NewPipelineAdapter(cfg, mcpClient, llm, promptLoader)
+	prReviewer := pipeline.NewPipelineAdapter(cfg, mcpClient, llmClient, promptLoader)
 	slog.Info("reviewer initialized", "backend", prReviewer.Name())
 
+	// Per-project models review the matching repositories instead of llm.model
+	for _, route := range cfg.LLM.Routes {
+		routeLLM, err := client.NewTargetLLM(cfg, route.LLMTarget)
+		if err != nil {
+			slog.Error("create llm route failed", "model", route.Model, "error", err)
+			os.Exit(1)
+		}
+		if route.Provider == config.LLMProviderLocal {
+			probeJSONFormat(cfg, routeLLM, route.Model)
+		}
+		prReviewer.AddModelRoute(route, routeLLM)
+		slog.Info("llm route added", "model", route.Model, "endpoint", route.Endpoint, "projects", route.Projects, "repos", route.Repos)
+	}
+
+	// Documentation snippets from the local vector store join the review context
+	retrievalCtx, retrievalCancel := context.WithCancel(context.Background())
+	defer retrievalCancel()
+	if rc := cfg.Pipeline.Retrieval; rc.Enabled {
+		docs := retrieval.New(rc, client.NewEmbedder(cfg, rc.Embedding), mcpClient)
+		if err := docs.Load(); err != nil {
+			slog.Warn("load documentation index failed", "error", err)
+		}
+		go docs.Run(retrievalCtx)
+		prReviewer.SetDocRetriever(docs)
+		slog.Info("documentation retrieval enabled", "model", rc.Embedding.Model, "sources", len(rc.Sources), "index", rc.IndexPath)
+	}
+
+	// Team standards from Confluence pages join the review prompt
+	if sc := cfg.Pipeline.Standards; sc.Enabled {
+		prReviewer.SetStandards(retrieval.NewStandards(sc, mcpClient))
+		slog.Info("team standards enabled", "projects", len(sc.Projects), "cache_ttl", sc.CacheTTL)
+	}
+
 	// Initialize storage
 	var store storage.Repository
+	storageCtx, storageCancel := context.WithCancel(context.Background())
+	defer storageCancel()
 	if cfg.Storage.Driver == "sqlite" {
-		var err error
-		store, err = storage.NewSQLiteRepository(cfg.Storage.DSN)
+		sqliteStore, err := storage.NewSQLiteRepository(cfg.Storage.DSN)
 		if err != nil {
 			slog.Error("init storage failed", "error", err)
 			os.Exit(1)
 		}
+		if cfg.Storage.EncryptionKey != "" {
+			c, err := storage.NewCipher(storage.StaticKey(cfg.Storage.EncryptionKey))
+			if err != nil {
+				slog.Error("init storage encryption failed", "error", err)
+				os.Exit(1)
+			}
+			sqliteStore.SetCipher(c)
+			slog.Info("storage encryption enabled")
+		}
+		store = sqliteStore
+		if cfg.Storage.Resilience.Enabled {
+			resilient := storage.NewResilientRepository(sqliteStore, cfg.Storage.Resilience, cfg.Storage.Timeout)
+			resilient.SetFaultInjector(faults)
+			go resilient.Run(storageCtx)
+			store = resilient
+		}
 		defer store.Close()
 	} else if cfg.Storage.Driver != "" {
 		slog.Warn("unknown storage driver", "driver", cfg.Storage.Driver)
 	}
 
+	// Adaptive chunk tuning keeps its per-repository state in storage
+	if cfg.Pipeline.Stage3Review.AdaptiveTuning.Enabled {
+		if tuningStore, ok := store.(storage.TuningRepository); ok {
+			prReviewer.SetChunkTuner(pipeline.NewChunkTuner(cfg.Pipeline.Stage3Review, tuningStore))
+			slog.Info("adaptive chunk tuning enabled")
+		} else {
+			slog.Warn("adaptive chunk tuning requires storage, disabled")
+		}
+	}
+
 	// Initialize PR processor
 	// Note: PRProcessor now uses domain types and generic Reviewer interface
 	prProcessor := processor.NewPRProcessor(cfg, prReviewer, mcpClient, store)
+	prProcessor.SetPatchRegistry(patches)
+	registerProcessorHooks(prProcessor)
+	if cfg.Pipeline.Assets.Enabled && cfg.Pipeline.Assets.Vision {
+		prProcessor.SetVisionClient(llmClient)
+	}
+	if cfg.Pipeline.Description.Enabled {
+		prProcessor.SetDescriptionClient(llmClient)
+	}
+
+	// Spend budgets resume from the reviews stored this month
+	if cfg.Budgets.Enabled {
+		budgets := budget.New(cfg.Budgets)
+		if spendStore, ok := store.(storage.SpendRepository); ok {
+			loadCtx, loadCancel := context.WithTimeout(context.Background(), cfg.Storage.Timeout)
+			if err := budgets.Load(loadCtx, spendStore); err != nil {
+				slog.Warn("load review spend failed, budgets start empty", "error", err)
+			}
+			loadCancel()
+		} else {
+			slog.Warn("budgets without storage start empty on every restart")
+		}
+		prProcessor.SetBudget(budgets)
+		slog.Info("spend budgets enabled")
+	}
+
+	// Operator-defined post-processing rules run before validation and posting
+	if path := cfg.Pipeline.PostProcessing.RulesFile; path != "" {
+		engine, err := rules.Load(path)
+		if err != nil {
+			slog.Error("load post-processing rules failed", "path", path, "error", err)
+			os.Exit(1)
+		}
+		prProcessor.OnAfterReview(func(ctx context.Context, pr *domain.PullRequest, result *domain.ReviewResult) error {
+			result.Comments = engine.Apply(pr, result.Comments)
+			return nil
+		})
+		slog.Info("post-processing rules loaded", "path", path, "rules", engine.Len())
+	}
+
+	// Review events decouple notifiers/exporters from the posting logic
+	eventBus := event.NewBus(event.DefaultBufferSize)
+	registerEventSubscribers(eventBus)
+	prProcessor.SetEventPublisher(eventBus)
+
+	// Static analyzer findings are merged with the model's
+	if lc := cfg.Pipeline.Linters; lc.Enabled {
+		prProcessor.SetAnalyzer(linter.New(lc))
+		slog.Info("linters enabled", "tools", len(lc.Tools))
+	}
 
 	// Initialize Payload Parser with filter
 	// Need to ensure payloadParser uses generic promptLoader or pipeline one
@@ -117,14 +292,131 @@ func main() {
 	// Or define PromptLoader in domain.
 
 	// Temporarily: use pipeline.PromptLoader and changing PayloadParser signature is best.
-	payloadParser := webhook.NewPayloadParser(cfg.Webhook, llm, promptLoader, bbPayloadFilter)
+	payloadParser := webhook.NewPayloadParser(cfg.Webhook, llmClient, promptLoader, bbPayloadFilter)
 
 	// Initialize webhook handler
 	webhookHandler := webhook.NewBitbucketWebhookHandler(cfg, prProcessor, payloadParser)
+	repoGate := scope.NewGate(cfg.Review)
+	webhookHandler.SetGate(repoGate)
+	reviewProgress := progress.NewBroker()
+	webhookHandler.SetProgress(reviewProgress)
+	webhookHandler.SetProviderResolver(prReviewer)
+	if cfg.JiraIssues.Enabled {
+		switch {
+		case store == nil:
+			slog.Warn("jira issues require storage, disabled")
+		case cfg.Review.DryRun:
+			slog.Warn("jira issues are not filed in dry-run mode, disabled")
+		default:
+			webhookHandler.SetMergeHandler(processor.NewJiraIssueFiler(cfg.JiraIssues, mcpClient, store))
+			slog.Info("jira issues for merged critical findings enabled", "mappings", len(cfg.JiraIssues.Projects))
+		}
+	}
+
+	if cfg.Commands.Enabled {
+		prProcessor.SetCommandClient(llmClient)
+		webhookHandler.SetCommandHandler(prProcessor)
+		slog.Info("slash commands in pr comments enabled", "prefix", cfg.Commands.Prefix)
+	}
+	if cfg.Conversation.Enabled {
+		webhookHandler.SetConversationHandler(processor.NewConversationHandler(cfg, mcpClient, llmClient))
+		slog.Info("conversation mode enabled", "max_turns", cfg.Conversation.MaxTurns)
+	}
+
+	// Queued reviews wait in an external queue, surviving restarts and shared by all instances
+	if cfg.Queue.Driver != config.QueueDriverMemory {
+		queueCtx, queueCancel := context.WithTimeout(context.Background(), 10*time.Second)
+		reviewQueue, err := queue.New(queueCtx, cfg.Queue)
+		queueCancel()
+		if err != nil {
+			slog.Error("connect review queue failed", "error", err)
+			os.Exit(1)
+		}
+		defer reviewQueue.Close()
+		webhookHandler.SetReviewQueue(reviewQueue)
+		slog.Info("review queue enabled", "driver", cfg.Queue.Driver, "role", cfg.Queue.Role)
+	}
+
+	// Pending reviews snapshotted during a maintenance pause survive restarts
+	if queueStore, ok := store.(storage.QueueRepository); ok {
+		webhookHandler.SetQueueStore(queueStore)
+		restoreCtx, restoreCancel := context.WithTimeout(context.Background(), cfg.Storage.Timeout)
+		if _, err := webhookHandler.ResumeIntake(restoreCtx); err != nil {
+			slog.Warn("restore queued reviews failed", "error", err)
+		}
+		restoreCancel()
+	}
+
+	// Accepted reviews are journaled until they finish, so a restart does not drop them
+	if jobStore, ok := store.(storage.JobRepository); ok {
+		webhookHandler.SetJobStore(jobStore)
+		restoreCtx, restoreCancel := context.WithTimeout(context.Background(), cfg.Storage.Timeout)
+		if _, err := webhookHandler.RestoreJobs(restoreCtx); err != nil {
+			slog.Warn("restore journaled reviews failed", "error", err)
+		}
+		restoreCancel()
+	}
+
+	// Background retention for stored data
+	retentionCtx, retentionCancel := context.WithCancel(context.Background())
+	defer retentionCancel()
+	if store != nil {
+		go storage.NewRetentionManager(store, cfg.Storage.Retention, cfg.Storage.RetentionInterval).Run(retentionCtx)
+	}
+
+	// Polling finds pull requests of repositories that cannot send webhooks
+	pollCtx, pollCancel := context.WithCancel(context.Background())
+	defer pollCancel()
+	if cfg.Polling.Enabled {
+		if cfg.Queue.Role == config.QueueRoleWorker {
+			slog.Warn("polling is not run by queue workers, disabled")
+		} else {
+			prPoller := poller.New(cfg.Polling, mcpClient, webhookHandler)
+			prPoller.SetGate(repoGate)
+			if store != nil {
+				prPoller.SetStore(store)
+			}
+			go prPoller.Run(pollCtx)
+			slog.Info("polling enabled", "repos", len(cfg.Polling.Repos), "interval", cfg.Polling.Interval)
+		}
+	}
 
 	// Setup HTTP server
 	mux := http.NewServeMux()
-	mux.Handle("/webhook", webhookHandler)
+	// Queue workers take reviews from the queue only
+	if cfg.Queue.Role != config.QueueRoleWorker {
+		mux.Handle("/webhook", webhookHandler)
+		if cfg.GitHub.Enabled {
+			mux.Handle(cfg.GitHub.WebhookPath, webhook.NewGitHubWebhookHandler(cfg, webhookHandler))
+			slog.Info("github webhook enabled", "path", cfg.GitHub.WebhookPath)
+		}
+		if cfg.GitLab.Enabled {
+			glParser := webhook.NewPayloadParser(cfg.Webhook, llmClient, promptLoader, gitlab.NewPayloadFilter())
+			mux.Handle(cfg.GitLab.WebhookPath, webhook.NewGitLabWebhookHandler(cfg, webhookHandler, glParser))
+			slog.Info("gitlab webhook enabled", "path", cfg.GitLab.WebhookPath)
+		}
+		if cfg.Gitea.Enabled {
+			mux.Handle(cfg.Gitea.WebhookPath, webhook.NewGiteaWebhookHandler(cfg, webhookHandler))
+			slog.Info("gitea webhook enabled", "path", cfg.Gitea.WebhookPath)
+		}
+		if cfg.BitbucketCloud.Enabled {
+			mux.Handle(cfg.BitbucketCloud.WebhookPath, webhook.NewBitbucketCloudWebhookHandler(cfg, webhookHandler))
+			slog.Info("bitbucket cloud webhook enabled", "path", cfg.BitbucketCloud.WebhookPath)
+		}
+	}
+
+	// Admin / result API (token auth and roles when auth.enabled)
+	apiServer := api.NewServer(cfg, store)
+	apiServer.SetToolProvider(mcpClient)
+	apiServer.SetRepoGate(repoGate)
+	apiServer.SetReplayer(prProcessor)
+	apiServer.SetComparer(prProcessor)
+	apiServer.SetPatchReviewer(prProcessor)
+	apiServer.SetReviewSubmitter(webhookHandler)
+	apiServer.SetIntakeController(webhookHandler)
+	apiServer.SetReviewCanceller(webhookHandler)
+	apiServer.SetProgress(reviewProgress)
+	apiServer.Register(mux)
 
 	// Liveness probe (Kubernetes: startup/liveness)
 	mux.HandleFunc("/health/live", func(w http.ResponseWriter, r *http.Request) {
@@ -159,7 +451,7 @@ func main() {
 	})
 
 	// Prometheus Metrics Endpoint
-	mux.Handle("/metrics", promhttp.Handler())
+	mux.Handle("/metrics", apiServer.Auth().Require(config.RoleViewer, promhttp.Handler()))
 
 	server := &http.Server{
 		Addr:         fmt.Sprintf(":%d", cfg.Server.Port),
@@ -199,6 +491,8 @@ func main() {
 	done := make(chan struct{})
 	go func() {
 		webhookHandler.WaitForCompletion()
+		// Findings held for working hours would be lost on exit
+		prProcessor.ReleaseHeld(shutdownCtx)
 		close(done)
 	}()
 
@@ -206,14 +500,79 @@ func main() {
 	case <-done:
 		slog.Info("background tasks completed")
 	case <-shutdownCtx.Done():
-		slog.Warn("task timeout, exiting", "timeout", cfg.Server.ShutdownTimeout)
+		// Running reviews stop their LLM and MCP calls and release their workers within seconds
+		slog.Warn("task timeout, cancelling running reviews", "timeout", cfg.Server.ShutdownTimeout)
+		webhookHandler.CancelInFlight()
+		select {
+		case <-done:
+			slog.Info("running reviews cancelled")
+		case <-time.After(cancelTimeout):
+			slog.Warn("reviews still running after cancellation, exiting", "timeout", cancelTimeout)
+		}
 	}
 
+	// Deliver remaining review events before storage and clients are closed
+	eventBus.Close()
+
 	// 3. defer store.Close() will handle storage cleanup (via WAL checkpoint)
 
 	slog.Info("server stopped")
 }
 
+// probeJSONFormat checks once whether a local server supports JSON mode, so reviews do not
+// fail on every request when it does not
+func probeJSONFormat(cfg *config.Config, c pipeline.LLMClient, model string) {
+	prober, ok := c.(interface{ ProbeJSONFormat(context.Context) error })
+	if !ok || !cfg.LLM.Local.ProbeJSON {
+		return
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), cfg.LLMTimeoutFor(config.LLMProviderLocal))
+	defer cancel()
+	if err := prober.ProbeJSONFormat(ctx); err != nil {
+		slog.Warn("llm json probe failed", "model", model, "error", err)
+	}
+}
+
+// registerProcessorHooks attaches deployment-specific hooks to the processor.
+// Custom logic (e.g. compliance checks) should be registered here instead of
+// modifying the processor; return processor.ErrSkip to stop quietly.
+func registerProcessorHooks(p *processor.PRProcessor) {
+	p.OnAfterReview(func(ctx context.Context, pr *domain.PullRequest, result *domain.ReviewResult) error {
+		slog.Debug("review finished", "pr_id", pr.ID, "comments", len(result.Comments), "score", result.Score)
+		return nil
+	})
+}
+
+// gateCapabilities logs what the review model supports and turns off the configured features
+// it lacks, so they degrade once at startup instead of failing every review
+func gateCapabilities(cfg *config.Config, c pipeline.LLMClient) {
+	caps := llm.CapabilitiesOf(c)
+	slog.Info("llm capabilities", "model", cfg.LLM.Model, "tools", caps.Tools, "json_schema", caps.JSONSchema,
+		"vision", caps.Vision, "streaming", caps.Streaming, "max_context", caps.MaxContext)
+	if cfg.Pipeline.Backend != config.BackendDirect && !caps.Tools {
+		slog.Warn("llm does not support tools, using the direct backend", "backend", cfg.Pipeline.Backend)
+		cfg.Pipeline.Backend = config.BackendDirect
+	}
+	if cfg.Pipeline.Assets.Enabled && cfg.Pipeline.Assets.Vision && !caps.Vision {
+		slog.Warn("llm does not support image input, pipeline.assets.vision disabled; set llm.capabilities.vision if it does")
+		cfg.Pipeline.Assets.Vision = false
+	}
+	if cfg.Pipeline.Stage3Review.StreamDebug.Enabled && !caps.Streaming {
+		slog.Warn("llm does not stream, stream debug artifacts are not written")
+	}
+}
+
+// registerEventSubscribers attaches review event consumers (notifiers, exporters, outbound webhooks)
+func registerEventSubscribers(bus *event.Bus) {
+	bus.Subscribe(event.SubscriberFunc(func(ctx context.Context, evt domain.ReviewCompletedEvent) {
+		slog.Info("review completed",
+			"pr_id", evt.PR.ID,
+			"repo", evt.PR.RepoSlug,
+			"status", evt.Status,
+			"duration", evt.Duration)
+	}))
+}
+
 // setupLogger creates a logger based on configuration
 func setupLogger(cfg *config.Config) (*slog.Logger, func()) {
 	var writers []io.Writer
@@ -0,0 +1,296 @@
+// Command storectl inspects and maintains the review storage of the configured deployment:
+// it lists and exports review records, shows per-repository stats, deletes records and
+// applies schema migrations. It reads the same config file (CONFIG_PATH) and
+// STORAGE_ENCRYPTION_KEY as the server.
+package main
+
+import (
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"strconv"
+	"text/tabwriter"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/storage"
+)
+
+const usage = `Usage: storectl [-dsn DSN] <command> [flags]
+
+Commands:
+  list     List review records, newest first
+  export   Export review records as JSON or CSV
+  stats    Show review counts per repository
+  delete   Delete a review record, or all data of a project, repository or author
+  migrate  Create or upgrade the storage schema
+
+Run "storectl <command> -h" for the flags of a command.
+`
+
+// allRecords is the SQLite LIMIT meaning no limit
+const allRecords = -1
+
+func main() {
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	if err := run(ctx, os.Args[1:], os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "storectl:", err)
+		os.Exit(1)
+	}
+}
+
+func run(ctx context.Context, args []string, out io.Writer) error {
+	global := flag.NewFlagSet("storectl", flag.ContinueOnError)
+	global.Usage = func() { fmt.Fprint(global.Output(), usage) }
+	dsn := global.String("dsn", "", "Storage DSN (default: storage.dsn from the config file)")
+	if err := global.Parse(args); err != nil {
+		return err
+	}
+	if global.NArg() == 0 {
+		global.Usage()
+		return errors.New("missing command")
+	}
+
+	cmd, cmdArgs := global.Arg(0), global.Args()[1:]
+	fs := flag.NewFlagSet(cmd, flag.ContinueOnError)
+	var handler func(ctx context.Context, store *storage.SQLiteRepository) error
+	switch cmd {
+	case "list":
+		project, repo, pr, limit := filterFlags(fs, 50)
+		handler = func(ctx context.Context, store *storage.SQLiteRepository) error {
+			records, err := listRecords(ctx, store, *project, *repo, *pr, *limit)
+			if err != nil {
+				return err
+			}
+			return writeTable(out, records)
+		}
+	case "export":
+		project, repo, pr, limit := filterFlags(fs, 0)
+		format := fs.String("format", "json", "Output format: json or csv")
+		output := fs.String("o", "", "Output file (default: stdout)")
+		handler = func(ctx context.Context, store *storage.SQLiteRepository) error {
+			if *format != "json" && *format != "csv" {
+				return fmt.Errorf("unknown format %q", *format)
+			}
+			records, err := listRecords(ctx, store, *project, *repo, *pr, *limit)
+			if err != nil {
+				return err
+			}
+			w := out
+			if *output != "" {
+				f, err := os.Create(*output)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				w = f
+			}
+			if *format == "csv" {
+				return writeCSV(w, records)
+			}
+			enc := json.NewEncoder(w)
+			enc.SetIndent("", "  ")
+			return enc.Encode(records)
+		}
+	case "stats":
+		project := fs.String("project", "", "Project key")
+		repo := fs.String("repo", "", "Repository slug (requires -project)")
+		asJSON := fs.Bool("json", false, "Print JSON instead of a table")
+		handler = func(ctx context.Context, store *storage.SQLiteRepository) error {
+			if *repo != "" && *project == "" {
+				return errors.New("-repo requires -project")
+			}
+			stats, err := store.RepoStats(ctx, storage.ReviewFilter{ProjectKey: *project, RepoSlug: *repo})
+			if err != nil {
+				return err
+			}
+			if *asJSON {
+				enc := json.NewEncoder(out)
+				enc.SetIndent("", "  ")
+				return enc.Encode(stats)
+			}
+			return writeStats(out, stats)
+		}
+	case "delete":
+		id := fs.String("id", "", "Review id")
+		var filter storage.PurgeFilter
+		fs.StringVar(&filter.ProjectKey, "project", "", "Delete all data of a project")
+		fs.StringVar(&filter.RepoSlug, "repo", "", "Delete all data of a repository (with -project)")
+		fs.StringVar(&filter.Author, "author", "", "Delete all data of a PR author")
+		reason := fs.String("reason", "", "Reason recorded in the purge audit (required)")
+		yes := fs.Bool("yes", false, "Confirm the deletion")
+		handler = func(ctx context.Context, store *storage.SQLiteRepository) error {
+			switch {
+			case *id == "" && filter.IsEmpty():
+				return errors.New("delete needs -id, -project, -repo or -author")
+			case *id != "" && !filter.IsEmpty():
+				return errors.New("-id cannot be combined with a filter")
+			case *reason == "":
+				return errors.New("-reason is required")
+			case !*yes:
+				return errors.New("deletion is permanent; add -yes to confirm")
+			}
+			requestedBy := "storectl"
+			if user := os.Getenv("USER"); user != "" {
+				requestedBy += ":" + user
+			}
+			if *id != "" {
+				if err := store.DeleteReview(ctx, *id, requestedBy, *reason); err != nil {
+					return err
+				}
+				fmt.Fprintf(out, "deleted review %s\n", *id)
+				return nil
+			}
+			n, err := store.Purge(ctx, filter, requestedBy, *reason)
+			if err != nil {
+				return err
+			}
+			fmt.Fprintf(out, "deleted %d records\n", n)
+			return nil
+		}
+	case "migrate":
+		handler = func(ctx context.Context, store *storage.SQLiteRepository) error {
+			// Opening the store applies the migrations
+			fmt.Fprintln(out, "storage schema is up to date")
+			return nil
+		}
+	default:
+		global.Usage()
+		return fmt.Errorf("unknown command %q", cmd)
+	}
+	if err := fs.Parse(cmdArgs); err != nil {
+		return err
+	}
+
+	cfg := config.LoadConfig()
+	store, err := openStore(cfg, *dsn)
+	if err != nil {
+		return err
+	}
+	defer store.Close()
+	return handler(ctx, store)
+}
+
+// filterFlags registers the record selection flags of list and export
+func filterFlags(fs *flag.FlagSet, defaultLimit int) (project, repo, pr *string, limit *int) {
+	project = fs.String("project", "", "Project key")
+	repo = fs.String("repo", "", "Repository slug (requires -project)")
+	pr = fs.String("pr", "", "Pull request id (requires -project and -repo)")
+	limit = fs.Int("limit", defaultLimit, "Maximum records, newest first (0 = all)")
+	return project, repo, pr, limit
+}
+
+// openStore opens the configured SQLite storage; dsn overrides storage.dsn
+func openStore(cfg *config.Config, dsn string) (*storage.SQLiteRepository, error) {
+	if dsn == "" {
+		switch cfg.Storage.Driver {
+		case "sqlite":
+			dsn = cfg.Storage.DSN
+		case "":
+			return nil, errors.New("storage is not configured; set storage.driver or pass -dsn")
+		default:
+			return nil, fmt.Errorf("unsupported storage driver %q", cfg.Storage.Driver)
+		}
+	}
+	store, err := storage.NewSQLiteRepository(dsn)
+	if err != nil {
+		return nil, err
+	}
+	if cfg.Storage.EncryptionKey != "" {
+		c, err := storage.NewCipher(storage.StaticKey(cfg.Storage.EncryptionKey))
+		if err != nil {
+			store.Close()
+			return nil, fmt.Errorf("init storage encryption: %w", err)
+		}
+		store.SetCipher(c)
+	}
+	return store, nil
+}
+
+// listRecords selects review records like the reviews API: a PR, a repository, a project or
+// all, newest first
+func listRecords(ctx context.Context, store *storage.SQLiteRepository, project, repo, pr string, limit int) ([]*storage.ReviewRecord, error) {
+	if limit <= 0 {
+		limit = allRecords
+	}
+	switch {
+	case repo != "" && project == "":
+		return nil, errors.New("-repo requires -project")
+	case pr != "" && (project == "" || repo == ""):
+		return nil, errors.New("-pr requires -project and -repo")
+	case pr != "":
+		records, err := store.ListReviewsByPR(ctx, project, repo, pr)
+		if limit > 0 && len(records) > limit {
+			records = records[:limit]
+		}
+		return records, err
+	case project != "":
+		return store.ListReviews(ctx, storage.ReviewFilter{ProjectKey: project, RepoSlug: repo}, limit)
+	default:
+		return store.ListRecentReviews(ctx, limit)
+	}
+}
+
+func writeTable(out io.Writer, records []*storage.ReviewRecord) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CREATED\tID\tPULL REQUEST\tSTATUS\tSCORE\tFINDINGS\tDURATION")
+	for _, r := range records {
+		score, findings := recordResult(r)
+		fmt.Fprintf(tw, "%s\t%s\t%s/%s#%s\t%s\t%s\t%s\t%s\n",
+			r.CreatedAt.Local().Format(time.DateTime), r.ID,
+			r.PullRequest.ProjectKey, r.PullRequest.RepoSlug, r.PullRequest.ID,
+			r.Status, score, findings, (time.Duration(r.DurationMs) * time.Millisecond).String())
+	}
+	return tw.Flush()
+}
+
+var csvHeader = []string{"id", "created_at", "project_key", "repo_slug", "pr_id", "title", "author", "commit", "status", "duration_ms", "score", "findings", "model"}
+
+func writeCSV(out io.Writer, records []*storage.ReviewRecord) error {
+	w := csv.NewWriter(out)
+	if err := w.Write(csvHeader); err != nil {
+		return err
+	}
+	for _, r := range records {
+		score, findings := recordResult(r)
+		model := ""
+		if r.Result != nil {
+			model = r.Result.Model
+		}
+		pr := r.PullRequest
+		if err := w.Write([]string{
+			r.ID, r.CreatedAt.UTC().Format(time.RFC3339), pr.ProjectKey, pr.RepoSlug, pr.ID, pr.Title, pr.Author, pr.LatestCommit,
+			r.Status, strconv.FormatInt(r.DurationMs, 10), score, findings, model,
+		}); err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// recordResult formats the score and finding count of a record; both are empty for failed reviews
+func recordResult(r *storage.ReviewRecord) (score, findings string) {
+	if r.Result == nil || r.Status != "success" {
+		return "", ""
+	}
+	return strconv.Itoa(r.Result.Score), strconv.Itoa(len(r.Result.Comments))
+}
+
+func writeStats(out io.Writer, stats []*storage.RepoStats) error {
+	tw := tabwriter.NewWriter(out, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "REPOSITORY\tREVIEWS\tFAILED\tPULL REQUESTS\tAVG DURATION\tLAST REVIEW\tCOST")
+	for _, s := range stats {
+		fmt.Fprintf(tw, "%s/%s\t%d\t%d\t%d\t%s\t%s\t$%.2f\n",
+			s.ProjectKey, s.RepoSlug, s.Reviews, s.Failed, s.PullRequests,
+			(time.Duration(s.AvgDurationMs) * time.Millisecond).String(), s.LastReview.Local().Format(time.DateTime), s.Cost)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/storage"
+)
+
+func TestRun(t *testing.T) {
+	t.Setenv("CONFIG_PATH", filepath.Join(t.TempDir(), "missing.yaml"))
+	dsn := filepath.Join(t.TempDir(), "reviews.db")
+	store, err := storage.NewSQLiteRepository(dsn)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, id := range []string{"r1", "r2"} {
+		if err := store.SaveReview(context.Background(), &storage.ReviewRecord{
+			ID:          id,
+			PullRequest: &domain.PullRequest{ID: "7", ProjectKey: "PAY", RepoSlug: "api", Title: "Fix, then \"ship\""},
+			Result:      &domain.ReviewResult{Score: 80 + i, Comments: []domain.ReviewComment{{File: "a.go"}}, Model: "gpt-4o"},
+			CreatedAt:   time.Now().Add(time.Duration(i) * time.Minute),
+			Status:      "success",
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	store.Close()
+
+	runOK := func(args ...string) string {
+		t.Helper()
+		var out bytes.Buffer
+		if err := run(context.Background(), append([]string{"-dsn", dsn}, args...), &out); err != nil {
+			t.Fatalf("storectl %v: %v", args, err)
+		}
+		return out.String()
+	}
+
+	if out := runOK("list", "-project", "PAY"); !strings.Contains(out, "PAY/api#7") || strings.Index(out, "r2") > strings.Index(out, "r1") {
+		t.Errorf("list output, newest first expected:\n%s", out)
+	}
+
+	rows, err := csv.NewReader(strings.NewReader(runOK("export", "-format", "csv"))).ReadAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(rows) != 3 || rows[1][0] != "r2" || rows[1][5] != "Fix, then \"ship\"" || rows[1][10] != "81" || rows[1][12] != "gpt-4o" {
+		t.Errorf("unexpected csv %q", rows)
+	}
+
+	var records []*storage.ReviewRecord
+	if err := json.Unmarshal([]byte(runOK("export", "-project", "PAY", "-repo", "api", "-pr", "7", "-limit", "1")), &records); err != nil || len(records) != 1 {
+		t.Fatalf("json export %v, %v", records, err)
+	}
+
+	if out := runOK("stats"); !strings.Contains(out, "PAY/api") {
+		t.Errorf("stats output:\n%s", out)
+	}
+
+	for _, args := range [][]string{
+		{"delete", "-id", "r1", "-reason", "test"},
+		{"delete", "-id", "r1", "-project", "PAY", "-reason", "test", "-yes"},
+		{"list", "-repo", "api"},
+		{"export", "-format", "xml"},
+		{"bogus"},
+	} {
+		if err := run(context.Background(), append([]string{"-dsn", dsn}, args...), &bytes.Buffer{}); err == nil {
+			t.Errorf("storectl %v succeeded", args)
+		}
+	}
+
+	if out := runOK("delete", "-id", "r1", "-reason", "test", "-yes"); !strings.Contains(out, "deleted review r1") {
+		t.Errorf("delete output: %s", out)
+	}
+	if out := runOK("delete", "-project", "PAY", "-reason", "test", "-yes"); !strings.Contains(out, "deleted 1 records") {
+		t.Errorf("purge output: %s", out)
+	}
+	runOK("migrate")
+}
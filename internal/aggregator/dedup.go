@@ -0,0 +1,101 @@
+package aggregator
+
+import (
+	"log/slog"
+	"slices"
+	"strings"
+	"unicode"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+const (
+	// nearDuplicateLines is how far apart two findings on the same file may be and still be
+	// the same finding reported from overlapping chunk context
+	nearDuplicateLines = 3
+	// nearDuplicateSimilarity is the word overlap (Jaccard) above which two findings say the same thing
+	nearDuplicateSimilarity = 0.6
+)
+
+// Deduplicate drops the findings that repeat an earlier one: the same fingerprint, or nearly the
+// same words on a nearby line of the same file. Chunks overlap by their context lines, so a
+// finding there is often reported by both chunks with slightly different wording or line. The
+// kept finding takes the higher severity of the two.
+func Deduplicate(comments []domain.ReviewComment) []domain.ReviewComment {
+	seen := make(map[string]int)
+	var result []domain.ReviewComment
+	var words []map[string]bool
+
+	for _, c := range comments {
+		if i, ok := seen[c.Fingerprint()]; ok {
+			result[i].Severity = higherSeverity(result[i].Severity, c.Severity)
+			continue
+		}
+		w := wordSet(c.Comment)
+		if i := nearDuplicate(result, words, c, w); i >= 0 {
+			slog.Debug("near-duplicate finding dropped", "file", c.File, "line", c.Line, "kept_line", result[i].Line)
+			result[i].Severity = higherSeverity(result[i].Severity, c.Severity)
+			continue
+		}
+		seen[c.Fingerprint()] = len(result)
+		result = append(result, c)
+		words = append(words, w)
+	}
+
+	return result
+}
+
+// nearDuplicate returns the index of a kept finding that c repeats, or -1
+func nearDuplicate(kept []domain.ReviewComment, words []map[string]bool, c domain.ReviewComment, w map[string]bool) int {
+	for i, k := range kept {
+		if k.File != c.File || abs(int(k.Line-c.Line)) > nearDuplicateLines {
+			continue
+		}
+		if jaccard(words[i], w) >= nearDuplicateSimilarity {
+			return i
+		}
+	}
+	return -1
+}
+
+// wordSet returns the distinct lowercase words of a comment
+func wordSet(s string) map[string]bool {
+	set := make(map[string]bool)
+	for _, f := range strings.FieldsFunc(strings.ToLower(s), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_'
+	}) {
+		set[f] = true
+	}
+	return set
+}
+
+// jaccard returns the share of words two sets have in common
+func jaccard(a, b map[string]bool) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	common := 0
+	for w := range a {
+		if b[w] {
+			common++
+		}
+	}
+	return float64(common) / float64(len(a)+len(b)-common)
+}
+
+// higherSeverity returns the more severe of two finding severities; unknown ones rank lowest
+func higherSeverity(a, b string) string {
+	ra, rb := slices.Index(config.FindingSeverities, strings.ToUpper(a)), slices.Index(config.FindingSeverities, strings.ToUpper(b))
+	if rb >= 0 && (ra < 0 || rb < ra) {
+		return b
+	}
+	return a
+}
+
+func abs(n int) int {
+	if n < 0 {
+		return -n
+	}
+	return n
+}
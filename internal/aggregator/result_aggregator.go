@@ -77,18 +77,7 @@ func (a *ResultAggregator) Aggregate(results []ChunkReviewResult) *domain.Review
 
 // deduplicateComments removes duplicate comments (same file + same content)
 func (a *ResultAggregator) deduplicateComments(comments []domain.ReviewComment) []domain.ReviewComment {
-	seen := make(map[string]bool)
-	var result []domain.ReviewComment
-
-	for _, c := range comments {
-		key := c.Fingerprint()
-		if !seen[key] {
-			seen[key] = true
-			result = append(result, c)
-		}
-	}
-
-	return result
+	return Deduplicate(comments)
 }
 
 // combineSummaries creates a unified summary from chunk summaries
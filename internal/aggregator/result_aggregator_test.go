@@ -64,3 +64,19 @@ func TestResultAggregator_DeduplicateComments(t *testing.T) {
 		})
 	}
 }
+
+func TestDeduplicate_NearDuplicates(t *testing.T) {
+	comments := []domain.ReviewComment{
+		{File: "main.go", Line: 40, Severity: "WARNING", Comment: "Close the response body to avoid leaking connections."},
+		{File: "main.go", Line: 42, Severity: "CRITICAL", Comment: "The response body should be closed to avoid leaking connections."},
+		{File: "main.go", Line: 60, Severity: "WARNING", Comment: "The response body should be closed to avoid leaking connections."},
+		{File: "main.go", Line: 41, Severity: "INFO", Comment: "Consider a named constant for the retry limit."},
+	}
+	got := Deduplicate(comments)
+	if len(got) != 3 {
+		t.Fatalf("Deduplicate() = %d findings, want 3: %+v", len(got), got)
+	}
+	if got[0].Line != 40 || got[0].Severity != "CRITICAL" {
+		t.Errorf("kept finding = line %d %s, want line 40 raised to CRITICAL", got[0].Line, got[0].Severity)
+	}
+}
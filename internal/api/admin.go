@@ -0,0 +1,56 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"pr-review-automation/internal/storage"
+)
+
+// PurgeRequest is the body of POST /api/v1/admin/purge
+type PurgeRequest struct {
+	storage.PurgeFilter
+	RequestedBy string `json:"requestedBy"`
+	Reason      string `json:"reason"`
+}
+
+// PurgeResponse reports the number of deleted records
+type PurgeResponse struct {
+	Deleted int64 `json:"deleted"`
+}
+
+// handlePurge deletes all stored data for a project, repository or author
+func (s *Server) handlePurge(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStore(w) {
+		return
+	}
+
+	var req PurgeRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.IsEmpty() {
+		writeError(w, http.StatusBadRequest, "at least one of projectKey, repoSlug or author is required")
+		return
+	}
+	if req.RequestedBy == "" {
+		// Authenticated callers are recorded by name unless they name the requester explicitly
+		if caller, ok := CallerFromContext(r.Context()); ok {
+			req.RequestedBy = caller.Name
+		}
+	}
+	if req.RequestedBy == "" {
+		writeError(w, http.StatusBadRequest, "requestedBy is required for the audit trail")
+		return
+	}
+
+	n, err := s.store.Purge(r.Context(), req.PurgeFilter, req.RequestedBy, req.Reason)
+	if err != nil {
+		slog.Error("purge failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "purge failed")
+		return
+	}
+	writeJSON(w, http.StatusOK, PurgeResponse{Deleted: n})
+}
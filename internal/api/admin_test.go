@@ -0,0 +1,117 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/storage"
+)
+
+// mockStore implements storage.Repository for API tests
+type mockStore struct {
+	storage.Repository
+	records     []*storage.ReviewRecord
+	purgeFilter storage.PurgeFilter
+	purgeBy     string
+}
+
+func (m *mockStore) Purge(ctx context.Context, filter storage.PurgeFilter, requestedBy, reason string) (int64, error) {
+	m.purgeFilter = filter
+	m.purgeBy = requestedBy
+	return 3, nil
+}
+
+func (m *mockStore) ListRecentReviews(ctx context.Context, limit int) ([]*storage.ReviewRecord, error) {
+	if limit < len(m.records) {
+		return m.records[:limit], nil
+	}
+	return m.records, nil
+}
+
+func (m *mockStore) ListReviewsByPR(ctx context.Context, projectKey, repoSlug, prID string) ([]*storage.ReviewRecord, error) {
+	var out []*storage.ReviewRecord
+	for _, r := range m.records {
+		if r.PullRequest.ProjectKey == projectKey && r.PullRequest.RepoSlug == repoSlug && r.PullRequest.ID == prID {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockStore) ListReviews(ctx context.Context, filter storage.ReviewFilter, limit int) ([]*storage.ReviewRecord, error) {
+	var out []*storage.ReviewRecord
+	for _, r := range m.records {
+		if r.PullRequest.ProjectKey == filter.ProjectKey && (filter.RepoSlug == "" || r.PullRequest.RepoSlug == filter.RepoSlug) && len(out) < limit {
+			out = append(out, r)
+		}
+	}
+	return out, nil
+}
+
+func (m *mockStore) GetReview(ctx context.Context, id string) (*storage.ReviewRecord, error) {
+	for _, r := range m.records {
+		if r.ID == id {
+			return r, nil
+		}
+	}
+	return nil, storage.ErrNotFound
+}
+
+func (m *mockStore) PurgeOlderThan(ctx context.Context, dataClass string, cutoff time.Time) (int64, error) {
+	return 0, nil
+}
+
+func newTestMux(store storage.Repository) *http.ServeMux {
+	mux := http.NewServeMux()
+	NewServer(&config.Config{}, store).Register(mux)
+	return mux
+}
+
+func TestHandlePurge(t *testing.T) {
+	store := &mockStore{}
+	mux := newTestMux(store)
+
+	tests := []struct {
+		name       string
+		body       any
+		wantStatus int
+	}{
+		{name: "missing filter", body: PurgeRequest{RequestedBy: "dpo"}, wantStatus: http.StatusBadRequest},
+		{name: "missing requester", body: PurgeRequest{PurgeFilter: storage.PurgeFilter{Author: "alice"}}, wantStatus: http.StatusBadRequest},
+		{name: "valid", body: PurgeRequest{PurgeFilter: storage.PurgeFilter{Author: "alice"}, RequestedBy: "dpo"}, wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body, _ := json.Marshal(tt.body)
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/purge", bytes.NewReader(body))
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d: %s", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+		})
+	}
+
+	if store.purgeFilter.Author != "alice" || store.purgeBy != "dpo" {
+		t.Errorf("unexpected purge call: %+v by %s", store.purgeFilter, store.purgeBy)
+	}
+}
+
+func TestHandlePurge_NoStorage(t *testing.T) {
+	mux := newTestMux(nil)
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/purge", bytes.NewReader([]byte(`{"author":"a","requestedBy":"b"}`)))
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", rr.Code)
+	}
+}
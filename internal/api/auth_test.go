@@ -0,0 +1,72 @@
+package api
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+)
+
+func TestAuthenticator(t *testing.T) {
+	cfg := &config.Config{Auth: config.AuthConfig{
+		Enabled: true,
+		Tokens: []config.APIToken{
+			{Name: "dash", Role: config.RoleViewer, Token: "viewer-token"},
+			{Name: "ops", Role: config.RoleAdmin, Token: "admin-token"},
+		},
+	}}
+	mux := http.NewServeMux()
+	NewServer(cfg, newReviewStore()).Register(mux)
+
+	tests := []struct {
+		name       string
+		method     string
+		url        string
+		token      string
+		wantStatus int
+	}{
+		{name: "no token", method: http.MethodGet, url: "/api/v1/stats", wantStatus: http.StatusUnauthorized},
+		{name: "unknown token", method: http.MethodGet, url: "/api/v1/stats", token: "nope", wantStatus: http.StatusUnauthorized},
+		{name: "viewer reads", method: http.MethodGet, url: "/api/v1/stats", token: "viewer-token", wantStatus: http.StatusOK},
+		{name: "viewer cannot purge", method: http.MethodPost, url: "/api/v1/admin/purge", token: "viewer-token", wantStatus: http.StatusForbidden},
+		{name: "admin reads", method: http.MethodGet, url: "/api/openapi.json", token: "admin-token", wantStatus: http.StatusOK},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.url, nil)
+			if tt.token != "" {
+				req.Header.Set("Authorization", "Bearer "+tt.token)
+			}
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+			if rr.Code != tt.wantStatus {
+				t.Errorf("expected status %d, got %d", tt.wantStatus, rr.Code)
+			}
+		})
+	}
+}
+
+func TestPurge_RequestedByDefaultsToCaller(t *testing.T) {
+	cfg := &config.Config{Auth: config.AuthConfig{
+		Enabled: true,
+		Tokens:  []config.APIToken{{Name: "ops", Role: config.RoleAdmin, Token: "admin-token"}},
+	}}
+	store := &mockStore{}
+	mux := http.NewServeMux()
+	NewServer(cfg, store).Register(mux)
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/admin/purge", strings.NewReader(`{"author":"alice"}`))
+	req.Header.Set("Authorization", "Bearer admin-token")
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, req)
+
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if store.purgeBy != "ops" {
+		t.Errorf("expected requester ops, got %q", store.purgeBy)
+	}
+}
@@ -0,0 +1,88 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"pr-review-automation/internal/domain"
+)
+
+// ReviewCanceller stops reviews in progress on this instance
+type ReviewCanceller interface {
+	RunningReviews() []string
+	CancelReview(key string) bool
+}
+
+// RunningReviewList is the response of GET /api/v1/reviews/running
+type RunningReviewList struct {
+	Reviews []string `json:"reviews"` // PR keys, e.g. "PROJ/repo/42"; other providers are prefixed, e.g. "github/org/repo/7"
+}
+
+// CancelReviewResponse is the response of DELETE /api/v1/review/{key} and
+// DELETE /api/v1/reviews/running/{projectKey}/{repoSlug}/{prId}
+type CancelReviewResponse struct {
+	Key       string `json:"key"`
+	Cancelled bool   `json:"cancelled"`
+}
+
+// SetReviewCanceller sets the queue whose reviews /api/v1/reviews/running lists and cancels
+func (s *Server) SetReviewCanceller(c ReviewCanceller) {
+	s.canceller = c
+}
+
+// handleListRunning lists the PRs under review on this instance
+func (s *Server) handleListRunning(w http.ResponseWriter, r *http.Request) {
+	if s.canceller == nil {
+		writeError(w, http.StatusServiceUnavailable, "review queue not configured")
+		return
+	}
+	keys := s.canceller.RunningReviews()
+	if keys == nil {
+		keys = []string{}
+	}
+	writeJSON(w, http.StatusOK, RunningReviewList{Reviews: keys})
+}
+
+// handleCancelReview cancels the running or pending review of one PR
+func (s *Server) handleCancelReview(w http.ResponseWriter, r *http.Request) {
+	if s.canceller == nil {
+		writeError(w, http.StatusServiceUnavailable, "review queue not configured")
+		return
+	}
+	key, ok := runningReviewKey(w, r)
+	if !ok {
+		return
+	}
+
+	if !s.canceller.CancelReview(key) {
+		writeError(w, http.StatusNotFound, "no running or pending review for "+key)
+		return
+	}
+	slog.Info("review cancel requested", "pr", key, "requested_by", callerName(r))
+	writeJSON(w, http.StatusOK, CancelReviewResponse{Key: key, Cancelled: true})
+}
+
+// runningReviewKey returns the PR key of a request: the {key} of /api/v1/review/{key}, or the key
+// of /api/v1/reviews/running/{projectKey}/{repoSlug}/{prId} prefixed with its provider query
+// parameter. It writes 400 and returns false for an empty key or an unknown provider.
+func runningReviewKey(w http.ResponseWriter, r *http.Request) (string, bool) {
+	if r.PathValue("projectKey") == "" {
+		key := r.PathValue("key")
+		if key == "" {
+			writeError(w, http.StatusBadRequest, "missing PR key")
+			return "", false
+		}
+		return key, true
+	}
+	key := r.PathValue("projectKey") + "/" + r.PathValue("repoSlug") + "/" + r.PathValue("prId")
+	switch provider := r.URL.Query().Get("provider"); provider {
+	case "", domain.ProviderBitbucket:
+	case domain.ProviderGitHub, domain.ProviderGitLab, domain.ProviderGitea, domain.ProviderBitbucketCloud:
+		key = provider + "/" + key
+	default:
+		writeError(w, http.StatusBadRequest, "unknown provider "+strconv.Quote(provider))
+		return "", false
+	}
+	return key, true
+}
@@ -0,0 +1,82 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+)
+
+// fakeCanceller tracks running review keys for API tests
+type fakeCanceller struct {
+	running []string
+}
+
+func (f *fakeCanceller) RunningReviews() []string {
+	return f.running
+}
+
+func (f *fakeCanceller) CancelReview(key string) bool {
+	i := slices.Index(f.running, key)
+	if i < 0 {
+		return false
+	}
+	f.running = slices.Delete(f.running, i, i+1)
+	return true
+}
+
+func TestHandleCancelReview(t *testing.T) {
+	canceller := &fakeCanceller{running: []string{"PROJ/repo/1", "github/org/app/7", "PROJ/repo/3", "gitlab/group/app/9"}}
+	mux := http.NewServeMux()
+	server := NewServer(nil, nil)
+	server.SetReviewCanceller(canceller)
+	server.Register(mux)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+		wantKey    string
+	}{
+		{name: "list", method: http.MethodGet, path: "/api/v1/reviews/running", wantStatus: http.StatusOK},
+		{name: "unknown provider", method: http.MethodDelete, path: "/api/v1/reviews/running/org/app/7?provider=svn", wantStatus: http.StatusBadRequest},
+		{name: "not running", method: http.MethodDelete, path: "/api/v1/reviews/running/PROJ/repo/2", wantStatus: http.StatusNotFound},
+		{name: "bitbucket", method: http.MethodDelete, path: "/api/v1/reviews/running/PROJ/repo/1", wantStatus: http.StatusOK, wantKey: "PROJ/repo/1"},
+		{name: "github", method: http.MethodDelete, path: "/api/v1/reviews/running/org/app/7?provider=github", wantStatus: http.StatusOK, wantKey: "github/org/app/7"},
+		{name: "already cancelled", method: http.MethodDelete, path: "/api/v1/reviews/running/PROJ/repo/1", wantStatus: http.StatusNotFound},
+		{name: "by key", method: http.MethodDelete, path: "/api/v1/review/PROJ/repo/3", wantStatus: http.StatusOK, wantKey: "PROJ/repo/3"},
+		{name: "by encoded key", method: http.MethodDelete, path: "/api/v1/review/gitlab%2Fgroup%2Fapp%2F9", wantStatus: http.StatusOK, wantKey: "gitlab/group/app/9"},
+		{name: "by key not running", method: http.MethodDelete, path: "/api/v1/review/PROJ/repo/3", wantStatus: http.StatusNotFound},
+		{name: "empty key", method: http.MethodDelete, path: "/api/v1/review/", wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+			if tt.name == "list" {
+				var list RunningReviewList
+				if err := json.NewDecoder(rr.Body).Decode(&list); err != nil || len(list.Reviews) != 4 {
+					t.Errorf("list = %+v, %v", list, err)
+				}
+			}
+			if tt.wantKey != "" {
+				var resp CancelReviewResponse
+				if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil || resp.Key != tt.wantKey || !resp.Cancelled {
+					t.Errorf("cancel = %+v, %v", resp, err)
+				}
+			}
+		})
+	}
+
+	unconfigured := newTestMux(nil)
+	rr := httptest.NewRecorder()
+	unconfigured.ServeHTTP(rr, httptest.NewRequest(http.MethodDelete, "/api/v1/reviews/running/PROJ/repo/1", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", rr.Code)
+	}
+}
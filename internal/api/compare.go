@@ -0,0 +1,123 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/processor"
+	"pr-review-automation/internal/storage"
+)
+
+// compareTimeout bounds a comparison run, which reviews the PR twice
+const compareTimeout = 30 * time.Minute
+
+// Comparer reviews one PR with two models
+type Comparer interface {
+	Compare(ctx context.Context, id string, pr *domain.PullRequest, models [2]string) (*processor.ComparisonReport, error)
+}
+
+// CompareRequest is the body of POST /api/v1/comparisons
+type CompareRequest struct {
+	ProjectKey string    `json:"projectKey"`
+	RepoSlug   string    `json:"repoSlug"`
+	PRID       string    `json:"prId"`
+	Provider   string    `json:"provider,omitempty"` // bitbucket (default), github, gitlab, gitea, bitbucket-cloud
+	Models     [2]string `json:"models"`             // The two LLM models to compare
+}
+
+// CompareResponse is the response of POST /api/v1/comparisons
+type CompareResponse struct {
+	ID      string `json:"id"` // Comparison id for GET /api/v1/comparisons/{id}
+	Started bool   `json:"started"`
+}
+
+// SetComparer sets the processor used by the comparison endpoints
+func (s *Server) SetComparer(c Comparer) {
+	s.comparer = c
+}
+
+// handleStartComparison starts dry-run reviews of one PR with two models in the background
+func (s *Server) handleStartComparison(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStore(w) {
+		return
+	}
+	if s.comparer == nil {
+		writeError(w, http.StatusServiceUnavailable, "comparison not configured")
+		return
+	}
+
+	var req CompareRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.ProjectKey == "" || req.RepoSlug == "" || req.PRID == "" {
+		writeError(w, http.StatusBadRequest, "projectKey, repoSlug and prId are required")
+		return
+	}
+	if req.Models[0] == "" || req.Models[1] == "" || req.Models[0] == req.Models[1] {
+		writeError(w, http.StatusBadRequest, "models must name two different models")
+		return
+	}
+	switch req.Provider {
+	case "", domain.ProviderBitbucket, domain.ProviderGitHub, domain.ProviderGitLab, domain.ProviderGitea, domain.ProviderBitbucketCloud:
+	default:
+		writeError(w, http.StatusBadRequest, "unknown provider "+strconv.Quote(req.Provider))
+		return
+	}
+
+	pr := &domain.PullRequest{ID: req.PRID, ProjectKey: req.ProjectKey, RepoSlug: req.RepoSlug, Provider: req.Provider}
+	id := fmt.Sprintf("cmp-%s-%s-%s-%d", pr.ProjectKey, pr.RepoSlug, pr.ID, time.Now().UnixNano())
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), compareTimeout)
+		defer cancel()
+		report, err := s.comparer.Compare(ctx, id, pr, req.Models)
+		if err != nil {
+			slog.Error("comparison failed", "id", id, "error", err)
+			return
+		}
+		slog.Info("comparison finished", "id", id, "both", report.Both, "changed", report.Changed, "only_a", report.OnlyA, "only_b", report.OnlyB)
+	}()
+	slog.Info("comparison started", "id", id, "pr_id", pr.ID, "models", req.Models, "requested_by", callerName(r))
+	writeJSON(w, http.StatusAccepted, CompareResponse{ID: id, Started: true})
+}
+
+// handleGetComparison renders a finished comparison as JSON, or as Markdown with ?format=markdown
+func (s *Server) handleGetComparison(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStore(w) {
+		return
+	}
+	id := r.PathValue("id")
+	var records [2]*storage.ReviewRecord
+	for i, side := range processor.ComparisonSides {
+		rec, err := s.store.GetReview(r.Context(), processor.ComparisonReviewID(id, side))
+		if errors.Is(err, storage.ErrNotFound) {
+			writeError(w, http.StatusNotFound, "comparison not found or still running")
+			return
+		}
+		if err != nil {
+			slog.Error("get comparison failed", "id", id, "error", err)
+			writeError(w, http.StatusInternalServerError, "get comparison failed")
+			return
+		}
+		records[i] = rec
+	}
+
+	report := processor.NewComparisonReport(id, records[0], records[1])
+	switch r.URL.Query().Get("format") {
+	case "", "json":
+		writeJSON(w, http.StatusOK, report)
+	case "markdown":
+		w.Header().Set("Content-Type", "text/markdown; charset=utf-8")
+		w.Write([]byte(report.Markdown()))
+	default:
+		writeError(w, http.StatusBadRequest, "format must be json or markdown")
+	}
+}
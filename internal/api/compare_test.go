@@ -0,0 +1,107 @@
+package api
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/processor"
+	"pr-review-automation/internal/storage"
+)
+
+// fakeComparer stores a fixed review for each side, like PRProcessor.Compare
+type fakeComparer struct {
+	store *mockStore
+	done  chan string
+}
+
+func (c *fakeComparer) Compare(ctx context.Context, id string, pr *domain.PullRequest, models [2]string) (*processor.ComparisonReport, error) {
+	var records [2]*storage.ReviewRecord
+	for i, side := range processor.ComparisonSides {
+		records[i] = &storage.ReviewRecord{
+			ID:          processor.ComparisonReviewID(id, side),
+			PullRequest: &domain.PullRequest{ID: pr.ID, ProjectKey: pr.ProjectKey, RepoSlug: pr.RepoSlug, Overrides: &domain.ReviewOverrides{Model: models[i], DryRun: true}},
+			Status:      domain.ReviewStatusSuccess,
+			Result:      &domain.ReviewResult{Comments: []domain.ReviewComment{{File: "a.go", Line: domain.FlexibleLine(i + 1), Severity: domain.CommentSeverityWarning, Comment: "finding"}}},
+		}
+	}
+	c.store.records = append(c.store.records, records[0], records[1])
+	c.done <- id
+	return processor.NewComparisonReport(id, records[0], records[1]), nil
+}
+
+func TestHandleComparison(t *testing.T) {
+	store := &mockStore{}
+	comparer := &fakeComparer{store: store, done: make(chan string, 1)}
+	srv := NewServer(&config.Config{}, store)
+	srv.SetComparer(comparer)
+	mux := http.NewServeMux()
+	srv.Register(mux)
+
+	post := func(body any) *httptest.ResponseRecorder {
+		data, _ := json.Marshal(body)
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/comparisons", bytes.NewReader(data)))
+		return rr
+	}
+
+	for name, body := range map[string]CompareRequest{
+		"missing pr":   {ProjectKey: "PROJ", RepoSlug: "repo", Models: [2]string{"a", "b"}},
+		"one model":    {ProjectKey: "PROJ", RepoSlug: "repo", PRID: "7", Models: [2]string{"a", ""}},
+		"same model":   {ProjectKey: "PROJ", RepoSlug: "repo", PRID: "7", Models: [2]string{"a", "a"}},
+		"bad provider": {ProjectKey: "PROJ", RepoSlug: "repo", PRID: "7", Provider: "svn", Models: [2]string{"a", "b"}},
+	} {
+		if rr := post(body); rr.Code != http.StatusBadRequest {
+			t.Errorf("%s: expected 400, got %d", name, rr.Code)
+		}
+	}
+
+	rr := post(CompareRequest{ProjectKey: "PROJ", RepoSlug: "repo", PRID: "7", Models: [2]string{"gpt-4o", "qwen2.5-coder"}})
+	if rr.Code != http.StatusAccepted {
+		t.Fatalf("expected 202, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var resp CompareResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil || resp.ID == "" {
+		t.Fatalf("bad response %s: %v", rr.Body.String(), err)
+	}
+	select {
+	case id := <-comparer.done:
+		if id != resp.ID {
+			t.Errorf("compared %q, want %q", id, resp.ID)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("comparison not started")
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/comparisons/"+resp.ID, nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	var report processor.ComparisonReport
+	if err := json.Unmarshal(rr.Body.Bytes(), &report); err != nil {
+		t.Fatal(err)
+	}
+	if report.A.Model != "gpt-4o" || report.B.Model != "qwen2.5-coder" || report.OnlyA != 1 || report.OnlyB != 1 {
+		t.Errorf("unexpected report: %+v", report)
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/comparisons/"+resp.ID+"?format=markdown", nil))
+	if ct := rr.Header().Get("Content-Type"); !strings.HasPrefix(ct, "text/markdown") || !strings.Contains(rr.Body.String(), "| gpt-4o | qwen2.5-coder |") {
+		t.Errorf("unexpected markdown (%s):\n%s", ct, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/comparisons/unknown", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404 for unknown comparison, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,105 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"pr-review-automation/internal/progress"
+)
+
+// eventsKeepAlive is how often an idle event stream gets a comment, so proxies keep it open
+const eventsKeepAlive = 15 * time.Second
+
+// ReviewProgress streams the progress of reviews running on this instance
+type ReviewProgress interface {
+	Subscribe(key string) (past []progress.Event, events <-chan progress.Event, stop func(), ok bool)
+}
+
+// handleReviewEventsByKey streams the running review of the PR key before the /events suffix
+// of /api/v1/review/{key}/events. The key keeps its slashes, so the route's wildcard takes the
+// rest of the path.
+func (s *Server) handleReviewEventsByKey(w http.ResponseWriter, r *http.Request) {
+	key, ok := strings.CutSuffix(r.PathValue("key"), "/events")
+	if !ok {
+		writeError(w, http.StatusNotFound, "not found")
+		return
+	}
+	r.SetPathValue("key", key)
+	s.handleReviewEvents(w, r)
+}
+
+// SetProgress sets the source of the events /api/v1/reviews/running/{...}/events and
+// /api/v1/review/{key}/events stream
+func (s *Server) SetProgress(p ReviewProgress) {
+	s.progress = p
+}
+
+// handleReviewEvents streams the progress of the running review of one PR as server-sent
+// events: the events so far, then each new one until the done event
+func (s *Server) handleReviewEvents(w http.ResponseWriter, r *http.Request) {
+	if s.progress == nil {
+		writeError(w, http.StatusServiceUnavailable, "review progress not configured")
+		return
+	}
+	key, ok := runningReviewKey(w, r)
+	if !ok {
+		return
+	}
+	past, events, stop, ok := s.progress.Subscribe(key)
+	if !ok {
+		writeError(w, http.StatusNotFound, "no running review for "+key)
+		return
+	}
+	defer stop()
+
+	// The stream lasts as long as the review, past the server's write timeout
+	rc := http.NewResponseController(w)
+	rc.SetWriteDeadline(time.Time{})
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+	slog.Debug("review events subscribed", "pr", key, "requested_by", callerName(r))
+
+	for _, e := range past {
+		if writeEvent(w, e) != nil {
+			return
+		}
+	}
+	rc.Flush()
+
+	ticker := time.NewTicker(eventsKeepAlive)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-r.Context().Done():
+			return
+		case <-ticker.C:
+			if _, err := fmt.Fprint(w, ": ping\n\n"); err != nil {
+				return
+			}
+		case e, open := <-events:
+			if !open {
+				return
+			}
+			if writeEvent(w, e) != nil {
+				return
+			}
+		}
+		rc.Flush()
+	}
+}
+
+// writeEvent writes e as a server-sent event named after its type
+func writeEvent(w http.ResponseWriter, e progress.Event) error {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "event: %s\ndata: %s\n\n", e.Type, data)
+	return err
+}
@@ -0,0 +1,81 @@
+package api
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/progress"
+)
+
+func TestHandleReviewEvents(t *testing.T) {
+	t.Run("by path", func(t *testing.T) { testReviewEvents(t, "/api/v1/reviews/running/org/app/7/events?provider=github") })
+	t.Run("by key", func(t *testing.T) { testReviewEvents(t, "/api/v1/review/github/org/app/7/events") })
+	t.Run("by encoded key", func(t *testing.T) { testReviewEvents(t, "/api/v1/review/github%2Forg%2Fapp%2F7/events") })
+}
+
+// testReviewEvents streams the running review of github/org/app/7 from path
+func testReviewEvents(t *testing.T, path string) {
+	broker := progress.NewBroker()
+	mux := http.NewServeMux()
+	server := NewServer(nil, nil)
+	server.SetProgress(broker)
+	server.Register(mux)
+	ts := httptest.NewServer(mux)
+	defer ts.Close()
+
+	ctx, finish := broker.Start(context.Background(), "github/org/app/7")
+	progress.Stage(ctx, progress.StageReview)
+
+	resp, err := http.Get(ts.URL + "/api/v1/reviews/running/PROJ/repo/7/events")
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode != http.StatusNotFound {
+		t.Errorf("review not running: status = %d", resp.StatusCode)
+	}
+
+	for _, notFound := range []string{"/api/v1/review/PROJ/repo/7/events", "/api/v1/review/github/org/app/7"} {
+		resp, err = http.Get(ts.URL + notFound)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != http.StatusNotFound {
+			t.Errorf("%s: status = %d, want 404", notFound, resp.StatusCode)
+		}
+	}
+
+	resp, err = http.Get(ts.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if ct := resp.Header.Get("Content-Type"); ct != "text/event-stream" {
+		t.Errorf("Content-Type = %q", ct)
+	}
+
+	// The past stage event arrives first; the summary is published once it was read
+	lines := bufio.NewScanner(resp.Body)
+	var body strings.Builder
+	for lines.Scan() {
+		body.WriteString(lines.Text() + "\n")
+		if lines.Text() == "" && strings.Contains(body.String(), "event: stage") && !strings.Contains(body.String(), "event: summary") {
+			progress.Emit(ctx, progress.Event{Type: progress.EventSummary, Chunk: 1, Summary: "Looks fine."})
+			finish()
+		}
+	}
+	for _, want := range []string{
+		"event: stage\ndata: {\"type\":\"stage\",\"stage\":\"review\"",
+		"event: summary\ndata: {\"type\":\"summary\",\"chunk\":1,\"summary\":\"Looks fine.\"",
+		"event: done\n",
+	} {
+		if !strings.Contains(body.String(), want) {
+			t.Errorf("stream lacks %q:\n%s", want, body.String())
+		}
+	}
+}
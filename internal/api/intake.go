@@ -0,0 +1,90 @@
+package api
+
+import (
+	"context"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"pr-review-automation/internal/domain"
+)
+
+// IntakeController pauses and resumes webhook intake for maintenance windows
+type IntakeController interface {
+	PauseIntake(retryAfter time.Duration) domain.IntakeStatus
+	ResumeIntake(ctx context.Context) (int, error)
+	IntakeStatus() domain.IntakeStatus
+}
+
+// IntakeResumeResponse is the response of POST /api/v1/admin/intake/resume
+type IntakeResumeResponse struct {
+	Restored int                 `json:"restored"` // Reviews restored from the storage snapshot
+	Status   domain.IntakeStatus `json:"status"`
+}
+
+// SetIntakeController sets the queue controlled by /api/v1/admin/intake
+func (s *Server) SetIntakeController(c IntakeController) {
+	s.intake = c
+}
+
+// requireIntake writes 503 and returns false when no queue is configured
+func (s *Server) requireIntake(w http.ResponseWriter) bool {
+	if s.intake == nil {
+		writeError(w, http.StatusServiceUnavailable, "review queue not configured")
+		return false
+	}
+	return true
+}
+
+// handleIntakeStatus reports whether intake is paused and how far the drain has got
+func (s *Server) handleIntakeStatus(w http.ResponseWriter, r *http.Request) {
+	if !s.requireIntake(w) {
+		return
+	}
+	writeJSON(w, http.StatusOK, s.intake.IntakeStatus())
+}
+
+// handlePauseIntake pauses intake; draining and the snapshot continue in the background
+func (s *Server) handlePauseIntake(w http.ResponseWriter, r *http.Request) {
+	if !s.requireIntake(w) {
+		return
+	}
+	var retryAfter time.Duration
+	if raw := r.URL.Query().Get("retryAfter"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			writeError(w, http.StatusBadRequest, "retryAfter must be a positive number of seconds")
+			return
+		}
+		retryAfter = time.Duration(v) * time.Second
+	}
+
+	status := s.intake.PauseIntake(retryAfter)
+	slog.Info("intake pause requested", "requested_by", callerName(r), "retry_after", status.RetryAfter)
+	writeJSON(w, http.StatusOK, status)
+}
+
+// handleResumeIntake resumes intake and queues the snapshotted reviews
+func (s *Server) handleResumeIntake(w http.ResponseWriter, r *http.Request) {
+	if !s.requireIntake(w) {
+		return
+	}
+	restored, err := s.intake.ResumeIntake(r.Context())
+	if err != nil {
+		// Intake is resumed either way; the snapshot stays in storage for the next resume or restart
+		slog.Error("resume intake failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "intake resumed, but the queue snapshot could not be restored")
+		return
+	}
+	slog.Info("intake resume requested", "requested_by", callerName(r), "restored", restored)
+	writeJSON(w, http.StatusOK, IntakeResumeResponse{Restored: restored, Status: s.intake.IntakeStatus()})
+}
+
+// callerName returns the authenticated caller, or "" when auth is disabled
+func callerName(r *http.Request) string {
+	if caller, ok := CallerFromContext(r.Context()); ok {
+		return caller.Name
+	}
+	return ""
+}
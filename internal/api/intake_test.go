@@ -0,0 +1,98 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/domain"
+)
+
+// fakeIntake records intake calls for API tests
+type fakeIntake struct {
+	status     domain.IntakeStatus
+	retryAfter time.Duration
+	restored   int
+	resumeErr  error
+}
+
+func (f *fakeIntake) PauseIntake(retryAfter time.Duration) domain.IntakeStatus {
+	f.retryAfter = retryAfter
+	f.status.Paused = true
+	f.status.RetryAfter = int(retryAfter.Seconds())
+	return f.status
+}
+
+func (f *fakeIntake) ResumeIntake(ctx context.Context) (int, error) {
+	f.status = domain.IntakeStatus{}
+	return f.restored, f.resumeErr
+}
+
+func (f *fakeIntake) IntakeStatus() domain.IntakeStatus {
+	return f.status
+}
+
+func TestHandleIntake(t *testing.T) {
+	intake := &fakeIntake{restored: 2}
+	mux := http.NewServeMux()
+	server := NewServer(nil, nil)
+	server.SetIntakeController(intake)
+	server.Register(mux)
+
+	tests := []struct {
+		name       string
+		method     string
+		path       string
+		wantStatus int
+	}{
+		{name: "invalid retryAfter", method: http.MethodPost, path: "/api/v1/admin/intake/pause?retryAfter=soon", wantStatus: http.StatusBadRequest},
+		{name: "pause", method: http.MethodPost, path: "/api/v1/admin/intake/pause?retryAfter=600", wantStatus: http.StatusOK},
+		{name: "status", method: http.MethodGet, path: "/api/v1/admin/intake", wantStatus: http.StatusOK},
+		{name: "resume", method: http.MethodPost, path: "/api/v1/admin/intake/resume", wantStatus: http.StatusOK},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(tt.method, tt.path, nil))
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+
+			switch tt.name {
+			case "status":
+				var status domain.IntakeStatus
+				if err := json.NewDecoder(rr.Body).Decode(&status); err != nil || !status.Paused || status.RetryAfter != 600 {
+					t.Errorf("status = %+v, %v", status, err)
+				}
+			case "resume":
+				var resp IntakeResumeResponse
+				if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil || resp.Restored != 2 || resp.Status.Paused {
+					t.Errorf("resume = %+v, %v", resp, err)
+				}
+			}
+		})
+	}
+	if intake.retryAfter != 10*time.Minute {
+		t.Errorf("retryAfter = %v", intake.retryAfter)
+	}
+
+	intake.resumeErr = errors.New("storage down")
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/admin/intake/resume", nil))
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("expected 500 when the snapshot cannot be restored, got %d", rr.Code)
+	}
+}
+
+func TestHandleIntake_NotConfigured(t *testing.T) {
+	mux := newTestMux(nil)
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/admin/intake/pause", nil))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", rr.Code)
+	}
+}
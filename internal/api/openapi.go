@@ -0,0 +1,439 @@
+package api
+
+import (
+	"net/http"
+	"reflect"
+	"strings"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/processor"
+	"pr-review-automation/internal/progress"
+	"pr-review-automation/internal/storage"
+)
+
+const openAPIVersion = "3.0.3"
+
+// route describes an API operation. The same table drives the mux and the OpenAPI document,
+// so the published spec cannot drift from the registered handlers.
+type route struct {
+	method      string
+	path        string // ServeMux pattern path; without the "..." of a trailing wildcard, a valid OpenAPI path template
+	docPath     string // OpenAPI path template, when the ServeMux pattern cannot express it
+	operationID string
+	summary     string
+	role        string // Minimum role required when auth is enabled
+	params      []param
+	request     any  // Request body type; nil when the operation takes no body
+	response    any  // 200 response body type
+	stream      bool // The 200 response is a text/event-stream of response values
+	handler     http.HandlerFunc
+}
+
+// param describes a path or query parameter
+type param struct {
+	name        string
+	in          string // path or query
+	typ         string // OpenAPI primitive type
+	description string
+}
+
+// routes returns the API operations served by s
+func (s *Server) routes() []route {
+	prParams := []param{
+		{name: "projectKey", in: "query", typ: "string", description: "Filter by project"},
+		{name: "repoSlug", in: "query", typ: "string", description: "Filter by repository; requires projectKey"},
+		{name: "prId", in: "query", typ: "string", description: "List all reviews of one pull request; requires projectKey and repoSlug"},
+	}
+	limitParam := param{name: "limit", in: "query", typ: "integer", description: "Maximum number of recent reviews"}
+	overrideParams := []param{
+		{name: "direct", in: "query", typ: "boolean", description: "Review all changes in one call, without degradation or chunking"},
+		{name: "chunkTokens", in: "query", typ: "integer", description: "Token budget for degradation and chunking instead of max_context_tokens"},
+		{name: "model", in: "query", typ: "string", description: "LLM model instead of llm.model"},
+		{name: "dryRun", in: "query", typ: "boolean", description: "Review and store the result, but post nothing"},
+	}
+	repoParams := []param{
+		{name: "projectKey", in: "path", typ: "string", description: "Project key"},
+		{name: "repoSlug", in: "path", typ: "string", description: "Repository slug"},
+	}
+	keyParam := param{name: "key", in: "path", typ: "string",
+		description: "PR key with its slashes unencoded, e.g. PROJ/repo/42; other providers are prefixed, e.g. github/org/repo/7. Percent-encoded slashes (%2F) are accepted too"}
+
+	return []route{
+		{
+			method: http.MethodGet, path: "/api/v1/reviews", operationID: "listReviews",
+			summary:  "List recent reviews or the reviews of one pull request",
+			role:     config.RoleViewer,
+			params:   append(prParams, limitParam),
+			response: ReviewList{},
+			handler:  s.handleListReviews,
+		},
+		{
+			method: http.MethodPost, path: "/api/v1/reviews", operationID: "triggerReview",
+			summary:  "Queue a review of one pull request, optionally with per-review overrides",
+			role:     config.RoleOperator,
+			params:   overrideParams,
+			request:  ReviewTriggerRequest{},
+			response: ReviewTriggerResponse{},
+			handler:  s.handleTriggerReview,
+		},
+		{
+			method: http.MethodPost, path: "/api/v1/review", operationID: "rerunReview",
+			summary:  "Re-run the review of one pull request without re-firing its webhook; same as triggerReview",
+			role:     config.RoleOperator,
+			params:   overrideParams,
+			request:  ReviewTriggerRequest{},
+			response: ReviewTriggerResponse{},
+			handler:  s.handleTriggerReview,
+		},
+		{
+			method: http.MethodPost, path: "/api/v1/reviews/patch", operationID: "reviewPatch",
+			summary: "Review a unified diff without a code host and return the findings; nothing is posted or stored",
+			role:    config.RoleOperator,
+			params: []param{
+				{name: "direct", in: "query", typ: "boolean", description: "Review all changes in one call, without degradation or chunking"},
+				{name: "chunkTokens", in: "query", typ: "integer", description: "Token budget for degradation and chunking instead of max_context_tokens"},
+				{name: "model", in: "query", typ: "string", description: "LLM model instead of llm.model"},
+				{name: "title", in: "query", typ: "string", description: "Title of the change, for a raw diff body"},
+				{name: "description", in: "query", typ: "string", description: "Description of the change, for a raw diff body"},
+				{name: "repo", in: "query", typ: "string", description: "Repository name, for a raw diff body"},
+			},
+			request:  PatchReviewRequest{},
+			response: domain.ReviewResult{},
+			handler:  s.handleReviewPatch,
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/reviews/running", operationID: "listRunningReviews",
+			summary:  "List the pull requests under review on this instance",
+			role:     config.RoleOperator,
+			response: RunningReviewList{},
+			handler:  s.handleListRunning,
+		},
+		{
+			method: http.MethodDelete, path: "/api/v1/reviews/running/{projectKey}/{repoSlug}/{prId}", operationID: "cancelReview",
+			summary: "Cancel the running review of a pull request on this instance and drop its pending event",
+			role:    config.RoleOperator,
+			params: append(append([]param(nil), repoParams...),
+				param{name: "prId", in: "path", typ: "string", description: "Pull request id"},
+				param{name: "provider", in: "query", typ: "string", description: "bitbucket (default), github, gitlab, gitea, bitbucket-cloud"},
+			),
+			response: CancelReviewResponse{},
+			handler:  s.handleCancelReview,
+		},
+		{
+			// {key...} spans the slashes of the PR key
+			method: http.MethodDelete, path: "/api/v1/review/{key...}", operationID: "cancelReviewByKey",
+			summary:  "Cancel the running review of a pull request by its PR key; same as cancelReview",
+			role:     config.RoleOperator,
+			params:   []param{keyParam},
+			response: CancelReviewResponse{},
+			handler:  s.handleCancelReview,
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/reviews/running/{projectKey}/{repoSlug}/{prId}/events", operationID: "streamReviewEvents",
+			summary: "Stream the stage transitions, chunk completions and summaries of a running review as server-sent events",
+			role:    config.RoleViewer,
+			params: append(append([]param(nil), repoParams...),
+				param{name: "prId", in: "path", typ: "string", description: "Pull request id"},
+				param{name: "provider", in: "query", typ: "string", description: "bitbucket (default), github, gitlab, gitea, bitbucket-cloud"},
+			),
+			response: progress.Event{},
+			stream:   true,
+			handler:  s.handleReviewEvents,
+		},
+		{
+			// A wildcard spanning slashes must end the pattern, so the handler cuts the /events suffix
+			method: http.MethodGet, path: "/api/v1/review/{key...}", docPath: "/api/v1/review/{key}/events", operationID: "streamReviewEventsByKey",
+			summary:  "Stream the events of the running review of a pull request by its PR key; same as streamReviewEvents",
+			role:     config.RoleViewer,
+			params:   []param{keyParam},
+			response: progress.Event{},
+			stream:   true,
+			handler:  s.handleReviewEventsByKey,
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/reviews/{id}", operationID: "getReview",
+			summary:  "Get a review by id",
+			role:     config.RoleViewer,
+			params:   []param{{name: "id", in: "path", typ: "string", description: "Review id"}},
+			response: storage.ReviewRecord{},
+			handler:  s.handleGetReview,
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/reviews/{id}/result", operationID: "getReviewResult",
+			summary:  "Get the stored review result of a review",
+			role:     config.RoleViewer,
+			params:   []param{{name: "id", in: "path", typ: "string", description: "Review id"}},
+			response: domain.ReviewResult{},
+			handler:  s.handleGetReviewResult,
+		},
+		{
+			method: http.MethodPost, path: "/api/v1/reviews/{id}/replay", operationID: "replayReview",
+			summary:  "Re-run selected stages of a stored review with candidate settings and diff the outcome",
+			role:     config.RoleAdmin,
+			params:   []param{{name: "id", in: "path", typ: "string", description: "Review id"}},
+			request:  ReplayRequest{},
+			response: processor.ReplayReport{},
+			handler:  s.handleReplayReview,
+		},
+		{
+			method: http.MethodPost, path: "/api/v1/comparisons", operationID: "startComparison",
+			summary:  "Review a pull request with two models as dry runs in the background and store both results",
+			role:     config.RoleAdmin,
+			request:  CompareRequest{},
+			response: CompareResponse{},
+			handler:  s.handleStartComparison,
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/comparisons/{id}", operationID: "getComparison",
+			summary: "Get the side-by-side findings of a finished comparison",
+			role:    config.RoleViewer,
+			params: []param{
+				{name: "id", in: "path", typ: "string", description: "Comparison id"},
+				{name: "format", in: "query", typ: "string", description: "json (default) or markdown"},
+			},
+			response: processor.ComparisonReport{},
+			handler:  s.handleGetComparison,
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/stats", operationID: "getStats",
+			summary:  "Summarize the most recent reviews",
+			role:     config.RoleViewer,
+			params:   []param{limitParam},
+			response: Stats{},
+			handler:  s.handleStats,
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/skips", operationID: "listSkips",
+			summary:  "List recently skipped reviews and their reasons",
+			role:     config.RoleViewer,
+			params:   []param{limitParam},
+			response: SkipList{},
+			handler:  s.handleListSkips,
+		},
+		{
+			method: http.MethodGet, path: "/api/tools", operationID: "listTools",
+			summary:  "List discovered MCP tools and input schemas per server",
+			role:     config.RoleOperator,
+			response: ToolsResponse{},
+			handler:  s.handleTools,
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/admin/repos", operationID: "listRepoScope",
+			summary:  "Show the review scope and runtime repository overrides",
+			role:     config.RoleOperator,
+			response: RepoScope{},
+			handler:  s.handleListRepos,
+		},
+		{
+			method: http.MethodPut, path: "/api/v1/admin/repos/{projectKey}/{repoSlug}", operationID: "setRepoOverride",
+			summary:  "Enable or disable reviews for a repository until restart",
+			role:     config.RoleAdmin,
+			params:   repoParams,
+			request:  RepoToggleRequest{},
+			response: RepoStatus{},
+			handler:  s.handleSetRepo,
+		},
+		{
+			method: http.MethodDelete, path: "/api/v1/admin/repos/{projectKey}/{repoSlug}", operationID: "clearRepoOverride",
+			summary:  "Remove a runtime repository override",
+			role:     config.RoleAdmin,
+			params:   repoParams,
+			response: RepoStatus{},
+			handler:  s.handleClearRepo,
+		},
+		{
+			method: http.MethodGet, path: "/api/v1/admin/intake", operationID: "getIntake",
+			summary:  "Show whether webhook intake is paused and how far draining has got",
+			role:     config.RoleOperator,
+			response: domain.IntakeStatus{},
+			handler:  s.handleIntakeStatus,
+		},
+		{
+			method: http.MethodPost, path: "/api/v1/admin/intake/pause", operationID: "pauseIntake",
+			summary:  "Reject webhooks with 503, finish running reviews and snapshot pending ones to storage",
+			role:     config.RoleOperator,
+			params:   []param{{name: "retryAfter", in: "query", typ: "integer", description: "Retry-After seconds sent with 503 responses (default 300)"}},
+			response: domain.IntakeStatus{},
+			handler:  s.handlePauseIntake,
+		},
+		{
+			method: http.MethodPost, path: "/api/v1/admin/intake/resume", operationID: "resumeIntake",
+			summary:  "Accept webhooks again and queue the snapshotted reviews",
+			role:     config.RoleOperator,
+			response: IntakeResumeResponse{},
+			handler:  s.handleResumeIntake,
+		},
+		{
+			method: http.MethodPost, path: "/api/v1/admin/purge", operationID: "purge",
+			summary:  "Delete all stored data for a project, repository or author",
+			role:     config.RoleAdmin,
+			request:  PurgeRequest{},
+			response: PurgeResponse{},
+			handler:  s.handlePurge,
+		},
+	}
+}
+
+// handleOpenAPI serves the OpenAPI document for the API
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.openAPIDocument())
+}
+
+// openAPIDocument builds the OpenAPI document from the route table
+func (s *Server) openAPIDocument() map[string]any {
+	sb := &schemaBuilder{components: map[string]any{}}
+	errSchema := sb.schema(reflect.TypeOf(errorResponse{}))
+
+	paths := map[string]any{}
+	for _, rt := range s.routes() {
+		ok := jsonContent("OK", sb.schema(reflect.TypeOf(rt.response)))
+		if rt.stream {
+			ok = map[string]any{
+				"description": "Server-sent events, each with one value as data",
+				"content":     map[string]any{"text/event-stream": map[string]any{"schema": sb.schema(reflect.TypeOf(rt.response))}},
+			}
+		}
+		op := map[string]any{
+			"operationId":     rt.operationID,
+			"summary":         rt.summary,
+			"x-required-role": rt.role,
+			"responses": map[string]any{
+				"200":     ok,
+				"default": jsonContent("Error", errSchema),
+			},
+		}
+		if len(rt.params) > 0 {
+			params := make([]any, 0, len(rt.params))
+			for _, p := range rt.params {
+				params = append(params, map[string]any{
+					"name":        p.name,
+					"in":          p.in,
+					"required":    p.in == "path",
+					"description": p.description,
+					"schema":      map[string]any{"type": p.typ},
+				})
+			}
+			op["parameters"] = params
+		}
+		if rt.request != nil {
+			op["requestBody"] = map[string]any{
+				"required": true,
+				"content":  map[string]any{"application/json": map[string]any{"schema": sb.schema(reflect.TypeOf(rt.request))}},
+			}
+		}
+
+		path := rt.openAPIPath()
+		item, _ := paths[path].(map[string]any)
+		if item == nil {
+			item = map[string]any{}
+			paths[path] = item
+		}
+		item[strings.ToLower(rt.method)] = op
+	}
+
+	return map[string]any{
+		"openapi": openAPIVersion,
+		"info": map[string]any{
+			"title":   "PR Review Automation API",
+			"version": "v1",
+		},
+		"paths": paths,
+		// Bearer auth applies only when auth is enabled in config
+		"security": []any{map[string]any{"bearerAuth": []any{}}},
+		"components": map[string]any{
+			"schemas":         sb.components,
+			"securitySchemes": map[string]any{"bearerAuth": map[string]any{"type": "http", "scheme": "bearer"}},
+		},
+	}
+}
+
+// openAPIPath returns the OpenAPI path template of the route: its docPath, or its ServeMux
+// pattern path with a trailing {name...} wildcard as {name}
+func (rt route) openAPIPath() string {
+	if rt.docPath != "" {
+		return rt.docPath
+	}
+	return strings.Replace(rt.path, "...}", "}", 1)
+}
+
+func jsonContent(description string, schema map[string]any) map[string]any {
+	return map[string]any{
+		"description": description,
+		"content":     map[string]any{"application/json": map[string]any{"schema": schema}},
+	}
+}
+
+// schemaBuilder derives JSON schemas from Go types, registering named structs as components
+type schemaBuilder struct {
+	components map[string]any
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (b *schemaBuilder) schema(t reflect.Type) map[string]any {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t == timeType {
+		return map[string]any{"type": "string", "format": "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.String:
+		return map[string]any{"type": "string"}
+	case reflect.Bool:
+		return map[string]any{"type": "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return map[string]any{"type": "integer"}
+	case reflect.Float32, reflect.Float64:
+		return map[string]any{"type": "number"}
+	case reflect.Slice, reflect.Array:
+		return map[string]any{"type": "array", "items": b.schema(t.Elem())}
+	case reflect.Map:
+		return map[string]any{"type": "object", "additionalProperties": b.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return b.object(t)
+		}
+		if _, ok := b.components[t.Name()]; !ok {
+			// Reserve the name first so recursive types terminate
+			b.components[t.Name()] = map[string]any{}
+			b.components[t.Name()] = b.object(t)
+		}
+		return map[string]any{"$ref": "#/components/schemas/" + t.Name()}
+	default:
+		return map[string]any{}
+	}
+}
+
+// object builds an object schema from the exported fields of struct type t, following encoding/json naming
+func (b *schemaBuilder) object(t reflect.Type) map[string]any {
+	props := map[string]any{}
+	b.addFields(t, props)
+	return map[string]any{"type": "object", "properties": props}
+}
+
+func (b *schemaBuilder) addFields(t reflect.Type, props map[string]any) {
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		if !f.IsExported() {
+			continue
+		}
+		name, _, _ := strings.Cut(f.Tag.Get("json"), ",")
+		if name == "-" {
+			continue
+		}
+		// Untagged embedded structs are flattened, as encoding/json does
+		if f.Anonymous && name == "" && f.Type.Kind() == reflect.Struct {
+			b.addFields(f.Type, props)
+			continue
+		}
+		if name == "" {
+			name = f.Name
+		}
+		props[name] = b.schema(f.Type)
+	}
+}
@@ -0,0 +1,57 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOpenAPIDocument(t *testing.T) {
+	s := NewServer(nil, nil)
+	mux := http.NewServeMux()
+	s.Register(mux)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/openapi.json", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	var doc struct {
+		Paths      map[string]map[string]json.RawMessage `json:"paths"`
+		Components struct {
+			Schemas map[string]struct {
+				Properties map[string]json.RawMessage `json:"properties"`
+			} `json:"schemas"`
+		} `json:"components"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &doc); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+
+	// Every registered route must be documented
+	for _, rt := range s.routes() {
+		if _, ok := doc.Paths[rt.openAPIPath()][strings.ToLower(rt.method)]; !ok {
+			t.Errorf("route %s %s missing from spec", rt.method, rt.path)
+		}
+	}
+	if _, ok := doc.Paths["/api/v1/review/{key}"]["delete"]; !ok {
+		t.Error("trailing wildcard not documented as {key}")
+	}
+	if _, ok := doc.Paths["/api/v1/review/{key}/events"]["get"]; !ok {
+		t.Error("events by key not documented under their docPath")
+	}
+
+	// Embedded filter fields are flattened into the request schema
+	purge := doc.Components.Schemas["PurgeRequest"].Properties
+	for _, name := range []string{"projectKey", "repoSlug", "author", "requestedBy", "reason"} {
+		if _, ok := purge[name]; !ok {
+			t.Errorf("PurgeRequest schema missing %q", name)
+		}
+	}
+	if _, ok := doc.Components.Schemas["ReviewRecord"]; !ok {
+		t.Error("ReviewRecord schema not registered")
+	}
+}
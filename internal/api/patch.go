@@ -0,0 +1,120 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"mime"
+	"net/http"
+	"strings"
+	"time"
+
+	"pr-review-automation/internal/domain"
+)
+
+const (
+	// maxPatchBytes bounds the diff accepted by POST /api/v1/reviews/patch
+	maxPatchBytes = 10 << 20
+	// patchReviewTimeout bounds a patch review, which is answered synchronously
+	patchReviewTimeout = 10 * time.Minute
+)
+
+// PatchReviewer reviews raw diffs that have no code host
+type PatchReviewer interface {
+	ReviewPatch(ctx context.Context, pr *domain.PullRequest, diff string) (*domain.ReviewResult, error)
+}
+
+// PatchReviewRequest is the JSON body of POST /api/v1/reviews/patch. A body of another content
+// type (e.g. text/x-diff) is the diff itself, with title, description and repo as query parameters.
+type PatchReviewRequest struct {
+	Diff        string `json:"diff"`                  // Unified diff, e.g. the output of git diff main...
+	Title       string `json:"title,omitempty"`       // Shown to the model
+	Description string `json:"description,omitempty"` // Shown to the model
+	Repo        string `json:"repo,omitempty"`        // Repository name, for logs and path-based rules
+}
+
+// SetPatchReviewer sets the processor used by POST /api/v1/reviews/patch
+func (s *Server) SetPatchReviewer(p PatchReviewer) {
+	s.patcher = p
+}
+
+// handleReviewPatch reviews a submitted diff and returns the findings; nothing is posted or stored
+func (s *Server) handleReviewPatch(w http.ResponseWriter, r *http.Request) {
+	if s.patcher == nil {
+		writeError(w, http.StatusServiceUnavailable, "patch reviews not configured")
+		return
+	}
+
+	req, msg := readPatchRequest(w, r)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+	overrides, msg := parseOverrides(r)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	repo := req.Repo
+	if repo == "" {
+		repo = "patch"
+	}
+	title := req.Title
+	if title == "" {
+		title = "Submitted patch"
+	}
+	pr := &domain.PullRequest{
+		ProjectKey:  domain.ProviderLocal,
+		RepoSlug:    repo,
+		Title:       title,
+		Description: req.Description,
+		Author:      callerName(r),
+		Overrides:   overrides,
+	}
+
+	// The review outlasts the server's write timeout
+	http.NewResponseController(w).SetWriteDeadline(time.Now().Add(patchReviewTimeout + time.Minute))
+	ctx, cancel := context.WithTimeout(r.Context(), patchReviewTimeout)
+	defer cancel()
+	result, err := s.patcher.ReviewPatch(ctx, pr, req.Diff)
+	if err != nil {
+		slog.Error("patch review failed", "repo", repo, "requested_by", callerName(r), "error", err)
+		writeError(w, http.StatusBadGateway, "review failed: "+err.Error())
+		return
+	}
+	writeJSON(w, http.StatusOK, result)
+}
+
+// readPatchRequest reads a JSON request or a raw diff body. It returns a non-empty message
+// for invalid requests.
+func readPatchRequest(w http.ResponseWriter, r *http.Request) (PatchReviewRequest, string) {
+	body := http.MaxBytesReader(w, r.Body, maxPatchBytes)
+	var req PatchReviewRequest
+	if mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mediaType == "application/json" {
+		if err := json.NewDecoder(body).Decode(&req); err != nil {
+			return req, patchBodyError(err)
+		}
+	} else {
+		data, err := io.ReadAll(body)
+		if err != nil {
+			return req, patchBodyError(err)
+		}
+		q := r.URL.Query()
+		req = PatchReviewRequest{Diff: string(data), Title: q.Get("title"), Description: q.Get("description"), Repo: q.Get("repo")}
+	}
+	if !strings.Contains(req.Diff, "\n+++ ") && !strings.HasPrefix(req.Diff, "+++ ") {
+		return req, "diff must be a unified diff"
+	}
+	return req, ""
+}
+
+func patchBodyError(err error) string {
+	var tooLarge *http.MaxBytesError
+	if errors.As(err, &tooLarge) {
+		return "diff exceeds 10 MiB"
+	}
+	return "invalid request body"
+}
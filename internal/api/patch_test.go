@@ -0,0 +1,76 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/domain"
+)
+
+// fakePatchReviewer records the reviewed patch for API tests
+type fakePatchReviewer struct {
+	pr   *domain.PullRequest
+	diff string
+}
+
+func (f *fakePatchReviewer) ReviewPatch(ctx context.Context, pr *domain.PullRequest, diff string) (*domain.ReviewResult, error) {
+	f.pr, f.diff = pr, diff
+	return &domain.ReviewResult{Score: 90, Comments: []domain.ReviewComment{{File: "main.go", Line: 1, Comment: "unchecked error"}}}, nil
+}
+
+func TestHandleReviewPatch(t *testing.T) {
+	const diff = "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -0,0 +1 @@\n+x\n"
+	reviewer := &fakePatchReviewer{}
+	mux := http.NewServeMux()
+	server := NewServer(nil, nil)
+	server.SetPatchReviewer(reviewer)
+	server.Register(mux)
+
+	jsonBody, _ := json.Marshal(PatchReviewRequest{Diff: diff, Title: "Add retries", Repo: "api"})
+	tests := []struct {
+		name        string
+		path        string
+		contentType string
+		body        string
+		wantStatus  int
+		wantTitle   string
+	}{
+		{name: "json", path: "/api/v1/reviews/patch", contentType: "application/json", body: string(jsonBody), wantStatus: http.StatusOK, wantTitle: "Add retries"},
+		{name: "raw diff", path: "/api/v1/reviews/patch?title=Fix", contentType: "text/x-diff", body: diff, wantStatus: http.StatusOK, wantTitle: "Fix"},
+		{name: "not a diff", path: "/api/v1/reviews/patch", contentType: "text/plain", body: "hello", wantStatus: http.StatusBadRequest},
+		{name: "invalid json", path: "/api/v1/reviews/patch", contentType: "application/json", body: "{", wantStatus: http.StatusBadRequest},
+		{name: "invalid override", path: "/api/v1/reviews/patch?direct=maybe", contentType: "text/x-diff", body: diff, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			reviewer.pr = nil
+			req := httptest.NewRequest(http.MethodPost, tt.path, strings.NewReader(tt.body))
+			req.Header.Set("Content-Type", tt.contentType)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, req)
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			if reviewer.diff != diff || reviewer.pr.Title != tt.wantTitle {
+				t.Errorf("reviewed %+v with diff %q", reviewer.pr, reviewer.diff)
+			}
+			var result domain.ReviewResult
+			if err := json.NewDecoder(rr.Body).Decode(&result); err != nil || len(result.Comments) != 1 {
+				t.Errorf("result = %+v, %v", result, err)
+			}
+		})
+	}
+	unconfigured := newTestMux(nil)
+	rr := httptest.NewRecorder()
+	unconfigured.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/reviews/patch", strings.NewReader(diff)))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", rr.Code)
+	}
+}
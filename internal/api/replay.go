@@ -0,0 +1,78 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"net/http"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/processor"
+	"pr-review-automation/internal/storage"
+)
+
+// Replayer re-runs pipeline stages on a stored review
+type Replayer interface {
+	Replay(ctx context.Context, record *storage.ReviewRecord, opts processor.ReplayOptions) (*processor.ReplayReport, error)
+}
+
+// ReplayRequest is the body of POST /api/v1/reviews/{id}/replay
+type ReplayRequest struct {
+	Stages       []string            `json:"stages,omitempty"`       // rules, validate, merge; default all
+	RulesFile    string              `json:"rulesFile,omitempty"`    // Server-side rules file to evaluate
+	CommentMerge *ReplayMergeRequest `json:"commentMerge,omitempty"` // Merge settings to evaluate
+	Diff         string              `json:"diff,omitempty"`         // PR diff for validation; fetched when empty
+}
+
+// ReplayMergeRequest mirrors pipeline.comment_merge
+type ReplayMergeRequest struct {
+	Enabled           bool   `json:"enabled"`
+	HighSeverityMerge string `json:"highSeverityMerge,omitempty"` // by_file | none
+	LowSeverityMerge  string `json:"lowSeverityMerge,omitempty"`  // to_summary | none
+}
+
+// SetReplayer sets the processor used by the replay endpoint
+func (s *Server) SetReplayer(r Replayer) {
+	s.replayer = r
+}
+
+// handleReplayReview re-runs selected stages of a stored review with candidate settings
+func (s *Server) handleReplayReview(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStore(w) {
+		return
+	}
+	if s.replayer == nil {
+		writeError(w, http.StatusServiceUnavailable, "replay not configured")
+		return
+	}
+
+	var req ReplayRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+
+	record, err := s.store.GetReview(r.Context(), r.PathValue("id"))
+	if errors.Is(err, storage.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "review not found")
+		return
+	}
+	if err != nil {
+		slog.Error("get review failed", "id", r.PathValue("id"), "error", err)
+		writeError(w, http.StatusInternalServerError, "get review failed")
+		return
+	}
+
+	opts := processor.ReplayOptions{Stages: req.Stages, RulesFile: req.RulesFile, Diff: req.Diff}
+	if m := req.CommentMerge; m != nil {
+		opts.CommentMerge = &config.CommentMergeConfig{Enabled: m.Enabled, HighSeverityMerge: m.HighSeverityMerge, LowSeverityMerge: m.LowSeverityMerge}
+	}
+	report, err := s.replayer.Replay(r.Context(), record, opts)
+	if err != nil {
+		writeError(w, http.StatusUnprocessableEntity, err.Error())
+		return
+	}
+	slog.Info("review replayed", "id", record.ID, "stages", report.Stages, "added", len(report.Added), "removed", len(report.Removed), "changed", len(report.Changed))
+	writeJSON(w, http.StatusOK, report)
+}
@@ -0,0 +1,101 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"pr-review-automation/internal/scope"
+)
+
+// RepoScope is the response of GET /api/v1/admin/repos
+type RepoScope struct {
+	EnabledProjects []string         `json:"enabledProjects"`
+	DisabledRepos   []string         `json:"disabledRepos"`
+	Overrides       []scope.Override `json:"overrides"`
+}
+
+// RepoToggleRequest is the body of PUT /api/v1/admin/repos/{projectKey}/{repoSlug}
+type RepoToggleRequest struct {
+	Enabled *bool `json:"enabled"`
+}
+
+// RepoStatus reports whether a repository is reviewed after a change
+type RepoStatus struct {
+	ProjectKey string `json:"projectKey"`
+	RepoSlug   string `json:"repoSlug"`
+	Enabled    bool   `json:"enabled"`
+	Reason     string `json:"reason,omitempty"` // override, project_disabled, repo_disabled
+}
+
+// SetRepoGate sets the review scope managed by the admin repo endpoints
+func (s *Server) SetRepoGate(g *scope.Gate) {
+	s.gate = g
+}
+
+func (s *Server) requireGate(w http.ResponseWriter) bool {
+	if s.gate == nil {
+		writeError(w, http.StatusServiceUnavailable, "review scope not configured")
+		return false
+	}
+	return true
+}
+
+// handleListRepos returns the configured scope and the runtime overrides
+func (s *Server) handleListRepos(w http.ResponseWriter, r *http.Request) {
+	if !s.requireGate(w) {
+		return
+	}
+	writeJSON(w, http.StatusOK, RepoScope{
+		EnabledProjects: nonNil(s.gate.EnabledProjects()),
+		DisabledRepos:   nonNil(s.gate.DisabledRepos()),
+		Overrides:       s.gate.Overrides(),
+	})
+}
+
+// handleSetRepo enables or disables a repository until restart
+func (s *Server) handleSetRepo(w http.ResponseWriter, r *http.Request) {
+	if !s.requireGate(w) {
+		return
+	}
+	var req RepoToggleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.Enabled == nil {
+		writeError(w, http.StatusBadRequest, "body must be {\"enabled\": true|false}")
+		return
+	}
+
+	projectKey, repoSlug := r.PathValue("projectKey"), r.PathValue("repoSlug")
+	var setBy string
+	if caller, ok := CallerFromContext(r.Context()); ok {
+		setBy = caller.Name
+	}
+	s.gate.SetOverride(projectKey, repoSlug, *req.Enabled, setBy)
+	slog.Info("repository review override set", "project", projectKey, "repo", repoSlug, "enabled", *req.Enabled, "set_by", setBy)
+	s.writeRepoStatus(w, projectKey, repoSlug)
+}
+
+// handleClearRepo removes a runtime override so the configured lists apply again
+func (s *Server) handleClearRepo(w http.ResponseWriter, r *http.Request) {
+	if !s.requireGate(w) {
+		return
+	}
+	projectKey, repoSlug := r.PathValue("projectKey"), r.PathValue("repoSlug")
+	if !s.gate.ClearOverride(projectKey, repoSlug) {
+		writeError(w, http.StatusNotFound, "no override for repository")
+		return
+	}
+	slog.Info("repository review override cleared", "project", projectKey, "repo", repoSlug)
+	s.writeRepoStatus(w, projectKey, repoSlug)
+}
+
+func (s *Server) writeRepoStatus(w http.ResponseWriter, projectKey, repoSlug string) {
+	enabled, reason := s.gate.Allowed(projectKey, repoSlug)
+	writeJSON(w, http.StatusOK, RepoStatus{ProjectKey: projectKey, RepoSlug: repoSlug, Enabled: enabled, Reason: reason})
+}
+
+func nonNil(s []string) []string {
+	if s == nil {
+		return []string{}
+	}
+	return s
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/scope"
+)
+
+func TestRepoScopeAPI(t *testing.T) {
+	gate := scope.NewGate(config.ReviewScopeConfig{DisabledRepos: []string{"PAY/legacy"}})
+	srv := NewServer(&config.Config{}, nil)
+	srv.SetRepoGate(gate)
+	mux := http.NewServeMux()
+	srv.Register(mux)
+
+	do := func(method, path, body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, httptest.NewRequest(method, path, bytes.NewBufferString(body)))
+		return rr
+	}
+
+	rr := do(http.MethodPut, "/api/v1/admin/repos/PAY/legacy", `{"enabled": true}`)
+	if rr.Code != http.StatusOK {
+		t.Fatalf("put: status %d, body %s", rr.Code, rr.Body.String())
+	}
+	var status RepoStatus
+	json.Unmarshal(rr.Body.Bytes(), &status)
+	if !status.Enabled || status.Reason != scope.ReasonOverride {
+		t.Errorf("unexpected status: %+v", status)
+	}
+
+	if rr := do(http.MethodPut, "/api/v1/admin/repos/PAY/legacy", `{}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("missing enabled: status %d", rr.Code)
+	}
+
+	rr = do(http.MethodGet, "/api/v1/admin/repos", "")
+	var sc RepoScope
+	json.Unmarshal(rr.Body.Bytes(), &sc)
+	if len(sc.Overrides) != 1 || len(sc.DisabledRepos) != 1 || sc.EnabledProjects == nil {
+		t.Errorf("unexpected scope: %+v", sc)
+	}
+
+	rr = do(http.MethodDelete, "/api/v1/admin/repos/PAY/legacy", "")
+	json.Unmarshal(rr.Body.Bytes(), &status)
+	if rr.Code != http.StatusOK || status.Enabled || status.Reason != scope.ReasonRepoDisabled {
+		t.Errorf("delete: status %d, %+v", rr.Code, status)
+	}
+	if rr := do(http.MethodDelete, "/api/v1/admin/repos/PAY/legacy", ""); rr.Code != http.StatusNotFound {
+		t.Errorf("second delete: status %d", rr.Code)
+	}
+}
@@ -0,0 +1,117 @@
+package api
+
+import (
+	"errors"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"pr-review-automation/internal/storage"
+)
+
+const (
+	defaultListLimit = 50
+	maxListLimit     = 500
+)
+
+// ReviewList is the response of GET /api/v1/reviews
+type ReviewList struct {
+	Reviews []*storage.ReviewRecord `json:"reviews"`
+}
+
+// handleListReviews lists recent reviews, optionally of one project or repository, or all reviews
+// of one PR when projectKey, repoSlug and prId are given
+func (s *Server) handleListReviews(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStore(w) {
+		return
+	}
+
+	q := r.URL.Query()
+	projectKey, repoSlug, prID := q.Get("projectKey"), q.Get("repoSlug"), q.Get("prId")
+
+	var (
+		records []*storage.ReviewRecord
+		err     error
+	)
+	switch {
+	case projectKey != "" && repoSlug != "" && prID != "":
+		records, err = s.store.ListReviewsByPR(r.Context(), projectKey, repoSlug, prID)
+	case prID != "":
+		writeError(w, http.StatusBadRequest, "prId requires projectKey and repoSlug")
+		return
+	case repoSlug != "" && projectKey == "":
+		writeError(w, http.StatusBadRequest, "repoSlug requires projectKey")
+		return
+	default:
+		limit, ok := parseLimit(w, q.Get("limit"))
+		if !ok {
+			return
+		}
+		if projectKey != "" {
+			records, err = s.store.ListReviews(r.Context(), storage.ReviewFilter{ProjectKey: projectKey, RepoSlug: repoSlug}, limit)
+		} else {
+			records, err = s.store.ListRecentReviews(r.Context(), limit)
+		}
+	}
+	if err != nil {
+		slog.Error("list reviews failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "list reviews failed")
+		return
+	}
+	if records == nil {
+		records = []*storage.ReviewRecord{}
+	}
+	writeJSON(w, http.StatusOK, ReviewList{Reviews: records})
+}
+
+// handleGetReview returns a single review by id
+func (s *Server) handleGetReview(w http.ResponseWriter, r *http.Request) {
+	if record, ok := s.loadReview(w, r); ok {
+		writeJSON(w, http.StatusOK, record)
+	}
+}
+
+// handleGetReviewResult returns the stored review result of a single review
+func (s *Server) handleGetReviewResult(w http.ResponseWriter, r *http.Request) {
+	record, ok := s.loadReview(w, r)
+	if !ok {
+		return
+	}
+	if record.Result == nil {
+		writeError(w, http.StatusNotFound, "review has no result")
+		return
+	}
+	writeJSON(w, http.StatusOK, record.Result)
+}
+
+// loadReview reads the review named by the id path value, writing an error response when it cannot
+func (s *Server) loadReview(w http.ResponseWriter, r *http.Request) (*storage.ReviewRecord, bool) {
+	if !s.requireStore(w) {
+		return nil, false
+	}
+
+	record, err := s.store.GetReview(r.Context(), r.PathValue("id"))
+	if errors.Is(err, storage.ErrNotFound) {
+		writeError(w, http.StatusNotFound, "review not found")
+		return nil, false
+	}
+	if err != nil {
+		slog.Error("get review failed", "id", r.PathValue("id"), "error", err)
+		writeError(w, http.StatusInternalServerError, "get review failed")
+		return nil, false
+	}
+	return record, true
+}
+
+// parseLimit parses the limit query parameter, writing 400 on invalid input
+func parseLimit(w http.ResponseWriter, raw string) (int, bool) {
+	if raw == "" {
+		return defaultListLimit, true
+	}
+	limit, err := strconv.Atoi(raw)
+	if err != nil || limit <= 0 {
+		writeError(w, http.StatusBadRequest, "limit must be a positive integer")
+		return 0, false
+	}
+	return min(limit, maxListLimit), true
+}
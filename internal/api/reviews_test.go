@@ -0,0 +1,229 @@
+package api
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/processor"
+	"pr-review-automation/internal/storage"
+)
+
+func newReviewStore() *mockStore {
+	return &mockStore{records: []*storage.ReviewRecord{
+		{
+			ID:          "r1",
+			PullRequest: &domain.PullRequest{ID: "1", ProjectKey: "PROJ", RepoSlug: "repo"},
+			Result:      &domain.ReviewResult{Score: 80, Comments: []domain.ReviewComment{{File: "a.go"}, {File: "b.go"}}, Outcome: domain.OutcomeOK},
+			Status:      domain.ReviewStatusSuccess,
+			DurationMs:  100,
+		},
+		{
+			ID:          "r2",
+			PullRequest: &domain.PullRequest{ID: "2", ProjectKey: "PROJ", RepoSlug: "repo"},
+			Result:      &domain.ReviewResult{},
+			Status:      "error",
+			DurationMs:  300,
+		},
+	}}
+}
+
+func TestReviewsAPI(t *testing.T) {
+	store := newReviewStore()
+	store.records = append(store.records, &storage.ReviewRecord{
+		ID:          "r3",
+		PullRequest: &domain.PullRequest{ID: "3", ProjectKey: "PROJ", RepoSlug: "web"},
+		Result:      &domain.ReviewResult{},
+		Status:      domain.ReviewStatusSuccess,
+	})
+	mux := newTestMux(store)
+
+	tests := []struct {
+		name       string
+		url        string
+		wantStatus int
+		wantCount  int
+	}{
+		{name: "recent", url: "/api/v1/reviews", wantStatus: http.StatusOK, wantCount: 3},
+		{name: "recent with limit", url: "/api/v1/reviews?limit=1", wantStatus: http.StatusOK, wantCount: 1},
+		{name: "by pr", url: "/api/v1/reviews?projectKey=PROJ&repoSlug=repo&prId=2", wantStatus: http.StatusOK, wantCount: 1},
+		{name: "by project", url: "/api/v1/reviews?projectKey=PROJ", wantStatus: http.StatusOK, wantCount: 3},
+		{name: "by repo", url: "/api/v1/reviews?projectKey=PROJ&repoSlug=repo&limit=1", wantStatus: http.StatusOK, wantCount: 1},
+		{name: "unknown project", url: "/api/v1/reviews?projectKey=NONE", wantStatus: http.StatusOK, wantCount: 0},
+		{name: "repo without project", url: "/api/v1/reviews?repoSlug=repo", wantStatus: http.StatusBadRequest},
+		{name: "pr without repo", url: "/api/v1/reviews?projectKey=PROJ&prId=2", wantStatus: http.StatusBadRequest},
+		{name: "invalid limit", url: "/api/v1/reviews?limit=abc", wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, tt.url, nil))
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected status %d, got %d: %s", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			var list ReviewList
+			if err := json.Unmarshal(rr.Body.Bytes(), &list); err != nil {
+				t.Fatalf("decode: %v", err)
+			}
+			if len(list.Reviews) != tt.wantCount {
+				t.Errorf("expected %d reviews, got %d", tt.wantCount, len(list.Reviews))
+			}
+		})
+	}
+}
+
+func TestGetReview(t *testing.T) {
+	mux := newTestMux(newReviewStore())
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/reviews/r1", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/reviews/missing", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rr.Code)
+	}
+}
+
+func TestGetReviewResult(t *testing.T) {
+	mux := newTestMux(newReviewStore())
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/reviews/r1/result", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+	var result domain.ReviewResult
+	if err := json.Unmarshal(rr.Body.Bytes(), &result); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	if result.Score != 80 || len(result.Comments) != 2 {
+		t.Errorf("unexpected result: %+v", result)
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/reviews/missing/result", nil))
+	if rr.Code != http.StatusNotFound {
+		t.Errorf("expected 404, got %d", rr.Code)
+	}
+}
+
+func TestStats(t *testing.T) {
+	mux := newTestMux(newReviewStore())
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/v1/stats", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	var stats Stats
+	if err := json.Unmarshal(rr.Body.Bytes(), &stats); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	want := Stats{Window: 2, Succeeded: 1, Failed: 1, Comments: 2, AverageScore: 80, AverageDurationMs: 200, Outcomes: map[string]int{domain.OutcomeOK: 1}}
+	if !reflect.DeepEqual(stats, want) {
+		t.Errorf("expected %+v, got %+v", want, stats)
+	}
+}
+
+// ledgerStore adds a skip ledger to mockStore
+type ledgerStore struct {
+	*mockStore
+	skips []*storage.SkipRecord
+}
+
+func (s *ledgerStore) SaveSkip(ctx context.Context, sk *storage.SkipRecord) error {
+	s.skips = append(s.skips, sk)
+	return nil
+}
+
+func (s *ledgerStore) ListSkips(ctx context.Context, limit int) ([]*storage.SkipRecord, error) {
+	return s.skips, nil
+}
+
+func TestListSkips(t *testing.T) {
+	store := &ledgerStore{mockStore: &mockStore{}, skips: []*storage.SkipRecord{{PRID: "1", Reason: domain.SkipReasonSizeGate}}}
+
+	w := httptest.NewRecorder()
+	newTestMux(store).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/skips", nil))
+	if w.Code != http.StatusOK {
+		t.Fatalf("status = %d, body %s", w.Code, w.Body.String())
+	}
+	var got SkipList
+	if err := json.Unmarshal(w.Body.Bytes(), &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got.Skips) != 1 || got.Skips[0].Reason != domain.SkipReasonSizeGate {
+		t.Errorf("unexpected skips: %+v", got.Skips)
+	}
+
+	// Stores without a ledger
+	w = httptest.NewRecorder()
+	newTestMux(&mockStore{}).ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/api/v1/skips", nil))
+	if w.Code != http.StatusNotImplemented {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusNotImplemented)
+	}
+}
+
+// replayFunc adapts a function to the Replayer interface
+type replayFunc func(ctx context.Context, record *storage.ReviewRecord, opts processor.ReplayOptions) (*processor.ReplayReport, error)
+
+func (f replayFunc) Replay(ctx context.Context, record *storage.ReviewRecord, opts processor.ReplayOptions) (*processor.ReplayReport, error) {
+	return f(ctx, record, opts)
+}
+
+func TestReplayReview(t *testing.T) {
+	var gotOpts processor.ReplayOptions
+	srv := NewServer(&config.Config{}, newReviewStore())
+	srv.SetReplayer(replayFunc(func(ctx context.Context, record *storage.ReviewRecord, opts processor.ReplayOptions) (*processor.ReplayReport, error) {
+		gotOpts = opts
+		return &processor.ReplayReport{ReviewID: record.ID, Stages: []string{processor.ReplayStageMerge}}, nil
+	}))
+	mux := http.NewServeMux()
+	srv.Register(mux)
+
+	tests := []struct {
+		name       string
+		id         string
+		body       string
+		wantStatus int
+	}{
+		{name: "replay", id: "r1", body: `{"stages":["merge"],"commentMerge":{"enabled":true,"highSeverityMerge":"by_file"}}`, wantStatus: http.StatusOK},
+		{name: "unknown review", id: "missing", body: `{}`, wantStatus: http.StatusNotFound},
+		{name: "invalid body", id: "r1", body: `{`, wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			w := httptest.NewRecorder()
+			mux.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/reviews/"+tt.id+"/replay", strings.NewReader(tt.body)))
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", w.Code, tt.wantStatus, w.Body.String())
+			}
+		})
+	}
+
+	if gotOpts.CommentMerge == nil || !gotOpts.CommentMerge.Enabled || gotOpts.CommentMerge.HighSeverityMerge != "by_file" {
+		t.Errorf("merge options not passed through: %+v", gotOpts.CommentMerge)
+	}
+
+	// Without a replayer
+	w := httptest.NewRecorder()
+	newTestMux(newReviewStore()).ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/api/v1/reviews/r1/replay", strings.NewReader(`{}`)))
+	if w.Code != http.StatusServiceUnavailable {
+		t.Errorf("status = %d, want %d", w.Code, http.StatusServiceUnavailable)
+	}
+}
@@ -0,0 +1,83 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/scope"
+	"pr-review-automation/internal/storage"
+	"pr-review-automation/internal/types"
+)
+
+// Server exposes the admin and result HTTP API
+type Server struct {
+	cfg   *config.Config
+	store storage.Repository
+	auth  *Authenticator
+	tools types.RawSchemaProvider // Optional: MCP tool schemas for /api/tools
+	gate  *scope.Gate             // Optional: review scope for /api/v1/admin/repos
+
+	replayer  Replayer         // Optional: what-if replay of stored reviews
+	submitter ReviewSubmitter  // Optional: review queue for POST /api/v1/reviews
+	intake    IntakeController // Optional: maintenance pause/resume of webhook intake
+	comparer  Comparer         // Optional: two-model comparison runs
+	canceller ReviewCanceller  // Optional: cancellation of running reviews
+	patcher   PatchReviewer    // Optional: reviews of diffs without a code host
+	progress  ReviewProgress   // Optional: live progress of running reviews
+}
+
+// NewServer creates a new API server. store may be nil when persistence is disabled.
+func NewServer(cfg *config.Config, store storage.Repository) *Server {
+	var authCfg *config.AuthConfig
+	if cfg != nil {
+		authCfg = &cfg.Auth
+	}
+	return &Server{
+		cfg:   cfg,
+		store: store,
+		auth:  NewAuthenticator(authCfg),
+	}
+}
+
+// Auth returns the authenticator used by the API, for protecting other endpoints such as /metrics
+func (s *Server) Auth() *Authenticator {
+	return s.auth
+}
+
+// Register mounts the API routes and the OpenAPI document on mux
+func (s *Server) Register(mux *http.ServeMux) {
+	for _, rt := range s.routes() {
+		mux.Handle(rt.method+" "+rt.path, s.auth.Require(rt.role, rt.handler))
+	}
+	mux.Handle("GET /api/openapi.json", s.auth.Require(config.RoleViewer, http.HandlerFunc(s.handleOpenAPI)))
+}
+
+// requireStore writes 503 and returns false when storage is not configured
+func (s *Server) requireStore(w http.ResponseWriter) bool {
+	if s.store == nil {
+		writeError(w, http.StatusServiceUnavailable, "storage not configured")
+		return false
+	}
+	return true
+}
+
+// writeJSON writes v as a JSON response with the given status
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		slog.Warn("write api response failed", "error", err)
+	}
+}
+
+// errorResponse is the body of all API error responses
+type errorResponse struct {
+	Error string `json:"error"`
+}
+
+// writeError writes a JSON error response
+func writeError(w http.ResponseWriter, status int, msg string) {
+	writeJSON(w, status, errorResponse{Error: msg})
+}
@@ -0,0 +1,40 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"pr-review-automation/internal/storage"
+)
+
+// SkipList is the response of GET /api/v1/skips
+type SkipList struct {
+	Skips []*storage.SkipRecord `json:"skips"`
+}
+
+// handleListSkips lists the most recent skip ledger entries
+func (s *Server) handleListSkips(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStore(w) {
+		return
+	}
+	ledger, ok := s.store.(storage.SkipRepository)
+	if !ok {
+		writeError(w, http.StatusNotImplemented, "storage does not keep a skip ledger")
+		return
+	}
+
+	limit, ok := parseLimit(w, r.URL.Query().Get("limit"))
+	if !ok {
+		return
+	}
+	skips, err := ledger.ListSkips(r.Context(), limit)
+	if err != nil {
+		slog.Error("list skips failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "list skips failed")
+		return
+	}
+	if skips == nil {
+		skips = []*storage.SkipRecord{}
+	}
+	writeJSON(w, http.StatusOK, SkipList{Skips: skips})
+}
@@ -0,0 +1,69 @@
+package api
+
+import (
+	"log/slog"
+	"net/http"
+
+	"pr-review-automation/internal/domain"
+)
+
+// Stats summarizes the most recent reviews
+type Stats struct {
+	Window            int     `json:"window"` // Number of reviews considered
+	Succeeded         int     `json:"succeeded"`
+	Failed            int     `json:"failed"`
+	Comments          int     `json:"comments"`
+	AverageScore      float64 `json:"averageScore"`
+	AverageDurationMs int64   `json:"averageDurationMs"`
+	// Outcomes counts successful reviews by response classification (ok, refusal, empty, ...)
+	Outcomes map[string]int `json:"outcomes,omitempty"`
+}
+
+// handleStats aggregates the most recent reviews (limit query parameter, default 50)
+func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
+	if !s.requireStore(w) {
+		return
+	}
+	limit, ok := parseLimit(w, r.URL.Query().Get("limit"))
+	if !ok {
+		return
+	}
+
+	records, err := s.store.ListRecentReviews(r.Context(), limit)
+	if err != nil {
+		slog.Error("list reviews for stats failed", "error", err)
+		writeError(w, http.StatusInternalServerError, "stats failed")
+		return
+	}
+
+	var stats Stats
+	var scoreSum, scored int
+	var durationSum int64
+	for _, rec := range records {
+		stats.Window++
+		durationSum += rec.DurationMs
+		if rec.Status != domain.ReviewStatusSuccess {
+			stats.Failed++
+			continue
+		}
+		stats.Succeeded++
+		if rec.Result != nil {
+			stats.Comments += len(rec.Result.Comments)
+			scoreSum += rec.Result.Score
+			scored++
+			if rec.Result.Outcome != "" {
+				if stats.Outcomes == nil {
+					stats.Outcomes = make(map[string]int)
+				}
+				stats.Outcomes[rec.Result.Outcome]++
+			}
+		}
+	}
+	if scored > 0 {
+		stats.AverageScore = float64(scoreSum) / float64(scored)
+	}
+	if stats.Window > 0 {
+		stats.AverageDurationMs = durationSum / int64(stats.Window)
+	}
+	writeJSON(w, http.StatusOK, stats)
+}
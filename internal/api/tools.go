@@ -0,0 +1,48 @@
+package api
+
+import (
+	"net/http"
+	"sort"
+	"time"
+
+	"pr-review-automation/internal/types"
+)
+
+// ToolInfo describes a discovered MCP tool
+type ToolInfo struct {
+	Name        string                 `json:"name"`
+	InputSchema map[string]interface{} `json:"inputSchema,omitempty"`
+}
+
+// ToolsResponse is the response of GET /api/tools
+type ToolsResponse struct {
+	UpdatedAt time.Time             `json:"updatedAt"` // Last schema refresh; zero when unknown
+	Servers   map[string][]ToolInfo `json:"servers"`
+}
+
+// SetToolProvider sets the source of discovered MCP tool schemas for /api/tools
+func (s *Server) SetToolProvider(p types.RawSchemaProvider) {
+	s.tools = p
+}
+
+// handleTools lists the tools and input schemas discovered per MCP server
+func (s *Server) handleTools(w http.ResponseWriter, r *http.Request) {
+	if s.tools == nil {
+		writeError(w, http.StatusServiceUnavailable, "mcp client not configured")
+		return
+	}
+
+	resp := ToolsResponse{Servers: make(map[string][]ToolInfo)}
+	if c, ok := s.tools.(interface{ ToolCacheUpdatedAt() time.Time }); ok {
+		resp.UpdatedAt = c.ToolCacheUpdatedAt()
+	}
+	for server, schemas := range s.tools.GetRawToolSchemas() {
+		tools := make([]ToolInfo, 0, len(schemas))
+		for _, t := range schemas {
+			tools = append(tools, ToolInfo{Name: t.Name, InputSchema: t.InputSchema})
+		}
+		sort.Slice(tools, func(i, j int) bool { return tools[i].Name < tools[j].Name })
+		resp.Servers[server] = tools
+	}
+	writeJSON(w, http.StatusOK, resp)
+}
@@ -0,0 +1,41 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pr-review-automation/internal/types"
+)
+
+type staticTools map[string][]types.RawToolSchema
+
+func (s staticTools) GetRawToolSchemas() map[string][]types.RawToolSchema { return s }
+
+func TestHandleTools(t *testing.T) {
+	s := NewServer(nil, nil)
+	s.SetToolProvider(staticTools{
+		"bitbucket": {
+			{Name: "b_tool", InputSchema: map[string]interface{}{"type": "object"}},
+			{Name: "a_tool"},
+		},
+	})
+	mux := http.NewServeMux()
+	s.Register(mux)
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodGet, "/api/tools", nil))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", rr.Code)
+	}
+
+	var resp ToolsResponse
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("decode: %v", err)
+	}
+	tools := resp.Servers["bitbucket"]
+	if len(tools) != 2 || tools[0].Name != "a_tool" || tools[1].InputSchema["type"] != "object" {
+		t.Errorf("unexpected tools: %+v", tools)
+	}
+}
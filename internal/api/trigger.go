@@ -0,0 +1,108 @@
+package api
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strconv"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/fault"
+)
+
+// ReviewSubmitter queues reviews requested through the API
+type ReviewSubmitter interface {
+	SubmitReview(pr *domain.PullRequest)
+}
+
+// ReviewTriggerRequest is the body of POST /api/v1/reviews. Per-review overrides are
+// given as query parameters (direct, chunkTokens, model, dryRun).
+type ReviewTriggerRequest struct {
+	ProjectKey string `json:"projectKey"`
+	RepoSlug   string `json:"repoSlug"`
+	PRID       string `json:"prId"`
+	Provider   string `json:"provider,omitempty"` // bitbucket (default), github, gitlab, gitea, bitbucket-cloud
+}
+
+// ReviewTriggerResponse is the response of POST /api/v1/reviews
+type ReviewTriggerResponse struct {
+	Queued    bool                    `json:"queued"`
+	Overrides *domain.ReviewOverrides `json:"overrides"`
+}
+
+// SetReviewSubmitter sets the queue used by POST /api/v1/reviews
+func (s *Server) SetReviewSubmitter(q ReviewSubmitter) {
+	s.submitter = q
+}
+
+// handleTriggerReview queues a review of one PR, with optional per-review overrides
+func (s *Server) handleTriggerReview(w http.ResponseWriter, r *http.Request) {
+	if s.submitter == nil {
+		writeError(w, http.StatusServiceUnavailable, "review queue not configured")
+		return
+	}
+
+	var req ReviewTriggerRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeError(w, http.StatusBadRequest, "invalid request body")
+		return
+	}
+	if req.ProjectKey == "" || req.RepoSlug == "" || req.PRID == "" {
+		writeError(w, http.StatusBadRequest, "projectKey, repoSlug and prId are required")
+		return
+	}
+	switch req.Provider {
+	case "", domain.ProviderBitbucket, domain.ProviderGitHub, domain.ProviderGitLab, domain.ProviderGitea, domain.ProviderBitbucketCloud:
+	default:
+		writeError(w, http.StatusBadRequest, "unknown provider "+strconv.Quote(req.Provider))
+		return
+	}
+
+	overrides, msg := parseOverrides(r)
+	if msg != "" {
+		writeError(w, http.StatusBadRequest, msg)
+		return
+	}
+
+	pr := &domain.PullRequest{
+		ID:         req.PRID,
+		ProjectKey: req.ProjectKey,
+		RepoSlug:   req.RepoSlug,
+		Provider:   req.Provider,
+		Overrides:  overrides,
+	}
+	if s.cfg != nil && s.cfg.FaultInjection.Enabled && s.cfg.FaultInjection.Header != "" {
+		pr.Faults = fault.ParseHeader(r.Header.Get(s.cfg.FaultInjection.Header))
+	}
+	s.submitter.SubmitReview(pr)
+	slog.Info("review triggered", "project", pr.ProjectKey, "repo", pr.RepoSlug, "pr_id", pr.ID, "requested_by", callerName(r))
+	writeJSON(w, http.StatusOK, ReviewTriggerResponse{Queued: true, Overrides: overrides})
+}
+
+// parseOverrides reads the per-review overrides from the query string.
+// It returns a non-empty message for invalid values.
+func parseOverrides(r *http.Request) (*domain.ReviewOverrides, string) {
+	q := r.URL.Query()
+	o := &domain.ReviewOverrides{Model: q.Get("model")}
+
+	for name, dst := range map[string]*bool{"direct": &o.Direct, "dryRun": &o.DryRun} {
+		if raw := q.Get(name); raw != "" {
+			v, err := strconv.ParseBool(raw)
+			if err != nil {
+				return nil, name + " must be a boolean"
+			}
+			*dst = v
+		}
+	}
+	if raw := q.Get("chunkTokens"); raw != "" {
+		v, err := strconv.Atoi(raw)
+		if err != nil || v <= 0 {
+			return nil, "chunkTokens must be a positive integer"
+		}
+		o.ChunkTokens = v
+	}
+	if o.Direct && o.ChunkTokens > 0 {
+		return nil, "direct and chunkTokens cannot be combined"
+	}
+	return o, ""
+}
@@ -0,0 +1,102 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+type recordingSubmitter struct {
+	prs []*domain.PullRequest
+}
+
+func (s *recordingSubmitter) SubmitReview(pr *domain.PullRequest) {
+	s.prs = append(s.prs, pr)
+}
+
+func TestHandleTriggerReview(t *testing.T) {
+	body := ReviewTriggerRequest{ProjectKey: "PROJ", RepoSlug: "repo", PRID: "7"}
+	tests := []struct {
+		name       string
+		query      string
+		body       any
+		wantStatus int
+		want       domain.ReviewOverrides
+	}{
+		{name: "no overrides", body: body, wantStatus: http.StatusOK},
+		{
+			name:       "all overrides",
+			query:      "?chunkTokens=16000&model=qwen2.5-coder&dryRun=true",
+			body:       body,
+			wantStatus: http.StatusOK,
+			want:       domain.ReviewOverrides{ChunkTokens: 16000, Model: "qwen2.5-coder", DryRun: true},
+		},
+		{name: "direct", query: "?direct=1", body: body, wantStatus: http.StatusOK, want: domain.ReviewOverrides{Direct: true}},
+		{name: "missing pr", body: ReviewTriggerRequest{ProjectKey: "PROJ", RepoSlug: "repo"}, wantStatus: http.StatusBadRequest},
+		{name: "unknown provider", body: ReviewTriggerRequest{ProjectKey: "PROJ", RepoSlug: "repo", PRID: "7", Provider: "svn"}, wantStatus: http.StatusBadRequest},
+		{name: "invalid chunk tokens", query: "?chunkTokens=-5", body: body, wantStatus: http.StatusBadRequest},
+		{name: "invalid bool", query: "?dryRun=maybe", body: body, wantStatus: http.StatusBadRequest},
+		{name: "direct with chunk tokens", query: "?direct=true&chunkTokens=1000", body: body, wantStatus: http.StatusBadRequest},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			submitter := &recordingSubmitter{}
+			srv := NewServer(&config.Config{}, nil)
+			srv.SetReviewSubmitter(submitter)
+			mux := http.NewServeMux()
+			srv.Register(mux)
+
+			data, _ := json.Marshal(tt.body)
+			rr := httptest.NewRecorder()
+			mux.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/reviews"+tt.query, bytes.NewReader(data)))
+			if rr.Code != tt.wantStatus {
+				t.Fatalf("expected %d, got %d: %s", tt.wantStatus, rr.Code, rr.Body.String())
+			}
+			if tt.wantStatus != http.StatusOK {
+				if len(submitter.prs) != 0 {
+					t.Errorf("rejected request must not be queued")
+				}
+				return
+			}
+			if len(submitter.prs) != 1 {
+				t.Fatalf("expected one queued review, got %d", len(submitter.prs))
+			}
+			pr := submitter.prs[0]
+			if pr.ID != "7" || pr.ProjectKey != "PROJ" || pr.RepoSlug != "repo" || pr.Overrides == nil || *pr.Overrides != tt.want {
+				t.Errorf("queued %+v with overrides %+v, want %+v", pr, pr.Overrides, tt.want)
+			}
+		})
+	}
+}
+
+func TestHandleTriggerReview_NotConfigured(t *testing.T) {
+	rr := httptest.NewRecorder()
+	newTestMux(nil).ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/reviews", bytes.NewBufferString(`{}`)))
+	if rr.Code != http.StatusServiceUnavailable {
+		t.Errorf("expected 503, got %d", rr.Code)
+	}
+}
+
+func TestHandleTriggerReview_Rerun(t *testing.T) {
+	submitter := &recordingSubmitter{}
+	srv := NewServer(&config.Config{}, nil)
+	srv.SetReviewSubmitter(submitter)
+	mux := http.NewServeMux()
+	srv.Register(mux)
+
+	rr := httptest.NewRecorder()
+	body := `{"projectKey": "PROJ", "repoSlug": "repo", "prId": "7"}`
+	mux.ServeHTTP(rr, httptest.NewRequest(http.MethodPost, "/api/v1/review", bytes.NewBufferString(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d: %s", rr.Code, rr.Body.String())
+	}
+	if len(submitter.prs) != 1 || submitter.prs[0].ID != "7" || submitter.prs[0].RepoSlug != "repo" {
+		t.Errorf("expected PROJ/repo/7 to be queued, got %+v", submitter.prs)
+	}
+}
@@ -0,0 +1,335 @@
+// Package apiclient is a Go client for the review result and admin API.
+// Operations and types mirror the routes published at /api/openapi.json.
+package apiclient
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"pr-review-automation/internal/api"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/processor"
+	"pr-review-automation/internal/progress"
+	"pr-review-automation/internal/storage"
+)
+
+// Client calls the API of a running server
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+}
+
+// New creates a client for the server at baseURL (e.g. http://localhost:8080).
+// httpClient may be nil to use a client with a 30s timeout.
+func New(baseURL string, httpClient *http.Client) *Client {
+	if httpClient == nil {
+		httpClient = &http.Client{Timeout: 30 * time.Second}
+	}
+	return &Client{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		httpClient: httpClient,
+	}
+}
+
+// Error is returned for non-2xx responses
+type Error struct {
+	StatusCode int
+	Message    string
+}
+
+func (e *Error) Error() string {
+	return fmt.Sprintf("api error %d: %s", e.StatusCode, e.Message)
+}
+
+// ListRecentReviews returns up to limit recent reviews. A limit of 0 uses the server default.
+func (c *Client) ListRecentReviews(ctx context.Context, limit int) ([]*storage.ReviewRecord, error) {
+	q := url.Values{}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var out api.ReviewList
+	err := c.do(ctx, http.MethodGet, "/api/v1/reviews", q, nil, &out)
+	return out.Reviews, err
+}
+
+// ListReviewsByPR returns all reviews of a pull request, newest first
+func (c *Client) ListReviewsByPR(ctx context.Context, projectKey, repoSlug, prID string) ([]*storage.ReviewRecord, error) {
+	q := url.Values{}
+	q.Set("projectKey", projectKey)
+	q.Set("repoSlug", repoSlug)
+	q.Set("prId", prID)
+	var out api.ReviewList
+	err := c.do(ctx, http.MethodGet, "/api/v1/reviews", q, nil, &out)
+	return out.Reviews, err
+}
+
+// ListReviews returns up to limit recent reviews of a project, or of one repository when
+// filter.RepoSlug is set. A limit of 0 uses the server default.
+func (c *Client) ListReviews(ctx context.Context, filter storage.ReviewFilter, limit int) ([]*storage.ReviewRecord, error) {
+	q := url.Values{}
+	q.Set("projectKey", filter.ProjectKey)
+	if filter.RepoSlug != "" {
+		q.Set("repoSlug", filter.RepoSlug)
+	}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var out api.ReviewList
+	err := c.do(ctx, http.MethodGet, "/api/v1/reviews", q, nil, &out)
+	return out.Reviews, err
+}
+
+// GetReview returns a review by id
+func (c *Client) GetReview(ctx context.Context, id string) (*storage.ReviewRecord, error) {
+	var out storage.ReviewRecord
+	if err := c.do(ctx, http.MethodGet, "/api/v1/reviews/"+url.PathEscape(id), nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// GetReviewResult returns the stored review result of a review
+func (c *Client) GetReviewResult(ctx context.Context, id string) (*domain.ReviewResult, error) {
+	var out domain.ReviewResult
+	if err := c.do(ctx, http.MethodGet, "/api/v1/reviews/"+url.PathEscape(id)+"/result", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListRunningReviews returns the keys of the PRs under review on the server instance
+func (c *Client) ListRunningReviews(ctx context.Context) ([]string, error) {
+	var out api.RunningReviewList
+	err := c.do(ctx, http.MethodGet, "/api/v1/reviews/running", nil, nil, &out)
+	return out.Reviews, err
+}
+
+// CancelReview cancels the running review of a pull request; an empty provider means Bitbucket Server
+func (c *Client) CancelReview(ctx context.Context, provider, projectKey, repoSlug, prID string) (*api.CancelReviewResponse, error) {
+	q := url.Values{}
+	if provider != "" {
+		q.Set("provider", provider)
+	}
+	path := "/api/v1/reviews/running/" + url.PathEscape(projectKey) + "/" + url.PathEscape(repoSlug) + "/" + url.PathEscape(prID)
+	var out api.CancelReviewResponse
+	if err := c.do(ctx, http.MethodDelete, path, q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// StreamReviewEvents follows the running review of a pull request, calling fn with each of its
+// progress events until the done event or until ctx ends. An empty provider means Bitbucket Server.
+// The stream is not bound by the client timeout.
+func (c *Client) StreamReviewEvents(ctx context.Context, provider, projectKey, repoSlug, prID string, fn func(progress.Event)) error {
+	u := c.baseURL + "/api/v1/reviews/running/" + url.PathEscape(projectKey) + "/" + url.PathEscape(repoSlug) + "/" + url.PathEscape(prID) + "/events"
+	if provider != "" {
+		u += "?" + url.Values{"provider": {provider}}.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+
+	hc := *c.httpClient
+	hc.Timeout = 0
+	resp, err := hc.Do(req)
+	if err != nil {
+		return fmt.Errorf("stream review events: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		var e struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		return &Error{StatusCode: resp.StatusCode, Message: e.Error}
+	}
+
+	lines := bufio.NewScanner(resp.Body)
+	lines.Buffer(nil, 1<<20) // Summaries can be long
+	for lines.Scan() {
+		data, ok := strings.CutPrefix(lines.Text(), "data: ")
+		if !ok {
+			continue // Event names, keepalive comments and separators
+		}
+		var e progress.Event
+		if err := json.Unmarshal([]byte(data), &e); err != nil {
+			return fmt.Errorf("decode event: %w", err)
+		}
+		fn(e)
+		if e.Type == progress.EventDone {
+			return nil
+		}
+	}
+	if err := lines.Err(); err != nil {
+		return fmt.Errorf("read events: %w", err)
+	}
+	return ctx.Err()
+}
+
+// ReplayReview re-runs selected stages of a stored review with candidate settings
+func (c *Client) ReplayReview(ctx context.Context, id string, req api.ReplayRequest) (*processor.ReplayReport, error) {
+	var out processor.ReplayReport
+	if err := c.do(ctx, http.MethodPost, "/api/v1/reviews/"+url.PathEscape(id)+"/replay", nil, req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// TriggerReview queues a review of one pull request. overrides may be nil to use the server configuration.
+func (c *Client) TriggerReview(ctx context.Context, req api.ReviewTriggerRequest, overrides *domain.ReviewOverrides) (*api.ReviewTriggerResponse, error) {
+	var out api.ReviewTriggerResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/reviews", overrideQuery(overrides), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ReviewPatch reviews a unified diff without a code host and returns the findings. overrides may
+// be nil to use the server configuration; DryRun does not apply, nothing is ever posted.
+func (c *Client) ReviewPatch(ctx context.Context, req api.PatchReviewRequest, overrides *domain.ReviewOverrides) (*domain.ReviewResult, error) {
+	var out domain.ReviewResult
+	if err := c.do(ctx, http.MethodPost, "/api/v1/reviews/patch", overrideQuery(overrides), req, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// overrideQuery encodes per-review overrides as query parameters
+func overrideQuery(o *domain.ReviewOverrides) url.Values {
+	q := url.Values{}
+	if o == nil {
+		return q
+	}
+	if o.Direct {
+		q.Set("direct", "true")
+	}
+	if o.ChunkTokens > 0 {
+		q.Set("chunkTokens", strconv.Itoa(o.ChunkTokens))
+	}
+	if o.Model != "" {
+		q.Set("model", o.Model)
+	}
+	if o.DryRun {
+		q.Set("dryRun", "true")
+	}
+	return q
+}
+
+// Stats summarizes up to limit recent reviews. A limit of 0 uses the server default.
+func (c *Client) Stats(ctx context.Context, limit int) (*api.Stats, error) {
+	q := url.Values{}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var out api.Stats
+	if err := c.do(ctx, http.MethodGet, "/api/v1/stats", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ListSkips returns the most recent skip ledger entries; limit <= 0 uses the server default
+func (c *Client) ListSkips(ctx context.Context, limit int) ([]*storage.SkipRecord, error) {
+	q := url.Values{}
+	if limit > 0 {
+		q.Set("limit", strconv.Itoa(limit))
+	}
+	var out api.SkipList
+	if err := c.do(ctx, http.MethodGet, "/api/v1/skips", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return out.Skips, nil
+}
+
+// Purge deletes all stored data matching the request filter and returns the number of deleted records
+func (c *Client) Purge(ctx context.Context, req api.PurgeRequest) (int64, error) {
+	var out api.PurgeResponse
+	err := c.do(ctx, http.MethodPost, "/api/v1/admin/purge", nil, req, &out)
+	return out.Deleted, err
+}
+
+// IntakeStatus reports whether webhook intake is paused for maintenance
+func (c *Client) IntakeStatus(ctx context.Context) (*domain.IntakeStatus, error) {
+	var out domain.IntakeStatus
+	if err := c.do(ctx, http.MethodGet, "/api/v1/admin/intake", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// PauseIntake pauses webhook intake; a retryAfter of 0 uses the server default
+func (c *Client) PauseIntake(ctx context.Context, retryAfter time.Duration) (*domain.IntakeStatus, error) {
+	q := url.Values{}
+	if retryAfter > 0 {
+		q.Set("retryAfter", strconv.Itoa(int(retryAfter.Seconds())))
+	}
+	var out domain.IntakeStatus
+	if err := c.do(ctx, http.MethodPost, "/api/v1/admin/intake/pause", q, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// ResumeIntake resumes webhook intake and queues the snapshotted reviews
+func (c *Client) ResumeIntake(ctx context.Context) (*api.IntakeResumeResponse, error) {
+	var out api.IntakeResumeResponse
+	if err := c.do(ctx, http.MethodPost, "/api/v1/admin/intake/resume", nil, nil, &out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+func (c *Client) do(ctx context.Context, method, path string, query url.Values, body, out any) error {
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	req.Header.Set("Accept", "application/json")
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		var e struct {
+			Error string `json:"error"`
+		}
+		_ = json.NewDecoder(resp.Body).Decode(&e)
+		return &Error{StatusCode: resp.StatusCode, Message: e.Error}
+	}
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("decode response: %w", err)
+	}
+	return nil
+}
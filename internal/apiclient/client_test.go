@@ -0,0 +1,170 @@
+package apiclient
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/api"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/progress"
+	"pr-review-automation/internal/storage"
+)
+
+func TestClient(t *testing.T) {
+	repo, err := storage.NewSQLiteRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open repo: %v", err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	err = repo.SaveReview(ctx, &storage.ReviewRecord{
+		ID:          "r1",
+		PullRequest: &domain.PullRequest{ID: "1", ProjectKey: "PROJ", RepoSlug: "repo", Author: "alice"},
+		Result:      &domain.ReviewResult{Score: 90},
+		CreatedAt:   time.Now(),
+		Status:      domain.ReviewStatusSuccess,
+	})
+	if err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	queue := &queueRecorder{}
+	mux := http.NewServeMux()
+	server := api.NewServer(nil, repo)
+	server.SetReviewSubmitter(queue)
+	server.SetIntakeController(&intakeRecorder{})
+	server.SetPatchReviewer(&patchRecorder{})
+	server.Register(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	c := New(srv.URL, nil)
+
+	reviews, err := c.ListReviewsByPR(ctx, "PROJ", "repo", "1")
+	if err != nil || len(reviews) != 1 {
+		t.Fatalf("ListReviewsByPR: %v, %d reviews", err, len(reviews))
+	}
+
+	reviews, err = c.ListReviews(ctx, storage.ReviewFilter{ProjectKey: "PROJ"}, 0)
+	if err != nil || len(reviews) != 1 {
+		t.Fatalf("ListReviews: %v, %d reviews", err, len(reviews))
+	}
+
+	rec, err := c.GetReview(ctx, "r1")
+	if err != nil || rec.Result.Score != 90 {
+		t.Fatalf("GetReview: %v, %+v", err, rec)
+	}
+
+	result, err := c.GetReviewResult(ctx, "r1")
+	if err != nil || result.Score != 90 {
+		t.Fatalf("GetReviewResult: %v, %+v", err, result)
+	}
+
+	stats, err := c.Stats(ctx, 0)
+	if err != nil || stats.Succeeded != 1 {
+		t.Fatalf("Stats: %v, %+v", err, stats)
+	}
+
+	triggered, err := c.TriggerReview(ctx, api.ReviewTriggerRequest{ProjectKey: "PROJ", RepoSlug: "repo", PRID: "2"}, &domain.ReviewOverrides{Direct: true, DryRun: true})
+	if err != nil || !triggered.Queued || len(queue.prs) != 1 || *queue.prs[0].Overrides != (domain.ReviewOverrides{Direct: true, DryRun: true}) {
+		t.Fatalf("TriggerReview: %v, %+v", err, triggered)
+	}
+
+	patch, err := c.ReviewPatch(ctx, api.PatchReviewRequest{Diff: "--- a/x\n+++ b/x\n@@ -0,0 +1 @@\n+x\n"}, &domain.ReviewOverrides{Model: "gpt-4o"})
+	if err != nil || patch.Model != "gpt-4o" {
+		t.Fatalf("ReviewPatch: %v, %+v", err, patch)
+	}
+
+	paused, err := c.PauseIntake(ctx, 10*time.Minute)
+	if err != nil || !paused.Paused || paused.RetryAfter != 600 {
+		t.Fatalf("PauseIntake: %v, %+v", err, paused)
+	}
+	if status, err := c.IntakeStatus(ctx); err != nil || !status.Paused {
+		t.Fatalf("IntakeStatus: %v, %+v", err, status)
+	}
+	if resumed, err := c.ResumeIntake(ctx); err != nil || resumed.Status.Paused {
+		t.Fatalf("ResumeIntake: %v, %+v", err, resumed)
+	}
+
+	n, err := c.Purge(ctx, api.PurgeRequest{PurgeFilter: storage.PurgeFilter{Author: "alice"}, RequestedBy: "dpo"})
+	if err != nil || n != 1 {
+		t.Fatalf("Purge: %v, deleted %d", err, n)
+	}
+
+	var apiErr *Error
+	if _, err := c.GetReview(ctx, "r1"); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("expected 404 after purge, got %v", err)
+	}
+}
+
+type queueRecorder struct {
+	prs []*domain.PullRequest
+}
+
+func (q *queueRecorder) SubmitReview(pr *domain.PullRequest) {
+	q.prs = append(q.prs, pr)
+}
+
+type patchRecorder struct{}
+
+func (patchRecorder) ReviewPatch(ctx context.Context, pr *domain.PullRequest, diff string) (*domain.ReviewResult, error) {
+	return &domain.ReviewResult{Model: pr.Overrides.Model}, nil
+}
+
+type intakeRecorder struct {
+	status domain.IntakeStatus
+}
+
+func (i *intakeRecorder) PauseIntake(retryAfter time.Duration) domain.IntakeStatus {
+	i.status = domain.IntakeStatus{Paused: true, RetryAfter: int(retryAfter.Seconds())}
+	return i.status
+}
+
+func (i *intakeRecorder) ResumeIntake(ctx context.Context) (int, error) {
+	i.status = domain.IntakeStatus{}
+	return 0, nil
+}
+
+func (i *intakeRecorder) IntakeStatus() domain.IntakeStatus {
+	return i.status
+}
+
+func TestClient_StreamReviewEvents(t *testing.T) {
+	broker := progress.NewBroker()
+	mux := http.NewServeMux()
+	server := api.NewServer(nil, nil)
+	server.SetProgress(broker)
+	server.Register(mux)
+	srv := httptest.NewServer(mux)
+	defer srv.Close()
+
+	ctx, finish := broker.Start(context.Background(), "gitlab/group/app/3")
+	progress.Stage(ctx, progress.StageReview)
+
+	c := New(srv.URL, nil)
+	var events []progress.Event
+	err := c.StreamReviewEvents(context.Background(), "gitlab", "group", "app", "3", func(e progress.Event) {
+		events = append(events, e)
+		if e.Type == progress.EventStage {
+			progress.Emit(ctx, progress.Event{Type: progress.EventChunk, Chunk: 1, Chunks: 2, Findings: 4})
+			finish()
+		}
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(events) != 3 || events[1].Findings != 4 || events[2].Type != progress.EventDone {
+		t.Errorf("events = %+v", events)
+	}
+
+	var apiErr *Error
+	if err := c.StreamReviewEvents(context.Background(), "", "PROJ", "repo", "1", func(progress.Event) {}); !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusNotFound {
+		t.Errorf("not running: err = %v", err)
+	}
+}
@@ -0,0 +1,182 @@
+// Package budget enforces the spend limits of budgets: the tokens and USD cost of reviews per
+// UTC day and month, for all projects together and per project.
+package budget
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/storage"
+)
+
+// Scopes of a limit
+const (
+	ScopeGlobal  = "global"
+	ScopeProject = "project"
+)
+
+// Periods of a limit, starting at 00:00 UTC
+const (
+	PeriodDaily   = "daily"
+	PeriodMonthly = "monthly"
+)
+
+// Units of a limit
+const (
+	UnitTokens = "tokens"
+	UnitCost   = "cost" // USD
+)
+
+// Exceeded describes the limit a project reached
+type Exceeded struct {
+	Scope  string
+	Period string
+	Unit   string
+	Limit  float64
+	Spent  float64
+	Resets time.Time // Start of the next period
+}
+
+// Name returns the limit as named in the configuration, e.g. "daily_tokens"
+func (e *Exceeded) Name() string {
+	return e.Period + "_" + e.Unit
+}
+
+// String describes the limit, e.g. "project daily cost $10.02 of $10.00"
+func (e *Exceeded) String() string {
+	if e.Unit == UnitCost {
+		return fmt.Sprintf("%s %s cost $%.2f of $%.2f", e.Scope, e.Period, e.Spent, e.Limit)
+	}
+	return fmt.Sprintf("%s %s tokens %.0f of %.0f", e.Scope, e.Period, e.Spent, e.Limit)
+}
+
+// spend is the usage of one project in one period
+type spend struct {
+	tokens int64
+	cost   float64
+}
+
+// Manager tracks the spend of the current day and month by project. It starts empty; Load
+// seeds it from the stored reviews so a restart does not reset the budgets.
+type Manager struct {
+	cfg config.BudgetConfig
+	now func() time.Time
+
+	mu      sync.Mutex
+	day     time.Time // Start of the tracked day
+	month   time.Time // Start of the tracked month
+	daily   map[string]spend
+	monthly map[string]spend
+}
+
+// New creates a manager enforcing cfg
+func New(cfg config.BudgetConfig) *Manager {
+	m := &Manager{cfg: cfg, now: time.Now}
+	m.roll()
+	return m
+}
+
+// Load replaces the tracked spend with the spend of the reviews stored since the start of
+// the month
+func (m *Manager) Load(ctx context.Context, store storage.SpendRepository) error {
+	m.mu.Lock()
+	m.roll()
+	day, month := m.day, m.month
+	m.mu.Unlock()
+
+	monthly, err := store.ReviewSpend(ctx, month)
+	if err != nil {
+		return fmt.Errorf("load monthly spend: %w", err)
+	}
+	daily, err := store.ReviewSpend(ctx, day)
+	if err != nil {
+		return fmt.Errorf("load daily spend: %w", err)
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.daily, m.monthly = toSpend(daily), toSpend(monthly)
+	return nil
+}
+
+func toSpend(rows []*storage.ProjectSpend) map[string]spend {
+	spent := make(map[string]spend, len(rows))
+	for _, s := range rows {
+		spent[s.ProjectKey] = spend{tokens: s.Tokens, cost: s.Cost}
+	}
+	return spent
+}
+
+// Add records the usage of a finished review of the project
+func (m *Manager) Add(project string, tokens int64, cost float64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.roll()
+	for _, spent := range []map[string]spend{m.daily, m.monthly} {
+		s := spent[project]
+		s.tokens += tokens
+		s.cost += cost
+		spent[project] = s
+	}
+}
+
+// Check returns the first limit the project or all projects together reached, nil when a
+// review of the project may run
+func (m *Manager) Check(project string) *Exceeded {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.roll()
+
+	limits, ok := m.cfg.Projects[project]
+	if !ok {
+		limits = m.cfg.Project
+	}
+	if e := m.check(ScopeProject, limits, m.daily[project], m.monthly[project]); e != nil {
+		return e
+	}
+	return m.check(ScopeGlobal, m.cfg.Global, total(m.daily), total(m.monthly))
+}
+
+func (m *Manager) check(scope string, limits config.BudgetLimits, daily, monthly spend) *Exceeded {
+	nextDay, nextMonth := m.day.AddDate(0, 0, 1), m.month.AddDate(0, 1, 0)
+	for _, c := range []struct {
+		period, unit string
+		limit, spent float64
+		resets       time.Time
+	}{
+		{PeriodDaily, UnitTokens, float64(limits.DailyTokens), float64(daily.tokens), nextDay},
+		{PeriodDaily, UnitCost, limits.DailyCost, daily.cost, nextDay},
+		{PeriodMonthly, UnitTokens, float64(limits.MonthlyTokens), float64(monthly.tokens), nextMonth},
+		{PeriodMonthly, UnitCost, limits.MonthlyCost, monthly.cost, nextMonth},
+	} {
+		if c.limit > 0 && c.spent >= c.limit {
+			return &Exceeded{Scope: scope, Period: c.period, Unit: c.unit, Limit: c.limit, Spent: c.spent, Resets: c.resets}
+		}
+	}
+	return nil
+}
+
+func total(spent map[string]spend) spend {
+	var sum spend
+	for _, s := range spent {
+		sum.tokens += s.tokens
+		sum.cost += s.cost
+	}
+	return sum
+}
+
+// roll starts a new day or month when the clock passed into it; the caller holds mu
+func (m *Manager) roll() {
+	now := m.now().UTC()
+	day := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	if !day.Equal(m.day) {
+		m.day, m.daily = day, make(map[string]spend)
+	}
+	month := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.UTC)
+	if !month.Equal(m.month) {
+		m.month, m.monthly = month, make(map[string]spend)
+	}
+}
@@ -0,0 +1,66 @@
+package budget
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/storage"
+)
+
+type spendStore map[time.Time][]*storage.ProjectSpend
+
+func (s spendStore) ReviewSpend(ctx context.Context, since time.Time) ([]*storage.ProjectSpend, error) {
+	return s[since], nil
+}
+
+func TestManager(t *testing.T) {
+	now := time.Date(2026, 3, 31, 22, 0, 0, 0, time.UTC)
+	m := New(config.BudgetConfig{
+		Enabled:  true,
+		Global:   config.BudgetLimits{MonthlyCost: 101},
+		Project:  config.BudgetLimits{DailyTokens: 1000},
+		Projects: map[string]config.BudgetLimits{"PAY": {DailyCost: 5}},
+	})
+	m.now = func() time.Time { return now }
+
+	err := m.Load(context.Background(), spendStore{
+		time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC):  {{ProjectKey: "OPS", Tokens: 5000, Cost: 90}, {ProjectKey: "PAY", Tokens: 100, Cost: 4}},
+		time.Date(2026, 3, 31, 0, 0, 0, 0, time.UTC): {{ProjectKey: "OPS", Tokens: 900, Cost: 1}, {ProjectKey: "PAY", Tokens: 100, Cost: 4}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if e := m.Check("OPS"); e != nil {
+		t.Errorf("OPS within its budget: %v", e)
+	}
+	m.Add("OPS", 100, 1)
+	e := m.Check("OPS")
+	if e == nil || e.Scope != ScopeProject || e.Name() != "daily_tokens" || e.Spent != 1000 || !e.Resets.Equal(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)) {
+		t.Fatalf("OPS daily tokens: %+v", e)
+	}
+	// PAY has its own limits instead of budgets.project
+	if e := m.Check("PAY"); e != nil {
+		t.Errorf("PAY within its budget: %v", e)
+	}
+	m.Add("PAY", 5000, 5)
+	if e := m.Check("PAY"); e == nil || e.Scope != ScopeProject || e.Name() != "daily_cost" {
+		t.Errorf("PAY daily cost: %+v", e)
+	}
+	if e := m.Check("WEB"); e != nil {
+		t.Errorf("WEB within its budget: %v", e)
+	}
+	m.Add("WEB", 10, 1)
+	e = m.Check("WEB")
+	if e == nil || e.Scope != ScopeGlobal || e.Name() != "monthly_cost" || e.String() != "global monthly cost $101.00 of $101.00" {
+		t.Fatalf("global monthly cost: %+v", e)
+	}
+
+	// A new month resets both periods
+	now = now.Add(3 * time.Hour)
+	if e := m.Check("OPS"); e != nil {
+		t.Errorf("new month: %v", e)
+	}
+}
@@ -0,0 +1,207 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"pr-review-automation/internal/config"
+)
+
+// BitbucketCloudClient serves the Bitbucket tool vocabulary from the Bitbucket Cloud REST API (2.0).
+// projectKey is the workspace, repoSlug the repository slug. Cloud comments already use the
+// {"content": {"raw"}, "inline": {"path", "to"}} shape the processor reads.
+type BitbucketCloudClient struct {
+	baseURL    string
+	username   string
+	token      string
+	httpClient *http.Client
+}
+
+// NewBitbucketCloudClient creates a Bitbucket Cloud REST client
+func NewBitbucketCloudClient(cfg config.BitbucketCloudConfig) *BitbucketCloudClient {
+	baseURL := cfg.APIURL
+	if baseURL == "" {
+		baseURL = config.DefaultBitbucketCloudURL
+	}
+	return &BitbucketCloudClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		username:   cfg.Username,
+		token:      cfg.Token,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// CallTool executes a Bitbucket tool against Bitbucket Cloud
+func (c *BitbucketCloudClient) CallTool(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+	workspace, repo := argString(args, "projectKey"), argString(args, "repoSlug")
+	if workspace == "" || repo == "" {
+		return nil, fmt.Errorf("bitbucket cloud %s: projectKey (workspace) and repoSlug are required", toolName)
+	}
+	repoPath := "/repositories/" + url.PathEscape(workspace) + "/" + url.PathEscape(repo)
+	prPath := repoPath + "/pullrequests/" + argString(args, "pullRequestId")
+
+	switch toolName {
+	case config.ToolBitbucketGetPullRequest:
+		var pr map[string]any
+		err := c.do(ctx, http.MethodGet, prPath, nil, &pr)
+		return pr, err
+
+	case config.ToolBitbucketGetDiff:
+		var out bytes.Buffer
+		err := c.do(ctx, http.MethodGet, prPath+"/diff", nil, &out)
+		return out.String(), err
+
+	case config.ToolBitbucketGetChanges:
+		var stat struct {
+			Values []struct {
+				Status string `json:"status"`
+				New    *struct {
+					Path string `json:"path"`
+				} `json:"new"`
+				Old *struct {
+					Path string `json:"path"`
+				} `json:"old"`
+			} `json:"values"`
+		}
+		if err := c.do(ctx, http.MethodGet, prPath+"/diffstat?pagelen=100", nil, &stat); err != nil {
+			return nil, err
+		}
+		values := make([]map[string]any, 0, len(stat.Values))
+		for _, v := range stat.Values {
+			path := ""
+			if v.New != nil {
+				path = v.New.Path
+			} else if v.Old != nil {
+				path = v.Old.Path
+			}
+			values = append(values, map[string]any{
+				"path": map[string]any{"toString": path},
+				"type": strings.ToUpper(v.Status),
+			})
+		}
+		return map[string]any{"values": values}, nil
+
+	case config.ToolBitbucketGetFileContent:
+		at := argString(args, "at")
+		if at == "" {
+			at = "HEAD"
+		}
+		var out bytes.Buffer
+		err := c.do(ctx, http.MethodGet, repoPath+"/src/"+url.PathEscape(at)+"/"+escapePath(argString(args, "path")), nil, &out)
+		return out.String(), err
+
+	case config.ToolBitbucketGetComments:
+		return c.comments(ctx, prPath)
+
+	case config.ToolBitbucketAddComment:
+		return c.addComment(ctx, prPath, args)
+
+	case config.ToolBitbucketAddComments:
+		// No batch endpoint: post the comments one by one and stop at the first failure
+		items, _ := args["comments"].([]map[string]interface{})
+		for _, item := range items {
+			if _, err := c.addComment(ctx, prPath, item); err != nil {
+				return nil, err
+			}
+		}
+		return map[string]any{"count": len(items)}, nil
+
+	default:
+		return nil, fmt.Errorf("tool %s is not supported for bitbucket cloud", toolName)
+	}
+}
+
+// comments lists the PR comments without deleted ones
+func (c *BitbucketCloudClient) comments(ctx context.Context, prPath string) (any, error) {
+	var page struct {
+		Values []map[string]any `json:"values"`
+	}
+	if err := c.do(ctx, http.MethodGet, prPath+"/comments?pagelen=100", nil, &page); err != nil {
+		return nil, err
+	}
+	values := make([]map[string]any, 0, len(page.Values))
+	for _, v := range page.Values {
+		if deleted, _ := v["deleted"].(bool); deleted {
+			continue
+		}
+		values = append(values, v)
+	}
+	return map[string]any{"values": values}, nil
+}
+
+// addComment posts a general, file-level or inline comment and returns {"id": ...}.
+// REMOVED lines are anchored with "from" (the old file), all others with "to".
+func (c *BitbucketCloudClient) addComment(ctx context.Context, prPath string, args map[string]interface{}) (any, error) {
+	body := map[string]any{"content": map[string]any{"raw": argString(args, "commentText")}}
+	if filePath := argString(args, "filePath"); filePath != "" {
+		inline := map[string]any{"path": filePath}
+		if line, _ := strconv.Atoi(argString(args, "lineNumber")); line > 0 {
+			if argString(args, "lineType") == "REMOVED" {
+				inline["from"] = line
+			} else {
+				inline["to"] = line
+			}
+		}
+		body["inline"] = inline
+	}
+
+	var out struct {
+		ID int64 `json:"id"`
+	}
+	err := c.do(ctx, http.MethodPost, prPath+"/comments", body, &out)
+	return map[string]any{"id": out.ID}, err
+}
+
+// do sends a request; out is a *bytes.Buffer for raw responses or a JSON target
+func (c *BitbucketCloudClient) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal bitbucket cloud request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("create bitbucket cloud request: %w", err)
+	}
+	switch {
+	case c.username != "":
+		req.SetBasicAuth(c.username, c.token)
+	case c.token != "":
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("bitbucket cloud %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("bitbucket cloud %s %s: status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if buf, ok := out.(*bytes.Buffer); ok {
+		_, err = io.Copy(buf, resp.Body)
+		return err
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("decode bitbucket cloud response: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestBitbucketCloudClient_CallTool(t *testing.T) {
+	var posted map[string]any
+	var postedPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if user, pass, ok := r.BasicAuth(); !ok || user != "bot" || pass != "app-pass" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost:
+			postedPath, posted = r.URL.Path, nil
+			json.NewDecoder(r.Body).Decode(&posted)
+			w.Write([]byte(`{"id": 7}`))
+		case r.URL.Path == "/repositories/acme/api/pullrequests/42/diff":
+			w.Write([]byte("diff --git a/a.go b/a.go\n"))
+		case r.URL.Path == "/repositories/acme/api/pullrequests/42/diffstat":
+			w.Write([]byte(`{"values": [{"status": "modified", "new": {"path": "a.go"}}, {"status": "removed", "old": {"path": "b.go"}}]}`))
+		case r.URL.Path == "/repositories/acme/api/pullrequests/42/comments":
+			w.Write([]byte(`{"values": [
+				{"id": 1, "content": {"raw": "summary"}},
+				{"id": 2, "content": {"raw": "inline"}, "inline": {"path": "a.go", "to": 3}},
+				{"id": 3, "content": {"raw": ""}, "deleted": true}
+			]}`))
+		case r.URL.Path == "/repositories/acme/api/src/abc/dir/a.go":
+			w.Write([]byte("package a\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewBitbucketCloudClient(config.BitbucketCloudConfig{APIURL: srv.URL, Username: "bot", Token: "app-pass", Timeout: time.Second})
+	ctx := context.Background()
+	with := func(extra map[string]interface{}) map[string]interface{} {
+		args := map[string]interface{}{"projectKey": "acme", "repoSlug": "api", "pullRequestId": 42}
+		for k, v := range extra {
+			args[k] = v
+		}
+		return args
+	}
+
+	diff, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetDiff, with(nil))
+	if err != nil || diff != "diff --git a/a.go b/a.go\n" {
+		t.Errorf("diff = %q, %v", diff, err)
+	}
+
+	content, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetFileContent, with(map[string]interface{}{"path": "dir/a.go", "at": "abc"}))
+	if err != nil || content != "package a\n" {
+		t.Errorf("content = %q, %v", content, err)
+	}
+
+	changes, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetChanges, with(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(changes)
+	if got := gjson.GetBytes(data, "values.#.path.toString").String(); got != `["a.go","b.go"]` {
+		t.Errorf("changed files = %s", got)
+	}
+
+	comments, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetComments, with(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ = json.Marshal(comments)
+	if got := gjson.GetBytes(data, "values.#").Int(); got != 2 {
+		t.Errorf("deleted comments must be dropped: %s", data)
+	}
+	if got := gjson.GetBytes(data, "values.1.inline.path").String(); got != "a.go" {
+		t.Errorf("inline path = %q in %s", got, data)
+	}
+
+	res, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketAddComment,
+		with(map[string]interface{}{"commentText": "nil deref", "filePath": "a.go", "lineNumber": "3", "lineType": "REMOVED"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if postedPath != "/repositories/acme/api/pullrequests/42/comments" || gjson.Get(mustJSON(posted), "inline.from").Int() != 3 ||
+		gjson.Get(mustJSON(posted), "content.raw").String() != "nil deref" {
+		t.Errorf("inline comment posted to %s: %v", postedPath, posted)
+	}
+	if res.(map[string]any)["id"] != int64(7) {
+		t.Errorf("result = %v", res)
+	}
+
+	if _, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketAddComment, with(map[string]interface{}{"commentText": "summary"})); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := posted["inline"]; ok {
+		t.Errorf("general comment must not be inline: %v", posted)
+	}
+}
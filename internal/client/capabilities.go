@@ -0,0 +1,79 @@
+package client
+
+import (
+	"strings"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/llm"
+)
+
+// modelFamily holds the known capabilities of the models whose name starts with prefix
+type modelFamily struct {
+	prefix     string
+	tools      bool // Function calling, also when served by a local server
+	strict     bool // Strict JSON Schema output, on provider openai
+	vision     bool
+	maxContext int
+}
+
+// modelFamilies are matched in order against the lower-case model name without its
+// organization or registry prefix, so more specific prefixes come first
+var modelFamilies = []modelFamily{
+	{prefix: "gpt-4o", tools: true, strict: true, vision: true, maxContext: 128000},
+	{prefix: "gpt-4.1", tools: true, strict: true, vision: true, maxContext: 1047576},
+	{prefix: "gpt-4-turbo", tools: true, vision: true, maxContext: 128000},
+	{prefix: "gpt-5", tools: true, strict: true, vision: true, maxContext: 400000},
+	{prefix: "o3", tools: true, strict: true, vision: true, maxContext: 200000},
+	{prefix: "o4-mini", tools: true, strict: true, vision: true, maxContext: 200000},
+	{prefix: "qwen2.5-vl", vision: true, maxContext: 32768},
+	{prefix: "qwen2.5vl", vision: true, maxContext: 32768},
+	{prefix: "qwen2.5-coder", tools: true, maxContext: 32768},
+	{prefix: "qwen3-coder", tools: true, maxContext: 262144},
+	{prefix: "qwen3", tools: true, maxContext: 40960},
+	{prefix: "glm-4", tools: true, maxContext: 128000},
+	{prefix: "llama3.1", tools: true, maxContext: 131072},
+	{prefix: "llama3.2-vision", vision: true, maxContext: 131072},
+	{prefix: "llava", vision: true, maxContext: 4096},
+}
+
+// detectCapabilities returns the capabilities of model on a provider, with the declared ones
+// taking precedence. OpenAI endpoints call tools; local servers only for known model families.
+// Strict schemas are assumed only for known OpenAI models on provider openai.
+func detectCapabilities(provider, model string, declared config.LLMCapabilities) llm.Capabilities {
+	caps := llm.DefaultCapabilities
+	caps.Tools = provider != config.LLMProviderLocal
+
+	name := strings.ToLower(model)
+	if i := strings.LastIndex(name, "/"); i >= 0 {
+		name = name[i+1:]
+	}
+	for _, f := range modelFamilies {
+		if strings.HasPrefix(name, f.prefix) {
+			caps.Tools = caps.Tools || f.tools
+			caps.StrictSchema = f.strict && provider != config.LLMProviderLocal
+			caps.Vision = f.vision
+			caps.MaxContext = f.maxContext
+			break
+		}
+	}
+
+	if declared.Tools != nil {
+		caps.Tools = *declared.Tools
+	}
+	if declared.JSONSchema != nil {
+		caps.JSONSchema = *declared.JSONSchema
+	}
+	if declared.StrictSchema != nil {
+		caps.StrictSchema = *declared.StrictSchema
+	}
+	if declared.Vision != nil {
+		caps.Vision = *declared.Vision
+	}
+	if declared.Streaming != nil {
+		caps.Streaming = *declared.Streaming
+	}
+	if declared.MaxContext > 0 {
+		caps.MaxContext = declared.MaxContext
+	}
+	return caps
+}
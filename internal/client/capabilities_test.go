@@ -0,0 +1,55 @@
+package client
+
+import (
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/llm"
+)
+
+func TestDetectCapabilities(t *testing.T) {
+	no, yes := false, true
+	tests := []struct {
+		name     string
+		provider string
+		model    string
+		declared config.LLMCapabilities
+		want     llm.Capabilities
+	}{
+		{name: "openai known model", provider: config.LLMProviderOpenAI, model: "gpt-4o-mini",
+			want: llm.Capabilities{Tools: true, JSONSchema: true, StrictSchema: true, Vision: true, Streaming: true, MaxContext: 128000}},
+		{name: "openai unknown model", provider: config.LLMProviderOpenAI, model: "deepseek-chat",
+			want: llm.Capabilities{Tools: true, JSONSchema: true, Streaming: true}},
+		{name: "local known family", provider: config.LLMProviderLocal, model: "Qwen/Qwen3-Coder-30B-A3B-Instruct",
+			want: llm.Capabilities{Tools: true, JSONSchema: true, Streaming: true, MaxContext: 262144}},
+		{name: "local vision model", provider: config.LLMProviderLocal, model: "llava:13b",
+			want: llm.Capabilities{JSONSchema: true, Vision: true, Streaming: true, MaxContext: 4096}},
+		{name: "local unknown model", provider: config.LLMProviderLocal, model: "codestral",
+			want: llm.Capabilities{JSONSchema: true, Streaming: true}},
+		{name: "declared", provider: config.LLMProviderLocal, model: "codestral",
+			declared: config.LLMCapabilities{Tools: &yes, JSONSchema: &no, Vision: &yes, Streaming: &no, MaxContext: 32000},
+			want:     llm.Capabilities{Tools: true, Vision: true, MaxContext: 32000}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := detectCapabilities(tt.provider, tt.model, tt.declared); got != tt.want {
+				t.Errorf("detectCapabilities() = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestFallbackLLM_Capabilities(t *testing.T) {
+	var models []string
+	primary := scriptedAdapter(t, "gpt-4o", 200, "{}", &models)
+	primary.SetCapabilities(detectCapabilities(config.LLMProviderOpenAI, "gpt-4o", config.LLMCapabilities{}))
+	fallback := scriptedAdapter(t, "qwen2.5-coder", 200, "{}", &models)
+	fallback.SetCapabilities(detectCapabilities(config.LLMProviderLocal, "qwen2.5-coder", config.LLMCapabilities{}))
+	chain := NewFallbackLLM(primary, "gpt-4o", config.LLMProviderOpenAI)
+	chain.Add(fallback, "qwen2.5-coder", config.LLMProviderLocal)
+
+	want := llm.Capabilities{Tools: true, JSONSchema: true, StrictSchema: true, Streaming: true, MaxContext: 32768}
+	if got := llm.CapabilitiesOf(chain); got != want {
+		t.Errorf("capabilities = %+v, want %+v", got, want)
+	}
+}
@@ -0,0 +1,60 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"pr-review-automation/internal/metrics"
+)
+
+// abandonGrace is how long a call may keep running after its context is done before the
+// caller stops waiting for it
+var abandonGrace = time.Second
+
+// withDeadline runs call with ctx limited to timeout (no limit when timeout <= 0) and returns
+// soon after ctx is done even when call ignores it. The SDKs honour ctx, but a connection that
+// hangs mid-read must not hold a review worker: such a call is abandoned, left to finish in the
+// background, and its result dropped.
+func withDeadline[T any](ctx context.Context, kind string, timeout time.Duration, call func(context.Context) (T, error)) (T, error) {
+	if timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, timeout)
+		defer cancel()
+	}
+
+	type result struct {
+		value T
+		err   error
+	}
+	done := make(chan result, 1)
+	go func() {
+		var r result
+		defer func() {
+			if p := recover(); p != nil {
+				r.err = fmt.Errorf("%s call panicked: %v", kind, p)
+			}
+			done <- r
+		}()
+		r.value, r.err = call(ctx)
+	}()
+
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-ctx.Done():
+	}
+
+	grace := time.NewTimer(abandonGrace)
+	defer grace.Stop()
+	select {
+	case r := <-done:
+		return r.value, r.err
+	case <-grace.C:
+		metrics.CallsAbandoned.WithLabelValues(kind).Inc()
+		slog.Warn("call ignored cancellation, abandoned", "kind", kind, "error", ctx.Err())
+		var zero T
+		return zero, fmt.Errorf("%s call abandoned: %w", kind, ctx.Err())
+	}
+}
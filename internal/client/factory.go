@@ -2,6 +2,8 @@ package client
 
 import (
 	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/fault"
 	"pr-review-automation/internal/llm"
 
 	"github.com/openai/openai-go"
@@ -12,16 +14,92 @@ import (
 // IMPORTANT: The returned LLM instance is safe for concurrent use from multiple goroutines,
 // as long as its configuration (API key, endpoint) is NOT modified after creation.
 // This is the standard practice for http.Client based libraries.
+// With llm.fallbacks, the instance is a FallbackLLM trying them in order.
 func NewLLM(cfg *config.Config) (llm.Client, error) {
-	client := openai.NewClient(
-		option.WithAPIKey(cfg.LLM.APIKey),
-		option.WithBaseURL(cfg.LLM.Endpoint),
-	)
+	primary := newOpenAIAdapter(cfg, config.LLMTarget{
+		Provider:     cfg.LLM.Provider,
+		Model:        cfg.LLM.Model,
+		Endpoint:     cfg.LLM.Endpoint,
+		APIKey:       cfg.LLM.APIKey,
+		Capabilities: cfg.LLM.Capabilities,
+	})
+	if len(cfg.LLM.Fallbacks) == 0 {
+		return primary, nil
+	}
+	chain := NewFallbackLLM(primary, cfg.LLM.Model, cfg.LLM.Provider)
+	for _, t := range cfg.LLM.Fallbacks {
+		chain.Add(newOpenAIAdapter(cfg, t), t.Model, t.Provider)
+	}
+	return chain, nil
+}
+
+// ProviderBackends returns the REST backends of the enabled providers other than Bitbucket
+// Server, for MCPClient.SetProviderBackend
+func ProviderBackends(cfg *config.Config) map[string]ToolBackend {
+	backends := make(map[string]ToolBackend)
+	if cfg.GitHub.Enabled {
+		backends[domain.ProviderGitHub] = NewGitHubClient(cfg.GitHub)
+	}
+	if cfg.GitLab.Enabled {
+		backends[domain.ProviderGitLab] = NewGitLabClient(cfg.GitLab)
+	}
+	if cfg.Gitea.Enabled {
+		backends[domain.ProviderGitea] = NewGiteaClient(cfg.Gitea)
+	}
+	if cfg.BitbucketCloud.Enabled {
+		backends[domain.ProviderBitbucketCloud] = NewBitbucketCloudClient(cfg.BitbucketCloud)
+	}
+	return backends
+}
+
+// NewTargetLLM creates the LLM instance of a per-project route or fallback model. The
+// target's unset fields were filled from the llm section by LoadConfig.
+func NewTargetLLM(cfg *config.Config, t config.LLMTarget) (llm.Client, error) {
+	return newOpenAIAdapter(cfg, t), nil
+}
+
+// NewEmbedder creates the embedding client of pipeline.retrieval. The target's unset fields
+// were filled from the llm section by LoadConfig.
+func NewEmbedder(cfg *config.Config, t config.LLMTarget) *OpenAIAdapter {
+	return newOpenAIAdapter(cfg, t)
+}
+
+func newOpenAIAdapter(cfg *config.Config, t config.LLMTarget) *OpenAIAdapter {
+	opts := []option.RequestOption{
+		option.WithAPIKey(t.APIKey),
+		option.WithBaseURL(t.Endpoint),
+	}
+	if faults := fault.New(cfg.FaultInjection); faults != nil {
+		opts = append(opts, option.WithMiddleware(faults.LLMMiddleware(t.Model)))
+	}
+	client := openai.NewClient(opts...)
 	// Use NewOpenAIAdapterWithConfig to ensure endpoint and apiKey are stored for GetConfig()
 	// Unified Concurrency: Use Server.ConcurrencyLimit for LLM adapter
-	adapter := NewOpenAIAdapterWithConfig(&client, cfg.LLM.Model, cfg.LLM.Endpoint, cfg.LLM.APIKey, int(cfg.Server.ConcurrencyLimit))
-	if cfg.LLM.Timeout > 0 {
-		adapter.SetTimeout(cfg.LLM.Timeout)
+	adapter := NewOpenAIAdapterWithConfig(&client, t.Model, t.Endpoint, t.APIKey, int(cfg.Server.ConcurrencyLimit))
+	if timeout := cfg.LLMTimeoutFor(t.Provider); timeout > 0 {
+		adapter.SetTimeout(timeout)
+	}
+	adapter.SetParams(requestParams(cfg, t.Provider))
+	adapter.SetCapabilities(detectCapabilities(t.Provider, t.Model, t.Capabilities))
+	adapter.SetPricing(cfg.PricingFor)
+	return adapter
+}
+
+// requestParams returns llm.params, with Ollama's keep_alive added for local models unless
+// extra_body already sets it
+func requestParams(cfg *config.Config, provider string) config.LLMParams {
+	params := cfg.LLM.Params
+	if provider != config.LLMProviderLocal || cfg.LLM.Local.KeepAlive == "" {
+		return params
+	}
+	if _, set := params.ExtraBody["keep_alive"]; set {
+		return params
+	}
+	extra := make(map[string]any, len(params.ExtraBody)+1)
+	for k, v := range params.ExtraBody {
+		extra[k] = v
 	}
-	return adapter, nil
+	extra["keep_alive"] = cfg.LLM.Local.KeepAlive
+	params.ExtraBody = extra
+	return params
 }
@@ -0,0 +1,200 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/llm"
+	"pr-review-automation/internal/metrics"
+
+	"github.com/openai/openai-go"
+)
+
+// Fallback reasons, used as metric labels
+const (
+	fallbackRateLimit     = "rate_limit"
+	fallbackServerError   = "server_error"
+	fallbackContextLength = "context_length"
+)
+
+// contextLengthHints match the error messages of OpenAI, vLLM and llama.cpp for prompts
+// exceeding the model's context window
+var contextLengthHints = []string{"context length", "context_length", "maximum context", "context size", "context window", "too many tokens", "prompt is too long"}
+
+// FallbackLLM sends requests to the first of its models and, when a model fails with a rate
+// limit, a server error or a context-length error, to the next one (llm.fallbacks).
+// The returned completion's Model is the configured model that answered.
+type FallbackLLM struct {
+	models []fallbackModel
+}
+
+type fallbackModel struct {
+	name     string
+	provider string
+	client   llm.Client
+}
+
+// NewFallbackLLM creates a chain whose first model is primary
+func NewFallbackLLM(primary llm.Client, model, provider string) *FallbackLLM {
+	return &FallbackLLM{models: []fallbackModel{{name: model, provider: provider, client: primary}}}
+}
+
+// Add appends a fallback model to the chain
+func (f *FallbackLLM) Add(c llm.Client, model, provider string) {
+	f.models = append(f.models, fallbackModel{name: model, provider: provider, client: c})
+}
+
+// FallbackModels returns the models of the chain in the order they are tried
+func (f *FallbackLLM) FallbackModels() []string {
+	names := make([]string, len(f.models))
+	for i, m := range f.models {
+		names[i] = m.name
+	}
+	return names
+}
+
+// Capabilities returns what every model of the chain supports, since any of them may answer.
+// Streaming and JSON output follow the first model: ChatStream degrades to one delta for
+// models that cannot stream, and each adapter degrades a response_format its model lacks.
+func (f *FallbackLLM) Capabilities() llm.Capabilities {
+	primary := llm.CapabilitiesOf(f.models[0].client)
+	caps := primary
+	for _, m := range f.models[1:] {
+		caps = caps.Intersect(llm.CapabilitiesOf(m.client))
+	}
+	caps.Streaming, caps.JSONSchema, caps.StrictSchema = primary.Streaming, primary.JSONSchema, primary.StrictSchema
+	return caps
+}
+
+// Chat sends a chat completion request, falling back to the next model on failure
+func (f *FallbackLLM) Chat(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	return f.try(ctx, params, func(c llm.Client, p openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+		return c.Chat(ctx, p)
+	})
+}
+
+// ChatStream streams a chat completion, falling back to the next model on failure. Deltas of
+// a failed attempt have already been passed to onDelta. Models that cannot stream answer
+// with one delta holding the whole content.
+func (f *FallbackLLM) ChatStream(ctx context.Context, params openai.ChatCompletionNewParams, onDelta func(string)) (*openai.ChatCompletion, error) {
+	return f.try(ctx, params, func(c llm.Client, p openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+		if sc, ok := c.(llm.StreamingClient); ok {
+			return sc.ChatStream(ctx, p, onDelta)
+		}
+		resp, err := c.Chat(ctx, p)
+		if err == nil && len(resp.Choices) > 0 {
+			onDelta(resp.Choices[0].Message.Content)
+		}
+		return resp, err
+	})
+}
+
+func (f *FallbackLLM) try(ctx context.Context, params openai.ChatCompletionNewParams, call func(llm.Client, openai.ChatCompletionNewParams) (*openai.ChatCompletion, error)) (*openai.ChatCompletion, error) {
+	for i, m := range f.models {
+		p := params
+		model := m.name
+		if i == 0 && params.Model != "" {
+			model = string(params.Model) // Per-review model override
+		} else {
+			p.Model = openai.ChatModel(m.name)
+		}
+
+		resp, err := call(m.client, p)
+		if err == nil {
+			if i > 0 {
+				slog.Info("llm fallback answered", "model", model, "attempt", i+1)
+			}
+			resp.Model = model
+			return resp, nil
+		}
+
+		reason := fallbackReason(err)
+		if reason == "" || ctx.Err() != nil || i == len(f.models)-1 {
+			return nil, err
+		}
+		metrics.LLMFallbacks.WithLabelValues(model, reason).Inc()
+		slog.Warn("llm failed, trying fallback", "model", model, "next", f.models[i+1].name, "reason", reason, "error", err)
+	}
+	return nil, errors.New("no llm configured")
+}
+
+// fallbackReason returns why err should be retried with the next model, or "" if it should not
+func fallbackReason(err error) string {
+	var apiErr *openai.Error
+	if !errors.As(err, &apiErr) {
+		return ""
+	}
+	switch {
+	case apiErr.StatusCode == http.StatusTooManyRequests:
+		return fallbackRateLimit
+	case apiErr.StatusCode >= 500:
+		return fallbackServerError
+	case apiErr.StatusCode == http.StatusBadRequest || apiErr.StatusCode == http.StatusRequestEntityTooLarge:
+		if apiErr.Code == "context_length_exceeded" {
+			return fallbackContextLength
+		}
+		msg := strings.ToLower(apiErr.Message)
+		for _, hint := range contextLengthHints {
+			if strings.Contains(msg, hint) {
+				return fallbackContextLength
+			}
+		}
+	}
+	return ""
+}
+
+// SimpleTextQuery sends a single text request and returns the text response
+func (f *FallbackLLM) SimpleTextQuery(ctx context.Context, systemPrompt, userInput string) (string, error) {
+	var messages []openai.ChatCompletionMessageParamUnion
+	if systemPrompt != "" {
+		messages = append(messages, openai.SystemMessage(systemPrompt))
+	}
+	messages = append(messages, openai.UserMessage(userInput))
+
+	resp, err := f.Chat(ctx, openai.ChatCompletionNewParams{Messages: messages})
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("no openai response")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// Ping verifies the connection of the primary model
+func (f *FallbackLLM) Ping(ctx context.Context) error {
+	if checker, ok := f.models[0].client.(interface{ Ping(context.Context) error }); ok {
+		return checker.Ping(ctx)
+	}
+	return nil
+}
+
+// ListModels lists the models offered by the primary endpoint
+func (f *FallbackLLM) ListModels(ctx context.Context) ([]string, error) {
+	if lister, ok := f.models[0].client.(interface {
+		ListModels(context.Context) ([]string, error)
+	}); ok {
+		return lister.ListModels(ctx)
+	}
+	return nil, errors.ErrUnsupported
+}
+
+// ProbeJSONFormat probes the JSON response_format support of the local models of the chain
+func (f *FallbackLLM) ProbeJSONFormat(ctx context.Context) error {
+	var errs []error
+	for _, m := range f.models {
+		prober, ok := m.client.(interface{ ProbeJSONFormat(context.Context) error })
+		if !ok || m.provider != config.LLMProviderLocal {
+			continue
+		}
+		if err := prober.ProbeJSONFormat(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", m.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,92 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+// scriptedAdapter answers every request with code and body and records the requested models
+func scriptedAdapter(t *testing.T, model string, code int, body string, models *[]string) *OpenAIAdapter {
+	t.Helper()
+	mockClient := openai.NewClient(option.WithMaxRetries(0), option.WithHTTPClient(&http.Client{
+		Transport: &roundTripperFunc{func(req *http.Request) (*http.Response, error) {
+			var sent struct {
+				Model string `json:"model"`
+			}
+			json.NewDecoder(req.Body).Decode(&sent)
+			*models = append(*models, sent.Model)
+			return &http.Response{
+				StatusCode: code,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(body)),
+			}, nil
+		}},
+	}))
+	return NewOpenAIAdapterWithConfig(&mockClient, model, "http://test", "key", 1)
+}
+
+func TestFallbackLLM(t *testing.T) {
+	answer := `{"model": "served-name", "choices": [{"message": {"role": "assistant", "content": "ok"}}]}`
+	tests := []struct {
+		name         string
+		primaryCode  int
+		primaryBody  string
+		override     string
+		wantErr      bool
+		wantModel    string
+		wantRequests []string
+	}{
+		{name: "primary answers", primaryCode: http.StatusOK, primaryBody: answer, wantModel: "primary", wantRequests: []string{"primary"}},
+		{name: "override answers", primaryCode: http.StatusOK, primaryBody: answer, override: "qwen", wantModel: "qwen", wantRequests: []string{"qwen"}},
+		{name: "rate limit", primaryCode: http.StatusTooManyRequests, primaryBody: `{"error": {"message": "slow down"}}`, wantModel: "fallback", wantRequests: []string{"primary", "fallback"}},
+		{name: "server error", primaryCode: http.StatusBadGateway, primaryBody: `{"error": {"message": "upstream"}}`, wantModel: "fallback", wantRequests: []string{"primary", "fallback"}},
+		{
+			name:        "context length",
+			primaryCode: http.StatusBadRequest, primaryBody: `{"error": {"message": "This model's maximum context length is 8192 tokens."}}`,
+			wantModel: "fallback", wantRequests: []string{"primary", "fallback"},
+		},
+		{name: "bad request", primaryCode: http.StatusBadRequest, primaryBody: `{"error": {"message": "invalid messages"}}`, wantErr: true, wantRequests: []string{"primary"}},
+		{name: "unauthorized", primaryCode: http.StatusUnauthorized, primaryBody: `{"error": {"message": "bad key"}}`, wantErr: true, wantRequests: []string{"primary"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var requests []string
+			chain := NewFallbackLLM(scriptedAdapter(t, "primary", tt.primaryCode, tt.primaryBody, &requests), "primary", "openai")
+			chain.Add(scriptedAdapter(t, "fallback", http.StatusOK, answer, &requests), "fallback", "openai")
+
+			resp, err := chain.Chat(context.Background(), openai.ChatCompletionNewParams{
+				Model:    openai.ChatModel(tt.override),
+				Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("review")},
+			})
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err == nil && resp.Model != tt.wantModel {
+				t.Errorf("model = %q, want %q", resp.Model, tt.wantModel)
+			}
+			if strings.Join(requests, ",") != strings.Join(tt.wantRequests, ",") {
+				t.Errorf("requested models = %v, want %v", requests, tt.wantRequests)
+			}
+		})
+	}
+}
+
+func TestFallbackLLM_LastErrorReturned(t *testing.T) {
+	var requests []string
+	chain := NewFallbackLLM(scriptedAdapter(t, "primary", http.StatusServiceUnavailable, `{"error": {"message": "down"}}`, &requests), "primary", "openai")
+	chain.Add(scriptedAdapter(t, "fallback", http.StatusTooManyRequests, `{"error": {"message": "quota"}}`, &requests), "fallback", "openai")
+
+	_, err := chain.Chat(context.Background(), openai.ChatCompletionNewParams{
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("review")},
+	})
+	if err == nil || !strings.Contains(err.Error(), "quota") {
+		t.Errorf("expected the error of the last model, got %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"pr-review-automation/internal/config"
+)
+
+// GiteaClient serves the Bitbucket tool vocabulary from the Gitea API (v1), which Forgejo
+// shares. projectKey is the owner, repoSlug the repository and pullRequestId the pull
+// request index. Inline comments are posted as COMMENT reviews, the only way Gitea
+// accepts line comments; file-level comments become conversation comments naming the file.
+type GiteaClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewGiteaClient creates a Gitea API client
+func NewGiteaClient(cfg config.GiteaConfig) *GiteaClient {
+	return &GiteaClient{
+		baseURL:    strings.TrimRight(cfg.APIURL, "/"),
+		token:      cfg.Token,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// CallTool executes a Bitbucket tool against Gitea
+func (c *GiteaClient) CallTool(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+	owner, repo := argString(args, "projectKey"), argString(args, "repoSlug")
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("gitea %s: projectKey (owner) and repoSlug are required", toolName)
+	}
+	repoPath := "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+	index := argString(args, "pullRequestId")
+
+	switch toolName {
+	case config.ToolBitbucketGetPullRequest:
+		var pr map[string]any
+		err := c.do(ctx, http.MethodGet, repoPath+"/pulls/"+index, nil, &pr)
+		return pr, err
+
+	case config.ToolBitbucketGetDiff:
+		var out bytes.Buffer
+		err := c.do(ctx, http.MethodGet, repoPath+"/pulls/"+index+".diff", nil, &out)
+		return out.String(), err
+
+	case config.ToolBitbucketGetFileContent:
+		path := repoPath + "/raw/" + escapePath(argString(args, "path"))
+		if at := argString(args, "at"); at != "" {
+			path += "?ref=" + url.QueryEscape(at)
+		}
+		var out bytes.Buffer
+		err := c.do(ctx, http.MethodGet, path, nil, &out)
+		return out.String(), err
+
+	case config.ToolBitbucketGetChanges:
+		var files []struct {
+			Filename string `json:"filename"`
+			Status   string `json:"status"`
+		}
+		if err := c.do(ctx, http.MethodGet, repoPath+"/pulls/"+index+"/files?limit=100", nil, &files); err != nil {
+			return nil, err
+		}
+		values := make([]map[string]any, 0, len(files))
+		for _, f := range files {
+			values = append(values, map[string]any{
+				"path": map[string]any{"toString": f.Filename},
+				"type": strings.ToUpper(f.Status),
+			})
+		}
+		return map[string]any{"values": values}, nil
+
+	case config.ToolBitbucketGetComments:
+		return c.comments(ctx, repoPath, index)
+
+	case config.ToolBitbucketAddComment:
+		if argString(args, "filePath") == "" || argString(args, "lineNumber") == "" {
+			return c.addIssueComment(ctx, repoPath, index, args)
+		}
+		return c.addReview(ctx, repoPath, index, []map[string]interface{}{args})
+
+	case config.ToolBitbucketAddComments:
+		items, _ := args["comments"].([]map[string]interface{})
+		return c.addReview(ctx, repoPath, index, items)
+
+	default:
+		return nil, fmt.Errorf("tool %s is not supported for gitea", toolName)
+	}
+}
+
+// comments lists conversation and review comments as
+// {"values": [{"id": ..., "content": {"raw": ...}, "inline": {"path": ..., "to": ...}}]}
+func (c *GiteaClient) comments(ctx context.Context, repoPath, index string) (any, error) {
+	var general []struct {
+		ID   int64  `json:"id"`
+		Body string `json:"body"`
+	}
+	if err := c.do(ctx, http.MethodGet, repoPath+"/issues/"+index+"/comments?limit=100", nil, &general); err != nil {
+		return nil, err
+	}
+	values := make([]map[string]any, 0, len(general))
+	for _, g := range general {
+		values = append(values, map[string]any{"id": g.ID, "content": map[string]any{"raw": g.Body}})
+	}
+
+	// Line comments are only listed per review
+	var reviews []struct {
+		ID            int64 `json:"id"`
+		CommentsCount int   `json:"comments_count"`
+	}
+	if err := c.do(ctx, http.MethodGet, repoPath+"/pulls/"+index+"/reviews?limit=100", nil, &reviews); err != nil {
+		return nil, err
+	}
+	for _, r := range reviews {
+		if r.CommentsCount == 0 {
+			continue
+		}
+		var inline []struct {
+			ID       int64  `json:"id"`
+			Body     string `json:"body"`
+			Path     string `json:"path"`
+			Position int    `json:"position"`
+		}
+		path := repoPath + "/pulls/" + index + "/reviews/" + strconv.FormatInt(r.ID, 10) + "/comments"
+		if err := c.do(ctx, http.MethodGet, path, nil, &inline); err != nil {
+			return nil, err
+		}
+		for _, i := range inline {
+			values = append(values, map[string]any{
+				"id":      i.ID,
+				"content": map[string]any{"raw": i.Body},
+				"inline":  map[string]any{"path": i.Path, "to": i.Position},
+			})
+		}
+	}
+	return map[string]any{"values": values}, nil
+}
+
+// addIssueComment posts a conversation comment; a file-level comment is prefixed with the file
+func (c *GiteaClient) addIssueComment(ctx context.Context, repoPath, index string, args map[string]interface{}) (any, error) {
+	body := argString(args, "commentText")
+	if filePath := argString(args, "filePath"); filePath != "" {
+		body = fmt.Sprintf("`%s`\n\n%s", filePath, body)
+	}
+	var out struct {
+		ID int64 `json:"id"`
+	}
+	err := c.do(ctx, http.MethodPost, repoPath+"/issues/"+index+"/comments", map[string]any{"body": body}, &out)
+	return map[string]any{"id": out.ID}, err
+}
+
+// addReview posts line comments as one COMMENT review. REMOVED lines are anchored to the old file.
+func (c *GiteaClient) addReview(ctx context.Context, repoPath, index string, items []map[string]interface{}) (any, error) {
+	comments := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		rc := map[string]any{
+			"body": argString(item, "commentText"),
+			"path": argString(item, "filePath"),
+		}
+		line, _ := strconv.Atoi(argString(item, "lineNumber"))
+		if argString(item, "lineType") == "REMOVED" {
+			rc["old_position"] = line
+		} else {
+			rc["new_position"] = line
+		}
+		comments = append(comments, rc)
+	}
+
+	var out struct {
+		ID int64 `json:"id"`
+	}
+	err := c.do(ctx, http.MethodPost, repoPath+"/pulls/"+index+"/reviews", map[string]any{
+		"event":    "COMMENT",
+		"comments": comments,
+	}, &out)
+	return map[string]any{"id": out.ID}, err
+}
+
+// do sends a request; out is a *bytes.Buffer for raw responses or a JSON target
+func (c *GiteaClient) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal gitea request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("create gitea request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "token "+c.token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("gitea %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("gitea %s %s: status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if buf, ok := out.(*bytes.Buffer); ok {
+		_, err = io.Copy(buf, resp.Body)
+		return err
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("decode gitea response: %w", err)
+		}
+	}
+	return nil
+}
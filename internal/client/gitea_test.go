@@ -0,0 +1,105 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestGiteaClient_CallTool(t *testing.T) {
+	var posted map[string]any
+	var postedPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "token tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost:
+			postedPath = r.URL.Path
+			json.NewDecoder(r.Body).Decode(&posted)
+			w.Write([]byte(`{"id": 7}`))
+		case r.URL.Path == "/api/v1/repos/acme/api/pulls/42.diff":
+			w.Write([]byte("diff --git a/a.go b/a.go\n"))
+		case r.URL.Path == "/api/v1/repos/acme/api/issues/42/comments":
+			w.Write([]byte(`[{"id": 1, "body": "summary"}]`))
+		case r.URL.Path == "/api/v1/repos/acme/api/pulls/42/reviews":
+			w.Write([]byte(`[{"id": 5, "comments_count": 1}, {"id": 6, "comments_count": 0}]`))
+		case r.URL.Path == "/api/v1/repos/acme/api/pulls/42/reviews/5/comments":
+			w.Write([]byte(`[{"id": 2, "body": "inline", "path": "a.go", "position": 3}]`))
+		case r.URL.Path == "/api/v1/repos/acme/api/raw/dir/a.go" && r.URL.Query().Get("ref") == "abc":
+			w.Write([]byte("package a\n"))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewGiteaClient(config.GiteaConfig{APIURL: srv.URL + "/api/v1/", Token: "tok", Timeout: time.Second})
+	ctx := context.Background()
+	with := func(extra map[string]interface{}) map[string]interface{} {
+		args := map[string]interface{}{"projectKey": "acme", "repoSlug": "api", "pullRequestId": 42}
+		for k, v := range extra {
+			args[k] = v
+		}
+		return args
+	}
+
+	diff, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetDiff, with(nil))
+	if err != nil || diff != "diff --git a/a.go b/a.go\n" {
+		t.Errorf("diff = %q, %v", diff, err)
+	}
+
+	content, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetFileContent, with(map[string]interface{}{"path": "dir/a.go", "at": "abc"}))
+	if err != nil || content != "package a\n" {
+		t.Errorf("content = %q, %v", content, err)
+	}
+
+	comments, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetComments, with(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(comments)
+	if got := gjson.GetBytes(data, "values.#").Int(); got != 2 {
+		t.Fatalf("comments = %s", data)
+	}
+	if got := gjson.GetBytes(data, "values.1.inline.path").String(); got != "a.go" {
+		t.Errorf("inline path = %q in %s", got, data)
+	}
+
+	res, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketAddComment,
+		with(map[string]interface{}{"commentText": "nil deref", "filePath": "a.go", "lineNumber": "3", "lineType": "REMOVED"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if postedPath != "/api/v1/repos/acme/api/pulls/42/reviews" || posted["event"] != "COMMENT" ||
+		gjson.Get(mustJSON(posted), "comments.0.old_position").Int() != 3 {
+		t.Errorf("inline comment posted to %s: %v", postedPath, posted)
+	}
+	if res.(map[string]any)["id"] != int64(7) {
+		t.Errorf("result = %v", res)
+	}
+
+	if _, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketAddComment, with(map[string]interface{}{"commentText": "looks odd", "filePath": "a.go"})); err != nil {
+		t.Fatal(err)
+	}
+	if postedPath != "/api/v1/repos/acme/api/issues/42/comments" || posted["body"] != "`a.go`\n\nlooks odd" {
+		t.Errorf("file comment posted to %s: %v", postedPath, posted)
+	}
+
+	if _, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketAddTask, with(nil)); err == nil {
+		t.Error("expected error for unsupported tool")
+	}
+}
+
+func mustJSON(v any) string {
+	data, _ := json.Marshal(v)
+	return string(data)
+}
@@ -0,0 +1,296 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"pr-review-automation/internal/config"
+)
+
+// GitHubClient serves the Bitbucket tool vocabulary used by the processor and pipeline
+// from the GitHub REST API, so GitHub pull requests run through the same review code.
+// Arguments keep their Bitbucket names: projectKey is the owner, repoSlug the repository
+// and pullRequestId the pull request number. Responses mimic the Bitbucket MCP shapes.
+type GitHubClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewGitHubClient creates a GitHub REST client
+func NewGitHubClient(cfg config.GitHubConfig) *GitHubClient {
+	baseURL := cfg.APIURL
+	if baseURL == "" {
+		baseURL = config.DefaultGitHubAPIURL
+	}
+	return &GitHubClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      cfg.Token,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// Accept headers for raw responses
+const (
+	githubAcceptJSON = "application/vnd.github+json"
+	githubAcceptDiff = "application/vnd.github.diff"
+	githubAcceptRaw  = "application/vnd.github.raw"
+)
+
+// CallTool executes a Bitbucket tool against GitHub
+func (c *GitHubClient) CallTool(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+	owner, repo := argString(args, "projectKey"), argString(args, "repoSlug")
+	if owner == "" || repo == "" {
+		return nil, fmt.Errorf("github %s: projectKey (owner) and repoSlug are required", toolName)
+	}
+	repoPath := "/repos/" + url.PathEscape(owner) + "/" + url.PathEscape(repo)
+	number := argString(args, "pullRequestId")
+
+	switch toolName {
+	case config.ToolBitbucketGetPullRequest:
+		var pr map[string]any
+		err := c.do(ctx, http.MethodGet, repoPath+"/pulls/"+number, githubAcceptJSON, nil, &pr)
+		return pr, err
+
+	case config.ToolBitbucketGetDiff:
+		return c.text(ctx, repoPath+"/pulls/"+number, githubAcceptDiff)
+
+	case config.ToolBitbucketGetFileContent:
+		path := repoPath + "/contents/" + escapePath(argString(args, "path"))
+		if at := argString(args, "at"); at != "" {
+			path += "?ref=" + url.QueryEscape(at)
+		}
+		return c.text(ctx, path, githubAcceptRaw)
+
+	case config.ToolBitbucketGetChanges:
+		return c.changes(ctx, repoPath, number)
+
+	case config.ToolBitbucketGetComments:
+		return c.comments(ctx, repoPath, number)
+
+	case config.ToolBitbucketAddComment:
+		return c.addComment(ctx, repoPath, number, args)
+
+	case config.ToolBitbucketAddComments:
+		return c.addReview(ctx, repoPath, number, args)
+
+	default:
+		return nil, fmt.Errorf("tool %s is not supported for github", toolName)
+	}
+}
+
+// changes lists the changed files as {"values": [{"path": {"toString": ...}}]}
+func (c *GitHubClient) changes(ctx context.Context, repoPath, number string) (any, error) {
+	var files []struct {
+		Filename string `json:"filename"`
+		Status   string `json:"status"`
+	}
+	if err := c.do(ctx, http.MethodGet, repoPath+"/pulls/"+number+"/files?per_page=100", githubAcceptJSON, nil, &files); err != nil {
+		return nil, err
+	}
+	values := make([]map[string]any, 0, len(files))
+	for _, f := range files {
+		values = append(values, map[string]any{
+			"path": map[string]any{"toString": f.Filename},
+			"type": strings.ToUpper(f.Status),
+		})
+	}
+	return map[string]any{"values": values}, nil
+}
+
+// comments lists conversation and review comments as
+// {"values": [{"id": ..., "content": {"raw": ...}, "inline": {"path": ..., "to": ...}}]}
+func (c *GitHubClient) comments(ctx context.Context, repoPath, number string) (any, error) {
+	var general []struct {
+		ID   int64  `json:"id"`
+		Body string `json:"body"`
+	}
+	if err := c.do(ctx, http.MethodGet, repoPath+"/issues/"+number+"/comments?per_page=100", githubAcceptJSON, nil, &general); err != nil {
+		return nil, err
+	}
+	var inline []struct {
+		ID   int64  `json:"id"`
+		Body string `json:"body"`
+		Path string `json:"path"`
+		Line int    `json:"line"`
+	}
+	if err := c.do(ctx, http.MethodGet, repoPath+"/pulls/"+number+"/comments?per_page=100", githubAcceptJSON, nil, &inline); err != nil {
+		return nil, err
+	}
+
+	values := make([]map[string]any, 0, len(general)+len(inline))
+	for _, g := range general {
+		values = append(values, map[string]any{"id": g.ID, "content": map[string]any{"raw": g.Body}})
+	}
+	for _, i := range inline {
+		values = append(values, map[string]any{
+			"id":      i.ID,
+			"content": map[string]any{"raw": i.Body},
+			"inline":  map[string]any{"path": i.Path, "to": i.Line},
+		})
+	}
+	return map[string]any{"values": values}, nil
+}
+
+// addComment posts an inline, file-level or conversation comment and returns {"id": ...}
+func (c *GitHubClient) addComment(ctx context.Context, repoPath, number string, args map[string]interface{}) (any, error) {
+	body := argString(args, "commentText")
+	filePath := argString(args, "filePath")
+
+	var out struct {
+		ID int64 `json:"id"`
+	}
+	if filePath == "" {
+		err := c.do(ctx, http.MethodPost, repoPath+"/issues/"+number+"/comments", githubAcceptJSON, map[string]any{"body": body}, &out)
+		return map[string]any{"id": out.ID}, err
+	}
+
+	headSHA, err := c.headSHA(ctx, repoPath, number)
+	if err != nil {
+		return nil, err
+	}
+	err = c.do(ctx, http.MethodPost, repoPath+"/pulls/"+number+"/comments", githubAcceptJSON, reviewComment(args, headSHA), &out)
+	return map[string]any{"id": out.ID}, err
+}
+
+// addReview posts a batch of inline comments as one review, so reviewers get a single notification
+func (c *GitHubClient) addReview(ctx context.Context, repoPath, number string, args map[string]interface{}) (any, error) {
+	items, _ := args["comments"].([]map[string]interface{})
+	headSHA, err := c.headSHA(ctx, repoPath, number)
+	if err != nil {
+		return nil, err
+	}
+
+	comments := make([]map[string]any, 0, len(items))
+	for _, item := range items {
+		rc := reviewComment(item, "")
+		delete(rc, "commit_id")
+		comments = append(comments, rc)
+	}
+	var out struct {
+		ID int64 `json:"id"`
+	}
+	err = c.do(ctx, http.MethodPost, repoPath+"/pulls/"+number+"/reviews", githubAcceptJSON, map[string]any{
+		"commit_id": headSHA,
+		"event":     "COMMENT",
+		"comments":  comments,
+	}, &out)
+	return map[string]any{"id": out.ID}, err
+}
+
+// reviewComment maps Bitbucket comment arguments to a GitHub review comment.
+// Without a line the comment is attached to the file; REMOVED lines are on the LEFT side.
+func reviewComment(args map[string]interface{}, commitID string) map[string]any {
+	rc := map[string]any{
+		"body":      argString(args, "commentText"),
+		"path":      argString(args, "filePath"),
+		"commit_id": commitID,
+	}
+	line, _ := strconv.Atoi(argString(args, "lineNumber"))
+	if line <= 0 {
+		rc["subject_type"] = "file"
+		return rc
+	}
+	rc["line"] = line
+	rc["side"] = "RIGHT"
+	if argString(args, "lineType") == "REMOVED" {
+		rc["side"] = "LEFT"
+	}
+	return rc
+}
+
+func (c *GitHubClient) headSHA(ctx context.Context, repoPath, number string) (string, error) {
+	var pr struct {
+		Head struct {
+			SHA string `json:"sha"`
+		} `json:"head"`
+	}
+	if err := c.do(ctx, http.MethodGet, repoPath+"/pulls/"+number, githubAcceptJSON, nil, &pr); err != nil {
+		return "", err
+	}
+	return pr.Head.SHA, nil
+}
+
+func (c *GitHubClient) text(ctx context.Context, path, accept string) (string, error) {
+	var out bytes.Buffer
+	err := c.do(ctx, http.MethodGet, path, accept, nil, &out)
+	return out.String(), err
+}
+
+// do sends a request; out is a *bytes.Buffer for raw responses or a JSON target
+func (c *GitHubClient) do(ctx context.Context, method, path, accept string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal github request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("create github request: %w", err)
+	}
+	req.Header.Set("Accept", accept)
+	req.Header.Set("X-GitHub-Api-Version", "2022-11-28")
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("github %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("github %s %s: status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if buf, ok := out.(*bytes.Buffer); ok {
+		_, err = io.Copy(buf, resp.Body)
+		return err
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("decode github response: %w", err)
+		}
+	}
+	return nil
+}
+
+// argString returns a tool argument as a string; numbers are formatted without decimals
+func argString(args map[string]interface{}, key string) string {
+	switch v := args[key].(type) {
+	case string:
+		return v
+	case int:
+		return strconv.Itoa(v)
+	case int64:
+		return strconv.FormatInt(v, 10)
+	case float64:
+		return strconv.FormatInt(int64(v), 10)
+	default:
+		return ""
+	}
+}
+
+// escapePath escapes each segment of a repository file path
+func escapePath(p string) string {
+	parts := strings.Split(strings.TrimPrefix(p, "/"), "/")
+	for i, part := range parts {
+		parts[i] = url.PathEscape(part)
+	}
+	return strings.Join(parts, "/")
+}
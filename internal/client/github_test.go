@@ -0,0 +1,130 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestGitHubClient_CallTool(t *testing.T) {
+	var posted map[string]any
+	var postedPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch {
+		case r.Method == http.MethodPost:
+			postedPath = r.URL.Path
+			json.NewDecoder(r.Body).Decode(&posted)
+			w.Write([]byte(`{"id": 7}`))
+		case r.URL.Path == "/repos/acme/api/pulls/42" && r.Header.Get("Accept") == githubAcceptDiff:
+			w.Write([]byte("diff --git a/a.go b/a.go\n"))
+		case r.URL.Path == "/repos/acme/api/pulls/42":
+			w.Write([]byte(`{"head": {"sha": "abc123"}}`))
+		case r.URL.Path == "/repos/acme/api/issues/42/comments":
+			w.Write([]byte(`[{"id": 1, "body": "summary"}]`))
+		case r.URL.Path == "/repos/acme/api/pulls/42/comments":
+			w.Write([]byte(`[{"id": 2, "body": "inline", "path": "a.go", "line": 3}]`))
+		case r.URL.Path == "/repos/acme/api/pulls/42/files":
+			w.Write([]byte(`[{"filename": "a.go", "status": "modified"}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewGitHubClient(config.GitHubConfig{APIURL: srv.URL, Token: "tok", Timeout: time.Second})
+	ctx := context.Background()
+	pr := map[string]interface{}{"projectKey": "acme", "repoSlug": "api", "pullRequestId": 42}
+	with := func(extra map[string]interface{}) map[string]interface{} {
+		args := map[string]interface{}{}
+		for k, v := range pr {
+			args[k] = v
+		}
+		for k, v := range extra {
+			args[k] = v
+		}
+		return args
+	}
+
+	diff, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetDiff, pr)
+	if err != nil || diff != "diff --git a/a.go b/a.go\n" {
+		t.Errorf("diff = %q, %v", diff, err)
+	}
+
+	comments, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetComments, pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(comments)
+	if got := gjson.GetBytes(data, "values.1.inline.path").String(); got != "a.go" {
+		t.Errorf("inline path = %q in %s", got, data)
+	}
+	if got := gjson.GetBytes(data, "values.0.content.raw").String(); got != "summary" {
+		t.Errorf("general comment = %q", got)
+	}
+
+	changes, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetChanges, pr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ = json.Marshal(changes)
+	if got := gjson.GetBytes(data, "values.0.path.toString").String(); got != "a.go" {
+		t.Errorf("changed file = %q", got)
+	}
+
+	res, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketAddComment,
+		with(map[string]interface{}{"commentText": "nil deref", "filePath": "a.go", "lineNumber": "3", "lineType": "REMOVED"}))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if postedPath != "/repos/acme/api/pulls/42/comments" || posted["commit_id"] != "abc123" || posted["side"] != "LEFT" || posted["line"] != float64(3) {
+		t.Errorf("inline comment posted to %s: %v", postedPath, posted)
+	}
+	if res.(map[string]any)["id"] != int64(7) {
+		t.Errorf("result = %v", res)
+	}
+
+	if _, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketAddComment, with(map[string]interface{}{"commentText": "summary"})); err != nil {
+		t.Fatal(err)
+	}
+	if postedPath != "/repos/acme/api/issues/42/comments" || posted["body"] != "summary" {
+		t.Errorf("summary posted to %s: %v", postedPath, posted)
+	}
+
+	if _, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketAddTask, pr); err == nil {
+		t.Error("expected error for unsupported tool")
+	}
+}
+
+func TestMCPClient_ProviderBackend(t *testing.T) {
+	m := NewMCPClient(&config.Config{})
+	m.SetProviderBackend(domain.ProviderGitHub, backendFunc(func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+		return "from github", nil
+	}))
+
+	got, err := m.CallTool(domain.WithProvider(context.Background(), domain.ProviderGitHub), config.MCPServerBitbucket, config.ToolBitbucketGetDiff, nil)
+	if err != nil || got != "from github" {
+		t.Errorf("got %v, %v", got, err)
+	}
+
+	if _, err := m.CallTool(domain.WithProvider(context.Background(), "gitea"), config.MCPServerBitbucket, config.ToolBitbucketGetDiff, nil); err == nil {
+		t.Error("expected error for provider without backend")
+	}
+}
+
+type backendFunc func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error)
+
+func (f backendFunc) CallTool(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+	return f(ctx, serverName, toolName, args)
+}
@@ -0,0 +1,262 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"pr-review-automation/internal/config"
+)
+
+// GitLabClient serves the Bitbucket tool vocabulary from the GitLab REST API (v4).
+// projectKey is the namespace (group path), repoSlug the project path and
+// pullRequestId the merge request IID. Inline comments are posted as discussion
+// threads anchored to the diff line.
+type GitLabClient struct {
+	baseURL    string
+	token      string
+	httpClient *http.Client
+}
+
+// NewGitLabClient creates a GitLab REST client
+func NewGitLabClient(cfg config.GitLabConfig) *GitLabClient {
+	baseURL := cfg.APIURL
+	if baseURL == "" {
+		baseURL = config.DefaultGitLabAPIURL
+	}
+	return &GitLabClient{
+		baseURL:    strings.TrimRight(baseURL, "/"),
+		token:      cfg.Token,
+		httpClient: &http.Client{Timeout: cfg.Timeout},
+	}
+}
+
+// gitlabDiff is one file of a merge request diff
+type gitlabDiff struct {
+	OldPath     string `json:"old_path"`
+	NewPath     string `json:"new_path"`
+	Diff        string `json:"diff"`
+	NewFile     bool   `json:"new_file"`
+	DeletedFile bool   `json:"deleted_file"`
+	RenamedFile bool   `json:"renamed_file"`
+}
+
+// CallTool executes a Bitbucket tool against GitLab
+func (c *GitLabClient) CallTool(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+	namespace, project := argString(args, "projectKey"), argString(args, "repoSlug")
+	if namespace == "" || project == "" {
+		return nil, fmt.Errorf("gitlab %s: projectKey (namespace) and repoSlug are required", toolName)
+	}
+	projectPath := "/projects/" + url.PathEscape(namespace+"/"+project)
+	mrPath := projectPath + "/merge_requests/" + argString(args, "pullRequestId")
+
+	switch toolName {
+	case config.ToolBitbucketGetPullRequest:
+		var mr map[string]any
+		err := c.do(ctx, http.MethodGet, mrPath, nil, &mr)
+		return mr, err
+
+	case config.ToolBitbucketGetDiff:
+		diffs, err := c.diffs(ctx, mrPath)
+		if err != nil {
+			return nil, err
+		}
+		return unifiedDiff(diffs), nil
+
+	case config.ToolBitbucketGetChanges:
+		diffs, err := c.diffs(ctx, mrPath)
+		if err != nil {
+			return nil, err
+		}
+		values := make([]map[string]any, 0, len(diffs))
+		for _, d := range diffs {
+			values = append(values, map[string]any{"path": map[string]any{"toString": d.NewPath}})
+		}
+		return map[string]any{"values": values}, nil
+
+	case config.ToolBitbucketGetFileContent:
+		path := projectPath + "/repository/files/" + url.PathEscape(strings.TrimPrefix(argString(args, "path"), "/")) + "/raw"
+		if at := argString(args, "at"); at != "" {
+			path += "?ref=" + url.QueryEscape(at)
+		}
+		var out bytes.Buffer
+		err := c.do(ctx, http.MethodGet, path, nil, &out)
+		return out.String(), err
+
+	case config.ToolBitbucketGetComments:
+		return c.notes(ctx, mrPath)
+
+	case config.ToolBitbucketAddComment:
+		return c.addComment(ctx, mrPath, args)
+
+	case config.ToolBitbucketAddComments:
+		// No batch endpoint: post the threads one by one and stop at the first failure
+		items, _ := args["comments"].([]map[string]interface{})
+		for _, item := range items {
+			if _, err := c.addComment(ctx, mrPath, item); err != nil {
+				return nil, err
+			}
+		}
+		return map[string]any{"count": len(items)}, nil
+
+	default:
+		return nil, fmt.Errorf("tool %s is not supported for gitlab", toolName)
+	}
+}
+
+func (c *GitLabClient) diffs(ctx context.Context, mrPath string) ([]gitlabDiff, error) {
+	var diffs []gitlabDiff
+	err := c.do(ctx, http.MethodGet, mrPath+"/diffs?per_page=100", nil, &diffs)
+	return diffs, err
+}
+
+// unifiedDiff joins per-file GitLab diffs into a git-style unified diff
+func unifiedDiff(diffs []gitlabDiff) string {
+	var b strings.Builder
+	for _, d := range diffs {
+		fmt.Fprintf(&b, "diff --git a/%s b/%s\n", d.OldPath, d.NewPath)
+		from, to := "a/"+d.OldPath, "b/"+d.NewPath
+		if d.NewFile {
+			from = "/dev/null"
+		}
+		if d.DeletedFile {
+			to = "/dev/null"
+		}
+		fmt.Fprintf(&b, "--- %s\n+++ %s\n", from, to)
+		b.WriteString(d.Diff)
+		if !strings.HasSuffix(d.Diff, "\n") {
+			b.WriteString("\n")
+		}
+	}
+	return b.String()
+}
+
+// notes lists merge request notes as
+// {"values": [{"id": ..., "content": {"raw": ...}, "inline": {"path": ..., "to": ...}}]}
+func (c *GitLabClient) notes(ctx context.Context, mrPath string) (any, error) {
+	var notes []struct {
+		ID       int64  `json:"id"`
+		Body     string `json:"body"`
+		System   bool   `json:"system"`
+		Position *struct {
+			NewPath string `json:"new_path"`
+			NewLine int    `json:"new_line"`
+		} `json:"position"`
+	}
+	if err := c.do(ctx, http.MethodGet, mrPath+"/notes?per_page=100", nil, &notes); err != nil {
+		return nil, err
+	}
+
+	values := make([]map[string]any, 0, len(notes))
+	for _, n := range notes {
+		if n.System {
+			continue
+		}
+		v := map[string]any{"id": n.ID, "content": map[string]any{"raw": n.Body}}
+		if n.Position != nil {
+			v["inline"] = map[string]any{"path": n.Position.NewPath, "to": n.Position.NewLine}
+		}
+		values = append(values, v)
+	}
+	return map[string]any{"values": values}, nil
+}
+
+// addComment posts a note, or a discussion thread when the comment targets a file
+func (c *GitLabClient) addComment(ctx context.Context, mrPath string, args map[string]interface{}) (any, error) {
+	body := argString(args, "commentText")
+	filePath := argString(args, "filePath")
+
+	var out struct {
+		ID string `json:"id"`
+	}
+	if filePath == "" {
+		var note struct {
+			ID int64 `json:"id"`
+		}
+		err := c.do(ctx, http.MethodPost, mrPath+"/notes", map[string]any{"body": body}, &note)
+		return map[string]any{"id": note.ID}, err
+	}
+
+	var mr struct {
+		DiffRefs struct {
+			BaseSHA  string `json:"base_sha"`
+			HeadSHA  string `json:"head_sha"`
+			StartSHA string `json:"start_sha"`
+		} `json:"diff_refs"`
+	}
+	if err := c.do(ctx, http.MethodGet, mrPath, nil, &mr); err != nil {
+		return nil, err
+	}
+
+	position := map[string]any{
+		"base_sha":  mr.DiffRefs.BaseSHA,
+		"head_sha":  mr.DiffRefs.HeadSHA,
+		"start_sha": mr.DiffRefs.StartSHA,
+		"old_path":  filePath,
+		"new_path":  filePath,
+	}
+	line, _ := strconv.Atoi(argString(args, "lineNumber"))
+	switch {
+	case line <= 0:
+		position["position_type"] = "file"
+	case argString(args, "lineType") == "REMOVED":
+		position["position_type"] = "text"
+		position["old_line"] = line
+	default:
+		position["position_type"] = "text"
+		position["new_line"] = line
+	}
+
+	err := c.do(ctx, http.MethodPost, mrPath+"/discussions", map[string]any{"body": body, "position": position}, &out)
+	return map[string]any{"id": out.ID}, err
+}
+
+// do sends a request; out is a *bytes.Buffer for raw responses or a JSON target
+func (c *GitLabClient) do(ctx context.Context, method, path string, body, out any) error {
+	var reader io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return fmt.Errorf("marshal gitlab request: %w", err)
+		}
+		reader = bytes.NewReader(data)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, reader)
+	if err != nil {
+		return fmt.Errorf("create gitlab request: %w", err)
+	}
+	if c.token != "" {
+		req.Header.Set("PRIVATE-TOKEN", c.token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return fmt.Errorf("gitlab %s %s: %w", method, path, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		msg, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("gitlab %s %s: status %d: %s", method, path, resp.StatusCode, strings.TrimSpace(string(msg)))
+	}
+	if buf, ok := out.(*bytes.Buffer); ok {
+		_, err = io.Copy(buf, resp.Body)
+		return err
+	}
+	if out != nil {
+		if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+			return fmt.Errorf("decode gitlab response: %w", err)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,91 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+)
+
+func TestGitLabClient_CallTool(t *testing.T) {
+	var posted map[string]any
+	var postedPath string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("PRIVATE-TOKEN") != "tok" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		mr := "/projects/acme/backend/api/merge_requests/7"
+		switch {
+		case r.Method == http.MethodPost:
+			postedPath = r.URL.Path
+			json.NewDecoder(r.Body).Decode(&posted)
+			if strings.HasSuffix(r.URL.Path, "/discussions") {
+				w.Write([]byte(`{"id": "6a9c1750"}`))
+			} else {
+				w.Write([]byte(`{"id": 9}`))
+			}
+		case r.URL.Path == mr:
+			w.Write([]byte(`{"diff_refs": {"base_sha": "b", "head_sha": "h", "start_sha": "s"}}`))
+		case r.URL.Path == mr+"/diffs":
+			w.Write([]byte(`[{"old_path": "a.go", "new_path": "a.go", "diff": "@@ -1 +1 @@\n-x\n+y\n"},
+				{"old_path": "b.go", "new_path": "b.go", "new_file": true, "diff": "@@ -0,0 +1 @@\n+z"}]`))
+		case r.URL.Path == mr+"/notes":
+			w.Write([]byte(`[{"id": 1, "body": "summary"}, {"id": 2, "body": "approved", "system": true},
+				{"id": 3, "body": "inline", "position": {"new_path": "a.go", "new_line": 1}}]`))
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer srv.Close()
+
+	c := NewGitLabClient(config.GitLabConfig{APIURL: srv.URL, Token: "tok", Timeout: time.Second})
+	ctx := context.Background()
+	args := func(extra map[string]interface{}) map[string]interface{} {
+		a := map[string]interface{}{"projectKey": "acme/backend", "repoSlug": "api", "pullRequestId": 7}
+		for k, v := range extra {
+			a[k] = v
+		}
+		return a
+	}
+
+	diff, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetDiff, args(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1 +1 @@", "--- /dev/null\n+++ b/b.go\n@@ -0,0 +1 @@\n+z\n"} {
+		if !strings.Contains(diff.(string), want) {
+			t.Errorf("diff missing %q:\n%s", want, diff)
+		}
+	}
+
+	notes, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetComments, args(nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	values := notes.(map[string]any)["values"].([]map[string]any)
+	if len(values) != 2 || values[1]["inline"].(map[string]any)["path"] != "a.go" {
+		t.Errorf("notes = %v", values)
+	}
+
+	if _, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketAddComment,
+		args(map[string]interface{}{"commentText": "nil deref", "filePath": "a.go", "lineNumber": "1"})); err != nil {
+		t.Fatal(err)
+	}
+	position, _ := posted["position"].(map[string]any)
+	if !strings.HasSuffix(postedPath, "/discussions") || position["new_line"] != float64(1) || position["head_sha"] != "h" || position["position_type"] != "text" {
+		t.Errorf("discussion posted to %s: %v", postedPath, posted)
+	}
+
+	if _, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketAddComment, args(map[string]interface{}{"commentText": "summary"})); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasSuffix(postedPath, "/notes") || posted["body"] != "summary" {
+		t.Errorf("note posted to %s: %v", postedPath, posted)
+	}
+}
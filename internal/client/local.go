@@ -0,0 +1,71 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"pr-review-automation/internal/config"
+)
+
+// LocalBackend serves the read tools of the Bitbucket vocabulary from a diff file and a
+// working tree, so the review CLI runs the pipeline without an SCM. Write tools fail.
+type LocalBackend struct {
+	diff string
+	dir  string // Working tree the diff applies to; file contents are read from it. Empty = none
+}
+
+// NewLocalBackend creates a backend serving diff, with file contents read from dir. Without a
+// dir, only the diff is available.
+func NewLocalBackend(diff, dir string) *LocalBackend {
+	return &LocalBackend{diff: diff, dir: dir}
+}
+
+// CallTool executes a read tool against the diff and working tree
+func (b *LocalBackend) CallTool(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+	switch toolName {
+	case config.ToolBitbucketGetDiff:
+		return b.diff, nil
+
+	case config.ToolBitbucketGetChanges:
+		values := []map[string]any{}
+		for _, path := range diffPaths(b.diff) {
+			values = append(values, map[string]any{"path": map[string]any{"toString": path}})
+		}
+		return map[string]any{"values": values}, nil
+
+	case config.ToolBitbucketGetFileContent:
+		if b.dir == "" {
+			return nil, fmt.Errorf("local %s: no working tree", toolName)
+		}
+		path := filepath.Clean(filepath.FromSlash(argString(args, "path")))
+		if filepath.IsAbs(path) || path == ".." || strings.HasPrefix(path, ".."+string(filepath.Separator)) {
+			return nil, fmt.Errorf("local %s: path %q is outside the working tree", toolName, argString(args, "path"))
+		}
+		data, err := os.ReadFile(filepath.Join(b.dir, path))
+		if err != nil {
+			return nil, err
+		}
+		return string(data), nil
+
+	case config.ToolBitbucketGetComments:
+		return map[string]any{"values": []any{}}, nil
+
+	default:
+		return nil, fmt.Errorf("tool %s is not available for a local diff", toolName)
+	}
+}
+
+// diffPaths returns the new paths of the files in a unified diff; deleted files are skipped
+func diffPaths(diff string) []string {
+	var paths []string
+	for line := range strings.SplitSeq(diff, "\n") {
+		if path, ok := strings.CutPrefix(line, "+++ "); ok && path != "/dev/null" {
+			path, _, _ = strings.Cut(path, "\t")
+			paths = append(paths, strings.TrimPrefix(path, "b/"))
+		}
+	}
+	return paths
+}
@@ -0,0 +1,68 @@
+package client
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"pr-review-automation/internal/config"
+)
+
+func TestLocalBackend_CallTool(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(dir, "main.go"), []byte("package main\n"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	diff := "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -0,0 +1 @@\n+package main\n" +
+		"diff --git a/old.go b/old.go\n--- a/old.go\n+++ /dev/null\n@@ -1 +0,0 @@\n-package old\n"
+	b := NewLocalBackend(diff, dir)
+	ctx := context.Background()
+
+	if got, err := b.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetDiff, nil); err != nil || got != diff {
+		t.Errorf("diff = %v, %v", got, err)
+	}
+	changes, err := b.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetChanges, nil)
+	if values := changes.(map[string]any)["values"].([]map[string]any); err != nil || len(values) != 1 {
+		t.Errorf("changes = %v, %v", changes, err)
+	}
+	if got, err := b.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetFileContent, map[string]interface{}{"path": "main.go"}); err != nil || got != "package main\n" {
+		t.Errorf("file content = %v, %v", got, err)
+	}
+	for _, path := range []string{"../secret", "/etc/passwd"} {
+		if _, err := b.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetFileContent, map[string]interface{}{"path": path}); err == nil {
+			t.Errorf("read %s outside the working tree", path)
+		}
+	}
+	if _, err := b.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketAddComment, nil); err == nil {
+		t.Error("write tool succeeded on a local diff")
+	}
+}
+
+func TestPatchBackend_CallTool(t *testing.T) {
+	b := NewPatchBackend()
+	ctx := context.Background()
+	id1, release1 := b.Add("diff one")
+	id2, release2 := b.Add("diff two")
+	defer release2()
+	if id1 == id2 {
+		t.Fatalf("both patches got id %s", id1)
+	}
+
+	for id, want := range map[string]string{id1: "diff one", id2: "diff two"} {
+		pullRequestID, _ := strconv.Atoi(id)
+		got, err := b.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetDiff, map[string]interface{}{"pullRequestId": pullRequestID})
+		if err != nil || got != want {
+			t.Errorf("diff of %s = %v, %v; want %q", id, got, err, want)
+		}
+	}
+	if _, err := b.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetFileContent, map[string]interface{}{"pullRequestId": id1, "path": "local_test.go"}); err == nil {
+		t.Error("patch served a file without a working tree")
+	}
+
+	release1()
+	if _, err := b.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetDiff, map[string]interface{}{"pullRequestId": id1}); err == nil {
+		t.Error("released patch is still served")
+	}
+}
@@ -6,12 +6,14 @@ import (
 	"io"
 	"log/slog"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"golang.org/x/sync/singleflight"
 
 	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/fault"
 	"pr-review-automation/internal/filter"
 	"pr-review-automation/internal/types"
 )
@@ -29,6 +31,9 @@ type MCPClient struct {
 	circuits        map[string]*circuitState         // Circuit breaker state per server
 	responseFilters map[string]filter.ResponseFilter // Response filters per server
 	callHistory     sync.Map                         // History of tool calls for deduplication
+	backends        map[string]ToolBackend           // SCM provider -> backend serving Bitbucket tool calls
+	replicas        map[string]readReplica           // Primary server -> read-only replica
+	faults          *fault.Injector                  // Simulated timeouts (fault_injection); nil when disabled
 
 	mu               sync.RWMutex                     // Thread-safe access (connections)
 	transportFactory TransportFactory                 // Factory for creating transports (injectable for testing)
@@ -37,6 +42,10 @@ type MCPClient struct {
 	cancel           context.CancelFunc               // Cancel function to cleanup resources on Close
 	toolCache        map[string][]types.RawToolSchema // Cache storage: serverName -> tools
 	toolCacheMu      sync.RWMutex                     // Mutex specifically for tool cache
+	toolCacheAt      time.Time                        // Last successful refresh
+	toolCacheStale   bool                             // Set on reconnect; forces a refresh before TTL expiry
+	toolCacheTried   time.Time                        // Last refresh attempt, successful or not
+	toolRefreshing   atomic.Bool                      // Guards against concurrent background refreshes
 }
 
 // SetTransportFactory allows tests to inject a mock transport factory
@@ -56,6 +65,21 @@ func (c *MCPClient) SetResponseFilter(serverName string, f filter.ResponseFilter
 	c.responseFilters[serverName] = f
 }
 
+// ToolBackend serves tool calls outside MCP (see GitHubClient)
+type ToolBackend interface {
+	CallTool(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error)
+}
+
+// SetProviderBackend routes Bitbucket tool calls made with domain.WithProvider(ctx, provider) to b
+func (c *MCPClient) SetProviderBackend(provider string, b ToolBackend) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.backends == nil {
+		c.backends = make(map[string]ToolBackend)
+	}
+	c.backends[provider] = b
+}
+
 // NewMCPClient creates a new MCP client manager
 func NewMCPClient(cfg *config.Config) *MCPClient {
 	ctx, cancel := context.WithCancel(context.Background())
@@ -66,11 +90,13 @@ func NewMCPClient(cfg *config.Config) *MCPClient {
 		endpoints:        make(map[string]endpointInfo),
 		stale:            make(map[string]bool),
 		circuits:         make(map[string]*circuitState),
+		replicas:         make(map[string]readReplica),
 		responseFilters:  make(map[string]filter.ResponseFilter),
 		transportFactory: NewMCPTransport, // Default to standard transport factory
 		baseCtx:          ctx,
 		cancel:           cancel,
 		toolCache:        make(map[string][]types.RawToolSchema),
+		faults:           fault.New(cfg.FaultInjection),
 	}
 }
 
@@ -118,7 +144,7 @@ func (c *MCPClient) InitializeConnections() error {
 			c.mu.Unlock()
 		}
 
-		_, err := c.getOrReconnect(name)
+		_, err := c.getOrReconnect(c.baseCtx, name)
 		if err != nil {
 			slog.Error("connect mcp failed", "server", name, "error", err)
 			return err
@@ -127,6 +153,9 @@ func (c *MCPClient) InitializeConnections() error {
 	}
 
 	addServerConn(config.MCPServerBitbucket, c.cfg.MCP.Bitbucket)
+	if replica := c.cfg.MCP.BitbucketReplica; replica.Endpoint != "" && c.cfg.MCP.Bitbucket.Endpoint != "" {
+		c.addReplica(config.MCPServerBitbucket, config.MCPServerBitbucketReplica, replica)
+	}
 	// Optimization: Only connect if tools are explicitly allowed (enabled)
 	if len(c.cfg.MCP.Jira.AllowedTools) > 0 {
 		addServerConn(config.MCPServerJira, c.cfg.MCP.Jira)
@@ -238,6 +267,9 @@ func (c *MCPClient) reconnect(name string, logger *slog.Logger) (*mcp.ClientSess
 	delete(c.circuits, name)
 	c.mu.Unlock()
 
+	// The server may have been redeployed with different tools
+	c.invalidateToolCache()
+
 	logger.Info("connected")
 	return session, nil
 }
@@ -9,6 +9,7 @@ import (
 	"pr-review-automation/internal/metrics"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"golang.org/x/sync/singleflight"
 )
 
 // circuitState represents the state of a circuit breaker for a single MCP server
@@ -40,6 +41,9 @@ func (c *MCPClient) IsHealthy() bool {
 	defer c.mu.RUnlock()
 
 	for name := range c.endpoints {
+		if c.isReplica(name) {
+			continue
+		}
 		if c.stale[name] {
 			return false
 		}
@@ -51,8 +55,9 @@ func (c *MCPClient) IsHealthy() bool {
 	return true
 }
 
-// getOrReconnect returns existing session or reconnects if stale
-func (c *MCPClient) getOrReconnect(name string) (*mcp.ClientSession, error) {
+// getOrReconnect returns existing session or reconnects if stale. A caller whose ctx is done
+// stops waiting for the reconnection, which completes for the others.
+func (c *MCPClient) getOrReconnect(ctx context.Context, name string) (*mcp.ClientSession, error) {
 	c.mu.RLock()
 	logger := slog.With("server", name)
 	session, hasSession := c.sessions[name]
@@ -76,7 +81,7 @@ func (c *MCPClient) getOrReconnect(name string) (*mcp.ClientSession, error) {
 	}
 
 	// Use singleflight to deduplicate concurrent reconnection attempts
-	val, err, _ := c.requestGroup.Do(name, func() (interface{}, error) {
+	ch := c.requestGroup.DoChan(name, func() (interface{}, error) {
 		// Double check inside the singleflight to see if another call just finished it
 		c.mu.RLock()
 		session, hasSession := c.sessions[name]
@@ -90,12 +95,18 @@ func (c *MCPClient) getOrReconnect(name string) (*mcp.ClientSession, error) {
 		return c.reconnect(name, logger)
 	})
 
-	if err != nil {
+	var res singleflight.Result
+	select {
+	case res = <-ch:
+	case <-ctx.Done():
+		return nil, fmt.Errorf("connect %s: %w", name, ctx.Err())
+	}
+	if res.Err != nil {
 		// Update circuit breaker state on failure
 		c.recordFailure(name)
-		return nil, err
+		return nil, res.Err
 	}
-	return val.(*mcp.ClientSession), nil
+	return res.Val.(*mcp.ClientSession), nil
 }
 
 // recordFailure updates circuit breaker state after a connection failure
@@ -132,16 +143,3 @@ func (c *MCPClient) forceReconnect(name string) {
 	c.stale[name] = true
 	c.mu.Unlock()
 }
-
-// backoff sleeps with exponential backoff, respecting context
-func (c *MCPClient) backoff(ctx context.Context, attempt int) {
-	backoff := c.cfg.MCP.Retry.Backoff * time.Duration(1<<attempt)
-	if backoff > c.cfg.MCP.Retry.MaxBackoff {
-		backoff = c.cfg.MCP.Retry.MaxBackoff // Cap at 30s
-	}
-	select {
-	case <-ctx.Done():
-		slog.Debug("backoff cancelled")
-	case <-time.After(backoff):
-	}
-}
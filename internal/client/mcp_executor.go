@@ -2,75 +2,105 @@ package client
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"slices"
 
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
 	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/retry"
+	"pr-review-automation/internal/types"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 )
 
-// CallTool calls a tool on a specific MCP server with retry logic
+// CallTool calls a tool on a specific MCP server, retrying transient failures of reads with a
+// fresh session under mcp.retry
 func (c *MCPClient) CallTool(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
 	slog.Debug("call tool", "server", serverName, "tool", toolName)
+	if err := c.faults.MCP(ctx, serverName, toolName); err != nil {
+		metrics.MCPToolCalls.WithLabelValues(serverName, toolName, "error").Inc()
+		return nil, err
+	}
+
+	if serverName == config.MCPServerBitbucket {
+		if provider := domain.ProviderFromContext(ctx); provider != domain.ProviderBitbucket {
+			return c.callBackend(ctx, provider, serverName, toolName, args)
+		}
+	}
 
-	maxAttempts := 2
-	var lastErr error
+	if result, ok := c.tryReplica(ctx, serverName, toolName, args); ok {
+		return result, nil
+	}
 
-	for attempt := 0; attempt < maxAttempts; attempt++ {
-		session, err := c.getOrReconnect(serverName)
+	// A write that failed after reaching the server may have been applied, and a retry would
+	// post it twice
+	policy := c.cfg.MCP.Retry
+	if slices.Contains(config.BitbucketWriteTools, toolName) {
+		policy.Attempts = 1
+	}
+	var result *mcp.CallToolResult
+	err := retry.Do(ctx, "mcp_call", policy, retry.Transient, func(attempt int) error {
+		if attempt > 0 {
+			c.forceReconnect(serverName)
+		}
+		session, err := c.getOrReconnect(ctx, serverName)
 		if err != nil {
-			lastErr = err
-			if attempt < maxAttempts-1 {
-				c.forceReconnect(serverName)
-				continue
-			}
-			return nil, err
+			return types.NewRetryableError(err)
 		}
-
-		// Execute Tool Call
 		params := mcp.CallToolParams{
 			Name:      toolName,
 			Arguments: args,
 		}
-
-		result, err := session.CallTool(ctx, &params)
-		if err == nil {
-			metrics.MCPToolCalls.WithLabelValues(serverName, toolName, "success").Inc()
-
-			// Check response filter
-			c.mu.RLock()
-			filter := c.responseFilters[serverName]
-			c.mu.RUnlock()
-
-			// Result is *mcp.CallToolResult.
-			// Currently returning it directly or filtering it.
-			// Pipeline expects a result that can be parsed (map or struct).
-			// mcp.CallToolResult struct: { Content: []Content, IsError: bool }
-			// We should probably return the result as is, or process content.
-			// For backward compatibility (ADK tool returned map), we might need to verify what callers expect.
-			// Stage1 uses ExtractString on result.
-
-			if filter != nil {
-				slog.Info("applying response filter", "server", serverName, "tool", toolName)
-				// Filter expects 'any'.
-				filtered := filter.Filter(toolName, result)
-				return filtered, nil
-			}
-
-			return result, nil
+		result, err = withDeadline(ctx, "mcp", c.cfg.MCP.Timeout, func(ctx context.Context) (*mcp.CallToolResult, error) {
+			return session.CallTool(ctx, &params)
+		})
+		if err != nil && ctx.Err() == nil {
+			slog.Warn("call tool failed", "server", serverName, "tool", toolName, "attempt", attempt, "error", err)
 		}
+		if errors.Is(err, mcp.ErrConnectionClosed) {
+			return types.NewRetryableError(err) // A fresh session may succeed
+		}
+		return err
+	})
+	if err != nil {
+		metrics.MCPToolCalls.WithLabelValues(serverName, toolName, "error").Inc()
+		return nil, fmt.Errorf("call tool %s/%s failed: %w", serverName, toolName, err)
+	}
+	metrics.MCPToolCalls.WithLabelValues(serverName, toolName, "success").Inc()
 
-		lastErr = err
-		slog.Warn("call tool failed", "server", serverName, "tool", toolName, "attempt", attempt, "error", err)
+	// Result is *mcp.CallToolResult; Stage1 uses ExtractString on it
+	c.mu.RLock()
+	filter := c.responseFilters[serverName]
+	c.mu.RUnlock()
+	if filter != nil {
+		slog.Info("applying response filter", "server", serverName, "tool", toolName)
+		return filter.Filter(toolName, result), nil
+	}
+	return result, nil
+}
 
-		if attempt < maxAttempts-1 {
-			c.forceReconnect(serverName)
-			c.backoff(ctx, attempt)
-			continue
-		}
+// callBackend executes an SCM tool call on the backend registered for provider.
+// The server's response filter still applies, so size limits hold for every provider.
+func (c *MCPClient) callBackend(ctx context.Context, provider, serverName, toolName string, args map[string]interface{}) (any, error) {
+	c.mu.RLock()
+	backend := c.backends[provider]
+	filter := c.responseFilters[serverName]
+	c.mu.RUnlock()
+	if backend == nil {
+		return nil, fmt.Errorf("no backend for provider %s", provider)
 	}
 
-	metrics.MCPToolCalls.WithLabelValues(serverName, toolName, "error").Inc()
-	return nil, fmt.Errorf("call tool %s/%s failed: %w", serverName, toolName, lastErr)
+	result, err := backend.CallTool(ctx, serverName, toolName, args)
+	if err != nil {
+		metrics.MCPToolCalls.WithLabelValues(provider, toolName, "error").Inc()
+		return nil, fmt.Errorf("call tool %s/%s failed: %w", provider, toolName, err)
+	}
+	metrics.MCPToolCalls.WithLabelValues(provider, toolName, "success").Inc()
+	if filter != nil {
+		return filter.Filter(toolName, result), nil
+	}
+	return result, nil
 }
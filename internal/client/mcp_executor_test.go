@@ -0,0 +1,118 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"pr-review-automation/internal/config"
+)
+
+func TestMCPClient_CallTool_Cancel(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.MCP.Bitbucket.Endpoint = "memory://primary"
+	cfg.MCP.Timeout = time.Minute
+	cfg.MCP.SchemaCacheTTL = time.Hour
+	cfg.MCP.Retry.Backoff = time.Second
+	cfg.MCP.Retry.MaxBackoff = time.Second
+	cfg.MCP.CircuitBreaker.FailureThreshold = 3
+
+	// The tool hangs, ignoring cancellation
+	release := make(chan struct{})
+	t.Cleanup(func() { close(release) })
+	server := mcp.NewServer(&mcp.Implementation{Name: "primary", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: config.ToolBitbucketGetDiff}, func(ctx context.Context, req *mcp.CallToolRequest, args echoArgs) (*mcp.CallToolResult, any, error) {
+		<-release
+		return &mcp.CallToolResult{}, nil, nil
+	})
+
+	c := NewMCPClient(cfg)
+	c.SetTransportFactory(func(ctx context.Context, endpoint, token, authHeader string, timeout time.Duration) (mcp.Transport, error) {
+		clientT, serverT := mcp.NewInMemoryTransports()
+		if _, err := server.Connect(ctx, serverT, nil); err != nil {
+			return nil, err
+		}
+		return clientT, nil
+	})
+	if err := c.InitializeConnections(); err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	_, err := c.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetDiff, map[string]interface{}{"text": "x"})
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected context.DeadlineExceeded, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > abandonGrace+time.Second {
+		t.Errorf("cancelled call returned after %v", elapsed)
+	}
+	// Cancellation is the caller's doing: the session is neither retried nor dropped
+	if !c.IsHealthy() {
+		t.Error("session marked stale after a cancelled call")
+	}
+}
+
+func TestMCPClient_CallTool_RetriesReadsOnly(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.MCP.Bitbucket.Endpoint = "memory://primary"
+	cfg.MCP.Timeout = 50 * time.Millisecond
+	cfg.MCP.SchemaCacheTTL = time.Hour
+	cfg.MCP.Retry.Attempts = 2
+	cfg.MCP.Retry.Backoff = time.Millisecond
+	cfg.MCP.CircuitBreaker.FailureThreshold = 10
+
+	// Both tools answer after the call timeout
+	var calls sync.Map
+	slow := func(ctx context.Context, req *mcp.CallToolRequest, args echoArgs) (*mcp.CallToolResult, any, error) {
+		n, _ := calls.LoadOrStore(req.Params.Name, new(atomic.Int32))
+		n.(*atomic.Int32).Add(1)
+		select {
+		case <-ctx.Done():
+		case <-time.After(200 * time.Millisecond):
+		}
+		return &mcp.CallToolResult{}, nil, nil
+	}
+	server := mcp.NewServer(&mcp.Implementation{Name: "primary", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: config.ToolBitbucketGetDiff}, slow)
+	mcp.AddTool(server, &mcp.Tool{Name: config.ToolBitbucketAddComment}, slow)
+
+	c := NewMCPClient(cfg)
+	c.SetTransportFactory(func(ctx context.Context, endpoint, token, authHeader string, timeout time.Duration) (mcp.Transport, error) {
+		clientT, serverT := mcp.NewInMemoryTransports()
+		if _, err := server.Connect(ctx, serverT, nil); err != nil {
+			return nil, err
+		}
+		return clientT, nil
+	})
+	if err := c.InitializeConnections(); err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	for _, tool := range []string{config.ToolBitbucketGetDiff, config.ToolBitbucketAddComment} {
+		if _, err := c.CallTool(context.Background(), config.MCPServerBitbucket, tool, map[string]interface{}{"text": "x"}); err == nil {
+			t.Fatalf("%s: expected a timeout", tool)
+		}
+	}
+	count := func(tool string) int32 {
+		n, ok := calls.Load(tool)
+		if !ok {
+			return 0
+		}
+		return n.(*atomic.Int32).Load()
+	}
+	if got := count(config.ToolBitbucketGetDiff); got != 2 {
+		t.Errorf("read called %d times, want 2", got)
+	}
+	if got := count(config.ToolBitbucketAddComment); got != 1 {
+		t.Errorf("write called %d times, want 1", got)
+	}
+}
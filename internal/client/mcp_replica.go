@@ -0,0 +1,114 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/metrics"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+)
+
+// readReplica is a read-only mirror of an MCP server
+type readReplica struct {
+	name  string          // Server name of the replica connection
+	tools map[string]bool // Tools the replica serves
+}
+
+// addReplica registers a read replica for primary. The replica is connected lazily and never
+// contributes tool schemas or health: when it is down, reads go to the primary.
+func (c *MCPClient) addReplica(primary, name string, cfg config.MCPReplicaConfig) {
+	tools := make(map[string]bool, len(cfg.ReadTools))
+	for _, t := range cfg.ReadTools {
+		tools[t] = true
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.endpoints[name] = endpointInfo{
+		endpoint:   cfg.Endpoint,
+		token:      cfg.Token,
+		authHeader: cfg.AuthHeader,
+	}
+	c.replicas[primary] = readReplica{name: name, tools: tools}
+	slog.Info("mcp read replica configured", "server", primary, "replica", name, "tools", cfg.ReadTools)
+}
+
+// replicaFor returns the replica serving toolName for serverName, if any
+func (c *MCPClient) replicaFor(serverName, toolName string) (readReplica, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	r, ok := c.replicas[serverName]
+	if !ok || !r.tools[toolName] {
+		return readReplica{}, false
+	}
+	return r, true
+}
+
+// isReplica reports whether name is the connection of a read replica. The caller holds c.mu.
+func (c *MCPClient) isReplica(name string) bool {
+	for _, r := range c.replicas {
+		if r.name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// callReplica makes a single attempt on the replica. Tool errors count as failures too, since
+// a lagging mirror may not have the pull request's latest commit yet.
+func (c *MCPClient) callReplica(ctx context.Context, replica readReplica, toolName string, args map[string]interface{}) (*mcp.CallToolResult, error) {
+	session, err := c.getOrReconnect(ctx, replica.name)
+	if err != nil {
+		return nil, err
+	}
+	result, err := withDeadline(ctx, "mcp", c.cfg.MCP.Timeout, func(ctx context.Context) (*mcp.CallToolResult, error) {
+		return session.CallTool(ctx, &mcp.CallToolParams{Name: toolName, Arguments: args})
+	})
+	if err != nil {
+		if ctx.Err() == nil {
+			c.forceReconnect(replica.name)
+		}
+		return nil, err
+	}
+	if result.IsError {
+		return nil, fmt.Errorf("tool error: %s", textContent(result))
+	}
+	return result, nil
+}
+
+// textContent joins the text parts of a tool result
+func textContent(result *mcp.CallToolResult) string {
+	var text string
+	for _, content := range result.Content {
+		if t, ok := content.(*mcp.TextContent); ok {
+			text += t.Text
+		}
+	}
+	return text
+}
+
+// tryReplica serves a read from the replica of serverName when one is configured for toolName.
+// ok is false when the call should go to the primary.
+func (c *MCPClient) tryReplica(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, bool) {
+	replica, ok := c.replicaFor(serverName, toolName)
+	if !ok {
+		return nil, false
+	}
+	result, err := c.callReplica(ctx, replica, toolName, args)
+	if err != nil {
+		metrics.MCPToolCalls.WithLabelValues(replica.name, toolName, "error").Inc()
+		slog.Warn("replica read failed, using primary", "server", serverName, "replica", replica.name, "tool", toolName, "error", err)
+		return nil, false
+	}
+	metrics.MCPToolCalls.WithLabelValues(replica.name, toolName, "success").Inc()
+
+	c.mu.RLock()
+	filter := c.responseFilters[serverName]
+	c.mu.RUnlock()
+	if filter != nil {
+		return filter.Filter(toolName, result), true
+	}
+	return result, true
+}
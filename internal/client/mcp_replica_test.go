@@ -0,0 +1,84 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"pr-review-automation/internal/config"
+)
+
+func namedServer(name string, failing ...string) *mcp.Server {
+	server := mcp.NewServer(&mcp.Implementation{Name: name, Version: "1.0.0"}, nil)
+	for _, tool := range []string{config.ToolBitbucketGetDiff, config.ToolBitbucketGetFileContent, config.ToolBitbucketAddComment} {
+		fail := false
+		for _, f := range failing {
+			fail = fail || f == tool
+		}
+		mcp.AddTool(server, &mcp.Tool{Name: tool}, func(ctx context.Context, req *mcp.CallToolRequest, args echoArgs) (*mcp.CallToolResult, any, error) {
+			if fail {
+				return nil, nil, errors.New("commit not found")
+			}
+			return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: name}}}, nil, nil
+		})
+	}
+	return server
+}
+
+func TestMCPClient_ReadReplica(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.MCP.Bitbucket.Endpoint = "memory://primary"
+	cfg.MCP.BitbucketReplica.Endpoint = "memory://replica"
+	cfg.MCP.BitbucketReplica.ReadTools = []string{config.ToolBitbucketGetDiff, config.ToolBitbucketGetFileContent}
+	cfg.MCP.Timeout = 5 * time.Second
+	cfg.MCP.SchemaCacheTTL = time.Hour
+	cfg.MCP.CircuitBreaker.FailureThreshold = 3
+
+	servers := map[string]*mcp.Server{
+		"memory://primary": namedServer("primary"),
+		"memory://replica": namedServer("replica", config.ToolBitbucketGetFileContent),
+	}
+	c := NewMCPClient(cfg)
+	c.SetTransportFactory(func(ctx context.Context, endpoint, token, authHeader string, timeout time.Duration) (mcp.Transport, error) {
+		clientT, serverT := mcp.NewInMemoryTransports()
+		if _, err := servers[endpoint].Connect(ctx, serverT, nil); err != nil {
+			return nil, err
+		}
+		return clientT, nil
+	})
+	if err := c.InitializeConnections(); err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+
+	tests := []struct {
+		name string
+		tool string
+		want string
+	}{
+		{name: "diff from replica", tool: config.ToolBitbucketGetDiff, want: "replica"},
+		{name: "replica tool error falls back to primary", tool: config.ToolBitbucketGetFileContent, want: "primary"},
+		{name: "writes go to primary", tool: config.ToolBitbucketAddComment, want: "primary"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			res, err := c.CallTool(context.Background(), config.MCPServerBitbucket, tt.tool, map[string]interface{}{"text": "x"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got := textContent(res.(*mcp.CallToolResult)); got != tt.want {
+				t.Errorf("served by %q, want %q", got, tt.want)
+			}
+		})
+	}
+
+	if _, ok := c.GetRawToolSchemas()[config.MCPServerBitbucketReplica]; ok {
+		t.Error("replica tools must not be exposed")
+	}
+	if !c.IsHealthy() {
+		t.Error("expected healthy client")
+	}
+}
@@ -7,31 +7,25 @@ import (
 	"log/slog"
 	"time"
 
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/retry"
 	"pr-review-automation/internal/types"
 )
 
 // refreshToolCache populates the tool cache from all connected MCP servers.
-// It includes retry logic for robustness during startup.
+// It retries a few times for robustness during startup.
 func (c *MCPClient) refreshToolCache(ctx context.Context) error {
-	maxRetries := 3
-	var lastErr error
-
-	for i := 0; i < maxRetries; i++ {
-		err := c.doRefreshToolCache(ctx)
-		if err == nil {
-			slog.Info("refresh tool cache success", "attempt", i+1)
-			return nil
-		}
-		lastErr = err
-		slog.Warn("failed to refresh tool cache, retrying...", "attempt", i+1, "error", err)
-
-		select {
-		case <-ctx.Done():
-			return ctx.Err()
-		case <-time.After(2 * time.Second):
+	err := retry.Do(ctx, "tool_cache", toolCacheRetry, nil, func(attempt int) error {
+		if err := c.doRefreshToolCache(ctx); err != nil {
+			return err
 		}
+		slog.Info("refresh tool cache success", "attempt", attempt+1)
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to refresh tool cache after %d attempts: %w", toolCacheRetry.Attempts, err)
 	}
-	return fmt.Errorf("failed to refresh tool cache after %d attempts: %w", maxRetries, lastErr)
+	return nil
 }
 
 // doRefreshToolCache performs the actual tool fetching
@@ -40,6 +34,9 @@ func (c *MCPClient) doRefreshToolCache(ctx context.Context) error {
 	c.mu.RLock()
 	var serverNames []string
 	for k := range c.endpoints {
+		if c.isReplica(k) {
+			continue
+		}
 		serverNames = append(serverNames, k)
 	}
 	c.mu.RUnlock()
@@ -49,7 +46,7 @@ func (c *MCPClient) doRefreshToolCache(ctx context.Context) error {
 
 	for _, name := range serverNames {
 		// Reuse existing session
-		session, err := c.getOrReconnect(name)
+		session, err := c.getOrReconnect(ctx, name)
 		if err != nil {
 			slog.Error("tool cache: connect failed", "server", name, "error", err)
 			errs = append(errs, fmt.Errorf("connect %s: %w", name, err))
@@ -117,16 +114,76 @@ func (c *MCPClient) doRefreshToolCache(ctx context.Context) error {
 	// Update cache thread-safely
 	c.toolCacheMu.Lock()
 	c.toolCache = newCache
+	c.toolCacheAt = time.Now()
+	c.toolCacheStale = false
 	c.toolCacheMu.Unlock()
 	return nil
 }
 
-// GetRawToolSchemas fetches raw tool schemas directly from MCP servers.
-// Now it returns the cached data.
+// toolCacheRetry is the retry policy of the startup tool cache refresh
+var toolCacheRetry = config.RetryConfig{Attempts: 3, Backoff: 2 * time.Second, MaxBackoff: 2 * time.Second, Jitter: config.DefaultRetryJitter}
+
+// schemaRefreshRetryInterval limits background refresh attempts while MCP servers are failing
+const schemaRefreshRetryInterval = 10 * time.Second
+
+// invalidateToolCache marks the cache for refresh on next use
+func (c *MCPClient) invalidateToolCache() {
+	c.toolCacheMu.Lock()
+	c.toolCacheStale = true
+	c.toolCacheMu.Unlock()
+}
+
+// ToolCacheUpdatedAt returns the time of the last successful tool schema refresh
+func (c *MCPClient) ToolCacheUpdatedAt() time.Time {
+	c.toolCacheMu.RLock()
+	defer c.toolCacheMu.RUnlock()
+	return c.toolCacheAt
+}
+
+// maybeRefreshToolCache starts a background refresh when the cache is expired or invalidated.
+// Callers keep using the current schemas until the refresh completes.
+// Must be called with toolCacheMu held (read or write).
+func (c *MCPClient) maybeRefreshToolCache() {
+	expired := c.cfg.MCP.SchemaCacheTTL > 0 && time.Since(c.toolCacheAt) > c.cfg.MCP.SchemaCacheTTL
+	if !expired && !c.toolCacheStale {
+		return
+	}
+	if time.Since(c.toolCacheTried) < schemaRefreshRetryInterval {
+		return
+	}
+	if !c.toolRefreshing.CompareAndSwap(false, true) {
+		return
+	}
+
+	go func() {
+		defer c.toolRefreshing.Store(false)
+
+		c.toolCacheMu.Lock()
+		c.toolCacheTried = time.Now()
+		c.toolCacheMu.Unlock()
+
+		ctx := c.baseCtx
+		if c.cfg.MCP.Timeout > 0 {
+			var cancel context.CancelFunc
+			ctx, cancel = context.WithTimeout(ctx, c.cfg.MCP.Timeout)
+			defer cancel()
+		}
+		if err := c.doRefreshToolCache(ctx); err != nil {
+			slog.Warn("background tool cache refresh failed, keeping cached schemas", "error", err)
+			return
+		}
+		slog.Debug("tool cache refreshed")
+	}()
+}
+
+// GetRawToolSchemas returns the cached tool schemas per server.
+// An expired or invalidated cache is refreshed in the background.
 func (c *MCPClient) GetRawToolSchemas() map[string][]types.RawToolSchema {
 	c.toolCacheMu.RLock()
 	defer c.toolCacheMu.RUnlock()
 
+	c.maybeRefreshToolCache()
+
 	// Return a copy to avoid race conditions if caller modifies the map (though slice content is shared)
 	result := make(map[string][]types.RawToolSchema)
 	for k, v := range c.toolCache {
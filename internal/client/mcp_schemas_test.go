@@ -0,0 +1,81 @@
+package client
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+
+	"pr-review-automation/internal/config"
+)
+
+type echoArgs struct {
+	Text string `json:"text"`
+}
+
+func echoTool(ctx context.Context, req *mcp.CallToolRequest, args echoArgs) (*mcp.CallToolResult, any, error) {
+	return &mcp.CallToolResult{Content: []mcp.Content{&mcp.TextContent{Text: args.Text}}}, nil, nil
+}
+
+// newInMemoryClient connects an MCPClient to an in-process MCP server as the bitbucket server
+func newInMemoryClient(t *testing.T, server *mcp.Server) *MCPClient {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.MCP.Bitbucket.Endpoint = "memory://bitbucket"
+	cfg.MCP.Timeout = 5 * time.Second
+	cfg.MCP.SchemaCacheTTL = time.Hour
+	cfg.MCP.CircuitBreaker.FailureThreshold = 3
+
+	c := NewMCPClient(cfg)
+	c.SetTransportFactory(func(ctx context.Context, endpoint, token, authHeader string, timeout time.Duration) (mcp.Transport, error) {
+		clientT, serverT := mcp.NewInMemoryTransports()
+		if _, err := server.Connect(ctx, serverT, nil); err != nil {
+			return nil, err
+		}
+		return clientT, nil
+	})
+	if err := c.InitializeConnections(); err != nil {
+		t.Fatalf("initialize: %v", err)
+	}
+	t.Cleanup(func() { c.Close() })
+	return c
+}
+
+func toolNames(c *MCPClient) map[string]bool {
+	names := make(map[string]bool)
+	for _, t := range c.GetRawToolSchemas()[config.MCPServerBitbucket] {
+		names[t.Name] = true
+	}
+	return names
+}
+
+func TestToolCache_RefreshAfterInvalidate(t *testing.T) {
+	server := mcp.NewServer(&mcp.Implementation{Name: "test", Version: "1.0.0"}, nil)
+	mcp.AddTool(server, &mcp.Tool{Name: "tool_a"}, echoTool)
+
+	c := newInMemoryClient(t, server)
+	if names := toolNames(c); !names["tool_a"] || len(names) != 1 {
+		t.Fatalf("expected [tool_a], got %v", names)
+	}
+
+	mcp.AddTool(server, &mcp.Tool{Name: "tool_b"}, echoTool)
+
+	// Within TTL the cache is served as is
+	if names := toolNames(c); names["tool_b"] {
+		t.Fatalf("expected cached schemas before invalidation, got %v", names)
+	}
+
+	c.invalidateToolCache()
+	deadline := time.Now().Add(5 * time.Second)
+	for !toolNames(c)["tool_b"] {
+		if time.Now().After(deadline) {
+			t.Fatal("tool cache was not refreshed after invalidation")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	if c.ToolCacheUpdatedAt().IsZero() {
+		t.Error("expected refresh time to be recorded")
+	}
+}
@@ -5,11 +5,18 @@ import (
 	"errors"
 	"fmt"
 	"log/slog"
+	"net/http"
+	"sync/atomic"
 	"time"
 
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/llm"
+	"pr-review-automation/internal/metrics"
 	"pr-review-automation/internal/types"
 
 	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/shared"
 )
 
 // OpenAIAdapter implements llm.Client interface using OpenAI official client
@@ -22,6 +29,10 @@ type OpenAIAdapter struct {
 	timeout        time.Duration
 	maxConcurrency int
 	sem            chan struct{}
+	params         config.LLMParams                               // Request defaults (llm.params)
+	caps           llm.Capabilities                               // Detected or declared (llm.capabilities)
+	noJSONFormat   atomic.Bool                                    // Set when the server rejects response_format json_object
+	pricing        func(model string) (config.ModelPricing, bool) // Token prices by model (llm.pricing)
 }
 
 // NewOpenAIAdapter creates a new OpenAI adapter
@@ -31,6 +42,7 @@ func NewOpenAIAdapter(client *openai.Client, model string) *OpenAIAdapter {
 		model:          model,
 		maxConcurrency: 1,                      // Default to 1
 		sem:            make(chan struct{}, 1), // Default semaphore
+		caps:           llm.DefaultCapabilities,
 	}
 }
 
@@ -56,6 +68,7 @@ func NewOpenAIAdapterWithConfig(client *openai.Client, model, endpoint, apiKey s
 		timeout:        120 * time.Second, // Default fallback
 		maxConcurrency: maxConcurrency,
 		sem:            sem,
+		caps:           llm.DefaultCapabilities,
 	}
 }
 
@@ -64,6 +77,74 @@ func (a *OpenAIAdapter) SetTimeout(d time.Duration) {
 	a.timeout = d
 }
 
+// SetParams sets the request parameters applied to chat completions that leave them unset
+func (a *OpenAIAdapter) SetParams(p config.LLMParams) {
+	a.params = p
+}
+
+// SetPricing sets the token prices by model, for cost metrics
+func (a *OpenAIAdapter) SetPricing(pricing func(model string) (config.ModelPricing, bool)) {
+	a.pricing = pricing
+}
+
+// SetCapabilities sets what the model supports
+func (a *OpenAIAdapter) SetCapabilities(c llm.Capabilities) {
+	a.caps = c
+}
+
+// Capabilities returns what the model supports; JSON output is off once the server rejected it
+func (a *OpenAIAdapter) Capabilities() llm.Capabilities {
+	caps := a.caps
+	caps.JSONSchema = caps.JSONSchema && !a.noJSONFormat.Load()
+	return caps
+}
+
+// ProbeJSONFormat checks whether the server accepts response_format json_object and, if it
+// rejects the request, stops sending it. Reviews still ask for JSON in the prompt, so servers
+// without constrained decoding keep working. Connection errors are returned and change nothing.
+func (a *OpenAIAdapter) ProbeJSONFormat(ctx context.Context) error {
+	format := shared.NewResponseFormatJSONObjectParam()
+	params := openai.ChatCompletionNewParams{
+		Model: openai.ChatModel(a.model),
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.UserMessage(`Reply with the JSON object {"ok": true}.`),
+		},
+		MaxTokens:      openai.Int(16),
+		ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{OfJSONObject: &format},
+	}
+	_, err := a.client.Chat.Completions.New(ctx, params)
+	if err == nil {
+		slog.Debug("llm accepts json response_format")
+		return nil
+	}
+
+	var apiErr *openai.Error
+	if errors.As(err, &apiErr) && apiErr.StatusCode >= 400 && apiErr.StatusCode < 500 &&
+		apiErr.StatusCode != http.StatusUnauthorized && apiErr.StatusCode != http.StatusTooManyRequests {
+		a.noJSONFormat.Store(true)
+		slog.Warn("llm rejects json response_format, disabling it", "status", apiErr.StatusCode, "error", apiErr.Message)
+		return nil
+	}
+	return fmt.Errorf("probe json response_format: %w", err)
+}
+
+// prepare fills request defaults and drops what the server does not support. A json_schema
+// response_format becomes json_object for models without strict schemas.
+func (a *OpenAIAdapter) prepare(params *openai.ChatCompletionNewParams) {
+	if params.Model == "" {
+		params.Model = openai.ChatModel(a.model)
+	}
+	llm.ApplyParams(params, a.params, false)
+	caps := a.Capabilities()
+	if params.ResponseFormat.OfJSONSchema != nil && !caps.StrictSchema {
+		format := shared.NewResponseFormatJSONObjectParam()
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{OfJSONObject: &format}
+	}
+	if !caps.JSONSchema {
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{}
+	}
+}
+
 // Name returns the model name
 func (a *OpenAIAdapter) Name() string {
 	return "openai-" + a.model
@@ -92,6 +173,20 @@ func (a *OpenAIAdapter) Ping(ctx context.Context) error {
 	return nil
 }
 
+// ListModels returns the model ids offered by the endpoint.
+// Not all OpenAI-compatible servers implement /models; callers should treat errors as "unknown".
+func (a *OpenAIAdapter) ListModels(ctx context.Context) ([]string, error) {
+	var ids []string
+	iter := a.client.Models.ListAutoPaging(ctx)
+	for iter.Next() {
+		ids = append(ids, iter.Current().ID)
+	}
+	if err := iter.Err(); err != nil {
+		return nil, fmt.Errorf("list models: %w", err)
+	}
+	return ids, nil
+}
+
 // Chat sends a chat completion request
 func (a *OpenAIAdapter) Chat(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
 	if a.sem != nil {
@@ -103,25 +198,115 @@ func (a *OpenAIAdapter) Chat(ctx context.Context, params openai.ChatCompletionNe
 		}
 	}
 
+	// Use default model if not provided
+	a.prepare(&params)
+
 	// Apply configured timeout ONLY for the request execution, NOT for waiting in queue
-	if a.timeout > 0 {
-		var cancel context.CancelFunc
-		ctx, cancel = context.WithTimeout(ctx, a.timeout)
-		defer cancel()
+	resp, err := withDeadline(ctx, "llm", a.timeout, func(ctx context.Context) (*openai.ChatCompletion, error) {
+		return a.client.Chat.Completions.New(ctx, params)
+	})
+	if err != nil {
+		return nil, a.wrapError(fmt.Errorf("openai request: %w", err))
 	}
+	a.recordUsage(ctx, string(params.Model), resp)
+	return resp, nil
+}
 
-	// Use default model if not provided
-	if params.Model == "" {
-		params.Model = openai.ChatModel(a.model)
+// ChatStream sends a streaming chat completion request and passes each content delta to onDelta.
+// Usage is requested in the final chunk so the accumulated completion matches Chat.
+func (a *OpenAIAdapter) ChatStream(ctx context.Context, params openai.ChatCompletionNewParams, onDelta func(string)) (*openai.ChatCompletion, error) {
+	if a.sem != nil {
+		select {
+		case a.sem <- struct{}{}:
+			defer func() { <-a.sem }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
 	}
 
-	resp, err := a.client.Chat.Completions.New(ctx, params)
+	a.prepare(&params)
+	params.StreamOptions.IncludeUsage = openai.Bool(true)
+
+	// Deltas of a stream abandoned after cancellation are dropped
+	var returned atomic.Bool
+	defer returned.Store(true)
+	resp, err := withDeadline(ctx, "llm", a.timeout, func(ctx context.Context) (*openai.ChatCompletion, error) {
+		stream := a.client.Chat.Completions.NewStreaming(ctx, params)
+		defer stream.Close()
+
+		var acc openai.ChatCompletionAccumulator
+		for stream.Next() {
+			chunk := stream.Current()
+			acc.AddChunk(chunk)
+			if len(chunk.Choices) > 0 && chunk.Choices[0].Delta.Content != "" && !returned.Load() {
+				onDelta(chunk.Choices[0].Delta.Content)
+			}
+		}
+		if err := stream.Err(); err != nil {
+			return nil, err
+		}
+		if err := ctx.Err(); err != nil {
+			return nil, err // A cancelled stream may end without an error, its completion is partial
+		}
+		return &acc.ChatCompletion, nil
+	})
 	if err != nil {
-		return nil, a.wrapError(fmt.Errorf("openai request: %w", err))
+		return nil, a.wrapError(fmt.Errorf("openai stream: %w", err))
 	}
+	a.recordUsage(ctx, string(params.Model), resp)
 	return resp, nil
 }
 
+// recordUsage counts the tokens of a completion, and their cost when the model is priced,
+// against the project of ctx
+func (a *OpenAIAdapter) recordUsage(ctx context.Context, model string, resp *openai.ChatCompletion) {
+	project := domain.ProjectFromContext(ctx)
+	if project == "" {
+		project = "none"
+	}
+	metrics.LLMUsageTokens.WithLabelValues(model, project, "prompt").Add(float64(resp.Usage.PromptTokens))
+	metrics.LLMUsageTokens.WithLabelValues(model, project, "completion").Add(float64(resp.Usage.CompletionTokens))
+	if a.pricing == nil {
+		return
+	}
+	if pricing, ok := a.pricing(model); ok {
+		metrics.LLMCost.WithLabelValues(model, project).Add(pricing.Cost(resp.Usage.PromptTokens, resp.Usage.CompletionTokens))
+	}
+}
+
+// Embed returns one embedding per text from the /embeddings endpoint of the adapter's model
+func (a *OpenAIAdapter) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	if a.sem != nil {
+		select {
+		case a.sem <- struct{}{}:
+			defer func() { <-a.sem }()
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+	resp, err := withDeadline(ctx, "llm", a.timeout, func(ctx context.Context) (*openai.CreateEmbeddingResponse, error) {
+		return a.client.Embeddings.New(ctx, openai.EmbeddingNewParams{
+			Model:          openai.EmbeddingModel(a.model),
+			Input:          openai.EmbeddingNewParamsInputUnion{OfArrayOfStrings: texts},
+			EncodingFormat: openai.EmbeddingNewParamsEncodingFormatFloat, // Self-hosted servers rarely support base64
+		})
+	})
+	if err != nil {
+		return nil, a.wrapError(fmt.Errorf("openai embeddings: %w", err))
+	}
+	if len(resp.Data) != len(texts) {
+		return nil, fmt.Errorf("openai embeddings: %d embeddings for %d inputs", len(resp.Data), len(texts))
+	}
+	vectors := make([][]float64, len(texts))
+	for _, d := range resp.Data {
+		if d.Index < 0 || int(d.Index) >= len(texts) {
+			return nil, fmt.Errorf("openai embeddings: index %d out of range", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
+
 // SimpleTextQuery sends a single text request and returns the text response.
 // Ideal for simple Q&A like JSON parsing.
 func (a *OpenAIAdapter) SimpleTextQuery(ctx context.Context, systemPrompt, userInput string) (string, error) {
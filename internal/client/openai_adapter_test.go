@@ -2,15 +2,25 @@ package client
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
+	"io"
 	"net/http"
+	"strings"
 	"sync"
 	"sync/atomic"
 	"testing"
 	"time"
 
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/llm"
+	"pr-review-automation/internal/metrics"
+
 	"github.com/openai/openai-go"
 	"github.com/openai/openai-go/option"
+	"github.com/openai/openai-go/shared"
+	"github.com/prometheus/client_golang/prometheus/testutil"
 )
 
 // TestOpenAIAdapter_Concurrency_Serialization verifies that requests are serialized
@@ -131,3 +141,276 @@ type roundTripperFunc struct {
 func (r *roundTripperFunc) RoundTrip(req *http.Request) (*http.Response, error) {
 	return r.f(req)
 }
+
+// TestOpenAIAdapter_Params verifies configured parameters fill unset request fields only
+func TestOpenAIAdapter_Params(t *testing.T) {
+	var body map[string]any
+	mockClient := openai.NewClient(option.WithHTTPClient(&http.Client{
+		Transport: &roundTripperFunc{func(req *http.Request) (*http.Response, error) {
+			json.NewDecoder(req.Body).Decode(&body)
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"choices": []}`)),
+			}, nil
+		}},
+	}))
+
+	temperature, topP := 0.7, 0.9
+	seed := int64(42)
+	adapter := NewOpenAIAdapterWithConfig(&mockClient, "test-model", "http://test", "key", 1)
+	adapter.SetParams(config.LLMParams{Temperature: &temperature, TopP: &topP, Seed: &seed, ExtraBody: map[string]any{"top_k": 20}})
+
+	_, err := adapter.Chat(context.Background(), openai.ChatCompletionNewParams{
+		Messages:    []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hello")},
+		Temperature: openai.Float(0),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	want := map[string]any{"temperature": float64(0), "top_p": 0.9, "seed": float64(42), "top_k": float64(20)}
+	for k, v := range want {
+		if body[k] != v {
+			t.Errorf("%s = %v, want %v (body %v)", k, body[k], v, body)
+		}
+	}
+	if _, ok := body["max_tokens"]; ok {
+		t.Errorf("unset max_tokens must not be sent: %v", body)
+	}
+}
+
+func TestOpenAIAdapter_RecordsUsage(t *testing.T) {
+	mockClient := openai.NewClient(option.WithHTTPClient(&http.Client{
+		Transport: &roundTripperFunc{func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": []string{"application/json"}},
+				Body:       io.NopCloser(strings.NewReader(`{"choices": [], "usage": {"prompt_tokens": 2000, "completion_tokens": 500}}`)),
+			}, nil
+		}},
+	}))
+	cfg := &config.Config{}
+	cfg.LLM.Pricing = []config.ModelPricing{{Models: []string{"usage-model*"}, Prompt: 2.5, Completion: 10}}
+	adapter := NewOpenAIAdapterWithConfig(&mockClient, "usage-model-1", "http://test", "key", 1)
+	adapter.SetPricing(cfg.PricingFor)
+
+	ctx := domain.WithProject(context.Background(), "PAY")
+	if _, err := adapter.Chat(ctx, openai.ChatCompletionNewParams{Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ToFloat64(metrics.LLMUsageTokens.WithLabelValues("usage-model-1", "PAY", "prompt")); got != 2000 {
+		t.Errorf("prompt tokens = %v", got)
+	}
+	if got := testutil.ToFloat64(metrics.LLMCost.WithLabelValues("usage-model-1", "PAY")); got != 0.01 {
+		t.Errorf("cost = %v, want 0.01", got)
+	}
+
+	// Outside a PR and for unpriced models only tokens are counted
+	if _, err := adapter.Chat(context.Background(), openai.ChatCompletionNewParams{Model: "other-model", Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hi")}}); err != nil {
+		t.Fatal(err)
+	}
+	if got := testutil.ToFloat64(metrics.LLMUsageTokens.WithLabelValues("other-model", "none", "completion")); got != 500 {
+		t.Errorf("completion tokens = %v", got)
+	}
+	if n := testutil.CollectAndCount(metrics.LLMCost, "agent_llm_cost_usd_total"); n != 1 {
+		t.Errorf("expected only the priced model's cost, got %d series", n)
+	}
+}
+
+func TestOpenAIAdapter_StrictSchemaFormat(t *testing.T) {
+	for _, strict := range []bool{true, false} {
+		var body map[string]any
+		mockClient := openai.NewClient(option.WithHTTPClient(&http.Client{
+			Transport: &roundTripperFunc{func(req *http.Request) (*http.Response, error) {
+				json.NewDecoder(req.Body).Decode(&body)
+				return &http.Response{
+					StatusCode: 200,
+					Header:     http.Header{"Content-Type": []string{"application/json"}},
+					Body:       io.NopCloser(strings.NewReader(`{"choices": []}`)),
+				}, nil
+			}},
+		}))
+		adapter := NewOpenAIAdapterWithConfig(&mockClient, "test-model", "http://test", "", 1)
+		adapter.SetCapabilities(llm.Capabilities{JSONSchema: true, StrictSchema: strict})
+
+		_, err := adapter.Chat(context.Background(), openai.ChatCompletionNewParams{
+			Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("review")},
+			ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+				JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{Name: "review_result", Schema: map[string]any{"type": "object"}},
+			}},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := "json_object"
+		if strict {
+			want = "json_schema"
+		}
+		if format, _ := body["response_format"].(map[string]any); format["type"] != want {
+			t.Errorf("strict %v: response_format = %v, want type %s", strict, body["response_format"], want)
+		}
+	}
+}
+
+func TestOpenAIAdapter_ChatStream(t *testing.T) {
+	var body map[string]any
+	sse := strings.Join([]string{
+		`data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"role":"assistant","content":"{\"summary\""}}]}`,
+		`data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":": \"ok\"}"},"finish_reason":"stop"}]}`,
+		`data: {"id":"1","object":"chat.completion.chunk","choices":[],"usage":{"prompt_tokens":9,"completion_tokens":4}}`,
+		`data: [DONE]`,
+	}, "\n\n") + "\n\n"
+	mockClient := openai.NewClient(option.WithHTTPClient(&http.Client{
+		Transport: &roundTripperFunc{func(req *http.Request) (*http.Response, error) {
+			json.NewDecoder(req.Body).Decode(&body)
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+				Body:       io.NopCloser(strings.NewReader(sse)),
+			}, nil
+		}},
+	}))
+	adapter := NewOpenAIAdapterWithConfig(&mockClient, "test-model", "http://test", "key", 1)
+
+	var deltas []string
+	resp, err := adapter.ChatStream(context.Background(), openai.ChatCompletionNewParams{
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hello")},
+	}, func(d string) { deltas = append(deltas, d) })
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(deltas) != 2 || deltas[0] != `{"summary"` {
+		t.Errorf("deltas = %q", deltas)
+	}
+	if resp.Choices[0].Message.Content != `{"summary": "ok"}` || resp.Choices[0].FinishReason != "stop" || resp.Usage.CompletionTokens != 4 {
+		t.Errorf("accumulated completion = %+v", resp)
+	}
+	if body["stream"] != true || body["model"] != "test-model" {
+		t.Errorf("request body = %v", body)
+	}
+	if opts, _ := body["stream_options"].(map[string]any); opts["include_usage"] != true {
+		t.Errorf("usage not requested: %v", body)
+	}
+}
+
+func TestOpenAIAdapter_ChatStream_Cancel(t *testing.T) {
+	// The body sends one chunk and then hangs, ignoring the request context like a stuck connection
+	body, writer := io.Pipe()
+	t.Cleanup(func() { writer.Close() })
+	go writer.Write([]byte(`data: {"id":"1","object":"chat.completion.chunk","choices":[{"index":0,"delta":{"content":"{"}}]}` + "\n\n"))
+
+	mockClient := openai.NewClient(option.WithMaxRetries(0), option.WithHTTPClient(&http.Client{
+		Transport: &roundTripperFunc{func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: 200,
+				Header:     http.Header{"Content-Type": []string{"text/event-stream"}},
+				Body:       body,
+			}, nil
+		}},
+	}))
+	adapter := NewOpenAIAdapterWithConfig(&mockClient, "test-model", "http://test", "key", 1)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var start time.Time
+	_, err := adapter.ChatStream(ctx, openai.ChatCompletionNewParams{
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("hello")},
+	}, func(string) {
+		start = time.Now()
+		cancel()
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("expected context.Canceled, got %v", err)
+	}
+	if elapsed := time.Since(start); elapsed > abandonGrace+time.Second {
+		t.Errorf("cancelled stream returned after %v", elapsed)
+	}
+	// The semaphore is released for the next review
+	select {
+	case adapter.sem <- struct{}{}:
+	default:
+		t.Error("semaphore still held after cancellation")
+	}
+}
+
+func TestOpenAIAdapter_ProbeJSONFormat(t *testing.T) {
+	tests := []struct {
+		name       string
+		probeCode  int
+		wantErr    bool
+		wantFormat bool
+	}{
+		{name: "supported", probeCode: http.StatusOK, wantFormat: true},
+		{name: "rejected", probeCode: http.StatusBadRequest, wantFormat: false},
+		{name: "server error keeps format", probeCode: http.StatusServiceUnavailable, wantErr: true, wantFormat: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var body map[string]any
+			probed := false
+			mockClient := openai.NewClient(option.WithMaxRetries(0), option.WithHTTPClient(&http.Client{
+				Transport: &roundTripperFunc{func(req *http.Request) (*http.Response, error) {
+					body = nil
+					json.NewDecoder(req.Body).Decode(&body)
+					code, resp := http.StatusOK, `{"choices": []}`
+					if !probed {
+						probed = true
+						code = tt.probeCode
+						if code != http.StatusOK {
+							resp = `{"error": {"message": "response_format is not supported"}}`
+						}
+					}
+					return &http.Response{
+						StatusCode: code,
+						Header:     http.Header{"Content-Type": []string{"application/json"}},
+						Body:       io.NopCloser(strings.NewReader(resp)),
+					}, nil
+				}},
+			}))
+			adapter := NewOpenAIAdapterWithConfig(&mockClient, "test-model", "http://test", "", 1)
+
+			if err := adapter.ProbeJSONFormat(context.Background()); (err != nil) != tt.wantErr {
+				t.Fatalf("probe error = %v, wantErr %v", err, tt.wantErr)
+			}
+
+			format := shared.NewResponseFormatJSONObjectParam()
+			_, err := adapter.Chat(context.Background(), openai.ChatCompletionNewParams{
+				Messages:       []openai.ChatCompletionMessageParamUnion{openai.UserMessage("review")},
+				ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{OfJSONObject: &format},
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, sent := body["response_format"]; sent != tt.wantFormat {
+				t.Errorf("response_format sent = %v, want %v", sent, tt.wantFormat)
+			}
+			if got := adapter.Capabilities().JSONSchema; got != tt.wantFormat {
+				t.Errorf("json_schema capability = %v, want %v", got, tt.wantFormat)
+			}
+		})
+	}
+}
+
+func TestRequestParams_LocalKeepAlive(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.LLM.Local.KeepAlive = "30m"
+	cfg.LLM.Params.ExtraBody = map[string]any{"top_k": 20}
+
+	if got := requestParams(cfg, config.LLMProviderOpenAI); got.ExtraBody["keep_alive"] != nil {
+		t.Errorf("keep_alive must only be sent to local models: %v", got.ExtraBody)
+	}
+
+	got := requestParams(cfg, config.LLMProviderLocal)
+	if got.ExtraBody["keep_alive"] != "30m" || got.ExtraBody["top_k"] != 20 {
+		t.Errorf("extra body = %v", got.ExtraBody)
+	}
+	if _, ok := cfg.LLM.Params.ExtraBody["keep_alive"]; ok {
+		t.Error("configured params must not be modified")
+	}
+
+	cfg.LLM.Params.ExtraBody["keep_alive"] = "-1"
+	if got := requestParams(cfg, config.LLMProviderLocal); got.ExtraBody["keep_alive"] != "-1" {
+		t.Errorf("extra_body keep_alive must win, got %v", got.ExtraBody["keep_alive"])
+	}
+}
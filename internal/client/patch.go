@@ -0,0 +1,48 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"sync"
+)
+
+// PatchBackend serves the read tools for diffs submitted through the API, which have no code
+// host and no working tree. Each diff is served under its own pull request id while it is
+// reviewed, so concurrent reviews do not see each other's diffs.
+type PatchBackend struct {
+	mu      sync.Mutex
+	next    int
+	patches map[string]*LocalBackend // Pull request id -> diff
+}
+
+// NewPatchBackend creates an empty patch backend
+func NewPatchBackend() *PatchBackend {
+	return &PatchBackend{patches: make(map[string]*LocalBackend)}
+}
+
+// Add registers diff and returns the pull request id it is served under. release removes it.
+func (b *PatchBackend) Add(diff string) (id string, release func()) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.next++
+	id = strconv.Itoa(b.next)
+	b.patches[id] = NewLocalBackend(diff, "")
+	return id, func() {
+		b.mu.Lock()
+		delete(b.patches, id)
+		b.mu.Unlock()
+	}
+}
+
+// CallTool executes a read tool against the diff registered under the pullRequestId argument
+func (b *PatchBackend) CallTool(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+	id := argString(args, "pullRequestId")
+	b.mu.Lock()
+	patch := b.patches[id]
+	b.mu.Unlock()
+	if patch == nil {
+		return nil, fmt.Errorf("patch %q not found", id)
+	}
+	return patch.CallTool(ctx, serverName, toolName, args)
+}
@@ -1,9 +1,14 @@
 package config
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"fmt"
 	"log/slog"
+	"maps"
 	"os"
+	"path"
+	"slices"
 	"strings"
 	"time"
 
@@ -12,13 +17,39 @@ import (
 
 // Default configuration values
 const (
-	DefaultMaxBodySize int64 = 2 * 1024 * 1024 // 2MB
-	DefaultConfigPath        = "config.yaml"
+	DefaultMaxBodySize       int64 = 2 * 1024 * 1024 // 2MB
+	DefaultConfigPath              = "config.yaml"
+	DefaultGitHubAPIURL            = "https://api.github.com"
+	DefaultGitLabAPIURL            = "https://gitlab.com/api/v4"
+	DefaultBitbucketCloudURL       = "https://api.bitbucket.org/2.0"
+	DefaultReviewTimeout           = 15 * time.Minute
 )
 
 // WebhookConfig holds configuration for webhook processing
 type WebhookConfig struct {
-	MaxRetries int `yaml:"max_retries"` // Max Retries for L2 extraction (default: 2)
+	MaxRetries int         `yaml:"max_retries"` // Max Retries for L2 extraction (default: 2)
+	Retry      RetryConfig `yaml:"retry"`       // Backoff between L2 extraction attempts; attempts defaults to max_retries + 1
+}
+
+// RetryConfig is a retry policy: exponential backoff from Backoff, capped at MaxBackoff,
+// with each delay randomized by ±Jitter
+type RetryConfig struct {
+	Attempts   int           `yaml:"attempts"`    // Total attempts including the first
+	Backoff    time.Duration `yaml:"backoff"`     // Delay before the second attempt; doubles for each further attempt
+	MaxBackoff time.Duration `yaml:"max_backoff"` // Cap on a single delay (0 = no cap)
+	MaxElapsed time.Duration `yaml:"max_elapsed"` // No retry starts this long after the first attempt (0 = no limit)
+	Jitter     float64       `yaml:"jitter"`      // Fraction of each delay randomized, 0-1
+}
+
+func (r RetryConfig) validate(name string) []string {
+	var errs []string
+	if r.Attempts < 0 || r.Backoff < 0 || r.MaxBackoff < 0 || r.MaxElapsed < 0 {
+		errs = append(errs, name+" values must not be negative")
+	}
+	if r.Jitter < 0 || r.Jitter > 1 {
+		errs = append(errs, fmt.Sprintf("%s.jitter must be between 0 and 1, got %v", name, r.Jitter))
+	}
+	return errs
 }
 
 // MCPServerConfig holds configuration for a single MCP server
@@ -30,6 +61,13 @@ type MCPServerConfig struct {
 	ResponseFilters []FilterConfig `yaml:"response_filters"` // Output filters
 }
 
+// MCPReplicaConfig holds a read-only replica of an MCP server. Heavy reads are served by the
+// replica; everything else, and any read the replica fails, goes to the primary.
+type MCPReplicaConfig struct {
+	MCPServerConfig `yaml:",inline"`
+	ReadTools       []string `yaml:"read_tools"` // Tools served by the replica (default: pull request diff and file content)
+}
+
 type FilterConfig struct {
 	Name    string                 `yaml:"name"`
 	Options map[string]interface{} `yaml:"options"`
@@ -42,6 +80,10 @@ type PromptsConfig struct {
 
 // Config holds the configuration for the PR review automation tool
 type Config struct {
+	Profile       string `yaml:"profile"` // Name of this configuration (e.g. prod, staging), shown in review summaries
+	ConfigVersion string `yaml:"-"`       // Short hash of the loaded config file; empty when running on defaults
+	LocalDiff     bool   `yaml:"-"`       // Set by the review CLI for a local diff, which needs no SCM connection
+
 	Log struct {
 		Level    string `yaml:"level"`  // DEBUG, INFO, WARN, ERROR
 		Format   string `yaml:"format"` // text, json
@@ -55,47 +97,328 @@ type Config struct {
 	} `yaml:"log"`
 
 	Server struct {
-		Port             int           `yaml:"port"`
-		ConcurrencyLimit int64         `yaml:"concurrency_limit"`
-		ReadTimeout      time.Duration `yaml:"read_timeout"`
-		WriteTimeout     time.Duration `yaml:"write_timeout"`
-		ShutdownTimeout  time.Duration `yaml:"shutdown_timeout"`
-		MaxBodySize      int64         `yaml:"max_body_size"`
-		QueueSize        int           `yaml:"queue_size"`
-		DebounceWindow   time.Duration `yaml:"debounce_window"`
-		WebhookSecret    string        `yaml:"-"` // From Env
+		Port             int             `yaml:"port"`
+		ConcurrencyLimit int64           `yaml:"concurrency_limit"`
+		ReadTimeout      time.Duration   `yaml:"read_timeout"`
+		WriteTimeout     time.Duration   `yaml:"write_timeout"`
+		ShutdownTimeout  time.Duration   `yaml:"shutdown_timeout"`
+		MaxBodySize      int64           `yaml:"max_body_size"`
+		QueueSize        int             `yaml:"queue_size"`
+		DebounceWindow   time.Duration   `yaml:"debounce_window"`
+		WebhookSecret    string          `yaml:"-"` // From Env
+		Autoscale        AutoscaleConfig `yaml:"autoscale"`
+		RateLimit        RateLimitConfig `yaml:"rate_limit"`
+		Priorities       PriorityConfig  `yaml:"priorities"`
+		ReviewTimeout    TimeoutConfig   `yaml:"review_timeout"`
 	} `yaml:"server"`
 
 	LLM struct {
-		Model    string        `yaml:"model"`
-		Endpoint string        `yaml:"endpoint"`
-		APIKey   string        `yaml:"api_key"` // From YAML or Env
-		Timeout  time.Duration `yaml:"timeout"`
+		Provider     string            `yaml:"provider"` // openai (default) or local (Ollama, vLLM and other self-hosted servers)
+		Model        string            `yaml:"model"`
+		Endpoint     string            `yaml:"endpoint"`
+		APIKey       string            `yaml:"api_key"` // From YAML or Env; optional for provider local
+		Timeout      time.Duration     `yaml:"timeout"`
+		Warmup       bool              `yaml:"warmup"`       // Send one low-cost completion at startup to load the model and prompt prefix
+		Params       LLMParams         `yaml:"params"`       // Request defaults for every chat completion
+		Local        LocalLLMConfig    `yaml:"local"`        // Used with provider local
+		Capabilities LLMCapabilities   `yaml:"capabilities"` // What llm.model supports; unset fields are detected
+		Routes       []LLMRoute        `yaml:"routes"`       // Per-project models; the first matching route reviews the PR
+		Fallbacks    []LLMTarget       `yaml:"fallbacks"`    // Tried in order when llm.model fails with a rate limit, server or context-length error; routes do not fall back
+		Tokenizers   []TokenizerConfig `yaml:"tokenizers"`   // BPE vocabularies for token counting; other models use a script-aware estimate
+		Pricing      []ModelPricing    `yaml:"pricing"`      // Token prices for cost metrics and stored review costs; the first matching entry applies
 	} `yaml:"llm"`
 
 	MCP struct {
-		Timeout time.Duration `yaml:"timeout"`
-		Retry   struct {
-			Attempts   int           `yaml:"attempts"`
-			Backoff    time.Duration `yaml:"backoff"`
-			MaxBackoff time.Duration `yaml:"max_backoff"`
-		} `yaml:"retry"`
+		Timeout        time.Duration `yaml:"timeout"`
+		SchemaCacheTTL time.Duration `yaml:"schema_cache_ttl"` // How long discovered tool schemas are reused (default: 10m)
+		Retry          RetryConfig   `yaml:"retry"`            // Retries of failed tool calls, reconnecting in between
 		CircuitBreaker struct {
 			FailureThreshold int           `yaml:"failure_threshold"`
 			OpenDuration     time.Duration `yaml:"open_duration"`
 		} `yaml:"circuit_breaker"`
-		Bitbucket  MCPServerConfig `yaml:"bitbucket"`
-		Jira       MCPServerConfig `yaml:"jira"`
-		Confluence MCPServerConfig `yaml:"confluence"`
+		Bitbucket        MCPServerConfig  `yaml:"bitbucket"`
+		BitbucketReplica MCPReplicaConfig `yaml:"bitbucket_replica"` // Optional read-only mirror (leave endpoint empty to disable)
+		Jira             MCPServerConfig  `yaml:"jira"`
+		Confluence       MCPServerConfig  `yaml:"confluence"`
 	} `yaml:"mcp"`
 
 	Prompts PromptsConfig `yaml:"prompts"`
 
 	Webhook WebhookConfig `yaml:"webhook"`
 
+	Review ReviewScopeConfig `yaml:"review"`
+
 	Pipeline PipelineConfig `yaml:"pipeline"`
 
 	Storage StorageConfig `yaml:"storage"`
+
+	Auth AuthConfig `yaml:"auth"`
+
+	JiraIssues  JiraIssueConfig   `yaml:"jira_issues"`
+	Approval    ApprovalConfig    `yaml:"approval"`
+	BuildStatus BuildStatusConfig `yaml:"build_status"`
+	RepoConfig  RepoConfigConfig  `yaml:"repo_config"`
+
+	GitHub GitHubConfig `yaml:"github"`
+
+	GitLab GitLabConfig `yaml:"gitlab"`
+
+	Gitea GiteaConfig `yaml:"gitea"`
+
+	BitbucketCloud BitbucketCloudConfig `yaml:"bitbucket_cloud"`
+
+	FaultInjection FaultInjectionConfig `yaml:"fault_injection"`
+
+	Queue QueueConfig `yaml:"queue"`
+
+	Polling PollingConfig `yaml:"polling"`
+
+	Commands CommandConfig `yaml:"commands"`
+
+	Conversation ConversationConfig `yaml:"conversation"`
+
+	Metrics MetricsConfig `yaml:"metrics"`
+
+	Budgets BudgetConfig `yaml:"budgets"`
+}
+
+// BudgetConfig caps the LLM spend of reviews per UTC day and month, for all projects together
+// and per project. Reviews that start once a limit is reached are skipped with a PR comment
+// explaining why; a review already running finishes.
+type BudgetConfig struct {
+	Enabled  bool                    `yaml:"enabled"`
+	Global   BudgetLimits            `yaml:"global"`   // All projects together
+	Project  BudgetLimits            `yaml:"project"`  // Each project without an entry in projects
+	Projects map[string]BudgetLimits `yaml:"projects"` // By project key (organization or group on other providers)
+}
+
+// BudgetLimits are spend limits; 0 means no limit
+type BudgetLimits struct {
+	DailyTokens   int64   `yaml:"daily_tokens"`
+	MonthlyTokens int64   `yaml:"monthly_tokens"`
+	DailyCost     float64 `yaml:"daily_cost"`   // USD; needs llm.pricing for the models used
+	MonthlyCost   float64 `yaml:"monthly_cost"` // USD; needs llm.pricing for the models used
+}
+
+func (l BudgetLimits) validate(name string) []string {
+	if l.DailyTokens < 0 || l.MonthlyTokens < 0 || l.DailyCost < 0 || l.MonthlyCost < 0 {
+		return []string{name + " limits must not be negative"}
+	}
+	return nil
+}
+
+// hasCost reports whether any cost limit is set
+func (l BudgetLimits) hasCost() bool {
+	return l.DailyCost > 0 || l.MonthlyCost > 0
+}
+
+// MetricsConfig bounds the project and repo labels of the core PR metrics
+// (agent_pull_requests_total, agent_processing_duration_seconds). Only listed projects and
+// repositories get their own series; all others share "other".
+type MetricsConfig struct {
+	Projects []string `yaml:"projects"` // Project keys labeled with their key; their repositories are labeled "other" unless in repos
+	Repos    []string `yaml:"repos"`    // Glob patterns on "PROJECT/repo" labeled with their project and repository, e.g. "PAY/*"
+}
+
+// ConversationConfig answers developer replies to AI review comments (pr:comment:added events
+// with a parent comment). Replies by service accounts and commands.ignore_users are not answered.
+type ConversationConfig struct {
+	Enabled  bool `yaml:"enabled"`
+	MaxTurns int  `yaml:"max_turns"` // Answers per thread, so a discussion does not go on forever; default: 5
+}
+
+// CommandConfig enables slash commands in Bitbucket Server pull request comments
+// (pr:comment:added events), e.g. "/ai review", "/ai explain file.go:42" and "/ai ignore"
+type CommandConfig struct {
+	Enabled      bool     `yaml:"enabled"`
+	Prefix       string   `yaml:"prefix"`        // Default: /ai
+	IgnoreUsers  []string `yaml:"ignore_users"`  // User slugs whose comments are never commands; service accounts are always ignored
+	ExplainLines int      `yaml:"explain_lines"` // Lines before and after the requested line sent with /ai explain; default: 20
+}
+
+// PollingConfig reviews pull requests found by listing the open pull requests of repositories
+// periodically, for Bitbucket Server instances where webhooks cannot be installed. Enable it on
+// one instance only; every poller submits the pull requests it finds.
+type PollingConfig struct {
+	Enabled  bool          `yaml:"enabled"`
+	Interval time.Duration `yaml:"interval"`  // Between polls of all repositories; default: 5m
+	Repos    []string      `yaml:"repos"`     // "PROJECT/repo" of every polled repository
+	PageSize int           `yaml:"page_size"` // Pull requests per list call; default: 50
+}
+
+// QueueConfig selects where queued reviews wait for a worker
+type QueueConfig struct {
+	Driver string           `yaml:"driver"` // memory (default), redis, nats or kafka
+	Role   string           `yaml:"role"`   // all (default), ingest or worker; see QueueRoleAll
+	Redis  RedisQueueConfig `yaml:"redis"`
+	NATS   NATSQueueConfig  `yaml:"nats"`
+	Kafka  KafkaQueueConfig `yaml:"kafka"`
+}
+
+// RedisQueueConfig keeps queued reviews in Redis, so they survive restarts and several server
+// instances share them
+type RedisQueueConfig struct {
+	Addr     string `yaml:"addr"`     // host:port
+	Password string `yaml:"-"`        // From Env REDIS_PASSWORD
+	DB       int    `yaml:"db"`       // Default: 0
+	Key      string `yaml:"key"`      // Prefix of the queue keys; default: pr-review:queue
+	Consumer string `yaml:"consumer"` // Stable name of this instance; reviews it was running when it stopped are queued again when it restarts (default: hostname)
+}
+
+// NATSQueueConfig queues reviews on a NATS JetStream work-queue stream. Workers share one durable
+// consumer; a review is redelivered when its worker stops without acknowledging it.
+type NATSQueueConfig struct {
+	URL      string        `yaml:"url"`      // e.g. nats://localhost:4222
+	Token    string        `yaml:"-"`        // From Env NATS_TOKEN
+	Stream   string        `yaml:"stream"`   // Created if missing; default: PR_REVIEWS
+	Subject  string        `yaml:"subject"`  // Default: pr-review.reviews
+	Consumer string        `yaml:"consumer"` // Durable consumer shared by all workers; default: pr-review-workers
+	AckWait  time.Duration `yaml:"ack_wait"` // Redelivery delay after a worker stops reporting progress; default: 2m
+}
+
+// KafkaQueueConfig queues reviews on a Kafka topic. Workers form one consumer group and commit
+// offsets only once a review and all reviews before it on the partition are done.
+type KafkaQueueConfig struct {
+	Brokers  []string `yaml:"brokers"`  // host:port
+	Topic    string   `yaml:"topic"`    // Default: pr-review.reviews
+	GroupID  string   `yaml:"group_id"` // Default: pr-review-workers
+	Username string   `yaml:"username"` // SASL/PLAIN user; empty disables SASL
+	Password string   `yaml:"-"`        // From Env KAFKA_PASSWORD
+	TLS      bool     `yaml:"tls"`
+}
+
+// FaultInjectionConfig simulates dependency failures so the resilience features (LLM fallbacks,
+// circuit breakers, degradation, the storage buffer) can be exercised in staging. Never enable
+// it in production.
+type FaultInjectionConfig struct {
+	Enabled bool        `yaml:"enabled"`
+	Header  string      `yaml:"header"` // Webhook and review API header naming faults for that review, e.g. X-Inject-Faults; empty = config faults only
+	Faults  []FaultRule `yaml:"faults"` // Injected into a share of all calls
+}
+
+// FaultRule injects one kind of fault
+type FaultRule struct {
+	Kind    string        `yaml:"kind"`    // mcp_timeout, llm_rate_limit, llm_malformed_json or storage_error
+	Rate    float64       `yaml:"rate"`    // Share of calls that fail, 0..1
+	Targets []string      `yaml:"targets"` // Tools (mcp_timeout), models (llm_*) or read/write (storage_error); empty = all
+	Delay   time.Duration `yaml:"delay"`   // mcp_timeout: how long a call hangs before failing; default: 5s
+}
+
+// GitHubConfig enables GitHub pull_request webhooks. Reviews of GitHub pull requests
+// read the diff and post comments through the GitHub REST API instead of the Bitbucket MCP server.
+type GitHubConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	APIURL        string        `yaml:"api_url"`      // Default: https://api.github.com; GitHub Enterprise: https://HOST/api/v3
+	WebhookPath   string        `yaml:"webhook_path"` // Default: /webhook/github
+	Timeout       time.Duration `yaml:"timeout"`      // Per API request; default: 30s
+	Token         string        `yaml:"-"`            // From Env GITHUB_TOKEN
+	WebhookSecret string        `yaml:"-"`            // From Env GITHUB_WEBHOOK_SECRET; verifies X-Hub-Signature-256
+}
+
+// JiraIssueConfig controls Jira issues filed for CRITICAL findings still present at merge (pr:merged)
+type JiraIssueConfig struct {
+	Enabled  bool                 `yaml:"enabled"`
+	Projects []JiraProjectMapping `yaml:"projects"` // First matching mapping wins; unmatched repositories are skipped
+}
+
+// JiraProjectMapping routes findings of matching repositories to a Jira project
+type JiraProjectMapping struct {
+	Repos       []string `yaml:"repos"`        // "PROJECT/repo" globs; empty = all repositories
+	JiraProject string   `yaml:"jira_project"` // Jira project key
+	IssueType   string   `yaml:"issue_type"`   // Default: Bug
+	Labels      []string `yaml:"labels"`
+}
+
+// ApprovalConfig lets the bot approve pull requests or mark them "needs work" from the review score
+type ApprovalConfig struct {
+	Enabled  bool           `yaml:"enabled"`
+	Projects []ApprovalRule `yaml:"projects"` // First matching rule wins; unmatched repositories are left alone
+}
+
+// ApprovalRule sets the score thresholds for matching repositories
+type ApprovalRule struct {
+	Repos          []string `yaml:"repos"`            // "PROJECT/repo" globs; empty = all repositories
+	ApproveScore   int      `yaml:"approve_score"`    // Approve at or above this score when there is no CRITICAL finding; 0 = never approve
+	NeedsWorkScore int      `yaml:"needs_work_score"` // Mark "needs work" below this score; 0 = never
+}
+
+// BuildStatusConfig publishes a build status for the reviewed commit, so branch permissions
+// can require the review to have run before merging
+type BuildStatusConfig struct {
+	Enabled        bool   `yaml:"enabled"`
+	Key            string `yaml:"key"`              // Default: ai-review
+	Name           string `yaml:"name"`             // Shown in the PR; default: AI Review
+	FailOnCritical bool   `yaml:"fail_on_critical"` // FAILED also when the review has CRITICAL findings, not only when it failed
+}
+
+// RepoConfigConfig reads per-repository settings (ignore globs, minimum severity, prompt,
+// enable/disable) from a file in the PR's source repository
+type RepoConfigConfig struct {
+	Enabled      bool   `yaml:"enabled"`
+	Path         string `yaml:"path"`          // Default: .ai-review.yaml
+	AllowDisable bool   `yaml:"allow_disable"` // Honor "enabled: false"; the file is read from the PR itself, so its author can change it
+}
+
+// GitLabConfig enables GitLab merge request webhooks. Reviews of GitLab merge requests
+// read the diff and post discussion threads through the GitLab REST API (v4).
+type GitLabConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	APIURL        string        `yaml:"api_url"`      // Default: https://gitlab.com/api/v4; self-managed: https://HOST/api/v4
+	WebhookPath   string        `yaml:"webhook_path"` // Default: /webhook/gitlab
+	Timeout       time.Duration `yaml:"timeout"`      // Per API request; default: 30s
+	Token         string        `yaml:"-"`            // From Env GITLAB_TOKEN (api scope)
+	WebhookSecret string        `yaml:"-"`            // From Env GITLAB_WEBHOOK_SECRET; compared with X-Gitlab-Token
+}
+
+// GiteaConfig enables Gitea and Forgejo pull request webhooks for self-hosted instances.
+// Both speak the same API; reviews read the diff and post review comments through it.
+type GiteaConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	APIURL        string        `yaml:"api_url"`      // Required: https://HOST/api/v1
+	WebhookPath   string        `yaml:"webhook_path"` // Default: /webhook/gitea
+	Timeout       time.Duration `yaml:"timeout"`      // Per API request; default: 30s
+	Token         string        `yaml:"-"`            // From Env GITEA_TOKEN
+	WebhookSecret string        `yaml:"-"`            // From Env GITEA_WEBHOOK_SECRET; verifies X-Gitea-Signature / X-Forgejo-Signature
+}
+
+// BitbucketCloudConfig enables a Bitbucket Cloud webhook endpoint next to the Bitbucket Server one
+// (/webhook). Cloud pull requests use the Cloud REST API (2.0); the workspace is the project key.
+type BitbucketCloudConfig struct {
+	Enabled       bool          `yaml:"enabled"`
+	APIURL        string        `yaml:"api_url"`      // Default: https://api.bitbucket.org/2.0
+	WebhookPath   string        `yaml:"webhook_path"` // Default: /webhook/bitbucket-cloud
+	Timeout       time.Duration `yaml:"timeout"`      // Per API request; default: 30s
+	Username      string        `yaml:"-"`            // From Env BITBUCKET_CLOUD_USERNAME; set for app passwords (basic auth)
+	Token         string        `yaml:"-"`            // From Env BITBUCKET_CLOUD_TOKEN; access token or app password
+	WebhookSecret string        `yaml:"-"`            // From Env BITBUCKET_CLOUD_WEBHOOK_SECRET; verifies X-Hub-Signature
+}
+
+// ReviewScopeConfig selects the repositories that are reviewed.
+// Checked in the webhook handler before queuing; the admin API can override single repositories at runtime.
+type ReviewScopeConfig struct {
+	EnabledProjects []string `yaml:"enabled_projects"`  // Project key globs, e.g. ["PAY", "CORE*"]; empty = all projects
+	DisabledRepos   []string `yaml:"disabled_repos"`    // "PROJECT/repo" globs, e.g. ["PAY/legacy-*", "*/docs"]
+	DryRun          bool     `yaml:"dry_run"`           // Review and store every PR, but post nothing (prompt evaluation on live traffic)
+	Drafts          string   `yaml:"drafts"`            // Draft PRs: review (default), skip or dry_run; skipped drafts are reviewed once they leave draft
+	SkipTags        []string `yaml:"skip_tags"`         // Title tags that opt a PR out, case-insensitive; default: ["[skip ai]"]
+	SkipLabels      []string `yaml:"skip_labels"`       // PR labels that opt a PR out, case-insensitive (GitHub, GitLab, Gitea)
+	BotAccounts     []string `yaml:"bot_accounts"`      // User names of bots, e.g. this reviewer's account; their PRs, pushes and comments are ignored
+	MinPostSeverity string   `yaml:"min_post_severity"` // Lowest severity posted as inline or file comments: CRITICAL, WARNING, INFO or NIT; default: WARNING with comment_merge, all without
+}
+
+// AuthConfig controls authentication of the API and metrics endpoints.
+// The webhook and health probes are not affected; the webhook uses its own signature check.
+type AuthConfig struct {
+	Enabled bool       `yaml:"enabled"`
+	Tokens  []APIToken `yaml:"tokens"`
+}
+
+// APIToken maps a static bearer token to a caller and role
+type APIToken struct {
+	Name     string `yaml:"name"`      // Caller name, used in logs and audit entries
+	Role     string `yaml:"role"`      // viewer, operator or admin
+	TokenEnv string `yaml:"token_env"` // Env var holding the token value
+	Token    string `yaml:"-"`         // From Env (TokenEnv)
 }
 
 // StorageConfig holds configuration for review persistence
@@ -103,6 +426,68 @@ type StorageConfig struct {
 	Driver  string        `yaml:"driver"`  // sqlite
 	DSN     string        `yaml:"dsn"`     // Connection string
 	Timeout time.Duration `yaml:"timeout"` // Timeout for storage operations (default: 5s)
+
+	EncryptionKey string `yaml:"-"` // From Env: base64 AES key (16/24/32 bytes); enables encryption at rest
+
+	Retention         map[string]time.Duration `yaml:"retention"`          // Max age per data class (e.g. reviews: 2160h); 0 keeps forever
+	RetentionInterval time.Duration            `yaml:"retention_interval"` // How often retention runs (default: 1h)
+
+	Resilience StorageResilienceConfig `yaml:"resilience"`
+}
+
+// StorageResilienceConfig keeps reviews independent of storage health: after repeated failures
+// the circuit opens, reads fail fast and writes are buffered in memory until storage recovers
+type StorageResilienceConfig struct {
+	Enabled          bool          `yaml:"enabled"`           // Default: true
+	FailureThreshold int           `yaml:"failure_threshold"` // Consecutive failures that open the circuit (default: 3)
+	OpenDuration     time.Duration `yaml:"open_duration"`     // How long the circuit stays open before a trial operation (default: 30s)
+	FlushInterval    time.Duration `yaml:"flush_interval"`    // How often buffered writes are retried (default: 10s)
+	MaxBuffered      int           `yaml:"max_buffered"`      // Buffered writes kept; the oldest are dropped beyond this (default: 1000)
+}
+
+// AutoscaleConfig lets the review worker pool grow with queue depth and average job duration,
+// and shrink back once the queue stays empty, instead of running concurrency_limit workers
+type AutoscaleConfig struct {
+	Enabled        bool          `yaml:"enabled"`
+	MinWorkers     int           `yaml:"min_workers"`      // Default: 1
+	MaxWorkers     int           `yaml:"max_workers"`      // Default: server.concurrency_limit
+	Interval       time.Duration `yaml:"interval"`         // How often the worker count is adjusted (default: 10s)
+	TargetWait     time.Duration `yaml:"target_wait"`      // Workers are added while queued reviews would wait longer than this (default: 1m)
+	ScaleDownDelay time.Duration `yaml:"scale_down_delay"` // How long the queue stays empty before idle workers stop (default: 5m)
+}
+
+// PriorityConfig orders the worker pool queue by priority instead of arrival: requested
+// reviews and slash commands first, then newly opened PRs, then new commits, then large PRs.
+// A job is queued as if it arrived boost earlier per level above the lowest, so a long wait
+// still gets lower priority jobs their turn.
+type PriorityConfig struct {
+	Enabled      bool          `yaml:"enabled"`
+	Boost        time.Duration `yaml:"boost"`          // Head start per priority level (default: 5m)
+	LargePRLines int           `yaml:"large_pr_lines"` // PRs changing more lines get the lowest priority, where the payload has the size (GitHub, Gitea); 0 = any size (default: 1000)
+}
+
+// TimeoutConfig bounds how long the review of one PR may run, from parsing the payload to
+// posting the last comment. A project timeout wins over a backend timeout, which wins over
+// the default.
+type TimeoutConfig struct {
+	Default  time.Duration            `yaml:"default"`  // Default: 15m
+	Backends map[string]time.Duration `yaml:"backends"` // By LLM provider of the model reviewing the PR (see llm.routes), e.g. {local: 45m}
+	Projects map[string]time.Duration `yaml:"projects"` // By project key, e.g. {MONO: 30m}
+}
+
+// RateLimitConfig limits how often the reviews of one project or repository start, with a token
+// bucket per project and per repository, so one noisy repository cannot starve the others.
+// A review over the limit waits until a token is free instead of being dropped.
+type RateLimitConfig struct {
+	Enabled bool      `yaml:"enabled"`
+	Project RateLimit `yaml:"project"` // Per project key (organization or group on other providers)
+	Repo    RateLimit `yaml:"repo"`    // Per repository
+}
+
+// RateLimit is a token bucket refilled at per_hour tokens an hour; a review takes one token
+type RateLimit struct {
+	PerHour float64 `yaml:"per_hour"` // 0 = no limit
+	Burst   int     `yaml:"burst"`    // Reviews that may start at once after an idle period (default: 1)
 }
 
 // PipelineConfig holds configuration for the 3-stage review pipeline
@@ -110,18 +495,276 @@ type PipelineConfig struct {
 	Enabled               bool   `yaml:"enabled"`
 	Backend               string `yaml:"backend"` // direct or agent
 	MaxConcurrentComments int    `yaml:"max_concurrent_comments"`
+	SerialCommentPosting  bool   `yaml:"serial_comment_posting"` // Post inline comments one at a time in file/line order
 	ResponseMaxStringLen  int    `yaml:"response_max_string_len"`
 
 	Stage1Diff    Stage1Config       `yaml:"stage1_diff"`
 	Stage2Context Stage2Config       `yaml:"stage2_context"`
 	Stage3Review  Stage3Config       `yaml:"stage3_review"`
 	CommentMerge  CommentMergeConfig `yaml:"comment_merge"`
+	Markers       MarkerConfig       `yaml:"markers"`
+
+	PostProcessing PostProcessingConfig `yaml:"post_processing"`
+	Tasks          TasksConfig          `yaml:"tasks"`
+	SummaryOnly    SummaryOnlyConfig    `yaml:"summary_only"`
+	WorkingHours   WorkingHoursConfig   `yaml:"working_hours"`
+	Summary        SummaryConfig        `yaml:"summary"`
+	Mentions       MentionsConfig       `yaml:"mentions"`
+	SkipNotes      SkipNotesConfig      `yaml:"skip_notes"`
+	AutoResolve    AutoResolveConfig    `yaml:"auto_resolve"`
+	SizeGate       SizeGateConfig       `yaml:"size_gate"`
+	SecretScan     SecretScanConfig     `yaml:"secret_scan"`
+	Linters        LintersConfig        `yaml:"linters"`
+	TestCoverage   TestCoverageConfig   `yaml:"test_coverage"`
+	Description    DescriptionConfig    `yaml:"description"`
+	PRPolicy       PRPolicyConfig       `yaml:"pr_policy"`
+
+	DuplicateDetection DuplicateDetectionConfig `yaml:"duplicate_detection"`
+	Assets             AssetsConfig             `yaml:"assets"`
+	Retrieval          RetrievalConfig          `yaml:"retrieval"`
+	Standards          StandardsConfig          `yaml:"standards"`
+}
+
+// StandardsConfig adds team coding standards kept on Confluence pages to the review prompt as
+// "Team Standards", so they are maintained in one place instead of copied into prompts
+type StandardsConfig struct {
+	Enabled  bool               `yaml:"enabled"`
+	Tool     string             `yaml:"tool"`      // Confluence MCP tool called with page_id; default: confluence_get_page
+	CacheTTL time.Duration      `yaml:"cache_ttl"` // How long a fetched page is reused; default: 1h
+	MaxChars int                `yaml:"max_chars"` // Standards text added per review; default: 12000
+	Projects []StandardsMapping `yaml:"projects"`  // First matching mapping wins; unmatched repositories get no standards
+}
+
+// StandardsMapping selects the standards pages of some repositories
+type StandardsMapping struct {
+	Repos []string `yaml:"repos"` // "PROJECT/repo" globs, e.g. "PAY/*"
+	Pages []string `yaml:"pages"` // Confluence page IDs
+}
+
+// RetrievalConfig indexes documentation (docs/ folders, Confluence spaces) and repository code into a local vector
+// store and adds the snippets most relevant to a PR to the review context
+type RetrievalConfig struct {
+	Enabled         bool              `yaml:"enabled"`
+	Embedding       LLMTarget         `yaml:"embedding"`        // OpenAI-compatible /embeddings model, e.g. nomic-embed-text on Ollama
+	IndexPath       string            `yaml:"index_path"`       // Index file, reused across restarts; default: data/doc_index.json
+	RefreshInterval time.Duration     `yaml:"refresh_interval"` // How often sources are re-indexed; default: 6h
+	TopK            int               `yaml:"top_k"`            // Snippets added per review; default: 4
+	MinScore        float64           `yaml:"min_score"`        // Snippets less similar than this (cosine, 0..1) are dropped; default: 0.3
+	ChunkSize       int               `yaml:"chunk_size"`       // Characters per indexed snippet; default: 1500
+	Sources         []RetrievalSource `yaml:"sources"`
+
+	Overrides []RetrievalOverride `yaml:"overrides"` // Per-project settings; the first matching entry applies
+}
+
+// RetrievalOverride changes retrieval for the reviews of some repositories
+type RetrievalOverride struct {
+	Repos    []string `yaml:"repos"`     // "PROJECT/repo" globs, e.g. "PAY/*"
+	Enabled  *bool    `yaml:"enabled"`   // false adds no snippets to their reviews
+	TopK     int      `yaml:"top_k"`     // Snippets added per review; 0 keeps top_k
+	MinScore float64  `yaml:"min_score"` // 0 keeps min_score
+}
+
+// RetrievalSource is one documentation source of the index
+type RetrievalSource struct {
+	Name    string   `yaml:"name"`    // Shown with its snippets; default: path or space
+	Type    string   `yaml:"type"`    // dir, code or confluence
+	Repos   []string `yaml:"repos"`   // "PROJECT/repo" globs the source is used for; empty = all repositories
+	Path    string   `yaml:"path"`    // dir, code: local directory, e.g. a checkout of a docs or code repository
+	Include []string `yaml:"include"` // dir, code: file globs; default: *.md, *.txt, *.rst, *.adoc (dir), common source files (code)
+	Space   string   `yaml:"space"`   // confluence: space key, read through the Confluence MCP server
+	Tool    string   `yaml:"tool"`    // confluence: search tool; default: confluence_search
+	Limit   int      `yaml:"limit"`   // confluence: pages indexed; default: 50
+}
+
+// AssetsConfig controls how image and diagram files added by a PR are handled. They are
+// listed in the summary as unreviewed assets; with Vision, small raster images are sent
+// to the model for a short sanity comment instead.
+type AssetsConfig struct {
+	Enabled    bool     `yaml:"enabled"`
+	Vision     bool     `yaml:"vision"`     // Send images to the LLM (OpenAI image_url content parts); off when it lacks the vision capability
+	Repos      []string `yaml:"repos"`      // "PROJECT/repo" globs; empty = all repositories
+	MaxSize    int      `yaml:"max_size"`   // Larger images are not sent to the model, in bytes; default: 524288
+	MaxImages  int      `yaml:"max_images"` // Images sent to the model per PR; default: 3
+	Extensions []string `yaml:"extensions"` // Asset file extensions; default: png, jpg, jpeg, gif, webp, svg, drawio, puml, mmd
+}
+
+// DuplicateDetectionConfig compares the diff of each PR with the recently reviewed PRs of the
+// same repository and notes likely duplicates and reverts in the summary. Requires storage.
+type DuplicateDetectionConfig struct {
+	Enabled    bool          `yaml:"enabled"`
+	Window     time.Duration `yaml:"window"`     // How far back to compare; default: 336h (14 days)
+	Similarity float64       `yaml:"similarity"` // Minimum share of identical changed lines (0-1); default: 0.8
+	MinLines   int           `yaml:"min_lines"`  // Diffs with fewer changed lines are not compared; default: 5
+}
+
+// SizeGateConfig skips the review of PRs too large to review without truncation and posts a
+// comment suggesting to split them. Sizes are measured on the preprocessed diff.
+type SizeGateConfig struct {
+	Enabled   bool `yaml:"enabled"`
+	MaxFiles  int  `yaml:"max_files"`  // Changed files; 0 = no limit (default: 100)
+	MaxTokens int  `yaml:"max_tokens"` // Estimated diff tokens; 0 = no limit (default: 150000)
+}
+
+// SecretScanConfig scans the added lines of a PR for secrets before the review. Secrets are
+// posted as CRITICAL findings whether or not the model reports them.
+type SecretScanConfig struct {
+	Enabled       bool     `yaml:"enabled"`
+	MinEntropy    float64  `yaml:"min_entropy"`    // Shannon entropy (bits per character) a value assigned to a password, token or key name needs; default: 3.5
+	Patterns      []string `yaml:"patterns"`       // Extra regular expressions reported as secrets, on top of the built-in ones
+	Ignore        []string `yaml:"ignore"`         // File globs not scanned, e.g. test fixtures
+	BlockExternal bool     `yaml:"block_external"` // When secrets are found, do not send the diff to a model outside provider local; only the scan findings are posted
+}
+
+// LintersConfig runs static analyzers on the changed files of a PR, fetched through MCP into a
+// temporary directory, and merges their findings on added lines with the model's
+type LintersConfig struct {
+	Enabled     bool           `yaml:"enabled"`
+	Timeout     time.Duration  `yaml:"timeout"`      // Per linter run; default: 2m
+	MaxFiles    int            `yaml:"max_files"`    // Changed files fetched per review; default: 50
+	MaxFindings int            `yaml:"max_findings"` // Findings kept per linter and review; default: 20
+	Tools       []LinterConfig `yaml:"tools"`
+}
+
+// LinterConfig is one static analyzer. Its command runs in the directory holding the fetched
+// files, with the paths of the matching files appended.
+type LinterConfig struct {
+	Name       string            `yaml:"name"`       // Shown with its findings
+	Command    []string          `yaml:"command"`    // Program and arguments, e.g. ["ruff", "check", "--output-format=json"]
+	Files      []string          `yaml:"files"`      // Globs of the files it checks, e.g. ["*.py"]
+	Format     string            `yaml:"format"`     // Output format: golangci, ruff or gcc (file:line:col: level: message, as clang-tidy prints)
+	Severity   string            `yaml:"severity"`   // Severity of findings no severities entry matches; default: WARNING
+	Severities map[string]string `yaml:"severities"` // Rule prefix (e.g. "F", "gosec", "bugprone-") or reported level (error, warning, note) to severity
+}
+
+// TestCoverageConfig lists in the summary the changed Go, Java and Python source files whose
+// tests the PR does not change, with the functions it changes in them
+type TestCoverageConfig struct {
+	Enabled   bool     `yaml:"enabled"`
+	Skeletons bool     `yaml:"skeletons"` // Add a table-driven test skeleton per file
+	MaxFiles  int      `yaml:"max_files"` // Files listed per review; default: 5
+	Ignore    []string `yaml:"ignore"`    // Source file globs that need no tests, e.g. generated code or main packages
+}
+
+// DescriptionConfig generates a description from the diff for PRs opened without one, and
+// either sets it as the PR description or proposes it in a comment
+type DescriptionConfig struct {
+	Enabled  bool     `yaml:"enabled"`
+	Action   string   `yaml:"action"`    // comment (default) or update (bitbucket_update_pull_request)
+	Projects []string `yaml:"projects"`  // Project keys, e.g. FAS; empty = all projects
+	MaxChars int      `yaml:"max_chars"` // Diff characters sent to the model; default: 30000
+}
+
+// PRPolicyConfig checks the title and branches of a PR against team conventions without the
+// model, and posts a WARNING comment listing the violations
+type PRPolicyConfig struct {
+	Enabled        bool     `yaml:"enabled"`
+	Repos          []string `yaml:"repos"`           // "PROJECT/repo" globs; empty = all repositories
+	TitlePattern   string   `yaml:"title_pattern"`   // Regular expression the title must match, e.g. ^[A-Z][A-Z0-9]+-\d+ for a leading Jira key
+	BranchPattern  string   `yaml:"branch_pattern"`  // Regular expression the source branch must match
+	TargetBranches []string `yaml:"target_branches"` // Globs of the branches PRs may target; empty = any
+}
+
+// SkipNotesConfig controls the one-line PR comment explaining why a review was skipped
+type SkipNotesConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	Reasons []string `yaml:"reasons"` // Skip reasons that get a note; empty = all
+}
+
+// AutoResolveConfig resolves the bot's comments from earlier commits once their findings are gone
+type AutoResolveConfig struct {
+	Enabled    bool   `yaml:"enabled"`
+	Action     string `yaml:"action"`      // resolve (default) or delete
+	LineWindow int    `yaml:"line_window"` // A finding this many lines away still keeps an inline comment open (default: 3)
+}
+
+// MentionsConfig controls @mentions in the summary comment when CRITICAL findings exist
+type MentionsConfig struct {
+	Enabled   bool     `yaml:"enabled"`
+	Reviewers bool     `yaml:"reviewers"` // Also mention the reviewers assigned in the webhook payload
+	Repos     []string `yaml:"repos"`     // "PROJECT/repo" globs; empty = all repositories
+}
+
+// SummaryConfig controls how the review summary comment is rendered
+type SummaryConfig struct {
+	Layout   string              `yaml:"layout"`   // classic, executive_first, developer_first (default: classic)
+	Template string              `yaml:"template"` // Optional text/template file for the two-view layouts
+	Repos    []SummaryRepoLayout `yaml:"repos"`    // Per-repository layout overrides; first match wins
+	Footer   SummaryFooterConfig `yaml:"footer"`
+}
+
+// SummaryFooterConfig adds the bot version, prompt version, model and config profile to the
+// summary footer, so every review can be traced to the configuration that produced it
+type SummaryFooterConfig struct {
+	Provenance bool   `yaml:"provenance"` // Default: true
+	ReportURL  string `yaml:"report_url"` // Public base URL of this server; links the footer to the stored review and its execution report
+}
+
+// SummaryRepoLayout overrides the summary layout for matching repositories
+type SummaryRepoLayout struct {
+	Repos  []string `yaml:"repos"` // "PROJECT/repo" globs
+	Layout string   `yaml:"layout"`
+}
+
+// WorkingHoursConfig holds non-critical findings outside working hours.
+// CRITICAL findings are always posted immediately; the rest is released at the next Start.
+type WorkingHoursConfig struct {
+	Enabled  bool     `yaml:"enabled"`
+	Timezone string   `yaml:"timezone"` // IANA name, e.g. "Europe/Berlin" (default: server local time)
+	Start    string   `yaml:"start"`    // HH:MM (default: 09:00)
+	End      string   `yaml:"end"`      // HH:MM (default: 18:00)
+	Weekdays []string `yaml:"weekdays"` // Working days, e.g. [mon, tue] (default: mon-fri)
+}
+
+// TasksConfig controls blocking Bitbucket tasks created for CRITICAL findings
+type TasksConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	Repos   []string `yaml:"repos"` // "PROJECT/repo" globs; empty = all repositories
+}
+
+// SummaryOnlyConfig posts only the summary comment for matching repositories, e.g. docs or
+// infrastructure repositories where inline comments are more noise than help. The review runs
+// in full; its inline findings are stored and available through the reviews API.
+type SummaryOnlyConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	Repos   []string `yaml:"repos"` // "PROJECT/repo" globs; empty = all repositories
+}
+
+// PostProcessingConfig configures rules applied to findings before they are posted
+type PostProcessingConfig struct {
+	RulesFile    string           `yaml:"rules_file"`    // YAML rules file (drop/downgrade/rewrite/tag); empty disables
+	SeverityCaps []SeverityCap    `yaml:"severity_caps"` // Highest severity allowed for findings in matching files
+	Categories   map[string]bool  `yaml:"categories"`    // false mutes a finding category (security, performance, style, logic), e.g. style: false
+	Confidence   ConfidenceConfig `yaml:"confidence"`    // Cutoff for findings the model is unsure about (review contract v2)
+}
+
+// ConfidenceConfig handles findings whose confidence, as answered by the model under review
+// contract v2, is below Min. Findings without a confidence are kept.
+type ConfidenceConfig struct {
+	Min    float64 `yaml:"min"`    // From 0 to 1; 0 disables the cutoff
+	Action string  `yaml:"action"` // drop (default) or label
+}
+
+// SeverityCap lowers findings in matching files to at most MaxSeverity, e.g. so tests,
+// examples or generated code never produce CRITICAL findings. The score, verdict and
+// blocking features (tasks, holds, mentions, Jira issues) see the capped severity.
+type SeverityCap struct {
+	Repos       []string `yaml:"repos"`        // "PROJECT/repo" globs; empty = all repositories
+	Files       []string `yaml:"files"`        // File path globs, e.g. "**/*_test.go", "examples/**"
+	MaxSeverity string   `yaml:"max_severity"` // WARNING, INFO or NIT
+}
+
+// MarkerConfig controls the hidden HTML markers embedded in posted comments
+type MarkerConfig struct {
+	Prefix         string   `yaml:"prefix"`          // Default: "<!-- ai-review::"
+	Suffix         string   `yaml:"suffix"`          // Default: "-->"
+	LegacyPrefixes []string `yaml:"legacy_prefixes"` // Older prefixes still recognized in existing comments
 }
 
 type CommentMergeConfig struct {
 	Enabled           bool   `yaml:"enabled"`
 	HighSeverityMerge string `yaml:"high_severity_merge"` // "by_file" | "none" (none = Hybrid Mode)
 	LowSeverityMerge  string `yaml:"low_severity_merge"`  // "to_summary" | "none"
+	UpdateInPlace     bool   `yaml:"update_in_place"`     // Edit the file comment of an earlier commit instead of posting a new one
 }
 
 type Stage1Config struct {
@@ -136,9 +779,80 @@ type Stage2Config struct {
 
 type Stage3Config struct {
 	PromptTemplate   string            `yaml:"prompt_template"`
+	Contract         string            `yaml:"contract"`          // Review JSON contract the prompt asks for: v1 or v2 (default: v1)
+	StructuredOutput string            `yaml:"structured_output"` // json_object (default) or json_schema: the contract's schema, enforced by the model or a forced tool call
 	Temperature      float64           `yaml:"temperature"`
+	Params           LLMParams         `yaml:"params"` // Overrides llm.params and temperature for review requests
 	MaxContextTokens int               `yaml:"max_context_tokens"`
 	Degradation      DegradationConfig `yaml:"degradation"`
+
+	AdaptiveTuning AdaptiveTuningConfig `yaml:"adaptive_tuning"`
+	StreamDebug    StreamDebugConfig    `yaml:"stream_debug"`
+	OutcomeCheck   OutcomeCheckConfig   `yaml:"outcome_check"`
+	SchemaRepair   SchemaRepairConfig   `yaml:"schema_repair"`
+	Synthesis      SynthesisConfig      `yaml:"synthesis"`
+	Security       SecurityReviewConfig `yaml:"security"`
+	Ensemble       EnsembleConfig       `yaml:"ensemble"`
+}
+
+// EnsembleConfig runs each review call several times at a raised temperature and keeps only
+// the findings a majority of the runs report (self-consistency)
+type EnsembleConfig struct {
+	Runs          int     `yaml:"runs"`           // Reviews per call; 0 or 1 disables (at most 9)
+	Temperature   float64 `yaml:"temperature"`    // Sampling temperature of the runs (default: 0.7)
+	LineTolerance int     `yaml:"line_tolerance"` // Findings in the same file this many lines apart count as one (default: 3)
+}
+
+// SecurityReviewConfig adds a second Stage 3 pass with a security-focused prompt. Its findings
+// are posted individually with their own marker, apart from the style and logic feedback.
+type SecurityReviewConfig struct {
+	Enabled        bool     `yaml:"enabled"`
+	PromptTemplate string   `yaml:"prompt_template"` // Default: pipeline/stage3_security.md
+	Repos          []string `yaml:"repos"`           // "PROJECT/repo" globs reviewed twice; empty = all repositories
+}
+
+// SynthesisConfig replaces the concatenated chunk summaries of a chunked review with one
+// PR-level summary and score written by a final LLM call over all chunk results
+type SynthesisConfig struct {
+	Enabled bool `yaml:"enabled"` // Uses prompts/pipeline/stage3_synthesis.md
+}
+
+// OutcomeCheckConfig controls how review responses are classified as refusal, empty or
+// low-content (no findings and a generic summary) and whether such a response is retried
+type OutcomeCheckConfig struct {
+	Retry           bool     `yaml:"retry"`             // Retry once with a reinforcement prompt (default: true)
+	MinChangedLines int      `yaml:"min_changed_lines"` // Smaller changes are never low-content (default: 30)
+	RefusalPatterns []string `yaml:"refusal_patterns"`  // Extra case-insensitive regular expressions marking a refusal
+}
+
+// SchemaRepairConfig checks review answers against the result JSON Schema and asks the model
+// once to correct an answer that does not parse or match it
+type SchemaRepairConfig struct {
+	Enabled bool `yaml:"enabled"` // Uses prompts/pipeline/stage3_repair.md (default: true)
+}
+
+// StreamDebugConfig streams review completions and appends the model output, as received,
+// to one file per review. Meant for inspecting truncated or garbled responses; lines are
+// redacted before they are written.
+type StreamDebugConfig struct {
+	Enabled bool     `yaml:"enabled"`
+	Dir     string   `yaml:"dir"`    // Artifact directory; default: debug/streams
+	Repos   []string `yaml:"repos"`  // "PROJECT/repo" globs; empty = all repositories
+	Redact  []string `yaml:"redact"` // Extra regular expressions replaced with [REDACTED], on top of the built-in secret patterns
+}
+
+// AdaptiveTuningConfig bounds automatic per-repository tuning of the review token budget
+// (max_context_tokens) and L1 context lines based on observed overflow and empty-result rates
+type AdaptiveTuningConfig struct {
+	Enabled         bool    `yaml:"enabled"`
+	MinTokens       int     `yaml:"min_tokens"`        // Lower bound for the token budget (default: max_context_tokens / 4)
+	MaxTokens       int     `yaml:"max_tokens"`        // Upper bound for the token budget (default: max_context_tokens)
+	MinContextLines int     `yaml:"min_context_lines"` // Lower bound for L1 context lines (default: 10)
+	MaxContextLines int     `yaml:"max_context_lines"` // Upper bound for L1 context lines (default: 4 * l1_context_lines)
+	Window          int     `yaml:"window"`            // Chunks observed before each adjustment (default: 20)
+	Step            float64 `yaml:"step"`              // Relative change per adjustment (default: 0.1)
+	MaxOverflowRate float64 `yaml:"max_overflow_rate"` // Shrink when the context-overflow rate exceeds this (default: 0.1)
+	MaxEmptyRate    float64 `yaml:"max_empty_rate"`    // Grow when the empty-result rate exceeds this (default: 0.5)
 }
 
 type DegradationConfig struct {
@@ -147,6 +861,132 @@ type DegradationConfig struct {
 	L3DiffOnly     bool `yaml:"l3_diff_only"`     // L3: Fallback to diff only (default: true)
 }
 
+// LLMParams are optional chat completion parameters. Unset fields keep the endpoint's defaults.
+type LLMParams struct {
+	Temperature *float64       `yaml:"temperature"`
+	TopP        *float64       `yaml:"top_p"`
+	MaxTokens   *int64         `yaml:"max_tokens"`
+	Seed        *int64         `yaml:"seed"`       // Fixed seed for reproducible evaluation runs
+	ExtraBody   map[string]any `yaml:"extra_body"` // Provider-specific fields merged into the request body, e.g. top_k
+}
+
+// LocalLLMConfig tunes requests for self-hosted models, which load slowly and run on hardware
+// that takes minutes for a large review
+type LocalLLMConfig struct {
+	KeepAlive string        `yaml:"keep_alive"` // How long Ollama keeps the model loaded after a request, e.g. "30m" or "-1" for forever (default: 30m; "" to omit)
+	Timeout   time.Duration `yaml:"timeout"`    // Request timeout replacing llm.timeout (default: 10m)
+	ProbeJSON bool          `yaml:"probe_json"` // Check at startup that the server accepts JSON response_format and stop sending it if not (default: true)
+}
+
+// LLMTarget is a model other than llm.model, on its own endpoint if needed.
+// Unset fields fall back to the llm section.
+type LLMTarget struct {
+	Provider     string          `yaml:"provider"`     // openai or local (default: llm.provider)
+	Model        string          `yaml:"model"`        // Required
+	Endpoint     string          `yaml:"endpoint"`     // Default: llm.endpoint
+	APIKeyEnv    string          `yaml:"api_key_env"`  // Env var holding the API key (default: llm.api_key)
+	APIKey       string          `yaml:"-"`            // From Env (APIKeyEnv)
+	Capabilities LLMCapabilities `yaml:"capabilities"` // What the model supports; not inherited from llm.capabilities
+}
+
+// LLMCapabilities declares what a model supports. Unset fields are detected from the provider
+// and the model name; JSON output is also checked by the startup probe of local servers.
+type LLMCapabilities struct {
+	Tools        *bool `yaml:"tools"`         // Function calling (default: true for openai, known model families for local)
+	JSONSchema   *bool `yaml:"json_schema"`   // JSON response_format; without it review output is cleaned of markdown fences (default: true)
+	StrictSchema *bool `yaml:"strict_schema"` // response_format json_schema with strict adherence (default: true for known OpenAI models on provider openai)
+	Vision       *bool `yaml:"vision"`        // Image input, required by pipeline.assets.vision (default: known model families)
+	Streaming    *bool `yaml:"streaming"`     // Streamed completions, required by stage3_review.stream_debug (default: true)
+	MaxContext   int   `yaml:"max_context"`   // Context window in tokens; caps stage3_review.max_context_tokens (default: known model families)
+}
+
+// TokenizerConfig counts the tokens of matching models with their BPE vocabulary, so chunk
+// sizes and degradation thresholds match what the model reads
+type TokenizerConfig struct {
+	Models []string `yaml:"models"` // Model name globs, e.g. ["gpt-4o*", "gpt-4.1*"]
+	File   string   `yaml:"file"`   // Vocabulary in tiktoken format, e.g. o200k_base.tiktoken or Llama 3's tokenizer.model
+}
+
+// ModelPricing prices the tokens of matching models
+type ModelPricing struct {
+	Models     []string `yaml:"models"`     // Model name globs, e.g. ["gpt-4o", "gpt-4o-2024-*"]
+	Prompt     float64  `yaml:"prompt"`     // USD per million prompt tokens
+	Completion float64  `yaml:"completion"` // USD per million completion tokens
+}
+
+// Cost returns the price of the tokens in USD
+func (p ModelPricing) Cost(promptTokens, completionTokens int64) float64 {
+	return (float64(promptTokens)*p.Prompt + float64(completionTokens)*p.Completion) / 1e6
+}
+
+// PricingFor returns the first llm.pricing entry matching model; ok is false for unpriced models
+func (c *Config) PricingFor(model string) (pricing ModelPricing, ok bool) {
+	for _, p := range c.LLM.Pricing {
+		for _, glob := range p.Models {
+			if match, _ := path.Match(glob, model); match {
+				return p, true
+			}
+		}
+	}
+	return ModelPricing{}, false
+}
+
+// LLMRoute sends the reviews of matching repositories to another model or endpoint
+type LLMRoute struct {
+	Projects  []string `yaml:"projects"` // Project keys, e.g. FAS
+	Repos     []string `yaml:"repos"`    // Glob patterns on "PROJECT/repo", e.g. "TOOLS/cli-*"
+	LLMTarget `yaml:",inline"`
+	Contract  string `yaml:"contract"` // Review contract v1 or v2 (default: pipeline.stage3_review.contract)
+}
+
+// IsLocalLLM reports whether the LLM is a self-hosted OpenAI-compatible server
+func (c *Config) IsLocalLLM() bool {
+	return c.LLM.Provider == LLMProviderLocal
+}
+
+// LLMTimeout returns the request timeout for the configured LLM provider
+func (c *Config) LLMTimeout() time.Duration {
+	return c.LLMTimeoutFor(c.LLM.Provider)
+}
+
+// LLMTimeoutFor returns the request timeout for an LLM provider
+func (c *Config) LLMTimeoutFor(provider string) time.Duration {
+	if provider == LLMProviderLocal && c.LLM.Local.Timeout > 0 {
+		return c.LLM.Local.Timeout
+	}
+	return c.LLM.Timeout
+}
+
+// ReviewTimeoutFor returns how long the review of a PR of the project may run when its model
+// is served by the LLM provider
+func (c *Config) ReviewTimeoutFor(provider, project string) time.Duration {
+	rt := c.Server.ReviewTimeout
+	if t := rt.Projects[project]; t > 0 {
+		return t
+	}
+	if t := rt.Backends[provider]; t > 0 {
+		return t
+	}
+	if rt.Default > 0 {
+		return rt.Default
+	}
+	return DefaultReviewTimeout
+}
+
+// ShortestReviewTimeout returns the shortest time any review may run, the limit of the steps
+// before the PR and its timeout are known
+func (c *Config) ShortestReviewTimeout() time.Duration {
+	shortest := c.ReviewTimeoutFor("", "")
+	for _, timeouts := range []map[string]time.Duration{c.Server.ReviewTimeout.Backends, c.Server.ReviewTimeout.Projects} {
+		for _, t := range timeouts {
+			if t > 0 && t < shortest {
+				shortest = t
+			}
+		}
+	}
+	return shortest
+}
+
 // GetLogLevel returns the slog.Level based on Log.Level string
 func (c *Config) GetLogLevel() slog.Level {
 	switch strings.ToUpper(c.Log.Level) {
@@ -177,17 +1017,52 @@ func LoadConfig() *Config {
 	cfg.Server.WriteTimeout = 30 * time.Second
 	cfg.Server.ShutdownTimeout = 30 * time.Second
 	cfg.Server.MaxBodySize = DefaultMaxBodySize
+	cfg.Server.Autoscale.MinWorkers = 1
+	cfg.Server.Autoscale.Interval = 10 * time.Second
+	cfg.Server.Autoscale.TargetWait = time.Minute
+	cfg.Server.Autoscale.ScaleDownDelay = 5 * time.Minute
+	cfg.Server.Priorities.Boost = 5 * time.Minute
+	cfg.Server.Priorities.LargePRLines = 1000
+	cfg.Server.ReviewTimeout.Default = DefaultReviewTimeout
 	cfg.LLM.Endpoint = "https://api.openai.com/v1"
 	cfg.LLM.Model = "gpt-4o"
 	cfg.LLM.Timeout = 120 * time.Second
+	cfg.LLM.Provider = LLMProviderOpenAI
+	cfg.LLM.Local.KeepAlive = "30m"
+	cfg.LLM.Local.Timeout = 10 * time.Minute
+	cfg.LLM.Local.ProbeJSON = true
 	cfg.MCP.Timeout = 30 * time.Second
-	cfg.MCP.Retry.Attempts = 3
+	cfg.MCP.SchemaCacheTTL = 10 * time.Minute
+	cfg.MCP.Retry.Attempts = 2
 	cfg.MCP.Retry.Backoff = 1 * time.Second
 	cfg.MCP.Retry.MaxBackoff = 30 * time.Second
+	cfg.MCP.Retry.MaxElapsed = 2 * time.Minute
+	cfg.MCP.Retry.Jitter = DefaultRetryJitter
 	cfg.MCP.CircuitBreaker.FailureThreshold = 3
 	cfg.MCP.CircuitBreaker.OpenDuration = 30 * time.Second
 	cfg.Prompts.Dir = "prompts"
+	cfg.Pipeline.Summary.Footer.Provenance = true
 	cfg.Webhook.MaxRetries = 2
+	cfg.Webhook.Retry.Backoff = 1 * time.Second
+	cfg.Webhook.Retry.MaxBackoff = 10 * time.Second
+	cfg.Webhook.Retry.Jitter = DefaultRetryJitter
+	cfg.GitHub.APIURL = DefaultGitHubAPIURL
+	cfg.GitHub.WebhookPath = "/webhook/github"
+	cfg.GitHub.Timeout = 30 * time.Second
+	cfg.GitLab.APIURL = DefaultGitLabAPIURL
+	cfg.GitLab.WebhookPath = "/webhook/gitlab"
+	cfg.GitLab.Timeout = 30 * time.Second
+	cfg.Gitea.WebhookPath = "/webhook/gitea"
+	cfg.Gitea.Timeout = 30 * time.Second
+	cfg.BitbucketCloud.APIURL = DefaultBitbucketCloudURL
+	cfg.BitbucketCloud.WebhookPath = "/webhook/bitbucket-cloud"
+	cfg.BitbucketCloud.Timeout = 30 * time.Second
+	cfg.MCP.BitbucketReplica.ReadTools = []string{ToolBitbucketGetDiff, ToolBitbucketGetFileContent}
+	cfg.Polling.Interval = 5 * time.Minute
+	cfg.Commands.Prefix = DefaultCommandPrefix
+	cfg.Commands.ExplainLines = 20
+	cfg.Conversation.MaxTurns = 5
+	cfg.Polling.PageSize = 50
 
 	// Pipeline defaults
 	cfg.Pipeline.Enabled = true
@@ -199,14 +1074,62 @@ func LoadConfig() *Config {
 	cfg.Pipeline.Stage2Context.MaxExtraFiles = 5
 	cfg.Pipeline.Stage2Context.MaxFileSize = 50000
 	cfg.Pipeline.Stage3Review.PromptTemplate = "pipeline/stage3.md"
+	cfg.Pipeline.Stage3Review.Security.PromptTemplate = "pipeline/stage3_security.md"
+	cfg.Pipeline.Stage3Review.Contract = ReviewContractV1
+	cfg.Pipeline.Stage3Review.StructuredOutput = StructuredOutputJSONObject
 	cfg.Pipeline.Stage3Review.Temperature = 0.0
 	cfg.Pipeline.Stage3Review.MaxContextTokens = 256000
 	cfg.Pipeline.Stage3Review.Degradation.L1ContextLines = 50
 	cfg.Pipeline.Stage3Review.Degradation.L2ChunkByFile = true
 	cfg.Pipeline.Stage3Review.Degradation.L3DiffOnly = true
+	cfg.Pipeline.Stage3Review.AdaptiveTuning.Window = 20
+	cfg.Pipeline.Stage3Review.AdaptiveTuning.Step = 0.1
+	cfg.Pipeline.Stage3Review.AdaptiveTuning.MaxOverflowRate = 0.1
+	cfg.Pipeline.Stage3Review.AdaptiveTuning.MaxEmptyRate = 0.5
+	cfg.Pipeline.Stage3Review.StreamDebug.Dir = "debug/streams"
+	cfg.Pipeline.Stage3Review.OutcomeCheck.Retry = true
+	cfg.Pipeline.Stage3Review.OutcomeCheck.MinChangedLines = 30
+	cfg.Pipeline.Stage3Review.SchemaRepair.Enabled = true
+	cfg.Pipeline.Stage3Review.Ensemble.Temperature = 0.7
+	cfg.Pipeline.Stage3Review.Ensemble.LineTolerance = 3
 	cfg.Pipeline.CommentMerge.Enabled = true
 	cfg.Pipeline.CommentMerge.HighSeverityMerge = "by_file"
 	cfg.Pipeline.CommentMerge.LowSeverityMerge = "to_summary"
+	cfg.Pipeline.Markers.Prefix = MarkerAIReviewPrefix
+	cfg.Pipeline.Markers.Suffix = MarkerAIReviewSuffix
+	cfg.Pipeline.AutoResolve.Action = AutoResolveActionResolve
+	cfg.Pipeline.AutoResolve.LineWindow = 3
+	cfg.BuildStatus.Key = DefaultBuildStatusKey
+	cfg.BuildStatus.Name = "AI Review"
+	cfg.RepoConfig.Path = DefaultRepoConfigPath
+	cfg.Review.Drafts = DraftPolicyReview
+	cfg.Review.SkipTags = []string{DefaultSkipTag}
+	cfg.Pipeline.SizeGate.MaxFiles = 100
+	cfg.Pipeline.SizeGate.MaxTokens = 150000
+	cfg.Pipeline.SecretScan.MinEntropy = 3.5
+	cfg.Pipeline.Linters.Timeout = 2 * time.Minute
+	cfg.Pipeline.Linters.MaxFiles = 50
+	cfg.Pipeline.Linters.MaxFindings = 20
+	cfg.Pipeline.TestCoverage.MaxFiles = 5
+	cfg.Pipeline.Description.Action = DescriptionActionComment
+	cfg.Pipeline.Description.MaxChars = 30000
+	cfg.Pipeline.DuplicateDetection.Window = 14 * 24 * time.Hour
+	cfg.Pipeline.DuplicateDetection.Similarity = 0.8
+	cfg.Pipeline.Retrieval.IndexPath = "data/doc_index.json"
+	cfg.Pipeline.Retrieval.RefreshInterval = 6 * time.Hour
+	cfg.Pipeline.Retrieval.TopK = 4
+	cfg.Pipeline.Retrieval.MinScore = 0.3
+	cfg.Pipeline.Retrieval.ChunkSize = 1500
+	cfg.Pipeline.Standards.Tool = ToolConfluenceGetPage
+	cfg.Pipeline.Standards.CacheTTL = time.Hour
+	cfg.Pipeline.Standards.MaxChars = 12000
+	cfg.Pipeline.DuplicateDetection.MinLines = 5
+	cfg.Pipeline.Assets.MaxSize = 512 * 1024
+	cfg.Pipeline.Assets.MaxImages = 3
+	cfg.Pipeline.Assets.Extensions = []string{"png", "jpg", "jpeg", "gif", "webp", "svg", "drawio", "puml", "mmd"}
+	cfg.Pipeline.WorkingHours.Start = "09:00"
+	cfg.Pipeline.WorkingHours.End = "18:00"
+	cfg.Pipeline.WorkingHours.Weekdays = []string{"mon", "tue", "wed", "thu", "fri"}
 
 	// Log Rotation defaults
 	cfg.Log.Rotation.MaxSize = 100
@@ -216,6 +1139,21 @@ func LoadConfig() *Config {
 
 	// Storage defaults
 	cfg.Storage.Timeout = 5 * time.Second
+	cfg.Storage.RetentionInterval = time.Hour
+	cfg.Queue.Driver = QueueDriverMemory
+	cfg.Queue.Role = QueueRoleAll
+	cfg.Queue.Redis.Key = "pr-review:queue"
+	cfg.Queue.NATS.Stream = "PR_REVIEWS"
+	cfg.Queue.NATS.Subject = "pr-review.reviews"
+	cfg.Queue.NATS.Consumer = "pr-review-workers"
+	cfg.Queue.NATS.AckWait = 2 * time.Minute
+	cfg.Queue.Kafka.Topic = "pr-review.reviews"
+	cfg.Queue.Kafka.GroupID = "pr-review-workers"
+	cfg.Storage.Resilience.Enabled = true
+	cfg.Storage.Resilience.FailureThreshold = 3
+	cfg.Storage.Resilience.OpenDuration = 30 * time.Second
+	cfg.Storage.Resilience.FlushInterval = 10 * time.Second
+	cfg.Storage.Resilience.MaxBuffered = 1000
 
 	// Try to load from YAML
 	configPath := getEnv("CONFIG_PATH", DefaultConfigPath)
@@ -225,7 +1163,9 @@ func LoadConfig() *Config {
 			slog.Error("unmarshal config failed", "error", err, "path", configPath)
 			os.Exit(1)
 		}
-		slog.Info("config loaded", "path", configPath)
+		sum := sha256.Sum256(data)
+		cfg.ConfigVersion = hex.EncodeToString(sum[:4])
+		slog.Info("config loaded", "path", configPath, "version", cfg.ConfigVersion)
 	} else {
 		if !os.IsNotExist(err) {
 			slog.Error("read config failed", "error", err, "path", configPath)
@@ -237,31 +1177,497 @@ func LoadConfig() *Config {
 	// Always supplement/override with environment variables for secrets and critical items
 	cfg.LLM.APIKey = getEnv("LLM_API_KEY", cfg.LLM.APIKey)
 	cfg.Server.WebhookSecret = getEnv("WEBHOOK_SECRET", cfg.Server.WebhookSecret)
+	cfg.Profile = getEnv("CONFIG_PROFILE", cfg.Profile)
 
 	cfg.MCP.Bitbucket.Token = getEnv("BITBUCKET_MCP_TOKEN", cfg.MCP.Bitbucket.Token)
+	cfg.MCP.BitbucketReplica.Token = getEnv("BITBUCKET_REPLICA_MCP_TOKEN", cfg.MCP.Bitbucket.Token)
 	cfg.MCP.Jira.Token = getEnv("JIRA_MCP_TOKEN", cfg.MCP.Jira.Token)
 	cfg.MCP.Confluence.Token = getEnv("CONFLUENCE_MCP_TOKEN", cfg.MCP.Confluence.Token)
 
+	cfg.Storage.EncryptionKey = getEnv("STORAGE_ENCRYPTION_KEY", cfg.Storage.EncryptionKey)
+	cfg.Queue.Redis.Password = getEnv("REDIS_PASSWORD", cfg.Queue.Redis.Password)
+	cfg.Queue.NATS.Token = getEnv("NATS_TOKEN", cfg.Queue.NATS.Token)
+	cfg.Queue.Kafka.Password = getEnv("KAFKA_PASSWORD", cfg.Queue.Kafka.Password)
+
+	cfg.GitHub.Token = getEnv("GITHUB_TOKEN", cfg.GitHub.Token)
+	cfg.GitHub.WebhookSecret = getEnv("GITHUB_WEBHOOK_SECRET", cfg.GitHub.WebhookSecret)
+	cfg.GitLab.Token = getEnv("GITLAB_TOKEN", cfg.GitLab.Token)
+	cfg.GitLab.WebhookSecret = getEnv("GITLAB_WEBHOOK_SECRET", cfg.GitLab.WebhookSecret)
+	cfg.Gitea.Token = getEnv("GITEA_TOKEN", cfg.Gitea.Token)
+	cfg.Gitea.WebhookSecret = getEnv("GITEA_WEBHOOK_SECRET", cfg.Gitea.WebhookSecret)
+	cfg.BitbucketCloud.Username = getEnv("BITBUCKET_CLOUD_USERNAME", cfg.BitbucketCloud.Username)
+	cfg.BitbucketCloud.Token = getEnv("BITBUCKET_CLOUD_TOKEN", cfg.BitbucketCloud.Token)
+	cfg.BitbucketCloud.WebhookSecret = getEnv("BITBUCKET_CLOUD_WEBHOOK_SECRET", cfg.BitbucketCloud.WebhookSecret)
+
+	for i := range cfg.Auth.Tokens {
+		if env := cfg.Auth.Tokens[i].TokenEnv; env != "" {
+			cfg.Auth.Tokens[i].Token = getEnv(env, cfg.Auth.Tokens[i].Token)
+		}
+	}
+
+	for i := range cfg.LLM.Routes {
+		cfg.fillLLMTarget(&cfg.LLM.Routes[i].LLMTarget)
+	}
+	for i := range cfg.LLM.Fallbacks {
+		cfg.fillLLMTarget(&cfg.LLM.Fallbacks[i])
+	}
+	cfg.fillLLMTarget(&cfg.Pipeline.Retrieval.Embedding)
+
+	if cfg.Server.Autoscale.MaxWorkers == 0 {
+		cfg.Server.Autoscale.MaxWorkers = int(cfg.Server.ConcurrencyLimit)
+	}
+	if cfg.Queue.Redis.Consumer == "" {
+		cfg.Queue.Redis.Consumer, _ = os.Hostname()
+	}
+
 	return cfg
 }
 
+// fillLLMTarget fills the unset fields of t from the llm section and reads its API key
+func (c *Config) fillLLMTarget(t *LLMTarget) {
+	if t.Provider == "" {
+		t.Provider = c.LLM.Provider
+	}
+	if t.Endpoint == "" {
+		t.Endpoint = c.LLM.Endpoint
+	}
+	t.APIKey = c.LLM.APIKey
+	if t.APIKeyEnv != "" {
+		t.APIKey = getEnv(t.APIKeyEnv, "")
+	}
+}
+
+// validate checks a filled LLM target; name is its config path
+func (t LLMTarget) validate(name string) []string {
+	var errs []string
+	if t.Model == "" {
+		errs = append(errs, name+".model is required")
+	}
+	switch t.Provider {
+	case LLMProviderOpenAI:
+		switch {
+		case t.APIKey != "":
+		case t.APIKeyEnv != "":
+			errs = append(errs, fmt.Sprintf("%s: %s is not set", name, t.APIKeyEnv))
+		default:
+			errs = append(errs, name+": LLM_API_KEY is required")
+		}
+	case LLMProviderLocal:
+	default:
+		errs = append(errs, fmt.Sprintf("invalid %s.provider: %q", name, t.Provider))
+	}
+	return append(errs, t.Capabilities.validate(name+".capabilities")...)
+}
+
+// validate checks declared capabilities; name is their config path
+func (c LLMCapabilities) validate(name string) []string {
+	if c.MaxContext < 0 {
+		return []string{fmt.Sprintf("%s.max_context must not be negative, got %d", name, c.MaxContext)}
+	}
+	return nil
+}
+
+// validateRetrieval checks the embedding model and documentation sources
+func (c *Config) validateRetrieval() []string {
+	r := c.Pipeline.Retrieval
+	errs := r.Embedding.validate("pipeline.retrieval.embedding")
+	if len(r.Sources) == 0 {
+		errs = append(errs, "pipeline.retrieval enabled but no sources configured")
+	}
+	for i, src := range r.Sources {
+		name := fmt.Sprintf("pipeline.retrieval.sources[%d]", i)
+		switch src.Type {
+		case RetrievalSourceDir, RetrievalSourceCode:
+			if src.Path == "" {
+				errs = append(errs, name+".path is required")
+			}
+		case RetrievalSourceConfluence:
+			if src.Space == "" {
+				errs = append(errs, name+".space is required")
+			}
+			if c.MCP.Confluence.Endpoint == "" {
+				errs = append(errs, name+" requires mcp.confluence")
+			}
+		default:
+			errs = append(errs, fmt.Sprintf("invalid %s.type: %q", name, src.Type))
+		}
+	}
+	for i, o := range r.Overrides {
+		name := fmt.Sprintf("pipeline.retrieval.overrides[%d]", i)
+		if len(o.Repos) == 0 {
+			errs = append(errs, name+".repos is required")
+		}
+		if o.TopK < 0 || o.MinScore < 0 || o.MinScore > 1 {
+			errs = append(errs, name+": top_k must not be negative and min_score must be within [0, 1]")
+		}
+	}
+	return errs
+}
+
 // Validate validates the configuration
 func (c *Config) Validate() error {
 	var errs []string
 
-	if c.LLM.APIKey == "" {
-		errs = append(errs, "LLM_API_KEY is required")
+	switch c.LLM.Provider {
+	case LLMProviderOpenAI:
+		if c.LLM.APIKey == "" {
+			errs = append(errs, "LLM_API_KEY is required")
+		}
+	case LLMProviderLocal:
+	default:
+		errs = append(errs, fmt.Sprintf("invalid llm provider: %q", c.LLM.Provider))
+	}
+	errs = append(errs, c.LLM.Capabilities.validate("llm.capabilities")...)
+
+	for i, r := range c.LLM.Routes {
+		name := fmt.Sprintf("llm.routes[%d]", i)
+		errs = append(errs, r.LLMTarget.validate(name)...)
+		if len(r.Projects) == 0 && len(r.Repos) == 0 {
+			errs = append(errs, name+" needs projects or repos")
+		}
+		if r.Contract != "" && r.Contract != ReviewContractV1 && r.Contract != ReviewContractV2 {
+			errs = append(errs, fmt.Sprintf("invalid %s.contract: %q", name, r.Contract))
+		}
+	}
+	for i, f := range c.LLM.Fallbacks {
+		errs = append(errs, f.validate(fmt.Sprintf("llm.fallbacks[%d]", i))...)
+	}
+	for i, t := range c.LLM.Tokenizers {
+		if len(t.Models) == 0 || t.File == "" {
+			errs = append(errs, fmt.Sprintf("llm.tokenizers[%d] needs models and file", i))
+		}
+	}
+	if c.Budgets.Enabled {
+		b := c.Budgets
+		errs = append(errs, b.Global.validate("budgets.global")...)
+		errs = append(errs, b.Project.validate("budgets.project")...)
+		hasCost := b.Global.hasCost() || b.Project.hasCost()
+		for _, project := range slices.Sorted(maps.Keys(b.Projects)) {
+			limits := b.Projects[project]
+			errs = append(errs, limits.validate("budgets.projects."+project)...)
+			hasCost = hasCost || limits.hasCost()
+		}
+		if hasCost && len(c.LLM.Pricing) == 0 {
+			errs = append(errs, "budgets cost limits need llm.pricing")
+		}
+	}
+	for i, p := range c.LLM.Pricing {
+		name := fmt.Sprintf("llm.pricing[%d]", i)
+		if len(p.Models) == 0 {
+			errs = append(errs, name+" needs models")
+		}
+		for _, glob := range p.Models {
+			if _, err := path.Match(glob, ""); err != nil {
+				errs = append(errs, fmt.Sprintf("invalid %s model glob %q: %v", name, glob, err))
+			}
+		}
+		if p.Prompt < 0 || p.Completion < 0 {
+			errs = append(errs, name+" prices must not be negative")
+		}
+	}
+	errs = append(errs, c.MCP.Retry.validate("mcp.retry")...)
+	errs = append(errs, c.Webhook.Retry.validate("webhook.retry")...)
+
+	switch c.Queue.Driver {
+	case "", QueueDriverMemory:
+	case QueueDriverRedis:
+		if c.Queue.Redis.Addr == "" {
+			errs = append(errs, "queue.redis.addr is required with queue driver redis")
+		}
+	case QueueDriverNATS:
+		if c.Queue.NATS.URL == "" {
+			errs = append(errs, "queue.nats.url is required with queue driver nats")
+		}
+		if c.Queue.NATS.AckWait <= 0 {
+			errs = append(errs, "queue.nats.ack_wait must be positive")
+		}
+	case QueueDriverKafka:
+		if len(c.Queue.Kafka.Brokers) == 0 {
+			errs = append(errs, "queue.kafka.brokers is required with queue driver kafka")
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("invalid queue.driver: %q", c.Queue.Driver))
+	}
+	switch c.Queue.Role {
+	case "", QueueRoleAll:
+	case QueueRoleIngest, QueueRoleWorker:
+		if c.Queue.Driver == "" || c.Queue.Driver == QueueDriverMemory {
+			errs = append(errs, fmt.Sprintf("queue.role %s needs an external queue driver", c.Queue.Role))
+		}
+	default:
+		errs = append(errs, fmt.Sprintf("invalid queue.role: %q", c.Queue.Role))
+	}
+
+	if pc := c.Server.Priorities; pc.Enabled && (pc.Boost <= 0 || pc.LargePRLines < 0) {
+		errs = append(errs, "server.priorities needs a positive boost and large_pr_lines of at least 0")
+	}
+	if rt := c.Server.ReviewTimeout; rt.Default < 0 {
+		errs = append(errs, "server.review_timeout.default must not be negative")
+	} else {
+		for name, timeouts := range map[string]map[string]time.Duration{"backends": rt.Backends, "projects": rt.Projects} {
+			for key, t := range timeouts {
+				if t <= 0 {
+					errs = append(errs, fmt.Sprintf("server.review_timeout.%s.%s must be positive", name, key))
+				}
+			}
+		}
+	}
+	if rl := c.Server.RateLimit; rl.Enabled {
+		if rl.Project.PerHour < 0 || rl.Project.Burst < 0 || rl.Repo.PerHour < 0 || rl.Repo.Burst < 0 {
+			errs = append(errs, "server.rate_limit limits must not be negative")
+		}
+		if rl.Project.PerHour == 0 && rl.Repo.PerHour == 0 {
+			errs = append(errs, "server.rate_limit needs project.per_hour or repo.per_hour")
+		}
+	}
+	if a := c.Server.Autoscale; a.Enabled {
+		if a.MinWorkers < 1 {
+			errs = append(errs, "server.autoscale.min_workers must be at least 1")
+		}
+		if a.MaxWorkers < a.MinWorkers {
+			errs = append(errs, fmt.Sprintf("server.autoscale.max_workers (%d) must not be below min_workers (%d)", a.MaxWorkers, a.MinWorkers))
+		}
+		if a.Interval <= 0 || a.TargetWait <= 0 {
+			errs = append(errs, "server.autoscale.interval and target_wait must be positive")
+		}
+	}
+
+	if s := c.Pipeline.Standards; s.Enabled {
+		if c.MCP.Confluence.Endpoint == "" {
+			errs = append(errs, "pipeline.standards requires mcp.confluence")
+		}
+		if len(s.Projects) == 0 {
+			errs = append(errs, "pipeline.standards enabled but no projects configured")
+		}
+		for i, m := range s.Projects {
+			if len(m.Repos) == 0 || len(m.Pages) == 0 {
+				errs = append(errs, fmt.Sprintf("pipeline.standards.projects[%d] needs repos and pages", i))
+			}
+		}
+		if s.MaxChars <= 0 {
+			errs = append(errs, fmt.Sprintf("pipeline.standards.max_chars must be positive, got %d", s.MaxChars))
+		}
+	}
+	if c.Pipeline.Retrieval.Enabled {
+		errs = append(errs, c.validateRetrieval()...)
+	}
+
+	for i, f := range c.FaultInjection.Faults {
+		if !slices.Contains(FaultKinds, f.Kind) {
+			errs = append(errs, fmt.Sprintf("invalid fault_injection.faults[%d].kind: %q", i, f.Kind))
+		}
+		if f.Rate < 0 || f.Rate > 1 {
+			errs = append(errs, fmt.Sprintf("fault_injection.faults[%d].rate must be between 0 and 1", i))
+		}
+	}
+
+	if c.Commands.Enabled {
+		if !strings.HasPrefix(c.Commands.Prefix, "/") || strings.ContainsAny(c.Commands.Prefix, " \t\n") {
+			errs = append(errs, fmt.Sprintf("invalid commands.prefix: %q", c.Commands.Prefix))
+		}
+		if c.Commands.ExplainLines <= 0 {
+			errs = append(errs, "commands.explain_lines must be positive")
+		}
+	}
+
+	if c.Conversation.Enabled && c.Conversation.MaxTurns <= 0 {
+		errs = append(errs, "conversation.max_turns must be positive")
+	}
+
+	if c.Polling.Enabled {
+		if c.MCP.Bitbucket.Endpoint == "" {
+			errs = append(errs, "polling requires mcp.bitbucket")
+		}
+		if len(c.Polling.Repos) == 0 {
+			errs = append(errs, "polling enabled but polling.repos is empty")
+		}
+		for _, repo := range c.Polling.Repos {
+			if project, slug, ok := strings.Cut(repo, "/"); !ok || project == "" || slug == "" || strings.Contains(slug, "/") {
+				errs = append(errs, fmt.Sprintf("invalid polling.repos entry %q: want PROJECT/repo", repo))
+			}
+		}
+		if c.Polling.Interval <= 0 || c.Polling.PageSize <= 0 {
+			errs = append(errs, "polling.interval and page_size must be positive")
+		}
 	}
 
 	if c.Server.Port < 1 || c.Server.Port > 65535 {
 		errs = append(errs, fmt.Sprintf("invalid server port: %d", c.Server.Port))
 	}
 
-	// At least one MCP endpoint should be configured
-	if c.MCP.Bitbucket.Endpoint == "" && c.MCP.Jira.Endpoint == "" && c.MCP.Confluence.Endpoint == "" {
+	// At least one MCP endpoint should be configured, unless only REST-backed SCMs are used
+	if !c.LocalDiff && c.MCP.Bitbucket.Endpoint == "" && c.MCP.Jira.Endpoint == "" && c.MCP.Confluence.Endpoint == "" && !c.GitHub.Enabled && !c.GitLab.Enabled && !c.Gitea.Enabled && !c.BitbucketCloud.Enabled {
 		errs = append(errs, "at least one MCP endpoint must be configured")
 	}
 
+	if c.MCP.BitbucketReplica.Endpoint != "" {
+		if c.MCP.Bitbucket.Endpoint == "" {
+			errs = append(errs, "mcp.bitbucket_replica requires mcp.bitbucket")
+		}
+		for _, tool := range c.MCP.BitbucketReplica.ReadTools {
+			if slices.Contains(BitbucketWriteTools, tool) {
+				errs = append(errs, fmt.Sprintf("mcp.bitbucket_replica.read_tools: %s writes to Bitbucket", tool))
+			}
+		}
+	}
+
+	if c.GitHub.Enabled && c.GitHub.Token == "" {
+		errs = append(errs, "github enabled but GITHUB_TOKEN is not set")
+	}
+	if c.GitLab.Enabled && c.GitLab.Token == "" {
+		errs = append(errs, "gitlab enabled but GITLAB_TOKEN is not set")
+	}
+	if c.BitbucketCloud.Enabled && c.BitbucketCloud.Token == "" {
+		errs = append(errs, "bitbucket_cloud enabled but BITBUCKET_CLOUD_TOKEN is not set")
+	}
+	if c.Gitea.Enabled {
+		if c.Gitea.APIURL == "" {
+			errs = append(errs, "gitea enabled but gitea.api_url is not set")
+		}
+		if c.Gitea.Token == "" {
+			errs = append(errs, "gitea enabled but GITEA_TOKEN is not set")
+		}
+	}
+
+	if c.Auth.Enabled {
+		if len(c.Auth.Tokens) == 0 {
+			errs = append(errs, "auth enabled but no tokens configured")
+		}
+		for _, t := range c.Auth.Tokens {
+			if t.Token == "" {
+				errs = append(errs, fmt.Sprintf("auth token %q: %s is not set", t.Name, t.TokenEnv))
+			}
+			switch t.Role {
+			case RoleViewer, RoleOperator, RoleAdmin:
+			default:
+				errs = append(errs, fmt.Sprintf("auth token %q: invalid role %q", t.Name, t.Role))
+			}
+		}
+	}
+
+	if c.Pipeline.WorkingHours.Enabled {
+		if _, err := c.Pipeline.WorkingHours.Schedule(); err != nil {
+			errs = append(errs, fmt.Sprintf("working_hours: %v", err))
+		}
+	}
+
+	if conf := c.Pipeline.PostProcessing.Confidence; conf.Min < 0 || conf.Min > 1 {
+		errs = append(errs, fmt.Sprintf("post_processing.confidence.min must be within [0, 1], got %g", conf.Min))
+	}
+	switch c.Pipeline.PostProcessing.Confidence.Action {
+	case "", ConfidenceActionDrop, ConfidenceActionLabel:
+	default:
+		errs = append(errs, fmt.Sprintf("invalid post_processing.confidence action %q", c.Pipeline.PostProcessing.Confidence.Action))
+	}
+	for category := range c.Pipeline.PostProcessing.Categories {
+		if !slices.Contains(FindingCategories, category) {
+			errs = append(errs, fmt.Sprintf("post_processing.categories: unknown category %q", category))
+		}
+	}
+	for i, sc := range c.Pipeline.PostProcessing.SeverityCaps {
+		if len(sc.Files) == 0 {
+			errs = append(errs, fmt.Sprintf("post_processing.severity_caps[%d]: files is required", i))
+		}
+		if !slices.Contains(FindingSeverities, strings.ToUpper(sc.MaxSeverity)) {
+			errs = append(errs, fmt.Sprintf("post_processing.severity_caps[%d]: invalid max_severity %q", i, sc.MaxSeverity))
+		}
+	}
+
+	if s := c.Review.MinPostSeverity; s != "" && !slices.Contains(FindingSeverities, strings.ToUpper(s)) {
+		errs = append(errs, fmt.Sprintf("invalid review.min_post_severity %q", s))
+	}
+	switch c.Review.Drafts {
+	case "", DraftPolicyReview, DraftPolicySkip, DraftPolicyDryRun:
+	default:
+		errs = append(errs, fmt.Sprintf("invalid review.drafts policy %q", c.Review.Drafts))
+	}
+
+	switch c.Pipeline.AutoResolve.Action {
+	case "", AutoResolveActionResolve, AutoResolveActionDelete:
+	default:
+		errs = append(errs, fmt.Sprintf("invalid auto_resolve action %q", c.Pipeline.AutoResolve.Action))
+	}
+	if c.Pipeline.AutoResolve.LineWindow < 0 {
+		errs = append(errs, "auto_resolve.line_window must not be negative")
+	}
+	if c.Pipeline.SizeGate.MaxFiles < 0 || c.Pipeline.SizeGate.MaxTokens < 0 {
+		errs = append(errs, "size_gate.max_files and max_tokens must not be negative")
+	}
+	if c.Pipeline.SecretScan.MinEntropy < 0 {
+		errs = append(errs, "secret_scan.min_entropy must not be negative")
+	}
+	if c.Pipeline.TestCoverage.Enabled && c.Pipeline.TestCoverage.MaxFiles <= 0 {
+		errs = append(errs, "test_coverage.max_files must be positive")
+	}
+	if d := c.Pipeline.Description; d.Enabled {
+		switch d.Action {
+		case "", DescriptionActionComment, DescriptionActionUpdate:
+		default:
+			errs = append(errs, fmt.Sprintf("invalid description action %q", d.Action))
+		}
+		if d.MaxChars <= 0 {
+			errs = append(errs, "description.max_chars must be positive")
+		}
+	}
+	if l := c.Pipeline.Linters; l.Enabled {
+		if len(l.Tools) == 0 {
+			errs = append(errs, "linters.tools must not be empty when linters are enabled")
+		}
+		if l.Timeout <= 0 || l.MaxFiles <= 0 || l.MaxFindings <= 0 {
+			errs = append(errs, "linters.timeout, max_files and max_findings must be positive")
+		}
+		for i, t := range l.Tools {
+			if t.Name == "" || len(t.Command) == 0 {
+				errs = append(errs, fmt.Sprintf("linters.tools[%d]: name and command are required", i))
+			}
+			switch t.Format {
+			case LinterFormatGolangci, LinterFormatRuff, LinterFormatGCC:
+			default:
+				errs = append(errs, fmt.Sprintf("linters.tools[%d]: invalid format %q", i, t.Format))
+			}
+			if t.Severity != "" && !slices.Contains(FindingSeverities, strings.ToUpper(t.Severity)) {
+				errs = append(errs, fmt.Sprintf("linters.tools[%d]: invalid severity %q", i, t.Severity))
+			}
+			for key, s := range t.Severities {
+				if !slices.Contains(FindingSeverities, strings.ToUpper(s)) {
+					errs = append(errs, fmt.Sprintf("linters.tools[%d].severities[%q]: invalid severity %q", i, key, s))
+				}
+			}
+		}
+	}
+
+	layouts := []string{c.Pipeline.Summary.Layout}
+	for _, r := range c.Pipeline.Summary.Repos {
+		layouts = append(layouts, r.Layout)
+	}
+	for _, l := range layouts {
+		switch l {
+		case "", SummaryLayoutClassic, SummaryLayoutExecutiveFirst, SummaryLayoutDeveloperFirst:
+		default:
+			errs = append(errs, fmt.Sprintf("invalid summary layout %q", l))
+		}
+	}
+
+	for i, r := range c.Approval.Projects {
+		if r.ApproveScore < 0 || r.ApproveScore > 100 || r.NeedsWorkScore < 0 || r.NeedsWorkScore > 100 {
+			errs = append(errs, fmt.Sprintf("approval.projects[%d]: scores must be between 0 and 100", i))
+		}
+		if r.ApproveScore > 0 && r.NeedsWorkScore > r.ApproveScore {
+			errs = append(errs, fmt.Sprintf("approval.projects[%d]: needs_work_score must not exceed approve_score", i))
+		}
+	}
+
+	if c.JiraIssues.Enabled {
+		if c.MCP.Jira.Endpoint == "" {
+			errs = append(errs, "jira_issues enabled but mcp.jira.endpoint is not set")
+		}
+		for i, m := range c.JiraIssues.Projects {
+			if m.JiraProject == "" {
+				errs = append(errs, fmt.Sprintf("jira_issues.projects[%d]: jira_project is required", i))
+			}
+		}
+	}
+
 	if len(errs) > 0 {
 		return fmt.Errorf("config invalid: %s", strings.Join(errs, "; "))
 	}
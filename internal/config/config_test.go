@@ -2,6 +2,7 @@ package config
 
 import (
 	"os"
+	"strings"
 	"testing"
 	"time"
 )
@@ -38,6 +39,14 @@ func TestLoadConfig_Defaults(t *testing.T) {
 	if cfg.Server.MaxBodySize != 2*1024*1024 {
 		t.Errorf("expected max body size 2MB, got %d", cfg.Server.MaxBodySize)
 	}
+
+	if cfg.LLM.Provider != LLMProviderOpenAI || cfg.LLMTimeout() != 120*time.Second {
+		t.Errorf("expected openai provider with 120s timeout, got %s %v", cfg.LLM.Provider, cfg.LLMTimeout())
+	}
+	cfg.LLM.Provider = LLMProviderLocal
+	if cfg.LLMTimeout() != 10*time.Minute {
+		t.Errorf("expected local timeout 10m, got %v", cfg.LLMTimeout())
+	}
 }
 
 func TestLoadConfig_MCPEndpointsFromEnv(t *testing.T) {
@@ -56,6 +65,10 @@ func TestLoadConfig_MCPEndpointsFromEnv(t *testing.T) {
 		t.Errorf("expected bitbucket token, got %s", cfg.MCP.Bitbucket.Token)
 	}
 
+	if cfg.MCP.BitbucketReplica.Token != "bb-token" {
+		t.Errorf("expected replica to default to bitbucket token, got %s", cfg.MCP.BitbucketReplica.Token)
+	}
+
 	if cfg.MCP.Jira.Token != "jira-token" {
 		t.Errorf("expected jira token, got %s", cfg.MCP.Jira.Token)
 	}
@@ -77,6 +90,9 @@ llm:
 mcp:
   bitbucket:
     endpoint: http://custom-bb:8080
+  bitbucket_replica:
+    endpoint: http://bb-mirror:8080
+    read_tools: [bitbucket_get_pull_request_diff]
 `
 	tmpfile, err := os.CreateTemp("", "config*.yaml")
 	if err != nil {
@@ -108,4 +124,461 @@ mcp:
 	if cfg.MCP.Bitbucket.Endpoint != "http://custom-bb:8080" {
 		t.Errorf("expected Bitbucket Endpoint, got %s", cfg.MCP.Bitbucket.Endpoint)
 	}
+	if r := cfg.MCP.BitbucketReplica; r.Endpoint != "http://bb-mirror:8080" || len(r.ReadTools) != 1 || r.ReadTools[0] != ToolBitbucketGetDiff {
+		t.Errorf("unexpected Bitbucket replica %+v", r)
+	}
+}
+
+func TestLoadConfig_AuthTokensFromEnv(t *testing.T) {
+	yamlContent := `
+auth:
+  enabled: true
+  tokens:
+    - name: dash
+      role: viewer
+      token_env: TEST_API_TOKEN_DASH
+    - name: broken
+      role: root
+      token_env: TEST_API_TOKEN_MISSING
+`
+	tmpfile, err := os.CreateTemp("", "config*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.Write([]byte(yamlContent)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("CONFIG_PATH", tmpfile.Name())
+	t.Setenv("TEST_API_TOKEN_DASH", "secret")
+
+	cfg := LoadConfig()
+
+	if cfg.Auth.Tokens[0].Token != "secret" {
+		t.Errorf("expected token from env, got %q", cfg.Auth.Tokens[0].Token)
+	}
+
+	err = cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{`auth token "broken": TEST_API_TOKEN_MISSING is not set`, `invalid role "root"`} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got %v", want, err)
+		}
+	}
+}
+
+func TestLoadConfig_LLMRoutes(t *testing.T) {
+	yamlContent := `
+llm:
+  model: gpt-4o
+  endpoint: https://api.openai.com/v1
+  routes:
+    - projects: [FAS]
+      model: gpt-4o-mini
+      contract: v3
+    - repos: ["TOOLS/*"]
+      provider: local
+      model: glm-4
+      endpoint: http://ollama:11434/v1
+    - projects: [OPS]
+      model: claude
+      api_key_env: TEST_ROUTE_KEY_MISSING
+    - model: orphan
+  fallbacks:
+    - model: gpt-4o-mini
+    - provider: local
+      model: qwen3-coder
+      endpoint: http://ollama:11434/v1
+      capabilities:
+        tools: false
+        max_context: -1
+    - api_key_env: TEST_FALLBACK_KEY_MISSING
+`
+	tmpfile, err := os.CreateTemp("", "config*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.Write([]byte(yamlContent)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("CONFIG_PATH", tmpfile.Name())
+	t.Setenv("LLM_API_KEY", "sk-main")
+
+	cfg := LoadConfig()
+
+	fas, tools := cfg.LLM.Routes[0], cfg.LLM.Routes[1]
+	if fas.Provider != LLMProviderOpenAI || fas.Endpoint != "https://api.openai.com/v1" || fas.APIKey != "sk-main" {
+		t.Errorf("route must inherit the llm section: %+v", fas)
+	}
+	if tools.Provider != LLMProviderLocal || tools.Endpoint != "http://ollama:11434/v1" {
+		t.Errorf("unexpected local route: %+v", tools)
+	}
+	if f := cfg.LLM.Fallbacks[0]; f.Provider != LLMProviderOpenAI || f.APIKey != "sk-main" {
+		t.Errorf("fallback must inherit the llm section: %+v", f)
+	}
+	if tools := cfg.LLM.Fallbacks[1].Capabilities.Tools; tools == nil || *tools {
+		t.Errorf("declared capabilities not loaded: %+v", cfg.LLM.Fallbacks[1].Capabilities)
+	}
+
+	err = cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{"llm.routes[2]: TEST_ROUTE_KEY_MISSING is not set", "llm.routes[3] needs projects or repos", `invalid llm.routes[0].contract: "v3"`,
+		"llm.fallbacks[2].model is required", "llm.fallbacks[2]: TEST_FALLBACK_KEY_MISSING is not set",
+		"llm.fallbacks[1].capabilities.max_context must not be negative"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "llm.routes[1]") {
+		t.Errorf("local route needs no API key: %v", err)
+	}
+}
+
+func TestLoadConfig_Retrieval(t *testing.T) {
+	yamlContent := `
+llm:
+  model: gpt-4o
+  endpoint: http://ollama:11434/v1
+  provider: local
+pipeline:
+  retrieval:
+    enabled: true
+    embedding:
+      model: nomic-embed-text
+    sources:
+      - type: dir
+        path: docs
+      - type: confluence
+        space: ENG
+      - type: git
+      - type: dir
+`
+	tmpfile, err := os.CreateTemp("", "config*.yaml")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.Remove(tmpfile.Name())
+
+	if _, err := tmpfile.Write([]byte(yamlContent)); err != nil {
+		t.Fatal(err)
+	}
+	if err := tmpfile.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	t.Setenv("CONFIG_PATH", tmpfile.Name())
+
+	cfg := LoadConfig()
+
+	r := cfg.Pipeline.Retrieval
+	if r.Embedding.Provider != LLMProviderLocal || r.Embedding.Endpoint != "http://ollama:11434/v1" {
+		t.Errorf("embedding must inherit the llm section: %+v", r.Embedding)
+	}
+	if r.TopK != 4 || r.ChunkSize != 1500 || r.IndexPath != "data/doc_index.json" {
+		t.Errorf("unexpected defaults: %+v", r)
+	}
+
+	err = cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{"pipeline.retrieval.sources[1] requires mcp.confluence", `invalid pipeline.retrieval.sources[2].type: "git"`,
+		"pipeline.retrieval.sources[3].path is required"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "sources[0]") {
+		t.Errorf("valid dir source rejected: %v", err)
+	}
+}
+
+func TestValidate_FaultInjection(t *testing.T) {
+	cfg := &Config{}
+	cfg.LLM.Provider = LLMProviderLocal
+	cfg.Server.Port = 8080
+	cfg.MCP.Bitbucket.Endpoint = "http://mcp"
+	cfg.FaultInjection = FaultInjectionConfig{Enabled: true, Faults: []FaultRule{
+		{Kind: FaultLLMRateLimit, Rate: 0.5},
+		{Kind: "disk_full", Rate: 0.1},
+		{Kind: FaultStorageError, Rate: 2},
+	}}
+
+	err := cfg.Validate()
+	if err == nil {
+		t.Fatal("expected validation error")
+	}
+	for _, want := range []string{`invalid fault_injection.faults[1].kind: "disk_full"`, "fault_injection.faults[2].rate must be between 0 and 1"} {
+		if !strings.Contains(err.Error(), want) {
+			t.Errorf("expected error to contain %q, got %v", want, err)
+		}
+	}
+	if strings.Contains(err.Error(), "faults[0]") {
+		t.Errorf("valid fault rejected: %v", err)
+	}
+}
+
+func TestValidate_Autoscale(t *testing.T) {
+	cfg := &Config{}
+	cfg.LLM.Provider = LLMProviderLocal
+	cfg.Server.Port = 8080
+	cfg.MCP.Bitbucket.Endpoint = "http://mcp"
+	cfg.Server.Autoscale = AutoscaleConfig{Enabled: true, MinWorkers: 4, MaxWorkers: 2, Interval: time.Second, TargetWait: time.Minute}
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "server.autoscale.max_workers (2) must not be below min_workers (4)") {
+		t.Errorf("expected max_workers error, got %v", err)
+	}
+
+	cfg.Server.Autoscale.MaxWorkers = 8
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidate_Queue(t *testing.T) {
+	cfg := &Config{}
+	cfg.LLM.Provider = LLMProviderLocal
+	cfg.Server.Port = 8080
+	cfg.MCP.Bitbucket.Endpoint = "http://mcp"
+
+	cfg.Queue.Driver = QueueDriverRedis
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "queue.redis.addr is required") {
+		t.Errorf("expected missing addr error, got %v", err)
+	}
+	cfg.Queue.Driver = "rabbitmq"
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), `invalid queue.driver: "rabbitmq"`) {
+		t.Errorf("expected invalid driver error, got %v", err)
+	}
+	cfg.Queue.Driver = QueueDriverKafka
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "queue.kafka.brokers is required") {
+		t.Errorf("expected missing brokers error, got %v", err)
+	}
+	cfg.Queue.Driver = QueueDriverMemory
+	cfg.Queue.Role = QueueRoleWorker
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "queue.role worker needs an external queue driver") {
+		t.Errorf("expected role error, got %v", err)
+	}
+	cfg.Queue.Driver = QueueDriverRedis
+	cfg.Queue.Redis.Addr = "localhost:6379"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidate_Polling(t *testing.T) {
+	cfg := &Config{}
+	cfg.LLM.Provider = LLMProviderLocal
+	cfg.Server.Port = 8080
+	cfg.MCP.Bitbucket.Endpoint = "http://mcp"
+
+	cfg.Polling = PollingConfig{Enabled: true, Interval: 5 * time.Minute, PageSize: 50}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "polling.repos is empty") {
+		t.Errorf("expected empty repos error, got %v", err)
+	}
+	cfg.Polling.Repos = []string{"PAY/api", "PAY", "PAY/api/extra"}
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), `"PAY": want PROJECT/repo`) || !strings.Contains(err.Error(), `"PAY/api/extra"`) {
+		t.Errorf("expected invalid repo errors, got %v", err)
+	}
+	cfg.Polling.Repos = []string{"PAY/api"}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidate_Approval(t *testing.T) {
+	cfg := &Config{}
+	cfg.LLM.Provider = LLMProviderLocal
+	cfg.Server.Port = 8080
+	cfg.MCP.Bitbucket.Endpoint = "http://mcp"
+
+	cfg.Approval = ApprovalConfig{Enabled: true, Projects: []ApprovalRule{{ApproveScore: 70, NeedsWorkScore: 80}, {ApproveScore: 120}}}
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "projects[0]: needs_work_score must not exceed approve_score") || !strings.Contains(err.Error(), "projects[1]: scores must be between 0 and 100") {
+		t.Errorf("expected threshold errors, got %v", err)
+	}
+	cfg.Approval.Projects = []ApprovalRule{{ApproveScore: 85, NeedsWorkScore: 50}, {NeedsWorkScore: 60}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidate_SeverityCaps(t *testing.T) {
+	cfg := &Config{}
+	cfg.LLM.Provider = LLMProviderLocal
+	cfg.Server.Port = 8080
+	cfg.MCP.Bitbucket.Endpoint = "http://mcp"
+
+	cfg.Pipeline.PostProcessing.SeverityCaps = []SeverityCap{{MaxSeverity: "WARNING"}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "severity_caps[0]: files is required") {
+		t.Errorf("expected missing files error, got %v", err)
+	}
+	cfg.Pipeline.PostProcessing.SeverityCaps = []SeverityCap{{Files: []string{"**/*_test.go"}, MaxSeverity: "minor"}}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), `invalid max_severity "minor"`) {
+		t.Errorf("expected invalid severity error, got %v", err)
+	}
+	cfg.Pipeline.PostProcessing.SeverityCaps[0].MaxSeverity = "warning"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestPricingFor(t *testing.T) {
+	cfg := &Config{}
+	cfg.LLM.Provider = LLMProviderLocal
+	cfg.Server.Port = 8080
+	cfg.MCP.Bitbucket.Endpoint = "http://mcp"
+	cfg.LLM.Pricing = []ModelPricing{
+		{Models: []string{"gpt-4o-mini*"}, Prompt: 0.15, Completion: 0.6},
+		{Models: []string{"gpt-4o*"}, Prompt: 2.5, Completion: 10},
+	}
+
+	p, ok := cfg.PricingFor("gpt-4o-2024-08-06")
+	if !ok || p.Prompt != 2.5 {
+		t.Fatalf("PricingFor(gpt-4o-2024-08-06) = %+v, %v", p, ok)
+	}
+	if cost := p.Cost(1_000_000, 100_000); cost != 3.5 {
+		t.Errorf("Cost = %v, want 3.5", cost)
+	}
+	if p, _ := cfg.PricingFor("gpt-4o-mini"); p.Prompt != 0.15 {
+		t.Errorf("the first matching entry must apply, got %+v", p)
+	}
+	if _, ok := cfg.PricingFor("qwen2.5-coder"); ok {
+		t.Error("unpriced model matched")
+	}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+
+	cfg.LLM.Pricing = []ModelPricing{{Prompt: 1}, {Models: []string{"["}, Completion: -1}}
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "llm.pricing[0] needs models") || !strings.Contains(err.Error(), `invalid llm.pricing[1] model glob "["`) || !strings.Contains(err.Error(), "llm.pricing[1] prices must not be negative") {
+		t.Errorf("expected pricing errors, got %v", err)
+	}
+}
+
+func TestValidate_Budgets(t *testing.T) {
+	cfg := &Config{}
+	cfg.LLM.Provider = LLMProviderLocal
+	cfg.Server.Port = 8080
+	cfg.MCP.Bitbucket.Endpoint = "http://mcp"
+	cfg.Budgets = BudgetConfig{
+		Enabled:  true,
+		Global:   BudgetLimits{DailyTokens: 1_000_000},
+		Projects: map[string]BudgetLimits{"PAY": {MonthlyTokens: -1}, "OPS": {DailyCost: 5}},
+	}
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "budgets.projects.PAY limits must not be negative") || !strings.Contains(err.Error(), "budgets cost limits need llm.pricing") {
+		t.Errorf("expected budget errors, got %v", err)
+	}
+
+	cfg.Budgets.Projects = map[string]BudgetLimits{"OPS": {DailyCost: 5}}
+	cfg.LLM.Pricing = []ModelPricing{{Models: []string{"*"}, Prompt: 1, Completion: 2}}
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidate_RateLimit(t *testing.T) {
+	cfg := &Config{}
+	cfg.LLM.Provider = LLMProviderLocal
+	cfg.Server.Port = 8080
+	cfg.MCP.Bitbucket.Endpoint = "http://mcp"
+	cfg.Server.RateLimit = RateLimitConfig{Enabled: true}
+
+	err := cfg.Validate()
+	if err == nil || !strings.Contains(err.Error(), "server.rate_limit needs project.per_hour or repo.per_hour") {
+		t.Errorf("expected missing rate error, got %v", err)
+	}
+
+	cfg.Server.RateLimit.Repo = RateLimit{PerHour: 10, Burst: -1}
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "server.rate_limit limits must not be negative") {
+		t.Errorf("expected negative limit error, got %v", err)
+	}
+
+	cfg.Server.RateLimit.Repo.Burst = 3
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestValidate_Priorities(t *testing.T) {
+	cfg := &Config{}
+	cfg.LLM.Provider = LLMProviderLocal
+	cfg.Server.Port = 8080
+	cfg.MCP.Bitbucket.Endpoint = "http://mcp"
+	cfg.Server.Priorities = PriorityConfig{Enabled: true}
+
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "server.priorities needs a positive boost") {
+		t.Errorf("expected boost error, got %v", err)
+	}
+	cfg.Server.Priorities.Boost = 5 * time.Minute
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestReviewTimeoutFor(t *testing.T) {
+	cfg := &Config{}
+	if got := cfg.ReviewTimeoutFor(LLMProviderOpenAI, "PAY"); got != DefaultReviewTimeout {
+		t.Errorf("unset: got %v, want %v", got, DefaultReviewTimeout)
+	}
+	cfg.Server.ReviewTimeout = TimeoutConfig{
+		Default:  10 * time.Minute,
+		Backends: map[string]time.Duration{LLMProviderLocal: 45 * time.Minute},
+		Projects: map[string]time.Duration{"MONO": 30 * time.Minute},
+	}
+	tests := []struct {
+		provider, project string
+		want              time.Duration
+	}{
+		{LLMProviderOpenAI, "PAY", 10 * time.Minute},
+		{LLMProviderLocal, "PAY", 45 * time.Minute},
+		{LLMProviderLocal, "MONO", 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		if got := cfg.ReviewTimeoutFor(tt.provider, tt.project); got != tt.want {
+			t.Errorf("ReviewTimeoutFor(%q, %q) = %v, want %v", tt.provider, tt.project, got, tt.want)
+		}
+	}
+	if got := cfg.ShortestReviewTimeout(); got != 10*time.Minute {
+		t.Errorf("ShortestReviewTimeout = %v, want 10m", got)
+	}
+	cfg.Server.ReviewTimeout.Projects["FAST"] = 2 * time.Minute
+	if got := cfg.ShortestReviewTimeout(); got != 2*time.Minute {
+		t.Errorf("ShortestReviewTimeout = %v, want 2m", got)
+	}
+}
+
+func TestValidate_ReviewTimeout(t *testing.T) {
+	cfg := &Config{}
+	cfg.LLM.Provider = LLMProviderLocal
+	cfg.Server.Port = 8080
+	cfg.MCP.Bitbucket.Endpoint = "http://mcp"
+	cfg.Server.ReviewTimeout.Projects = map[string]time.Duration{"MONO": -time.Minute}
+
+	if err := cfg.Validate(); err == nil || !strings.Contains(err.Error(), "server.review_timeout.projects.MONO must be positive") {
+		t.Errorf("expected project timeout error, got %v", err)
+	}
+	cfg.Server.ReviewTimeout.Projects["MONO"] = 30 * time.Minute
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
 }
@@ -7,6 +7,71 @@ const (
 	BackendDirect    = "direct"
 )
 
+// LLM providers
+const (
+	LLMProviderOpenAI = "openai" // OpenAI or a hosted OpenAI-compatible gateway
+	LLMProviderLocal  = "local"  // Self-hosted OpenAI-compatible server such as Ollama or vLLM
+)
+
+// Review contract versions: the JSON answer the review prompt asks for
+const (
+	ReviewContractV1 = "v1" // comments (path, line, message, severity), score, summary
+	ReviewContractV2 = "v2" // v1 plus a line range, a suggested replacement and a confidence per comment
+)
+
+// Structured output modes of pipeline.stage3_review.structured_output
+const (
+	StructuredOutputJSONObject = "json_object" // Any JSON object; the prompt describes the format
+	StructuredOutputJSONSchema = "json_schema" // The review contract's JSON Schema, strict where supported
+)
+
+// Documentation source types of pipeline.retrieval
+const (
+	RetrievalSourceDir        = "dir"        // Text files under a local directory
+	RetrievalSourceConfluence = "confluence" // Pages of a Confluence space
+	RetrievalSourceCode       = "code"       // Source files under a local repository checkout, added as related code
+)
+
+// Linter output formats (pipeline.linters)
+const (
+	LinterFormatGolangci = "golangci" // golangci-lint run --out-format json
+	LinterFormatRuff     = "ruff"     // ruff check --output-format=json
+	LinterFormatGCC      = "gcc"      // file:line:col: level: message [rule], as printed by clang-tidy and compilers
+)
+
+// Queue drivers: where queued reviews wait for a worker
+const (
+	QueueDriverMemory = "memory" // In-process; queued reviews are lost on restart
+	QueueDriverRedis  = "redis"  // A Redis list shared by all server instances
+	QueueDriverNATS   = "nats"   // A NATS JetStream work-queue stream
+	QueueDriverKafka  = "kafka"  // A Kafka topic consumed by one consumer group
+)
+
+// Queue roles: which half of the review flow an instance runs with an external queue
+const (
+	QueueRoleAll    = "all"    // Accept webhooks and run reviews
+	QueueRoleIngest = "ingest" // Accept webhooks and queue their reviews; run none
+	QueueRoleWorker = "worker" // Run queued reviews; no webhook endpoints
+)
+
+// Fault kinds of fault_injection
+const (
+	FaultMCPTimeout       = "mcp_timeout"        // An MCP tool call hangs, then fails with a deadline error
+	FaultLLMRateLimit     = "llm_rate_limit"     // A chat completion is answered with HTTP 429
+	FaultLLMMalformedJSON = "llm_malformed_json" // A chat completion returns truncated review JSON
+	FaultStorageError     = "storage_error"      // A storage read or write fails
+)
+
+// FaultKinds lists the fault kinds that can be injected
+var FaultKinds = []string{FaultMCPTimeout, FaultLLMRateLimit, FaultLLMMalformedJSON, FaultStorageError}
+
+// API roles, from least to most privileged
+const (
+	RoleViewer   = "viewer"   // Read reviews, stats and metrics
+	RoleOperator = "operator" // Viewer plus operational actions
+	RoleAdmin    = "admin"    // Operator plus destructive admin actions (purge)
+)
+
 // Diff processing markers
 const (
 	MarkerTruncated  = "\n\n[... TRUNCATED FOR TOKEN LIMIT ...]"
@@ -20,16 +85,79 @@ const (
 const (
 	// MarkerAIReviewPrefix is the HTML comment start for AI metadata
 	MarkerAIReviewPrefix = "<!-- ai-review::"
+	// MarkerAIReviewLegacyPrefix is the single-colon prefix used by early releases
+	MarkerAIReviewLegacyPrefix = "<!-- ai-review:"
 	// MarkerAIReviewSuffix is the HTML comment end
 	MarkerAIReviewSuffix = "-->"
 	// MarkerAIReviewVisible is the visible Markdown identifier
 	MarkerAIReviewVisible = "**AI Review**"
 
 	// New marker types
-	MarkerTypeFile    = "file"
-	MarkerTypeSummary = "summary"
+	MarkerTypeFile        = "file"
+	MarkerTypeSummary     = "summary"
+	MarkerTypeSkip        = "skip"
+	MarkerTypeIgnore      = "ignore"      // Note pausing automatic reviews of a PR (/ai ignore)
+	MarkerTypeReply       = "reply"       // Answer to a slash command
+	MarkerTypeSecurity    = "security"    // Finding of the security review pass, next to its inline marker
+	MarkerTypeDescription = "description" // Comment proposing a description for a PR without one
+	MarkerTypePolicy      = "policy"      // Warning about title and branch policy violations
+)
+
+// Summary layouts
+const (
+	SummaryLayoutClassic        = "classic"         // Single summary section (default)
+	SummaryLayoutExecutiveFirst = "executive_first" // Executive verdict, then collapsible developer details
+	SummaryLayoutDeveloperFirst = "developer_first" // Collapsible developer details, then executive verdict
 )
 
+// DefaultSkipTag opts a pull request out of automated review when its title contains it
+const DefaultSkipTag = "[skip ai]"
+
+// Review policies for draft pull requests (review.drafts)
+const (
+	DraftPolicyReview = "review"  // Review drafts like any other PR
+	DraftPolicySkip   = "skip"    // Skip drafts; review when they leave draft
+	DraftPolicyDryRun = "dry_run" // Review drafts without posting; review again when they leave draft
+)
+
+// Auto-resolve actions for outdated comments
+const (
+	AutoResolveActionResolve = "resolve" // Mark the comment resolved
+	AutoResolveActionDelete  = "delete"  // Delete the comment
+)
+
+// Actions for findings below post_processing.confidence.min
+const (
+	ConfidenceActionDrop  = "drop"  // Do not post the finding
+	ConfidenceActionLabel = "label" // Post it marked as low confidence
+)
+
+// Actions for generated PR descriptions (pipeline.description)
+const (
+	DescriptionActionComment = "comment" // Propose the description in a PR comment
+	DescriptionActionUpdate  = "update"  // Set it as the PR description
+)
+
+// Reviewer statuses set by approval (Bitbucket Server participant status)
+const (
+	ReviewerStatusApproved   = "APPROVED"
+	ReviewerStatusNeedsWork  = "NEEDS_WORK"
+	ReviewerStatusUnapproved = "UNAPPROVED" // Withdraws an earlier verdict
+)
+
+// Build states published by build_status
+const (
+	BuildStateInProgress = "INPROGRESS"
+	BuildStateSuccessful = "SUCCESSFUL"
+	BuildStateFailed     = "FAILED"
+)
+
+// DefaultBuildStatusKey identifies the review's build status on a commit
+const DefaultBuildStatusKey = "ai-review"
+
+// DefaultRepoConfigPath is the per-repository settings file read by repo_config
+const DefaultRepoConfigPath = ".ai-review.yaml"
+
 // Deduplication Key Formats
 const (
 	// DedupeKeyFileLineFormat: file:line
@@ -57,24 +185,69 @@ var TokenLimitErrorKeywords = []string{
 
 // MCP Server Names
 const (
-	MCPServerBitbucket  = "bitbucket"
-	MCPServerJira       = "jira"
-	MCPServerConfluence = "confluence"
+	MCPServerBitbucket        = "bitbucket"
+	MCPServerBitbucketReplica = "bitbucket_replica" // Read-only mirror of bitbucket; never exposes tools
+	MCPServerJira             = "jira"
+	MCPServerConfluence       = "confluence"
 )
 
 // MCP Tool Names
 const (
 	// Bitbucket Tools
-	ToolBitbucketGetDiff        = "bitbucket_get_pull_request_diff"
-	ToolBitbucketGetComments    = "bitbucket_get_pull_request_comments"
-	ToolBitbucketAddComment     = "bitbucket_add_pull_request_comment"
-	ToolBitbucketGetChanges     = "bitbucket_get_pull_request_changes"
-	ToolBitbucketGetFileContent = "bitbucket_get_file_content"
-	ToolBitbucketGetPullRequest = "bitbucket_get_pull_request"
+	ToolBitbucketGetDiff         = "bitbucket_get_pull_request_diff"
+	ToolBitbucketGetComments     = "bitbucket_get_pull_request_comments"
+	ToolBitbucketAddComment      = "bitbucket_add_pull_request_comment"
+	ToolBitbucketAddComments     = "bitbucket_add_pull_request_comments" // Optional batch variant
+	ToolBitbucketGetChanges      = "bitbucket_get_pull_request_changes"
+	ToolBitbucketGetFileContent  = "bitbucket_get_file_content"
+	ToolBitbucketGetPullRequest  = "bitbucket_get_pull_request"
+	ToolBitbucketGetPullRequests = "bitbucket_get_pull_requests"              // Lists the pull requests of a repository (polling)
+	ToolBitbucketAddTask         = "bitbucket_add_pull_request_task"          // Optional: blocking task on a comment
+	ToolBitbucketUpdateComment   = "bitbucket_update_pull_request_comment"    // Optional: edits or resolves a comment
+	ToolBitbucketDeleteComment   = "bitbucket_delete_pull_request_comment"    // Optional: deletes a comment
+	ToolBitbucketSetReviewStatus = "bitbucket_set_pull_request_review_status" // Optional: approves or marks needs work
+	ToolBitbucketSetBuildStatus  = "bitbucket_set_commit_build_status"        // Optional: build status of a commit
+	ToolBitbucketUpdatePR        = "bitbucket_update_pull_request"            // Optional: sets the PR description
+)
+
+// Jira Tools
+const (
+	ToolJiraCreateIssue  = "jira_create_issue"
+	DefaultJiraIssueType = "Bug"
+)
+
+// Confluence Tools
+const (
+	ToolConfluenceSearch  = "confluence_search"   // Default search tool of retrieval sources
+	ToolConfluenceGetPage = "confluence_get_page" // Default page tool of pipeline.standards
 )
 
 // Tool Sets
 var (
 	// ChunkedReviewAllowedTools is the minimal toolset for chunked PR review
 	ChunkedReviewAllowedTools = []string{ToolBitbucketGetFileContent}
+
+	// BitbucketWriteTools change pull request state and are never sent to a read replica
+	BitbucketWriteTools = []string{ToolBitbucketAddComment, ToolBitbucketAddComments, ToolBitbucketAddTask, ToolBitbucketUpdateComment, ToolBitbucketDeleteComment, ToolBitbucketSetReviewStatus, ToolBitbucketSetBuildStatus, ToolBitbucketUpdatePR}
 )
+
+// FindingSeverities lists the severities of review findings from highest to lowest
+var FindingSeverities = []string{"CRITICAL", "WARNING", "INFO", "NIT"}
+
+// FindingCategories lists the categories of review findings
+var FindingCategories = []string{"security", "performance", "style", "logic"}
+
+// SeverityCapScoreRefund is the score given back per severity level a cap removes from a
+// finding, since the model lowered its score for the uncapped severity
+const SeverityCapScoreRefund = 5
+
+// DefaultRetryJitter randomizes retry delays by ±20% so instances that failed together do not
+// retry in lockstep
+const DefaultRetryJitter = 0.2
+
+// ContextOutputReserveDivisor sets aside 1/8 of a model's known context window for the answer
+// when stage3_review.params.max_tokens does not say how long it may be
+const ContextOutputReserveDivisor = 8
+
+// DefaultCommandPrefix starts slash commands in pull request comments, e.g. "/ai review"
+const DefaultCommandPrefix = "/ai"
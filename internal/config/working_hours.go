@@ -0,0 +1,97 @@
+package config
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// WorkingSchedule is the parsed form of WorkingHoursConfig
+type WorkingSchedule struct {
+	Location *time.Location
+	Start    time.Duration // Offset from local midnight
+	End      time.Duration
+	Days     map[time.Weekday]bool
+}
+
+var weekdayNames = map[string]time.Weekday{
+	"sun": time.Sunday, "mon": time.Monday, "tue": time.Tuesday, "wed": time.Wednesday,
+	"thu": time.Thursday, "fri": time.Friday, "sat": time.Saturday,
+}
+
+// Schedule parses the working hours configuration
+func (c WorkingHoursConfig) Schedule() (*WorkingSchedule, error) {
+	s := &WorkingSchedule{Location: time.Local, Days: make(map[time.Weekday]bool)}
+	if c.Timezone != "" {
+		loc, err := time.LoadLocation(c.Timezone)
+		if err != nil {
+			return nil, fmt.Errorf("invalid timezone %q: %w", c.Timezone, err)
+		}
+		s.Location = loc
+	}
+
+	var err error
+	if s.Start, err = parseClock(c.Start); err != nil {
+		return nil, fmt.Errorf("invalid start: %w", err)
+	}
+	if s.End, err = parseClock(c.End); err != nil {
+		return nil, fmt.Errorf("invalid end: %w", err)
+	}
+	if s.End <= s.Start {
+		return nil, fmt.Errorf("end %s must be after start %s", c.End, c.Start)
+	}
+
+	for _, d := range c.Weekdays {
+		name := strings.ToLower(strings.TrimSpace(d))
+		if len(name) > 3 {
+			name = name[:3] // Accept full names ("monday")
+		}
+		wd, ok := weekdayNames[name]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", d)
+		}
+		s.Days[wd] = true
+	}
+	if len(s.Days) == 0 {
+		return nil, fmt.Errorf("no working days configured")
+	}
+	return s, nil
+}
+
+// Contains reports whether t falls within working hours
+func (s *WorkingSchedule) Contains(t time.Time) bool {
+	t = t.In(s.Location)
+	if !s.Days[t.Weekday()] {
+		return false
+	}
+	offset := clockOffset(t)
+	return offset >= s.Start && offset < s.End
+}
+
+// NextStart returns the next start of working hours strictly after t
+func (s *WorkingSchedule) NextStart(t time.Time) time.Time {
+	t = t.In(s.Location)
+	h, m := int(s.Start/time.Hour), int(s.Start%time.Hour/time.Minute)
+	for i := 0; i <= 7; i++ {
+		// Wall-clock construction keeps the start time stable across DST changes
+		start := time.Date(t.Year(), t.Month(), t.Day()+i, h, m, 0, 0, s.Location)
+		if s.Days[start.Weekday()] && start.After(t) {
+			return start
+		}
+	}
+	return t // unreachable: Schedule requires at least one working day
+}
+
+// clockOffset returns the wall-clock time of day as an offset from midnight
+func clockOffset(t time.Time) time.Duration {
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute + time.Duration(t.Second())*time.Second
+}
+
+// parseClock parses "HH:MM" into an offset from midnight
+func parseClock(v string) (time.Duration, error) {
+	t, err := time.Parse("15:04", v)
+	if err != nil {
+		return 0, fmt.Errorf("%q is not HH:MM", v)
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute, nil
+}
@@ -0,0 +1,60 @@
+package config
+
+import (
+	"testing"
+	"time"
+)
+
+func TestWorkingHoursConfig_Schedule(t *testing.T) {
+	cfg := WorkingHoursConfig{Timezone: "Europe/Berlin", Start: "09:00", End: "18:00", Weekdays: []string{"mon", "tue", "wed", "thu", "Friday"}}
+	s, err := cfg.Schedule()
+	if err != nil {
+		t.Fatalf("Schedule: %v", err)
+	}
+	berlin := s.Location
+
+	tests := []struct {
+		name      string
+		at        time.Time
+		wantIn    bool
+		wantStart time.Time
+	}{
+		{"weekday morning", time.Date(2026, 3, 2, 10, 0, 0, 0, berlin), true, time.Date(2026, 3, 3, 9, 0, 0, 0, berlin)},
+		{"before start", time.Date(2026, 3, 2, 8, 59, 0, 0, berlin), false, time.Date(2026, 3, 2, 9, 0, 0, 0, berlin)},
+		{"at end", time.Date(2026, 3, 2, 18, 0, 0, 0, berlin), false, time.Date(2026, 3, 3, 9, 0, 0, 0, berlin)},
+		{"friday night", time.Date(2026, 3, 6, 22, 0, 0, 0, berlin), false, time.Date(2026, 3, 9, 9, 0, 0, 0, berlin)},
+		{"saturday", time.Date(2026, 3, 7, 12, 0, 0, 0, berlin), false, time.Date(2026, 3, 9, 9, 0, 0, 0, berlin)},
+		{"other zone", time.Date(2026, 3, 2, 8, 30, 0, 0, time.UTC), true, time.Date(2026, 3, 3, 9, 0, 0, 0, berlin)},
+		{"across dst change", time.Date(2026, 3, 28, 12, 0, 0, 0, berlin), false, time.Date(2026, 3, 30, 9, 0, 0, 0, berlin)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := s.Contains(tt.at); got != tt.wantIn {
+				t.Errorf("Contains() = %v, want %v", got, tt.wantIn)
+			}
+			if got := s.NextStart(tt.at); !got.Equal(tt.wantStart) {
+				t.Errorf("NextStart() = %v, want %v", got, tt.wantStart)
+			}
+		})
+	}
+}
+
+func TestWorkingHoursConfig_ScheduleInvalid(t *testing.T) {
+	tests := []struct {
+		name string
+		cfg  WorkingHoursConfig
+	}{
+		{"bad timezone", WorkingHoursConfig{Timezone: "Mars/Base", Start: "09:00", End: "18:00", Weekdays: []string{"mon"}}},
+		{"bad start", WorkingHoursConfig{Start: "9am", End: "18:00", Weekdays: []string{"mon"}}},
+		{"end before start", WorkingHoursConfig{Start: "18:00", End: "09:00", Weekdays: []string{"mon"}}},
+		{"bad weekday", WorkingHoursConfig{Start: "09:00", End: "18:00", Weekdays: []string{"someday"}}},
+		{"no weekdays", WorkingHoursConfig{Start: "09:00", End: "18:00"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := tt.cfg.Schedule(); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
@@ -0,0 +1,45 @@
+package domain
+
+import "strings"
+
+// Slash commands posted in pull request comments
+const (
+	CommandReview  = "review"  // Review the pull request now
+	CommandExplain = "explain" // Explain the code at file:line
+	CommandIgnore  = "ignore"  // Stop automatic reviews of the pull request
+	CommandHelp    = "help"    // List the commands
+)
+
+// Command is a slash command posted in a pull request comment
+type Command struct {
+	Name      string // Lowercased command name, e.g. "explain"; may be unknown
+	Args      string // Rest of the first line, e.g. "file.go:42"
+	Author    string // Display name of the commenter
+	CommentID int64  // Comment holding the command; answers are posted as replies to it
+}
+
+// ParseCommand recognizes a command on the first line of a comment: the prefix (e.g. "/ai"),
+// a command name and its arguments. The prefix alone is the help command. ok is false for
+// comments that do not start with the prefix.
+func ParseCommand(prefix, text string) (cmd Command, ok bool) {
+	line, _, _ := strings.Cut(strings.TrimSpace(text), "\n")
+	fields := strings.Fields(line)
+	if len(fields) == 0 || !strings.EqualFold(fields[0], prefix) {
+		return Command{}, false
+	}
+	if len(fields) == 1 {
+		return Command{Name: CommandHelp}, true
+	}
+	return Command{
+		Name: strings.ToLower(fields[1]),
+		Args: strings.Join(fields[2:], " "),
+	}, true
+}
+
+// CommentReply is a reply posted in a comment thread of a pull request
+type CommentReply struct {
+	CommentID int64 // The reply; the answer is threaded under it
+	ParentID  int64 // Comment replied to
+	Text      string
+	Author    string // Display name of the commenter
+}
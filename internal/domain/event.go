@@ -0,0 +1,27 @@
+package domain
+
+import (
+	"errors"
+	"time"
+)
+
+// Review completion statuses carried by ReviewCompletedEvent
+const (
+	ReviewStatusSuccess = "success"
+	ReviewStatusFailed  = "failed"
+	ReviewStatusTimeout = "timeout" // Stopped by server.review_timeout
+)
+
+// ErrReviewTimeout is the cancellation cause of a review that ran past server.review_timeout
+var ErrReviewTimeout = errors.New("review timed out")
+
+// ReviewCompletedEvent is emitted once per processed PR, after comments are posted (or on failure).
+// Subscribers must treat the payload as read-only; it is shared across all of them.
+type ReviewCompletedEvent struct {
+	PR        *PullRequest
+	Result    *ReviewResult // nil when the review failed before producing a result
+	Status    string        // success, failed, timeout
+	Error     string        // Failure reason, empty on success
+	StartedAt time.Time
+	Duration  time.Duration
+}
@@ -0,0 +1,15 @@
+package domain
+
+import "time"
+
+// IntakeStatus describes webhook intake during a maintenance window. While paused, webhooks
+// are rejected with 503; once in-flight reviews finish, pending ones are snapshotted to storage.
+type IntakeStatus struct {
+	Paused      bool       `json:"paused"`
+	PausedAt    *time.Time `json:"pausedAt,omitempty"`
+	RetryAfter  int        `json:"retryAfterSeconds,omitempty"` // Retry-After sent with 503 responses
+	Drained     bool       `json:"drained"`                     // No review running and the pending queue snapshotted
+	InFlight    int        `json:"inFlight"`                    // Jobs running or waiting in the worker queue
+	Pending     int        `json:"pending"`                     // Reviews held in memory (debouncing or parked)
+	Snapshotted int        `json:"snapshotted"`                 // Reviews saved to storage by the last drain
+}
@@ -17,7 +17,38 @@ type PullRequest struct {
 	Author       string
 	LatestCommit string // Latest commit SHA for tracking reviewed versions
 	WebURL       string // Full URL to the pull request in the web interface
-	// SourceBranch and TargetBranch can be added here if needed in the future
+	Draft        bool   // Draft (work in progress) pull request, see review.drafts
+
+	// Host-specific mention markup resolved from the webhook payload,
+	// e.g. "@jdoe" (Bitbucket Server) or "@{557058:...}" (Bitbucket Cloud)
+	AuthorMention    string
+	ReviewerMentions []string
+
+	// Provider is the SCM hosting the pull request (see ProviderGitHub); empty means Bitbucket.
+	// For GitHub, ProjectKey is the repository owner and RepoSlug the repository name;
+	// for GitLab, ProjectKey is the namespace path and RepoSlug the project path.
+	Provider string
+
+	// Overrides are per-review settings of an API-triggered review; nil for webhook reviews
+	Overrides *ReviewOverrides `json:",omitempty"`
+	// Faults are the fault_injection kinds requested for this review through the fault header
+	Faults []string `json:",omitempty"`
+	// Labels are the PR's labels, where the SCM has them (GitHub, GitLab, Gitea)
+	Labels []string `json:",omitempty"`
+	// RepoConfig is the repository's settings file at LatestCommit; nil when it has none
+	RepoConfig *RepoConfig `json:",omitempty"`
+	// SourceBranch and TargetBranch are the branch names, without refs/heads/
+	SourceBranch string `json:",omitempty"`
+	TargetBranch string `json:",omitempty"`
+}
+
+// ReviewOverrides replace configuration for a single review, so engineers debugging a
+// specific PR can experiment without changing the global config
+type ReviewOverrides struct {
+	Direct      bool   `json:"direct,omitempty"`      // Review all changes in one call, without degradation or chunking
+	ChunkTokens int    `json:"chunkTokens,omitempty"` // Token budget for degradation and chunking instead of max_context_tokens
+	Model       string `json:"model,omitempty"`       // LLM model instead of llm.model
+	DryRun      bool   `json:"dryRun,omitempty"`      // Review and store the result, but post nothing
 }
 
 // IsValid checks if the PullRequest has the minimum required fields to proceed.
@@ -30,15 +61,111 @@ const (
 	CommentSeverityWarning  = "WARNING"
 	CommentSeverityCritical = "CRITICAL"
 	CommentSeverityNit      = "NIT"
+
+	// Finding categories; findings of the security review pass are always CommentCategorySecurity
+	CommentCategorySecurity    = "security"
+	CommentCategoryPerformance = "performance"
+	CommentCategoryStyle       = "style"
+	CommentCategoryLogic       = "logic"
 )
 
+// categoryAliases maps other names models use for a category to the category
+var categoryAliases = map[string]string{
+	"vulnerability": CommentCategorySecurity,
+	"perf":          CommentCategoryPerformance,
+	"efficiency":    CommentCategoryPerformance,
+	"readability":   CommentCategoryStyle,
+	"formatting":    CommentCategoryStyle,
+	"naming":        CommentCategoryStyle,
+	"bug":           CommentCategoryLogic,
+	"correctness":   CommentCategoryLogic,
+}
+
+// NormalizeCategory returns the category a model answered as one of the CommentCategory
+// constants, or "" when it is none of them
+func NormalizeCategory(category string) string {
+	category = strings.ToLower(strings.TrimSpace(category))
+	switch category {
+	case CommentCategorySecurity, CommentCategoryPerformance, CommentCategoryStyle, CommentCategoryLogic:
+		return category
+	}
+	return categoryAliases[category]
+}
+
 // ReviewComment represents a single review comment
 type ReviewComment struct {
 	File     string       `json:"path"`
 	Line     FlexibleLine `json:"line"`
 	Comment  string       `json:"message"`
 	Severity string       `json:"severity,omitempty"`
-	Marker   string       `json:"marker,omitempty"` // Internal use for deduplication
+	Marker   string       `json:"marker,omitempty"`   // Internal use for deduplication
+	Chunk    int          `json:"chunk,omitempty"`    // Source chunk (1-based) in the execution report
+	Category string       `json:"category,omitempty"` // security, performance, style or logic; empty when unknown
+
+	// Review contract v2 fields; responses to v1 prompts leave them unset
+	EndLine    int        `json:"end_line,omitempty"`   // Last line of the commented range
+	Suggestion string     `json:"suggestion,omitempty"` // Replacement code for the commented lines
+	Confidence Confidence `json:"confidence,omitempty"` // How sure the model is, from 0 to 1
+}
+
+// UnmarshalJSON reads a line range given as "line": [start, end] into Line and EndLine
+func (c *ReviewComment) UnmarshalJSON(data []byte) error {
+	type plain ReviewComment
+	var aux struct {
+		plain
+		Line json.RawMessage `json:"line"`
+	}
+	if err := json.Unmarshal(data, &aux); err != nil {
+		return err
+	}
+	*c = ReviewComment(aux.plain)
+	if len(aux.Line) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(aux.Line, &c.Line); err != nil {
+		return err
+	}
+	var lines []int
+	if json.Unmarshal(aux.Line, &lines) == nil && len(lines) > 1 && c.EndLine == 0 && lines[len(lines)-1] > lines[0] {
+		c.EndLine = lines[len(lines)-1]
+	}
+	return nil
+}
+
+// Confidence accepts a number from 0 to 1, a percentage, or "high", "medium" and "low"
+type Confidence float64
+
+func (c *Confidence) UnmarshalJSON(data []byte) error {
+	var f float64
+	if err := json.Unmarshal(data, &f); err != nil {
+		var s string
+		if json.Unmarshal(data, &s) != nil {
+			return nil // Unknown shapes are ignored like unparseable lines
+		}
+		switch s = strings.ToLower(strings.TrimSpace(s)); s {
+		case "high":
+			f = 0.9
+		case "medium":
+			f = 0.6
+		case "low":
+			f = 0.3
+		default:
+			if _, err := fmt.Sscanf(strings.TrimSuffix(s, "%"), "%g", &f); err != nil {
+				return nil
+			}
+			if strings.HasSuffix(s, "%") {
+				f /= 100
+			}
+		}
+	}
+	if f > 1 && f <= 100 {
+		f /= 100
+	}
+	if f < 0 || f > 1 {
+		f = 0
+	}
+	*c = Confidence(f)
+	return nil
 }
 
 // FlexibleLine handles both int and []int JSON input, resolving to a single int anchor.
@@ -85,10 +212,16 @@ func (c *ReviewComment) IsHighSeverity() bool {
 	return s == CommentSeverityCritical || s == CommentSeverityWarning
 }
 
+// IsCritical checks if the comment is a must-fix (CRITICAL) finding.
+func (c *ReviewComment) IsCritical() bool {
+	return strings.EqualFold(c.Severity, CommentSeverityCritical)
+}
+
 // ReviewRequest represents a request to review a PR
 type ReviewRequest struct {
 	PR                 *PullRequest
 	HistoricalComments []ReviewComment
+	Cache              *ReviewContext // Optional: the diff, fetched once for processor and reviewer
 }
 
 // ReviewResult represents the outcome of a review
@@ -97,4 +230,64 @@ type ReviewResult struct {
 	Score    int             `json:"score"`
 	Summary  string          `json:"summary"`
 	Model    string
+	Usage    *TokenUsage      `json:"usage,omitempty"`  // LLM token usage, summed across chunks
+	Report   *ExecutionReport `json:"report,omitempty"` // How the review was executed
+
+	// RawComments are the reviewer's findings before hooks, validation and deduplication,
+	// kept so stored reviews can be replayed against new configuration
+	RawComments []ReviewComment `json:"raw_comments,omitempty"`
+
+	Duplicates []DuplicateMatch `json:"duplicates,omitempty"` // Recent PRs with matching changes
+	Assets     []AssetNote      `json:"assets,omitempty"`     // Image and diagram files added by the PR
+	Coverage   []CoverageGap    `json:"coverage,omitempty"`   // Changed source files without changed tests
+
+	Contract string `json:"contract,omitempty"` // Review contract version the response followed (v1, v2)
+
+	Outcome  string `json:"outcome,omitempty"`  // Classification of the model response (see OutcomeOK)
+	Retried  bool   `json:"retried,omitempty"`  // The response was retried with a reinforcement prompt
+	Repaired bool   `json:"repaired,omitempty"` // The response was corrected after failing the result schema
+
+	SummaryOnly bool `json:"summary_only,omitempty"` // Only the summary was posted; Comments were not posted inline
+
+	Provenance *Provenance `json:"provenance,omitempty"` // Build and configuration that produced the review
+}
+
+// Review outcomes, classified from the model response
+const (
+	OutcomeOK          = "ok"
+	OutcomeRefusal     = "refusal"     // The model declined to review
+	OutcomeEmpty       = "empty"       // No content, or neither findings nor summary
+	OutcomeLowContent  = "low_content" // No findings and a generic summary for a sizeable change
+	OutcomeUnparseable = "unparseable" // Not valid review JSON (e.g. truncated)
+)
+
+// AssetNote is an image or diagram file added by the PR
+type AssetNote struct {
+	Path    string `json:"path"`
+	Comment string `json:"comment,omitempty"` // The model's sanity comment; empty when not reviewed
+	Reason  string `json:"reason,omitempty"`  // Why the asset was not reviewed
+}
+
+// CoverageGap is a changed source file whose tests the PR does not change
+type CoverageGap struct {
+	File      string   `json:"file"`
+	TestFile  string   `json:"test_file"`          // Where its tests conventionally live
+	Functions []string `json:"functions"`          // Functions and methods the PR adds or changes
+	Language  string   `json:"language,omitempty"` // Language of the skeleton: go, java or python
+	Skeleton  string   `json:"skeleton,omitempty"` // Suggested table-driven test skeleton
+}
+
+// Duplicate match kinds
+const (
+	DuplicateKindDuplicate = "duplicate" // Makes the same changes as the other PR
+	DuplicateKindRevert    = "revert"    // Undoes the changes of the other PR
+)
+
+// DuplicateMatch is a recent PR of the same repository whose diff matches this PR
+type DuplicateMatch struct {
+	PRID       string  `json:"pr_id"`
+	Title      string  `json:"title,omitempty"`
+	WebURL     string  `json:"web_url,omitempty"`
+	Kind       string  `json:"kind"`
+	Similarity float64 `json:"similarity"` // Share of identical changed lines (0-1)
 }
@@ -0,0 +1,20 @@
+package domain
+
+import "context"
+
+type projectKey struct{}
+
+// WithProject returns a context whose LLM calls are attributed to the project (project key,
+// organization or group) in usage metrics. An empty project leaves ctx unchanged.
+func WithProject(ctx context.Context, project string) context.Context {
+	if project == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, projectKey{}, project)
+}
+
+// ProjectFromContext returns the project set by WithProject, or "" outside a PR
+func ProjectFromContext(ctx context.Context) string {
+	p, _ := ctx.Value(projectKey{}).(string)
+	return p
+}
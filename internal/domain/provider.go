@@ -0,0 +1,32 @@
+package domain
+
+import "context"
+
+// SCM providers
+const (
+	ProviderBitbucket      = "bitbucket"
+	ProviderGitHub         = "github"
+	ProviderGitLab         = "gitlab"
+	ProviderGitea          = "gitea" // Also Forgejo
+	ProviderBitbucketCloud = "bitbucket-cloud"
+	ProviderLocal          = "local" // A diff without a code host: the review CLI or POST /api/v1/reviews/patch
+)
+
+type providerKey struct{}
+
+// WithProvider returns a context that routes SCM tool calls to the given provider.
+// An empty provider leaves ctx unchanged (Bitbucket).
+func WithProvider(ctx context.Context, provider string) context.Context {
+	if provider == "" {
+		return ctx
+	}
+	return context.WithValue(ctx, providerKey{}, provider)
+}
+
+// ProviderFromContext returns the SCM provider set by WithProvider, or ProviderBitbucket
+func ProviderFromContext(ctx context.Context) string {
+	if p, ok := ctx.Value(providerKey{}).(string); ok && p != "" {
+		return p
+	}
+	return ProviderBitbucket
+}
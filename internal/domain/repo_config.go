@@ -0,0 +1,10 @@
+package domain
+
+// RepoConfig holds the settings a repository keeps in its own settings file (.ai-review.yaml),
+// merged over the server configuration for reviews of its pull requests
+type RepoConfig struct {
+	Enabled     *bool    `yaml:"enabled" json:"enabled,omitempty"`           // false stops automatic reviews (if repo_config.allow_disable)
+	Ignore      []string `yaml:"ignore" json:"ignore,omitempty"`             // File globs left out of the review, e.g. "docs/**"
+	MinSeverity string   `yaml:"min_severity" json:"min_severity,omitempty"` // Findings below this severity are dropped
+	Prompt      string   `yaml:"prompt" json:"prompt,omitempty"`             // Stage 3 prompt template under prompts.dir
+}
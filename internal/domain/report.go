@@ -0,0 +1,84 @@
+package domain
+
+// Review execution strategies, from full context to the most degraded
+const (
+	StrategyFull           = "full"
+	StrategyContextTrimmed = "l1_context_truncation"
+	StrategyChunked        = "l2_chunk_by_file"
+	StrategyDiffOnly       = "l3_diff_only"
+)
+
+// TokenUsage is the LLM token usage reported by the provider
+type TokenUsage struct {
+	PromptTokens     int64 `json:"prompt_tokens"`
+	CompletionTokens int64 `json:"completion_tokens"`
+}
+
+// Add accumulates other into u. A nil other is ignored.
+func (u *TokenUsage) Add(other *TokenUsage) {
+	if other == nil {
+		return
+	}
+	u.PromptTokens += other.PromptTokens
+	u.CompletionTokens += other.CompletionTokens
+}
+
+// Provenance identifies the build and configuration that produced a review
+type Provenance struct {
+	BotVersion    string `json:"bot_version"`
+	PromptVersion string `json:"prompt_version,omitempty"` // Short hash of the prompt files
+	Model         string `json:"model,omitempty"`
+	Profile       string `json:"profile,omitempty"`        // Config profile name
+	ConfigVersion string `json:"config_version,omitempty"` // Short hash of the config file
+	ReviewID      string `json:"review_id,omitempty"`      // Stored review holding the execution report
+}
+
+// ChunkReport describes the review of one chunk (the whole PR when not chunked)
+type ChunkReport struct {
+	Index           int         `json:"index"` // 1-based
+	Files           []string    `json:"files"`
+	EstimatedTokens int         `json:"estimated_tokens"` // Input estimate used for chunk planning
+	DurationMs      int64       `json:"duration_ms"`
+	Findings        int         `json:"findings"`
+	Usage           *TokenUsage `json:"usage,omitempty"`
+	Outcome         string      `json:"outcome,omitempty"` // Classification of the model response
+	Retried         bool        `json:"retried,omitempty"`
+	Repaired        bool        `json:"repaired,omitempty"`
+	Model           string      `json:"model,omitempty"` // Set when a fallback model may have answered
+	Error           string      `json:"error,omitempty"`
+}
+
+// ExecutionReport records how a review was executed and where its findings came from
+type ExecutionReport struct {
+	Strategy       string         `json:"strategy"`
+	Chunks         []ChunkReport  `json:"chunks"`
+	FindingsByFile map[string]int `json:"findings_by_file"`
+	// Unattributed counts findings whose path was not among the files of their chunk,
+	// which usually means the model invented or misspelled a path
+	Unattributed int `json:"unattributed"`
+	// DebugArtifact is the file holding the streamed model output (pipeline.stage3_review.stream_debug)
+	DebugArtifact string `json:"debug_artifact,omitempty"`
+}
+
+// Attribute fills the per-file finding counts from the final comments.
+// Comments carry their source chunk in ReviewComment.Chunk.
+func (r *ExecutionReport) Attribute(comments []ReviewComment) {
+	chunkFiles := make(map[int]map[string]bool, len(r.Chunks))
+	for _, c := range r.Chunks {
+		files := make(map[string]bool, len(c.Files))
+		for _, f := range c.Files {
+			files[f] = true
+		}
+		chunkFiles[c.Index] = files
+	}
+
+	r.FindingsByFile = make(map[string]int)
+	r.Unattributed = 0
+	for _, c := range comments {
+		if c.File == "" || !chunkFiles[c.Chunk][c.File] {
+			r.Unattributed++
+			continue
+		}
+		r.FindingsByFile[c.File]++
+	}
+}
@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"context"
+	"sync"
+)
+
+// ReviewContext caches what one review of a pull request fetches from the code host, so the
+// processor (size gate, duplicate detection, comment validation) and the reviewer (diff
+// extraction and splitting) share a single fetch. A failed fetch is not cached and is retried
+// by the next caller.
+type ReviewContext struct {
+	fetchDiff func(ctx context.Context) (string, error)
+
+	mu   sync.Mutex
+	diff string
+}
+
+// NewReviewContext creates a review context that fetches the diff with fetchDiff on first use
+func NewReviewContext(fetchDiff func(ctx context.Context) (string, error)) *ReviewContext {
+	return &ReviewContext{fetchDiff: fetchDiff}
+}
+
+// Diff returns the pull request's unified diff, fetching it on first use
+func (rc *ReviewContext) Diff(ctx context.Context) (string, error) {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+	if rc.diff != "" {
+		return rc.diff, nil
+	}
+	diff, err := rc.fetchDiff(ctx)
+	if err != nil {
+		return "", err
+	}
+	rc.diff = diff
+	return diff, nil
+}
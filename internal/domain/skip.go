@@ -0,0 +1,27 @@
+package domain
+
+// Review skip reasons recorded in the skip ledger
+const (
+	SkipReasonEventFilter = "event_filter" // Event or PR excluded by a filter
+	SkipReasonSizeGate    = "size_gate"    // Diff too large to review
+	SkipReasonBudget      = "budget"       // Token or cost budget exhausted
+	SkipReasonDryRun      = "dry_run"      // Reviewed but not posted
+	SkipReasonHook        = "hook"         // Stopped by a processor hook without a specific reason
+	SkipReasonIgnored     = "ignored"      // Automatic reviews paused with a comment command
+	SkipReasonRepoConfig  = "repo_config"  // Disabled by the repository's settings file
+	SkipReasonDraft       = "draft"        // Draft PR, with review.drafts: skip
+	SkipReasonOptOut      = "opt_out"      // Title tag or label opting the PR out
+)
+
+// SkipReasonDescriptions are the human-readable reasons used in transparency notes
+var SkipReasonDescriptions = map[string]string{
+	SkipReasonEventFilter: "this pull request is excluded by the review filters",
+	SkipReasonSizeGate:    "the change is too large for an automated review",
+	SkipReasonBudget:      "the review budget is exhausted",
+	SkipReasonDryRun:      "the reviewer runs in dry-run mode",
+	SkipReasonHook:        "a repository policy stopped the review",
+	SkipReasonIgnored:     "automatic reviews of this pull request are paused",
+	SkipReasonRepoConfig:  "the repository's settings file disables automatic reviews",
+	SkipReasonDraft:       "this pull request is a draft; it is reviewed once it is ready",
+	SkipReasonOptOut:      "this pull request opted out of automated review",
+}
@@ -0,0 +1,129 @@
+package event
+
+import (
+	"context"
+	"log/slog"
+	"runtime/debug"
+	"sync"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+)
+
+// DefaultBufferSize is the default capacity of the event channel
+const DefaultBufferSize = 100
+
+// Subscriber receives review completion events
+type Subscriber interface {
+	OnReviewCompleted(ctx context.Context, evt domain.ReviewCompletedEvent)
+}
+
+// SubscriberFunc adapts a function to the Subscriber interface
+type SubscriberFunc func(ctx context.Context, evt domain.ReviewCompletedEvent)
+
+// OnReviewCompleted implements Subscriber
+func (f SubscriberFunc) OnReviewCompleted(ctx context.Context, evt domain.ReviewCompletedEvent) {
+	f(ctx, evt)
+}
+
+// Bus fans out review events to subscribers asynchronously.
+// Publishing never blocks the processor: events are dropped when the buffer is full.
+type Bus struct {
+	events      chan domain.ReviewCompletedEvent
+	mu          sync.RWMutex // Guards subscribers and closed; held while sending so Close cannot close events mid-send
+	subscribers []Subscriber
+	closed      bool
+	wg          sync.WaitGroup
+	ctx         context.Context
+	cancel      context.CancelFunc
+}
+
+// NewBus creates a new event bus and starts its dispatch loop
+func NewBus(bufferSize int) *Bus {
+	if bufferSize <= 0 {
+		bufferSize = DefaultBufferSize
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	b := &Bus{
+		events: make(chan domain.ReviewCompletedEvent, bufferSize),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	b.wg.Add(1)
+	go b.dispatch()
+	return b
+}
+
+// Subscribe registers a subscriber. Subscribers are called sequentially in registration order.
+// Subscribing after Close is ignored.
+func (b *Bus) Subscribe(s Subscriber) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.closed {
+		slog.Warn("subscribe on closed event bus")
+		return
+	}
+	b.subscribers = append(b.subscribers, s)
+}
+
+// Publish enqueues an event without blocking. Events published after Close are dropped.
+func (b *Bus) Publish(evt domain.ReviewCompletedEvent) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	if b.closed {
+		slog.Warn("publish on closed event bus", "pr_id", prID(evt))
+		metrics.EventsPublished.WithLabelValues("dropped").Inc()
+		return
+	}
+
+	select {
+	case b.events <- evt:
+		metrics.EventsPublished.WithLabelValues("queued").Inc()
+	default:
+		slog.Warn("event buffer full, dropping event", "pr_id", prID(evt))
+		metrics.EventsPublished.WithLabelValues("dropped").Inc()
+	}
+}
+
+// Close stops accepting events and waits for pending events to be delivered
+func (b *Bus) Close() {
+	b.mu.Lock()
+	if !b.closed {
+		b.closed = true
+		close(b.events)
+	}
+	b.mu.Unlock()
+
+	b.wg.Wait()
+	b.cancel()
+}
+
+func (b *Bus) dispatch() {
+	defer b.wg.Done()
+	for evt := range b.events {
+		b.mu.RLock()
+		subs := append([]Subscriber(nil), b.subscribers...)
+		b.mu.RUnlock()
+
+		for _, s := range subs {
+			b.deliver(s, evt)
+		}
+	}
+}
+
+// deliver isolates subscriber panics so one bad subscriber cannot stop the bus
+func (b *Bus) deliver(s Subscriber, evt domain.ReviewCompletedEvent) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Error("panic in event subscriber", "panic", r, "stack", string(debug.Stack()))
+		}
+	}()
+	s.OnReviewCompleted(b.ctx, evt)
+}
+
+func prID(evt domain.ReviewCompletedEvent) string {
+	if evt.PR == nil {
+		return ""
+	}
+	return evt.PR.ID
+}
@@ -0,0 +1,84 @@
+package event
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"pr-review-automation/internal/domain"
+)
+
+func TestBus_DeliversToAllSubscribers(t *testing.T) {
+	bus := NewBus(10)
+
+	var mu sync.Mutex
+	received := make(map[string]int)
+	for _, name := range []string{"a", "b"} {
+		name := name
+		bus.Subscribe(SubscriberFunc(func(ctx context.Context, evt domain.ReviewCompletedEvent) {
+			mu.Lock()
+			received[name]++
+			mu.Unlock()
+		}))
+	}
+
+	for i := 0; i < 3; i++ {
+		bus.Publish(domain.ReviewCompletedEvent{PR: &domain.PullRequest{ID: "1"}, Status: domain.ReviewStatusSuccess})
+	}
+	bus.Close()
+
+	if received["a"] != 3 || received["b"] != 3 {
+		t.Errorf("expected 3 events per subscriber, got %v", received)
+	}
+}
+
+func TestBus_SubscriberPanicIsIsolated(t *testing.T) {
+	bus := NewBus(10)
+
+	var delivered int
+	bus.Subscribe(SubscriberFunc(func(ctx context.Context, evt domain.ReviewCompletedEvent) {
+		panic("boom")
+	}))
+	bus.Subscribe(SubscriberFunc(func(ctx context.Context, evt domain.ReviewCompletedEvent) {
+		delivered++
+	}))
+
+	bus.Publish(domain.ReviewCompletedEvent{PR: &domain.PullRequest{ID: "1"}})
+	bus.Close()
+
+	if delivered != 1 {
+		t.Errorf("expected second subscriber to receive event, got %d", delivered)
+	}
+}
+
+func TestBus_PublishAfterCloseDoesNotPanic(t *testing.T) {
+	bus := NewBus(1)
+	bus.Close()
+	bus.Publish(domain.ReviewCompletedEvent{PR: &domain.PullRequest{ID: "1"}})
+}
+
+func TestBus_SubscribeAfterCloseIsIgnored(t *testing.T) {
+	bus := NewBus(1)
+	bus.Close()
+	bus.Subscribe(SubscriberFunc(func(ctx context.Context, evt domain.ReviewCompletedEvent) {}))
+	if len(bus.subscribers) != 0 {
+		t.Errorf("expected no subscribers after Close, got %d", len(bus.subscribers))
+	}
+	bus.Close()
+}
+
+func TestBus_PublishRacingClose(t *testing.T) {
+	bus := NewBus(1)
+	var wg sync.WaitGroup
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				bus.Publish(domain.ReviewCompletedEvent{PR: &domain.PullRequest{ID: "1"}})
+			}
+		}()
+	}
+	bus.Close()
+	wg.Wait()
+}
@@ -0,0 +1,139 @@
+// Package fault simulates dependency failures (fault_injection) so the resilience features can
+// be exercised in staging.
+package fault
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/rules"
+)
+
+// ErrInjected marks every simulated failure
+var ErrInjected = errors.New("injected fault")
+
+// defaultDelay is how long an injected MCP timeout hangs when the rule sets no delay
+const defaultDelay = 5 * time.Second
+
+// Storage operations, the targets of storage_error rules
+const (
+	OpRead  = "read"
+	OpWrite = "write"
+)
+
+type faultsKey struct{}
+
+// WithFaults returns a context whose calls all fail with the given fault kinds, as requested
+// for one review through the fault injection header. No kinds leave ctx unchanged.
+func WithFaults(ctx context.Context, kinds []string) context.Context {
+	if len(kinds) == 0 {
+		return ctx
+	}
+	return context.WithValue(ctx, faultsKey{}, kinds)
+}
+
+// ParseHeader returns the known fault kinds of a comma-separated header value
+func ParseHeader(value string) []string {
+	var kinds []string
+	for _, k := range strings.Split(value, ",") {
+		k = strings.TrimSpace(strings.ToLower(k))
+		if slices.Contains(config.FaultKinds, k) && !slices.Contains(kinds, k) {
+			kinds = append(kinds, k)
+		}
+	}
+	return kinds
+}
+
+// Injector decides which calls fail. A nil Injector injects nothing.
+type Injector struct {
+	cfg  config.FaultInjectionConfig
+	roll func() float64 // Uniform in [0, 1)
+}
+
+// New returns the injector of cfg, or nil when fault injection is disabled
+func New(cfg config.FaultInjectionConfig) *Injector {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &Injector{cfg: cfg, roll: rand.Float64}
+}
+
+// Header returns the request header naming the faults of one review, or "" when reviews
+// cannot request faults
+func (i *Injector) Header() string {
+	if i == nil {
+		return ""
+	}
+	return i.cfg.Header
+}
+
+// inject reports whether a call to target fails with fault kind, and the rule configuring it.
+// Faults requested for the review always apply; configured faults apply to their share of calls.
+func (i *Injector) inject(ctx context.Context, kind, target string) (config.FaultRule, bool) {
+	if i == nil {
+		return config.FaultRule{}, false
+	}
+	rule := config.FaultRule{Kind: kind}
+	configured := false
+	for _, r := range i.cfg.Faults {
+		if r.Kind == kind && rules.MatchAny(r.Targets, target) {
+			rule, configured = r, true
+			break
+		}
+	}
+
+	trigger := ""
+	switch {
+	case slices.Contains(faultsFromContext(ctx), kind):
+		trigger = "header"
+	case configured && i.roll() < rule.Rate:
+		trigger = "config"
+	default:
+		return rule, false
+	}
+	metrics.FaultsInjected.WithLabelValues(kind, trigger).Inc()
+	slog.Warn("fault injected", "kind", kind, "target", target, "trigger", trigger)
+	return rule, true
+}
+
+// MCP simulates an MCP tool call that hangs and then times out. It returns nil when the call
+// is not affected.
+func (i *Injector) MCP(ctx context.Context, serverName, toolName string) error {
+	rule, ok := i.inject(ctx, config.FaultMCPTimeout, toolName)
+	if !ok {
+		return nil
+	}
+	delay := rule.Delay
+	if delay <= 0 {
+		delay = defaultDelay
+	}
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return fmt.Errorf("call tool %s/%s: %w: %w", serverName, toolName, ErrInjected, ctx.Err())
+	case <-timer.C:
+		return fmt.Errorf("call tool %s/%s: %w: %w", serverName, toolName, ErrInjected, context.DeadlineExceeded)
+	}
+}
+
+// Storage returns a simulated storage failure of op (OpRead, OpWrite), or nil
+func (i *Injector) Storage(ctx context.Context, op string) error {
+	if _, ok := i.inject(ctx, config.FaultStorageError, op); !ok {
+		return nil
+	}
+	return fmt.Errorf("storage %s: %w: database is locked", op, ErrInjected)
+}
+
+func faultsFromContext(ctx context.Context) []string {
+	kinds, _ := ctx.Value(faultsKey{}).([]string)
+	return kinds
+}
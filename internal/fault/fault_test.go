@@ -0,0 +1,126 @@
+package fault
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"reflect"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/option"
+)
+
+func TestParseHeader(t *testing.T) {
+	got := ParseHeader(" LLM_Rate_Limit, bogus,mcp_timeout,llm_rate_limit")
+	if want := []string{config.FaultLLMRateLimit, config.FaultMCPTimeout}; !reflect.DeepEqual(got, want) {
+		t.Errorf("kinds = %v, want %v", got, want)
+	}
+}
+
+func TestInjector_Inject(t *testing.T) {
+	cfg := config.FaultInjectionConfig{Enabled: true, Faults: []config.FaultRule{
+		{Kind: config.FaultStorageError, Rate: 0.5, Targets: []string{OpWrite}},
+	}}
+	i := New(cfg)
+	ctx := context.Background()
+
+	tests := []struct {
+		name   string
+		ctx    context.Context
+		kind   string
+		target string
+		roll   float64
+		want   bool
+	}{
+		{name: "within rate", ctx: ctx, kind: config.FaultStorageError, target: OpWrite, roll: 0.2, want: true},
+		{name: "above rate", ctx: ctx, kind: config.FaultStorageError, target: OpWrite, roll: 0.7, want: false},
+		{name: "other target", ctx: ctx, kind: config.FaultStorageError, target: OpRead, roll: 0.2, want: false},
+		{name: "not configured", ctx: ctx, kind: config.FaultLLMRateLimit, target: "gpt-4o", roll: 0, want: false},
+		{name: "requested by review", ctx: WithFaults(ctx, []string{config.FaultLLMRateLimit}), kind: config.FaultLLMRateLimit, target: "gpt-4o", roll: 0.9, want: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			i.roll = func() float64 { return tt.roll }
+			if _, got := i.inject(tt.ctx, tt.kind, tt.target); got != tt.want {
+				t.Errorf("inject = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	if New(config.FaultInjectionConfig{Faults: cfg.Faults}) != nil {
+		t.Error("disabled fault injection must return a nil injector")
+	}
+	var disabled *Injector
+	if err := disabled.Storage(WithFaults(ctx, []string{config.FaultStorageError}), OpWrite); err != nil {
+		t.Errorf("nil injector injected %v", err)
+	}
+}
+
+func TestInjector_MCP(t *testing.T) {
+	i := New(config.FaultInjectionConfig{Enabled: true, Faults: []config.FaultRule{
+		{Kind: config.FaultMCPTimeout, Rate: 1, Targets: []string{"bitbucket_get_*"}, Delay: 10 * time.Millisecond},
+	}})
+	ctx := context.Background()
+
+	if err := i.MCP(ctx, "bitbucket", "bitbucket_add_pull_request_comment"); err != nil {
+		t.Errorf("untargeted tool failed: %v", err)
+	}
+	err := i.MCP(ctx, "bitbucket", "bitbucket_get_pull_request_diff")
+	if !errors.Is(err, ErrInjected) || !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want an injected deadline error", err)
+	}
+
+	// A cancelled call returns at once
+	i.cfg.Faults[0].Delay = time.Hour
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	if err := i.MCP(cancelled, "bitbucket", "bitbucket_get_pull_request_diff"); !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+}
+
+func TestInjector_LLMMiddleware(t *testing.T) {
+	i := New(config.FaultInjectionConfig{Enabled: true})
+	client := openai.NewClient(
+		option.WithBaseURL("http://llm.invalid/v1"),
+		option.WithAPIKey("key"),
+		option.WithMaxRetries(0),
+		option.WithMiddleware(i.LLMMiddleware("gpt-4o")),
+	)
+	params := openai.ChatCompletionNewParams{
+		Model:    "gpt-4o",
+		Messages: []openai.ChatCompletionMessageParamUnion{openai.UserMessage("review")},
+	}
+
+	ctx := WithFaults(context.Background(), []string{config.FaultLLMRateLimit})
+	_, err := client.Chat.Completions.New(ctx, params)
+	var apiErr *openai.Error
+	if !errors.As(err, &apiErr) || apiErr.StatusCode != http.StatusTooManyRequests {
+		t.Fatalf("err = %v, want a 429 API error", err)
+	}
+
+	ctx = WithFaults(context.Background(), []string{config.FaultLLMMalformedJSON})
+	resp, err := client.Chat.Completions.New(ctx, params)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := resp.Choices[0].Message.Content; got != malformedReview {
+		t.Errorf("content = %q", got)
+	}
+
+	stream := client.Chat.Completions.NewStreaming(ctx, params)
+	var acc openai.ChatCompletionAccumulator
+	for stream.Next() {
+		acc.AddChunk(stream.Current())
+	}
+	if err := stream.Err(); err != nil {
+		t.Fatal(err)
+	}
+	if got := acc.Choices[0].Message.Content; got != malformedReview {
+		t.Errorf("streamed content = %q", got)
+	}
+}
@@ -0,0 +1,99 @@
+package fault
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"pr-review-automation/internal/config"
+
+	"github.com/openai/openai-go/option"
+	"github.com/tidwall/gjson"
+)
+
+// malformedReview is the content of an injected malformed answer: review JSON cut off mid-way,
+// as a truncated completion would be
+const malformedReview = `{"summary": "Injected fault: truncated review", "comments": [{"path": "`
+
+// LLMMiddleware returns the OpenAI client middleware answering chat completions of model with
+// injected rate limits and malformed JSON. Faults come back as HTTP responses, so the client's
+// retries, fallbacks and response parsing handle them as they would real ones.
+func (i *Injector) LLMMiddleware(model string) option.Middleware {
+	return func(req *http.Request, next option.MiddlewareNext) (*http.Response, error) {
+		if i == nil || !strings.HasSuffix(req.URL.Path, "/chat/completions") {
+			return next(req)
+		}
+		ctx := req.Context()
+		if _, ok := i.inject(ctx, config.FaultLLMRateLimit, model); ok {
+			return rateLimited(req), nil
+		}
+		if _, ok := i.inject(ctx, config.FaultLLMMalformedJSON, model); ok {
+			return malformed(req, model)
+		}
+		return next(req)
+	}
+}
+
+// rateLimited is a 429 answer shaped like OpenAI's
+func rateLimited(req *http.Request) *http.Response {
+	body := `{"error": {"message": "Rate limit reached (injected fault)", "type": "requests", "code": "rate_limit_exceeded"}}`
+	resp := response(req, http.StatusTooManyRequests, "application/json", body)
+	resp.Header.Set("Retry-After", "1")
+	return resp
+}
+
+// malformed answers a chat completion, streamed or not, with truncated review JSON
+func malformed(req *http.Request, model string) (*http.Response, error) {
+	var stream bool
+	if req.Body != nil {
+		data, err := io.ReadAll(req.Body)
+		if err != nil {
+			return nil, fmt.Errorf("read request: %w", err)
+		}
+		stream = gjson.GetBytes(data, "stream").Bool()
+	}
+
+	if !stream {
+		body, err := json.Marshal(map[string]any{
+			"id": "fault-injected", "object": "chat.completion", "created": 0, "model": model,
+			"choices": []any{map[string]any{
+				"index": 0, "finish_reason": "stop",
+				"message": map[string]any{"role": "assistant", "content": malformedReview},
+			}},
+			"usage": map[string]any{"prompt_tokens": 0, "completion_tokens": 0, "total_tokens": 0},
+		})
+		if err != nil {
+			return nil, err
+		}
+		return response(req, http.StatusOK, "application/json", string(body)), nil
+	}
+
+	chunk, err := json.Marshal(map[string]any{
+		"id": "fault-injected", "object": "chat.completion.chunk", "created": 0, "model": model,
+		"choices": []any{map[string]any{
+			"index": 0, "finish_reason": "stop",
+			"delta": map[string]any{"role": "assistant", "content": malformedReview},
+		}},
+	})
+	if err != nil {
+		return nil, err
+	}
+	return response(req, http.StatusOK, "text/event-stream", "data: "+string(chunk)+"\n\ndata: [DONE]\n\n"), nil
+}
+
+func response(req *http.Request, status int, contentType, body string) *http.Response {
+	return &http.Response{
+		StatusCode:    status,
+		Status:        fmt.Sprintf("%d %s", status, http.StatusText(status)),
+		Proto:         "HTTP/1.1",
+		ProtoMajor:    1,
+		ProtoMinor:    1,
+		Header:        http.Header{"Content-Type": []string{contentType}},
+		Body:          io.NopCloser(bytes.NewBufferString(body)),
+		ContentLength: int64(len(body)),
+		Request:       req,
+	}
+}
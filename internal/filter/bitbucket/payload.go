@@ -2,6 +2,7 @@ package bitbucket
 
 import (
 	"encoding/json"
+	"slices"
 )
 
 // PayloadFilter implements filtering for Bitbucket Webhook payloads
@@ -52,6 +53,10 @@ func prune(v interface{}, depth int) {
 			simplifyRepository(val)
 		} else if isRefObject(val) {
 			simplifyRef(val)
+		} else if isCloudUserObject(val) {
+			keepOnly(val, "display_name", "account_id")
+		} else if isCloudRepositoryObject(val) {
+			keepOnly(val, "full_name")
 		}
 
 	case []interface{}:
@@ -131,3 +136,25 @@ func simplifyRef(m map[string]interface{}) {
 		m["repository"] = repo
 	}
 }
+
+// Bitbucket Cloud objects use snake_case fields
+
+func isCloudUserObject(m map[string]interface{}) bool {
+	_, hasDisplayName := m["display_name"]
+	_, hasAccountID := m["account_id"]
+	return hasDisplayName && hasAccountID
+}
+
+func isCloudRepositoryObject(m map[string]interface{}) bool {
+	_, hasFullName := m["full_name"]
+	return hasFullName && m["type"] == "repository"
+}
+
+// keepOnly removes all fields except keys
+func keepOnly(m map[string]interface{}, keys ...string) {
+	for k := range m {
+		if !slices.Contains(keys, k) {
+			delete(m, k)
+		}
+	}
+}
@@ -15,6 +15,7 @@ var pruneKeys = map[string]bool{
 	"state":               true, // Comment state (OPEN) is default/noise
 	"markup":              true, // We use 'raw' content
 	"html":                true, // We use 'raw' content
+	"rendered":            true, // Bitbucket Cloud: HTML renditions of title/description
 
 	// Repository metadata
 	"archived":      true,
@@ -0,0 +1,69 @@
+// Package gitlab filters GitLab webhook payloads
+package gitlab
+
+import (
+	"strings"
+
+	"github.com/tidwall/gjson"
+	"github.com/tidwall/sjson"
+)
+
+// keepPaths lists the merge request fields needed to build a PullRequest.
+// Everything else (labels, changes, repository mirrors, avatars) is noise for extraction.
+var keepPaths = []string{
+	"object_kind",
+	"event_type",
+	"user.username",
+	"user.name",
+	"project.path",
+	"project.path_with_namespace",
+	"project.web_url",
+	"object_attributes.iid",
+	"object_attributes.title",
+	"object_attributes.description",
+	"object_attributes.url",
+	"object_attributes.action",
+	"object_attributes.oldrev",
+	"object_attributes.source_branch",
+	"object_attributes.target_branch",
+	"object_attributes.last_commit.id",
+	"reviewers.#.username",
+}
+
+// PayloadFilter reduces GitLab merge request events to the fields in keepPaths
+type PayloadFilter struct{}
+
+// NewPayloadFilter creates a new GitLab PayloadFilter
+func NewPayloadFilter() *PayloadFilter {
+	return &PayloadFilter{}
+}
+
+// Filter filters the raw payload bytes. Invalid JSON is returned unchanged.
+func (f *PayloadFilter) Filter(payload []byte) []byte {
+	if !gjson.ValidBytes(payload) {
+		return payload
+	}
+
+	out := []byte("{}")
+	var err error
+	for _, path := range keepPaths {
+		res := gjson.GetBytes(payload, path)
+		if !res.Exists() {
+			continue
+		}
+		// Array queries (reviewers.#.username) keep one object per element
+		if array, field, ok := strings.Cut(path, ".#."); ok {
+			items := make([]map[string]any, 0, len(res.Array()))
+			for _, v := range res.Array() {
+				items = append(items, map[string]any{field: v.Value()})
+			}
+			out, err = sjson.SetBytes(out, array, items)
+		} else {
+			out, err = sjson.SetRawBytes(out, path, []byte(res.Raw))
+		}
+		if err != nil {
+			return payload
+		}
+	}
+	return out
+}
@@ -0,0 +1,46 @@
+package gitlab
+
+import (
+	"testing"
+
+	"github.com/tidwall/gjson"
+)
+
+func TestPayloadFilter(t *testing.T) {
+	input := `{
+		"object_kind": "merge_request",
+		"user": {"username": "jdoe", "name": "J Doe", "avatar_url": "https://example.com/a.png", "email": "j@example.com"},
+		"project": {"path": "api", "path_with_namespace": "acme/backend/api", "ci_config_path": "", "avatar_url": null},
+		"object_attributes": {
+			"iid": 7, "title": "Add cache", "description": "desc", "action": "update",
+			"last_commit": {"id": "abc", "message": "long message", "author": {"email": "j@example.com"}},
+			"labels": [{"title": "backend"}]
+		},
+		"reviewers": [{"username": "rev1", "avatar_url": "x"}, {"username": "rev2"}],
+		"changes": {"updated_at": {"previous": "a", "current": "b"}}
+	}`
+
+	out := NewPayloadFilter().Filter([]byte(input))
+
+	checks := map[string]string{
+		"object_attributes.iid":            "7",
+		"project.path_with_namespace":      "acme/backend/api",
+		"object_attributes.last_commit.id": "abc",
+		"user.username":                    "jdoe",
+		"reviewers.1.username":             "rev2",
+	}
+	for path, want := range checks {
+		if got := gjson.GetBytes(out, path).String(); got != want {
+			t.Errorf("%s = %q, want %q", path, got, want)
+		}
+	}
+	for _, path := range []string{"changes", "user.email", "object_attributes.labels", "object_attributes.last_commit.message", "reviewers.0.avatar_url"} {
+		if gjson.GetBytes(out, path).Exists() {
+			t.Errorf("%s should be filtered: %s", path, out)
+		}
+	}
+
+	if got := string(NewPayloadFilter().Filter([]byte("not json"))); got != "not json" {
+		t.Errorf("invalid JSON changed: %q", got)
+	}
+}
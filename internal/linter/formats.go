@@ -0,0 +1,95 @@
+package linter
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"pr-review-automation/internal/config"
+)
+
+// gccDiagnostic matches "file:line[:col]: level: message [rule]"
+var gccDiagnostic = regexp.MustCompile(`^(.+?):(\d+):(?:\d+:)?\s*(fatal error|error|warning):\s*(.*?)(?:\s+\[([\w.,-]+)\])?$`)
+
+// parse reads the issues from a linter's output in format
+func parse(format string, out []byte) ([]issue, error) {
+	switch format {
+	case config.LinterFormatGolangci:
+		return parseGolangci(out)
+	case config.LinterFormatRuff:
+		return parseRuff(out)
+	case config.LinterFormatGCC:
+		return parseGCC(out), nil
+	}
+	return nil, fmt.Errorf("unknown linter format %q", format)
+}
+
+// parseGolangci reads the JSON report of golangci-lint
+func parseGolangci(out []byte) ([]issue, error) {
+	var report struct {
+		Issues []struct {
+			FromLinter string
+			Text       string
+			Severity   string
+			Pos        struct {
+				Filename string
+				Line     int
+			}
+		}
+	}
+	if err := json.Unmarshal(out, &report); err != nil {
+		return nil, fmt.Errorf("parse golangci-lint output: %w", err)
+	}
+	issues := make([]issue, 0, len(report.Issues))
+	for _, i := range report.Issues {
+		issues = append(issues, issue{file: i.Pos.Filename, line: i.Pos.Line, rule: i.FromLinter, level: i.Severity, message: i.Text})
+	}
+	return issues, nil
+}
+
+// parseRuff reads the JSON report of ruff check
+func parseRuff(out []byte) ([]issue, error) {
+	var report []struct {
+		Code     string `json:"code"`
+		Message  string `json:"message"`
+		Filename string `json:"filename"`
+		Location struct {
+			Row int `json:"row"`
+		} `json:"location"`
+	}
+	if len(bytes.TrimSpace(out)) == 0 {
+		return nil, nil
+	}
+	if err := json.Unmarshal(out, &report); err != nil {
+		return nil, fmt.Errorf("parse ruff output: %w", err)
+	}
+	issues := make([]issue, 0, len(report))
+	for _, i := range report {
+		issues = append(issues, issue{file: i.Filename, line: i.Location.Row, rule: i.Code, message: i.Message})
+	}
+	return issues, nil
+}
+
+// parseGCC reads compiler-style diagnostics. Notes only explain the diagnostic before them and
+// are skipped, like any other line.
+func parseGCC(out []byte) []issue {
+	var issues []issue
+	scanner := bufio.NewScanner(bytes.NewReader(out))
+	for scanner.Scan() {
+		m := gccDiagnostic.FindStringSubmatch(strings.TrimSpace(scanner.Text()))
+		if m == nil {
+			continue
+		}
+		line, _ := strconv.Atoi(m[2])
+		level := m[3]
+		if level == "fatal error" {
+			level = "error"
+		}
+		issues = append(issues, issue{file: m[1], line: line, rule: m[5], level: level, message: m[4]})
+	}
+	return issues
+}
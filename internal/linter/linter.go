@@ -0,0 +1,201 @@
+// Package linter runs static analyzers (golangci-lint, ruff, clang-tidy, ...) on the changed
+// files of a pull request and turns their output into review findings (pipeline.linters).
+package linter
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"slices"
+	"sort"
+	"strings"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/rules"
+)
+
+// maxStderr bounds the linter output quoted in errors
+const maxStderr = 500
+
+// issue is one diagnostic parsed from a linter's output
+type issue struct {
+	file    string
+	line    int
+	rule    string // e.g. errcheck, F401, bugprone-use-after-move
+	level   string // Reported level (error, warning), if any
+	message string
+}
+
+// Runner runs the configured linters
+type Runner struct {
+	cfg config.LintersConfig
+}
+
+// New creates a runner for pipeline.linters
+func New(cfg config.LintersConfig) *Runner {
+	return &Runner{cfg: cfg}
+}
+
+// Matches reports whether a linter checks path
+func (r *Runner) Matches(path string) bool {
+	for _, t := range r.cfg.Tools {
+		if rules.MatchAny(t.Files, path) {
+			return true
+		}
+	}
+	return false
+}
+
+// Analyze writes files (path to content) into a temporary directory, runs each linter on the
+// files it checks and returns their findings. A linter that fails is logged and skipped.
+func (r *Runner) Analyze(ctx context.Context, files map[string]string) []domain.ReviewComment {
+	dir, err := os.MkdirTemp("", "pr-review-lint-")
+	if err != nil {
+		slog.Warn("create lint directory failed", "error", err)
+		return nil
+	}
+	defer os.RemoveAll(dir)
+	// Linters may print absolute paths through a symlinked temp directory (e.g. macOS /tmp)
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	}
+
+	var paths []string
+	for path, content := range files {
+		if !filepath.IsLocal(path) {
+			continue
+		}
+		full := filepath.Join(dir, filepath.FromSlash(path))
+		if err := os.MkdirAll(filepath.Dir(full), 0o750); err != nil {
+			continue
+		}
+		if err := os.WriteFile(full, []byte(content), 0o640); err != nil {
+			continue
+		}
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+
+	var findings []domain.ReviewComment
+	for _, t := range r.cfg.Tools {
+		var matched []string
+		for _, p := range paths {
+			if rules.MatchAny(t.Files, p) {
+				matched = append(matched, p)
+			}
+		}
+		if len(matched) == 0 {
+			continue
+		}
+		issues, err := r.run(ctx, dir, t, matched)
+		if err != nil {
+			slog.Warn("linter failed", "tool", t.Name, "files", len(matched), "error", err)
+			continue
+		}
+		findings = append(findings, r.findings(t, dir, issues, files)...)
+	}
+	return findings
+}
+
+// run runs one linter on paths, relative to dir, and parses its output
+func (r *Runner) run(ctx context.Context, dir string, t config.LinterConfig, paths []string) ([]issue, error) {
+	ctx, cancel := context.WithTimeout(ctx, r.cfg.Timeout)
+	defer cancel()
+
+	args := append(slices.Clone(t.Command[1:]), paths...)
+	cmd := exec.CommandContext(ctx, t.Command[0], args...)
+	cmd.Dir = dir
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout, cmd.Stderr = &stdout, &stderr
+	err := cmd.Run()
+	if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+		metrics.LinterRuns.WithLabelValues(t.Name, "timeout").Inc()
+		return nil, fmt.Errorf("timed out after %s", r.cfg.Timeout)
+	}
+	// Linters exit non-zero when they report issues; only a failure to start is an error here
+	var exitErr *exec.ExitError
+	if err != nil && !errors.As(err, &exitErr) {
+		metrics.LinterRuns.WithLabelValues(t.Name, "error").Inc()
+		return nil, err
+	}
+
+	issues, perr := parse(t.Format, stdout.Bytes())
+	if perr != nil || (err != nil && len(issues) == 0 && stdout.Len() == 0) {
+		metrics.LinterRuns.WithLabelValues(t.Name, "error").Inc()
+		if perr == nil {
+			perr = err
+		}
+		msg := strings.TrimSpace(stderr.String())
+		if len(msg) > maxStderr {
+			msg = msg[:maxStderr]
+		}
+		return nil, fmt.Errorf("%w: %s", perr, msg)
+	}
+	metrics.LinterRuns.WithLabelValues(t.Name, "ok").Inc()
+	return issues, nil
+}
+
+// findings converts the issues of a linter on the fetched files into review comments, highest
+// severity first and at most linters.max_findings
+func (r *Runner) findings(t config.LinterConfig, dir string, issues []issue, files map[string]string) []domain.ReviewComment {
+	var out []domain.ReviewComment
+	for _, is := range issues {
+		file := filepath.ToSlash(filepath.Clean(is.file))
+		if filepath.IsAbs(is.file) {
+			rel, err := filepath.Rel(dir, is.file)
+			if err != nil {
+				continue
+			}
+			file = filepath.ToSlash(rel)
+		}
+		if _, ok := files[file]; !ok || is.line <= 0 {
+			continue
+		}
+		text := fmt.Sprintf("**%s**: %s", t.Name, is.message)
+		if is.rule != "" {
+			text = fmt.Sprintf("**%s** (`%s`): %s", t.Name, is.rule, is.message)
+		}
+		out = append(out, domain.ReviewComment{
+			File:     file,
+			Line:     domain.FlexibleLine(is.line),
+			Severity: severity(t, is.rule, is.level),
+			Comment:  text,
+		})
+	}
+	sort.SliceStable(out, func(i, j int) bool {
+		return slices.Index(config.FindingSeverities, out[i].Severity) < slices.Index(config.FindingSeverities, out[j].Severity)
+	})
+	if len(out) > r.cfg.MaxFindings {
+		out = out[:r.cfg.MaxFindings]
+	}
+	metrics.LinterFindings.WithLabelValues(t.Name).Add(float64(len(out)))
+	return out
+}
+
+// severity maps a linter issue to a finding severity: the longest severities key the rule
+// starts with, else the key equal to the reported level, else the linter's default
+func severity(t config.LinterConfig, rule, level string) string {
+	best := ""
+	for key := range t.Severities {
+		if key != "" && strings.HasPrefix(rule, key) && len(key) > len(best) {
+			best = key
+		}
+	}
+	if best != "" {
+		return strings.ToUpper(t.Severities[best])
+	}
+	if s, ok := t.Severities[strings.ToLower(level)]; ok && level != "" {
+		return strings.ToUpper(s)
+	}
+	if t.Severity != "" {
+		return strings.ToUpper(t.Severity)
+	}
+	return domain.CommentSeverityWarning
+}
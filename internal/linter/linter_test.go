@@ -0,0 +1,87 @@
+package linter
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+)
+
+func TestParse(t *testing.T) {
+	golangci := `{"Issues": [{"FromLinter": "errcheck", "Text": "Error return value is not checked", "Severity": "", "Pos": {"Filename": "pkg/a.go", "Line": 12}}]}`
+	ruff := `[{"code": "F401", "message": "os imported but unused", "filename": "/tmp/x/app/main.py", "location": {"row": 1, "column": 8}}]`
+	gcc := "src/a.cpp:7:3: warning: use after move [bugprone-use-after-move]\nsrc/a.cpp:5:3: note: move occurred here\n1 warning generated.\n"
+
+	tests := []struct {
+		format string
+		out    string
+		want   string
+	}{
+		{config.LinterFormatGolangci, golangci, "[{pkg/a.go 12 errcheck  Error return value is not checked}]"},
+		{config.LinterFormatRuff, ruff, "[{/tmp/x/app/main.py 1 F401  os imported but unused}]"},
+		{config.LinterFormatRuff, "", "[]"},
+		{config.LinterFormatGCC, gcc, "[{src/a.cpp 7 bugprone-use-after-move warning use after move}]"},
+	}
+	for _, tt := range tests {
+		issues, err := parse(tt.format, []byte(tt.out))
+		if err != nil {
+			t.Fatalf("%s: %v", tt.format, err)
+		}
+		if got := fmt.Sprint(issues); got != tt.want {
+			t.Errorf("%s: issues = %s, want %s", tt.format, got, tt.want)
+		}
+	}
+	if _, err := parse(config.LinterFormatGolangci, []byte("panic: no go files")); err == nil {
+		t.Error("invalid JSON accepted")
+	}
+}
+
+func TestSeverity(t *testing.T) {
+	tool := config.LinterConfig{Severity: "info", Severities: map[string]string{"F": "WARNING", "F8": "critical", "error": "WARNING"}}
+	tests := []struct{ rule, level, want string }{
+		{"F821", "", "CRITICAL"}, // longest prefix wins
+		{"F401", "", "WARNING"},
+		{"E501", "error", "WARNING"},
+		{"E501", "", "INFO"},
+	}
+	for _, tt := range tests {
+		if got := severity(tool, tt.rule, tt.level); got != tt.want {
+			t.Errorf("severity(%q, %q) = %s, want %s", tt.rule, tt.level, got, tt.want)
+		}
+	}
+	if got := severity(config.LinterConfig{}, "x", ""); got != "WARNING" {
+		t.Errorf("default severity = %s", got)
+	}
+}
+
+func TestRunner_Analyze(t *testing.T) {
+	cfg := config.LintersConfig{
+		Timeout:     time.Minute,
+		MaxFindings: 10,
+		Tools: []config.LinterConfig{{
+			Name:    "clang-tidy",
+			Command: []string{"sh", "-c", `for f; do echo "$PWD/$f:2:1: warning: magic number [readability-magic-numbers]"; done; exit 1`, "lint"},
+			Files:   []string{"*.cpp"},
+			Format:  config.LinterFormatGCC,
+		}, {
+			Name:    "missing",
+			Command: []string{"no-such-linter-binary"},
+			Files:   []string{"*.cpp"},
+			Format:  config.LinterFormatGCC,
+		}},
+	}
+	r := New(cfg)
+	if !r.Matches("src/a.cpp") || r.Matches("main.go") {
+		t.Error("Matches does not follow the file globs")
+	}
+	findings := r.Analyze(context.Background(), map[string]string{"src/a.cpp": "int a;\nint b = 42;\n", "README.md": "# x"})
+	if len(findings) != 1 {
+		t.Fatalf("findings = %+v", findings)
+	}
+	f := findings[0]
+	if f.File != "src/a.cpp" || f.Line != 2 || f.Severity != "WARNING" || f.Comment != "**clang-tidy** (`readability-magic-numbers`): magic number" {
+		t.Errorf("finding = %+v", f)
+	}
+}
@@ -0,0 +1,48 @@
+package llm
+
+// Capabilities describe what a model endpoint supports. The pipeline chooses its strategies
+// (streaming, JSON output mode, vision, context budget) from them instead of from the client type.
+type Capabilities struct {
+	Tools        bool `json:"tools"`        // Function calling
+	JSONSchema   bool `json:"jsonSchema"`   // Constrained JSON output through response_format
+	StrictSchema bool `json:"strictSchema"` // Output following a given JSON Schema (response_format json_schema, strict)
+	Vision       bool `json:"vision"`       // Image input (image_url content parts)
+	Streaming    bool `json:"streaming"`    // Streamed completions
+	MaxContext   int  `json:"maxContext"`   // Context window in tokens; 0 = unknown
+}
+
+// DefaultCapabilities are assumed for OpenAI-compatible endpoints that declare nothing
+var DefaultCapabilities = Capabilities{Tools: true, JSONSchema: true, Streaming: true}
+
+// CapabilityReporter is implemented by clients that know the capabilities of their model
+type CapabilityReporter interface {
+	Capabilities() Capabilities
+}
+
+// CapabilitiesOf returns the capabilities of c. A client that does not report them gets
+// DefaultCapabilities, streaming only when it implements StreamingClient.
+func CapabilitiesOf(c Client) Capabilities {
+	if r, ok := c.(CapabilityReporter); ok {
+		return r.Capabilities()
+	}
+	caps := DefaultCapabilities
+	_, caps.Streaming = c.(StreamingClient)
+	return caps
+}
+
+// Intersect returns what both c and o support, for clients that may answer with either.
+// The context window is the smaller known one.
+func (c Capabilities) Intersect(o Capabilities) Capabilities {
+	maxContext := c.MaxContext
+	if maxContext == 0 || (o.MaxContext > 0 && o.MaxContext < maxContext) {
+		maxContext = o.MaxContext
+	}
+	return Capabilities{
+		Tools:        c.Tools && o.Tools,
+		JSONSchema:   c.JSONSchema && o.JSONSchema,
+		StrictSchema: c.StrictSchema && o.StrictSchema,
+		Vision:       c.Vision && o.Vision,
+		Streaming:    c.Streaming && o.Streaming,
+		MaxContext:   maxContext,
+	}
+}
@@ -13,3 +13,16 @@ type Client interface {
 	// SimpleTextQuery sends a simple text query.
 	SimpleTextQuery(ctx context.Context, systemPrompt, userInput string) (string, error)
 }
+
+// StreamingClient is implemented by clients that can stream a chat completion.
+// onDelta receives each content fragment as it arrives; the returned completion is the
+// accumulated result, as Chat would have returned it.
+type StreamingClient interface {
+	ChatStream(ctx context.Context, params openai.ChatCompletionNewParams, onDelta func(string)) (*openai.ChatCompletion, error)
+}
+
+// FallbackClient is implemented by clients that may answer with another model than the
+// requested one. The returned completion's Model is then the configured model that answered.
+type FallbackClient interface {
+	FallbackModels() []string
+}
@@ -0,0 +1,39 @@
+package llm
+
+import (
+	"pr-review-automation/internal/config"
+
+	"github.com/openai/openai-go"
+)
+
+// ApplyParams copies the configured parameters into a chat completion request.
+// With override false, only parameters the request leaves unset are filled, so
+// configured defaults never replace values chosen by the caller.
+func ApplyParams(params *openai.ChatCompletionNewParams, p config.LLMParams, override bool) {
+	if p.Temperature != nil && (override || !params.Temperature.Valid()) {
+		params.Temperature = openai.Float(*p.Temperature)
+	}
+	if p.TopP != nil && (override || !params.TopP.Valid()) {
+		params.TopP = openai.Float(*p.TopP)
+	}
+	if p.MaxTokens != nil && (override || !params.MaxTokens.Valid()) {
+		params.MaxTokens = openai.Int(*p.MaxTokens)
+	}
+	if p.Seed != nil && (override || !params.Seed.Valid()) {
+		params.Seed = openai.Int(*p.Seed)
+	}
+
+	if len(p.ExtraBody) == 0 {
+		return
+	}
+	extra := make(map[string]any, len(p.ExtraBody))
+	for k, v := range params.ExtraFields() {
+		extra[k] = v
+	}
+	for k, v := range p.ExtraBody {
+		if _, set := extra[k]; override || !set {
+			extra[k] = v
+		}
+	}
+	params.SetExtraFields(extra)
+}
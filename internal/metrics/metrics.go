@@ -6,24 +6,26 @@ import (
 )
 
 var (
-	// PullRequestTotal counts the total number of PRs processed, labeled by status.
+	// PullRequestTotal counts the total number of PRs processed, labeled by status and by the
+	// project and repository allowed by the metrics section ("other" for the rest).
 	PullRequestTotal = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "agent_pull_requests_total",
 		Help: "The total number of processed pull requests",
-	}, []string{"status"}) // status: success, failed
+	}, []string{"status", "project", "repo"}) // status: started, skipped, success, failed
 
 	// WebhookRequests counts incoming webhooks, labeled by status.
 	WebhookRequests = promauto.NewCounterVec(prometheus.CounterOpts{
 		Name: "agent_webhook_requests_total",
 		Help: "The total number of received webhook requests",
-	}, []string{"status"}) // status: accepted, dropped, invalid, ignored
+	}, []string{"status"}) // status: accepted, dropped, invalid, ignored, paused, rejected_full
 
-	// ProcessingDuration measures the time taken to process a PR (end-to-end).
+	// ProcessingDuration measures the time taken to review a PR (end-to-end), labeled like
+	// PullRequestTotal. Skipped PRs are not observed.
 	ProcessingDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
 		Name:    "agent_processing_duration_seconds",
 		Help:    "Time taken to process a pull request",
-		Buckets: prometheus.DefBuckets,
-	}, []string{"result"}) // result: success, error
+		Buckets: []float64{5, 15, 30, 60, 120, 300, 600, 900},
+	}, []string{"result", "project", "repo"}) // result: success, error
 
 	// MCPToolCalls counts MCP tool executions
 	MCPToolCalls = promauto.NewCounterVec(prometheus.CounterOpts{
@@ -42,4 +44,376 @@ var (
 		Name: "webhook_payload_parse_failures_total",
 		Help: "Total number of webhook payloads that failed to parse",
 	}, []string{"failure_type"}) // failure_type: gjson, llm, both
+
+	// EventsPublished counts review events handed to the event bus
+	EventsPublished = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_events_published_total",
+		Help: "The total number of review events published to the internal event bus",
+	}, []string{"status"}) // status: queued, dropped
+
+	// ChunkDuration measures the time taken to review one chunk (the whole PR when not chunked)
+	ChunkDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "agent_review_chunk_duration_seconds",
+		Help:    "Time taken to review a single chunk",
+		Buckets: prometheus.DefBuckets,
+	}, []string{"strategy", "status"}) // status: success, error
+
+	// ChunkFindings observes the number of findings per reviewed chunk; mass at 0 indicates empty reviews
+	ChunkFindings = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "agent_review_chunk_findings",
+		Help:    "Number of findings produced per reviewed chunk",
+		Buckets: []float64{0, 1, 2, 5, 10, 20, 50},
+	}, []string{"strategy"})
+
+	// ChunkTokens observes the tokens of one reviewed chunk: the planning estimate and, as
+	// reported by the provider, the tokens sent and received. Compare estimated with sent to
+	// tune max_context_tokens.
+	ChunkTokens = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "agent_review_chunk_tokens",
+		Help:    "Tokens per reviewed chunk",
+		Buckets: prometheus.ExponentialBuckets(250, 2, 11), // 250 .. 256000
+	}, []string{"strategy", "type"}) // type: estimated, sent, received
+
+	// ChunkResults counts reviewed chunks by result: the outcome of the model response, or error
+	ChunkResults = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_review_chunk_results_total",
+		Help: "The total number of reviewed chunks by result",
+	}, []string{"strategy", "result"}) // result: ok, refusal, empty, low_content, unparseable, error
+
+	// ReviewChunks observes how many chunks a chunked review was split into
+	ReviewChunks = promauto.NewHistogram(prometheus.HistogramOpts{
+		Name:    "agent_review_chunks",
+		Help:    "Number of chunks per chunked review",
+		Buckets: []float64{2, 3, 4, 6, 8, 12, 16, 24, 32},
+	})
+
+	// LLMTokens counts LLM tokens used by reviews, as reported by the provider
+	LLMTokens = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_llm_tokens_total",
+		Help: "The total number of LLM tokens used by reviews",
+	}, []string{"type"}) // type: prompt, completion
+
+	// TasksCreated counts Bitbucket tasks created for critical findings
+	TasksCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_review_tasks_total",
+		Help: "The total number of Bitbucket tasks created for critical findings",
+	}, []string{"status"}) // status: success, error, no_comment_id
+
+	// JiraIssuesCreated counts Jira issues filed for CRITICAL findings at merge time
+	JiraIssuesCreated = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_jira_issues_total",
+		Help: "The total number of Jira issues filed for critical findings on merged pull requests",
+	}, []string{"status"}) // status: success, error
+
+	// HeldPosts tracks PRs whose non-critical findings wait for working hours
+	HeldPosts = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "agent_held_posts",
+		Help: "The number of pull requests with findings held until working hours",
+	})
+
+	// ReviewSkips counts reviews skipped, by reason
+	ReviewSkips = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_review_skips_total",
+		Help: "The total number of skipped reviews",
+	}, []string{"reason"}) // reason: event_filter, size_gate, budget, dry_run, hook
+
+	// DuplicatePRs counts PRs flagged as likely duplicates or reverts of recent PRs
+	DuplicatePRs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_duplicate_prs_total",
+		Help: "The total number of PRs matching the diff of a recent PR",
+	}, []string{"kind"}) // kind: duplicate, revert
+
+	// ReviewOutcomes counts classified review responses, for the first attempt, the repair and the retry
+	ReviewOutcomes = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_review_outcomes_total",
+		Help: "The total number of review responses by outcome",
+	}, []string{"outcome", "attempt"}) // outcome: ok, refusal, empty, low_content, unparseable; attempt: first, repair, retry
+
+	// SchemaRepairs counts the follow-up requests for review answers that failed the result schema
+	SchemaRepairs = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_schema_repairs_total",
+		Help: "The total number of review answer repairs by result",
+	}, []string{"result"}) // result: repaired, partial, unparseable, error
+
+	// ReviewContracts counts parsed review responses by requested and answered contract version
+	ReviewContracts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_review_contracts_total",
+		Help: "The total number of parsed review responses by contract version",
+	}, []string{"requested", "received"}) // v1, v2
+
+	// LLMFallbacks counts requests handed to the next model of llm.fallbacks
+	LLMFallbacks = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_llm_fallbacks_total",
+		Help: "The total number of LLM requests retried with a fallback model",
+	}, []string{"model", "reason"}) // model: the failed model; reason: rate_limit, server_error, context_length
+
+	// RetrievalSnippets tracks the documentation snippets in the retrieval index
+	RetrievalSnippets = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "agent_retrieval_snippets",
+		Help: "The number of documentation snippets in the retrieval index",
+	})
+
+	// RetrievalQueries counts documentation lookups for reviews
+	RetrievalQueries = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_retrieval_queries_total",
+		Help: "The total number of documentation lookups for reviews",
+	}, []string{"result"}) // hit, miss, error
+
+	// SecretFindings counts the secrets found in added lines before the review, by rule
+	SecretFindings = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_secret_findings_total",
+		Help: "The total number of secrets found in PR diffs by the secret scan",
+	}, []string{"rule"}) // aws_access_key, private_key, ..., credential, custom
+
+	// SecretScanBlocks counts the reviews whose diff was not sent to an external model
+	// because it contains secrets
+	SecretScanBlocks = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "agent_secret_scan_blocked_reviews_total",
+		Help: "The total number of reviews not sent to an external model because of secrets in the diff",
+	})
+
+	// LinterRuns counts static analyzer runs
+	LinterRuns = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_linter_runs_total",
+		Help: "The total number of static analyzer runs on changed files",
+	}, []string{"tool", "result"}) // result: ok, error, timeout
+
+	// LinterFindings counts the static analyzer findings on the changed files
+	LinterFindings = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_linter_findings_total",
+		Help: "The total number of static analyzer findings on changed files",
+	}, []string{"tool"})
+
+	// SecurityReviews counts the security review passes
+	SecurityReviews = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_security_reviews_total",
+		Help: "The total number of security review passes",
+	}, []string{"result"}) // success, error
+
+	// SecurityFindings counts the findings of the security review pass
+	SecurityFindings = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_security_findings_total",
+		Help: "The total number of findings reported by the security review pass",
+	}, []string{"severity"})
+
+	// CoverageGaps counts the changed source files listed without changed tests
+	CoverageGaps = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_coverage_gaps_total",
+		Help: "The total number of changed source files whose tests were not changed",
+	}, []string{"language"}) // go, java, python
+
+	// GeneratedDescriptions counts descriptions generated for PRs without one
+	GeneratedDescriptions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_generated_descriptions_total",
+		Help: "The total number of PR descriptions generated from the diff",
+	}, []string{"action", "result"}) // action: comment, update; result: success, error
+
+	// PolicyViolations counts PRs violating a title or branch policy, by check
+	PolicyViolations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_policy_violations_total",
+		Help: "The total number of PR title and branch policy violations",
+	}, []string{"check"}) // title, branch, target_branch
+
+	// EnsembleFindings counts the findings of ensemble reviews by whether a majority of the
+	// runs reported them
+	EnsembleFindings = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_ensemble_findings_total",
+		Help: "The total number of ensemble review findings by vote result",
+	}, []string{"result"}) // kept, dropped
+
+	// LLMUsageTokens counts the tokens of every chat completion, as reported by the provider
+	LLMUsageTokens = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_llm_usage_tokens_total",
+		Help: "The total number of LLM tokens by model and project",
+	}, []string{"model", "project", "type"}) // project: "none" outside PRs; type: prompt, completion
+
+	// LLMCost counts the cost of chat completions of models with llm.pricing
+	LLMCost = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_llm_cost_usd_total",
+		Help: "The total LLM cost in USD by model and project",
+	}, []string{"model", "project"})
+
+	// ReviewTokens observes the tokens used by one review, summed across chunks and retries
+	ReviewTokens = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "agent_review_tokens",
+		Help:    "LLM tokens per review by model and project",
+		Buckets: prometheus.ExponentialBuckets(1000, 2, 10),
+	}, []string{"model", "project"}) // project: as on agent_pull_requests_total
+
+	// ReviewCost observes the cost of one review of a model with llm.pricing
+	ReviewCost = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "agent_review_cost_usd",
+		Help:    "LLM cost in USD per review by model and project",
+		Buckets: []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5},
+	}, []string{"model", "project"})
+
+	// BudgetExhausted counts reviews skipped because a budgets limit was reached
+	BudgetExhausted = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_budget_exhausted_total",
+		Help: "The total number of reviews skipped by an exhausted spend budget",
+	}, []string{"scope", "limit"}) // scope: global, project; limit: daily_tokens, daily_cost, monthly_tokens, monthly_cost
+
+	// ReviewsRateLimited counts reviews deferred by server.rate_limit, once per retry
+	ReviewsRateLimited = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_reviews_rate_limited_total",
+		Help: "The total number of reviews deferred by a project or repository rate limit",
+	}, []string{"scope"}) // scope: project, repo
+
+	// WorkerPoolWait observes how long jobs waited in the worker pool queue, by priority
+	WorkerPoolWait = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "agent_worker_pool_wait_seconds",
+		Help:    "Time jobs waited in the worker pool queue",
+		Buckets: []float64{0.1, 1, 5, 15, 30, 60, 120, 300, 600, 1800},
+	}, []string{"priority"}) // priority: low, normal, high, manual
+
+	// ReviewTimeouts counts reviews stopped by server.review_timeout
+	ReviewTimeouts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_review_timeouts_total",
+		Help: "The total number of reviews that ran past their processing timeout",
+	}, []string{"project", "repo"}) // project, repo: as on agent_processing_duration_seconds
+
+	// StandardsPages counts the team standards pages a review needed, by where they came from
+	StandardsPages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_standards_pages_total",
+		Help: "The total number of team standards pages used by reviews",
+	}, []string{"result"}) // cached, fetched, stale, error
+
+	// FaultsInjected counts simulated failures of fault_injection
+	FaultsInjected = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_faults_injected_total",
+		Help: "The total number of simulated dependency failures",
+	}, []string{"kind", "trigger"}) // trigger: config, header
+
+	// WorkerPoolWorkers tracks the running review workers
+	WorkerPoolWorkers = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "agent_worker_pool_workers",
+		Help: "The number of running review workers",
+	})
+
+	// WorkerPoolQueueDepth tracks the jobs waiting for a worker, sampled by the autoscaler
+	WorkerPoolQueueDepth = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "agent_worker_pool_queue_depth",
+		Help: "The number of jobs waiting for a review worker",
+	})
+
+	// WorkerPoolScaling counts worker pool autoscaling decisions
+	WorkerPoolScaling = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_worker_pool_scaling_total",
+		Help: "The total number of times the worker pool grew or shrank",
+	}, []string{"direction"}) // up, down
+
+	// CallsAbandoned counts LLM and MCP calls still running after their context was done;
+	// the caller stopped waiting for them
+	CallsAbandoned = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_calls_abandoned_total",
+		Help: "The total number of LLM and MCP calls abandoned after ignoring cancellation",
+	}, []string{"kind"}) // llm, mcp
+
+	// ReviewsCancelled counts reviews stopped before they finished
+	ReviewsCancelled = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_reviews_cancelled_total",
+		Help: "The total number of reviews cancelled while running",
+	}, []string{"reason"}) // superseded, shutdown, api
+
+	// ReviewQueueOperations counts operations on the external review queue (queue.driver)
+	ReviewQueueOperations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_review_queue_operations_total",
+		Help: "The total number of review queue operations by result",
+	}, []string{"op", "result"}) // op: push, pop, ack, recover; result: success, error, invalid
+
+	// StorageBuffered tracks writes held in memory while storage is unavailable
+	StorageBuffered = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "agent_storage_buffered_records",
+		Help: "The number of storage writes buffered until storage recovers",
+	})
+
+	// StorageOperations counts storage operations through the circuit breaker
+	StorageOperations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_storage_operations_total",
+		Help: "The total number of storage operations by result",
+	}, []string{"result"}) // result: success, error, rejected, buffered, flushed, dropped
+
+	// PostRuleActions counts findings matched by post-processing rules
+	PostRuleActions = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_post_rule_actions_total",
+		Help: "The total number of findings matched by post-processing rules",
+	}, []string{"rule", "action"}) // action: drop, downgrade, rewrite, tag, cap (severity_caps), label; rule category-<name> or confidence for post_processing
+
+	// AuthFailures counts rejected API requests
+	AuthFailures = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_api_auth_failures_total",
+		Help: "The total number of API requests rejected by authentication or authorization",
+	}, []string{"reason"}) // reason: unauthenticated, forbidden
+
+	// RetryAttempts counts retries after a failed attempt
+	RetryAttempts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_retry_attempts_total",
+		Help: "The total number of retries after a failed attempt",
+	}, []string{"operation"}) // operation: mcp_call, webhook_l2, tool_cache
+
+	// RetryCalls counts calls made through the retry package by how they ended
+	RetryCalls = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_retry_calls_total",
+		Help: "The total number of retried calls by outcome",
+	}, []string{"operation", "outcome"}) // outcome: success, exhausted, permanent, cancelled
+
+	// PollListings counts repository listings of the poller
+	PollListings = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_poll_listings_total",
+		Help: "The total number of open pull request listings by the poller",
+	}, []string{"result"}) // result: success, error
+
+	// PollPullRequests counts open pull requests found by the poller by what it did with them
+	PollPullRequests = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_poll_pull_requests_total",
+		Help: "The total number of open pull requests found by the poller",
+	}, []string{"outcome"}) // outcome: submitted, reviewed
+
+	// SlashCommands counts slash commands posted in pull request comments
+	SlashCommands = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_slash_commands_total",
+		Help: "The total number of slash commands in pull request comments by result",
+	}, []string{"command", "result"}) // command: review, explain, ignore, help, unknown; result: success, error, rejected, queued
+
+	// ConversationReplies counts developer replies to AI comments by what the reviewer did
+	ConversationReplies = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_conversation_replies_total",
+		Help: "The total number of developer replies in comment threads by outcome",
+	}, []string{"outcome"}) // outcome: answered, not_ai_thread, turn_limit, error
+
+	// FileCommentUpdates counts merged file comments edited in place instead of posted again
+	FileCommentUpdates = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_file_comment_updates_total",
+		Help: "The total number of merged file comments updated in place by result",
+	}, []string{"result"}) // result: success, error
+
+	// OutdatedComments counts the bot's comments resolved or deleted after their findings went away
+	OutdatedComments = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_outdated_comments_total",
+		Help: "The total number of outdated AI comments resolved or deleted by result",
+	}, []string{"action", "result"}) // action: resolve, delete; result: success, error
+
+	// ReviewerStatuses counts the approve / needs work verdicts set by the bot
+	ReviewerStatuses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_reviewer_statuses_total",
+		Help: "The total number of reviewer statuses set on pull requests by result",
+	}, []string{"status", "result"}) // status: APPROVED, NEEDS_WORK, UNAPPROVED; result: success, error
+
+	// BuildStatuses counts build statuses published for reviewed commits
+	BuildStatuses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_build_statuses_total",
+		Help: "The total number of build statuses published for reviewed commits by result",
+	}, []string{"state", "result"}) // state: INPROGRESS, SUCCESSFUL, FAILED; result: success, error
+
+	// RepoConfigLoads counts reads of the per-repository settings file
+	RepoConfigLoads = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_repo_config_loads_total",
+		Help: "The total number of per-repository settings file reads by result",
+	}, []string{"result"}) // result: loaded, missing, invalid
+
+	// SummarySyntheses counts the final calls that combine chunk summaries into one
+	SummarySyntheses = promauto.NewCounterVec(prometheus.CounterOpts{
+		Name: "agent_summary_syntheses_total",
+		Help: "The total number of chunked review summary syntheses by result",
+	}, []string{"result"}) // result: success, error
 )
@@ -8,11 +8,17 @@ import (
 	"pr-review-automation/internal/client"
 	"pr-review-automation/internal/config"
 	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/progress"
+	"pr-review-automation/internal/rules"
 )
 
 // PipelineAdapter adapts the Pipeline to the Reviewer interface
 type PipelineAdapter struct {
-	pipeline *Pipeline
+	pipeline     *Pipeline
+	promptLoader *PromptLoader
+	routes       []modelRoute      // Optional: per-project models (llm.routes)
+	docs         DocRetriever      // Optional: documentation snippets (pipeline.retrieval)
+	standards    StandardsProvider // Optional: team standards pages (pipeline.standards)
 }
 
 // NewPipelineAdapter creates a new adapter for the pipeline
@@ -29,51 +35,105 @@ func NewPipelineAdapter(cfg *config.Config, mcpClient *client.MCPClient, llm LLM
 	p.stage3 = NewStage3(&cfg.Pipeline, mcpClient, llm, promptLoader)
 
 	return &PipelineAdapter{
-		pipeline: p,
+		pipeline:     p,
+		promptLoader: promptLoader,
+	}
+}
+
+// SetChunkTuner enables adaptive per-repository chunk tuning in Stage 3
+func (pa *PipelineAdapter) SetChunkTuner(t *ChunkTuner) {
+	if s3, ok := pa.pipeline.stage3.(*Stage3); ok {
+		s3.SetTuner(t)
+	}
+	for _, r := range pa.routes {
+		if s3, ok := r.stage3.(*Stage3); ok {
+			s3.SetTuner(t)
+		}
 	}
 }
 
 // ReviewPR implements the Reviewer interface
 func (pa *PipelineAdapter) ReviewPR(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
-	slog.Info("Pipeline: Starting review", "pr_id", req.PR.ID)
+	stage3, model := pa.route(req.PR)
+	if o := req.PR.Overrides; o != nil && o.Model != "" {
+		model = o.Model
+	}
+	slog.Info("Pipeline: Starting review", "pr_id", req.PR.ID, "model", model)
 
 	pipelineReq := ReviewRequest{
 		PR:           *req.PR,
 		LatestCommit: req.PR.LatestCommit,
+		Cache:        req.Cache,
 	}
 
 	// 1. Stage 1: Diff Extraction
+	progress.Stage(ctx, progress.StageDiff)
 	changes, err := pa.pipeline.stage1.ExtractDiffs(ctx, pipelineReq)
 	if err != nil {
 		return nil, fmt.Errorf("stage 1 failed: %w", err)
 	}
+	changes = ignoreChanges(changes, req.PR.RepoConfig)
 	if len(changes) == 0 {
 		return &domain.ReviewResult{
 			Comments: []domain.ReviewComment{},
 			Score:    100,
 			Summary:  "No relevant changes found in this PR.",
-			Model:    pa.pipeline.cfg.LLM.Model,
+			Model:    model,
 		}, nil
 	}
 
 	// 2. Stage 2: Context Collection
 	// Note: We currently don't use context files in Stage 3 prompt yet, but it's ready to be added.
+	progress.Stage(ctx, progress.StageContext)
 	contextFiles, err := pa.pipeline.stage2.CollectContext(ctx, pipelineReq, changes)
 	if err != nil {
 		slog.Warn("stage 2 partially failed", "error", err)
 		// Proceed even if context collection fails, using empty context
 	}
+	contextFiles = append(contextFiles, pa.retrieveDocs(ctx, req.PR, changes)...)
+	if pa.standards != nil {
+		pipelineReq.Standards = pa.standards.For(ctx, req.PR.ProjectKey+"/"+req.PR.RepoSlug)
+	}
 
 	// 3. Stage 3: Direct Review
-	result, err := pa.pipeline.stage3.Review(ctx, pipelineReq, changes, contextFiles)
+	progress.Stage(ctx, progress.StageReview)
+	result, err := stage3.Review(ctx, pipelineReq, changes, contextFiles)
 	if err != nil {
 		return nil, fmt.Errorf("stage 3 failed: %w", err)
 	}
+	if pa.securityEnabled(req.PR) {
+		progress.Stage(ctx, progress.StageSecurity)
+		pa.securityReview(ctx, stage3, pipelineReq, changes, contextFiles, result)
+	}
 
-	result.Model = pa.pipeline.cfg.LLM.Model
+	// 4. Test coverage advisory
+	if tc := pa.pipeline.cfg.Pipeline.TestCoverage; tc.Enabled {
+		result.Coverage = coverageGaps(tc, changes)
+	}
+
+	// A fallback model may have answered instead of the configured one
+	if result.Model == "" {
+		result.Model = model
+	}
 	return result, nil
 }
 
+// ignoreChanges drops the files matching the ignore globs of the repository's settings file
+func ignoreChanges(changes []FileChange, rc *domain.RepoConfig) []FileChange {
+	if rc == nil || len(rc.Ignore) == 0 {
+		return changes
+	}
+	var kept []FileChange
+	for _, c := range changes {
+		if rules.MatchAny(rc.Ignore, c.Path) {
+			slog.Debug("file ignored by repository settings", "file", c.Path)
+			continue
+		}
+		kept = append(kept, c)
+	}
+	return kept
+}
+
 // Name returns the name of the reviewer
 func (pa *PipelineAdapter) Name() string {
 	return "pipeline"
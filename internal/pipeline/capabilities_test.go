@@ -0,0 +1,127 @@
+package pipeline
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/llm"
+
+	"github.com/openai/openai-go"
+)
+
+// capableLLM is a scriptedLLM that reports its capabilities
+type capableLLM struct {
+	scriptedLLM
+	caps llm.Capabilities
+}
+
+func (m *capableLLM) Capabilities() llm.Capabilities {
+	return m.caps
+}
+
+func TestStage3_JSONModeFollowsCapabilities(t *testing.T) {
+	answer := "```json\n" + `{"summary": "One issue.", "comments": [{"path": "a.go", "line": 3, "message": "unchecked error", "severity": "WARNING"}]}` + "\n```"
+	for _, jsonSchema := range []bool{true, false} {
+		cfg := validConfig(t)
+		client := &capableLLM{scriptedLLM: scriptedLLM{responses: []string{answer}}, caps: llm.Capabilities{JSONSchema: jsonSchema}}
+		s3 := NewStage3(&cfg.Pipeline, nil, client, NewPromptLoader(cfg.Prompts.Dir))
+
+		result, err := s3.Review(context.Background(), ReviewRequest{PR: domain.PullRequest{ID: "1"}}, addedLines(3), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := client.requests[0].ResponseFormat.OfJSONObject != nil; got != jsonSchema {
+			t.Errorf("json_schema %v: response_format sent = %v", jsonSchema, got)
+		}
+		if len(result.Comments) != 1 {
+			t.Errorf("json_schema %v: expected the fenced answer to parse, got %+v", jsonSchema, result)
+		}
+	}
+}
+
+// toolLLM answers every request with a call of the review tool
+type toolLLM struct {
+	capableLLM
+	arguments string
+}
+
+func (m *toolLLM) Chat(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	m.requests = append(m.requests, params)
+	resp := completion("")
+	resp.Choices[0].Message.ToolCalls = []openai.ChatCompletionMessageToolCall{{ID: "call_1", Function: openai.ChatCompletionMessageToolCallFunction{Name: reviewToolName, Arguments: m.arguments}}}
+	return resp, nil
+}
+
+func TestStage3_StructuredOutput(t *testing.T) {
+	answer := `{"version": "v2", "summary": "One issue.", "score": 80, "comments": [{"path": "a.go", "line": 3, "end_line": 3, "message": "unchecked error", "severity": "WARNING", "category": "logic", "suggestion": "", "confidence": 0.9}]}`
+	cfg := validConfig(t)
+	cfg.Pipeline.Stage3Review.Contract = config.ReviewContractV2
+	cfg.Pipeline.Stage3Review.StructuredOutput = config.StructuredOutputJSONSchema
+	req := ReviewRequest{PR: domain.PullRequest{ID: "1"}}
+
+	// Strict schemas are sent as response_format
+	strict := &capableLLM{scriptedLLM: scriptedLLM{responses: []string{answer}}, caps: llm.Capabilities{JSONSchema: true, StrictSchema: true, Tools: true}}
+	if _, err := NewStage3(&cfg.Pipeline, nil, strict, NewPromptLoader(cfg.Prompts.Dir)).Review(context.Background(), req, addedLines(3), nil); err != nil {
+		t.Fatal(err)
+	}
+	format := strict.requests[0].ResponseFormat.OfJSONSchema
+	if format == nil || !format.JSONSchema.Strict.Value || len(strict.requests[0].Tools) > 0 {
+		t.Fatalf("expected a strict json_schema response_format, got %+v", strict.requests[0].ResponseFormat)
+	}
+	comment := format.JSONSchema.Schema.(map[string]any)["properties"].(map[string]any)["comments"].(map[string]any)["items"].(map[string]any)
+	if required := comment["required"].([]string); !slices.Contains(required, "confidence") || comment["additionalProperties"] != false {
+		t.Errorf("comment schema = %v", comment)
+	}
+
+	// Models that call tools but lack strict schemas must call the review tool
+	tool := &toolLLM{capableLLM: capableLLM{caps: llm.Capabilities{JSONSchema: true, Tools: true}}, arguments: answer}
+	result, err := NewStage3(&cfg.Pipeline, nil, tool, NewPromptLoader(cfg.Prompts.Dir)).Review(context.Background(), req, addedLines(3), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sent := tool.requests[0]
+	if len(sent.Tools) != 1 || sent.ToolChoice.OfChatCompletionNamedToolChoice == nil || sent.ResponseFormat.OfJSONSchema != nil {
+		t.Errorf("expected a forced %s call, got tools=%d choice=%+v", reviewToolName, len(sent.Tools), sent.ToolChoice)
+	}
+	if len(result.Comments) != 1 || result.Score != 80 || result.Outcome != domain.OutcomeOK {
+		t.Errorf("expected the tool arguments as the review, got %+v", result)
+	}
+
+	// Others get json_object
+	plain := &capableLLM{scriptedLLM: scriptedLLM{responses: []string{answer}}, caps: llm.Capabilities{JSONSchema: true}}
+	if _, err := NewStage3(&cfg.Pipeline, nil, plain, NewPromptLoader(cfg.Prompts.Dir)).Review(context.Background(), req, addedLines(3), nil); err != nil {
+		t.Fatal(err)
+	}
+	if plain.requests[0].ResponseFormat.OfJSONObject == nil {
+		t.Errorf("expected json_object, got %+v", plain.requests[0].ResponseFormat)
+	}
+}
+
+func TestStage3_ContextLimit(t *testing.T) {
+	maxTokens := int64(200)
+	tests := []struct {
+		name       string
+		window     int
+		maxTokens  *int64
+		wantBudget int
+	}{
+		{name: "unknown window", window: 0, wantBudget: 1000},
+		{name: "larger window", window: 100000, wantBudget: 1000},
+		{name: "smaller window", window: 800, wantBudget: 700},
+		{name: "smaller window with max_tokens", window: 800, maxTokens: &maxTokens, wantBudget: 600},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig(t)
+			cfg.Pipeline.Stage3Review.MaxContextTokens = 1000
+			cfg.Pipeline.Stage3Review.Params.MaxTokens = tt.maxTokens
+			s3 := NewStage3(&cfg.Pipeline, nil, &capableLLM{caps: llm.Capabilities{MaxContext: tt.window}}, NewPromptLoader(cfg.Prompts.Dir))
+			if got := s3.contextLimit(); got != tt.wantBudget {
+				t.Errorf("contextLimit() = %d, want %d", got, tt.wantBudget)
+			}
+		})
+	}
+}
@@ -6,21 +6,41 @@ import (
 	"log/slog"
 	"sort"
 
+	"pr-review-automation/internal/aggregator"
 	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/progress"
+	"pr-review-automation/internal/tokenizer"
 )
 
 // ReviewFunc is the function signature for the core review logic
 type ReviewFunc func(ctx context.Context, req ReviewRequest, changes []FileChange, contextFiles []FileContent) (*domain.ReviewResult, error)
 
+// ChunkSummary is the outcome of one chunk handed to a SummaryReducer
+type ChunkSummary struct {
+	Index   int
+	Files   []string
+	Score   int
+	Summary string
+	Error   string // Set when the chunk failed
+}
+
+// SummaryReducer writes one PR-level summary and score from the chunk summaries and the
+// aggregated findings. The returned result carries only Summary, Score and Usage.
+type SummaryReducer func(ctx context.Context, req ReviewRequest, chunks []ChunkSummary, findings []domain.ReviewComment) (*domain.ReviewResult, error)
+
 // ChunkReviewer handles the logic for splitting a large review into smaller chunks by file
 type ChunkReviewer struct {
 	maxTokens int
+	tokens    tokenizer.Tokenizer
+	reduce    SummaryReducer // Optional: replaces the concatenated chunk summaries
 }
 
 // NewChunkReviewer creates a new ChunkReviewer
 func NewChunkReviewer(maxTokens int) *ChunkReviewer {
 	return &ChunkReviewer{
 		maxTokens: maxTokens,
+		tokens:    tokenizer.Default(),
 	}
 }
 
@@ -65,7 +85,7 @@ func (cr *ChunkReviewer) ReviewChunked(
 	}
 
 	// Calculate tokens for each group
-	baseTokens := EstimateTokens(baseSystemPrompt)
+	baseTokens := cr.tokens.Count(baseSystemPrompt)
 	availableTokens := cr.maxTokens - baseTokens
 	// Safety buffer
 	availableTokens = int(float64(availableTokens) * 0.9)
@@ -77,9 +97,9 @@ func (cr *ChunkReviewer) ReviewChunked(
 	for _, g := range groups {
 		diffTokens := 0
 		for _, line := range g.Diff.HunkLines {
-			diffTokens += EstimateTokens(line)
+			diffTokens += cr.tokens.Count(line)
 		}
-		g.Tokens = diffTokens + EstimateTokens(g.Context.Content)
+		g.Tokens = diffTokens + cr.tokens.Count(g.Context.Content)
 	}
 
 	// 2. Create Chunks
@@ -126,12 +146,19 @@ func (cr *ChunkReviewer) ReviewChunked(
 	}
 
 	slog.Info("L2 Chunking Plan", "total_files", len(groups), "chunks", len(chunks))
+	metrics.ReviewChunks.Observe(float64(len(chunks)))
 
 	// 3. Process Chunks
 	var aggregatedResult domain.ReviewResult
 	aggregatedResult.Summary = "## Chunked Review Summary\n\n"
+	report := &domain.ExecutionReport{Strategy: domain.StrategyChunked}
+	var usage domain.TokenUsage
+	var summaries []ChunkSummary
 
 	for i, chunk := range chunks {
+		if err := ctx.Err(); err != nil {
+			return nil, fmt.Errorf("chunked review stopped before chunk %d: %w", i+1, err)
+		}
 		slog.Info("Processing Chunk", "index", i+1, "total", len(chunks), "files", len(chunk))
 
 		// Convert back to changes and context
@@ -146,22 +173,68 @@ func (cr *ChunkReviewer) ReviewChunked(
 			}
 		}
 
-		res, err := reviewFunc(ctx, req, chunkChanges, chunkContext)
+		res, chunkReport, err := reviewChunk(ctx, domain.StrategyChunked, i+1, req, chunkChanges, chunkContext, reviewFunc)
+		report.Chunks = append(report.Chunks, chunkReport)
 		if err != nil {
 			slog.Error("Failed to review chunk", "index", i+1, "error", err)
 			aggregatedResult.Summary += fmt.Sprintf("- **Chunk %d Failed**: %v\n", i+1, err)
+			summaries = append(summaries, ChunkSummary{Index: i + 1, Files: chunkReport.Files, Error: err.Error()})
+			progress.Emit(ctx, progress.Event{Type: progress.EventChunk, Chunk: i + 1, Chunks: len(chunks), Files: chunkReport.Files, Error: err.Error()})
 			continue
 		}
+		summaries = append(summaries, ChunkSummary{Index: i + 1, Files: chunkReport.Files, Score: res.Score, Summary: res.Summary})
+		progress.Emit(ctx, progress.Event{Type: progress.EventChunk, Chunk: i + 1, Chunks: len(chunks), Files: chunkReport.Files, Findings: len(res.Comments)})
+		progress.Emit(ctx, progress.Event{Type: progress.EventSummary, Chunk: i + 1, Chunks: len(chunks), Summary: res.Summary})
 
 		// Merge Results
 		aggregatedResult.Comments = append(aggregatedResult.Comments, res.Comments...)
 		aggregatedResult.Score += res.Score // We need to average this later
 		aggregatedResult.Summary += fmt.Sprintf("### Chunk %d\n%s\n\n", i+1, res.Summary)
+		usage.Add(res.Usage)
 	}
 
 	if len(chunks) > 0 {
 		aggregatedResult.Score /= len(chunks)
 	}
 
+	// Findings in context shared by two chunks come back from both
+	if n := len(aggregatedResult.Comments); n > 0 {
+		aggregatedResult.Comments = aggregator.Deduplicate(aggregatedResult.Comments)
+		if dropped := n - len(aggregatedResult.Comments); dropped > 0 {
+			slog.Info("duplicate chunk findings dropped", "dropped", dropped)
+		}
+	}
+
+	// A synthesis over all chunks replaces the concatenated summaries and the averaged score
+	if cr.reduce != nil && len(summaries) > 1 && anySucceeded(summaries) {
+		if synth, err := cr.reduce(ctx, req, summaries, aggregatedResult.Comments); err != nil {
+			slog.Warn("summary synthesis failed, keeping chunk summaries", "error", err)
+		} else {
+			aggregatedResult.Summary = synth.Summary
+			aggregatedResult.Score = synth.Score
+			usage.Add(synth.Usage)
+		}
+	}
+
+	aggregatedResult.Usage = &usage
+	aggregatedResult.Outcome = aggregateOutcome(report.Chunks)
+	aggregatedResult.Model = chunkModels(report.Chunks)
+	for _, c := range report.Chunks {
+		aggregatedResult.Retried = aggregatedResult.Retried || c.Retried
+		aggregatedResult.Repaired = aggregatedResult.Repaired || c.Repaired
+	}
+	report.Attribute(aggregatedResult.Comments)
+	aggregatedResult.Report = report
+
 	return &aggregatedResult, nil
 }
+
+// anySucceeded reports whether any chunk produced a review
+func anySucceeded(summaries []ChunkSummary) bool {
+	for _, s := range summaries {
+		if s.Error == "" {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,82 @@
+package pipeline
+
+import (
+	"strings"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+
+	"github.com/tidwall/gjson"
+)
+
+// resultFormats are the JSON answers of the review contract versions. Each advertises its
+// version, so responses can be told apart when prompts of both versions are in use.
+var resultFormats = map[string]string{
+	config.ReviewContractV1: `{
+  "version": "v1",
+  "comments": [
+    {
+      "path": "path/to/file.go",
+      "line": 42,
+      "message": "Comment text...",
+      "severity": "INFO|WARNING|CRITICAL|NIT",
+      "category": "security|performance|style|logic"
+    }
+  ],
+  "score": 85,
+  "summary": "Overall review summary..."
+}`,
+	config.ReviewContractV2: `{
+  "version": "v2",
+  "comments": [
+    {
+      "path": "path/to/file.go",
+      "line": 42,
+      "end_line": 45,
+      "message": "Comment text...",
+      "severity": "INFO|WARNING|CRITICAL|NIT",
+      "category": "security|performance|style|logic",
+      "suggestion": "Replacement code for lines 42-45, or empty",
+      "confidence": 0.8
+    }
+  ],
+  "score": 85,
+  "summary": "Overall review summary..."
+}
+
+"category" is what the finding is about: security, performance, style (formatting, naming, readability) or logic (correctness).
+"end_line" is the last line of the commented range (equal to "line" for a single line).
+"suggestion" replaces the lines from "line" to "end_line" exactly; leave it empty unless the fix is certain.
+"confidence" is a number from 0 to 1 saying how sure you are that the finding is real.`,
+}
+
+// getResultFormat returns the JSON answer the prompt asks for
+func (s *Stage3) getResultFormat() string {
+	return resultFormats[s.requestedContract()]
+}
+
+// requestedContract returns the configured contract version, v1 when unset or unknown
+func (s *Stage3) requestedContract() string {
+	if _, ok := resultFormats[s.contract]; ok {
+		return s.contract
+	}
+	return config.ReviewContractV1
+}
+
+// responseContract returns the contract version a parsed response followed: the version it
+// declares, or v2 when it carries v2 fields without declaring one
+func responseContract(jsonStr string, result *domain.ReviewResult) string {
+	version := strings.ToLower(strings.TrimSpace(gjson.Get(jsonStr, "version").String()))
+	switch strings.TrimPrefix(version, "v") {
+	case "1", "1.0":
+		return config.ReviewContractV1
+	case "2", "2.0":
+		return config.ReviewContractV2
+	}
+	for _, c := range result.Comments {
+		if c.EndLine > 0 || c.Suggestion != "" || c.Confidence > 0 {
+			return config.ReviewContractV2
+		}
+	}
+	return config.ReviewContractV1
+}
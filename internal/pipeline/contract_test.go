@@ -0,0 +1,96 @@
+package pipeline
+
+import (
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestStage3_ResultFormatPerContract(t *testing.T) {
+	cfg := validConfig(t)
+	s3 := NewStage3(&cfg.Pipeline, nil, nil, NewPromptLoader(cfg.Prompts.Dir))
+
+	if f := s3.getResultFormat(); !strings.Contains(f, `"version": "v1"`) || strings.Contains(f, "suggestion") {
+		t.Errorf("unset contract must ask for v1:\n%s", f)
+	}
+	s3.contract = config.ReviewContractV2
+	if f := s3.getResultFormat(); !strings.Contains(f, `"version": "v2"`) || !strings.Contains(f, `"end_line"`) {
+		t.Errorf("v2 format:\n%s", f)
+	}
+}
+
+func TestStage3_ParseResultContracts(t *testing.T) {
+	tests := []struct {
+		name         string
+		requested    string
+		response     string
+		wantContract string
+		want         domain.ReviewComment
+	}{
+		{
+			name:         "v1 answer",
+			requested:    config.ReviewContractV1,
+			response:     `{"comments": [{"path": "a.go", "line": 3, "message": "unchecked error"}], "summary": "s"}`,
+			wantContract: config.ReviewContractV1,
+			want:         domain.ReviewComment{File: "a.go", Line: 3, Comment: "unchecked error"},
+		},
+		{
+			name:      "v2 answer",
+			requested: config.ReviewContractV2,
+			response: `{"version": "v2", "comments": [{"path": "a.go", "line": 3, "end_line": 5, "message": "m",
+				"suggestion": "if err != nil {\n\treturn err\n}", "confidence": 0.8}], "summary": "s"}`,
+			wantContract: config.ReviewContractV2,
+			want:         domain.ReviewComment{File: "a.go", Line: 3, EndLine: 5, Comment: "m", Suggestion: "if err != nil {\n\treturn err\n}", Confidence: 0.8},
+		},
+		{
+			name:         "v1 answer to a v2 prompt",
+			requested:    config.ReviewContractV2,
+			response:     `{"version": "1", "comments": [{"path": "a.go", "line": 3, "message": "m"}], "summary": "s"}`,
+			wantContract: config.ReviewContractV1,
+			want:         domain.ReviewComment{File: "a.go", Line: 3, Comment: "m"},
+		},
+		{
+			name:         "undeclared v2 fields",
+			requested:    config.ReviewContractV1,
+			response:     `{"comments": [{"path": "a.go", "line": [3, 7], "message": "m", "confidence": "85%"}], "summary": "s"}`,
+			wantContract: config.ReviewContractV2,
+			want:         domain.ReviewComment{File: "a.go", Line: 3, EndLine: 7, Comment: "m", Confidence: 0.85},
+		},
+		{
+			name:         "confidence words",
+			requested:    config.ReviewContractV2,
+			response:     `{"version": "v2", "comments": [{"path": "a.go", "line": 3, "message": "m", "confidence": "High"}], "summary": "s"}`,
+			wantContract: config.ReviewContractV2,
+			want:         domain.ReviewComment{File: "a.go", Line: 3, Comment: "m", Confidence: 0.9},
+		},
+		{
+			name:         "category alias",
+			requested:    config.ReviewContractV1,
+			response:     `{"comments": [{"path": "a.go", "line": 3, "message": "m", "category": " Perf"}], "summary": "s"}`,
+			wantContract: config.ReviewContractV1,
+			want:         domain.ReviewComment{File: "a.go", Line: 3, Comment: "m", Category: domain.CommentCategoryPerformance},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig(t)
+			cfg.Pipeline.Stage3Review.Contract = tt.requested
+			s3 := NewStage3(&cfg.Pipeline, nil, nil, NewPromptLoader(cfg.Prompts.Dir))
+
+			result, parsed := s3.parseResult(completion(tt.response), nil)
+			if !parsed || len(result.Comments) != 1 {
+				t.Fatalf("parsed = %v, result = %+v", parsed, result)
+			}
+			if result.Contract != tt.wantContract {
+				t.Errorf("contract = %q, want %q", result.Contract, tt.wantContract)
+			}
+			got := result.Comments[0]
+			got.Severity = ""
+			if got != tt.want {
+				t.Errorf("comment = %+v, want %+v", got, tt.want)
+			}
+		})
+	}
+}
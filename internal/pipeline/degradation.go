@@ -8,6 +8,7 @@ import (
 
 	"pr-review-automation/internal/config"
 	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/tokenizer"
 )
 
 // DegradationManager handles token limit degradation strategies
@@ -15,6 +16,7 @@ type DegradationManager struct {
 	cfg           config.DegradationConfig
 	maxTokens     int
 	chunkReviewer *ChunkReviewer
+	tokens        tokenizer.Tokenizer
 }
 
 // NewDegradationManager creates a new DegradationManager
@@ -23,12 +25,13 @@ func NewDegradationManager(cfg config.DegradationConfig, maxTokens int, chunkRev
 		cfg:           cfg,
 		maxTokens:     maxTokens,
 		chunkReviewer: chunkReviewer,
+		tokens:        tokenizer.Default(),
 	}
 }
 
-// EstimateTokens provides a rough estimate of token count (char count / 3.5)
+// EstimateTokens counts the tokens of text with the tokenizer of llm.model
 func EstimateTokens(text string) int {
-	return int(float64(len(text)) / 3.5)
+	return tokenizer.Count(text)
 }
 
 // ApplyStrategy determines and applies the appropriate degradation strategy
@@ -47,16 +50,16 @@ func (dm *DegradationManager) ApplyStrategy(
 	// Note: precise accounting is hard without actually building the full prompt,
 	// so we use a safe heuristic on the components.
 
-	baseTokens := EstimateTokens(baseSystemPrompt)
+	baseTokens := dm.tokens.Count(baseSystemPrompt)
 	diffTokens := 0
 	for _, c := range changes {
 		for _, line := range c.HunkLines {
-			diffTokens += EstimateTokens(line)
+			diffTokens += dm.tokens.Count(line)
 		}
 	}
 	contextTokens := 0
 	for _, c := range contextFiles {
-		contextTokens += EstimateTokens(c.Content)
+		contextTokens += dm.tokens.Count(c.Content)
 	}
 
 	totalTokens := baseTokens + diffTokens + contextTokens
@@ -73,7 +76,7 @@ func (dm *DegradationManager) ApplyStrategy(
 
 	// Case 0: Within safe limits
 	if totalTokens <= threshold80 {
-		result, err := reviewFunc(ctx, req, changes, contextFiles)
+		result, err := reviewSingle(ctx, domain.StrategyFull, req, changes, contextFiles, reviewFunc)
 		if err == nil {
 			return result, nil
 		}
@@ -98,13 +101,13 @@ func (dm *DegradationManager) ApplyStrategy(
 		// Re-estimate
 		newContextTokens := 0
 		for _, c := range reducedContext {
-			newContextTokens += EstimateTokens(c.Content)
+			newContextTokens += dm.tokens.Count(c.Content)
 		}
 		newTotal := baseTokens + diffTokens + newContextTokens
 
 		if newTotal <= threshold100 {
 			slog.Info("L1 degradation successful", "new_total", newTotal)
-			return reviewFunc(ctx, req, changes, reducedContext)
+			return reviewSingle(ctx, domain.StrategyContextTrimmed, req, changes, reducedContext, reviewFunc)
 		}
 		slog.Warn("L1 degradation insufficient", "new_total", newTotal)
 	}
@@ -119,7 +122,7 @@ func (dm *DegradationManager) ApplyStrategy(
 	if dm.cfg.L3DiffOnly {
 		slog.Warn("Token limit critical, applying L3 degradation (Diff Only)")
 		// Drop all context files
-		return reviewFunc(ctx, req, changes, []FileContent{})
+		return reviewSingle(ctx, domain.StrategyDiffOnly, req, changes, []FileContent{}, reviewFunc)
 	}
 
 	// Fallback/Fail
@@ -156,8 +159,9 @@ func (dm *DegradationManager) applyL1Truncation(contextFiles []FileContent) []Fi
 			truncated := strings.Join(lines[:limit], "\n")
 			truncated += fmt.Sprintf("\n... (truncated %d lines) ...", len(lines)-limit)
 			reduced = append(reduced, FileContent{
-				Path:    cf.Path,
-				Content: truncated,
+				Path:      cf.Path,
+				Content:   truncated,
+				Relevance: cf.Relevance,
 			})
 		} else {
 			reduced = append(reduced, cf)
@@ -0,0 +1,99 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/retrieval"
+)
+
+// RelevanceDoc marks context that is a documentation snippet rather than source code
+const RelevanceDoc = "doc"
+
+// RelevanceRelated marks retrieved code from elsewhere in the repository, for reference
+const RelevanceRelated = "related"
+
+// maxDocQueryLen bounds the text embedded to look up documentation for a review
+const maxDocQueryLen = 2000
+
+// DocRetriever finds the documentation and code snippets relevant to a review (pipeline.retrieval)
+type DocRetriever interface {
+	Search(ctx context.Context, repo, query string) ([]retrieval.Match, error)
+}
+
+// StandardsProvider returns the team standards text of a repository ("PROJECT/repo"), or ""
+type StandardsProvider interface {
+	For(ctx context.Context, repo string) string
+}
+
+// SetStandards adds the team standards of each PR's repository to the review prompt
+func (pa *PipelineAdapter) SetStandards(s StandardsProvider) {
+	pa.standards = s
+}
+
+// SetDocRetriever adds the documentation snippets most relevant to each PR to the review context
+func (pa *PipelineAdapter) SetDocRetriever(r DocRetriever) {
+	pa.docs = r
+}
+
+// retrieveDocs returns the relevant documentation and code snippets as context files. Code
+// of the changed files is left out, the review has it already. A failed lookup only costs the
+// snippets, the review goes on without them.
+func (pa *PipelineAdapter) retrieveDocs(ctx context.Context, pr *domain.PullRequest, changes []FileChange) []FileContent {
+	if pa.docs == nil {
+		return nil
+	}
+	matches, err := pa.docs.Search(ctx, pr.ProjectKey+"/"+pr.RepoSlug, docQuery(pr, changes))
+	if err != nil {
+		slog.Warn("documentation lookup failed", "pr_id", pr.ID, "error", err)
+		return nil
+	}
+
+	changed := make(map[string]bool, len(changes))
+	for _, c := range changes {
+		changed[c.Path] = true
+	}
+	files := make([]FileContent, 0, len(matches))
+	for i, m := range matches {
+		relevance := RelevanceDoc
+		if m.Kind == retrieval.KindCode {
+			if changed[m.Path] {
+				continue
+			}
+			relevance = RelevanceRelated
+		}
+		files = append(files, FileContent{
+			// Unique per snippet, so chunked reviews keep every snippet
+			Path:      fmt.Sprintf("%s: %s (%d)", m.Source, m.Path, i+1),
+			Content:   m.Text,
+			Relevance: relevance,
+		})
+	}
+	if len(files) > 0 {
+		slog.Info("retrieved snippets added", "pr_id", pr.ID, "snippets", len(files))
+	}
+	return files
+}
+
+// docQuery is the text documentation is looked up with: the PR title, description and changed paths
+func docQuery(pr *domain.PullRequest, changes []FileChange) string {
+	var b strings.Builder
+	b.WriteString(pr.Title)
+	if pr.Description != "" {
+		b.WriteString("\n\n")
+		b.WriteString(pr.Description)
+	}
+	b.WriteString("\n")
+	for _, c := range changes {
+		b.WriteString("\n")
+		b.WriteString(c.Path)
+	}
+	q := b.String()
+	if len(q) > maxDocQueryLen {
+		q = strings.ToValidUTF8(q[:maxDocQueryLen], "")
+	}
+	return q
+}
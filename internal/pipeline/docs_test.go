@@ -0,0 +1,109 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/retrieval"
+)
+
+// fakeDocs records documentation lookups and returns fixed matches
+type fakeDocs struct {
+	matches []retrieval.Match
+	err     error
+	repo    string
+	query   string
+}
+
+func (f *fakeDocs) Search(ctx context.Context, repo, query string) ([]retrieval.Match, error) {
+	f.repo, f.query = repo, query
+	return f.matches, f.err
+}
+
+func TestPipelineAdapter_AddsDocumentationSnippets(t *testing.T) {
+	answer := `{"summary": "Checked the retry settings against the client guidelines.", "comments": []}`
+	cfg := validConfig(t)
+	llm := &scriptedLLM{responses: []string{answer, answer}}
+	pa := NewPipelineAdapter(cfg, nil, llm, NewPromptLoader(cfg.Prompts.Dir))
+	diff := staticDiff{changes: []FileChange{{Path: "client/retry.go", HunkLines: []string{"+maxRetries := 10"}}}}
+	pa.pipeline.stage1, pa.pipeline.stage2 = diff, diff
+
+	docs := &fakeDocs{matches: []retrieval.Match{{
+		Snippet: retrieval.Snippet{Source: "guidelines", Path: "http-clients.md", Text: "Clients retry at most 3 times."},
+		Score:   0.8,
+	}}}
+	pa.SetDocRetriever(docs)
+
+	pr := domain.PullRequest{ID: "1", ProjectKey: "PAY", RepoSlug: "api", Title: "Raise retry limit"}
+	if _, err := pa.ReviewPR(context.Background(), &domain.ReviewRequest{PR: &pr}); err != nil {
+		t.Fatal(err)
+	}
+	if docs.repo != "PAY/api" || !strings.Contains(docs.query, "Raise retry limit") || !strings.Contains(docs.query, "client/retry.go") {
+		t.Errorf("lookup repo = %q, query = %q", docs.repo, docs.query)
+	}
+	system := llm.requests[0].Messages[0].OfSystem.Content.OfString.Value
+	if !strings.Contains(system, "### Documentation: guidelines: http-clients.md (1)") || !strings.Contains(system, "Clients retry at most 3 times.") {
+		t.Errorf("snippet missing from prompt:\n%s", system)
+	}
+
+	// A failed lookup does not fail the review
+	docs.err = errors.New("embedding server down")
+	if _, err := pa.ReviewPR(context.Background(), &domain.ReviewRequest{PR: &pr}); err != nil {
+		t.Fatal(err)
+	}
+	if system := llm.requests[1].Messages[0].OfSystem.Content.OfString.Value; strings.Contains(system, "### Documentation") {
+		t.Errorf("snippets added despite failed lookup")
+	}
+}
+
+func TestPipelineAdapter_AddsRelatedCode(t *testing.T) {
+	answer := `{"summary": "Checked the retry against the shared backoff.", "comments": []}`
+	cfg := validConfig(t)
+	llm := &scriptedLLM{responses: []string{answer}}
+	pa := NewPipelineAdapter(cfg, nil, llm, NewPromptLoader(cfg.Prompts.Dir))
+	diff := staticDiff{changes: []FileChange{{Path: "client/retry.go", HunkLines: []string{"+maxRetries := 10"}}}}
+	pa.pipeline.stage1, pa.pipeline.stage2 = diff, diff
+	pa.SetDocRetriever(&fakeDocs{matches: []retrieval.Match{
+		{Snippet: retrieval.Snippet{Source: "api", Path: "client/backoff.go", Kind: retrieval.KindCode, Text: "func Backoff() {}"}},
+		{Snippet: retrieval.Snippet{Source: "api", Path: "client/retry.go", Kind: retrieval.KindCode, Text: "old retry code"}},
+	}})
+
+	pr := domain.PullRequest{ID: "1", ProjectKey: "PAY", RepoSlug: "api", Title: "Raise retry limit"}
+	if _, err := pa.ReviewPR(context.Background(), &domain.ReviewRequest{PR: &pr}); err != nil {
+		t.Fatal(err)
+	}
+	system := llm.requests[0].Messages[0].OfSystem.Content.OfString.Value
+	if !strings.Contains(system, "### Related code: api: client/backoff.go (1)") {
+		t.Errorf("related code missing from prompt:\n%s", system)
+	}
+	if strings.Contains(system, "old retry code") {
+		t.Error("code of a changed file must not be added")
+	}
+}
+
+// fixedStandards returns the same standards text for every repository
+type fixedStandards string
+
+func (f fixedStandards) For(ctx context.Context, repo string) string { return string(f) }
+
+func TestPipelineAdapter_AddsTeamStandards(t *testing.T) {
+	answer := `{"summary": "Checked error handling against the team standards.", "comments": []}`
+	cfg := validConfig(t)
+	llm := &scriptedLLM{responses: []string{answer}}
+	pa := NewPipelineAdapter(cfg, nil, llm, NewPromptLoader(cfg.Prompts.Dir))
+	diff := staticDiff{changes: []FileChange{{Path: "client/retry.go", HunkLines: []string{"+return err"}}}}
+	pa.pipeline.stage1, pa.pipeline.stage2 = diff, diff
+	pa.SetStandards(fixedStandards("### Go Style\n\nWrap errors with context."))
+
+	pr := domain.PullRequest{ID: "1", ProjectKey: "PAY", RepoSlug: "api", Title: "Return errors"}
+	if _, err := pa.ReviewPR(context.Background(), &domain.ReviewRequest{PR: &pr}); err != nil {
+		t.Fatal(err)
+	}
+	system := llm.requests[0].Messages[0].OfSystem.Content.OfString.Value
+	if !strings.Contains(system, "## Team Standards") || !strings.Contains(system, "Wrap errors with context.") {
+		t.Errorf("standards missing from prompt:\n%s", system)
+	}
+}
@@ -0,0 +1,166 @@
+package pipeline
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"sync"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+
+	"github.com/openai/openai-go"
+)
+
+// maxEnsembleRuns bounds pipeline.stage3_review.ensemble.runs
+const maxEnsembleRuns = 9
+
+// findingVote is one finding as reported by the runs of an ensemble review
+type findingVote struct {
+	reports []domain.ReviewComment
+	runs    []int // Index of the run of each report
+}
+
+// ensemble sends the review request pipeline.stage3_review.ensemble.runs times at the ensemble
+// temperature and keeps the findings a majority of the answered runs report. Runs that fail,
+// refuse or do not parse do not vote. The summary merges those of the runs (see mergeSummaries),
+// the outcome is that of the run agreeing most with the kept findings and the score is the
+// average of the answered runs.
+func (s *Stage3) ensemble(ctx context.Context, params openai.ChatCompletionNewParams, changes []FileChange, streamDebug *streamLog) (*domain.ReviewResult, error) {
+	cfg := s.cfg.Stage3Review.Ensemble
+	params.Temperature = openai.Float(cfg.Temperature)
+
+	results := make([]*domain.ReviewResult, cfg.Runs)
+	errs := make([]error, cfg.Runs)
+	var wg sync.WaitGroup
+	for i := range cfg.Runs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i], errs[i] = s.answer(ctx, params, changes, streamDebug)
+		}()
+		if streamDebug != nil {
+			wg.Wait() // Keep the calls apart in the debug artifact
+		}
+	}
+	wg.Wait()
+
+	var usage domain.TokenUsage
+	var answered []*domain.ReviewResult
+	var fallback *domain.ReviewResult
+	for i, r := range results {
+		if errs[i] != nil {
+			slog.Warn("ensemble run failed", "run", i+1, "error", errs[i])
+			continue
+		}
+		usage.Add(r.Usage)
+		if r.Outcome == domain.OutcomeOK || r.Outcome == domain.OutcomeLowContent {
+			answered = append(answered, r)
+		} else if fallback == nil {
+			fallback = r
+		}
+	}
+	if len(answered) == 0 {
+		if fallback == nil {
+			return nil, errs[0]
+		}
+		fallback.Usage = &usage
+		return fallback, nil
+	}
+
+	votes := voteFindings(answered, cfg.LineTolerance)
+	needed := len(answered)/2 + 1
+	agreement := make([]int, len(answered))
+	var kept []domain.ReviewComment
+	score := 0
+	for _, r := range answered {
+		score += r.Score
+	}
+	for _, v := range votes {
+		if len(v.runs) < needed {
+			metrics.EnsembleFindings.WithLabelValues("dropped").Inc()
+			continue
+		}
+		metrics.EnsembleFindings.WithLabelValues("kept").Inc()
+		kept = append(kept, v.consensus())
+		for _, run := range v.runs {
+			agreement[run]++
+		}
+	}
+
+	result := *answered[slices.Index(agreement, slices.Max(agreement))]
+	result.Summary = mergeSummaries(answered, agreement)
+	result.Comments = kept
+	result.Score = score / len(answered)
+	result.Usage = &usage
+	slog.Info("ensemble review voted", "runs", cfg.Runs, "answered", len(answered), "findings", len(votes), "kept", len(kept))
+	return &result, nil
+}
+
+// mergeSummaries joins the distinct summaries of the runs that report a kept finding, or of all
+// runs when none was kept, the runs agreeing most with the kept findings first
+func mergeSummaries(runs []*domain.ReviewResult, agreement []int) string {
+	order := make([]int, 0, len(runs))
+	for i := range runs {
+		if agreement[i] > 0 || slices.Max(agreement) == 0 {
+			order = append(order, i)
+		}
+	}
+	slices.SortStableFunc(order, func(a, b int) int { return agreement[b] - agreement[a] })
+
+	var summaries []string
+	for _, i := range order {
+		if s := strings.TrimSpace(runs[i].Summary); s != "" && !slices.Contains(summaries, s) {
+			summaries = append(summaries, s)
+		}
+	}
+	return strings.Join(summaries, "\n\n")
+}
+
+// voteFindings groups the findings of the runs: reports in the same file at most tolerance
+// lines apart are one finding, reported at most once per run
+func voteFindings(runs []*domain.ReviewResult, tolerance int) []*findingVote {
+	var votes []*findingVote
+	for run, r := range runs {
+		for _, c := range r.Comments {
+			var match *findingVote
+			best := tolerance + 1
+			for _, v := range votes {
+				d := lineDistance(v.reports[0], c)
+				if d < best && v.reports[0].File == c.File && !slices.Contains(v.runs, run) {
+					match, best = v, d
+				}
+			}
+			if match == nil {
+				match = &findingVote{}
+				votes = append(votes, match)
+			}
+			match.reports = append(match.reports, c)
+			match.runs = append(match.runs, run)
+		}
+	}
+	return votes
+}
+
+// consensus returns the report on the line most runs named, the earliest run's on a tie
+func (v *findingVote) consensus() domain.ReviewComment {
+	counts := make(map[domain.FlexibleLine]int)
+	best := v.reports[0]
+	for _, c := range v.reports {
+		counts[c.Line]++
+		if counts[c.Line] > counts[best.Line] {
+			best = c
+		}
+	}
+	return best
+}
+
+// lineDistance returns how many lines apart two findings are
+func lineDistance(a, b domain.ReviewComment) int {
+	d := int(a.Line) - int(b.Line)
+	if d < 0 {
+		return -d
+	}
+	return d
+}
@@ -0,0 +1,105 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+
+	"github.com/openai/openai-go"
+)
+
+// ensembleLLM answers concurrent review calls with its responses in turn; an empty response fails
+type ensembleLLM struct {
+	countingLLM
+	mu           sync.Mutex
+	responses    []string
+	temperatures []float64
+}
+
+func (m *ensembleLLM) Chat(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	content := m.responses[len(m.temperatures)]
+	m.temperatures = append(m.temperatures, params.Temperature.Value)
+	if content == "" {
+		return nil, errors.New("llm unavailable")
+	}
+	resp := completion(content)
+	resp.Usage.CompletionTokens = 10
+	return resp, nil
+}
+
+func TestStage3_Ensemble(t *testing.T) {
+	cfg := validConfig(t)
+	cfg.Pipeline.Stage3Review.Ensemble = config.EnsembleConfig{Runs: 4, Temperature: 0.7, LineTolerance: 3}
+	llm := &ensembleLLM{responses: []string{
+		`{"summary": "Leak in a.go.", "score": 70, "comments": [{"path": "a.go", "line": 10, "message": "leak", "severity": "CRITICAL"}]}`,
+		`{"summary": "Connection leak.", "score": 60, "comments": [{"path": "a.go", "line": 12, "message": "connection leak", "severity": "CRITICAL"}, {"path": "b.go", "line": 5, "message": "invented", "severity": "WARNING"}]}`,
+		`{"summary": "Leak.", "score": 80, "comments": [{"path": "a.go", "line": 10, "message": "leaks the connection", "severity": "WARNING"}]}`,
+		``,
+	}}
+	s3 := NewStage3(&cfg.Pipeline, nil, llm, NewPromptLoader(cfg.Prompts.Dir))
+
+	result, err := s3.Review(context.Background(), ReviewRequest{PR: domain.PullRequest{ID: "1"}}, addedLines(3), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(llm.temperatures) != 4 || llm.temperatures[0] != 0.7 {
+		t.Errorf("expected 4 calls at temperature 0.7, got %v", llm.temperatures)
+	}
+	if len(result.Comments) != 1 || result.Comments[0].File != "a.go" || result.Comments[0].Line != 10 {
+		t.Fatalf("expected only the majority finding on a.go:10, got %+v", result.Comments)
+	}
+	if result.Score != 70 || result.Usage.CompletionTokens != 30 {
+		t.Errorf("result = score %d usage %+v, want the average score and summed usage", result.Score, result.Usage)
+	}
+	// The runs are sent in parallel, so the equally agreeing summaries come in any order
+	summaries := strings.Split(result.Summary, "\n\n")
+	slices.Sort(summaries)
+	if want := []string{"Connection leak.", "Leak in a.go.", "Leak."}; !slices.Equal(summaries, want) {
+		t.Errorf("summary = %q, want the summaries of the runs merged: %q", result.Summary, want)
+	}
+}
+
+func TestMergeSummaries(t *testing.T) {
+	runs := []*domain.ReviewResult{
+		{Summary: "Leak."},
+		{Summary: "Invented issue."},
+		{Summary: "Connection leak in a.go."},
+		{Summary: " Leak. "},
+	}
+	if got, want := mergeSummaries(runs, []int{1, 0, 2, 1}), "Connection leak in a.go.\n\nLeak."; got != want {
+		t.Errorf("summary = %q, want the distinct summaries of the agreeing runs, most agreeing first: %q", got, want)
+	}
+	if got, want := mergeSummaries(runs[:2], []int{0, 0}), "Leak.\n\nInvented issue."; got != want {
+		t.Errorf("without kept findings: summary = %q, want %q", got, want)
+	}
+}
+
+func TestVoteFindings(t *testing.T) {
+	runs := []*domain.ReviewResult{
+		{Comments: []domain.ReviewComment{{File: "a.go", Line: 10}, {File: "a.go", Line: 12}}},
+		{Comments: []domain.ReviewComment{{File: "a.go", Line: 13}, {File: "a.go", Line: 30}}},
+		{Comments: []domain.ReviewComment{{File: "b.go", Line: 10}, {File: "a.go", Line: 9}}},
+	}
+	votes := voteFindings(runs, 3)
+	if len(votes) != 4 {
+		t.Fatalf("expected 4 findings, got %d", len(votes))
+	}
+	// Reports join the nearest finding of the same file, at most once per run
+	want := [][]int{{0, 2}, {0, 1}, {1}, {2}}
+	for i, v := range votes {
+		if !slices.Equal(v.runs, want[i]) {
+			t.Errorf("finding %d (%s:%d) runs = %v, want %v", i, v.reports[0].File, v.reports[0].Line, v.runs, want[i])
+		}
+	}
+	if c := votes[0].consensus(); c.Line != 10 {
+		t.Errorf("consensus on a tie must keep the earliest run's line, got %d", c.Line)
+	}
+}
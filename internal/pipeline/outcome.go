@@ -0,0 +1,156 @@
+package pipeline
+
+import (
+	"context"
+	"log/slog"
+	"regexp"
+	"slices"
+	"strings"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+
+	"github.com/openai/openai-go"
+)
+
+// retryPromptTemplate is the reinforcement prompt sent after a refusal, empty or low-content review
+const retryPromptTemplate = "pipeline/stage3_retry"
+
+// defaultLowContentLines is used when outcome_check.min_changed_lines is not set
+const defaultLowContentLines = 30
+
+var (
+	refusalPattern = regexp.MustCompile(`(?i)\b(i\s*(?:can(?:no|')?t|am unable to|'m unable to|won'?t|will not)\s+(?:review|help|assist|comply|provide|do that|complete)|i'?m sorry,? but|as an ai\b)`)
+	genericSummary = regexp.MustCompile(`(?i)\b((?:looks?|seems?)\s+(?:good|fine|ok(?:ay)?|great|clean|correct)|lgtm|no\s+(?:significant\s+|major\s+|obvious\s+)?(?:issues|problems|concerns)\s+(?:were\s+)?(?:found|detected|identified)|nothing\s+to\s+(?:report|comment|add))\b`)
+)
+
+// classifyOutcome classifies a review response. Responses with findings are always OK;
+// without findings, a refusal, a missing summary or a generic summary for a sizeable change
+// (outcome_check.min_changed_lines) is flagged.
+func classifyOutcome(cfg config.OutcomeCheckConfig, resp *openai.ChatCompletion, result *domain.ReviewResult, parsed bool, changes []FileChange) string {
+	msg := resp.Choices[0].Message
+	content := strings.TrimSpace(msg.Content)
+	switch {
+	case msg.Refusal != "":
+		return domain.OutcomeRefusal
+	case content == "":
+		return domain.OutcomeEmpty
+	case !parsed:
+		if isRefusal(cfg, content) {
+			return domain.OutcomeRefusal
+		}
+		return domain.OutcomeUnparseable
+	case len(result.Comments) > 0:
+		return domain.OutcomeOK
+	}
+
+	summary := strings.TrimSpace(result.Summary)
+	switch {
+	case isRefusal(cfg, summary):
+		return domain.OutcomeRefusal
+	case summary == "":
+		return domain.OutcomeEmpty
+	}
+
+	minLines := cfg.MinChangedLines
+	if minLines <= 0 {
+		minLines = defaultLowContentLines
+	}
+	words := len(strings.Fields(summary))
+	if changedLines(changes) >= minLines && (words < 8 || (words < 30 && genericSummary.MatchString(summary))) {
+		return domain.OutcomeLowContent
+	}
+	return domain.OutcomeOK
+}
+
+// isRefusal reports whether text reads like the model declining the review
+func isRefusal(cfg config.OutcomeCheckConfig, text string) bool {
+	if refusalPattern.MatchString(text) {
+		return true
+	}
+	for _, expr := range cfg.RefusalPatterns {
+		re, err := regexp.Compile("(?i)" + expr)
+		if err == nil && re.MatchString(text) {
+			return true
+		}
+	}
+	return false
+}
+
+// changedLines counts the added and removed lines of the changes
+func changedLines(changes []FileChange) int {
+	n := 0
+	for _, c := range changes {
+		for _, line := range c.HunkLines {
+			if (strings.HasPrefix(line, "+") && !strings.HasPrefix(line, "+++")) ||
+				(strings.HasPrefix(line, "-") && !strings.HasPrefix(line, "---")) {
+				n++
+			}
+		}
+	}
+	return n
+}
+
+// retryable reports whether an outcome is worth a second attempt. Unparseable responses are
+// usually truncated and would be again.
+func retryable(outcome string) bool {
+	return outcome == domain.OutcomeRefusal || outcome == domain.OutcomeEmpty || outcome == domain.OutcomeLowContent
+}
+
+// retryReview asks once more with the first answer and a reinforcement prompt appended.
+// The retry replaces the first result when it parses; otherwise the first result is kept.
+func (s *Stage3) retryReview(
+	ctx context.Context,
+	params openai.ChatCompletionNewParams,
+	first *openai.ChatCompletion,
+	result *domain.ReviewResult,
+	changes []FileChange,
+	streamDebug *streamLog,
+) *domain.ReviewResult {
+	reinforcement, err := s.promptLoader.LoadPrompt(retryPromptTemplate, map[string]interface{}{
+		"Outcome":      result.Outcome,
+		"ResultFormat": s.getResultFormat(),
+		"Contract":     s.contract,
+	})
+	if err != nil {
+		slog.Warn("load retry prompt failed", "error", err)
+		return result
+	}
+	params.Messages = append(slices.Clone(params.Messages), first.Choices[0].Message.ToParam(), openai.UserMessage(reinforcement))
+
+	resp, err := s.complete(ctx, params, changes, streamDebug)
+	if err != nil {
+		slog.Warn("review retry failed", "outcome", result.Outcome, "error", err)
+		result.Retried = true
+		return result
+	}
+	retried, parsed := s.parseResult(resp, streamDebug)
+	retried.Outcome = classifyOutcome(s.cfg.Stage3Review.OutcomeCheck, resp, retried, parsed, changes)
+	metrics.ReviewOutcomes.WithLabelValues(retried.Outcome, "retry").Inc()
+	slog.Info("review retried", "outcome", result.Outcome, "retry_outcome", retried.Outcome, "comments", len(retried.Comments))
+
+	if !parsed {
+		result.Usage.Add(retried.Usage)
+		result.Retried = true
+		return result
+	}
+	retried.Usage.Add(result.Usage)
+	retried.Retried = true
+	return retried
+}
+
+// aggregateOutcome combines chunk outcomes: OK when any chunk produced a usable review,
+// otherwise the outcome of the first reviewed chunk
+func aggregateOutcome(chunks []domain.ChunkReport) string {
+	outcome := ""
+	for _, c := range chunks {
+		if c.Outcome == domain.OutcomeOK {
+			return domain.OutcomeOK
+		}
+		if outcome == "" {
+			outcome = c.Outcome
+		}
+	}
+	return outcome
+}
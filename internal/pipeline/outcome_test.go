@@ -0,0 +1,156 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+
+	"github.com/openai/openai-go"
+)
+
+func completion(content string) *openai.ChatCompletion {
+	return &openai.ChatCompletion{Choices: []openai.ChatCompletionChoice{{
+		Message: openai.ChatCompletionMessage{Content: content},
+	}}}
+}
+
+func addedLines(n int) []FileChange {
+	lines := make([]string, n)
+	for i := range lines {
+		lines[i] = "+x++"
+	}
+	return []FileChange{{Path: "a.go", HunkLines: lines}}
+}
+
+func TestClassifyOutcome(t *testing.T) {
+	finding := []domain.ReviewComment{{File: "a.go", Line: 1, Comment: "nil deref"}}
+	tests := []struct {
+		name    string
+		content string
+		result  domain.ReviewResult
+		parsed  bool
+		changes []FileChange
+		cfg     config.OutcomeCheckConfig
+		want    string
+	}{
+		{name: "findings", content: "{}", result: domain.ReviewResult{Comments: finding, Summary: "LGTM"}, parsed: true, changes: addedLines(100), want: domain.OutcomeOK},
+		{name: "blank response", content: "  ", want: domain.OutcomeEmpty},
+		{name: "plain text refusal", content: "I'm sorry, but I can't help with that request.", want: domain.OutcomeRefusal},
+		{name: "truncated json", content: `{"comments": [`, want: domain.OutcomeUnparseable},
+		{name: "refusal in summary", content: "{}", result: domain.ReviewResult{Summary: "I cannot review this code."}, parsed: true, want: domain.OutcomeRefusal},
+		{name: "no summary", content: "{}", result: domain.ReviewResult{}, parsed: true, want: domain.OutcomeEmpty},
+		{name: "generic summary of a large change", content: "{}", result: domain.ReviewResult{Summary: "The code looks good overall, no issues found."}, parsed: true, changes: addedLines(40), want: domain.OutcomeLowContent},
+		{name: "generic summary of a small change", content: "{}", result: domain.ReviewResult{Summary: "Looks good."}, parsed: true, changes: addedLines(5), want: domain.OutcomeOK},
+		{
+			name:    "specific summary of a large change",
+			content: "{}",
+			result:  domain.ReviewResult{Summary: "The retry loop now backs off exponentially and the new test covers the cancellation path; the config default matches the README."},
+			parsed:  true,
+			changes: addedLines(40),
+			want:    domain.OutcomeOK,
+		},
+		{
+			name:    "configured refusal pattern",
+			content: "{}",
+			result:  domain.ReviewResult{Summary: "Request blocked by content policy."},
+			parsed:  true,
+			cfg:     config.OutcomeCheckConfig{RefusalPatterns: []string{`content policy`}},
+			want:    domain.OutcomeRefusal,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := classifyOutcome(tt.cfg, completion(tt.content), &tt.result, tt.parsed, tt.changes); got != tt.want {
+				t.Errorf("classifyOutcome() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
+
+// scriptedLLM answers the review calls in order and records the requests
+type scriptedLLM struct {
+	countingLLM
+	responses []string
+	requests  []openai.ChatCompletionNewParams
+}
+
+func (m *scriptedLLM) Chat(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	m.requests = append(m.requests, params)
+	resp := completion(m.responses[len(m.requests)-1])
+	resp.Usage.CompletionTokens = 10
+	return resp, nil
+}
+
+func TestStage3_RetriesRefusal(t *testing.T) {
+	cfg := validConfig(t)
+	cfg.Pipeline.Stage3Review.OutcomeCheck.Retry = true
+	llm := &scriptedLLM{responses: []string{
+		`{"summary": "I'm sorry, but I cannot review this code.", "comments": []}`,
+		`{"summary": "One issue.", "comments": [{"path": "a.go", "line": 3, "message": "unchecked error", "severity": "WARNING"}]}`,
+	}}
+	s3 := NewStage3(&cfg.Pipeline, nil, llm, NewPromptLoader(cfg.Prompts.Dir))
+
+	result, err := s3.Review(context.Background(), ReviewRequest{PR: domain.PullRequest{ID: "1"}}, addedLines(3), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(llm.requests) != 2 {
+		t.Fatalf("expected one retry, got %d calls", len(llm.requests))
+	}
+	retry := llm.requests[1].Messages
+	if len(retry) != 4 || retry[2].OfAssistant == nil || !strings.Contains(retry[3].OfUser.Content.OfString.Value, "(refusal)") {
+		t.Errorf("retry must carry the first answer and the reinforcement prompt, got %d messages", len(retry))
+	}
+	if result.Outcome != domain.OutcomeOK || !result.Retried || len(result.Comments) != 1 {
+		t.Errorf("expected the retried review, got outcome=%q retried=%v comments=%d", result.Outcome, result.Retried, len(result.Comments))
+	}
+	if result.Usage.CompletionTokens != 20 {
+		t.Errorf("usage must cover both calls, got %d", result.Usage.CompletionTokens)
+	}
+	if c := result.Report.Chunks[0]; c.Outcome != domain.OutcomeOK || !c.Retried {
+		t.Errorf("chunk report = %+v", c)
+	}
+}
+
+func TestStage3_KeepsFirstResultWhenRetryUnparseable(t *testing.T) {
+	cfg := validConfig(t)
+	cfg.Pipeline.Stage3Review.OutcomeCheck.Retry = true
+	llm := &scriptedLLM{responses: []string{`{"summary": "", "comments": []}`, `{"summary": "trunc`}}
+	s3 := NewStage3(&cfg.Pipeline, nil, llm, NewPromptLoader(cfg.Prompts.Dir))
+
+	result, err := s3.Review(context.Background(), ReviewRequest{PR: domain.PullRequest{ID: "1"}}, addedLines(3), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Outcome != domain.OutcomeEmpty || !result.Retried {
+		t.Errorf("expected the first (empty) result marked retried, got outcome=%q retried=%v", result.Outcome, result.Retried)
+	}
+}
+
+func TestStage3_NoRetryWhenDisabled(t *testing.T) {
+	cfg := validConfig(t)
+	llm := &scriptedLLM{responses: []string{`{"summary": "I can't help with that.", "comments": []}`}}
+	s3 := NewStage3(&cfg.Pipeline, nil, llm, NewPromptLoader(cfg.Prompts.Dir))
+
+	result, err := s3.Review(context.Background(), ReviewRequest{PR: domain.PullRequest{ID: "1"}}, addedLines(3), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(llm.requests) != 1 || result.Outcome != domain.OutcomeRefusal || result.Retried {
+		t.Errorf("expected a single classified call, got %d calls, outcome=%q", len(llm.requests), result.Outcome)
+	}
+}
+
+func TestAggregateOutcome(t *testing.T) {
+	chunks := []domain.ChunkReport{{Error: "timeout"}, {Outcome: domain.OutcomeLowContent}, {Outcome: domain.OutcomeRefusal}}
+	if got := aggregateOutcome(chunks); got != domain.OutcomeLowContent {
+		t.Errorf("aggregateOutcome() = %q", got)
+	}
+	chunks = append(chunks, domain.ChunkReport{Outcome: domain.OutcomeOK})
+	if got := aggregateOutcome(chunks); got != domain.OutcomeOK {
+		t.Errorf("aggregateOutcome() = %q", got)
+	}
+}
@@ -0,0 +1,48 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/domain"
+)
+
+func TestStage3_PerReviewOverrides(t *testing.T) {
+	// Two files whose diff alone exceeds max_context_tokens (1000) of the test config
+	big := []string{"+" + strings.Repeat("x", 2000)}
+	changes := []FileChange{{Path: "a.go", HunkLines: big}, {Path: "b.go", HunkLines: big}}
+	answer := `{"summary": "Checked the generated constants; they match the schema.", "comments": []}`
+
+	tests := []struct {
+		name         string
+		overrides    *domain.ReviewOverrides
+		wantCalls    int
+		wantStrategy string
+		wantModel    string
+	}{
+		{name: "config", overrides: nil, wantCalls: 2, wantStrategy: domain.StrategyChunked},
+		{name: "direct with model", overrides: &domain.ReviewOverrides{Direct: true, Model: "qwen2.5-coder"}, wantCalls: 1, wantStrategy: domain.StrategyFull, wantModel: "qwen2.5-coder"},
+		{name: "larger chunk budget", overrides: &domain.ReviewOverrides{ChunkTokens: 100000}, wantCalls: 1, wantStrategy: domain.StrategyFull},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig(t)
+			cfg.Pipeline.Stage3Review.Degradation.L2ChunkByFile = true
+			llm := &scriptedLLM{responses: []string{answer, answer}}
+			s3 := NewStage3(&cfg.Pipeline, nil, llm, NewPromptLoader(cfg.Prompts.Dir))
+
+			pr := domain.PullRequest{ID: "1", Overrides: tt.overrides}
+			result, err := s3.Review(context.Background(), ReviewRequest{PR: pr}, changes, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(llm.requests) != tt.wantCalls || result.Report.Strategy != tt.wantStrategy {
+				t.Errorf("got %d calls with strategy %s, want %d with %s", len(llm.requests), result.Report.Strategy, tt.wantCalls, tt.wantStrategy)
+			}
+			if got := string(llm.requests[0].Model); got != tt.wantModel {
+				t.Errorf("model = %q, want %q", got, tt.wantModel)
+			}
+		})
+	}
+}
@@ -8,18 +8,74 @@ import (
 	"pr-review-automation/internal/config"
 	"pr-review-automation/internal/types"
 	"strings"
+	"sync"
 	"text/template"
+	"time"
 )
 
 // PromptLoader loads prompts from filesystem
 type PromptLoader struct {
 	baseDir           string
 	rawSchemaProvider types.RawSchemaProvider
+
+	mu        sync.RWMutex
+	templates map[string]cachedTemplate // Parsed templates by file path
+}
+
+// cachedTemplate is a parsed prompt file, reused until the file changes on disk
+type cachedTemplate struct {
+	tmpl    *template.Template
+	modTime time.Time
+	size    int64
 }
 
 // NewPromptLoader creates a new prompt loader
 func NewPromptLoader(baseDir string) *PromptLoader {
-	return &PromptLoader{baseDir: baseDir}
+	return &PromptLoader{
+		baseDir:   baseDir,
+		templates: make(map[string]cachedTemplate),
+	}
+}
+
+// template returns the parsed template for path, parsing it only when the file is new or changed.
+// File errors are returned unwrapped so callers can check os.IsNotExist.
+func (l *PromptLoader) template(path string) (*template.Template, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+
+	l.mu.RLock()
+	cached, ok := l.templates[path]
+	l.mu.RUnlock()
+	if ok && cached.modTime.Equal(info.ModTime()) && cached.size == info.Size() {
+		return cached.tmpl, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	tmpl, err := template.New("prompt").Parse(string(data))
+	if err != nil {
+		return nil, fmt.Errorf("parse prompt template: %w", err)
+	}
+
+	l.mu.Lock()
+	l.templates[path] = cachedTemplate{tmpl: tmpl, modTime: info.ModTime(), size: info.Size()}
+	l.mu.Unlock()
+	return tmpl, nil
+}
+
+// Precompile parses the named prompts (as accepted by LoadPrompt) ahead of first use
+func (l *PromptLoader) Precompile(names ...string) error {
+	for _, name := range names {
+		path := filepath.Join(l.baseDir, strings.TrimSuffix(name, ".md")+".md")
+		if _, err := l.template(path); err != nil {
+			return fmt.Errorf("precompile prompt %s: %w", path, err)
+		}
+	}
+	return nil
 }
 
 // SetRawSchemaProvider sets the raw schema provider for dynamic prompt generation
@@ -37,9 +93,9 @@ func (l *PromptLoader) Load(project, language string, extraData map[string]inter
 	}
 
 	for _, path := range candidates {
-		data, err := os.ReadFile(path)
+		tmpl, err := l.template(path)
 		if err == nil {
-			return l.render(string(data), extraData)
+			return l.render(tmpl, extraData)
 		}
 		if !os.IsNotExist(err) {
 			return "", fmt.Errorf("read prompt %s: %w", path, err)
@@ -73,7 +129,7 @@ func NewPromptData() PromptData {
 	}
 }
 
-func (l *PromptLoader) render(tmplContent string, extraData map[string]interface{}) (string, error) {
+func (l *PromptLoader) render(tmpl *template.Template, extraData map[string]interface{}) (string, error) {
 	data := NewPromptData()
 	if val, ok := extraData["ProjectKey"].(string); ok {
 		data.ProjectKey = val
@@ -102,10 +158,6 @@ func (l *PromptLoader) render(tmplContent string, extraData map[string]interface
 		mergedData[k] = v
 	}
 
-	tmpl, err := template.New("prompt").Parse(tmplContent)
-	if err != nil {
-		return "", fmt.Errorf("parse prompt template: %w", err)
-	}
 	var sb strings.Builder
 	if err := tmpl.Execute(&sb, mergedData); err != nil {
 		return "", fmt.Errorf("execute prompt template: %w", err)
@@ -176,10 +228,10 @@ func (l *PromptLoader) LoadPrompt(name string, data map[string]interface{}) (str
 	name = strings.TrimSuffix(name, ".md")
 
 	path := filepath.Join(l.baseDir, name+".md")
-	tmplData, err := os.ReadFile(path)
+	tmpl, err := l.template(path)
 	if err != nil {
 		return "", fmt.Errorf("read prompt %s: %w", path, err)
 	}
 
-	return l.render(string(tmplData), data)
+	return l.render(tmpl, data)
 }
@@ -0,0 +1,124 @@
+package pipeline
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+	"strings"
+	"time"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+)
+
+// reviewChunk runs reviewFunc on one chunk, tags the resulting comments with the chunk index
+// and records the chunk report and metrics. The report is returned even when the review fails.
+func reviewChunk(
+	ctx context.Context,
+	strategy string,
+	index int,
+	req ReviewRequest,
+	changes []FileChange,
+	contextFiles []FileContent,
+	reviewFunc ReviewFunc,
+) (*domain.ReviewResult, domain.ChunkReport, error) {
+	report := domain.ChunkReport{
+		Index:           index,
+		EstimatedTokens: estimateChunkTokens(changes, contextFiles),
+	}
+	for _, c := range changes {
+		report.Files = append(report.Files, c.Path)
+	}
+
+	start := time.Now()
+	result, err := reviewFunc(ctx, req, changes, contextFiles)
+	elapsed := time.Since(start)
+	report.DurationMs = elapsed.Milliseconds()
+
+	metrics.ChunkTokens.WithLabelValues(strategy, "estimated").Observe(float64(report.EstimatedTokens))
+	if err != nil {
+		report.Error = err.Error()
+		metrics.ChunkDuration.WithLabelValues(strategy, "error").Observe(elapsed.Seconds())
+		metrics.ChunkResults.WithLabelValues(strategy, "error").Inc()
+		return nil, report, err
+	}
+
+	for i := range result.Comments {
+		result.Comments[i].Chunk = index
+	}
+	report.Findings = len(result.Comments)
+	report.Usage = result.Usage
+	report.Outcome = result.Outcome
+	report.Retried = result.Retried
+	report.Repaired = result.Repaired
+	report.Model = result.Model
+
+	metrics.ChunkDuration.WithLabelValues(strategy, "success").Observe(elapsed.Seconds())
+	metrics.ChunkFindings.WithLabelValues(strategy).Observe(float64(report.Findings))
+	metrics.ChunkResults.WithLabelValues(strategy, chunkResult(result.Outcome)).Inc()
+	if result.Usage != nil {
+		metrics.LLMTokens.WithLabelValues("prompt").Add(float64(result.Usage.PromptTokens))
+		metrics.LLMTokens.WithLabelValues("completion").Add(float64(result.Usage.CompletionTokens))
+		metrics.ChunkTokens.WithLabelValues(strategy, "sent").Observe(float64(result.Usage.PromptTokens))
+		metrics.ChunkTokens.WithLabelValues(strategy, "received").Observe(float64(result.Usage.CompletionTokens))
+	}
+
+	slog.Info("chunk reviewed", "strategy", strategy, "index", index, "files", len(report.Files),
+		"duration", elapsed, "estimated_tokens", report.EstimatedTokens, "findings", report.Findings)
+	return result, report, nil
+}
+
+// chunkResult labels a reviewed chunk by the outcome of its model response
+func chunkResult(outcome string) string {
+	if outcome == "" {
+		return domain.OutcomeOK
+	}
+	return outcome
+}
+
+// reviewSingle reviews all changes in one call and attaches a single-chunk execution report
+func reviewSingle(
+	ctx context.Context,
+	strategy string,
+	req ReviewRequest,
+	changes []FileChange,
+	contextFiles []FileContent,
+	reviewFunc ReviewFunc,
+) (*domain.ReviewResult, error) {
+	result, chunk, err := reviewChunk(ctx, strategy, 1, req, changes, contextFiles, reviewFunc)
+	if err != nil {
+		return nil, err
+	}
+	result.Report = &domain.ExecutionReport{
+		Strategy: strategy,
+		Chunks:   []domain.ChunkReport{chunk},
+	}
+	result.Report.Attribute(result.Comments)
+	return result, nil
+}
+
+// estimateChunkTokens estimates the diff and context tokens of a chunk, excluding the system prompt
+func estimateChunkTokens(changes []FileChange, contextFiles []FileContent) int {
+	tokens := 0
+	for _, c := range changes {
+		for _, line := range c.HunkLines {
+			tokens += EstimateTokens(line)
+		}
+	}
+	for _, c := range contextFiles {
+		tokens += EstimateTokens(c.Content)
+	}
+	return tokens
+}
+
+// chunkModels lists the models that answered the chunks, in order of first use, or "" when
+// no chunk recorded one
+func chunkModels(chunks []domain.ChunkReport) string {
+	var models []string
+	for _, c := range chunks {
+		if c.Model != "" && !slices.Contains(models, c.Model) {
+			models = append(models, c.Model)
+		}
+	}
+	return strings.Join(models, ", ")
+}
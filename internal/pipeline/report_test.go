@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestReviewChunked_Report(t *testing.T) {
+	// Each file is ~100 tokens, so with a 150-token budget every file gets its own chunk
+	big := strings.Repeat("x", 350)
+	changes := []FileChange{
+		{Path: "a.go", HunkLines: []string{big}},
+		{Path: "b.go", HunkLines: []string{big}},
+		{Path: "c.go", HunkLines: []string{big}},
+	}
+
+	reviewFunc := func(ctx context.Context, req ReviewRequest, changes []FileChange, contextFiles []FileContent) (*domain.ReviewResult, error) {
+		switch changes[0].Path {
+		case "a.go":
+			return &domain.ReviewResult{
+				Comments: []domain.ReviewComment{{File: "a.go", Line: 1}, {File: "ghost.go", Line: 2}},
+				Usage:    &domain.TokenUsage{PromptTokens: 100, CompletionTokens: 10},
+			}, nil
+		case "b.go":
+			return &domain.ReviewResult{Usage: &domain.TokenUsage{PromptTokens: 50, CompletionTokens: 5}}, nil
+		default:
+			return nil, errors.New("llm unavailable")
+		}
+	}
+
+	cr := NewChunkReviewer(170)
+	result, err := cr.ReviewChunked(context.Background(), ReviewRequest{}, changes, nil, "", reviewFunc)
+	if err != nil {
+		t.Fatalf("ReviewChunked: %v", err)
+	}
+
+	report := result.Report
+	if report == nil || report.Strategy != domain.StrategyChunked || len(report.Chunks) != 3 {
+		t.Fatalf("unexpected report: %+v", report)
+	}
+	if report.Chunks[0].Findings != 2 || report.Chunks[1].Findings != 0 {
+		t.Errorf("unexpected findings per chunk: %+v", report.Chunks)
+	}
+	if report.Chunks[2].Error == "" {
+		t.Error("expected failed chunk to record its error")
+	}
+	if report.FindingsByFile["a.go"] != 1 || report.Unattributed != 1 {
+		t.Errorf("unexpected attribution: %v, unattributed %d", report.FindingsByFile, report.Unattributed)
+	}
+	for _, c := range result.Comments {
+		if c.Chunk != 1 {
+			t.Errorf("expected comment %s to come from chunk 1, got %d", c.File, c.Chunk)
+		}
+	}
+	if result.Usage.PromptTokens != 150 || result.Usage.CompletionTokens != 15 {
+		t.Errorf("unexpected usage: %+v", result.Usage)
+	}
+}
+
+func TestApplyStrategy_SingleChunkReport(t *testing.T) {
+	dm := NewDegradationManager(config.DegradationConfig{L3DiffOnly: true}, 100000, nil)
+	changes := []FileChange{{Path: "a.go", HunkLines: []string{"+x"}}}
+
+	reviewFunc := func(ctx context.Context, req ReviewRequest, changes []FileChange, contextFiles []FileContent) (*domain.ReviewResult, error) {
+		return &domain.ReviewResult{Comments: []domain.ReviewComment{{File: "a.go"}}}, nil
+	}
+
+	result, err := dm.ApplyStrategy(context.Background(), ReviewRequest{}, changes, nil, "", "", reviewFunc)
+	if err != nil {
+		t.Fatalf("ApplyStrategy: %v", err)
+	}
+	if result.Report == nil || result.Report.Strategy != domain.StrategyFull || result.Report.FindingsByFile["a.go"] != 1 {
+		t.Errorf("unexpected report: %+v", result.Report)
+	}
+}
@@ -0,0 +1,83 @@
+package pipeline
+
+import (
+	"slices"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/rules"
+	"pr-review-automation/internal/tokenizer"
+)
+
+// modelRoute reviews the pull requests matching an llm.routes entry with its own client
+type modelRoute struct {
+	route  config.LLMRoute
+	stage3 Stage3Reviewer
+}
+
+// matches reports whether the route applies to the pull request
+func (r modelRoute) matches(pr *domain.PullRequest) bool {
+	if slices.Contains(r.route.Projects, pr.ProjectKey) {
+		return true
+	}
+	return len(r.route.Repos) > 0 && rules.MatchAny(r.route.Repos, pr.ProjectKey+"/"+pr.RepoSlug)
+}
+
+// AddModelRoute sends the Stage 3 review of the repositories matching route to llm, asking
+// for the route's review contract when it sets one. Routes are tried in the order they were
+// added; unmatched reviews use the default client.
+func (pa *PipelineAdapter) AddModelRoute(route config.LLMRoute, llm LLMClient) {
+	s3, ok := pa.pipeline.stage3.(*Stage3)
+	if ok {
+		s3 = s3.withLLM(llm)
+	} else {
+		s3 = NewStage3(&pa.pipeline.cfg.Pipeline, pa.pipeline.mcpClient, llm, pa.promptLoader)
+	}
+	if route.Contract != "" {
+		s3.contract = route.Contract
+	}
+	s3 = s3.withTokenizer(tokenizer.For(route.Model))
+	pa.routes = append(pa.routes, modelRoute{route: route, stage3: s3})
+}
+
+// route returns the Stage 3 reviewer and model for a pull request
+func (pa *PipelineAdapter) route(pr *domain.PullRequest) (Stage3Reviewer, string) {
+	if r, ok := pa.matchRoute(pr); ok {
+		return r.stage3, r.route.Model
+	}
+	return pa.pipeline.stage3, pa.pipeline.cfg.LLM.Model
+}
+
+// matchRoute returns the first route applying to the pull request
+func (pa *PipelineAdapter) matchRoute(pr *domain.PullRequest) (modelRoute, bool) {
+	for _, r := range pa.routes {
+		if r.matches(pr) {
+			return r, true
+		}
+	}
+	return modelRoute{}, false
+}
+
+// Provider returns the LLM provider serving the review of a pull request: that of its route,
+// else llm.provider. A model override is sent to the same client, so it keeps the provider.
+func (pa *PipelineAdapter) Provider(pr *domain.PullRequest) string {
+	if r, ok := pa.matchRoute(pr); ok && r.route.Provider != "" {
+		return r.route.Provider
+	}
+	return pa.pipeline.cfg.LLM.Provider
+}
+
+// withTokenizer returns a copy of the stage that counts tokens with t
+func (s *Stage3) withTokenizer(t tokenizer.Tokenizer) *Stage3 {
+	clone := *s
+	clone.tokens = t
+	clone.degradationManager = clone.newDegradationManager(s.cfg.Stage3Review.Degradation, s.cfg.Stage3Review.MaxContextTokens)
+	return &clone
+}
+
+// withLLM returns a copy of the stage that sends its reviews to llm
+func (s *Stage3) withLLM(llm LLMClient) *Stage3 {
+	clone := *s
+	clone.llm = llm
+	return &clone
+}
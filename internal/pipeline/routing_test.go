@@ -0,0 +1,161 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/tokenizer"
+
+	"github.com/openai/openai-go"
+)
+
+// staticDiff is a Stage 1 and Stage 2 stand-in returning fixed changes and no context
+type staticDiff struct {
+	changes []FileChange
+}
+
+func (s staticDiff) ExtractDiffs(ctx context.Context, req ReviewRequest) ([]FileChange, error) {
+	return s.changes, nil
+}
+
+func (s staticDiff) CollectContext(ctx context.Context, req ReviewRequest, changes []FileChange) ([]FileContent, error) {
+	return nil, nil
+}
+
+func TestPipelineAdapter_ModelRoutes(t *testing.T) {
+	answer := `{"summary": "Checked the generated constants; they match the schema.", "comments": []}`
+	cfg := validConfig(t)
+	defaultLLM := &scriptedLLM{responses: []string{answer, answer}}
+	fasLLM := &scriptedLLM{responses: []string{answer}}
+	toolsLLM := &scriptedLLM{responses: []string{answer}}
+
+	pa := NewPipelineAdapter(cfg, nil, defaultLLM, NewPromptLoader(cfg.Prompts.Dir))
+	diff := staticDiff{changes: []FileChange{{Path: "a.go", HunkLines: []string{"+x := 1"}}}}
+	pa.pipeline.stage1, pa.pipeline.stage2 = diff, diff
+	pa.AddModelRoute(config.LLMRoute{Projects: []string{"FAS"}, LLMTarget: config.LLMTarget{Model: "gpt-4o-mini"}}, fasLLM)
+	pa.AddModelRoute(config.LLMRoute{Repos: []string{"TOOLS/cli-*"}, LLMTarget: config.LLMTarget{Model: "glm-4"}}, toolsLLM)
+
+	tests := []struct {
+		name      string
+		pr        domain.PullRequest
+		wantLLM   *scriptedLLM
+		wantModel string
+	}{
+		{name: "project route", pr: domain.PullRequest{ID: "1", ProjectKey: "FAS", RepoSlug: "api"}, wantLLM: fasLLM, wantModel: "gpt-4o-mini"},
+		{name: "repo route", pr: domain.PullRequest{ID: "2", ProjectKey: "TOOLS", RepoSlug: "cli-sync"}, wantLLM: toolsLLM, wantModel: "glm-4"},
+		{name: "unmatched repo", pr: domain.PullRequest{ID: "3", ProjectKey: "TOOLS", RepoSlug: "web"}, wantLLM: defaultLLM, wantModel: "gpt-4o"},
+		{name: "override wins", pr: domain.PullRequest{ID: "4", ProjectKey: "OPS", RepoSlug: "api", Overrides: &domain.ReviewOverrides{Model: "qwen2.5-coder"}}, wantLLM: defaultLLM, wantModel: "qwen2.5-coder"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := len(tt.wantLLM.requests)
+			pr := tt.pr
+			result, err := pa.ReviewPR(context.Background(), &domain.ReviewRequest{PR: &pr})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if len(tt.wantLLM.requests) != calls+1 {
+				t.Errorf("review not sent to the routed client")
+			}
+			if result.Model != tt.wantModel {
+				t.Errorf("model = %q, want %q", result.Model, tt.wantModel)
+			}
+		})
+	}
+}
+
+// fallbackLLM answers like scriptedLLM, each response from the next of its models
+type fallbackLLM struct {
+	scriptedLLM
+	answeredBy []string
+}
+
+func (m *fallbackLLM) Chat(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	resp, err := m.scriptedLLM.Chat(ctx, params)
+	resp.Model = m.answeredBy[len(m.requests)-1]
+	return resp, err
+}
+
+func (m *fallbackLLM) FallbackModels() []string {
+	return m.answeredBy
+}
+
+func TestPipelineAdapter_RecordsFallbackModel(t *testing.T) {
+	answer := `{"summary": "Checked the generated constants; they match the schema.", "comments": []}`
+	big := []string{"+" + strings.Repeat("x", 2000)}
+	tests := []struct {
+		name       string
+		changes    []FileChange
+		answeredBy []string
+		wantModel  string
+	}{
+		{name: "single review", changes: []FileChange{{Path: "a.go", HunkLines: []string{"+x := 1"}}}, answeredBy: []string{"gpt-4o-mini"}, wantModel: "gpt-4o-mini"},
+		{name: "chunks answered by two models", changes: []FileChange{{Path: "a.go", HunkLines: big}, {Path: "b.go", HunkLines: big}}, answeredBy: []string{"gpt-4o", "gpt-4o-mini"}, wantModel: "gpt-4o, gpt-4o-mini"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig(t)
+			cfg.Pipeline.Stage3Review.Degradation.L2ChunkByFile = true
+			llm := &fallbackLLM{scriptedLLM: scriptedLLM{responses: []string{answer, answer}}, answeredBy: tt.answeredBy}
+			pa := NewPipelineAdapter(cfg, nil, llm, NewPromptLoader(cfg.Prompts.Dir))
+			diff := staticDiff{changes: tt.changes}
+			pa.pipeline.stage1, pa.pipeline.stage2 = diff, diff
+
+			result, err := pa.ReviewPR(context.Background(), &domain.ReviewRequest{PR: &domain.PullRequest{ID: "1", ProjectKey: "PROJ", RepoSlug: "repo"}})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.Model != tt.wantModel {
+				t.Errorf("model = %q, want %q", result.Model, tt.wantModel)
+			}
+		})
+	}
+}
+
+// fixedTokenizer counts every text as the same number of tokens
+type fixedTokenizer int
+
+func (f fixedTokenizer) Count(string) int { return int(f) }
+
+func TestPipelineAdapter_RouteTokenizer(t *testing.T) {
+	cfg := validConfig(t)
+	pa := NewPipelineAdapter(cfg, nil, &scriptedLLM{}, NewPromptLoader(cfg.Prompts.Dir))
+	tokenizer.Register([]string{"route-tokenizer-*"}, fixedTokenizer(7))
+	pa.AddModelRoute(config.LLMRoute{Projects: []string{"FAS"}, LLMTarget: config.LLMTarget{Model: "route-tokenizer-1"}}, &scriptedLLM{})
+
+	routed := pa.routes[0].stage3.(*Stage3)
+	if got := routed.degradationManager.tokens.Count("x"); got != 7 {
+		t.Errorf("route degradation counts %d tokens, want the route tokenizer's 7", got)
+	}
+	if got := routed.degradationManager.chunkReviewer.tokens.Count("x"); got != 7 {
+		t.Errorf("route chunking counts %d tokens, want the route tokenizer's 7", got)
+	}
+	if _, ok := pa.pipeline.stage3.(*Stage3).tokens.(fixedTokenizer); ok {
+		t.Error("route tokenizer leaked into the default stage")
+	}
+}
+
+func TestPipelineAdapter_Provider(t *testing.T) {
+	cfg := validConfig(t)
+	cfg.LLM.Provider = config.LLMProviderOpenAI
+	pa := NewPipelineAdapter(cfg, nil, &scriptedLLM{}, NewPromptLoader(cfg.Prompts.Dir))
+	pa.AddModelRoute(config.LLMRoute{Repos: []string{"TOOLS/cli-*"}, LLMTarget: config.LLMTarget{Provider: config.LLMProviderLocal, Model: "qwen"}}, &scriptedLLM{})
+
+	tests := []struct {
+		name string
+		pr   domain.PullRequest
+		want string
+	}{
+		{name: "route", pr: domain.PullRequest{ProjectKey: "TOOLS", RepoSlug: "cli-go"}, want: config.LLMProviderLocal},
+		{name: "unmatched", pr: domain.PullRequest{ProjectKey: "TOOLS", RepoSlug: "web"}, want: config.LLMProviderOpenAI},
+		{name: "model override keeps the route", pr: domain.PullRequest{ProjectKey: "TOOLS", RepoSlug: "cli-go", Overrides: &domain.ReviewOverrides{Model: "gpt-4o"}}, want: config.LLMProviderLocal},
+	}
+	for _, tt := range tests {
+		if got := pa.Provider(&tt.pr); got != tt.want {
+			t.Errorf("%s: Provider = %q, want %q", tt.name, got, tt.want)
+		}
+	}
+}
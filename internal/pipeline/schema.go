@@ -0,0 +1,223 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"math"
+	"slices"
+	"strings"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+
+	"github.com/openai/openai-go"
+)
+
+// repairPromptTemplate is the follow-up sent when a review answer does not match resultSchema
+const repairPromptTemplate = "pipeline/stage3_repair"
+
+// maxSchemaProblems bounds the problems listed in the repair prompt
+const maxSchemaProblems = 10
+
+// resultSchema is the JSON Schema review answers of both contract versions must match. Line
+// ranges may be given as [start, end] and confidences as words, as parseResult accepts them.
+const resultSchema = `{
+  "type": "object",
+  "required": ["comments", "score", "summary"],
+  "properties": {
+    "version": {"type": "string"},
+    "comments": {
+      "type": "array",
+      "items": {
+        "type": "object",
+        "required": ["path", "line", "message", "severity"],
+        "properties": {
+          "path": {"type": "string", "minLength": 1},
+          "line": {"type": ["integer", "array"], "minimum": 0, "items": {"type": "integer", "minimum": 0}},
+          "end_line": {"type": "integer", "minimum": 0},
+          "message": {"type": "string", "minLength": 1},
+          "severity": {"enum": ["CRITICAL", "WARNING", "INFO", "NIT"]},
+          "category": {"type": "string"},
+          "suggestion": {"type": "string"},
+          "confidence": {"type": ["number", "string"]}
+        }
+      }
+    },
+    "score": {"type": "integer", "minimum": 0, "maximum": 100},
+    "summary": {"type": "string"}
+  }
+}`
+
+// jsonSchema is the subset of JSON Schema resultSchema uses
+type jsonSchema struct {
+	Type       schemaTypes            `json:"type"`
+	Required   []string               `json:"required"`
+	Properties map[string]*jsonSchema `json:"properties"`
+	Items      *jsonSchema            `json:"items"`
+	Enum       []any                  `json:"enum"`
+	Minimum    *float64               `json:"minimum"`
+	Maximum    *float64               `json:"maximum"`
+	MinLength  int                    `json:"minLength"`
+}
+
+// schemaTypes reads "type" given as one name or a list of names
+type schemaTypes []string
+
+func (t *schemaTypes) UnmarshalJSON(data []byte) error {
+	var one string
+	if err := json.Unmarshal(data, &one); err == nil {
+		*t = schemaTypes{one}
+		return nil
+	}
+	return json.Unmarshal(data, (*[]string)(t))
+}
+
+var reviewSchema = mustSchema(resultSchema)
+
+func mustSchema(s string) *jsonSchema {
+	var schema jsonSchema
+	if err := json.Unmarshal([]byte(s), &schema); err != nil {
+		panic(fmt.Sprintf("invalid result schema: %v", err))
+	}
+	return &schema
+}
+
+// schemaProblems returns how a review answer breaks resultSchema, or nil when it matches
+func schemaProblems(jsonStr string) []string {
+	var doc any
+	if err := json.Unmarshal([]byte(jsonStr), &doc); err != nil {
+		return []string{fmt.Sprintf("the answer is not valid JSON: %v", err)}
+	}
+	problems := reviewSchema.validate(doc, "$")
+	if len(problems) > maxSchemaProblems {
+		problems = append(problems[:maxSchemaProblems], fmt.Sprintf("and %d more", len(problems)-maxSchemaProblems))
+	}
+	return problems
+}
+
+// validate returns the violations of v, at path
+func (s *jsonSchema) validate(v any, path string) []string {
+	if len(s.Type) > 0 && !slices.ContainsFunc(s.Type, func(t string) bool { return schemaType(v, t) }) {
+		return []string{fmt.Sprintf("%s must be %s", path, strings.Join(s.Type, " or "))}
+	}
+	if len(s.Enum) > 0 && !slices.Contains(s.Enum, v) {
+		return []string{fmt.Sprintf("%s must be one of %s, got %v", path, enumList(s.Enum), v)}
+	}
+
+	var problems []string
+	switch v := v.(type) {
+	case float64:
+		if s.Minimum != nil && v < *s.Minimum {
+			problems = append(problems, fmt.Sprintf("%s must be at least %g, got %g", path, *s.Minimum, v))
+		}
+		if s.Maximum != nil && v > *s.Maximum {
+			problems = append(problems, fmt.Sprintf("%s must be at most %g, got %g", path, *s.Maximum, v))
+		}
+	case string:
+		if len(strings.TrimSpace(v)) < s.MinLength {
+			problems = append(problems, fmt.Sprintf("%s must not be empty", path))
+		}
+	case []any:
+		if s.Items != nil {
+			for i, item := range v {
+				problems = append(problems, s.Items.validate(item, fmt.Sprintf("%s[%d]", path, i))...)
+			}
+		}
+	case map[string]any:
+		for _, key := range s.Required {
+			if _, ok := v[key]; !ok {
+				problems = append(problems, fmt.Sprintf("%s.%s is required", path, key))
+			}
+		}
+		keys := make([]string, 0, len(s.Properties))
+		for key := range s.Properties {
+			keys = append(keys, key)
+		}
+		slices.Sort(keys)
+		for _, key := range keys {
+			if value, ok := v[key]; ok {
+				problems = append(problems, s.Properties[key].validate(value, path+"."+key)...)
+			}
+		}
+	}
+	return problems
+}
+
+// schemaType reports whether a decoded JSON value has the JSON Schema type t
+func schemaType(v any, t string) bool {
+	switch v := v.(type) {
+	case nil:
+		return t == "null"
+	case bool:
+		return t == "boolean"
+	case float64:
+		return t == "number" || (t == "integer" && v == math.Trunc(v))
+	case string:
+		return t == "string"
+	case []any:
+		return t == "array"
+	case map[string]any:
+		return t == "object"
+	}
+	return false
+}
+
+func enumList(values []any) string {
+	parts := make([]string, len(values))
+	for i, v := range values {
+		parts[i] = fmt.Sprint(v)
+	}
+	return strings.Join(parts, ", ")
+}
+
+// repairReview sends the first answer back with the schema problems and asks for corrected
+// JSON. The repaired answer replaces the first when it parses; otherwise the first is kept.
+// It returns the result and the response it came from.
+func (s *Stage3) repairReview(
+	ctx context.Context,
+	params openai.ChatCompletionNewParams,
+	first *openai.ChatCompletion,
+	result *domain.ReviewResult,
+	problems []string,
+	changes []FileChange,
+	streamDebug *streamLog,
+) (*domain.ReviewResult, *openai.ChatCompletion) {
+	prompt, err := s.promptLoader.LoadPrompt(repairPromptTemplate, map[string]interface{}{
+		"Problems":     problems,
+		"Schema":       resultSchema,
+		"ResultFormat": s.getResultFormat(),
+	})
+	if err != nil {
+		slog.Warn("load repair prompt failed", "error", err)
+		return result, first
+	}
+	params.Messages = append(slices.Clone(params.Messages), first.Choices[0].Message.ToParam(), openai.UserMessage(prompt))
+
+	resp, err := s.complete(ctx, params, changes, streamDebug)
+	if err != nil {
+		slog.Warn("review repair failed", "problems", len(problems), "error", err)
+		metrics.SchemaRepairs.WithLabelValues("error").Inc()
+		return result, first
+	}
+	repaired, parsed := s.parseResult(resp, streamDebug)
+	repaired.Outcome = classifyOutcome(s.cfg.Stage3Review.OutcomeCheck, resp, repaired, parsed, changes)
+	metrics.ReviewOutcomes.WithLabelValues(repaired.Outcome, "repair").Inc()
+	remaining := schemaProblems(cleanJSON(resp.Choices[0].Message.Content))
+	slog.Info("review repaired", "problems", len(problems), "remaining", len(remaining), "outcome", repaired.Outcome)
+
+	if !parsed {
+		metrics.SchemaRepairs.WithLabelValues("unparseable").Inc()
+		result.Usage.Add(repaired.Usage)
+		return result, first
+	}
+	if len(remaining) > 0 {
+		metrics.SchemaRepairs.WithLabelValues("partial").Inc()
+	} else {
+		metrics.SchemaRepairs.WithLabelValues("repaired").Inc()
+	}
+	repaired.Usage.Add(result.Usage)
+	repaired.Repaired = true
+	return repaired, resp
+}
@@ -0,0 +1,78 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/domain"
+)
+
+func TestSchemaProblems(t *testing.T) {
+	tests := []struct {
+		name string
+		json string
+		want []string
+	}{
+		{name: "valid v1", json: `{"comments": [{"path": "a.go", "line": 3, "message": "leak", "severity": "CRITICAL"}], "score": 80, "summary": "ok"}`},
+		{name: "valid v2 range", json: `{"version": "v2", "comments": [{"path": "a.go", "line": [3, 5], "end_line": 5, "message": "leak", "severity": "NIT", "confidence": "high"}], "score": 80, "summary": "ok"}`},
+		{name: "not json", json: `{"comments": [`, want: []string{"not valid JSON"}},
+		{name: "missing fields", json: `{"comments": []}`, want: []string{"$.score is required", "$.summary is required"}},
+		{
+			name: "bad comment",
+			json: `{"comments": [{"path": "", "line": -1, "message": "x", "severity": "warning"}], "score": 80.5, "summary": "ok"}`,
+			want: []string{"$.comments[0].line must be at least 0", "$.comments[0].path must not be empty", "$.comments[0].severity must be one of CRITICAL, WARNING, INFO, NIT, got warning", "$.score must be integer"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := schemaProblems(tt.json)
+			if len(got) != len(tt.want) {
+				t.Fatalf("schemaProblems() = %q, want %q", got, tt.want)
+			}
+			for i := range got {
+				if !strings.Contains(got[i], tt.want[i]) {
+					t.Errorf("problem %d = %q, want %q", i, got[i], tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestStage3_RepairsSchemaViolation(t *testing.T) {
+	cfg := validConfig(t)
+	cfg.Pipeline.Stage3Review.SchemaRepair.Enabled = true
+	llm := &scriptedLLM{responses: []string{
+		`{"comments": [{"path": "a.go", "line": 3, "message": "unchecked error", "severity": "high"}], "summary": "One issue."}`,
+		`{"comments": [{"path": "a.go", "line": 3, "message": "unchecked error", "severity": "WARNING"}], "score": 70, "summary": "One issue."}`,
+	}}
+	s3 := NewStage3(&cfg.Pipeline, nil, llm, NewPromptLoader(cfg.Prompts.Dir))
+
+	result, err := s3.Review(context.Background(), ReviewRequest{PR: domain.PullRequest{ID: "1"}}, addedLines(3), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(llm.requests) != 2 {
+		t.Fatalf("expected one repair request, got %d calls", len(llm.requests))
+	}
+	repair := llm.requests[1].Messages
+	prompt := repair[len(repair)-1].OfUser.Content.OfString.Value
+	for _, want := range []string{"$.score is required", "$.comments[0].severity must be one of", `"required": ["comments", "score", "summary"]`} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("repair prompt lacks %q:\n%s", want, prompt)
+		}
+	}
+	if !result.Repaired || result.Score != 70 || result.Comments[0].Severity != domain.CommentSeverityWarning || result.Usage.CompletionTokens != 20 {
+		t.Errorf("expected the repaired review, got %+v", result)
+	}
+	if !result.Report.Chunks[0].Repaired {
+		t.Errorf("chunk report = %+v", result.Report.Chunks[0])
+	}
+
+	// A valid answer is used as is
+	llm = &scriptedLLM{responses: []string{llm.responses[1]}}
+	s3 = NewStage3(&cfg.Pipeline, nil, llm, NewPromptLoader(cfg.Prompts.Dir))
+	if result, err = s3.Review(context.Background(), ReviewRequest{PR: domain.PullRequest{ID: "1"}}, addedLines(3), nil); err != nil || result.Repaired || len(llm.requests) != 1 {
+		t.Errorf("valid answer: err=%v repaired=%v calls=%d", err, result.Repaired, len(llm.requests))
+	}
+}
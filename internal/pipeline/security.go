@@ -0,0 +1,44 @@
+package pipeline
+
+import (
+	"context"
+	"log/slog"
+	"strings"
+
+	"pr-review-automation/internal/aggregator"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/rules"
+)
+
+// securityEnabled reports whether the PR gets the security pass of pipeline.stage3_review.security
+func (pa *PipelineAdapter) securityEnabled(pr *domain.PullRequest) bool {
+	cfg := pa.pipeline.cfg.Pipeline.Stage3Review.Security
+	return cfg.Enabled && rules.MatchAny(cfg.Repos, pr.ProjectKey+"/"+pr.RepoSlug)
+}
+
+// securityReview reviews the changes again with the security prompt and adds the findings to
+// result as security findings. Where both passes report the same issue, the security finding is
+// kept. A failed pass is logged and leaves result unchanged.
+func (pa *PipelineAdapter) securityReview(ctx context.Context, stage3 Stage3Reviewer, req ReviewRequest, changes []FileChange, contextFiles []FileContent, result *domain.ReviewResult) {
+	req.Prompt = pa.pipeline.cfg.Pipeline.Stage3Review.Security.PromptTemplate
+	sec, err := stage3.Review(ctx, req, changes, contextFiles)
+	if err != nil {
+		slog.Warn("security review failed", "pr_id", req.PR.ID, "error", err)
+		metrics.SecurityReviews.WithLabelValues("error").Inc()
+		return
+	}
+	metrics.SecurityReviews.WithLabelValues("success").Inc()
+
+	comments := make([]domain.ReviewComment, 0, len(sec.Comments)+len(result.Comments))
+	for _, c := range sec.Comments {
+		c.Category = domain.CommentCategorySecurity
+		metrics.SecurityFindings.WithLabelValues(strings.ToUpper(c.Severity)).Inc()
+		comments = append(comments, c)
+	}
+	result.Comments = aggregator.Deduplicate(append(comments, result.Comments...))
+	if s := strings.TrimSpace(sec.Summary); s != "" {
+		result.Summary = strings.TrimSpace(result.Summary + "\n\n**Security review:** " + s)
+	}
+	slog.Info("security review completed", "pr_id", req.PR.ID, "findings", len(sec.Comments))
+}
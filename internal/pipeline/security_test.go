@@ -0,0 +1,58 @@
+package pipeline
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/domain"
+)
+
+func TestPipelineAdapter_SecurityReview(t *testing.T) {
+	main := `{"summary": "Adds a lookup by name.", "score": 85, "comments": [
+		{"path": "db/users.go", "line": 2, "severity": "WARNING", "message": "The query concatenates name into SQL, which allows injection."},
+		{"path": "db/users.go", "line": 3, "severity": "NIT", "message": "Rename rows to users."}]}`
+	security := `{"summary": "User input reaches a SQL query.", "score": 40, "comments": [
+		{"path": "db/users.go", "line": 2, "severity": "CRITICAL", "message": "The query concatenates name into SQL, which allows SQL injection."}]}`
+	cfg := validConfig(t)
+	cfg.Pipeline.Stage3Review.Security.Enabled = true
+	cfg.Pipeline.Stage3Review.Security.PromptTemplate = "pipeline/stage3_security.md"
+	cfg.Pipeline.Stage3Review.Security.Repos = []string{"PAY/*"}
+	llm := &scriptedLLM{responses: []string{main, security, main}}
+	pa := NewPipelineAdapter(cfg, nil, llm, NewPromptLoader(cfg.Prompts.Dir))
+	diff := staticDiff{changes: []FileChange{{Path: "db/users.go", HunkLines: []string{"+q := \"SELECT * FROM users WHERE name='\" + name + \"'\"", "+rows, _ := db.Query(q)"}}}}
+	pa.pipeline.stage1, pa.pipeline.stage2 = diff, diff
+
+	pr := domain.PullRequest{ID: "1", ProjectKey: "PAY", RepoSlug: "api", Title: "Find users by name"}
+	result, err := pa.ReviewPR(context.Background(), &domain.ReviewRequest{PR: &pr})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(llm.requests) != 2 || !strings.Contains(llm.requests[1].Messages[0].OfSystem.Content.OfString.Value, "application security engineer") {
+		t.Fatalf("security pass not run with its prompt (%d requests)", len(llm.requests))
+	}
+	// The repeated injection finding is kept once, as a CRITICAL security finding
+	if len(result.Comments) != 2 {
+		t.Fatalf("comments = %+v", result.Comments)
+	}
+	for _, c := range result.Comments {
+		if c.Line == 2 && (c.Category != domain.CommentCategorySecurity || c.Severity != "CRITICAL") {
+			t.Errorf("injection finding = %+v", c)
+		}
+		if c.Line == 3 && c.Category != "" {
+			t.Errorf("style finding marked as security: %+v", c)
+		}
+	}
+	if !strings.Contains(result.Summary, "**Security review:** User input reaches a SQL query.") {
+		t.Errorf("summary = %q", result.Summary)
+	}
+
+	// Other projects are reviewed once
+	other := domain.PullRequest{ID: "2", ProjectKey: "WEB", RepoSlug: "site", Title: "Find users by name"}
+	if _, err := pa.ReviewPR(context.Background(), &domain.ReviewRequest{PR: &other}); err != nil {
+		t.Fatal(err)
+	}
+	if len(llm.requests) != 3 {
+		t.Errorf("requests = %d, want no security pass for WEB/site", len(llm.requests))
+	}
+}
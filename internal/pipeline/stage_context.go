@@ -46,8 +46,12 @@ func (s *Stage2) CollectContext(ctx context.Context, req ReviewRequest, changes
 		wg.Add(1)
 		go func(c FileChange) {
 			defer wg.Done()
-			semaphore <- struct{}{}
-			defer func() { <-semaphore }()
+			select {
+			case semaphore <- struct{}{}:
+				defer func() { <-semaphore }()
+			case <-ctx.Done():
+				return
+			}
 
 			content, err := s.fetchFileContent(ctx, req.PR, c.Path, req.LatestCommit)
 			if err != nil {
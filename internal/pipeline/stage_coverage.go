@@ -0,0 +1,238 @@
+package pipeline
+
+import (
+	"fmt"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/rules"
+)
+
+// coverageMaxFunctions bounds the functions listed and given a skeleton case per file
+const coverageMaxFunctions = 5
+
+// coverageLanguage knows where the tests of a source file live and how to write them
+type coverageLanguage struct {
+	name     string
+	function *regexp.Regexp // Captures an optional receiver or class (1) and the function name (2)
+	isTest   func(file string) bool
+	testFile func(file string) string // Conventional test file of a source file
+	skeleton func(file string, functions []string) string
+}
+
+// coverageLanguages are the languages checked by the test coverage stage, by file extension
+var coverageLanguages = map[string]coverageLanguage{
+	".go": {
+		name:     "go",
+		function: regexp.MustCompile(`^func\s+(?:\(\s*\w*\s*\*?\s*([A-Za-z_]\w*)(?:\[[^\]]*\])?\s*\)\s*)?([A-Za-z_]\w*)\s*[\[(]`),
+		isTest:   func(f string) bool { return strings.HasSuffix(f, "_test.go") },
+		testFile: func(f string) string { return strings.TrimSuffix(f, ".go") + "_test.go" },
+		skeleton: goTestSkeleton,
+	},
+	".java": {
+		name:     "java",
+		function: regexp.MustCompile(`^\s*()(?:(?:public|protected|private|static|final|synchronized|abstract|default)\s+)+[\w<>\[\],.? ]+?\s+([a-z]\w*)\s*\(`),
+		isTest: func(f string) bool {
+			base := strings.TrimSuffix(path.Base(f), ".java")
+			return strings.Contains(f, "src/test/") || strings.HasSuffix(base, "Test") || strings.HasSuffix(base, "Tests") || strings.HasSuffix(base, "IT")
+		},
+		testFile: func(f string) string {
+			return strings.Replace(strings.TrimSuffix(f, ".java"), "src/main/", "src/test/", 1) + "Test.java"
+		},
+		skeleton: javaTestSkeleton,
+	},
+	".py": {
+		name:     "python",
+		function: regexp.MustCompile(`^\s*()(?:async\s+)?def\s+([A-Za-z]\w*)\s*\(`),
+		isTest: func(f string) bool {
+			base := path.Base(f)
+			return strings.HasPrefix(base, "test_") || strings.HasSuffix(base, "_test.py") || strings.Contains("/"+f, "/tests/")
+		},
+		testFile: func(f string) string { return path.Join(path.Dir(f), "test_"+path.Base(f)) },
+		skeleton: pythonTestSkeleton,
+	},
+}
+
+// hunkHeader matches a hunk header and captures the enclosing declaration git adds after it
+var hunkHeader = regexp.MustCompile(`^@@ [^@]* @@\s?(.*)$`)
+
+// coverageGaps lists the changed source files whose tests were not changed in the same PR,
+// with the functions the PR changes in them (pipeline.test_coverage)
+func coverageGaps(cfg config.TestCoverageConfig, changes []FileChange) []domain.CoverageGap {
+	var tests []string
+	for _, c := range changes {
+		if lang, ok := coverageLanguages[path.Ext(c.Path)]; ok && lang.isTest(c.Path) {
+			tests = append(tests, c.Path)
+		}
+	}
+
+	var gaps []domain.CoverageGap
+	for _, c := range changes {
+		lang, ok := coverageLanguages[path.Ext(c.Path)]
+		if !ok || lang.isTest(c.Path) || (len(cfg.Ignore) > 0 && rules.MatchAny(cfg.Ignore, c.Path)) {
+			continue
+		}
+		if hasChangedTest(lang, c.Path, tests) {
+			continue
+		}
+		functions := changedFunctions(lang, c.HunkLines)
+		if len(functions) == 0 {
+			continue
+		}
+		gap := domain.CoverageGap{File: c.Path, TestFile: lang.testFile(c.Path), Functions: functions}
+		if cfg.Skeletons {
+			gap.Language = lang.name
+			gap.Skeleton = lang.skeleton(c.Path, functions)
+		}
+		metrics.CoverageGaps.WithLabelValues(lang.name).Inc()
+		gaps = append(gaps, gap)
+		if len(gaps) == cfg.MaxFiles {
+			break
+		}
+	}
+	return gaps
+}
+
+// hasChangedTest reports whether the PR changes a test of file: for Go a test in the same
+// package directory, otherwise a test named after the file
+func hasChangedTest(lang coverageLanguage, file string, tests []string) bool {
+	base := strings.TrimSuffix(path.Base(file), path.Ext(file))
+	for _, t := range tests {
+		switch lang.name {
+		case "go":
+			if path.Dir(t) == path.Dir(file) {
+				return true
+			}
+		case "java":
+			tb := strings.TrimSuffix(path.Base(t), ".java")
+			if tb == base+"Test" || tb == base+"Tests" || tb == base+"IT" {
+				return true
+			}
+		case "python":
+			if tb := path.Base(t); tb == "test_"+base+".py" || tb == base+"_test.py" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// changedFunctions returns the functions a file diff adds or changes: declarations on added
+// lines and the enclosing declarations git names in the headers of hunks that add lines
+func changedFunctions(lang coverageLanguage, lines []string) []string {
+	var functions []string
+	add := func(decl string) {
+		m := lang.function.FindStringSubmatch(decl)
+		if m == nil || len(functions) == coverageMaxFunctions {
+			return
+		}
+		name := m[2]
+		if m[1] != "" {
+			name = m[1] + "." + name
+		}
+		if !slices.Contains(functions, name) {
+			functions = append(functions, name)
+		}
+	}
+
+	header, headerAdds := "", false
+	for _, l := range lines {
+		switch {
+		case strings.HasPrefix(l, "@@"):
+			if headerAdds {
+				add(header)
+			}
+			header, headerAdds = "", false
+			if m := hunkHeader.FindStringSubmatch(l); m != nil {
+				header = m[1]
+			}
+		case strings.HasPrefix(l, "+++"):
+		case strings.HasPrefix(l, "+"):
+			headerAdds = true
+			add(l[1:])
+		}
+	}
+	if headerAdds {
+		add(header)
+	}
+	return functions
+}
+
+// goTestSkeleton is a table-driven Go test per function
+func goTestSkeleton(file string, functions []string) string {
+	var sb strings.Builder
+	for i, f := range functions {
+		if i > 0 {
+			sb.WriteString("\n")
+		}
+		call := f
+		if typ, method, ok := strings.Cut(f, "."); ok {
+			f, call = typ+"_"+method, "("+typ+")."+method
+		}
+		fmt.Fprintf(&sb, `func Test%s(t *testing.T) {
+	tests := []struct {
+		name string
+		// inputs and expected results
+	}{
+		{name: "TODO"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			// call %s and compare with the expected results
+		})
+	}
+}
+`, capitalize(f), call)
+	}
+	return sb.String()
+}
+
+// javaTestSkeleton is a JUnit 5 parameterized test per method
+func javaTestSkeleton(file string, functions []string) string {
+	class := strings.TrimSuffix(path.Base(file), ".java")
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "class %sTest {\n", class)
+	for _, f := range functions {
+		fmt.Fprintf(&sb, `
+    @ParameterizedTest
+    @CsvSource({
+        // "input, expected",
+    })
+    void %s(String input, String expected) {
+        // call %s.%s and assert the expected result
+    }
+`, f, class, f)
+	}
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// pythonTestSkeleton is a parametrized pytest test per function
+func pythonTestSkeleton(file string, functions []string) string {
+	var sb strings.Builder
+	for i, f := range functions {
+		if i > 0 {
+			sb.WriteString("\n\n")
+		}
+		fmt.Fprintf(&sb, `@pytest.mark.parametrize("args, expected", [
+    # ((...), expected),
+])
+def test_%s(args, expected):
+    assert %s(*args) == expected
+`, f, f)
+	}
+	return sb.String()
+}
+
+// capitalize upper-cases the first letter of an identifier
+func capitalize(s string) string {
+	if s == "" {
+		return s
+	}
+	return strings.ToUpper(s[:1]) + s[1:]
+}
@@ -0,0 +1,62 @@
+package pipeline
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+)
+
+func TestCoverageGaps(t *testing.T) {
+	changes := []FileChange{
+		{Path: "server/http.go", HunkLines: []string{
+			"+++ b/server/http.go",
+			"@@ -10,6 +10,7 @@ func (s *Server) Start() error {",
+			" \tx := 1",
+			"+\ty := 2",
+			"@@ -40,2 +41,6 @@ func helper() {",
+			"+func Parse[T any](raw string) (T, error) {",
+			"+}",
+			"@@ -80,2 +85,2 @@ func untouched() {",
+			"-\tremoved()",
+		}},
+		{Path: "store/db.go", HunkLines: []string{"+func Open() {}"}},
+		{Path: "store/db_test.go", HunkLines: []string{"+func TestOpen(t *testing.T) {}"}},
+		{Path: "src/main/java/com/acme/Billing.java", HunkLines: []string{"+    public BigDecimal total(List<Item> items) {", "+    if (x) {"}},
+		{Path: "src/main/java/com/acme/Tax.java", HunkLines: []string{"+    public int rate() {"}},
+		{Path: "src/test/java/com/acme/TaxTest.java", HunkLines: []string{"+    void rate() {}"}},
+		{Path: "app/pricing.py", HunkLines: []string{"+async def quote(order):", "+    return 1"}},
+		{Path: "gen/api.pb.go", HunkLines: []string{"+func Marshal() {}"}},
+		{Path: "README.md", HunkLines: []string{"+# Title"}},
+	}
+	gaps := coverageGaps(config.TestCoverageConfig{MaxFiles: 5, Skeletons: true, Ignore: []string{"*.pb.go"}}, changes)
+
+	var got []string
+	for _, g := range gaps {
+		got = append(got, fmt.Sprintf("%s->%s %v", g.File, g.TestFile, g.Functions))
+	}
+	want := []string{
+		"server/http.go->server/http_test.go [Server.Start Parse helper]",
+		"src/main/java/com/acme/Billing.java->src/test/java/com/acme/BillingTest.java [total]",
+		"app/pricing.py->app/test_pricing.py [quote]",
+	}
+	if fmt.Sprint(got) != fmt.Sprint(want) {
+		t.Errorf("gaps:\n%s\nwant:\n%s", strings.Join(got, "\n"), strings.Join(want, "\n"))
+	}
+	if s := gaps[0].Skeleton; !strings.Contains(s, "func TestServer_Start(t *testing.T) {") || !strings.Contains(s, "// call (Server).Start") || !strings.Contains(s, "func TestParse(") {
+		t.Errorf("go skeleton:\n%s", s)
+	}
+	if s := gaps[1].Skeleton; !strings.Contains(s, "class BillingTest {") || !strings.Contains(s, "@ParameterizedTest") {
+		t.Errorf("java skeleton:\n%s", s)
+	}
+	if s := gaps[2].Skeleton; !strings.Contains(s, "def test_quote(args, expected):") {
+		t.Errorf("python skeleton:\n%s", s)
+	}
+
+	// Without skeletons only the list remains, up to max_files
+	gaps = coverageGaps(config.TestCoverageConfig{MaxFiles: 1}, changes)
+	if len(gaps) != 1 || gaps[0].Skeleton != "" {
+		t.Errorf("gaps = %+v", gaps)
+	}
+}
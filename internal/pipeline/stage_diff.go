@@ -36,29 +36,10 @@ func NewStage1(cfg *config.PipelineConfig, mcpClient *client.MCPClient, llm LLMC
 func (s *Stage1) ExtractDiffs(ctx context.Context, req ReviewRequest) ([]FileChange, error) {
 	slog.Info("Stage 1: Starting Diff Extraction", "pr_id", req.PR.ID)
 
-	// 1. Execute Tool: Get Diff
-	// We default to bitbucket_get_pull_request_diff as it is the primary tool.
-	// In a future advanced version, we could use LLM to decide the tool,
-	// but for "Diff Extraction" stage, it is deterministic enough.
-
-	prID, err := strconv.Atoi(req.PR.ID)
-	if err != nil {
-		return nil, fmt.Errorf("invalid pull request ID: %w", err)
-	}
-
-	diffResult, err := s.mcpClient.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetDiff, map[string]interface{}{
-		"projectKey":    req.PR.ProjectKey,
-		"repoSlug":      req.PR.RepoSlug,
-		"pullRequestId": prID,
-	})
+	// 1-2. Get the diff, shared with the processor when it fetched it already
+	diffStr, err := s.getDiff(ctx, req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get diff: %w", err)
-	}
-
-	// 2. Extract Diff String
-	diffStr := ExtractString(diffResult, "content.0.text", "output.diff", "output.text", "output", "diff")
-	if diffStr == "" {
-		return nil, fmt.Errorf("empty diff content extracted")
+		return nil, err
 	}
 
 	// [Fix] Handle case where tool returns JSON-wrapped diff (e.g. {"diff": "..."}) inside the text content
@@ -78,13 +59,56 @@ func (s *Stage1) ExtractDiffs(ctx context.Context, req ReviewRequest) ([]FileCha
 	}
 
 	// 3. Parse Diff into FileChanges
+	changes := ParseDiff(diffStr)
+
+	slog.Info("Stage 1: Completed", "files_changed", len(changes))
+	return changes, nil
+}
+
+// getDiff returns the PR diff from the request's cache, or fetches it with the get-diff tool
+func (s *Stage1) getDiff(ctx context.Context, req ReviewRequest) (string, error) {
+	if req.Cache != nil {
+		diff, err := req.Cache.Diff(ctx)
+		if err != nil {
+			return "", fmt.Errorf("failed to get diff: %w", err)
+		}
+		return diff, nil
+	}
+
+	// We default to bitbucket_get_pull_request_diff as it is the primary tool.
+	// In a future advanced version, we could use LLM to decide the tool,
+	// but for "Diff Extraction" stage, it is deterministic enough.
+	prID, err := strconv.Atoi(req.PR.ID)
+	if err != nil {
+		return "", fmt.Errorf("invalid pull request ID: %w", err)
+	}
+
+	diffResult, err := s.mcpClient.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetDiff, map[string]interface{}{
+		"projectKey":    req.PR.ProjectKey,
+		"repoSlug":      req.PR.RepoSlug,
+		"pullRequestId": prID,
+	})
+	if err != nil {
+		return "", fmt.Errorf("failed to get diff: %w", err)
+	}
+
+	diffStr := ExtractString(diffResult, "content.0.text", "output.diff", "output.text", "output", "diff")
+	if diffStr == "" {
+		return "", fmt.Errorf("empty diff content extracted")
+	}
+	return diffStr, nil
+}
+
+// ParseDiff cleans up noise (whitespace-only changes, long deletions) in a unified diff and
+// splits it into per-file changes, as Stage 1 sends them to the model
+func ParseDiff(diff string) []FileChange {
 	preprocessor := splitter.NewDiffPreprocessor(splitter.PreprocessOptions{
 		RemoveWhitespace: true,
 		FoldDeletesOver:  10,
 	})
 
 	// Preprocess first to clean up noise
-	cleanDiff := preprocessor.Preprocess(diffStr)
+	cleanDiff := preprocessor.Preprocess(diff)
 
 	// Split into per-file chunks
 	fileDiffStrs := preprocessor.SplitByFile(cleanDiff)
@@ -98,7 +122,5 @@ func (s *Stage1) ExtractDiffs(ctx context.Context, req ReviewRequest) ([]FileCha
 			HunkLines:  strings.Split(fdStr, "\n"),
 		})
 	}
-
-	slog.Info("Stage 1: Completed", "files_changed", len(changes))
-	return changes, nil
+	return changes
 }
@@ -0,0 +1,30 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestStage1_UsesSharedDiff(t *testing.T) {
+	s1 := NewStage1(&config.PipelineConfig{}, nil, nil, nil)
+	cache := domain.NewReviewContext(func(ctx context.Context) (string, error) {
+		return `{"diff": "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -0,0 +1 @@\n+package a\n"}`, nil
+	})
+
+	changes, err := s1.ExtractDiffs(context.Background(), ReviewRequest{PR: domain.PullRequest{ID: "1"}, Cache: cache})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(changes) != 1 || changes[0].Path != "a.go" {
+		t.Errorf("changes = %+v", changes)
+	}
+
+	failing := domain.NewReviewContext(func(ctx context.Context) (string, error) { return "", errors.New("host down") })
+	if _, err := s1.ExtractDiffs(context.Background(), ReviewRequest{PR: domain.PullRequest{ID: "1"}, Cache: failing}); err == nil {
+		t.Error("expected the fetch error")
+	}
+}
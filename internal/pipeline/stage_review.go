@@ -13,9 +13,11 @@ import (
 	"pr-review-automation/internal/client"
 	"pr-review-automation/internal/config"
 	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/llm"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/tokenizer"
 
 	"github.com/openai/openai-go"
-	"github.com/openai/openai-go/shared"
 )
 
 // Stage3 implements the Direct Review stage
@@ -25,20 +27,42 @@ type Stage3 struct {
 	llm                LLMClient
 	promptLoader       *PromptLoader
 	degradationManager *DegradationManager
+	tuner              *ChunkTuner         // Optional: per-repository budget and context lines
+	contract           string              // Review contract version the prompt asks for
+	tokens             tokenizer.Tokenizer // Token counting for the stage's model
 }
 
 // NewStage3 creates a new Stage3 instance
 func NewStage3(cfg *config.PipelineConfig, mcpClient *client.MCPClient, llm LLMClient, promptLoader *PromptLoader) *Stage3 {
-	chunkReviewer := NewChunkReviewer(cfg.Stage3Review.MaxContextTokens)
-	dm := NewDegradationManager(cfg.Stage3Review.Degradation, cfg.Stage3Review.MaxContextTokens, chunkReviewer)
+	s := &Stage3{
+		cfg:          cfg,
+		mcpClient:    mcpClient,
+		llm:          llm,
+		promptLoader: promptLoader,
+		contract:     cfg.Stage3Review.Contract,
+		tokens:       tokenizer.Default(),
+	}
+	s.degradationManager = s.newDegradationManager(cfg.Stage3Review.Degradation, cfg.Stage3Review.MaxContextTokens)
+	return s
+}
 
-	return &Stage3{
-		cfg:                cfg,
-		mcpClient:          mcpClient,
-		llm:                llm,
-		promptLoader:       promptLoader,
-		degradationManager: dm,
+// newDegradationManager creates a degradation manager that counts tokens for the stage's model
+func (s *Stage3) newDegradationManager(cfg config.DegradationConfig, maxTokens int) *DegradationManager {
+	chunkReviewer := NewChunkReviewer(maxTokens)
+	dm := NewDegradationManager(cfg, maxTokens, chunkReviewer)
+	if s.tokens != nil {
+		chunkReviewer.tokens = s.tokens
+		dm.tokens = s.tokens
 	}
+	if s.cfg.Stage3Review.Synthesis.Enabled {
+		chunkReviewer.reduce = s.synthesize
+	}
+	return dm
+}
+
+// SetTuner enables adaptive per-repository tuning of the token budget and L1 context lines
+func (s *Stage3) SetTuner(t *ChunkTuner) {
+	s.tuner = t
 }
 
 // Review implements the Stage3Reviewer interface
@@ -49,33 +73,100 @@ func (s *Stage3) Review(ctx context.Context, req ReviewRequest, changes []FileCh
 	baseData := map[string]interface{}{
 		"PR":           req.PR,
 		"ResultFormat": s.getResultFormat(),
+		"Contract":     s.contract,
 		"Changes":      []FileChange{},
 		"Context":      []FileContent{},
+		"Standards":    req.Standards,
 	}
-	baseSystemPrompt, err := s.promptLoader.LoadPrompt(s.cfg.Stage3Review.PromptTemplate, baseData)
+	promptTemplate := s.promptTemplate(req, baseData)
+	baseSystemPrompt, err := s.promptLoader.LoadPrompt(promptTemplate, baseData)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load base prompt for estimation: %w", err)
 	}
 
-	// 2. Delegate to DegradationManager
-	return s.degradationManager.ApplyStrategy(
-		ctx, req, changes, contextFiles,
-		s.cfg.Stage3Review.PromptTemplate,
-		baseSystemPrompt,
-		s.reviewCore,
-	)
+	// 2. Delegate to DegradationManager, tuned for this repository when enabled.
+	// Reviews with a per-review token budget are neither tuned nor observed by the tuner.
+	overrides := req.PR.Overrides
+	if overrides == nil {
+		overrides = &domain.ReviewOverrides{}
+	}
+	tuner := s.tuner
+	if overrides.ChunkTokens > 0 || overrides.Direct {
+		tuner = nil
+	}
+	dm := s.degradationManager
+	limit := s.contextLimit()
+	switch {
+	case overrides.ChunkTokens > 0:
+		dm = s.newDegradationManager(s.cfg.Stage3Review.Degradation, overrides.ChunkTokens)
+	case tuner != nil:
+		maxTokens, contextLines := tuner.Params(ctx, req.PR.ProjectKey, req.PR.RepoSlug)
+		maxTokens = min(maxTokens, limit)
+		dcfg := s.cfg.Stage3Review.Degradation
+		dcfg.L1ContextLines = contextLines
+		dm = s.newDegradationManager(dcfg, maxTokens)
+	case limit < s.cfg.Stage3Review.MaxContextTokens:
+		dm = s.newDegradationManager(s.cfg.Stage3Review.Degradation, limit)
+	}
+
+	// Stream into a per-review debug artifact when enabled and supported by the client
+	reviewFunc := s.reviewCore
+	var streamDebug *streamLog
+	if s.streamer() != nil {
+		streamDebug = openStreamLog(s.cfg.Stage3Review.StreamDebug, req.PR)
+	}
+	if streamDebug != nil {
+		defer streamDebug.Close()
+		reviewFunc = func(ctx context.Context, req ReviewRequest, changes []FileChange, contextFiles []FileContent) (*domain.ReviewResult, error) {
+			return s.review(ctx, req, changes, contextFiles, streamDebug)
+		}
+	}
+
+	var result *domain.ReviewResult
+	if overrides.Direct {
+		slog.Info("Stage 3: direct review requested, skipping degradation", "pr_id", req.PR.ID)
+		result, err = reviewSingle(ctx, domain.StrategyFull, req, changes, contextFiles, reviewFunc)
+	} else {
+		result, err = dm.ApplyStrategy(
+			ctx, req, changes, contextFiles,
+			promptTemplate,
+			baseSystemPrompt,
+			reviewFunc,
+		)
+	}
+	if streamDebug != nil {
+		slog.Info("stream debug artifact written", "pr_id", req.PR.ID, "path", streamDebug.path)
+		if result != nil && result.Report != nil {
+			result.Report.DebugArtifact = streamDebug.path
+		}
+	}
+	if tuner != nil {
+		var report *domain.ExecutionReport
+		if result != nil {
+			report = result.Report
+		}
+		tuner.Observe(ctx, req.PR.ProjectKey, req.PR.RepoSlug, report, err)
+	}
+	return result, err
 }
 
 // reviewCore executes the actual LLM review
 func (s *Stage3) reviewCore(ctx context.Context, req ReviewRequest, changes []FileChange, contextFiles []FileContent) (*domain.ReviewResult, error) {
+	return s.review(ctx, req, changes, contextFiles, nil)
+}
+
+// review executes the LLM review; with a stream log, the completion is streamed into it
+func (s *Stage3) review(ctx context.Context, req ReviewRequest, changes []FileChange, contextFiles []FileContent, streamDebug *streamLog) (*domain.ReviewResult, error) {
 	slog.Info("Stage 3: Executing Core Review", "files_changed", len(changes), "context_files", len(contextFiles))
 
 	// 1. Prepare Prompt Data
 	data := map[string]interface{}{
 		"PR":           req.PR,
 		"ResultFormat": s.getResultFormat(),
+		"Contract":     s.contract,
 		"Changes":      changes,
 		"Context":      contextFiles,
+		"Standards":    req.Standards,
 	}
 
 	// 2. Load System Prompt
@@ -84,7 +175,7 @@ func (s *Stage3) reviewCore(ctx context.Context, req ReviewRequest, changes []Fi
 	data["LanguageRules"] = lRules
 	data["Language"] = lNames
 
-	systemPromptStr, err := s.promptLoader.LoadPrompt(s.cfg.Stage3Review.PromptTemplate, data)
+	systemPromptStr, err := s.promptLoader.LoadPrompt(s.promptTemplate(req, data), data)
 	if err != nil {
 		return nil, fmt.Errorf("failed to load stage 3 prompt: %w", err)
 	}
@@ -93,20 +184,77 @@ func (s *Stage3) reviewCore(ctx context.Context, req ReviewRequest, changes []Fi
 	userMessage := fmt.Sprintf("Review PR %s: %s", req.PR.ID, req.PR.Title)
 
 	// 4. Call LLM
-	// Construct request using OpenAI types
-	val := shared.NewResponseFormatJSONObjectParam()
+	// Construct request using OpenAI types. Models without JSON mode answer from the prompt's
+	// format instructions; parseResult strips the markdown fences they tend to add.
 	params := openai.ChatCompletionNewParams{
 		Messages: []openai.ChatCompletionMessageParamUnion{
 			openai.SystemMessage(systemPromptStr),
 			openai.UserMessage(userMessage),
 		},
 		Temperature: openai.Float(s.cfg.Stage3Review.Temperature),
-		ResponseFormat: openai.ChatCompletionNewParamsResponseFormatUnion{
-			OfJSONObject: &val,
-		},
+	}
+	s.setResponseFormat(&params)
+	llm.ApplyParams(&params, s.cfg.Stage3Review.Params, true)
+	if o := req.PR.Overrides; o != nil && o.Model != "" {
+		params.Model = openai.ChatModel(o.Model)
+	}
+
+	var result *domain.ReviewResult
+	if s.cfg.Stage3Review.Ensemble.Runs > 1 {
+		result, err = s.ensemble(ctx, params, changes, streamDebug)
+	} else {
+		result, err = s.answer(ctx, params, changes, streamDebug)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	slog.Info("Stage 3: Completed", "comments_generated", len(result.Comments), "outcome", result.Outcome)
+	return result, nil
+}
+
+// answer sends the review request and parses the response, retrying a refusal, empty or
+// low-content review once
+func (s *Stage3) answer(ctx context.Context, params openai.ChatCompletionNewParams, changes []FileChange, streamDebug *streamLog) (*domain.ReviewResult, error) {
+	resp, err := s.complete(ctx, params, changes, streamDebug)
+	if err != nil {
+		return nil, err
+	}
+
+	// Parse Result and classify refusals, empty and low-content reviews
+	result, parsed := s.parseResult(resp, streamDebug)
+	result.Outcome = classifyOutcome(s.cfg.Stage3Review.OutcomeCheck, resp, result, parsed, changes)
+	metrics.ReviewOutcomes.WithLabelValues(result.Outcome, "first").Inc()
+
+	// Ask once for corrected JSON when the answer does not match the result schema
+	if s.cfg.Stage3Review.SchemaRepair.Enabled && (parsed || result.Outcome == domain.OutcomeUnparseable) {
+		if problems := schemaProblems(cleanJSON(resp.Choices[0].Message.Content)); len(problems) > 0 {
+			result, resp = s.repairReview(ctx, params, resp, result, problems, changes, streamDebug)
+		}
 	}
 
-	resp, err := s.llm.Chat(ctx, params)
+	// Retry such a review once with a reinforcement prompt
+	if retryable(result.Outcome) && s.cfg.Stage3Review.OutcomeCheck.Retry {
+		result = s.retryReview(ctx, params, resp, result, changes, streamDebug)
+	}
+	return result, nil
+}
+
+// complete sends the review request, streamed into the debug artifact when one is open
+func (s *Stage3) complete(ctx context.Context, params openai.ChatCompletionNewParams, changes []FileChange, streamDebug *streamLog) (*openai.ChatCompletion, error) {
+	var resp *openai.ChatCompletion
+	var err error
+	if streamDebug != nil {
+		files := make([]string, 0, len(changes))
+		for _, c := range changes {
+			files = append(files, c.Path)
+		}
+		w := streamDebug.call(files)
+		resp, err = s.streamer().ChatStream(ctx, params, w.delta)
+		w.finish(resp, err)
+	} else {
+		resp, err = s.llm.Chat(ctx, params)
+	}
 	if err != nil {
 		return nil, fmt.Errorf("llm chat failed: %w", err)
 	}
@@ -114,22 +262,67 @@ func (s *Stage3) reviewCore(ctx context.Context, req ReviewRequest, changes []Fi
 	if len(resp.Choices) == 0 {
 		return nil, fmt.Errorf("received empty response from LLM")
 	}
+	extractToolAnswer(resp)
+	return resp, nil
+}
+
+// streamer returns the client as a StreamingClient when its model streams, or nil
+func (s *Stage3) streamer() llm.StreamingClient {
+	sc, ok := s.llm.(llm.StreamingClient)
+	if !ok || !llm.CapabilitiesOf(s.llm).Streaming {
+		return nil
+	}
+	return sc
+}
 
+// contextLimit returns the token budget of a review: max_context_tokens, lowered to fit the
+// model's context window with room for the answer when the window is known
+func (s *Stage3) contextLimit() int {
+	budget := s.cfg.Stage3Review.MaxContextTokens
+	window := llm.CapabilitiesOf(s.llm).MaxContext
+	if window <= 0 {
+		return budget
+	}
+	reserve := int64(window / config.ContextOutputReserveDivisor)
+	if p := s.cfg.Stage3Review.Params.MaxTokens; p != nil && *p > 0 {
+		reserve = *p
+	}
+	return max(min(budget, window-int(reserve)), 1)
+}
+
+// parseResult parses the review JSON of a response. A response that does not parse yields
+// a result whose summary carries the parse error, and false.
+func (s *Stage3) parseResult(resp *openai.ChatCompletion, streamDebug *streamLog) (*domain.ReviewResult, bool) {
 	responseStr := resp.Choices[0].Message.Content
+	usage := &domain.TokenUsage{
+		PromptTokens:     resp.Usage.PromptTokens,
+		CompletionTokens: resp.Usage.CompletionTokens,
+	}
 
-	// 5. Parse Result
 	var result domain.ReviewResult
 
 	// Try to clean up markdown code blocks if present (common with some models)
 	jsonStr := cleanJSON(responseStr)
 
 	if err := json.Unmarshal([]byte(jsonStr), &result); err != nil {
-		slog.Error("failed to unmarshal review result", "error", err, "response", responseStr)
+		if streamDebug != nil {
+			slog.Error("failed to unmarshal review result", "error", err, "debug_artifact", streamDebug.path)
+		} else {
+			slog.Error("failed to unmarshal review result", "error", err, "response", responseStr)
+		}
 		// Don't fail completely, return empty result with error summary
 		return &domain.ReviewResult{
 			Summary: fmt.Sprintf("Failed to parse review result: %v", err),
 			Score:   0,
-		}, nil
+			Model:   s.answeredModel(resp),
+			Usage:   usage,
+		}, false
+	}
+
+	result.Contract = responseContract(jsonStr, &result)
+	metrics.ReviewContracts.WithLabelValues(s.requestedContract(), result.Contract).Inc()
+	if result.Contract != s.requestedContract() {
+		slog.Debug("review answered with another contract", "requested", s.requestedContract(), "received", result.Contract)
 	}
 
 	// Enrich comments with file paths if missing
@@ -137,25 +330,21 @@ func (s *Stage3) reviewCore(ctx context.Context, req ReviewRequest, changes []Fi
 		if result.Comments[i].Severity == "" {
 			result.Comments[i].Severity = domain.CommentSeverityInfo // Default
 		}
+		result.Comments[i].Category = domain.NormalizeCategory(result.Comments[i].Category)
 	}
 
-	slog.Info("Stage 3: Completed", "comments_generated", len(result.Comments))
-	return &result, nil
+	result.Usage = usage
+	result.Model = s.answeredModel(resp)
+	return &result, true
 }
 
-func (s *Stage3) getResultFormat() string {
-	return `{
-  "comments": [
-    {
-      "path": "path/to/file.go",
-      "line": 42,
-      "message": "Comment text...",
-      "severity": "INFO|WARNING|CRITICAL|NIT"
-    }
-  ],
-  "score": 85,
-  "summary": "Overall review summary..."
-}`
+// answeredModel returns the model that produced resp when the client may fall back to another
+// model, or "" to record the configured one
+func (s *Stage3) answeredModel(resp *openai.ChatCompletion) string {
+	if _, ok := s.llm.(llm.FallbackClient); ok {
+		return resp.Model
+	}
+	return ""
 }
 
 // cleanJSON removes markdown code block markers if present
@@ -271,3 +460,20 @@ func (d *RuleDetector) Detect(changes []FileChange) []string {
 	sort.Strings(keys)
 	return keys
 }
+
+// promptTemplate returns the stage 3 prompt of req: the prompt of its pass when set, the
+// template chosen by the repository's settings file when it renders, or
+// pipeline.stage3_review.prompt_template otherwise
+func (s *Stage3) promptTemplate(req ReviewRequest, data map[string]interface{}) string {
+	if req.Prompt != "" {
+		return req.Prompt
+	}
+	if rc := req.PR.RepoConfig; rc != nil && rc.Prompt != "" {
+		if _, err := s.promptLoader.LoadPrompt(rc.Prompt, data); err != nil {
+			slog.Warn("repository prompt not usable, using default", "prompt", rc.Prompt, "error", err)
+		} else {
+			return rc.Prompt
+		}
+	}
+	return s.cfg.Stage3Review.PromptTemplate
+}
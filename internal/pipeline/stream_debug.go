@@ -0,0 +1,144 @@
+package pipeline
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"sync"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/rules"
+
+	"github.com/openai/openai-go"
+)
+
+// defaultRedactions match secrets the model may echo from reviewed code.
+// The first group, when present, is kept so the redacted line stays readable.
+var defaultRedactions = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)(bearer\s+)[A-Za-z0-9._~+/-]{8,}=*`),
+	regexp.MustCompile(`(?i)((?:password|passwd|secret|token|api[_-]?key)\\?["']?\s*[:=]\s*\\?["']?)[^"'\\\s,}]{4,}`),
+	regexp.MustCompile(`\b(?:sk|rk)-[A-Za-z0-9_-]{16,}`),
+	regexp.MustCompile(`\bAKIA[0-9A-Z]{16}\b`),
+	regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{30,}`),
+	regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`),
+	regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`),
+	regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----[^-]*`),
+}
+
+var unsafeFileChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// streamLog is the debug artifact of one review. Every LLM call of the review (one per chunk)
+// appends a header, the streamed output line by line as received, and how the call ended.
+type streamLog struct {
+	mu      sync.Mutex
+	f       *os.File
+	path    string
+	redacts []*regexp.Regexp
+	calls   int
+}
+
+// openStreamLog creates the artifact for a review, or returns nil when stream debugging is
+// disabled for the repository or the file cannot be created
+func openStreamLog(cfg config.StreamDebugConfig, pr domain.PullRequest) *streamLog {
+	if !cfg.Enabled || !rules.MatchAny(cfg.Repos, pr.ProjectKey+"/"+pr.RepoSlug) {
+		return nil
+	}
+
+	redacts := append([]*regexp.Regexp{}, defaultRedactions...)
+	for _, expr := range cfg.Redact {
+		re, err := regexp.Compile(expr)
+		if err != nil {
+			slog.Warn("invalid stream debug redaction", "pattern", expr, "error", err)
+			continue
+		}
+		redacts = append(redacts, re)
+	}
+
+	if err := os.MkdirAll(cfg.Dir, 0o750); err != nil {
+		slog.Warn("create stream debug dir failed", "dir", cfg.Dir, "error", err)
+		return nil
+	}
+	name := fmt.Sprintf("%s_%s_%s_%s.log", pr.ProjectKey, pr.RepoSlug, pr.ID, time.Now().UTC().Format("20060102T150405.000"))
+	path := filepath.Join(cfg.Dir, unsafeFileChars.ReplaceAllString(name, "-"))
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o640)
+	if err != nil {
+		slog.Warn("create stream debug artifact failed", "path", path, "error", err)
+		return nil
+	}
+
+	l := &streamLog{f: f, path: path, redacts: redacts}
+	l.write(fmt.Sprintf("# review %s/%s #%s commit=%s\n", pr.ProjectKey, pr.RepoSlug, pr.ID, pr.LatestCommit))
+	return l
+}
+
+// call starts the section of one LLM call
+func (l *streamLog) call(files []string) *streamWriter {
+	l.mu.Lock()
+	l.calls++
+	n := l.calls
+	l.mu.Unlock()
+	l.write(fmt.Sprintf("\n=== call %d at %s files=%s ===\n", n, time.Now().UTC().Format(time.RFC3339), strings.Join(files, ",")))
+	return &streamWriter{log: l}
+}
+
+// Close closes the artifact file
+func (l *streamLog) Close() error {
+	return l.f.Close()
+}
+
+func (l *streamLog) redact(line string) string {
+	for _, re := range l.redacts {
+		line = re.ReplaceAllString(line, "${1}[REDACTED]")
+	}
+	return line
+}
+
+func (l *streamLog) write(s string) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, err := l.f.WriteString(s); err != nil {
+		slog.Debug("write stream debug artifact failed", "path", l.path, "error", err)
+	}
+}
+
+// streamWriter appends the deltas of one call. Output is written per complete line so
+// redaction patterns also match secrets split across deltas.
+type streamWriter struct {
+	log     *streamLog
+	pending strings.Builder
+}
+
+// delta buffers a content fragment and writes the lines it completes
+func (w *streamWriter) delta(s string) {
+	w.pending.WriteString(s)
+	buf := w.pending.String()
+	i := strings.LastIndexByte(buf, '\n')
+	if i < 0 {
+		return
+	}
+	w.log.write(w.log.redact(buf[:i+1]))
+	w.pending.Reset()
+	w.pending.WriteString(buf[i+1:])
+}
+
+// finish writes the unterminated rest of the output and how the call ended
+func (w *streamWriter) finish(resp *openai.ChatCompletion, err error) {
+	if w.pending.Len() > 0 {
+		w.log.write(w.log.redact(w.pending.String()) + "\n")
+		w.pending.Reset()
+	}
+	switch {
+	case err != nil:
+		w.log.write(fmt.Sprintf("--- error: %s ---\n", w.log.redact(err.Error())))
+	case len(resp.Choices) == 0:
+		w.log.write("--- no choices ---\n")
+	default:
+		w.log.write(fmt.Sprintf("--- finish_reason=%s prompt_tokens=%d completion_tokens=%d ---\n",
+			resp.Choices[0].FinishReason, resp.Usage.PromptTokens, resp.Usage.CompletionTokens))
+	}
+}
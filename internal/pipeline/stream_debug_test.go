@@ -0,0 +1,98 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/domain"
+
+	"github.com/openai/openai-go"
+)
+
+// streamingLLM streams a fixed response in the given deltas
+type streamingLLM struct {
+	countingLLM
+	deltas []string
+}
+
+func (m *streamingLLM) ChatStream(ctx context.Context, params openai.ChatCompletionNewParams, onDelta func(string)) (*openai.ChatCompletion, error) {
+	for _, d := range m.deltas {
+		onDelta(d)
+	}
+	resp := &openai.ChatCompletion{Choices: []openai.ChatCompletionChoice{{
+		FinishReason: "length",
+		Message:      openai.ChatCompletionMessage{Content: strings.Join(m.deltas, "")},
+	}}}
+	resp.Usage.CompletionTokens = 12
+	return resp, nil
+}
+
+func TestStage3_StreamDebugArtifact(t *testing.T) {
+	cfg := validConfig(t)
+	cfg.Pipeline.Stage3Review.StreamDebug.Enabled = true
+	cfg.Pipeline.Stage3Review.StreamDebug.Dir = t.TempDir()
+	cfg.Pipeline.Stage3Review.StreamDebug.Redact = []string{`internal\.corp\.example`}
+
+	// Truncated response with a token split across deltas
+	llm := &streamingLLM{deltas: []string{
+		`{"summary": "uses `, `ghp_0123456789abcdef`, `0123456789abcdef0123 from internal.corp.example",`, "\n",
+		`"comments": [{"path": "a.go", "line": 3, "mess`,
+	}}
+	s3 := NewStage3(&cfg.Pipeline, nil, llm, NewPromptLoader(cfg.Prompts.Dir))
+	pr := domain.PullRequest{ID: "7", ProjectKey: "PAY", RepoSlug: "api", LatestCommit: "abc"}
+	changes := []FileChange{{Path: "a.go", HunkLines: []string{"+x := 1"}}}
+
+	result, err := s3.Review(context.Background(), ReviewRequest{PR: pr}, changes, nil)
+	if err != nil {
+		t.Fatalf("review: %v", err)
+	}
+	if !strings.HasPrefix(result.Summary, "Failed to parse review result") {
+		t.Errorf("expected parse failure for the truncated response, got %q", result.Summary)
+	}
+	if result.Report == nil || result.Report.DebugArtifact == "" {
+		t.Fatalf("expected debug artifact in the execution report, got %+v", result.Report)
+	}
+
+	data, err := os.ReadFile(result.Report.DebugArtifact)
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifact := string(data)
+	for _, want := range []string{
+		"# review PAY/api #7 commit=abc",
+		"=== call 1 at ",
+		"files=a.go ===",
+		`{"summary": "uses [REDACTED] from [REDACTED]",`,
+		`"comments": [{"path": "a.go", "line": 3, "mess` + "\n",
+		"--- finish_reason=length prompt_tokens=0 completion_tokens=12 ---",
+	} {
+		if !strings.Contains(artifact, want) {
+			t.Errorf("artifact missing %q:\n%s", want, artifact)
+		}
+	}
+	if strings.Contains(artifact, "ghp_") || strings.Contains(artifact, "corp") {
+		t.Errorf("secret leaked into artifact:\n%s", artifact)
+	}
+}
+
+func TestStage3_StreamDebugDisabledForRepo(t *testing.T) {
+	cfg := validConfig(t)
+	cfg.Pipeline.Stage3Review.StreamDebug.Enabled = true
+	cfg.Pipeline.Stage3Review.StreamDebug.Dir = t.TempDir()
+	cfg.Pipeline.Stage3Review.StreamDebug.Repos = []string{"OTHER/*"}
+
+	llm := &streamingLLM{}
+	s3 := NewStage3(&cfg.Pipeline, nil, llm, NewPromptLoader(cfg.Prompts.Dir))
+	pr := domain.PullRequest{ID: "7", ProjectKey: "PAY", RepoSlug: "api"}
+
+	// countingLLM answers plain chat calls with an empty completion, so the review itself fails
+	s3.Review(context.Background(), ReviewRequest{PR: pr}, []FileChange{{Path: "a.go"}}, nil)
+	if llm.calls != 1 {
+		t.Errorf("expected a plain chat call, got %d", llm.calls)
+	}
+	if entries, _ := os.ReadDir(cfg.Pipeline.Stage3Review.StreamDebug.Dir); len(entries) != 0 {
+		t.Errorf("expected no artifacts, got %d", len(entries))
+	}
+}
@@ -0,0 +1,96 @@
+package pipeline
+
+import (
+	"log/slog"
+	"slices"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/llm"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/shared"
+)
+
+// reviewToolName is the function models without strict schemas are made to call with the review
+const reviewToolName = "submit_review"
+
+// strictResultSchema returns the JSON Schema of a contract's answer in the form strict
+// structured outputs accept: every property required and no others allowed
+func strictResultSchema(contract string) map[string]any {
+	comment := map[string]any{
+		"path":     map[string]any{"type": "string"},
+		"line":     map[string]any{"type": "integer", "description": "Line in the new version of the file, 0 for the whole file"},
+		"message":  map[string]any{"type": "string"},
+		"severity": map[string]any{"type": "string", "enum": config.FindingSeverities},
+		"category": map[string]any{"type": "string", "enum": config.FindingCategories},
+	}
+	if contract == config.ReviewContractV2 {
+		comment["end_line"] = map[string]any{"type": "integer"}
+		comment["suggestion"] = map[string]any{"type": "string", "description": "Replacement code for the commented lines, or empty"}
+		comment["confidence"] = map[string]any{"type": "number", "description": "From 0 to 1"}
+	}
+	return strictObject(map[string]any{
+		"version":  map[string]any{"type": "string", "enum": []string{contract}},
+		"comments": map[string]any{"type": "array", "items": strictObject(comment)},
+		"score":    map[string]any{"type": "integer", "description": "From 0 to 100"},
+		"summary":  map[string]any{"type": "string"},
+	})
+}
+
+// strictObject is an object schema requiring exactly its properties
+func strictObject(properties map[string]any) map[string]any {
+	required := make([]string, 0, len(properties))
+	for name := range properties {
+		required = append(required, name)
+	}
+	slices.Sort(required)
+	return map[string]any{
+		"type":                 "object",
+		"properties":           properties,
+		"required":             required,
+		"additionalProperties": false,
+	}
+}
+
+// setResponseFormat asks for the review as JSON. With structured_output json_schema, models
+// with strict schemas get the contract's schema as response_format and models that call tools
+// must call submit_review with it; others, like json_object, get any JSON object.
+func (s *Stage3) setResponseFormat(params *openai.ChatCompletionNewParams) {
+	caps := llm.CapabilitiesOf(s.llm)
+	schema := s.cfg.Stage3Review.StructuredOutput == config.StructuredOutputJSONSchema
+	switch {
+	case schema && caps.StrictSchema:
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{OfJSONSchema: &shared.ResponseFormatJSONSchemaParam{
+			JSONSchema: shared.ResponseFormatJSONSchemaJSONSchemaParam{
+				Name:   "review_result",
+				Strict: openai.Bool(true),
+				Schema: strictResultSchema(s.requestedContract()),
+			},
+		}}
+	case schema && caps.Tools:
+		params.Tools = []openai.ChatCompletionToolParam{{Function: shared.FunctionDefinitionParam{
+			Name:        reviewToolName,
+			Description: openai.String("Submit the code review result"),
+			Parameters:  strictResultSchema(s.requestedContract()),
+		}}}
+		params.ToolChoice = openai.ChatCompletionToolChoiceOptionParamOfChatCompletionNamedToolChoice(
+			openai.ChatCompletionNamedToolChoiceFunctionParam{Name: reviewToolName})
+	case caps.JSONSchema:
+		val := shared.NewResponseFormatJSONObjectParam()
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{OfJSONObject: &val}
+	}
+}
+
+// extractToolAnswer moves the arguments of a submit_review call into the message content, so
+// the answer parses like any other and can be sent back on a retry or repair
+func extractToolAnswer(resp *openai.ChatCompletion) {
+	msg := &resp.Choices[0].Message
+	for _, call := range msg.ToolCalls {
+		if call.Function.Name == reviewToolName && msg.Content == "" {
+			slog.Debug("review answered through tool call", "tool", reviewToolName)
+			msg.Content = call.Function.Arguments
+			msg.ToolCalls = nil
+			return
+		}
+	}
+}
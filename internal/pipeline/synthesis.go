@@ -0,0 +1,106 @@
+package pipeline
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/llm"
+	"pr-review-automation/internal/metrics"
+
+	"github.com/openai/openai-go"
+	"github.com/openai/openai-go/shared"
+)
+
+// synthesisPromptTemplate is the prompt that combines the chunks of a chunked review
+const synthesisPromptTemplate = "pipeline/stage3_synthesis"
+
+// synthesisMaxFindings caps the findings listed in the synthesis prompt, most severe first
+const synthesisMaxFindings = 30
+
+// synthesize writes one PR-level summary and score from the results of a chunked review.
+// It implements SummaryReducer.
+func (s *Stage3) synthesize(ctx context.Context, req ReviewRequest, chunks []ChunkSummary, findings []domain.ReviewComment) (*domain.ReviewResult, error) {
+	severities := make(map[string]int)
+	for _, f := range findings {
+		severities[f.Severity]++
+	}
+	listed := append([]domain.ReviewComment(nil), findings...)
+	sort.SliceStable(listed, func(i, j int) bool {
+		return severityOrder(listed[i].Severity) < severityOrder(listed[j].Severity)
+	})
+	if len(listed) > synthesisMaxFindings {
+		listed = listed[:synthesisMaxFindings]
+	}
+
+	prompt, err := s.promptLoader.LoadPrompt(synthesisPromptTemplate, map[string]interface{}{
+		"PR":         req.PR,
+		"Chunks":     chunks,
+		"Severities": severities,
+		"Findings":   listed,
+	})
+	if err != nil {
+		metrics.SummarySyntheses.WithLabelValues("error").Inc()
+		return nil, fmt.Errorf("load synthesis prompt: %w", err)
+	}
+
+	params := openai.ChatCompletionNewParams{
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(prompt),
+			openai.UserMessage(fmt.Sprintf("Summarize the review of PR %s: %s", req.PR.ID, req.PR.Title)),
+		},
+		Temperature: openai.Float(s.cfg.Stage3Review.Temperature),
+	}
+	if llm.CapabilitiesOf(s.llm).JSONSchema {
+		val := shared.NewResponseFormatJSONObjectParam()
+		params.ResponseFormat = openai.ChatCompletionNewParamsResponseFormatUnion{OfJSONObject: &val}
+	}
+	llm.ApplyParams(&params, s.cfg.Stage3Review.Params, true)
+	if o := req.PR.Overrides; o != nil && o.Model != "" {
+		params.Model = openai.ChatModel(o.Model)
+	}
+
+	resp, err := s.llm.Chat(ctx, params)
+	if err == nil && len(resp.Choices) == 0 {
+		err = fmt.Errorf("received empty response from LLM")
+	}
+	if err != nil {
+		metrics.SummarySyntheses.WithLabelValues("error").Inc()
+		return nil, err
+	}
+
+	var answer struct {
+		Summary string `json:"summary"`
+		Score   *int   `json:"score"`
+	}
+	if err := json.Unmarshal([]byte(cleanJSON(resp.Choices[0].Message.Content)), &answer); err != nil || answer.Summary == "" || answer.Score == nil {
+		metrics.SummarySyntheses.WithLabelValues("error").Inc()
+		return nil, fmt.Errorf("unusable synthesis answer (summary and score required): %v", err)
+	}
+	metrics.SummarySyntheses.WithLabelValues("success").Inc()
+	slog.Info("chunk summaries synthesized", "pr_id", req.PR.ID, "chunks", len(chunks), "score", *answer.Score)
+	return &domain.ReviewResult{
+		Summary: answer.Summary,
+		Score:   min(max(*answer.Score, 0), 100),
+		Usage: &domain.TokenUsage{
+			PromptTokens:     resp.Usage.PromptTokens,
+			CompletionTokens: resp.Usage.CompletionTokens,
+		},
+	}, nil
+}
+
+// severityOrder ranks a finding severity for listing, most severe first
+func severityOrder(severity string) int {
+	switch severity {
+	case domain.CommentSeverityCritical:
+		return 0
+	case domain.CommentSeverityWarning:
+		return 1
+	case domain.CommentSeverityInfo:
+		return 2
+	}
+	return 3
+}
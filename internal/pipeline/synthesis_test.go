@@ -0,0 +1,90 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/domain"
+)
+
+func TestReviewChunked_Synthesis(t *testing.T) {
+	big := strings.Repeat("x", 350)
+	changes := []FileChange{
+		{Path: "a.go", HunkLines: []string{big}},
+		{Path: "b.go", HunkLines: []string{big}},
+		{Path: "c.go", HunkLines: []string{big}},
+	}
+	reviewFunc := func(ctx context.Context, req ReviewRequest, changes []FileChange, contextFiles []FileContent) (*domain.ReviewResult, error) {
+		if changes[0].Path == "c.go" {
+			return nil, errors.New("llm unavailable")
+		}
+		return &domain.ReviewResult{Score: 90, Summary: "part of " + changes[0].Path}, nil
+	}
+
+	var got []ChunkSummary
+	cr := NewChunkReviewer(170)
+	cr.reduce = func(ctx context.Context, req ReviewRequest, chunks []ChunkSummary, findings []domain.ReviewComment) (*domain.ReviewResult, error) {
+		got = chunks
+		return &domain.ReviewResult{Summary: "whole PR", Score: 42, Usage: &domain.TokenUsage{PromptTokens: 7}}, nil
+	}
+	result, err := cr.ReviewChunked(context.Background(), ReviewRequest{}, changes, nil, "", reviewFunc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Summary != "whole PR" || result.Score != 42 || result.Usage.PromptTokens != 7 {
+		t.Errorf("result = %q score %d usage %+v, want the synthesis", result.Summary, result.Score, result.Usage)
+	}
+	if len(got) != 3 || got[0].Summary != "part of a.go" || got[2].Error == "" || got[2].Files[0] != "c.go" {
+		t.Errorf("reducer got %+v", got)
+	}
+
+	// A failed synthesis keeps the concatenated summaries
+	cr.reduce = func(ctx context.Context, req ReviewRequest, chunks []ChunkSummary, findings []domain.ReviewComment) (*domain.ReviewResult, error) {
+		return nil, errors.New("timeout")
+	}
+	result, _ = cr.ReviewChunked(context.Background(), ReviewRequest{}, changes, nil, "", reviewFunc)
+	if !strings.Contains(result.Summary, "part of b.go") || result.Score != 60 {
+		t.Errorf("fallback result = %q score %d", result.Summary, result.Score)
+	}
+}
+
+func TestStage3_Synthesize(t *testing.T) {
+	cfg := validConfig(t)
+	llm := &scriptedLLM{responses: []string{
+		"```json\n{\"summary\": \"Adds a cache; one leak.\", \"score\": 140}\n```",
+		`{"summary": ""}`,
+	}}
+	s3 := NewStage3(&cfg.Pipeline, nil, llm, NewPromptLoader(cfg.Prompts.Dir))
+	chunks := []ChunkSummary{
+		{Index: 1, Files: []string{"a.go", "b.go"}, Score: 80, Summary: "cache added"},
+		{Index: 2, Files: []string{"c.go"}, Error: "timeout"},
+	}
+	findings := []domain.ReviewComment{
+		{File: "a.go", Line: 3, Severity: domain.CommentSeverityInfo, Comment: "naming"},
+		{File: "b.go", Line: 9, Severity: domain.CommentSeverityCritical, Comment: "connection leak"},
+	}
+	req := ReviewRequest{PR: domain.PullRequest{ID: "1", Title: "Cache"}}
+
+	result, err := s3.synthesize(context.Background(), req, chunks, findings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.Summary != "Adds a cache; one leak." || result.Score != 100 || result.Usage.CompletionTokens != 10 {
+		t.Errorf("synthesis = %+v", result)
+	}
+	prompt := llm.requests[0].Messages[0].OfSystem.Content.OfString.Value
+	for _, want := range []string{"Part 1: a.go, b.go", "Review failed: timeout", "- CRITICAL: 1", "[CRITICAL] b.go:9 connection leak"} {
+		if !strings.Contains(prompt, want) {
+			t.Errorf("prompt lacks %q:\n%s", want, prompt)
+		}
+	}
+	if strings.Index(prompt, "[CRITICAL]") > strings.Index(prompt, "[INFO]") {
+		t.Error("findings must be listed most severe first")
+	}
+
+	if _, err := s3.synthesize(context.Background(), req, chunks, findings); err == nil {
+		t.Error("an answer without summary and score must fail")
+	}
+}
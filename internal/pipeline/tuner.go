@@ -0,0 +1,179 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/storage"
+)
+
+// ChunkTuner nudges the token budget and L1 context lines of each repository within
+// configured bounds: it shrinks them when chunks overflow the LLM context and grows them
+// when most chunks come back without findings.
+type ChunkTuner struct {
+	cfg          config.AdaptiveTuningConfig
+	maxTokens    int // Starting budget for repositories without tuning state
+	contextLines int // Starting context lines for repositories without tuning state
+	store        storage.TuningRepository
+
+	mu    sync.Mutex
+	state map[string]*storage.ChunkTuning // Loaded tuning by project/repo
+}
+
+// NewChunkTuner creates a tuner starting from the stage 3 configuration.
+// Zero bounds are derived from the configured budget and context lines.
+func NewChunkTuner(cfg config.Stage3Config, store storage.TuningRepository) *ChunkTuner {
+	tc := cfg.AdaptiveTuning
+	if tc.MaxTokens <= 0 {
+		tc.MaxTokens = cfg.MaxContextTokens
+	}
+	if tc.MinTokens <= 0 {
+		tc.MinTokens = cfg.MaxContextTokens / 4
+	}
+	if tc.MinContextLines <= 0 {
+		tc.MinContextLines = 10
+	}
+	if tc.MaxContextLines <= 0 {
+		tc.MaxContextLines = max(4*cfg.Degradation.L1ContextLines, tc.MinContextLines)
+	}
+	if tc.Window <= 0 {
+		tc.Window = 20
+	}
+	if tc.Step <= 0 {
+		tc.Step = 0.1
+	}
+
+	return &ChunkTuner{
+		cfg:          tc,
+		maxTokens:    clamp(cfg.MaxContextTokens, tc.MinTokens, tc.MaxTokens),
+		contextLines: clamp(cfg.Degradation.L1ContextLines, tc.MinContextLines, tc.MaxContextLines),
+		store:        store,
+		state:        make(map[string]*storage.ChunkTuning),
+	}
+}
+
+// Params returns the token budget and L1 context lines to use for a repository
+func (t *ChunkTuner) Params(ctx context.Context, projectKey, repoSlug string) (maxTokens, contextLines int) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.load(ctx, projectKey, repoSlug)
+	return s.MaxTokens, s.ContextLines
+}
+
+// Observe records the outcome of a review. report may be nil when the review failed,
+// in which case reviewErr is classified instead.
+func (t *ChunkTuner) Observe(ctx context.Context, projectKey, repoSlug string, report *domain.ExecutionReport, reviewErr error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	s := t.load(ctx, projectKey, repoSlug)
+
+	switch {
+	case report != nil:
+		for _, c := range report.Chunks {
+			s.Chunks++
+			if c.Error != "" {
+				if isContextOverflow(c.Error) {
+					s.Overflows++
+				}
+			} else if c.Findings == 0 {
+				s.Empties++
+			}
+		}
+	case reviewErr != nil && !errors.Is(reviewErr, context.Canceled):
+		s.Chunks++
+		if isContextOverflow(reviewErr.Error()) {
+			s.Overflows++
+		}
+	default:
+		return
+	}
+
+	if s.Chunks >= t.cfg.Window {
+		t.adjust(s)
+	}
+	s.UpdatedAt = time.Now()
+	if err := t.store.SaveTuning(ctx, s); err != nil {
+		slog.Warn("save chunk tuning failed", "project", projectKey, "repo", repoSlug, "error", err)
+	}
+}
+
+// adjust applies one tuning step from the current window and starts a new window
+func (t *ChunkTuner) adjust(s *storage.ChunkTuning) {
+	overflowRate := float64(s.Overflows) / float64(s.Chunks)
+	emptyRate := float64(s.Empties) / float64(s.Chunks)
+
+	factor := 1.0
+	switch {
+	case overflowRate > t.cfg.MaxOverflowRate:
+		factor = 1 - t.cfg.Step
+	case emptyRate > t.cfg.MaxEmptyRate:
+		factor = 1 + t.cfg.Step
+	}
+
+	if factor != 1 {
+		oldTokens, oldLines := s.MaxTokens, s.ContextLines
+		s.MaxTokens = clamp(scale(s.MaxTokens, factor), t.cfg.MinTokens, t.cfg.MaxTokens)
+		s.ContextLines = clamp(scale(s.ContextLines, factor), t.cfg.MinContextLines, t.cfg.MaxContextLines)
+		if s.MaxTokens != oldTokens || s.ContextLines != oldLines {
+			slog.Info("chunk tuning adjusted",
+				"project", s.ProjectKey, "repo", s.RepoSlug,
+				"overflow_rate", overflowRate, "empty_rate", emptyRate,
+				"max_tokens", oldTokens, "new_max_tokens", s.MaxTokens,
+				"context_lines", oldLines, "new_context_lines", s.ContextLines)
+		}
+	}
+
+	s.Chunks, s.Overflows, s.Empties = 0, 0, 0
+}
+
+// load returns the cached tuning state, reading it from storage on first use. Caller holds t.mu.
+func (t *ChunkTuner) load(ctx context.Context, projectKey, repoSlug string) *storage.ChunkTuning {
+	key := projectKey + "/" + repoSlug
+	if s, ok := t.state[key]; ok {
+		return s
+	}
+
+	s, err := t.store.GetTuning(ctx, projectKey, repoSlug)
+	if err != nil {
+		if !errors.Is(err, storage.ErrNotFound) {
+			slog.Warn("load chunk tuning failed, using defaults", "project", projectKey, "repo", repoSlug, "error", err)
+		}
+		s = &storage.ChunkTuning{
+			ProjectKey:   projectKey,
+			RepoSlug:     repoSlug,
+			MaxTokens:    t.maxTokens,
+			ContextLines: t.contextLines,
+		}
+	}
+	// Bounds may have changed since the state was stored
+	s.MaxTokens = clamp(s.MaxTokens, t.cfg.MinTokens, t.cfg.MaxTokens)
+	s.ContextLines = clamp(s.ContextLines, t.cfg.MinContextLines, t.cfg.MaxContextLines)
+	t.state[key] = s
+	return s
+}
+
+// isContextOverflow reports whether an LLM error message indicates the prompt exceeded the model context
+func isContextOverflow(msg string) bool {
+	msg = strings.ToLower(msg)
+	for _, s := range []string{"context_length_exceeded", "context length", "maximum context", "too many tokens", "prompt is too long"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
+
+func scale(v int, factor float64) int {
+	return int(math.Round(float64(v) * factor))
+}
+
+func clamp(v, lo, hi int) int {
+	return min(max(v, lo), hi)
+}
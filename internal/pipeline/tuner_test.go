@@ -0,0 +1,99 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/storage"
+)
+
+type memTuningStore struct {
+	saved map[string]storage.ChunkTuning
+}
+
+func (m *memTuningStore) GetTuning(ctx context.Context, projectKey, repoSlug string) (*storage.ChunkTuning, error) {
+	t, ok := m.saved[projectKey+"/"+repoSlug]
+	if !ok {
+		return nil, storage.ErrNotFound
+	}
+	return &t, nil
+}
+
+func (m *memTuningStore) SaveTuning(ctx context.Context, t *storage.ChunkTuning) error {
+	m.saved[t.ProjectKey+"/"+t.RepoSlug] = *t
+	return nil
+}
+
+func newTestTuner(store storage.TuningRepository) *ChunkTuner {
+	cfg := config.Stage3Config{
+		MaxContextTokens: 1000,
+		Degradation:      config.DegradationConfig{L1ContextLines: 50},
+		AdaptiveTuning: config.AdaptiveTuningConfig{
+			MinTokens:       700,
+			Window:          4,
+			Step:            0.2,
+			MaxOverflowRate: 0.1,
+			MaxEmptyRate:    0.5,
+		},
+	}
+	return NewChunkTuner(cfg, store)
+}
+
+func chunks(findings ...int) *domain.ExecutionReport {
+	r := &domain.ExecutionReport{}
+	for i, f := range findings {
+		r.Chunks = append(r.Chunks, domain.ChunkReport{Index: i + 1, Findings: f})
+	}
+	return r
+}
+
+func TestChunkTuner_ShrinksOnOverflow(t *testing.T) {
+	store := &memTuningStore{saved: map[string]storage.ChunkTuning{}}
+	tuner := newTestTuner(store)
+	ctx := context.Background()
+
+	overflow := errors.New("llm chat failed: This model's maximum context length is 8192 tokens")
+	for i := 0; i < 4; i++ {
+		tuner.Observe(ctx, "PROJ", "repo", nil, overflow)
+	}
+
+	tokens, lines := tuner.Params(ctx, "PROJ", "repo")
+	if tokens != 800 || lines != 40 {
+		t.Errorf("expected 800 tokens / 40 lines after shrink, got %d / %d", tokens, lines)
+	}
+
+	// Another window of overflows is bounded by min_tokens
+	for i := 0; i < 4; i++ {
+		tuner.Observe(ctx, "PROJ", "repo", nil, overflow)
+	}
+	if tokens, _ := tuner.Params(ctx, "PROJ", "repo"); tokens != 700 {
+		t.Errorf("expected token budget clamped to 700, got %d", tokens)
+	}
+
+	if s := store.saved["PROJ/repo"]; s.MaxTokens != 700 || s.Chunks != 0 {
+		t.Errorf("expected persisted state after adjustment, got %+v", s)
+	}
+}
+
+func TestChunkTuner_GrowsOnEmptyResults(t *testing.T) {
+	store := &memTuningStore{saved: map[string]storage.ChunkTuning{
+		"PROJ/repo": {ProjectKey: "PROJ", RepoSlug: "repo", MaxTokens: 800, ContextLines: 40},
+	}}
+	tuner := newTestTuner(store)
+	ctx := context.Background()
+
+	tuner.Observe(ctx, "PROJ", "repo", chunks(0, 0, 0, 1), nil)
+
+	tokens, lines := tuner.Params(ctx, "PROJ", "repo")
+	if tokens != 960 || lines != 48 {
+		t.Errorf("expected 960 tokens / 48 lines after growth, got %d / %d", tokens, lines)
+	}
+
+	// Other repositories keep the configured defaults
+	if tokens, lines := tuner.Params(ctx, "PROJ", "other"); tokens != 1000 || lines != 50 {
+		t.Errorf("expected defaults for untouched repo, got %d / %d", tokens, lines)
+	}
+}
@@ -27,6 +27,9 @@ type Pipeline struct {
 type ReviewRequest struct {
 	PR           domain.PullRequest
 	LatestCommit string
+	Cache        *domain.ReviewContext // Optional: diff already fetched by the processor
+	Standards    string                // Team standards text for the prompt (pipeline.standards)
+	Prompt       string                // Optional: prompt template of this pass instead of the stage's (security pass)
 }
 
 // FileChange represents a file change from Stage 1
@@ -42,7 +45,7 @@ type FileContent struct {
 	Path      string
 	Content   string
 	IsDiffed  bool   // true if this file was in the diff
-	Relevance string // direct, import, test, config
+	Relevance string // direct, import, test, config, doc (retrieved documentation snippet), related (retrieved code)
 }
 
 // Stage1DiffExtractor defines the interface for Stage 1
@@ -0,0 +1,193 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+// ModelLister lists the models offered by the LLM provider
+type ModelLister interface {
+	ListModels(ctx context.Context) ([]string, error)
+}
+
+// ValidateConfig checks the pipeline configuration at startup so that template and
+// threshold mistakes fail fast instead of on the first review.
+// models may be nil; a provider that cannot list models only produces a warning.
+func ValidateConfig(ctx context.Context, cfg *config.Config, loader *PromptLoader, models ModelLister) error {
+	var errs []string
+	p := &cfg.Pipeline
+
+	// Stage 3 template must exist and render with the data the stage provides
+	stage3 := NewStage3(p, nil, nil, loader)
+	_, err := loader.LoadPrompt(p.Stage3Review.PromptTemplate, map[string]interface{}{
+		"PR":            &domain.PullRequest{},
+		"ResultFormat":  stage3.getResultFormat(),
+		"Contract":      stage3.contract,
+		"Changes":       []FileChange{},
+		"Context":       []FileContent{},
+		"LanguageRules": "",
+		"Language":      "",
+	})
+	if err != nil {
+		errs = append(errs, fmt.Sprintf("pipeline.stage3_review.prompt_template %q: %v (check prompts.dir=%q)", p.Stage3Review.PromptTemplate, err, cfg.Prompts.Dir))
+	}
+
+	// Language rule prompts are optional, but present ones must render
+	for _, rule := range knownRules() {
+		name := filepath.Join("rules", rule)
+		if _, err := os.Stat(filepath.Join(cfg.Prompts.Dir, name+".md")); err != nil {
+			continue
+		}
+		if _, err := loader.LoadPrompt(name, nil); err != nil {
+			errs = append(errs, fmt.Sprintf("rule prompt %s: %v", name, err))
+		}
+	}
+
+	if c := p.Stage3Review.Contract; c != "" && c != config.ReviewContractV1 && c != config.ReviewContractV2 {
+		errs = append(errs, fmt.Sprintf("pipeline.stage3_review.contract must be v1 or v2, got %q", c))
+	}
+	if o := p.Stage3Review.StructuredOutput; o != "" && o != config.StructuredOutputJSONObject && o != config.StructuredOutputJSONSchema {
+		errs = append(errs, fmt.Sprintf("pipeline.stage3_review.structured_output must be json_object or json_schema, got %q", o))
+	}
+	if p.PostProcessing.Confidence.Min > 0 && stage3.requestedContract() != config.ReviewContractV2 &&
+		!slices.ContainsFunc(cfg.LLM.Routes, func(r config.LLMRoute) bool { return r.Contract == config.ReviewContractV2 }) {
+		errs = append(errs, "pipeline.post_processing.confidence requires review contract v2, which asks the model for a confidence per comment")
+	}
+	if p.Stage3Review.MaxContextTokens <= 0 {
+		errs = append(errs, fmt.Sprintf("pipeline.stage3_review.max_context_tokens must be positive, got %d", p.Stage3Review.MaxContextTokens))
+	}
+	if t := p.Stage3Review.Temperature; t < 0 || t > 2 {
+		errs = append(errs, fmt.Sprintf("pipeline.stage3_review.temperature must be within [0, 2], got %g", t))
+	}
+	if e := p.Stage3Review.Ensemble; e.Runs < 0 || e.Runs > maxEnsembleRuns {
+		errs = append(errs, fmt.Sprintf("pipeline.stage3_review.ensemble.runs must be within [0, %d], got %d", maxEnsembleRuns, e.Runs))
+	} else if e.Runs > 1 && (e.Temperature <= 0 || e.Temperature > 2) {
+		errs = append(errs, fmt.Sprintf("pipeline.stage3_review.ensemble.temperature must be within (0, 2] so the runs differ, got %g", e.Temperature))
+	} else if e.LineTolerance < 0 {
+		errs = append(errs, fmt.Sprintf("pipeline.stage3_review.ensemble.line_tolerance must not be negative, got %d", e.LineTolerance))
+	}
+	errs = append(errs, validateLLMParams("llm.params", cfg.LLM.Params)...)
+	errs = append(errs, validateLLMParams("pipeline.stage3_review.params", p.Stage3Review.Params)...)
+	for _, expr := range p.Stage3Review.StreamDebug.Redact {
+		if _, err := regexp.Compile(expr); err != nil {
+			errs = append(errs, fmt.Sprintf("pipeline.stage3_review.stream_debug.redact %q: %v", expr, err))
+		}
+	}
+	for _, expr := range p.SecretScan.Patterns {
+		if _, err := regexp.Compile(expr); err != nil {
+			errs = append(errs, fmt.Sprintf("pipeline.secret_scan.patterns %q: %v", expr, err))
+		}
+	}
+	if _, err := regexp.Compile(p.PRPolicy.TitlePattern); err != nil {
+		errs = append(errs, fmt.Sprintf("pipeline.pr_policy.title_pattern %q: %v", p.PRPolicy.TitlePattern, err))
+	}
+	if _, err := regexp.Compile(p.PRPolicy.BranchPattern); err != nil {
+		errs = append(errs, fmt.Sprintf("pipeline.pr_policy.branch_pattern %q: %v", p.PRPolicy.BranchPattern, err))
+	}
+	for _, expr := range p.Stage3Review.OutcomeCheck.RefusalPatterns {
+		if _, err := regexp.Compile(expr); err != nil {
+			errs = append(errs, fmt.Sprintf("pipeline.stage3_review.outcome_check.refusal_patterns %q: %v", expr, err))
+		}
+	}
+	if p.Stage3Review.OutcomeCheck.Retry {
+		if _, err := loader.LoadPrompt(retryPromptTemplate, map[string]interface{}{"Outcome": domain.OutcomeEmpty, "ResultFormat": stage3.getResultFormat()}); err != nil {
+			errs = append(errs, fmt.Sprintf("retry prompt %s: %v (required by pipeline.stage3_review.outcome_check.retry)", retryPromptTemplate, err))
+		}
+	}
+	if p.Stage3Review.SchemaRepair.Enabled {
+		if _, err := loader.LoadPrompt(repairPromptTemplate, map[string]interface{}{"Problems": []string{"$.score is required"}, "Schema": resultSchema, "ResultFormat": stage3.getResultFormat()}); err != nil {
+			errs = append(errs, fmt.Sprintf("repair prompt %s: %v (required by pipeline.stage3_review.schema_repair)", repairPromptTemplate, err))
+		}
+	}
+	if sec := p.Stage3Review.Security; sec.Enabled {
+		if _, err := loader.LoadPrompt(sec.PromptTemplate, map[string]interface{}{"PR": &domain.PullRequest{}, "ResultFormat": stage3.getResultFormat()}); err != nil {
+			errs = append(errs, fmt.Sprintf("pipeline.stage3_review.security.prompt_template %q: %v", sec.PromptTemplate, err))
+		}
+	}
+	if p.Stage3Review.Synthesis.Enabled {
+		if _, err := loader.LoadPrompt(synthesisPromptTemplate, map[string]interface{}{"PR": &domain.PullRequest{}, "Chunks": []ChunkSummary{{Files: []string{"a.go"}}}}); err != nil {
+			errs = append(errs, fmt.Sprintf("synthesis prompt %s: %v (required by pipeline.stage3_review.synthesis)", synthesisPromptTemplate, err))
+		}
+	}
+	if p.Stage2Context.MaxExtraFiles < 0 || p.Stage2Context.MaxFileSize < 0 {
+		errs = append(errs, "pipeline.stage2_context.max_extra_files and max_file_size must not be negative")
+	}
+
+	// Degradation levels apply in order L1 -> L2 -> L3 as the prompt grows past the limit
+	d := p.Stage3Review.Degradation
+	if d.L1ContextLines < 0 {
+		errs = append(errs, fmt.Sprintf("pipeline.stage3_review.degradation.l1_context_lines must not be negative, got %d", d.L1ContextLines))
+	}
+	if d.L1ContextLines == 0 && !d.L2ChunkByFile && !d.L3DiffOnly {
+		slog.Warn("all degradation levels disabled; reviews exceeding max_context_tokens will fail")
+	}
+
+	if models != nil {
+		if err := checkModel(ctx, models, cfg.LLM.Model); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("pipeline config invalid: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
+
+// checkModel verifies the configured model is offered by the provider
+func checkModel(ctx context.Context, models ModelLister, model string) error {
+	available, err := models.ListModels(ctx)
+	if err != nil || len(available) == 0 {
+		slog.Warn("cannot list llm models, skipping model check", "model", model, "error", err)
+		return nil
+	}
+	if !slices.Contains(available, model) {
+		return fmt.Errorf("llm.model %q not offered by provider (available: %s)", model, strings.Join(available, ", "))
+	}
+	return nil
+}
+
+// knownRules returns the rule names the RuleDetector can emit
+func knownRules() []string {
+	d := NewRuleDetector()
+	seen := make(map[string]bool)
+	for _, r := range d.ExtRules {
+		seen[r] = true
+	}
+	for _, r := range d.FilenameRules {
+		seen[r] = true
+	}
+	for r := range d.ContentRules {
+		seen[r] = true
+	}
+	rules := make([]string, 0, len(seen))
+	for r := range seen {
+		rules = append(rules, r)
+	}
+	slices.Sort(rules)
+	return rules
+}
+
+// validateLLMParams checks the ranges of configured chat completion parameters
+func validateLLMParams(key string, p config.LLMParams) []string {
+	var errs []string
+	if p.Temperature != nil && (*p.Temperature < 0 || *p.Temperature > 2) {
+		errs = append(errs, fmt.Sprintf("%s.temperature must be within [0, 2], got %g", key, *p.Temperature))
+	}
+	if p.TopP != nil && (*p.TopP <= 0 || *p.TopP > 1) {
+		errs = append(errs, fmt.Sprintf("%s.top_p must be within (0, 1], got %g", key, *p.TopP))
+	}
+	if p.MaxTokens != nil && *p.MaxTokens <= 0 {
+		errs = append(errs, fmt.Sprintf("%s.max_tokens must be positive, got %d", key, *p.MaxTokens))
+	}
+	return errs
+}
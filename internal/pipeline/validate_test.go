@@ -0,0 +1,126 @@
+package pipeline
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+)
+
+type staticModels struct {
+	ids []string
+	err error
+}
+
+func (m staticModels) ListModels(ctx context.Context) ([]string, error) { return m.ids, m.err }
+
+func validConfig(t *testing.T) *config.Config {
+	t.Helper()
+	baseDir, _ := filepath.Abs("../../prompts")
+	cfg := &config.Config{}
+	cfg.Prompts.Dir = baseDir
+	cfg.LLM.Model = "gpt-4o"
+	cfg.Pipeline.Stage3Review.PromptTemplate = "pipeline/stage3.md"
+	cfg.Pipeline.Stage3Review.MaxContextTokens = 1000
+	cfg.Pipeline.Stage3Review.Degradation.L1ContextLines = 50
+	return cfg
+}
+
+func TestValidateConfig_Valid(t *testing.T) {
+	cfg := validConfig(t)
+	loader := NewPromptLoader(cfg.Prompts.Dir)
+
+	if err := ValidateConfig(context.Background(), cfg, loader, staticModels{ids: []string{"gpt-4o"}}); err != nil {
+		t.Fatalf("expected valid config, got %v", err)
+	}
+	// Providers without a model listing are not fatal
+	if err := ValidateConfig(context.Background(), cfg, loader, staticModels{err: errors.New("404")}); err != nil {
+		t.Fatalf("expected list failure to be ignored, got %v", err)
+	}
+}
+
+func TestValidateConfig_Errors(t *testing.T) {
+	tests := []struct {
+		name    string
+		mutate  func(cfg *config.Config)
+		models  ModelLister
+		wantErr string
+	}{
+		{
+			name:    "missing template",
+			mutate:  func(cfg *config.Config) { cfg.Pipeline.Stage3Review.PromptTemplate = "pipeline/missing" },
+			wantErr: "prompt_template \"pipeline/missing\"",
+		},
+		{
+			name:    "non-positive token limit",
+			mutate:  func(cfg *config.Config) { cfg.Pipeline.Stage3Review.MaxContextTokens = 0 },
+			wantErr: "max_context_tokens must be positive",
+		},
+		{
+			name:    "negative L1 context",
+			mutate:  func(cfg *config.Config) { cfg.Pipeline.Stage3Review.Degradation.L1ContextLines = -1 },
+			wantErr: "l1_context_lines must not be negative",
+		},
+		{
+			name: "top_p out of range",
+			mutate: func(cfg *config.Config) {
+				topP := 1.5
+				cfg.Pipeline.Stage3Review.Params.TopP = &topP
+			},
+			wantErr: "pipeline.stage3_review.params.top_p must be within (0, 1]",
+		},
+		{
+			name:    "invalid redaction pattern",
+			mutate:  func(cfg *config.Config) { cfg.Pipeline.Stage3Review.StreamDebug.Redact = []string{"(unclosed"} },
+			wantErr: "stream_debug.redact \"(unclosed\"",
+		},
+		{
+			name:    "confidence cutoff without contract v2",
+			mutate:  func(cfg *config.Config) { cfg.Pipeline.PostProcessing.Confidence.Min = 0.6 },
+			wantErr: "post_processing.confidence requires review contract v2",
+		},
+		{
+			name:    "ensemble at temperature 0",
+			mutate:  func(cfg *config.Config) { cfg.Pipeline.Stage3Review.Ensemble.Runs = 3 },
+			wantErr: "ensemble.temperature must be within (0, 2]",
+		},
+		{
+			name:    "unknown model",
+			mutate:  func(cfg *config.Config) {},
+			models:  staticModels{ids: []string{"other"}},
+			wantErr: "llm.model \"gpt-4o\" not offered",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := validConfig(t)
+			tt.mutate(cfg)
+			err := ValidateConfig(context.Background(), cfg, NewPromptLoader(cfg.Prompts.Dir), tt.models)
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("expected error containing %q, got %v", tt.wantErr, err)
+			}
+		})
+	}
+}
+
+func TestValidateConfig_BrokenTemplate(t *testing.T) {
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "pipeline"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "pipeline", "stage3.md"), []byte("{{.PR.Title"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cfg := validConfig(t)
+	cfg.Prompts.Dir = dir
+
+	err := ValidateConfig(context.Background(), cfg, NewPromptLoader(dir), nil)
+	if err == nil || !strings.Contains(err.Error(), "parse prompt template") {
+		t.Errorf("expected template parse error, got %v", err)
+	}
+}
@@ -0,0 +1,69 @@
+package pipeline
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"path/filepath"
+	"time"
+
+	"github.com/openai/openai-go"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+// Warmup prepares the pipeline before traffic arrives: it parses the prompt templates,
+// estimates the static Stage 3 prompt size and, when llm.warmup is enabled, sends the
+// static system prompt once so the provider loads the model and caches the prompt prefix.
+// A failed warm-up completion is logged, not returned; template errors are returned.
+func Warmup(ctx context.Context, cfg *config.Config, loader *PromptLoader, llm LLMClient) error {
+	start := time.Now()
+
+	names := []string{cfg.Pipeline.Stage3Review.PromptTemplate}
+	for _, rule := range knownRules() {
+		names = append(names, filepath.Join("rules", rule))
+	}
+	// Rule prompts are optional; only the stage template is required
+	if err := loader.Precompile(names[0]); err != nil {
+		return err
+	}
+	for _, name := range names[1:] {
+		if err := loader.Precompile(name); err != nil {
+			slog.Debug("rule prompt not precompiled", "rule", name, "error", err)
+		}
+	}
+
+	stage3 := NewStage3(&cfg.Pipeline, nil, llm, loader)
+	staticPrompt, err := loader.LoadPrompt(cfg.Pipeline.Stage3Review.PromptTemplate, map[string]interface{}{
+		"PR":           &domain.PullRequest{},
+		"ResultFormat": stage3.getResultFormat(),
+		"Contract":     stage3.contract,
+		"Changes":      []FileChange{},
+		"Context":      []FileContent{},
+	})
+	if err != nil {
+		return fmt.Errorf("render stage 3 prompt: %w", err)
+	}
+	staticTokens := EstimateTokens(staticPrompt)
+	slog.Info("prompts precompiled", "templates", len(names), "static_tokens", staticTokens,
+		"budget_left", cfg.Pipeline.Stage3Review.MaxContextTokens-staticTokens)
+
+	if !cfg.LLM.Warmup || llm == nil {
+		return nil
+	}
+
+	_, err = llm.Chat(ctx, openai.ChatCompletionNewParams{
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(staticPrompt),
+			openai.UserMessage("ping"),
+		},
+		MaxTokens: openai.Int(1),
+	})
+	if err != nil {
+		slog.Warn("llm warm-up failed", "model", cfg.LLM.Model, "error", err)
+		return nil
+	}
+	slog.Info("llm warmed up", "model", cfg.LLM.Model, "duration", time.Since(start))
+	return nil
+}
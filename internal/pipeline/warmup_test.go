@@ -0,0 +1,80 @@
+package pipeline
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/openai/openai-go"
+)
+
+type countingLLM struct {
+	calls  int
+	system string
+}
+
+func (m *countingLLM) Chat(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	m.calls++
+	if len(params.Messages) > 0 && params.Messages[0].OfSystem != nil {
+		m.system = params.Messages[0].OfSystem.Content.OfString.Value
+	}
+	return &openai.ChatCompletion{}, nil
+}
+
+func (m *countingLLM) SimpleTextQuery(ctx context.Context, systemPrompt, userInput string) (string, error) {
+	return "", nil
+}
+
+func TestWarmup(t *testing.T) {
+	cfg := validConfig(t)
+	loader := NewPromptLoader(cfg.Prompts.Dir)
+	llm := &countingLLM{}
+
+	if err := Warmup(context.Background(), cfg, loader, llm); err != nil {
+		t.Fatalf("warmup: %v", err)
+	}
+	if llm.calls != 0 {
+		t.Errorf("expected no completion when warmup is disabled, got %d", llm.calls)
+	}
+
+	cfg.LLM.Warmup = true
+	if err := Warmup(context.Background(), cfg, loader, llm); err != nil {
+		t.Fatalf("warmup: %v", err)
+	}
+	if llm.calls != 1 || !strings.Contains(llm.system, "expert code reviewer") {
+		t.Errorf("expected one warm-up completion with the stage 3 prompt, got %d calls", llm.calls)
+	}
+}
+
+func TestPromptLoader_ReparsesChangedTemplate(t *testing.T) {
+	dir := t.TempDir()
+	path := filepath.Join(dir, "p.md")
+	if err := os.WriteFile(path, []byte("v1 {{.ProjectKey}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	loader := NewPromptLoader(dir)
+	if err := loader.Precompile("p"); err != nil {
+		t.Fatalf("precompile: %v", err)
+	}
+
+	out, _ := loader.LoadPrompt("p", map[string]interface{}{"ProjectKey": "A"})
+	if out != "v1 A" {
+		t.Errorf("expected %q, got %q", "v1 A", out)
+	}
+
+	// A changed file must not be served from the cache
+	if err := os.WriteFile(path, []byte("v2 {{.ProjectKey}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	future := time.Now().Add(time.Minute)
+	if err := os.Chtimes(path, future, future); err != nil {
+		t.Fatal(err)
+	}
+	out, _ = loader.LoadPrompt("p", map[string]interface{}{"ProjectKey": "A"})
+	if out != "v2 A" {
+		t.Errorf("expected %q, got %q", "v2 A", out)
+	}
+}
@@ -0,0 +1,218 @@
+// Package poller finds pull requests to review by listing the open pull requests of configured
+// repositories periodically, for Bitbucket Server instances where webhooks cannot be installed.
+package poller
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/scope"
+	"pr-review-automation/internal/storage"
+
+	"github.com/tidwall/gjson"
+)
+
+// ToolCaller calls MCP tools
+type ToolCaller interface {
+	CallTool(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error)
+}
+
+// Submitter queues the review of a pull request
+type Submitter interface {
+	SubmitReview(pr *domain.PullRequest)
+}
+
+// maxPages bounds the listing of one repository, in case a server keeps returning pages
+const maxPages = 100
+
+// Poller lists the open pull requests of the configured repositories and submits those whose
+// latest commit has not been reviewed yet
+type Poller struct {
+	cfg       config.PollingConfig
+	tools     ToolCaller
+	submitter Submitter
+	store     storage.Repository // Optional: skips commits reviewed before a restart
+	gate      *scope.Gate        // Optional: skips repositories outside the review scope
+
+	seen map[string]string // "PROJECT/repo/id" -> latest commit submitted
+}
+
+// New creates a Poller
+func New(cfg config.PollingConfig, tools ToolCaller, submitter Submitter) *Poller {
+	return &Poller{
+		cfg:       cfg,
+		tools:     tools,
+		submitter: submitter,
+		seen:      make(map[string]string),
+	}
+}
+
+// SetStore sets the review storage, so commits reviewed before a restart are not submitted again
+func (p *Poller) SetStore(store storage.Repository) {
+	p.store = store
+}
+
+// SetGate sets the review scope; repositories it disables are not polled
+func (p *Poller) SetGate(gate *scope.Gate) {
+	p.gate = gate
+}
+
+// Run polls all repositories immediately and then on every interval until ctx is done
+func (p *Poller) Run(ctx context.Context) {
+	if len(p.cfg.Repos) == 0 {
+		return
+	}
+	ticker := time.NewTicker(p.cfg.Interval)
+	defer ticker.Stop()
+
+	for {
+		p.Poll(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Poll lists every configured repository once and submits the pull requests to review
+func (p *Poller) Poll(ctx context.Context) {
+	for _, repo := range p.cfg.Repos {
+		if ctx.Err() != nil {
+			return
+		}
+		projectKey, repoSlug, _ := strings.Cut(repo, "/")
+		if p.gate != nil {
+			if ok, reason := p.gate.Allowed(projectKey, repoSlug); !ok {
+				slog.Debug("repository not enabled for review, not polled", "project", projectKey, "repo", repoSlug, "reason", reason)
+				continue
+			}
+		}
+		if err := p.pollRepo(ctx, projectKey, repoSlug); err != nil {
+			metrics.PollListings.WithLabelValues("error").Inc()
+			slog.Warn("poll repository failed", "project", projectKey, "repo", repoSlug, "error", err)
+			continue
+		}
+		metrics.PollListings.WithLabelValues("success").Inc()
+	}
+}
+
+// pollRepo submits the open pull requests of one repository with an unreviewed latest commit
+func (p *Poller) pollRepo(ctx context.Context, projectKey, repoSlug string) error {
+	prs, err := p.listOpen(ctx, projectKey, repoSlug)
+	if err != nil {
+		return err
+	}
+
+	open := make(map[string]bool, len(prs))
+	for _, pr := range prs {
+		key := prKey(pr)
+		open[key] = true
+		if p.reviewed(ctx, key, pr) {
+			metrics.PollPullRequests.WithLabelValues("reviewed").Inc()
+			continue
+		}
+		p.seen[key] = pr.LatestCommit
+		metrics.PollPullRequests.WithLabelValues("submitted").Inc()
+		slog.Info("polled pull request submitted", "key", key, "commit", pr.LatestCommit)
+		p.submitter.SubmitReview(pr)
+	}
+
+	// Closed pull requests are forgotten, so the map does not grow
+	prefix := projectKey + "/" + repoSlug + "/"
+	for key := range p.seen {
+		if strings.HasPrefix(key, prefix) && !open[key] {
+			delete(p.seen, key)
+		}
+	}
+	return nil
+}
+
+// reviewed reports whether the latest commit of pr was submitted by this poller or has a
+// successful stored review
+func (p *Poller) reviewed(ctx context.Context, key string, pr *domain.PullRequest) bool {
+	if commit, ok := p.seen[key]; ok && commit == pr.LatestCommit {
+		return true
+	}
+	if p.store == nil {
+		return false
+	}
+	records, err := p.store.ListReviewsByPR(ctx, pr.ProjectKey, pr.RepoSlug, pr.ID)
+	if err != nil {
+		// Submitting again is safe: the comment markers keep the review from posting twice
+		slog.Warn("list stored reviews failed", "key", key, "error", err)
+		return false
+	}
+	for _, r := range records {
+		if r.Status == "success" && r.PullRequest != nil && r.PullRequest.LatestCommit == pr.LatestCommit {
+			p.seen[key] = pr.LatestCommit
+			return true
+		}
+	}
+	return false
+}
+
+// listOpen lists the open pull requests of a repository, following the pages of the response
+func (p *Poller) listOpen(ctx context.Context, projectKey, repoSlug string) ([]*domain.PullRequest, error) {
+	var prs []*domain.PullRequest
+	start := int64(0)
+	for range maxPages {
+		result, err := p.tools.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetPullRequests, map[string]interface{}{
+			"projectKey": projectKey,
+			"repoSlug":   repoSlug,
+			"state":      "OPEN",
+			"start":      start,
+			"limit":      p.cfg.PageSize,
+		})
+		if err != nil {
+			return nil, err
+		}
+		data, err := json.Marshal(result)
+		if err != nil {
+			return nil, err
+		}
+		// MCP servers wrap the JSON in a text content item
+		if text := gjson.GetBytes(data, "content.0.text"); text.Exists() {
+			data = []byte(text.String())
+		}
+		if !gjson.ValidBytes(data) {
+			return nil, fmt.Errorf("%s: response is not JSON", config.ToolBitbucketGetPullRequests)
+		}
+
+		for _, v := range gjson.GetBytes(data, "values").Array() {
+			if v.Get("id").String() == "" || (v.Get("state").Exists() && v.Get("state").String() != "OPEN") {
+				continue
+			}
+			prs = append(prs, &domain.PullRequest{
+				ID:           v.Get("id").String(),
+				ProjectKey:   projectKey,
+				RepoSlug:     repoSlug,
+				Title:        v.Get("title").String(),
+				Description:  v.Get("description").String(),
+				Author:       v.Get("author.user.displayName").String(),
+				LatestCommit: v.Get("fromRef.latestCommit").String(),
+				WebURL:       v.Get("links.self.0.href").String(),
+				SourceBranch: v.Get("fromRef.displayId").String(),
+				TargetBranch: v.Get("toRef.displayId").String(),
+			})
+		}
+
+		next := gjson.GetBytes(data, "nextPageStart")
+		if gjson.GetBytes(data, "isLastPage").Bool() || !next.Exists() || next.Int() <= start {
+			return prs, nil
+		}
+		start = next.Int()
+	}
+	return prs, nil
+}
+
+func prKey(pr *domain.PullRequest) string {
+	return pr.ProjectKey + "/" + pr.RepoSlug + "/" + pr.ID
+}
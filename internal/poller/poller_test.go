@@ -0,0 +1,140 @@
+package poller
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/scope"
+	"pr-review-automation/internal/storage"
+)
+
+// fakeBitbucket serves the open pull requests of repositories, two per page
+type fakeBitbucket struct {
+	open  map[string][]map[string]any // "PROJECT/repo" -> pull requests
+	calls int
+}
+
+func (f *fakeBitbucket) CallTool(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+	if toolName != config.ToolBitbucketGetPullRequests {
+		return nil, fmt.Errorf("unexpected tool %s", toolName)
+	}
+	f.calls++
+	repo := fmt.Sprintf("%s/%s", args["projectKey"], args["repoSlug"])
+	prs, ok := f.open[repo]
+	if !ok {
+		return nil, fmt.Errorf("repository %s not found", repo)
+	}
+	start := int(args["start"].(int64))
+	end := min(start+2, len(prs))
+	page := map[string]any{"values": prs[start:end], "isLastPage": end == len(prs)}
+	if end < len(prs) {
+		page["nextPageStart"] = end
+	}
+	return page, nil
+}
+
+func openPR(id int, commit string) map[string]any {
+	return map[string]any{
+		"id":      id,
+		"title":   fmt.Sprintf("PR %d", id),
+		"state":   "OPEN",
+		"fromRef": map[string]any{"latestCommit": commit},
+		"author":  map[string]any{"user": map[string]any{"displayName": "Alice"}},
+	}
+}
+
+type recordingSubmitter struct {
+	submitted []string
+}
+
+func (s *recordingSubmitter) SubmitReview(pr *domain.PullRequest) {
+	s.submitted = append(s.submitted, prKey(pr)+"@"+pr.LatestCommit)
+}
+
+func newPoller(bb *fakeBitbucket, repos ...string) (*Poller, *recordingSubmitter) {
+	sub := &recordingSubmitter{}
+	return New(config.PollingConfig{Enabled: true, Interval: time.Minute, Repos: repos, PageSize: 2}, bb, sub), sub
+}
+
+func TestPoller_SubmitsNewCommitsOnce(t *testing.T) {
+	bb := &fakeBitbucket{open: map[string][]map[string]any{
+		"PAY/api": {openPR(1, "a1"), openPR(2, "b1"), openPR(3, "c1")},
+	}}
+	p, sub := newPoller(bb, "PAY/api")
+	ctx := context.Background()
+
+	p.Poll(ctx)
+	if len(sub.submitted) != 3 || bb.calls != 2 {
+		t.Fatalf("first poll: submitted %v in %d calls, want 3 pull requests over 2 pages", sub.submitted, bb.calls)
+	}
+
+	// Unchanged pull requests are not submitted again; a new commit is
+	bb.open["PAY/api"][1] = openPR(2, "b2")
+	p.Poll(ctx)
+	if len(sub.submitted) != 4 || sub.submitted[3] != "PAY/api/2@b2" {
+		t.Fatalf("second poll: submitted %v, want only PAY/api/2@b2 added", sub.submitted)
+	}
+
+	// Closed pull requests are forgotten
+	bb.open["PAY/api"] = bb.open["PAY/api"][:1]
+	p.Poll(ctx)
+	if len(p.seen) != 1 {
+		t.Errorf("seen = %v, want only the open pull request", p.seen)
+	}
+}
+
+func TestPoller_SkipsStoredReviews(t *testing.T) {
+	store, err := storage.NewSQLiteRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+	for _, r := range []struct{ id, commit, status string }{{"1", "a1", "success"}, {"2", "b1", "error"}, {"3", "c0", "success"}} {
+		if err := store.SaveReview(ctx, &storage.ReviewRecord{
+			ID:          "rev-" + r.id,
+			PullRequest: &domain.PullRequest{ID: r.id, ProjectKey: "PAY", RepoSlug: "api", LatestCommit: r.commit},
+			Result:      &domain.ReviewResult{},
+			CreatedAt:   time.Now(),
+			Status:      r.status,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	bb := &fakeBitbucket{open: map[string][]map[string]any{
+		"PAY/api": {openPR(1, "a1"), openPR(2, "b1"), openPR(3, "c1")},
+	}}
+	p, sub := newPoller(bb, "PAY/api")
+	p.SetStore(store)
+	p.Poll(ctx)
+
+	// PR 2 failed and PR 3 has a new commit
+	want := []string{"PAY/api/2@b1", "PAY/api/3@c1"}
+	if fmt.Sprint(sub.submitted) != fmt.Sprint(want) {
+		t.Errorf("submitted %v, want %v", sub.submitted, want)
+	}
+}
+
+func TestPoller_ScopeAndErrors(t *testing.T) {
+	bb := &fakeBitbucket{open: map[string][]map[string]any{
+		"PAY/api":    {openPR(1, "a1")},
+		"PAY/legacy": {openPR(7, "l1")},
+	}}
+	p, sub := newPoller(bb, "PAY/missing", "PAY/legacy", "PAY/api")
+	p.SetGate(scope.NewGate(config.ReviewScopeConfig{DisabledRepos: []string{"PAY/legacy"}}))
+	p.Poll(context.Background())
+
+	// A failing repository does not stop the others; disabled ones are not listed
+	if len(sub.submitted) != 1 || sub.submitted[0] != "PAY/api/1@a1" {
+		t.Errorf("submitted %v, want PAY/api/1@a1", sub.submitted)
+	}
+	if bb.calls != 2 {
+		t.Errorf("calls = %d, want 2 (missing and api)", bb.calls)
+	}
+}
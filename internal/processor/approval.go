@@ -0,0 +1,69 @@
+package processor
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/rules"
+)
+
+// approvalRule returns the first approval rule matching the PR's repository
+func (p *PRProcessor) approvalRule(pr *domain.PullRequest) (config.ApprovalRule, bool) {
+	if !p.cfg.Approval.Enabled {
+		return config.ApprovalRule{}, false
+	}
+	repo := pr.ProjectKey + "/" + pr.RepoSlug
+	for _, r := range p.cfg.Approval.Projects {
+		if rules.MatchAny(r.Repos, repo) {
+			return r, true
+		}
+	}
+	return config.ApprovalRule{}, false
+}
+
+// reviewerStatus returns the verdict for a review: approved at or above ApproveScore without
+// CRITICAL findings, needs work below NeedsWorkScore, otherwise unapproved
+func reviewerStatus(rule config.ApprovalRule, score int, findings []domain.ReviewComment) string {
+	if rule.NeedsWorkScore > 0 && score < rule.NeedsWorkScore {
+		return config.ReviewerStatusNeedsWork
+	}
+	if rule.ApproveScore > 0 && score >= rule.ApproveScore {
+		for _, f := range findings {
+			if f.IsCritical() {
+				return config.ReviewerStatusUnapproved
+			}
+		}
+		return config.ReviewerStatusApproved
+	}
+	return config.ReviewerStatusUnapproved
+}
+
+// setReviewerStatus approves the PR or marks it "needs work" from the review score. findings are
+// the validated findings of the review, including those already posted. A review in the middle band
+// withdraws an earlier verdict of the bot. Incomplete reviews leave the status unchanged.
+func (p *PRProcessor) setReviewerStatus(ctx context.Context, pr *domain.PullRequest, review *domain.ReviewResult, findings []domain.ReviewComment) {
+	rule, ok := p.approvalRule(pr)
+	if !ok || !reviewComplete(review) {
+		return
+	}
+	status := reviewerStatus(rule, review.Score, findings)
+
+	pullRequestId, _ := strconv.Atoi(pr.ID)
+	_, err := p.commenter.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketSetReviewStatus, map[string]interface{}{
+		"projectKey":    pr.ProjectKey,
+		"repoSlug":      pr.RepoSlug,
+		"pullRequestId": pullRequestId,
+		"status":        status,
+	})
+	if err != nil {
+		slog.Warn("set reviewer status failed", "pr_id", pr.ID, "status", status, "error", err)
+		metrics.ReviewerStatuses.WithLabelValues(status, "error").Inc()
+		return
+	}
+	slog.Info("reviewer status set", "pr_id", pr.ID, "status", status, "score", review.Score)
+	metrics.ReviewerStatuses.WithLabelValues(status, "success").Inc()
+}
@@ -0,0 +1,60 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestReviewerStatus(t *testing.T) {
+	rule := config.ApprovalRule{ApproveScore: 85, NeedsWorkScore: 50}
+	critical := []domain.ReviewComment{{File: "a.go", Line: 1, Severity: "CRITICAL"}}
+	warning := []domain.ReviewComment{{File: "a.go", Line: 1, Severity: "WARNING"}}
+
+	tests := []struct {
+		rule     config.ApprovalRule
+		score    int
+		findings []domain.ReviewComment
+		want     string
+	}{
+		{rule, 90, warning, config.ReviewerStatusApproved},
+		{rule, 85, nil, config.ReviewerStatusApproved},
+		{rule, 95, critical, config.ReviewerStatusUnapproved},
+		{rule, 70, nil, config.ReviewerStatusUnapproved},
+		{rule, 49, nil, config.ReviewerStatusNeedsWork},
+		{config.ApprovalRule{NeedsWorkScore: 50}, 100, nil, config.ReviewerStatusUnapproved},
+		{config.ApprovalRule{ApproveScore: 80}, 10, nil, config.ReviewerStatusUnapproved},
+	}
+	for _, tt := range tests {
+		if got := reviewerStatus(tt.rule, tt.score, tt.findings); got != tt.want {
+			t.Errorf("reviewerStatus(%+v, %d) = %s, want %s", tt.rule, tt.score, got, tt.want)
+		}
+	}
+}
+
+func TestPRProcessor_SetReviewerStatus(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Approval = config.ApprovalConfig{Enabled: true, Projects: []config.ApprovalRule{
+		{Repos: []string{"PAY/*"}, ApproveScore: 80, NeedsWorkScore: 40},
+	}}
+
+	var statuses []string
+	commenter := &MockCommenter{CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+		if toolName == config.ToolBitbucketSetReviewStatus {
+			statuses = append(statuses, args["status"].(string))
+		}
+		return nil, nil
+	}}
+	p := NewPRProcessor(cfg, &MockReviewer{}, commenter, nil)
+
+	p.setReviewerStatus(context.Background(), &domain.PullRequest{ID: "1", ProjectKey: "PAY", RepoSlug: "api"}, &domain.ReviewResult{Score: 90}, nil)
+	// Unmatched repositories and incomplete reviews are left alone
+	p.setReviewerStatus(context.Background(), &domain.PullRequest{ID: "2", ProjectKey: "CORE", RepoSlug: "api"}, &domain.ReviewResult{Score: 90}, nil)
+	p.setReviewerStatus(context.Background(), &domain.PullRequest{ID: "3", ProjectKey: "PAY", RepoSlug: "api"}, &domain.ReviewResult{Score: 10, Outcome: domain.OutcomeUnparseable}, nil)
+
+	if len(statuses) != 1 || statuses[0] != config.ReviewerStatusApproved {
+		t.Errorf("statuses = %v, want one approval", statuses)
+	}
+}
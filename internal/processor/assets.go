@@ -0,0 +1,206 @@
+package processor
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/llm"
+	"pr-review-automation/internal/rules"
+
+	"github.com/openai/openai-go"
+	"github.com/tidwall/gjson"
+)
+
+// assetPrompt asks the vision model for a short sanity check of an added image
+const assetPrompt = `You check images added to a pull request, such as architecture diagrams and screenshots.
+Reply in at most three sentences: what the image shows and anything that looks wrong, unreadable or inconsistent
+(e.g. unlabeled components, arrows pointing nowhere, leaked credentials or personal data). Reply "Looks fine." if nothing stands out.`
+
+// imageMIMETypes are the raster formats sent to vision models
+var imageMIMETypes = map[string]string{
+	"png":  "image/png",
+	"jpg":  "image/jpeg",
+	"jpeg": "image/jpeg",
+	"gif":  "image/gif",
+	"webp": "image/webp",
+}
+
+// SetVisionClient sets the model used for image sanity comments (pipeline.assets.vision)
+func (p *PRProcessor) SetVisionClient(c llm.Client) {
+	p.vision = c
+}
+
+// reviewAssets returns the image and diagram files added by the PR. With vision enabled,
+// small raster images get a sanity comment from the model; the rest are noted as unreviewed.
+func (p *PRProcessor) reviewAssets(ctx context.Context, pr *domain.PullRequest, diff string) []domain.AssetNote {
+	ac := p.cfg.Pipeline.Assets
+	if !ac.Enabled || !rules.MatchAny(ac.Repos, pr.ProjectKey+"/"+pr.RepoSlug) {
+		return nil
+	}
+
+	var notes []domain.AssetNote
+	sent := 0
+	for _, file := range addedAssets(diff, ac.Extensions) {
+		note := domain.AssetNote{Path: file}
+		mimeType := imageMIMETypes[assetExtension(file)]
+		switch {
+		case !ac.Vision || p.vision == nil:
+			note.Reason = "no vision model"
+		case mimeType == "":
+			note.Reason = "not a raster image"
+		case sent >= ac.MaxImages:
+			note.Reason = "image limit reached"
+		default:
+			sent++
+			note.Comment, note.Reason = p.describeImage(ctx, pr, file, mimeType)
+		}
+		notes = append(notes, note)
+	}
+	return notes
+}
+
+// describeImage sends one image to the vision model. It returns the comment, or the reason it has none.
+func (p *PRProcessor) describeImage(ctx context.Context, pr *domain.PullRequest, file, mimeType string) (string, string) {
+	content, err := p.fetchFileContent(ctx, pr, file)
+	if err != nil {
+		slog.Warn("fetch asset failed", "file", file, "error", err)
+		return "", "could not be fetched"
+	}
+	if limit := p.cfg.Pipeline.Assets.MaxSize; limit > 0 && len(content) > limit {
+		return "", fmt.Sprintf("larger than %d KB", limit/1024)
+	}
+
+	dataURL := "data:" + mimeType + ";base64," + base64.StdEncoding.EncodeToString([]byte(content))
+	resp, err := p.vision.Chat(ctx, openai.ChatCompletionNewParams{
+		Messages: []openai.ChatCompletionMessageParamUnion{
+			openai.SystemMessage(assetPrompt),
+			openai.UserMessage([]openai.ChatCompletionContentPartUnionParam{
+				openai.TextContentPart(fmt.Sprintf("File %s added in pull request %q.", file, pr.Title)),
+				openai.ImageContentPart(openai.ChatCompletionContentPartImageImageURLParam{URL: dataURL, Detail: "low"}),
+			}),
+		},
+		MaxTokens: openai.Int(300),
+	})
+	if err != nil {
+		slog.Warn("asset review failed", "file", file, "error", err)
+		return "", "model request failed"
+	}
+	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
+		return "", "empty model response"
+	}
+	return strings.TrimSpace(resp.Choices[0].Message.Content), ""
+}
+
+// fetchFileContent reads a file at the PR's latest commit
+func (p *PRProcessor) fetchFileContent(ctx context.Context, pr *domain.PullRequest, file string) (string, error) {
+	result, err := p.commenter.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetFileContent, map[string]interface{}{
+		"projectKey": pr.ProjectKey,
+		"repoSlug":   pr.RepoSlug,
+		"path":       file,
+		"at":         pr.LatestCommit,
+	})
+	if err != nil {
+		return "", err
+	}
+	if s, ok := result.(string); ok {
+		return s, nil
+	}
+	jsonBytes, err := json.Marshal(result)
+	if err != nil {
+		return "", err
+	}
+	return gjson.GetBytes(jsonBytes, "content.0.text").String(), nil
+}
+
+// addedAssets returns the files with one of the extensions that the diff adds.
+// Added files are recognized by "new file mode", a /dev/null source or a binary-file notice.
+func addedAssets(diff string, extensions []string) []string {
+	var files []string
+	seen := make(map[string]bool)
+	add := func(file string) {
+		file = domain.NormalizePath(strings.TrimSpace(file))
+		if file != "" && !seen[file] && slices.Contains(extensions, assetExtension(file)) {
+			seen[file] = true
+			files = append(files, file)
+		}
+	}
+
+	current, added := "", false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			current, added = "", false
+			if i := strings.LastIndex(line, " b/"); i >= 0 {
+				current = line[i+1:]
+			}
+		case strings.HasPrefix(line, "new file mode"), line == "--- /dev/null":
+			added = true
+		case strings.HasPrefix(line, "+++ ") && line != "+++ /dev/null":
+			current = strings.TrimPrefix(line, "+++ ")
+			if added {
+				add(current)
+			}
+		case strings.HasPrefix(line, "Binary files /dev/null and "):
+			add(strings.TrimSuffix(strings.TrimPrefix(line, "Binary files /dev/null and "), " differ"))
+		case strings.HasPrefix(line, "index ") && added:
+			// Binary files without a notice only carry the header
+			add(current)
+		}
+	}
+	return files
+}
+
+func assetExtension(file string) string {
+	return strings.ToLower(strings.TrimPrefix(path.Ext(file), "."))
+}
+
+// coverageNote returns the summary section listing changed source files without changed tests
+func coverageNote(gaps []domain.CoverageGap) string {
+	if len(gaps) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("**Untested changes** (no tests changed for these files)")
+	for _, g := range gaps {
+		functions := make([]string, len(g.Functions))
+		for i, f := range g.Functions {
+			functions[i] = "`" + f + "`"
+		}
+		fmt.Fprintf(&sb, "\n- `%s`: %s (tests expected in `%s`)", g.File, strings.Join(functions, ", "), g.TestFile)
+	}
+	for _, g := range gaps {
+		if g.Skeleton != "" {
+			fmt.Fprintf(&sb, "\n\nSuggested tests for `%s`:\n```%s\n%s```", g.File, g.Language, g.Skeleton)
+		}
+	}
+	return sb.String()
+}
+
+// assetNote returns the summary section listing added assets
+func assetNote(notes []domain.AssetNote) string {
+	var reviewed, unreviewed []string
+	for _, n := range notes {
+		if n.Comment != "" {
+			reviewed = append(reviewed, fmt.Sprintf("- `%s`: %s", n.Path, n.Comment))
+		} else {
+			unreviewed = append(unreviewed, fmt.Sprintf("- `%s` (%s)", n.Path, n.Reason))
+		}
+	}
+
+	var sections []string
+	if len(reviewed) > 0 {
+		sections = append(sections, "**Image check**\n"+strings.Join(reviewed, "\n"))
+	}
+	if len(unreviewed) > 0 {
+		sections = append(sections, "**Unreviewed assets** (check manually)\n"+strings.Join(unreviewed, "\n"))
+	}
+	return strings.Join(sections, "\n\n")
+}
@@ -0,0 +1,160 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+
+	"github.com/openai/openai-go"
+)
+
+// visionLLM answers every chat request with a fixed reply
+type visionLLM struct {
+	reply string
+	calls int
+}
+
+func (v *visionLLM) Chat(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	v.calls++
+	return &openai.ChatCompletion{Choices: []openai.ChatCompletionChoice{{Message: openai.ChatCompletionMessage{Content: v.reply}}}}, nil
+}
+
+func (v *visionLLM) SimpleTextQuery(ctx context.Context, systemPrompt, userInput string) (string, error) {
+	return v.reply, nil
+}
+
+const assetDiff = `diff --git a/docs/arch.png b/docs/arch.png
+new file mode 100644
+index 0000000..1234567
+Binary files /dev/null and b/docs/arch.png differ
+diff --git a/docs/flow.drawio b/docs/flow.drawio
+new file mode 100644
+index 0000000..89abcde
+--- /dev/null
++++ b/docs/flow.drawio
+@@ -0,0 +1 @@
++<mxfile/>
+diff --git a/docs/logo.png b/docs/logo.png
+index 1111111..2222222 100644
+Binary files a/docs/logo.png and b/docs/logo.png differ
+diff --git a/docs/big.jpg b/docs/big.jpg
+new file mode 100644
+index 0000000..3333333
+Binary files /dev/null and b/docs/big.jpg differ
+`
+
+func TestAddedAssets(t *testing.T) {
+	got := addedAssets(assetDiff, []string{"png", "jpg", "drawio"})
+	want := []string{"docs/arch.png", "docs/flow.drawio", "docs/big.jpg"}
+	if strings.Join(got, ",") != strings.Join(want, ",") {
+		t.Errorf("assets = %v, want %v", got, want)
+	}
+}
+
+func TestPRProcessor_ReviewAssets(t *testing.T) {
+	tests := []struct {
+		name      string
+		vision    bool
+		repos     []string
+		maxImages int
+		want      []domain.AssetNote
+		wantCalls int
+	}{
+		{
+			name:   "listed without vision",
+			vision: false, maxImages: 3,
+			want: []domain.AssetNote{
+				{Path: "docs/arch.png", Reason: "no vision model"},
+				{Path: "docs/flow.drawio", Reason: "no vision model"},
+				{Path: "docs/big.jpg", Reason: "no vision model"},
+			},
+		},
+		{
+			name:   "small images reviewed",
+			vision: true, maxImages: 3,
+			want: []domain.AssetNote{
+				{Path: "docs/arch.png", Comment: "Looks fine."},
+				{Path: "docs/flow.drawio", Reason: "not a raster image"},
+				{Path: "docs/big.jpg", Reason: "larger than 1 KB"},
+			},
+			wantCalls: 1,
+		},
+		{
+			name:   "image limit",
+			vision: true, maxImages: 0,
+			want: []domain.AssetNote{
+				{Path: "docs/arch.png", Reason: "image limit reached"},
+				{Path: "docs/flow.drawio", Reason: "not a raster image"},
+				{Path: "docs/big.jpg", Reason: "image limit reached"},
+			},
+		},
+		{name: "repository not selected", repos: []string{"OTHER/*"}, maxImages: 3},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			commenter := &MockCommenter{
+				CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+					if toolName != config.ToolBitbucketGetFileContent || args["at"] != "abc" {
+						return nil, errors.New("unexpected call")
+					}
+					if args["path"] == "docs/big.jpg" {
+						return strings.Repeat("x", 2000), nil
+					}
+					return "\x89PNG", nil
+				},
+			}
+			cfg := &config.Config{}
+			cfg.Pipeline.Assets = config.AssetsConfig{Enabled: true, Vision: tt.vision, Repos: tt.repos, MaxSize: 1024, MaxImages: tt.maxImages,
+				Extensions: []string{"png", "jpg", "drawio"}}
+			vision := &visionLLM{reply: " Looks fine.\n"}
+			p := NewPRProcessor(cfg, &MockReviewer{}, commenter, nil)
+			p.SetVisionClient(vision)
+
+			got := p.reviewAssets(context.Background(), &domain.PullRequest{ProjectKey: "P", RepoSlug: "r", LatestCommit: "abc"}, assetDiff)
+			if len(got) != len(tt.want) {
+				t.Fatalf("notes = %+v, want %+v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Errorf("note %d = %+v, want %+v", i, got[i], tt.want[i])
+				}
+			}
+			if vision.calls != tt.wantCalls {
+				t.Errorf("model calls = %d, want %d", vision.calls, tt.wantCalls)
+			}
+		})
+	}
+}
+
+func TestAssetNote(t *testing.T) {
+	note := assetNote([]domain.AssetNote{
+		{Path: "docs/arch.png", Comment: "Looks fine."},
+		{Path: "docs/flow.drawio", Reason: "not a raster image"},
+	})
+	want := "**Image check**\n- `docs/arch.png`: Looks fine.\n\n**Unreviewed assets** (check manually)\n- `docs/flow.drawio` (not a raster image)"
+	if note != want {
+		t.Errorf("note = %q, want %q", note, want)
+	}
+}
+
+func TestCoverageNote(t *testing.T) {
+	note := coverageNote([]domain.CoverageGap{
+		{File: "server/http.go", TestFile: "server/http_test.go", Functions: []string{"Server.Start", "Parse"}},
+		{File: "app/pricing.py", TestFile: "app/test_pricing.py", Functions: []string{"quote"}, Language: "python", Skeleton: "def test_quote():\n    pass\n"},
+	})
+	want := "**Untested changes** (no tests changed for these files)\n" +
+		"- `server/http.go`: `Server.Start`, `Parse` (tests expected in `server/http_test.go`)\n" +
+		"- `app/pricing.py`: `quote` (tests expected in `app/test_pricing.py`)\n\n" +
+		"Suggested tests for `app/pricing.py`:\n```python\ndef test_quote():\n    pass\n```"
+	if note != want {
+		t.Errorf("note = %q, want %q", note, want)
+	}
+	if coverageNote(nil) != "" {
+		t.Error("empty gaps should not add a note")
+	}
+}
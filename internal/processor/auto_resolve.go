@@ -0,0 +1,89 @@
+package processor
+
+import (
+	"context"
+	"log/slog"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/validator"
+)
+
+// resolveOutdated resolves or deletes the bot's comments from earlier commits whose findings are
+// gone: the file left the diff, or the new review has no finding on it (file comments) or within
+// auto_resolve.line_window lines (inline comments). findings are the validated findings of the
+// new review, before deduplication against the posted comments. Incomplete reviews resolve nothing.
+func (p *PRProcessor) resolveOutdated(ctx context.Context, pr *domain.PullRequest, review *domain.ReviewResult, findings []domain.ReviewComment, v *validator.CommentValidator) {
+	cfg := p.cfg.Pipeline.AutoResolve
+	if !cfg.Enabled || !reviewComplete(review) {
+		return
+	}
+	data, err := fetchComments(ctx, p.commenter, pr)
+	if err != nil {
+		slog.Warn("fetch comments for auto-resolve failed", "error", err)
+		return
+	}
+
+	byFile := make(map[string][]domain.ReviewComment)
+	for _, f := range findings {
+		byFile[domain.NormalizePath(f.File)] = append(byFile[domain.NormalizePath(f.File)], f)
+	}
+	action := cfg.Action
+	if action == "" {
+		action = config.AutoResolveActionResolve
+	}
+
+	for _, pc := range p.markers().parsePostedComments(data) {
+		if pc.resolved || pc.commit == pr.LatestCommit || !outdated(pc, byFile[domain.NormalizePath(pc.path)], v, cfg.LineWindow) {
+			continue
+		}
+		if err := p.resolveComment(ctx, pr, pc, action); err != nil {
+			slog.Warn("auto-resolve comment failed", "comment_id", pc.id, "file", pc.path, "action", action, "error", err)
+			metrics.OutdatedComments.WithLabelValues(action, "error").Inc()
+			continue
+		}
+		slog.Info("outdated comment resolved", "pr_id", pr.ID, "comment_id", pc.id, "file", pc.path, "line", pc.line, "action", action)
+		metrics.OutdatedComments.WithLabelValues(action, "success").Inc()
+	}
+}
+
+// reviewComplete reports whether every part of the review produced a usable answer, so that
+// missing findings mean fixed code rather than a failed chunk
+func reviewComplete(review *domain.ReviewResult) bool {
+	if review.Outcome != "" && review.Outcome != domain.OutcomeOK {
+		return false
+	}
+	if review.Report != nil {
+		for _, c := range review.Report.Chunks {
+			if c.Error != "" || (c.Outcome != "" && c.Outcome != domain.OutcomeOK) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+// outdated reports whether the posted comment no longer matches a finding of its file
+func outdated(pc postedComment, fileFindings []domain.ReviewComment, v *validator.CommentValidator, window int) bool {
+	if v != nil && !v.FileInDiff(pc.path) {
+		return true
+	}
+	if pc.mType == config.MarkerTypeFile {
+		return len(fileFindings) == 0
+	}
+	for _, f := range fileFindings {
+		if d := int(f.Line) - pc.line; d >= -window && d <= window {
+			return false
+		}
+	}
+	return true
+}
+
+// resolveComment marks the comment resolved, or deletes it
+func (p *PRProcessor) resolveComment(ctx context.Context, pr *domain.PullRequest, pc postedComment, action string) error {
+	if action == config.AutoResolveActionDelete {
+		return p.callCommentTool(ctx, pr, config.ToolBitbucketDeleteComment, pc, map[string]interface{}{})
+	}
+	return p.callCommentTool(ctx, pr, config.ToolBitbucketUpdateComment, pc, map[string]interface{}{"state": "RESOLVED"})
+}
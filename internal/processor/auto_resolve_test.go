@@ -0,0 +1,67 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/validator"
+)
+
+func TestPRProcessor_ResolveOutdated(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Pipeline.AutoResolve = config.AutoResolveConfig{Enabled: true, Action: config.AutoResolveActionResolve, LineWindow: 3}
+	markers := newMarkerSet(cfg.Pipeline.Markers)
+
+	existing := fmt.Sprintf(`{"values":[
+		{"id":1,"version":2,"text":%q},
+		{"id":2,"version":0,"text":%q},
+		{"id":3,"version":0,"text":%q},
+		{"id":4,"version":0,"text":%q},
+		{"id":5,"version":0,"state":"RESOLVED","text":%q},
+		{"id":6,"version":0,"text":%q},
+		{"id":7,"version":0,"text":%q}
+	]}`,
+		markers.inlineMarker("a.go", 10, "old")+"\nfixed since",
+		markers.inlineMarker("a.go", 20, "old")+"\nstill there",
+		markers.inlineMarker("a.go", 12, "new")+"\nposted at the current commit",
+		markers.inlineMarker("gone.go", 5, "old")+"\nfile reverted",
+		markers.inlineMarker("a.go", 30, "old")+"\nalready resolved",
+		markers.fileMarker("b.go", "old")+"\nno findings left",
+		markers.summaryMarker("old")+"\nsummary")
+
+	var resolved []int64
+	commenter := &MockCommenter{CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+		switch toolName {
+		case config.ToolBitbucketGetComments:
+			return existing, nil
+		case config.ToolBitbucketUpdateComment:
+			if args["state"] != "RESOLVED" {
+				t.Errorf("update args = %v", args)
+			}
+			resolved = append(resolved, args["commentId"].(int64))
+		}
+		return nil, nil
+	}}
+	p := NewPRProcessor(cfg, &MockReviewer{}, commenter, nil)
+	pr := &domain.PullRequest{ID: "7", ProjectKey: "PAY", RepoSlug: "api", LatestCommit: "new"}
+	diff := "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -1,1 +1,40 @@\n+x\n" +
+		"diff --git a/b.go b/b.go\n--- a/b.go\n+++ b/b.go\n@@ -1,1 +1,2 @@\n+y\n"
+	findings := []domain.ReviewComment{{File: "a.go", Line: 22, Comment: "still there"}}
+
+	p.resolveOutdated(context.Background(), pr, &domain.ReviewResult{}, findings, validator.NewCommentValidator(diff))
+	want := []int64{1, 4, 6}
+	if fmt.Sprint(resolved) != fmt.Sprint(want) {
+		t.Errorf("resolved = %v, want %v", resolved, want)
+	}
+
+	// A failed chunk resolves nothing
+	resolved = nil
+	incomplete := &domain.ReviewResult{Report: &domain.ExecutionReport{Chunks: []domain.ChunkReport{{Index: 1, Error: "timeout"}}}}
+	p.resolveOutdated(context.Background(), pr, incomplete, nil, validator.NewCommentValidator(diff))
+	if len(resolved) != 0 {
+		t.Errorf("resolved %v after an incomplete review", resolved)
+	}
+}
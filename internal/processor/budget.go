@@ -0,0 +1,50 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"pr-review-automation/internal/budget"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+)
+
+// SetBudget skips reviews once a budgets limit is reached and charges finished reviews to it
+func (p *PRProcessor) SetBudget(b *budget.Manager) {
+	p.budget = b
+}
+
+// overBudget returns the budgets limit the PR's project reached, nil without budgets
+func (p *PRProcessor) overBudget(pr *domain.PullRequest) *budget.Exceeded {
+	if p.budget == nil {
+		return nil
+	}
+	return p.budget.Check(pr.ProjectKey)
+}
+
+// skipOverBudget records the review of a PR as skipped by an exhausted budget and explains in a
+// skip note when reviews resume
+func (p *PRProcessor) skipOverBudget(ctx context.Context, pr *domain.PullRequest, exceeded *budget.Exceeded) {
+	p.countPR(pr, "skipped")
+	metrics.BudgetExhausted.WithLabelValues(exceeded.Scope, exceeded.Name()).Inc()
+	p.recordSkip(pr, domain.SkipReasonBudget, exceeded.String())
+	p.postSkipNote(ctx, pr, domain.SkipReasonBudget, budgetNote(exceeded))
+}
+
+// budgetNote explains which limit stopped the review and when reviews resume
+func budgetNote(exceeded *budget.Exceeded) string {
+	var sb strings.Builder
+	sb.WriteString("⚠️ **AI review skipped: the review budget is exhausted.**\n\n")
+	whose := "this project's"
+	if exceeded.Scope == budget.ScopeGlobal {
+		whose = "the shared"
+	}
+	spent := fmt.Sprintf("%.0f of %.0f tokens", exceeded.Spent, exceeded.Limit)
+	if exceeded.Unit == budget.UnitCost {
+		spent = fmt.Sprintf("$%.2f of $%.2f", exceeded.Spent, exceeded.Limit)
+	}
+	fmt.Fprintf(&sb, "Reviews have used %s %s budget (%s). ", whose, exceeded.Period, spent)
+	fmt.Fprintf(&sb, "Automated reviews resume at %s.", exceeded.Resets.Format("2006-01-02 15:04 UTC"))
+	return sb.String()
+}
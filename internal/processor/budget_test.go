@@ -0,0 +1,54 @@
+package processor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/budget"
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestPRProcessor_Budget(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Budgets = config.BudgetConfig{Enabled: true, Project: config.BudgetLimits{DailyTokens: 1000}}
+	cfg.Pipeline.SkipNotes.Enabled = true
+
+	var notes []string
+	commenter := &MockCommenter{CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+		if toolName == config.ToolBitbucketAddComment {
+			notes = append(notes, args["commentText"].(string))
+		}
+		return `{"values": []}`, nil
+	}}
+	reviews := 0
+	reviewer := &MockReviewer{ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+		reviews++
+		return &domain.ReviewResult{Score: 100, Usage: &domain.TokenUsage{PromptTokens: 900, CompletionTokens: 100}}, nil
+	}}
+	p := NewPRProcessor(cfg, reviewer, commenter, nil)
+	p.SetBudget(budget.New(cfg.Budgets))
+	pr := func(project string) *domain.PullRequest {
+		return &domain.PullRequest{ID: "1", ProjectKey: project, RepoSlug: "api", LatestCommit: "abc"}
+	}
+
+	if err := p.ProcessPullRequest(context.Background(), pr("PAY")); err != nil {
+		t.Fatalf("ProcessPullRequest: %v", err)
+	}
+	notes = nil
+	if err := p.ProcessPullRequest(context.Background(), pr("PAY")); err != nil {
+		t.Fatalf("ProcessPullRequest: %v", err)
+	}
+	if reviews != 1 || len(notes) != 1 || !strings.Contains(notes[0], "budget is exhausted") || !strings.Contains(notes[0], "1000 of 1000 tokens") {
+		t.Errorf("over budget: reviews = %d, notes = %q", reviews, notes)
+	}
+
+	// Other projects have their own budget
+	if err := p.ProcessPullRequest(context.Background(), pr("OPS")); err != nil {
+		t.Fatalf("ProcessPullRequest: %v", err)
+	}
+	if reviews != 2 {
+		t.Errorf("OPS review skipped by the PAY budget")
+	}
+}
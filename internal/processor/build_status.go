@@ -0,0 +1,85 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+)
+
+// buildStatusTimeout bounds the final build status call, which also runs after a review timed out
+const buildStatusTimeout = 10 * time.Second
+
+// buildStatusEnabled reports whether reviews of this PR publish a build status. Dry runs write
+// nothing to the SCM.
+func (p *PRProcessor) buildStatusEnabled(pr *domain.PullRequest) bool {
+	return p.cfg.BuildStatus.Enabled && pr.LatestCommit != "" && !p.dryRun(pr)
+}
+
+// finishBuildStatus publishes the outcome of a review: FAILED when processing failed (or, with
+// fail_on_critical, when CRITICAL findings remain), SUCCESSFUL otherwise. findings are the
+// validated findings, including those already posted. A nil review without error is a skipped review.
+func (p *PRProcessor) finishBuildStatus(ctx context.Context, pr *domain.PullRequest, review *domain.ReviewResult, findings []domain.ReviewComment, err error) {
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), buildStatusTimeout)
+	defer cancel()
+
+	state, description := config.BuildStateSuccessful, "Review skipped"
+	switch {
+	case err != nil:
+		state, description = config.BuildStateFailed, "Review failed"
+	case review == nil:
+	default:
+		critical := 0
+		for _, c := range findings {
+			if c.IsCritical() {
+				critical++
+			}
+		}
+		description = fmt.Sprintf("Score %d, %d findings", review.Score, len(findings))
+		if critical > 0 {
+			description += fmt.Sprintf(" (%d critical)", critical)
+			if p.cfg.BuildStatus.FailOnCritical {
+				state = config.BuildStateFailed
+			}
+		}
+	}
+	p.setBuildStatus(ctx, pr, state, description, p.buildStatusURL(pr, review))
+}
+
+// buildStatusURL links the build status to the stored review when the server's public URL is
+// known, and to the pull request otherwise
+func (p *PRProcessor) buildStatusURL(pr *domain.PullRequest, review *domain.ReviewResult) string {
+	base := strings.TrimRight(p.cfg.Pipeline.Summary.Footer.ReportURL, "/")
+	if base != "" && review != nil && review.Provenance != nil && review.Provenance.ReviewID != "" {
+		return base + "/api/v1/reviews/" + review.Provenance.ReviewID
+	}
+	return pr.WebURL
+}
+
+// setBuildStatus publishes a build status for the PR's latest commit
+func (p *PRProcessor) setBuildStatus(ctx context.Context, pr *domain.PullRequest, state, description, url string) {
+	args := map[string]interface{}{
+		"projectKey":  pr.ProjectKey,
+		"repoSlug":    pr.RepoSlug,
+		"commitId":    pr.LatestCommit,
+		"key":         p.cfg.BuildStatus.Key,
+		"name":        p.cfg.BuildStatus.Name,
+		"state":       state,
+		"description": description,
+	}
+	if url != "" {
+		args["url"] = url
+	}
+	if _, err := p.commenter.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketSetBuildStatus, args); err != nil {
+		slog.Warn("set build status failed", "pr_id", pr.ID, "commit", pr.LatestCommit, "state", state, "error", err)
+		metrics.BuildStatuses.WithLabelValues(state, "error").Inc()
+		return
+	}
+	slog.Debug("build status set", "pr_id", pr.ID, "commit", pr.LatestCommit, "state", state)
+	metrics.BuildStatuses.WithLabelValues(state, "success").Inc()
+}
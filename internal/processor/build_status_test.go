@@ -0,0 +1,68 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestPRProcessor_BuildStatus(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.BuildStatus = config.BuildStatusConfig{Enabled: true, Key: config.DefaultBuildStatusKey, Name: "AI Review", FailOnCritical: true}
+
+	var states []string
+	commenter := &MockCommenter{CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+		switch toolName {
+		case config.ToolBitbucketGetComments:
+			return `{"values": []}`, nil
+		case config.ToolBitbucketSetBuildStatus:
+			if args["commitId"] != "abc" || args["key"] != config.DefaultBuildStatusKey {
+				t.Errorf("build status args = %v", args)
+			}
+			states = append(states, args["state"].(string))
+		}
+		return nil, nil
+	}}
+	result := &domain.ReviewResult{Score: 90, Summary: "ok"}
+	var reviewErr error
+	reviewer := &MockReviewer{ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+		return result, reviewErr
+	}}
+	p := NewPRProcessor(cfg, reviewer, commenter, nil)
+	pr := func() *domain.PullRequest {
+		return &domain.PullRequest{ID: "7", ProjectKey: "PAY", RepoSlug: "api", LatestCommit: "abc"}
+	}
+
+	tests := []struct {
+		name     string
+		comments []domain.ReviewComment
+		err      error
+		want     string
+	}{
+		{"clean", nil, nil, config.BuildStateSuccessful},
+		{"critical", []domain.ReviewComment{{Comment: "leak", Severity: "CRITICAL"}}, nil, config.BuildStateFailed},
+		{"failed", nil, errors.New("llm down"), config.BuildStateFailed},
+	}
+	for _, tt := range tests {
+		states = nil
+		result = &domain.ReviewResult{Score: 90, Summary: "ok", Comments: tt.comments}
+		reviewErr = tt.err
+		_ = p.ProcessPullRequest(context.Background(), pr())
+		if len(states) != 2 || states[0] != config.BuildStateInProgress || states[1] != tt.want {
+			t.Errorf("%s: states = %v, want INPROGRESS then %s", tt.name, states, tt.want)
+		}
+	}
+
+	// Dry runs publish nothing
+	states = nil
+	dry := pr()
+	dry.Overrides = &domain.ReviewOverrides{DryRun: true}
+	reviewErr = nil
+	_ = p.ProcessPullRequest(context.Background(), dry)
+	if len(states) != 0 {
+		t.Errorf("dry run published %v", states)
+	}
+}
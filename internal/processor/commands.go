@@ -0,0 +1,231 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/llm"
+	"pr-review-automation/internal/metrics"
+
+	"github.com/tidwall/gjson"
+)
+
+// CommandHandler runs slash commands posted in pull request comments
+type CommandHandler interface {
+	HandleCommand(ctx context.Context, pr *domain.PullRequest, cmd domain.Command) error
+}
+
+// explainPrompt asks the model to explain a piece of code for /ai explain
+const explainPrompt = `You explain code in a pull request to the developer who asked about it.
+Explain what the marked line does in the context of the surrounding code, why it might be written that way
+and anything risky about it. Be concise: at most two short paragraphs, no headings, no restating of the code.`
+
+// SetCommandClient sets the model answering /ai explain
+func (p *PRProcessor) SetCommandClient(c llm.Client) {
+	p.commandLLM = c
+}
+
+// HandleCommand runs a slash command and answers with a reply to the comment holding it.
+// "/ai review" is queued by the webhook handler like an API-triggered review; here it reviews
+// the PR directly.
+func (p *PRProcessor) HandleCommand(ctx context.Context, pr *domain.PullRequest, cmd domain.Command) error {
+	ctx = domain.WithProvider(ctx, pr.Provider)
+	ctx = domain.WithProject(ctx, pr.ProjectKey)
+	slog.Info("slash command", "pr_id", pr.ID, "repo", pr.RepoSlug, "command", cmd.Name, "args", cmd.Args, "author", cmd.Author)
+
+	var err error
+	switch cmd.Name {
+	case domain.CommandReview:
+		// Requested reviews run even while automatic reviews are paused
+		if pr.Overrides == nil {
+			pr.Overrides = &domain.ReviewOverrides{}
+		}
+		err = p.ProcessPullRequest(ctx, pr)
+	case domain.CommandExplain:
+		err = p.explain(ctx, pr, cmd)
+	case domain.CommandIgnore:
+		err = p.ignore(ctx, pr, cmd)
+	case domain.CommandHelp:
+		err = p.reply(ctx, pr, cmd, p.commandUsage())
+	default:
+		metrics.SlashCommands.WithLabelValues("unknown", "rejected").Inc()
+		return p.reply(ctx, pr, cmd, fmt.Sprintf("Unknown command `%s`.\n\n%s", cmd.Name, p.commandUsage()))
+	}
+	result := "success"
+	if err != nil {
+		result = "error"
+	}
+	metrics.SlashCommands.WithLabelValues(cmd.Name, result).Inc()
+	return err
+}
+
+// commandUsage lists the commands with the configured prefix
+func (p *PRProcessor) commandUsage() string {
+	prefix := p.cfg.Commands.Prefix
+	return fmt.Sprintf("Commands:\n"+
+		"- `%[1]s review`: review this pull request now\n"+
+		"- `%[1]s explain path/to/file.go:42`: explain the code at a line of the latest commit\n"+
+		"- `%[1]s ignore`: stop automatic reviews of this pull request; delete the note to resume them", prefix)
+}
+
+// explain answers "/ai explain file:line" with the model's explanation of the code around the line
+func (p *PRProcessor) explain(ctx context.Context, pr *domain.PullRequest, cmd domain.Command) error {
+	file, line, ok := parseLocation(cmd.Args)
+	if !ok {
+		return p.reply(ctx, pr, cmd, fmt.Sprintf("Usage: `%s explain path/to/file.go:42`", p.cfg.Commands.Prefix))
+	}
+	if p.commandLLM == nil {
+		return p.reply(ctx, pr, cmd, "Explanations are not available on this reviewer.")
+	}
+
+	content, err := p.fetchFileContent(ctx, pr, file)
+	if err != nil || content == "" {
+		slog.Warn("fetch file for explain failed", "file", file, "error", err)
+		return p.reply(ctx, pr, cmd, fmt.Sprintf("Could not read `%s` at the latest commit.", file))
+	}
+	snippet, ok := numberedSnippet(content, line, p.cfg.Commands.ExplainLines)
+	if !ok {
+		return p.reply(ctx, pr, cmd, fmt.Sprintf("`%s` has no line %d.", file, line))
+	}
+
+	input := fmt.Sprintf("Pull request: %s\nFile: %s\nMarked line: %d (prefixed with >)\n\n```\n%s\n```", pr.Title, file, line, snippet)
+	answer, err := p.commandLLM.SimpleTextQuery(ctx, explainPrompt, input)
+	if err != nil {
+		_ = p.reply(ctx, pr, cmd, "The explanation failed; please try again later.")
+		return fmt.Errorf("explain %s:%d: %w", file, line, err)
+	}
+	return p.reply(ctx, pr, cmd, fmt.Sprintf("**`%s:%d`**\n\n%s", file, line, strings.TrimSpace(answer)))
+}
+
+// ignore answers "/ai ignore" with the note that pauses automatic reviews of the PR while it exists
+func (p *PRProcessor) ignore(ctx context.Context, pr *domain.PullRequest, cmd domain.Command) error {
+	text := fmt.Sprintf("%s\n🔕 Automatic AI reviews of this pull request are paused", p.markers().ignoreMarker())
+	if cmd.Author != "" {
+		text += " at the request of " + cmd.Author
+	}
+	text += fmt.Sprintf(". Comment `%s review` for an on-demand review, or delete this comment to resume them.", p.cfg.Commands.Prefix)
+	// A top-level note, so it is easy to find and delete
+	if err := postReply(ctx, p.commenter, pr, 0, text); err != nil {
+		metrics.CommentPostFailures.WithLabelValues("command_reply").Inc()
+		return fmt.Errorf("post ignore note: %w", err)
+	}
+	return nil
+}
+
+// reply posts text as a reply to the comment holding the command
+func (p *PRProcessor) reply(ctx context.Context, pr *domain.PullRequest, cmd domain.Command, text string) error {
+	if err := postReply(ctx, p.commenter, pr, cmd.CommentID, p.markers().replyMarker()+"\n"+text); err != nil {
+		metrics.CommentPostFailures.WithLabelValues("command_reply").Inc()
+		return fmt.Errorf("post command reply: %w", err)
+	}
+	return nil
+}
+
+// postReply posts a comment on pr, threaded under parentID when it is set
+func postReply(ctx context.Context, tools Commenter, pr *domain.PullRequest, parentID int64, text string) error {
+	prID, err := strconv.Atoi(pr.ID)
+	if err != nil {
+		return fmt.Errorf("invalid pull request id %q", pr.ID)
+	}
+	args := map[string]interface{}{
+		"projectKey":    pr.ProjectKey,
+		"repoSlug":      pr.RepoSlug,
+		"pullRequestId": prID,
+		"commentText":   text,
+	}
+	if parentID > 0 {
+		args["parentId"] = parentID
+	}
+	_, err = tools.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketAddComment, args)
+	return err
+}
+
+// fetchComments returns the get-comments response of pr as JSON, unwrapped from the MCP
+// text content
+func fetchComments(ctx context.Context, tools Commenter, pr *domain.PullRequest) ([]byte, error) {
+	prID, _ := strconv.Atoi(pr.ID)
+	result, err := tools.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetComments, map[string]interface{}{
+		"projectKey":    pr.ProjectKey,
+		"repoSlug":      pr.RepoSlug,
+		"pullRequestId": prID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	var data []byte
+	if s, ok := result.(string); ok {
+		data = []byte(s)
+	} else if data, err = json.Marshal(result); err != nil {
+		return nil, err
+	}
+	if text := gjson.GetBytes(data, "content.0.text"); text.Exists() {
+		data = []byte(text.String())
+	}
+	return data, nil
+}
+
+// reviewsPaused reports whether the PR carries the note of "/ai ignore". Failures to read the
+// comments are logged and do not pause reviews.
+func (p *PRProcessor) reviewsPaused(ctx context.Context, pr *domain.PullRequest) bool {
+	paused, err := p.hasComment(ctx, pr, p.markers().ignoreMarker())
+	if err != nil {
+		slog.Warn("fetch comments for ignore note failed", "pr_id", pr.ID, "error", err)
+		return false
+	}
+	return paused
+}
+
+// hasComment reports whether a comment of pr contains marker
+func (p *PRProcessor) hasComment(ctx context.Context, pr *domain.PullRequest, marker string) (bool, error) {
+	data, err := fetchComments(ctx, p.commenter, pr)
+	if err != nil {
+		return false, err
+	}
+	markers := p.markers()
+	found := false
+	gjson.GetBytes(data, "values").ForEach(func(_, value gjson.Result) bool {
+		text := markers.migrate(firstOf(value, "content.raw", "text", "comment.text").String())
+		found = strings.Contains(text, marker)
+		return !found
+	})
+	return found, nil
+}
+
+// parseLocation parses "path/to/file.go:42"
+func parseLocation(args string) (file string, line int, ok bool) {
+	loc := strings.Trim(strings.TrimSpace(args), "`")
+	i := strings.LastIndex(loc, ":")
+	if i <= 0 {
+		return "", 0, false
+	}
+	line, err := strconv.Atoi(loc[i+1:])
+	if err != nil || line <= 0 {
+		return "", 0, false
+	}
+	return domain.NormalizePath(loc[:i]), line, true
+}
+
+// numberedSnippet returns the lines within around lines of line, numbered, with the line itself
+// marked by ">"; ok is false when the file has no such line
+func numberedSnippet(content string, line, around int) (string, bool) {
+	lines := strings.Split(strings.TrimRight(content, "\n"), "\n")
+	if line > len(lines) {
+		return "", false
+	}
+	from, to := max(line-around, 1), min(line+around, len(lines))
+	var sb strings.Builder
+	for n := from; n <= to; n++ {
+		mark := " "
+		if n == line {
+			mark = ">"
+		}
+		fmt.Fprintf(&sb, "%s%5d  %s\n", mark, n, lines[n-1])
+	}
+	return strings.TrimRight(sb.String(), "\n"), true
+}
@@ -0,0 +1,186 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+
+	"github.com/openai/openai-go"
+)
+
+func TestParseCommand(t *testing.T) {
+	tests := []struct {
+		text   string
+		want   domain.Command
+		wantOK bool
+	}{
+		{"/ai review", domain.Command{Name: domain.CommandReview}, true},
+		{"  /AI Explain  internal/api/server.go:42\nwhy is this here?", domain.Command{Name: domain.CommandExplain, Args: "internal/api/server.go:42"}, true},
+		{"/ai", domain.Command{Name: domain.CommandHelp}, true},
+		{"/ai frobnicate now", domain.Command{Name: "frobnicate", Args: "now"}, true},
+		{"please /ai review", domain.Command{}, false},
+		{"/aireview", domain.Command{}, false},
+		{"<!-- ai-review::reply-->\n/ai review", domain.Command{}, false},
+	}
+	for _, tt := range tests {
+		got, ok := domain.ParseCommand("/ai", tt.text)
+		if ok != tt.wantOK || got != tt.want {
+			t.Errorf("ParseCommand(%q) = %+v, %v; want %+v, %v", tt.text, got, ok, tt.want, tt.wantOK)
+		}
+	}
+}
+
+func TestParseLocation(t *testing.T) {
+	tests := []struct {
+		args string
+		file string
+		line int
+		ok   bool
+	}{
+		{"main.go:42", "main.go", 42, true},
+		{"`internal/a.go:7`", "internal/a.go", 7, true},
+		{"main.go", "", 0, false},
+		{"main.go:0", "", 0, false},
+		{":12", "", 0, false},
+	}
+	for _, tt := range tests {
+		file, line, ok := parseLocation(tt.args)
+		if file != tt.file || line != tt.line || ok != tt.ok {
+			t.Errorf("parseLocation(%q) = %q, %d, %v; want %q, %d, %v", tt.args, file, line, ok, tt.file, tt.line, tt.ok)
+		}
+	}
+}
+
+// commandCommenter serves a file and records posted comments
+type commandCommenter struct {
+	posted   []map[string]interface{}
+	comments string // get_pull_request_comments response
+}
+
+func (c *commandCommenter) CallTool(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+	switch toolName {
+	case config.ToolBitbucketGetFileContent:
+		var sb strings.Builder
+		for i := 1; i <= 50; i++ {
+			fmt.Fprintf(&sb, "line %d\n", i)
+		}
+		return sb.String(), nil
+	case config.ToolBitbucketAddComment:
+		c.posted = append(c.posted, args)
+		return nil, nil
+	case config.ToolBitbucketGetComments:
+		if c.comments != "" {
+			return c.comments, nil
+		}
+		return `{"values":[]}`, nil
+	}
+	return nil, nil
+}
+
+// recordingLLM answers text queries with a fixed reply and records the last input
+type recordingLLM struct {
+	reply string
+	input string
+}
+
+func (r *recordingLLM) Chat(ctx context.Context, params openai.ChatCompletionNewParams) (*openai.ChatCompletion, error) {
+	return nil, fmt.Errorf("unexpected chat request")
+}
+
+func (r *recordingLLM) SimpleTextQuery(ctx context.Context, systemPrompt, userInput string) (string, error) {
+	r.input = userInput
+	return r.reply, nil
+}
+
+func commandProcessor(commenter Commenter) *PRProcessor {
+	cfg := &config.Config{}
+	cfg.Commands = config.CommandConfig{Enabled: true, Prefix: "/ai", ExplainLines: 2}
+	return NewPRProcessor(cfg, &MockReviewer{}, commenter, nil)
+}
+
+func TestPRProcessor_HandleCommand_Explain(t *testing.T) {
+	commenter := &commandCommenter{}
+	p := commandProcessor(commenter)
+	model := &recordingLLM{reply: "It prints the line."}
+	p.SetCommandClient(model)
+	pr := &domain.PullRequest{ID: "7", ProjectKey: "PAY", RepoSlug: "api", LatestCommit: "abc"}
+
+	err := p.HandleCommand(context.Background(), pr, domain.Command{Name: domain.CommandExplain, Args: "main.go:10", CommentID: 99})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(model.input, ">   10  line 10") || strings.Contains(model.input, "line 13") || !strings.Contains(model.input, "line 8") {
+		t.Errorf("model input does not hold lines 8-12 with 10 marked:\n%s", model.input)
+	}
+	if len(commenter.posted) != 1 {
+		t.Fatalf("posted %d comments, want 1 reply", len(commenter.posted))
+	}
+	reply := commenter.posted[0]
+	if reply["parentId"] != int64(99) || !strings.Contains(reply["commentText"].(string), "It prints the line.") {
+		t.Errorf("reply = %v", reply)
+	}
+
+	// Out of range lines are answered without asking the model
+	commenter.posted = nil
+	model.input = ""
+	if err := p.HandleCommand(context.Background(), pr, domain.Command{Name: domain.CommandExplain, Args: "main.go:500"}); err != nil {
+		t.Fatal(err)
+	}
+	if model.input != "" || len(commenter.posted) != 1 || !strings.Contains(commenter.posted[0]["commentText"].(string), "has no line 500") {
+		t.Errorf("posted %v", commenter.posted)
+	}
+}
+
+func TestPRProcessor_HandleCommand_IgnorePausesReviews(t *testing.T) {
+	commenter := &commandCommenter{}
+	p := commandProcessor(commenter)
+	reviews := 0
+	p.reviewer = &MockReviewer{ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+		reviews++
+		return &domain.ReviewResult{Summary: "ok"}, nil
+	}}
+	pr := &domain.PullRequest{ID: "7", ProjectKey: "PAY", RepoSlug: "api", LatestCommit: "abc"}
+
+	if err := p.HandleCommand(context.Background(), pr, domain.Command{Name: domain.CommandIgnore, Author: "Alice"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(commenter.posted) != 1 {
+		t.Fatalf("posted %d comments, want the ignore note", len(commenter.posted))
+	}
+	note := commenter.posted[0]["commentText"].(string)
+	if !strings.Contains(note, p.markers().ignoreMarker()) || !strings.Contains(note, "Alice") {
+		t.Errorf("note = %q", note)
+	}
+
+	// The PR now carries the note: automatic reviews are skipped, requested ones run
+	commenter.comments = fmt.Sprintf(`{"values":[{"text":%q}]}`, note)
+	if err := p.ProcessPullRequest(context.Background(), &domain.PullRequest{ID: "7", ProjectKey: "PAY", RepoSlug: "api", LatestCommit: "def"}); err != nil {
+		t.Fatal(err)
+	}
+	if reviews != 0 {
+		t.Errorf("automatic review ran while paused")
+	}
+	if err := p.HandleCommand(context.Background(), &domain.PullRequest{ID: "7", ProjectKey: "PAY", RepoSlug: "api", LatestCommit: "def"}, domain.Command{Name: domain.CommandReview}); err != nil {
+		t.Fatal(err)
+	}
+	if reviews != 1 {
+		t.Errorf("reviews = %d, want the requested review", reviews)
+	}
+}
+
+func TestPRProcessor_HandleCommand_Unknown(t *testing.T) {
+	commenter := &commandCommenter{}
+	p := commandProcessor(commenter)
+	pr := &domain.PullRequest{ID: "7", ProjectKey: "PAY", RepoSlug: "api"}
+
+	if err := p.HandleCommand(context.Background(), pr, domain.Command{Name: "frobnicate"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(commenter.posted) != 1 || !strings.Contains(commenter.posted[0]["commentText"].(string), "/ai explain path/to/file.go:42") {
+		t.Errorf("posted %v, want the usage", commenter.posted)
+	}
+}
@@ -5,6 +5,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"text/template"
 
 	"pr-review-automation/internal/config"
 	"pr-review-automation/internal/domain"
@@ -14,11 +15,14 @@ import (
 type CommentMerger struct {
 	config   *config.CommentMergeConfig
 	prWebURL string
+	markers  markerSet
+	summary  *template.Template // Two-view summary template; nil renders the classic layout
+	minPost  string             // Lowest severity merged into file comments (review.min_post_severity); default: WARNING
 }
 
 // NewCommentMerger creates a new CommentMerger
 func NewCommentMerger(cfg *config.CommentMergeConfig, prWebURL string) *CommentMerger {
-	return &CommentMerger{config: cfg, prWebURL: prWebURL}
+	return &CommentMerger{config: cfg, prWebURL: prWebURL, markers: newMarkerSet(config.MarkerConfig{})}
 }
 
 // MergeResult contains merged comments ready for posting
@@ -91,7 +95,7 @@ func (m *CommentMerger) Merge(comments []domain.ReviewComment, commit string) *M
 			return cs[i].Line < cs[j].Line
 		})
 
-		marker := fmt.Sprintf("%s%s:%s:%s%s", config.MarkerAIReviewPrefix, config.MarkerTypeFile, file, commit, config.MarkerAIReviewSuffix)
+		marker := m.markers.fileMarker(file, commit)
 
 		res.FileComments = append(res.FileComments, MergedFileComment{
 			FilePath: file,
@@ -108,10 +112,15 @@ func (m *CommentMerger) Merge(comments []domain.ReviewComment, commit string) *M
 	return res
 }
 
-func (m *CommentMerger) isHighSeverity(severty string) bool {
-	// Construct a temporary comment to check severity
-	c := domain.ReviewComment{Severity: severty}
-	return c.IsHighSeverity()
+// isHighSeverity reports whether a finding is posted as a comment rather than folded into the
+// summary: CRITICAL and WARNING, or review.min_post_severity and above
+func (m *CommentMerger) isHighSeverity(severity string) bool {
+	if m.minPost == "" {
+		c := domain.ReviewComment{Severity: severity}
+		return c.IsHighSeverity()
+	}
+	rank := severityRank(severity)
+	return rank >= 0 && rank <= severityRank(m.minPost)
 }
 
 func (m *CommentMerger) getFileLink(filePath string) string {
@@ -153,8 +162,14 @@ func (m *CommentMerger) FormatFileComment(fc *MergedFileComment) string {
 
 	fileLink := m.getFileLink(fc.FilePath)
 	sb.WriteString(fmt.Sprintf("## %s %s Code Review\n\n", icon, fileLink))
-	sb.WriteString("| Line | Severity | Message |\n")
-	sb.WriteString("|------|----------|----------|\n")
+	categorized := hasCategories(fc.Comments)
+	if categorized {
+		sb.WriteString("| Line | Severity | Category | Message |\n")
+		sb.WriteString("|------|----------|----------|----------|\n")
+	} else {
+		sb.WriteString("| Line | Severity | Message |\n")
+		sb.WriteString("|------|----------|----------|\n")
+	}
 
 	for _, c := range fc.Comments {
 		sevBadge := c.Severity
@@ -168,7 +183,11 @@ func (m *CommentMerger) FormatFileComment(fc *MergedFileComment) string {
 		msg := strings.ReplaceAll(c.Comment, "|", "\\|")
 		msg = strings.ReplaceAll(msg, "\n", "<br>")
 
-		sb.WriteString(fmt.Sprintf("| %d | %s | %s |\n", int(c.Line), sevBadge, msg))
+		if categorized {
+			sb.WriteString(fmt.Sprintf("| %d | %s | %s | %s |\n", int(c.Line), sevBadge, c.Category, msg))
+		} else {
+			sb.WriteString(fmt.Sprintf("| %d | %s | %s |\n", int(c.Line), sevBadge, msg))
+		}
 	}
 
 	footer := "*This comment was automatically generated by AI Code Review*"
@@ -180,16 +199,26 @@ func (m *CommentMerger) FormatFileComment(fc *MergedFileComment) string {
 	return sb.String()
 }
 
-// FormatSummaryAddons generates Markdown table for INFO/NIT comments
+// FormatSummaryAddons generates Markdown table for the comments below the posting threshold
 func (m *CommentMerger) FormatSummaryAddons(comments []domain.ReviewComment) string {
 	if len(comments) == 0 {
 		return ""
 	}
 
 	var sb strings.Builder
-	sb.WriteString("\n### 📋 Suggestions (INFO/NIT)\n\n")
-	sb.WriteString("| File | Line | Suggestion |\n")
-	sb.WriteString("|------|------|------|\n")
+	below := "INFO/NIT"
+	if rank := severityRank(m.minPost); rank >= 0 && rank < len(config.FindingSeverities)-1 {
+		below = strings.Join(config.FindingSeverities[rank+1:], "/")
+	}
+	sb.WriteString("\n### 📋 Suggestions (" + below + ")\n\n")
+	categorized := hasCategories(comments)
+	if categorized {
+		sb.WriteString("| File | Line | Category | Suggestion |\n")
+		sb.WriteString("|------|------|------|------|\n")
+	} else {
+		sb.WriteString("| File | Line | Suggestion |\n")
+		sb.WriteString("|------|------|------|\n")
+	}
 
 	// Sort by file then line
 	sort.Slice(comments, func(i, j int) bool {
@@ -206,8 +235,22 @@ func (m *CommentMerger) FormatSummaryAddons(comments []domain.ReviewComment) str
 		fileLink := m.getFileLink(c.File)
 		lineLink := m.getLineLink(c.File, int(c.Line))
 
-		sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", fileLink, lineLink, msg))
+		if categorized {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s | %s |\n", fileLink, lineLink, c.Category, msg))
+		} else {
+			sb.WriteString(fmt.Sprintf("| %s | %s | %s |\n", fileLink, lineLink, msg))
+		}
 	}
 
 	return sb.String()
 }
+
+// hasCategories reports whether any comment has a category; tables show a Category column then
+func hasCategories(comments []domain.ReviewComment) bool {
+	for _, c := range comments {
+		if c.Category != "" {
+			return true
+		}
+	}
+	return false
+}
@@ -134,3 +134,63 @@ func TestCommentMerger_FormatWithLinks(t *testing.T) {
 		t.Errorf("summary missing expected line link.\nGot: %s\nExpected: %s", output, expectedLineLink)
 	}
 }
+
+func TestCommentMerger_FormatCategories(t *testing.T) {
+	merger := NewCommentMerger(&config.CommentMergeConfig{Enabled: true}, "")
+
+	fc := &MergedFileComment{
+		FilePath: "test.go",
+		Marker:   "<!-- marker -->",
+		Comments: []domain.ReviewComment{
+			{Line: 1, Severity: "WARNING", Category: domain.CommentCategoryPerformance, Comment: "Allocates per call"},
+			{Line: 9, Severity: "WARNING", Comment: "Unclear"},
+		},
+	}
+	output := merger.FormatFileComment(fc)
+	if !strings.Contains(output, "| Line | Severity | Category | Message |\n|------|----------|----------|----------|\n"+
+		"| 1 | ⚠️ WARNING | performance | Allocates per call |\n| 9 | ⚠️ WARNING |  | Unclear |\n") {
+		t.Errorf("file comment without category column:\n%s", output)
+	}
+
+	addons := merger.FormatSummaryAddons([]domain.ReviewComment{{File: "a.go", Line: 2, Category: domain.CommentCategoryStyle, Comment: "Rename"}})
+	if !strings.Contains(addons, "| File | Line | Category | Suggestion |") || !strings.Contains(addons, "| a.go | 2 | style | Rename |") {
+		t.Errorf("summary addons without category column:\n%s", addons)
+	}
+}
+
+func TestCommentMerger_MinPostSeverity(t *testing.T) {
+	merger := NewCommentMerger(&config.CommentMergeConfig{Enabled: true, HighSeverityMerge: "by_file", LowSeverityMerge: "to_summary"}, "")
+	merger.minPost = "critical"
+
+	result := merger.Merge([]domain.ReviewComment{
+		{File: "a.go", Line: 1, Severity: "CRITICAL", Comment: "Crit"},
+		{File: "a.go", Line: 2, Severity: "WARNING", Comment: "Warn"},
+		{File: "b.go", Line: 3, Severity: "INFO", Comment: "Info"},
+	}, "c1")
+	if len(result.FileComments) != 1 || len(result.FileComments[0].Comments) != 1 || result.FileComments[0].Comments[0].Comment != "Crit" {
+		t.Errorf("file comments = %+v, want only the CRITICAL finding", result.FileComments)
+	}
+	if len(result.SummaryAddons) != 2 {
+		t.Errorf("summary addons = %+v, want the WARNING and INFO findings", result.SummaryAddons)
+	}
+	if out := merger.FormatSummaryAddons(result.SummaryAddons); !strings.Contains(out, "### 📋 Suggestions (WARNING/INFO/NIT)") {
+		t.Errorf("summary addons header:\n%s", out)
+	}
+
+	// INFO findings become comments too; the summary keeps NITs
+	merger.minPost = "INFO"
+	result = merger.Merge([]domain.ReviewComment{{File: "b.go", Line: 3, Severity: "INFO"}, {File: "b.go", Line: 4, Severity: "NIT"}}, "c1")
+	if len(result.FileComments) != 1 || len(result.SummaryAddons) != 1 {
+		t.Errorf("file comments %d, summary addons %d; want 1 and 1", len(result.FileComments), len(result.SummaryAddons))
+	}
+}
+
+func TestAtOrAbove(t *testing.T) {
+	comments := []domain.ReviewComment{{Severity: "CRITICAL"}, {Severity: "warning"}, {Severity: "INFO"}, {Severity: "NIT"}}
+	if got := atOrAbove(comments, ""); len(got) != 4 {
+		t.Errorf("no threshold kept %d, want 4", len(got))
+	}
+	if got := atOrAbove(comments, "WARNING"); len(got) != 2 || got[1].Severity != "warning" {
+		t.Errorf("WARNING threshold kept %+v", got)
+	}
+}
@@ -0,0 +1,127 @@
+package processor
+
+import (
+	"context"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+
+	"github.com/tidwall/gjson"
+)
+
+// postedComment is a merged file comment or an inline comment the bot already posted on the pull request
+type postedComment struct {
+	id        int64
+	version   int64 // Bitbucket Server optimistic locking version
+	versioned bool  // Whether the code host reported a version
+	mType     string
+	path      string
+	line      int // 0 for file comments
+	commit    string
+	resolved  bool
+}
+
+// parsePostedComments returns the bot's file and inline comments in a get-comments response
+func (m markerSet) parsePostedComments(data []byte) []postedComment {
+	var posted []postedComment
+	gjson.GetBytes(data, "values").ForEach(func(_, v gjson.Result) bool {
+		if c := v.Get("comment"); c.IsObject() {
+			v = c
+		}
+		text := m.migrate(firstOf(v, "content.raw", "text").String())
+		start := strings.Index(text, m.prefix)
+		if start == -1 {
+			return true
+		}
+		pc, ok := m.parsePostedMarker(text[start:])
+		if !ok {
+			return true
+		}
+		version := v.Get("version")
+		pc.id = v.Get("id").Int()
+		pc.version, pc.versioned = version.Int(), version.Exists()
+		pc.resolved = v.Get("state").String() == "RESOLVED" || v.Get("resolution").IsObject()
+		posted = append(posted, pc)
+		return true
+	})
+	return posted
+}
+
+// parsePostedMarker reads a file marker or an inline marker ("path:line:commit") at the start of text
+func (m markerSet) parsePostedMarker(text string) (postedComment, bool) {
+	if mType, path, commit, found := m.parseMarker(text); found {
+		if mType != config.MarkerTypeFile {
+			return postedComment{}, false
+		}
+		return postedComment{mType: config.MarkerTypeFile, path: path, commit: commit}, true
+	}
+	end := strings.Index(text, m.suffix)
+	if end == -1 {
+		return postedComment{}, false
+	}
+	content := text[len(m.prefix):end]
+	rest, commit, ok := cutLast(content, ":")
+	if !ok {
+		return postedComment{}, false
+	}
+	path, lineStr, ok := cutLast(rest, ":")
+	if !ok || path == "" {
+		return postedComment{}, false
+	}
+	line, err := strconv.Atoi(lineStr)
+	if err != nil || line <= 0 {
+		return postedComment{}, false
+	}
+	return postedComment{path: path, line: line, commit: commit}, true
+}
+
+// cutLast slices s around the last instance of sep
+func cutLast(s, sep string) (before, after string, found bool) {
+	if i := strings.LastIndex(s, sep); i >= 0 {
+		return s[:i], s[i+len(sep):], true
+	}
+	return s, "", false
+}
+
+// postedFileComments returns the latest merged file comment of each file, keyed by path.
+// It returns nil when the comments cannot be fetched.
+func (p *PRProcessor) postedFileComments(ctx context.Context, pr *domain.PullRequest) map[string]postedComment {
+	data, err := fetchComments(ctx, p.commenter, pr)
+	if err != nil {
+		slog.Warn("fetch file comments failed", "error", err)
+		return nil
+	}
+	posted := make(map[string]postedComment)
+	for _, pc := range p.markers().parsePostedComments(data) {
+		if pc.mType != config.MarkerTypeFile {
+			continue
+		}
+		if prev, ok := posted[pc.path]; !ok || pc.id > prev.id {
+			posted[pc.path] = pc
+		}
+	}
+	return posted
+}
+
+// updateFileComment replaces the text of a posted file comment
+func (p *PRProcessor) updateFileComment(ctx context.Context, pr *domain.PullRequest, posted postedComment, text string) error {
+	return p.callCommentTool(ctx, pr, config.ToolBitbucketUpdateComment, posted, map[string]interface{}{"commentText": text})
+}
+
+// callCommentTool calls a tool acting on the posted comment, adding the PR identity, the comment id
+// and its version to args
+func (p *PRProcessor) callCommentTool(ctx context.Context, pr *domain.PullRequest, tool string, posted postedComment, args map[string]interface{}) error {
+	pullRequestId, _ := strconv.Atoi(pr.ID)
+	args["projectKey"] = pr.ProjectKey
+	args["repoSlug"] = pr.RepoSlug
+	args["pullRequestId"] = pullRequestId
+	args["commentId"] = posted.id
+	if posted.versioned {
+		args["version"] = posted.version
+	}
+	_, err := p.commenter.CallTool(ctx, config.MCPServerBitbucket, tool, args)
+	return err
+}
@@ -0,0 +1,56 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestPRProcessor_PostMergedComments_UpdateInPlace(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Pipeline.CommentMerge = config.CommentMergeConfig{Enabled: true, HighSeverityMerge: "by_file", LowSeverityMerge: "to_summary", UpdateInPlace: true}
+	markers := newMarkerSet(cfg.Pipeline.Markers)
+
+	// a.go was commented at an earlier commit, b.go at the current one; c.go is new
+	existing := fmt.Sprintf(`{"values":[
+		{"id":11,"version":0,"text":%q},
+		{"id":12,"version":3,"text":%q},
+		{"id":13,"version":0,"text":%q}
+	]}`, markers.fileMarker("a.go", "old")+"\n| 1 | WARNING | x |", markers.fileMarker("a.go", "older"), markers.fileMarker("b.go", "new"))
+
+	var added []string
+	var updates []map[string]interface{}
+	commenter := &MockCommenter{CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+		switch toolName {
+		case config.ToolBitbucketGetComments:
+			return existing, nil
+		case config.ToolBitbucketUpdateComment:
+			updates = append(updates, args)
+		case config.ToolBitbucketAddComment:
+			if path, ok := args["filePath"].(string); ok {
+				added = append(added, path)
+			}
+		}
+		return nil, nil
+	}}
+	p := NewPRProcessor(cfg, &MockReviewer{}, commenter, nil)
+	pr := &domain.PullRequest{ID: "7", ProjectKey: "PAY", RepoSlug: "api", LatestCommit: "new"}
+	review := &domain.ReviewResult{Comments: []domain.ReviewComment{
+		{File: "a.go", Line: 2, Comment: "still wrong", Severity: "WARNING"},
+		{File: "b.go", Line: 3, Comment: "posted", Severity: "WARNING"},
+		{File: "c.go", Line: 4, Comment: "new file", Severity: "WARNING"},
+	}}
+
+	if err := p.postMergedComments(context.Background(), pr, review, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+	if len(updates) != 1 || updates[0]["commentId"] != int64(12) || updates[0]["version"] != int64(3) {
+		t.Errorf("updates = %v, want the latest a.go comment at its version", updates)
+	}
+	if len(added) != 1 || added[0] != "c.go" {
+		t.Errorf("added file comments = %v, want c.go only", added)
+	}
+}
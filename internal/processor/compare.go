@@ -0,0 +1,256 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/storage"
+	"pr-review-automation/internal/validator"
+)
+
+// ComparisonSides are the suffixes of the review ids of the two sides of a comparison
+var ComparisonSides = [2]string{"a", "b"}
+
+// ComparisonReport puts the findings of two models on the same PR side by side
+type ComparisonReport struct {
+	ID          string              `json:"id"`
+	PullRequest *domain.PullRequest `json:"pullRequest"`
+	A           ComparisonSide      `json:"a"`
+	B           ComparisonSide      `json:"b"`
+	Rows        []ComparisonRow     `json:"rows"`    // One row per finding location, in file/line order
+	Both        int                 `json:"both"`    // Rows where both models report the same severity
+	Changed     int                 `json:"changed"` // Rows where both report a finding with different severity
+	OnlyA       int                 `json:"onlyA"`
+	OnlyB       int                 `json:"onlyB"`
+}
+
+// ComparisonSide summarizes the review of one model
+type ComparisonSide struct {
+	Model      string             `json:"model"`
+	ReviewID   string             `json:"reviewId"`
+	Status     string             `json:"status"`
+	Error      string             `json:"error,omitempty"`
+	Score      int                `json:"score"`
+	Findings   int                `json:"findings"`
+	DurationMs int64              `json:"durationMs"`
+	Usage      *domain.TokenUsage `json:"usage,omitempty"`
+	Summary    string             `json:"summary,omitempty"`
+}
+
+// ComparisonRow is one finding location with what each model reported there
+type ComparisonRow struct {
+	File string                `json:"file"`
+	Line int                   `json:"line"`
+	A    *domain.ReviewComment `json:"a,omitempty"`
+	B    *domain.ReviewComment `json:"b,omitempty"`
+}
+
+// ComparisonReviewID returns the id of the stored review of one side of comparison id
+func ComparisonReviewID(id, side string) string {
+	return id + "-" + side
+}
+
+// Compare reviews pr with both models as dry runs, stores both reviews under comparison id and
+// returns the side-by-side report. Both reviews see the same diff and PR comments; nothing is
+// posted.
+func (p *PRProcessor) Compare(ctx context.Context, id string, pr *domain.PullRequest, models [2]string) (*ComparisonReport, error) {
+	ctx = domain.WithProvider(ctx, pr.Provider)
+	ctx = domain.WithProject(ctx, pr.ProjectKey)
+	if pr.LatestCommit == "" {
+		p.resolvePullRequest(ctx, pr)
+	}
+	existing := p.fetchExistingAIComments(ctx, pr)
+	cache := p.newReviewContext(pr)
+	v := validator.NewCommentValidator(sharedDiff(ctx, cache))
+
+	var records [2]*storage.ReviewRecord
+	for i, model := range models {
+		records[i] = p.compareSide(ctx, ComparisonReviewID(id, ComparisonSides[i]), pr, model, existing, cache, v)
+		if p.storage != nil {
+			saveCtx, cancel := context.WithTimeout(context.Background(), p.cfg.Storage.Timeout)
+			if err := p.storage.SaveReview(saveCtx, records[i]); err != nil {
+				slog.Warn("save comparison review failed", "id", records[i].ID, "error", err)
+			}
+			cancel()
+		}
+	}
+	return NewComparisonReport(id, records[0], records[1]), nil
+}
+
+// compareSide runs the dry-run review of one model
+func (p *PRProcessor) compareSide(ctx context.Context, id string, pr *domain.PullRequest, model string, existing []domain.ReviewComment, cache *domain.ReviewContext, v *validator.CommentValidator) *storage.ReviewRecord {
+	start := time.Now()
+	side := *pr
+	side.Overrides = &domain.ReviewOverrides{Model: model, DryRun: true}
+
+	record := &storage.ReviewRecord{ID: id, PullRequest: &side, CreatedAt: start, Status: domain.ReviewStatusSuccess}
+	review, err := p.reviewer.ReviewPR(ctx, &domain.ReviewRequest{PR: &side, HistoricalComments: existing, Cache: cache})
+	record.DurationMs = time.Since(start).Milliseconds()
+	if err != nil {
+		slog.Warn("comparison review failed", "id", id, "model", model, "error", err)
+		record.Status = domain.ReviewStatusFailed
+		record.Result = &domain.ReviewResult{Model: model, Summary: err.Error()}
+		return record
+	}
+	review.RawComments = append([]domain.ReviewComment(nil), review.Comments...)
+	valid, _ := p.validateComments(review.Comments, v)
+	review.Comments = valid
+	if review.Model == "" {
+		review.Model = model
+	}
+	record.Result = review
+	record.Cost = p.reviewCost(pr, review)
+	return record
+}
+
+// NewComparisonReport builds the report of comparison id from the stored reviews of its sides
+func NewComparisonReport(id string, a, b *storage.ReviewRecord) *ComparisonReport {
+	report := &ComparisonReport{ID: id, PullRequest: a.PullRequest, A: comparisonSide(a), B: comparisonSide(b)}
+	if report.PullRequest != nil {
+		pr := *report.PullRequest
+		pr.Overrides = nil
+		report.PullRequest = &pr
+	}
+	report.Rows = pairFindings(reviewComments(a), reviewComments(b))
+	for _, r := range report.Rows {
+		switch {
+		case r.A == nil:
+			report.OnlyB++
+		case r.B == nil:
+			report.OnlyA++
+		case strings.EqualFold(r.A.Severity, r.B.Severity):
+			report.Both++
+		default:
+			report.Changed++
+		}
+	}
+	return report
+}
+
+func comparisonSide(rec *storage.ReviewRecord) ComparisonSide {
+	side := ComparisonSide{ReviewID: rec.ID, Status: rec.Status, DurationMs: rec.DurationMs}
+	if rec.PullRequest != nil && rec.PullRequest.Overrides != nil {
+		side.Model = rec.PullRequest.Overrides.Model
+	}
+	if r := rec.Result; r != nil {
+		if rec.Status != domain.ReviewStatusSuccess {
+			side.Error = r.Summary
+			return side
+		}
+		side.Score, side.Findings, side.Usage, side.Summary = r.Score, len(r.Comments), r.Usage, r.Summary
+	}
+	return side
+}
+
+func reviewComments(rec *storage.ReviewRecord) []domain.ReviewComment {
+	if rec.Status != domain.ReviewStatusSuccess || rec.Result == nil {
+		return nil
+	}
+	return rec.Result.Comments
+}
+
+// pairFindings matches the findings of both models by file and line, in order
+func pairFindings(a, b []domain.ReviewComment) []ComparisonRow {
+	key := func(c domain.ReviewComment) string {
+		return fmt.Sprintf(config.DedupeKeyFileLineFormat, c.File, int(c.Line))
+	}
+	pending := make(map[string][]int)
+	for i, c := range b {
+		pending[key(c)] = append(pending[key(c)], i)
+	}
+
+	var rows []ComparisonRow
+	paired := make([]bool, len(b))
+	for i := range a {
+		row := ComparisonRow{File: a[i].File, Line: int(a[i].Line), A: &a[i]}
+		if k := key(a[i]); len(pending[k]) > 0 {
+			j := pending[k][0]
+			pending[k] = pending[k][1:]
+			row.B = &b[j]
+			paired[j] = true
+		}
+		rows = append(rows, row)
+	}
+	for j := range b {
+		if !paired[j] {
+			rows = append(rows, ComparisonRow{File: b[j].File, Line: int(b[j].Line), B: &b[j]})
+		}
+	}
+	sort.SliceStable(rows, func(i, j int) bool {
+		if rows[i].File != rows[j].File {
+			return rows[i].File < rows[j].File
+		}
+		return rows[i].Line < rows[j].Line
+	})
+	return rows
+}
+
+// Markdown renders the report as a side-by-side table
+func (r *ComparisonReport) Markdown() string {
+	var sb strings.Builder
+	if pr := r.PullRequest; pr != nil {
+		fmt.Fprintf(&sb, "## Model comparison: %s/%s#%s\n\n", pr.ProjectKey, pr.RepoSlug, pr.ID)
+	} else {
+		fmt.Fprintf(&sb, "## Model comparison %s\n\n", r.ID)
+	}
+
+	sb.WriteString("| | " + markdownCell(r.A.Model) + " | " + markdownCell(r.B.Model) + " |\n|---|---|---|\n")
+	fmt.Fprintf(&sb, "| Status | %s | %s |\n", sideStatus(r.A), sideStatus(r.B))
+	fmt.Fprintf(&sb, "| Score | %d | %d |\n", r.A.Score, r.B.Score)
+	fmt.Fprintf(&sb, "| Findings | %d | %d |\n", r.A.Findings, r.B.Findings)
+	fmt.Fprintf(&sb, "| Duration | %s | %s |\n", time.Duration(r.A.DurationMs)*time.Millisecond, time.Duration(r.B.DurationMs)*time.Millisecond)
+	if r.A.Usage != nil || r.B.Usage != nil {
+		fmt.Fprintf(&sb, "| Tokens | %d | %d |\n", totalTokens(r.A.Usage), totalTokens(r.B.Usage))
+	}
+	fmt.Fprintf(&sb, "\nSame: %d · different severity: %d · only %s: %d · only %s: %d\n",
+		r.Both, r.Changed, markdownCell(r.A.Model), r.OnlyA, markdownCell(r.B.Model), r.OnlyB)
+
+	if len(r.Rows) == 0 {
+		return sb.String()
+	}
+	sb.WriteString("\n| Location | " + markdownCell(r.A.Model) + " | " + markdownCell(r.B.Model) + " |\n|---|---|---|\n")
+	for _, row := range r.Rows {
+		loc := row.File
+		if row.Line > 0 {
+			loc = fmt.Sprintf("%s:%d", row.File, row.Line)
+		}
+		if loc == "" {
+			loc = "(general)"
+		}
+		fmt.Fprintf(&sb, "| %s | %s | %s |\n", markdownCell(loc), findingCell(row.A), findingCell(row.B))
+	}
+	return sb.String()
+}
+
+func sideStatus(s ComparisonSide) string {
+	if s.Error != "" {
+		return s.Status + ": " + markdownCell(s.Error)
+	}
+	return s.Status
+}
+
+func totalTokens(u *domain.TokenUsage) int64 {
+	if u == nil {
+		return 0
+	}
+	return u.PromptTokens + u.CompletionTokens
+}
+
+func findingCell(c *domain.ReviewComment) string {
+	if c == nil {
+		return "—"
+	}
+	return "**" + c.Severity + "** " + markdownCell(c.Comment)
+}
+
+// markdownCell escapes text for a single table cell
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", "\\|")
+	return strings.ReplaceAll(s, "\n", "<br>")
+}
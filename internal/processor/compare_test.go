@@ -0,0 +1,121 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/storage"
+)
+
+func TestPRProcessor_Compare(t *testing.T) {
+	reviewer := &MockReviewer{
+		ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+			if req.PR.Overrides == nil || !req.PR.Overrides.DryRun {
+				t.Errorf("comparison review of %+v is not a dry run", req.PR)
+			}
+			switch req.PR.Overrides.Model {
+			case "model-a":
+				return &domain.ReviewResult{Score: 70, Comments: []domain.ReviewComment{
+					{File: "main.go", Line: 1, Severity: domain.CommentSeverityCritical, Comment: "nil deref"},
+					{File: "main.go", Line: 2, Severity: domain.CommentSeverityNit, Comment: "typo"},
+					{File: "gone.go", Line: 9, Severity: domain.CommentSeverityCritical, Comment: "not in diff"},
+				}}, nil
+			case "model-b":
+				return &domain.ReviewResult{Score: 80, Comments: []domain.ReviewComment{
+					{File: "main.go", Line: 1, Severity: domain.CommentSeverityCritical, Comment: "possible nil pointer"},
+					{File: "main.go", Line: 2, Severity: domain.CommentSeverityWarning, Comment: "misspelt name"},
+					{File: "main.go", Line: 3, Severity: domain.CommentSeverityWarning, Comment: "unchecked error"},
+				}}, nil
+			}
+			return nil, errors.New("model unavailable")
+		},
+	}
+	var posted int
+	commenter := &MockCommenter{
+		CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+			switch toolName {
+			case config.ToolBitbucketGetComments:
+				return `{"values": []}`, nil
+			case config.ToolBitbucketGetDiff:
+				return "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -0,0 +1,3 @@\n+a\n+b\n+c\n", nil
+			case config.ToolBitbucketAddComment:
+				posted++
+			}
+			return nil, nil
+		},
+	}
+	store, err := storage.NewSQLiteRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	cfg := &config.Config{}
+	cfg.Storage.Timeout = 5 * time.Second
+	p := NewPRProcessor(cfg, reviewer, commenter, store)
+
+	pr := &domain.PullRequest{ID: "3", ProjectKey: "PROJ", RepoSlug: "repo", LatestCommit: "abc"}
+	report, err := p.Compare(context.Background(), "cmp1", pr, [2]string{"model-a", "model-b"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if posted != 0 {
+		t.Errorf("comparison posted %d comments", posted)
+	}
+	if report.A.Model != "model-a" || report.B.Model != "model-b" || report.A.Findings != 2 || report.B.Findings != 3 {
+		t.Errorf("unexpected sides: %+v / %+v", report.A, report.B)
+	}
+	if report.Both != 1 || report.Changed != 1 || report.OnlyA != 0 || report.OnlyB != 1 || len(report.Rows) != 3 {
+		t.Errorf("unexpected counts: both %d changed %d onlyA %d onlyB %d rows %d", report.Both, report.Changed, report.OnlyA, report.OnlyB, len(report.Rows))
+	}
+
+	// Both sides are stored and rebuild the same report
+	a, err := store.GetReview(context.Background(), ComparisonReviewID("cmp1", "a"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	b, err := store.GetReview(context.Background(), ComparisonReviewID("cmp1", "b"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	stored := NewComparisonReport("cmp1", a, b)
+	if stored.Both != report.Both || stored.OnlyB != report.OnlyB || stored.A.Score != 70 || stored.B.Score != 80 {
+		t.Errorf("stored report differs: %+v", stored)
+	}
+
+	md := report.Markdown()
+	for _, want := range []string{"PROJ/repo#3", "| model-a | model-b |", "main.go:3 | — | **WARNING** unchecked error"} {
+		if !strings.Contains(md, want) {
+			t.Errorf("markdown missing %q:\n%s", want, md)
+		}
+	}
+}
+
+func TestPRProcessor_CompareFailedSide(t *testing.T) {
+	reviewer := &MockReviewer{
+		ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+			if req.PR.Overrides.Model == "broken" {
+				return nil, errors.New("model unavailable")
+			}
+			return &domain.ReviewResult{Comments: []domain.ReviewComment{{Severity: domain.CommentSeverityWarning, Comment: "general"}}}, nil
+		},
+	}
+	p := NewPRProcessor(&config.Config{}, reviewer, &MockCommenter{}, nil)
+
+	report, err := p.Compare(context.Background(), "cmp2", &domain.PullRequest{ID: "1", ProjectKey: "P", RepoSlug: "r", LatestCommit: "x"}, [2]string{"good", "broken"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if report.B.Status != domain.ReviewStatusFailed || report.B.Error != "model unavailable" {
+		t.Errorf("failed side not reported: %+v", report.B)
+	}
+	if report.OnlyA != 1 {
+		t.Errorf("expected the findings of the good model only, got %+v", report.Rows)
+	}
+}
@@ -0,0 +1,249 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/llm"
+	"pr-review-automation/internal/metrics"
+
+	"github.com/tidwall/gjson"
+)
+
+// conversationPrompt asks the model to answer a developer's reply to a review finding
+const conversationPrompt = `You are the code reviewer who wrote the finding below, answering the developer's reply in the comment thread.
+Answer the question or objection directly, using the diff hunk and the earlier discussion. If the developer is right,
+say so plainly and withdraw the finding; if not, explain why with reference to the code. Be concise: at most two short
+paragraphs, no headings, no greeting.`
+
+// ReplyHandler answers replies posted in pull request comment threads
+type ReplyHandler interface {
+	HandleReply(ctx context.Context, pr *domain.PullRequest, reply domain.CommentReply) error
+}
+
+// ConversationHandler answers developer replies to AI review comments with threaded answers
+type ConversationHandler struct {
+	cfg       *config.Config
+	commenter Commenter
+	llm       llm.Client
+}
+
+// NewConversationHandler creates a ConversationHandler answering with client
+func NewConversationHandler(cfg *config.Config, commenter Commenter, client llm.Client) *ConversationHandler {
+	return &ConversationHandler{cfg: cfg, commenter: commenter, llm: client}
+}
+
+// threadComment is one comment of a pull request comment thread
+type threadComment struct {
+	id      int64
+	text    string
+	author  string
+	path    string
+	line    int
+	parent  *threadComment
+	replies []*threadComment
+}
+
+// HandleReply answers reply when it is part of a thread started by an AI comment. The answer
+// is built from the original finding, its diff hunk, the earlier discussion and the reply.
+// Replies in other threads, and threads that reached conversation.max_turns, are left alone.
+func (h *ConversationHandler) HandleReply(ctx context.Context, pr *domain.PullRequest, reply domain.CommentReply) error {
+	ctx = domain.WithProvider(ctx, pr.Provider)
+	ctx = domain.WithProject(ctx, pr.ProjectKey)
+	markers := newMarkerSet(h.cfg.Pipeline.Markers)
+	if markers.contains(reply.Text) {
+		// The reviewer's own answers
+		return nil
+	}
+
+	data, err := fetchComments(ctx, h.commenter, pr)
+	if err != nil {
+		metrics.ConversationReplies.WithLabelValues("error").Inc()
+		return fmt.Errorf("fetch comments: %w", err)
+	}
+	parent, ok := parseThreads(data)[reply.ParentID]
+	if !ok {
+		slog.Debug("parent comment not found", "pr_id", pr.ID, "parent_id", reply.ParentID)
+		metrics.ConversationReplies.WithLabelValues("not_ai_thread").Inc()
+		return nil
+	}
+	root := parent
+	for root.parent != nil {
+		root = root.parent
+	}
+	if !markers.contains(root.text) {
+		metrics.ConversationReplies.WithLabelValues("not_ai_thread").Inc()
+		return nil
+	}
+
+	var history []*threadComment
+	answers := 0
+	var walk func(c *threadComment)
+	walk = func(c *threadComment) {
+		if c != root && c.id != reply.CommentID {
+			history = append(history, c)
+			if strings.Contains(c.text, markers.replyMarker()) {
+				answers++
+			}
+		}
+		for _, r := range c.replies {
+			walk(r)
+		}
+	}
+	walk(root)
+	if answers >= h.cfg.Conversation.MaxTurns {
+		slog.Info("conversation turn limit reached", "pr_id", pr.ID, "thread", root.id)
+		metrics.ConversationReplies.WithLabelValues("turn_limit").Inc()
+		return nil
+	}
+
+	var hunk string
+	if root.path != "" {
+		hunk = diffHunk(fetchPRDiff(ctx, h.commenter, pr), root.path, root.line)
+	}
+	answer, err := h.llm.SimpleTextQuery(ctx, conversationPrompt, conversationInput(pr, root, hunk, history, reply, markers))
+	if err != nil {
+		metrics.ConversationReplies.WithLabelValues("error").Inc()
+		return fmt.Errorf("answer reply %d: %w", reply.CommentID, err)
+	}
+	if err := postReply(ctx, h.commenter, pr, reply.CommentID, markers.replyMarker()+"\n"+strings.TrimSpace(answer)); err != nil {
+		metrics.CommentPostFailures.WithLabelValues("conversation").Inc()
+		metrics.ConversationReplies.WithLabelValues("error").Inc()
+		return fmt.Errorf("post answer: %w", err)
+	}
+	slog.Info("reply answered", "pr_id", pr.ID, "thread", root.id, "reply_id", reply.CommentID, "author", reply.Author)
+	metrics.ConversationReplies.WithLabelValues("answered").Inc()
+	return nil
+}
+
+// conversationInput is the user message of the answer: the finding, its hunk, the thread and the reply
+func conversationInput(pr *domain.PullRequest, root *threadComment, hunk string, history []*threadComment, reply domain.CommentReply, markers markerSet) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "Pull request: %s\n\n", pr.Title)
+	if root.path != "" {
+		fmt.Fprintf(&sb, "Finding on %s:%d:\n", root.path, root.line)
+	} else {
+		sb.WriteString("Finding:\n")
+	}
+	sb.WriteString(markers.strip(root.text) + "\n")
+	if hunk != "" {
+		fmt.Fprintf(&sb, "\nDiff hunk:\n```diff\n%s\n```\n", hunk)
+	}
+	if len(history) > 0 {
+		sb.WriteString("\nEarlier discussion:\n")
+		for _, c := range history {
+			who := c.author
+			if strings.Contains(c.text, markers.replyMarker()) {
+				who = "Reviewer (you)"
+			}
+			fmt.Fprintf(&sb, "- %s: %s\n", who, markers.strip(c.text))
+		}
+	}
+	fmt.Fprintf(&sb, "\nReply from %s:\n%s\n", reply.Author, reply.Text)
+	return sb.String()
+}
+
+// parseThreads indexes the comments of a get-comments response by id. Replies are nested under
+// "comments" (Bitbucket Server, also inside activities) or point to their "parent.id" (Bitbucket Cloud).
+func parseThreads(data []byte) map[int64]*threadComment {
+	comments := make(map[int64]*threadComment)
+	parentIDs := make(map[int64]int64)
+	var visit func(v gjson.Result, parent *threadComment)
+	visit = func(v gjson.Result, parent *threadComment) {
+		if c := v.Get("comment"); c.IsObject() {
+			v = c
+		}
+		id := v.Get("id").Int()
+		if id == 0 {
+			return
+		}
+		c := &threadComment{
+			id:     id,
+			text:   firstOf(v, "content.raw", "text").String(),
+			author: firstOf(v, "author.displayName", "author.display_name", "user.display_name", "author.name").String(),
+			path:   firstOf(v, "anchor.path", "inline.path").String(),
+			line:   int(firstOf(v, "anchor.line", "inline.to").Int()),
+			parent: parent,
+		}
+		if _, seen := comments[id]; seen {
+			return
+		}
+		comments[id] = c
+		if parent != nil {
+			parent.replies = append(parent.replies, c)
+		} else if p := v.Get("parent.id"); p.Exists() {
+			parentIDs[id] = p.Int()
+		}
+		v.Get("comments").ForEach(func(_, r gjson.Result) bool {
+			visit(r, c)
+			return true
+		})
+	}
+	gjson.GetBytes(data, "values").ForEach(func(_, v gjson.Result) bool {
+		visit(v, nil)
+		return true
+	})
+	for id, parentID := range parentIDs {
+		if parent, ok := comments[parentID]; ok {
+			comments[id].parent = parent
+			parent.replies = append(parent.replies, comments[id])
+		}
+	}
+	return comments
+}
+
+// diffHunk returns the hunk of file in diff whose new lines contain line, or "" if there is none
+func diffHunk(diff, file string, line int) string {
+	var hunk []string
+	current, inFile := "", false
+	newStart, newLen := 0, 0
+	flush := func() string {
+		if inFile && len(hunk) > 0 && line >= newStart && line < newStart+max(newLen, 1) {
+			return strings.Join(hunk, "\n")
+		}
+		return ""
+	}
+	for _, l := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(l, "diff --git "), strings.HasPrefix(l, "+++ "):
+			if h := flush(); h != "" {
+				return h
+			}
+			hunk = nil
+			if path, ok := strings.CutPrefix(l, "+++ "); ok {
+				path, _, _ = strings.Cut(path, "\t")
+				current = domain.NormalizePath(path)
+				inFile = current == domain.NormalizePath(file)
+			}
+		case strings.HasPrefix(l, "@@"):
+			if h := flush(); h != "" {
+				return h
+			}
+			hunk = []string{l}
+			newStart, newLen = parseHunkNewRange(l)
+		case len(hunk) > 0:
+			hunk = append(hunk, l)
+		}
+	}
+	return flush()
+}
+
+// parseHunkNewRange returns the new-file start and length of a hunk header "@@ -a,b +c,d @@"
+func parseHunkNewRange(header string) (start, length int) {
+	fields := strings.Fields(header)
+	if len(fields) < 3 || !strings.HasPrefix(fields[2], "+") {
+		return 0, 0
+	}
+	startStr, lenStr, hasLen := strings.Cut(fields[2][1:], ",")
+	start, _ = strconv.Atoi(startStr)
+	length = 1
+	if hasLen {
+		length, _ = strconv.Atoi(lenStr)
+	}
+	return start, length
+}
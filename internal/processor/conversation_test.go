@@ -0,0 +1,109 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestDiffHunk(t *testing.T) {
+	diff := `diff --git a/main.go b/main.go
+--- a/main.go
++++ b/main.go
+@@ -1,3 +1,4 @@
+ package main
++import "fmt"
+ func a() {}
+ func b() {}
+@@ -20,2 +21,3 @@ func c() {
+ 	x := 1
++	y := 2
+ }
+diff --git a/other.go b/other.go
+--- a/other.go
++++ b/other.go
+@@ -1 +1 @@
+-package old
++package other`
+
+	if got := diffHunk(diff, "main.go", 22); !strings.HasPrefix(got, "@@ -20,2 +21,3 @@") || !strings.Contains(got, "y := 2") || strings.Contains(got, "other") {
+		t.Errorf("hunk for main.go:22 = %q", got)
+	}
+	if got := diffHunk(diff, "other.go", 1); !strings.Contains(got, "+package other") {
+		t.Errorf("hunk for other.go:1 = %q", got)
+	}
+	if got := diffHunk(diff, "main.go", 10); got != "" {
+		t.Errorf("hunk for main.go:10 = %q, want none", got)
+	}
+}
+
+func TestParseThreads(t *testing.T) {
+	// Bitbucket Server nests replies; Bitbucket Cloud points to the parent
+	server := `{"values":[{"id":1,"text":"finding","anchor":{"path":"a.go","line":3},"comments":[{"id":2,"text":"why?","author":{"displayName":"Bob"}}]}]}`
+	threads := parseThreads([]byte(server))
+	if c := threads[2]; c == nil || c.parent != threads[1] || c.author != "Bob" || threads[1].path != "a.go" || threads[1].line != 3 {
+		t.Errorf("server threads = %+v", threads)
+	}
+
+	cloud := `{"values":[{"id":5,"content":{"raw":"why?"},"parent":{"id":4}},{"id":4,"content":{"raw":"finding"},"inline":{"path":"b.go","to":9}}]}`
+	threads = parseThreads([]byte(cloud))
+	if c := threads[5]; c == nil || c.parent != threads[4] || len(threads[4].replies) != 1 || threads[4].line != 9 {
+		t.Errorf("cloud threads = %+v", threads)
+	}
+}
+
+func TestConversationHandler_HandleReply(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Conversation = config.ConversationConfig{Enabled: true, MaxTurns: 2}
+	markers := newMarkerSet(cfg.Pipeline.Markers)
+	finding := markers.inlineMarker("a.go", 3, "abc") + "\nThis leaks the file handle."
+	pr := &domain.PullRequest{ID: "7", ProjectKey: "PAY", RepoSlug: "api", Title: "Add cache"}
+
+	thread := func(replies ...string) string {
+		return fmt.Sprintf(`{"values":[{"id":1,"text":%q,"anchor":{"path":"a.go","line":3},"comments":[%s]}, {"id":9,"text":"human thread"}]}`,
+			finding, strings.Join(replies, ","))
+	}
+	reply := domain.CommentReply{CommentID: 2, ParentID: 1, Text: "It is closed by the caller.", Author: "Bob"}
+
+	commenter := &commandCommenter{comments: thread(`{"id":2,"text":"It is closed by the caller.","author":{"displayName":"Bob"}}`)}
+	model := &recordingLLM{reply: "You are right, the caller closes it."}
+	h := NewConversationHandler(cfg, commenter, model)
+	if err := h.HandleReply(context.Background(), pr, reply); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(model.input, "This leaks the file handle.") || strings.Contains(model.input, markers.prefix) || !strings.Contains(model.input, "Reply from Bob") {
+		t.Errorf("model input = %q", model.input)
+	}
+	if len(commenter.posted) != 1 {
+		t.Fatalf("posted %d comments, want 1 answer", len(commenter.posted))
+	}
+	answer := commenter.posted[0]
+	if answer["parentId"] != int64(2) || !strings.Contains(answer["commentText"].(string), markers.replyMarker()) {
+		t.Errorf("answer = %v", answer)
+	}
+
+	// Replies in threads not started by the reviewer are left alone
+	commenter.posted = nil
+	if err := h.HandleReply(context.Background(), pr, domain.CommentReply{CommentID: 10, ParentID: 9, Text: "ok"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(commenter.posted) != 0 {
+		t.Errorf("answered a reply in a human thread")
+	}
+
+	// Threads stop at max_turns answers
+	answered := func(id int) string {
+		return fmt.Sprintf(`{"id":%d,"text":%q}`, id, markers.replyMarker()+"\nno")
+	}
+	commenter.comments = thread(`{"id":2,"text":"a"}`, answered(3), `{"id":4,"text":"b"}`, answered(5), `{"id":6,"text":"c"}`)
+	if err := h.HandleReply(context.Background(), pr, domain.CommentReply{CommentID: 6, ParentID: 1, Text: "c"}); err != nil {
+		t.Fatal(err)
+	}
+	if len(commenter.posted) != 0 {
+		t.Errorf("answered past the turn limit")
+	}
+}
@@ -0,0 +1,112 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/llm"
+	"pr-review-automation/internal/metrics"
+)
+
+// descriptionPrompt asks the model for a pull request description written from the diff
+const descriptionPrompt = `You write the description of a pull request from its diff, for the reviewers who read it first.
+Use exactly these Markdown sections:
+## Summary - two or three sentences on what changes and, as far as the diff shows, why
+## Changes - one bullet per notable change, naming the files or components
+## Testing - the tests added or changed, or "No tests changed"
+## Risks - behavior changes, migrations or configuration to watch, or "None identified"
+Describe only what the diff shows; do not invent motivation, tickets or results. No greeting, no text outside the sections.`
+
+// SetDescriptionClient sets the model writing descriptions of PRs opened without one
+func (p *PRProcessor) SetDescriptionClient(c llm.Client) {
+	p.describer = c
+}
+
+// describe generates a description for pr when it has none and sets it as the PR description or
+// proposes it in a comment (pipeline.description). A PR gets one proposal comment, not one per
+// push. Failures are logged only.
+func (p *PRProcessor) describe(ctx context.Context, pr *domain.PullRequest, diff string) {
+	cfg := p.cfg.Pipeline.Description
+	if !cfg.Enabled || p.describer == nil || diff == "" || strings.TrimSpace(pr.Description) != "" {
+		return
+	}
+	if len(cfg.Projects) > 0 && !slices.Contains(cfg.Projects, pr.ProjectKey) {
+		return
+	}
+	action := cfg.Action
+	if action == "" {
+		action = config.DescriptionActionComment
+	}
+	marker := p.markers().descriptionMarker()
+	if action == config.DescriptionActionComment {
+		proposed, err := p.hasComment(ctx, pr, marker)
+		if err != nil {
+			slog.Warn("fetch comments for description failed", "pr_id", pr.ID, "error", err)
+			return
+		}
+		if proposed {
+			return
+		}
+	}
+
+	text, err := p.generateDescription(ctx, pr, diff, cfg.MaxChars)
+	if err == nil {
+		if action == config.DescriptionActionUpdate {
+			err = p.updateDescription(ctx, pr, text)
+		} else {
+			err = postReply(ctx, p.commenter, pr, 0, fmt.Sprintf("%s\n📝 **Suggested description**: this pull request has no description. "+
+				"Copy this into it, and correct anything the diff does not tell.\n\n%s", marker, text))
+		}
+	}
+	if err != nil {
+		slog.Warn("generate description failed", "pr_id", pr.ID, "action", action, "error", err)
+		metrics.GeneratedDescriptions.WithLabelValues(action, "error").Inc()
+		return
+	}
+	slog.Info("description generated", "pr_id", pr.ID, "repo", pr.RepoSlug, "action", action)
+	metrics.GeneratedDescriptions.WithLabelValues(action, "success").Inc()
+}
+
+// generateDescription asks the model for a description of pr from at most maxChars of diff
+func (p *PRProcessor) generateDescription(ctx context.Context, pr *domain.PullRequest, diff string, maxChars int) (string, error) {
+	note := ""
+	if maxChars > 0 && len(diff) > maxChars {
+		diff = strings.ToValidUTF8(diff[:maxChars], "")
+		note = " (truncated)"
+	}
+	input := fmt.Sprintf("Pull request: %s\n\nDiff%s:\n```diff\n%s\n```", pr.Title, note, diff)
+	text, err := p.describer.SimpleTextQuery(ctx, descriptionPrompt, input)
+	if err != nil {
+		return "", err
+	}
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return "", fmt.Errorf("empty description")
+	}
+	return text, nil
+}
+
+// updateDescription sets the description of pr
+func (p *PRProcessor) updateDescription(ctx context.Context, pr *domain.PullRequest, text string) error {
+	prID, err := strconv.Atoi(pr.ID)
+	if err != nil {
+		return fmt.Errorf("invalid pull request id %q", pr.ID)
+	}
+	_, err = p.commenter.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketUpdatePR, map[string]interface{}{
+		"projectKey":    pr.ProjectKey,
+		"repoSlug":      pr.RepoSlug,
+		"pullRequestId": prID,
+		"description":   text,
+	})
+	if err != nil {
+		return err
+	}
+	pr.Description = text
+	return nil
+}
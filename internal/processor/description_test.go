@@ -0,0 +1,78 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+const describedDiff = "diff --git a/api.go b/api.go\n+++ b/api.go\n@@ -1,1 +1,2 @@\n+func Ping() {}\n"
+
+func descriptionProcessor(commenter Commenter, action string) (*PRProcessor, *recordingLLM) {
+	cfg := &config.Config{}
+	cfg.Pipeline.Description = config.DescriptionConfig{Enabled: true, Action: action, Projects: []string{"PAY"}, MaxChars: 1000}
+	p := NewPRProcessor(cfg, &MockReviewer{}, commenter, nil)
+	model := &recordingLLM{reply: "## Summary\nAdds a ping endpoint.\n"}
+	p.SetDescriptionClient(model)
+	return p, model
+}
+
+func TestPRProcessor_Describe_ProposesOnce(t *testing.T) {
+	commenter := &commandCommenter{}
+	p, model := descriptionProcessor(commenter, config.DescriptionActionComment)
+	pr := &domain.PullRequest{ID: "7", ProjectKey: "PAY", RepoSlug: "api", Title: "Add ping"}
+
+	p.describe(context.Background(), pr, describedDiff)
+	if !strings.Contains(model.input, "Pull request: Add ping") || !strings.Contains(model.input, "+func Ping() {}") {
+		t.Errorf("model input:\n%s", model.input)
+	}
+	if len(commenter.posted) != 1 {
+		t.Fatalf("posted %d comments, want 1 proposal", len(commenter.posted))
+	}
+	text := commenter.posted[0]["commentText"].(string)
+	if !strings.HasPrefix(text, p.markers().descriptionMarker()) || !strings.Contains(text, "Adds a ping endpoint.") {
+		t.Errorf("proposal = %q", text)
+	}
+
+	// The next push finds the proposal and posts nothing
+	commenter.comments = fmt.Sprintf(`{"values":[{"text":%q}]}`, text)
+	p.describe(context.Background(), pr, describedDiff)
+	if len(commenter.posted) != 1 {
+		t.Errorf("posted %d comments after the proposal, want 1", len(commenter.posted))
+	}
+
+	// PRs with a description and other projects are left alone
+	model.input = ""
+	p.describe(context.Background(), &domain.PullRequest{ID: "8", ProjectKey: "PAY", Description: "Fixes the login."}, describedDiff)
+	p.describe(context.Background(), &domain.PullRequest{ID: "9", ProjectKey: "OPS"}, describedDiff)
+	if model.input != "" {
+		t.Errorf("model asked for a PR that needs no description:\n%s", model.input)
+	}
+}
+
+func TestPRProcessor_Describe_Update(t *testing.T) {
+	var updated map[string]interface{}
+	commenter := &MockCommenter{CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+		switch toolName {
+		case config.ToolBitbucketUpdatePR:
+			updated = args
+		case config.ToolBitbucketGetComments, config.ToolBitbucketAddComment:
+			t.Errorf("unexpected %s call", toolName)
+		}
+		return nil, nil
+	}}
+	p, _ := descriptionProcessor(commenter, config.DescriptionActionUpdate)
+	pr := &domain.PullRequest{ID: "7", ProjectKey: "PAY", RepoSlug: "api"}
+
+	p.describe(context.Background(), pr, describedDiff)
+	if updated == nil || updated["pullRequestId"] != 7 || updated["description"] != "## Summary\nAdds a ping endpoint." {
+		t.Errorf("update args = %v", updated)
+	}
+	if pr.Description != "## Summary\nAdds a ping endpoint." {
+		t.Errorf("pr description = %q", pr.Description)
+	}
+}
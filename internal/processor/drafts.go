@@ -0,0 +1,20 @@
+package processor
+
+import (
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+// draftPolicy returns the review.drafts policy that applies to pr, or "" for PRs that are not
+// drafts. Requested reviews ignore the policy.
+func (p *PRProcessor) draftPolicy(pr *domain.PullRequest) string {
+	if !pr.Draft || pr.Overrides != nil {
+		return ""
+	}
+	return p.cfg.Review.Drafts
+}
+
+// dryRun reports whether the review of pr is stored but not posted
+func (p *PRProcessor) dryRun(pr *domain.PullRequest) bool {
+	return p.cfg.Review.DryRun || (pr.Overrides != nil && pr.Overrides.DryRun) || p.draftPolicy(pr) == config.DraftPolicyDryRun
+}
@@ -0,0 +1,62 @@
+package processor
+
+import (
+	"context"
+	"slices"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestPRProcessor_DraftPolicy(t *testing.T) {
+	cfg := &config.Config{}
+	var posted int
+	commenter := &MockCommenter{CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+		if slices.Contains(config.BitbucketWriteTools, toolName) {
+			posted++
+		}
+		return `{"values": []}`, nil
+	}}
+	var reviewed int
+	reviewer := &MockReviewer{ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+		reviewed++
+		return &domain.ReviewResult{Score: 90, Summary: "ok", Comments: []domain.ReviewComment{{Comment: "missing tests", Severity: "WARNING"}}}, nil
+	}}
+	p := NewPRProcessor(cfg, reviewer, commenter, nil)
+	draft := func() *domain.PullRequest {
+		return &domain.PullRequest{ID: "1", ProjectKey: "PAY", RepoSlug: "api", LatestCommit: "abc", Draft: true}
+	}
+
+	tests := []struct {
+		policy   string
+		reviewed int
+		posted   bool
+	}{
+		{config.DraftPolicyReview, 1, true},
+		{config.DraftPolicySkip, 0, false},
+		{config.DraftPolicyDryRun, 1, false},
+	}
+	for _, tt := range tests {
+		cfg.Review.Drafts = tt.policy
+		reviewed, posted = 0, 0
+		if err := p.ProcessPullRequest(context.Background(), draft()); err != nil {
+			t.Fatalf("%s: ProcessPullRequest: %v", tt.policy, err)
+		}
+		if reviewed != tt.reviewed || (posted > 0) != tt.posted {
+			t.Errorf("%s: reviewed %d, posted %d", tt.policy, reviewed, posted)
+		}
+	}
+
+	// Requested reviews ignore the policy
+	cfg.Review.Drafts = config.DraftPolicySkip
+	reviewed = 0
+	pr := draft()
+	pr.Overrides = &domain.ReviewOverrides{}
+	if err := p.ProcessPullRequest(context.Background(), pr); err != nil {
+		t.Fatalf("ProcessPullRequest: %v", err)
+	}
+	if reviewed != 1 {
+		t.Error("requested review of a draft was skipped")
+	}
+}
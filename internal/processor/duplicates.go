@@ -0,0 +1,176 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/storage"
+)
+
+// detectDuplicates compares the diff of pr with the stored fingerprints of recent PRs in the
+// same repository, then stores the fingerprint of pr. Storage failures are logged only.
+func (p *PRProcessor) detectDuplicates(ctx context.Context, pr *domain.PullRequest, diff string) []domain.DuplicateMatch {
+	cfg := p.cfg.Pipeline.DuplicateDetection
+	store, ok := p.storage.(storage.FingerprintRepository)
+	if !cfg.Enabled || !ok {
+		return nil
+	}
+
+	lines := diffFingerprint(diff, false)
+	if len(lines) == 0 || len(lines) < cfg.MinLines {
+		return nil
+	}
+	inverted := diffFingerprint(diff, true)
+
+	storeCtx, cancel := context.WithTimeout(context.Background(), p.cfg.Storage.Timeout)
+	defer cancel()
+
+	recent, err := store.ListFingerprints(storeCtx, pr.ProjectKey, pr.RepoSlug, time.Now().Add(-cfg.Window))
+	if err != nil {
+		slog.Warn("list fingerprints failed", "error", err)
+	}
+
+	var matches []domain.DuplicateMatch
+	for _, f := range recent {
+		if f.PRID == pr.ID || len(f.Lines) == 0 {
+			continue
+		}
+		kind, similarity := domain.DuplicateKindDuplicate, jaccard(lines, f.Lines)
+		if s := jaccard(inverted, f.Lines); s > similarity {
+			kind, similarity = domain.DuplicateKindRevert, s
+		}
+		if similarity < cfg.Similarity {
+			continue
+		}
+		metrics.DuplicatePRs.WithLabelValues(kind).Inc()
+		slog.Info("duplicate pr detected", "pr_id", pr.ID, "other_pr_id", f.PRID, "kind", kind, "similarity", similarity)
+		matches = append(matches, domain.DuplicateMatch{
+			PRID:       f.PRID,
+			Title:      f.Title,
+			WebURL:     f.WebURL,
+			Kind:       kind,
+			Similarity: similarity,
+		})
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Similarity > matches[j].Similarity })
+
+	err = store.SaveFingerprint(storeCtx, &storage.FingerprintRecord{
+		ProjectKey: pr.ProjectKey,
+		RepoSlug:   pr.RepoSlug,
+		PRID:       pr.ID,
+		Commit:     pr.LatestCommit,
+		Title:      pr.Title,
+		WebURL:     pr.WebURL,
+		Lines:      lines,
+	})
+	if err != nil {
+		slog.Warn("save fingerprint failed", "error", err)
+	}
+	return matches
+}
+
+// diffFingerprint returns the sorted, distinct hashes of the changed lines of a unified diff.
+// Lines are keyed by file and direction and compared without surrounding whitespace, so
+// rebases and re-indentation do not hide a duplicate. invert swaps additions and removals:
+// the inverted fingerprint of a revert equals the fingerprint of the reverted PR.
+// "---" and "+++" lines name the file only in a file header, before its first hunk; inside a
+// hunk they are a removed "-- " or added "++ " line.
+func diffFingerprint(diff string, invert bool) []string {
+	seen := make(map[string]bool)
+	file := ""
+	inHunk := false
+	for _, line := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(line, "diff --git "):
+			inHunk = false
+			continue
+		case strings.HasPrefix(line, "@@"):
+			inHunk = true
+			continue
+		case !inHunk && strings.HasPrefix(line, "+++ "):
+			if path := strings.TrimPrefix(line, "+++ "); path != "/dev/null" {
+				file = strings.TrimPrefix(path, "b/")
+			}
+			continue
+		case !inHunk && strings.HasPrefix(line, "--- "):
+			// Deleted files only name the old path
+			file = strings.TrimPrefix(strings.TrimPrefix(line, "--- "), "a/")
+			continue
+		}
+
+		var sign byte
+		switch {
+		case strings.HasPrefix(line, "+"):
+			sign = '+'
+		case strings.HasPrefix(line, "-"):
+			sign = '-'
+		default:
+			continue
+		}
+		content := strings.TrimSpace(line[1:])
+		if content == "" {
+			continue
+		}
+		if invert {
+			sign = '+' + '-' - sign
+		}
+
+		h := fnv.New64a()
+		fmt.Fprintf(h, "%s\x00%c\x00%s", file, sign, content)
+		seen[strconv.FormatUint(h.Sum64(), 16)] = true
+	}
+
+	hashes := make([]string, 0, len(seen))
+	for hash := range seen {
+		hashes = append(hashes, hash)
+	}
+	sort.Strings(hashes)
+	return hashes
+}
+
+// jaccard returns the share of hashes the two fingerprints have in common
+func jaccard(a, b []string) float64 {
+	if len(a) == 0 || len(b) == 0 {
+		return 0
+	}
+	set := make(map[string]bool, len(a))
+	for _, h := range a {
+		set[h] = true
+	}
+	shared := 0
+	for _, h := range b {
+		if set[h] {
+			shared++
+		}
+	}
+	return float64(shared) / float64(len(a)+len(b)-shared)
+}
+
+// duplicateNote returns the summary lines pointing at duplicate and reverted PRs
+func duplicateNote(matches []domain.DuplicateMatch) string {
+	lines := make([]string, 0, len(matches))
+	for _, m := range matches {
+		ref := "PR #" + m.PRID
+		if m.WebURL != "" {
+			ref = fmt.Sprintf("[%s](%s)", ref, m.WebURL)
+		}
+		if m.Title != "" {
+			ref += fmt.Sprintf(" (%s)", m.Title)
+		}
+		percent := int(m.Similarity * 100)
+		if m.Kind == domain.DuplicateKindRevert {
+			lines = append(lines, fmt.Sprintf("**Possible revert** of %s: undoes %d%% of its changes.", ref, percent))
+		} else {
+			lines = append(lines, fmt.Sprintf("**Possible duplicate** of %s: %d%% identical changes.", ref, percent))
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,139 @@
+package processor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/storage"
+)
+
+// fingerprintStore keeps the latest fingerprint per PR in memory
+type fingerprintStore struct {
+	storage.Repository
+	saved []*storage.FingerprintRecord
+}
+
+func (s *fingerprintStore) SaveFingerprint(ctx context.Context, f *storage.FingerprintRecord) error {
+	for i, old := range s.saved {
+		if old.PRID == f.PRID {
+			s.saved[i] = f
+			return nil
+		}
+	}
+	s.saved = append(s.saved, f)
+	return nil
+}
+
+func (s *fingerprintStore) ListFingerprints(ctx context.Context, projectKey, repoSlug string, since time.Time) ([]*storage.FingerprintRecord, error) {
+	return s.saved, nil
+}
+
+const duplicateDiff = `diff --git a/app.go b/app.go
+--- a/app.go
++++ b/app.go
+@@ -1,4 +1,5 @@
+-func old() {}
++func handler() error {
++	if err := run(); err != nil {
++		return err
++	}
++	return nil
++}
+`
+
+// revertDiff undoes duplicateDiff
+const revertDiff = `diff --git a/app.go b/app.go
+--- a/app.go
++++ b/app.go
+@@ -1,5 +1,4 @@
++func old() {}
+-func handler() error {
+-	if err := run(); err != nil {
+-		return err
+-	}
+-	return nil
+-}
+`
+
+func TestPRProcessor_DetectDuplicates(t *testing.T) {
+	tests := []struct {
+		name     string
+		diff     string
+		minLines int
+		wantKind string // Empty: no match
+	}{
+		{name: "same changes", diff: duplicateDiff, minLines: 5, wantKind: domain.DuplicateKindDuplicate},
+		{name: "re-indented changes", diff: strings.ReplaceAll(duplicateDiff, "\t", "    "), minLines: 5, wantKind: domain.DuplicateKindDuplicate},
+		{name: "revert", diff: revertDiff, minLines: 5, wantKind: domain.DuplicateKindRevert},
+		{name: "unrelated changes", diff: "+++ b/other.go\n+a := 1\n+b := 2\n+c := 3\n+d := 4\n+e := 5\n", minLines: 5},
+		{name: "below min lines", diff: duplicateDiff, minLines: 50},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			store := &fingerprintStore{}
+			cfg := &config.Config{}
+			cfg.Storage.Timeout = time.Second
+			cfg.Pipeline.DuplicateDetection = config.DuplicateDetectionConfig{Enabled: true, Window: time.Hour, Similarity: 0.8, MinLines: tt.minLines}
+			p := NewPRProcessor(cfg, &MockReviewer{}, &MockCommenter{}, store)
+
+			first := &domain.PullRequest{ID: "12", ProjectKey: "P", RepoSlug: "r", Title: "Add handler", WebURL: "https://scm/pr/12"}
+			if got := p.detectDuplicates(context.Background(), first, duplicateDiff); len(got) != 0 {
+				t.Fatalf("first PR must not match: %+v", got)
+			}
+
+			// A later review of the same PR is not its own duplicate
+			if got := p.detectDuplicates(context.Background(), first, duplicateDiff); len(got) != 0 {
+				t.Fatalf("PR must not match itself: %+v", got)
+			}
+
+			second := &domain.PullRequest{ID: "13", ProjectKey: "P", RepoSlug: "r"}
+			got := p.detectDuplicates(context.Background(), second, tt.diff)
+			if tt.wantKind == "" {
+				if len(got) != 0 {
+					t.Fatalf("unexpected matches: %+v", got)
+				}
+				return
+			}
+			if len(got) != 1 || got[0].PRID != "12" || got[0].Kind != tt.wantKind || got[0].Similarity < 0.8 {
+				t.Fatalf("unexpected matches: %+v", got)
+			}
+		})
+	}
+}
+
+func TestDuplicateNote(t *testing.T) {
+	note := duplicateNote([]domain.DuplicateMatch{
+		{PRID: "12", Title: "Add handler", WebURL: "https://scm/pr/12", Kind: domain.DuplicateKindDuplicate, Similarity: 0.93},
+		{PRID: "9", Kind: domain.DuplicateKindRevert, Similarity: 1},
+	})
+	want := "**Possible duplicate** of [PR #12](https://scm/pr/12) (Add handler): 93% identical changes.\n" +
+		"**Possible revert** of PR #9: undoes 100% of its changes."
+	if note != want {
+		t.Errorf("note = %q, want %q", note, want)
+	}
+	if duplicateNote(nil) != "" {
+		t.Error("no matches must produce no note")
+	}
+}
+
+func TestDiffFingerprint_HunkLinesLikeHeaders(t *testing.T) {
+	// Removing "-- banner --" and adding "++ banner ++" in SQL gives hunk lines that start
+	// like file headers
+	diff := "diff --git a/schema.sql b/schema.sql\n--- a/schema.sql\n+++ b/schema.sql\n@@ -1,2 +1,3 @@\n" +
+		"--- banner --\n+++ banner ++\n+SELECT 1;\n SELECT 2;\n"
+	revert := "diff --git a/schema.sql b/schema.sql\n--- a/schema.sql\n+++ b/schema.sql\n@@ -1,3 +1,2 @@\n" +
+		"+-- banner --\n-++ banner ++\n-SELECT 1;\n SELECT 2;\n"
+
+	got := diffFingerprint(diff, false)
+	if len(got) != 3 {
+		t.Fatalf("fingerprint has %d lines, want 3: %v", len(got), got)
+	}
+	if inverted := diffFingerprint(revert, true); strings.Join(inverted, ",") != strings.Join(got, ",") {
+		t.Errorf("inverted revert = %v, want %v", inverted, got)
+	}
+}
@@ -0,0 +1,97 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"pr-review-automation/internal/domain"
+)
+
+// ErrSkip can be returned by a hook to stop processing a PR without reporting a failure
+var ErrSkip = errors.New("processing skipped by hook")
+
+// SkipError stops processing like ErrSkip and records why in the skip ledger
+type SkipError struct {
+	Reason string // domain.SkipReason*
+	Detail string
+}
+
+func (e *SkipError) Error() string {
+	if e.Detail == "" {
+		return "review skipped: " + e.Reason
+	}
+	return fmt.Sprintf("review skipped: %s (%s)", e.Reason, e.Detail)
+}
+
+// Is makes errors.Is(err, ErrSkip) true for skip errors
+func (e *SkipError) Is(target error) bool {
+	return target == ErrSkip
+}
+
+// Skip returns an error for hooks that stops processing with a recorded reason
+func Skip(reason, detail string) error {
+	return &SkipError{Reason: reason, Detail: detail}
+}
+
+// BeforeReviewHook runs before the reviewer is invoked. It may modify the request.
+type BeforeReviewHook func(ctx context.Context, req *domain.ReviewRequest) error
+
+// AfterReviewHook runs after the reviewer returns, before validation and deduplication.
+type AfterReviewHook func(ctx context.Context, pr *domain.PullRequest, result *domain.ReviewResult) error
+
+// BeforePostHook runs right before comments are posted. Result.Comments holds the final set.
+type BeforePostHook func(ctx context.Context, pr *domain.PullRequest, result *domain.ReviewResult) error
+
+// hooks holds registered extension points, executed in registration order
+type hooks struct {
+	beforeReview []BeforeReviewHook
+	afterReview  []AfterReviewHook
+	beforePost   []BeforePostHook
+}
+
+// OnBeforeReview registers a hook executed before the review starts
+func (p *PRProcessor) OnBeforeReview(h BeforeReviewHook) {
+	p.hooks.beforeReview = append(p.hooks.beforeReview, h)
+}
+
+// OnAfterReview registers a hook executed after the review completes
+func (p *PRProcessor) OnAfterReview(h AfterReviewHook) {
+	p.hooks.afterReview = append(p.hooks.afterReview, h)
+}
+
+// OnBeforePost registers a hook executed before comments are posted
+func (p *PRProcessor) OnBeforePost(h BeforePostHook) {
+	p.hooks.beforePost = append(p.hooks.beforePost, h)
+}
+
+func (h *hooks) runBeforeReview(ctx context.Context, req *domain.ReviewRequest) error {
+	for i, fn := range h.beforeReview {
+		if err := fn(ctx, req); err != nil {
+			return wrapHookError("before_review", i, err)
+		}
+	}
+	return nil
+}
+
+func (h *hooks) runAfterReview(ctx context.Context, pr *domain.PullRequest, result *domain.ReviewResult) error {
+	for i, fn := range h.afterReview {
+		if err := fn(ctx, pr, result); err != nil {
+			return wrapHookError("after_review", i, err)
+		}
+	}
+	return nil
+}
+
+func (h *hooks) runBeforePost(ctx context.Context, pr *domain.PullRequest, result *domain.ReviewResult) error {
+	for i, fn := range h.beforePost {
+		if err := fn(ctx, pr, result); err != nil {
+			return wrapHookError("before_post", i, err)
+		}
+	}
+	return nil
+}
+
+func wrapHookError(stage string, index int, err error) error {
+	return fmt.Errorf("hook %s[%d]: %w", stage, index, err)
+}
@@ -0,0 +1,98 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestPRProcessor_Hooks(t *testing.T) {
+	newProcessor := func(posted *int) *PRProcessor {
+		reviewer := &MockReviewer{
+			ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+				return &domain.ReviewResult{
+					Comments: []domain.ReviewComment{{Comment: "general"}},
+				}, nil
+			},
+		}
+		commenter := &MockCommenter{
+			CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+				if toolName == config.ToolBitbucketAddComment {
+					*posted++
+				}
+				return nil, nil
+			},
+		}
+		return NewPRProcessor(&config.Config{}, reviewer, commenter, nil)
+	}
+	pr := &domain.PullRequest{ID: "1", ProjectKey: "P", RepoSlug: "r"}
+
+	t.Run("hooks run in order", func(t *testing.T) {
+		var posted int
+		var calls []string
+		p := newProcessor(&posted)
+		p.OnBeforeReview(func(ctx context.Context, req *domain.ReviewRequest) error {
+			calls = append(calls, "before_review")
+			return nil
+		})
+		p.OnAfterReview(func(ctx context.Context, pr *domain.PullRequest, result *domain.ReviewResult) error {
+			calls = append(calls, "after_review")
+			return nil
+		})
+		p.OnBeforePost(func(ctx context.Context, pr *domain.PullRequest, result *domain.ReviewResult) error {
+			calls = append(calls, "before_post")
+			return nil
+		})
+
+		if err := p.ProcessPullRequest(context.Background(), pr); err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		if len(calls) != 3 || calls[0] != "before_review" || calls[1] != "after_review" || calls[2] != "before_post" {
+			t.Errorf("unexpected hook order: %v", calls)
+		}
+		if posted != 1 {
+			t.Errorf("expected 1 posted comment, got %d", posted)
+		}
+	})
+
+	t.Run("skip stops quietly", func(t *testing.T) {
+		var posted int
+		p := newProcessor(&posted)
+		p.OnBeforePost(func(ctx context.Context, pr *domain.PullRequest, result *domain.ReviewResult) error {
+			return ErrSkip
+		})
+
+		if err := p.ProcessPullRequest(context.Background(), pr); err != nil {
+			t.Fatalf("expected nil error on skip, got %v", err)
+		}
+		if posted != 0 {
+			t.Errorf("expected no posted comments, got %d", posted)
+		}
+	})
+
+	t.Run("error aborts", func(t *testing.T) {
+		var posted int
+		p := newProcessor(&posted)
+		rejected := errors.New("compliance rejected")
+		p.OnAfterReview(func(ctx context.Context, pr *domain.PullRequest, result *domain.ReviewResult) error {
+			return rejected
+		})
+
+		events := &recordedEvents{}
+		p.SetEventPublisher(events)
+
+		err := p.ProcessPullRequest(context.Background(), pr)
+		if !errors.Is(err, rejected) {
+			t.Fatalf("expected compliance error, got %v", err)
+		}
+		if posted != 0 {
+			t.Errorf("expected no posted comments, got %d", posted)
+		}
+		if len(events.got) != 1 || events.got[0].Status != domain.ReviewStatusFailed || events.got[0].Result == nil || events.got[0].Error == "" {
+			t.Errorf("expected one failed review event with the review result, got %+v", events.got)
+		}
+	})
+}
@@ -0,0 +1,152 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+	"sync"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/rules"
+	"pr-review-automation/internal/storage"
+)
+
+// MergeHandler runs follow-up actions when a pull request is merged
+type MergeHandler interface {
+	HandleMerged(ctx context.Context, pr *domain.PullRequest) error
+}
+
+// JiraIssueFiler files Jira issues for CRITICAL findings that are still present when a PR is merged.
+// A finding persists when the latest successful review covered the merged head commit.
+type JiraIssueFiler struct {
+	cfg       config.JiraIssueConfig
+	commenter Commenter
+	storage   storage.Repository
+	filed     sync.Map // "PROJECT/repo/id" -> struct{}: guards against webhook redelivery
+}
+
+// NewJiraIssueFiler creates a filer that uses the jira MCP connection of commenter
+func NewJiraIssueFiler(cfg config.JiraIssueConfig, commenter Commenter, store storage.Repository) *JiraIssueFiler {
+	return &JiraIssueFiler{cfg: cfg, commenter: commenter, storage: store}
+}
+
+// HandleMerged files one issue per persisting CRITICAL finding of the merged PR
+func (f *JiraIssueFiler) HandleMerged(ctx context.Context, pr *domain.PullRequest) error {
+	target, ok := f.target(pr)
+	if !ok {
+		slog.Debug("no jira project configured for repository", "project", pr.ProjectKey, "repo", pr.RepoSlug)
+		return nil
+	}
+
+	key := fmt.Sprintf("%s/%s/%s", pr.ProjectKey, pr.RepoSlug, pr.ID)
+	if _, loaded := f.filed.LoadOrStore(key, struct{}{}); loaded {
+		slog.Debug("jira issues already filed for merged pr", "pr", key)
+		return nil
+	}
+
+	findings, err := f.persistingFindings(ctx, pr)
+	if err != nil {
+		f.filed.Delete(key)
+		return err
+	}
+	if len(findings) == 0 {
+		return nil
+	}
+
+	var failed int
+	for _, c := range findings {
+		args := map[string]interface{}{
+			"projectKey":  target.JiraProject,
+			"issueType":   target.IssueType,
+			"summary":     issueSummary(pr, c),
+			"description": issueDescription(pr, c),
+		}
+		if len(target.Labels) > 0 {
+			args["labels"] = target.Labels
+		}
+		if _, err := f.commenter.CallTool(ctx, config.MCPServerJira, config.ToolJiraCreateIssue, args); err != nil {
+			slog.Error("create jira issue failed", "pr", key, "file", c.File, "error", err)
+			metrics.JiraIssuesCreated.WithLabelValues("error").Inc()
+			failed++
+			continue
+		}
+		metrics.JiraIssuesCreated.WithLabelValues("success").Inc()
+	}
+
+	slog.Info("jira issues filed for merged pr", "pr", key, "jira_project", target.JiraProject, "issues", len(findings)-failed, "failed", failed)
+	if failed > 0 {
+		return fmt.Errorf("create jira issues: %d of %d failed", failed, len(findings))
+	}
+	return nil
+}
+
+// target returns the first project mapping matching the PR's repository
+func (f *JiraIssueFiler) target(pr *domain.PullRequest) (config.JiraProjectMapping, bool) {
+	repo := pr.ProjectKey + "/" + pr.RepoSlug
+	for _, m := range f.cfg.Projects {
+		if rules.MatchAny(m.Repos, repo) {
+			if m.IssueType == "" {
+				m.IssueType = config.DefaultJiraIssueType
+			}
+			return m, true
+		}
+	}
+	return config.JiraProjectMapping{}, false
+}
+
+// persistingFindings returns the CRITICAL findings of the latest successful review of the merged head
+func (f *JiraIssueFiler) persistingFindings(ctx context.Context, pr *domain.PullRequest) ([]domain.ReviewComment, error) {
+	if f.storage == nil {
+		return nil, nil
+	}
+	records, err := f.storage.ListReviewsByPR(ctx, pr.ProjectKey, pr.RepoSlug, pr.ID)
+	if err != nil {
+		return nil, fmt.Errorf("list reviews: %w", err)
+	}
+
+	// Records are ordered newest first
+	for _, r := range records {
+		if r.Status != domain.ReviewStatusSuccess || r.Result == nil {
+			continue
+		}
+		if r.PullRequest != nil && pr.LatestCommit != "" && r.PullRequest.LatestCommit != pr.LatestCommit {
+			slog.Info("latest review does not cover merged commit, skipping jira issues",
+				"pr_id", pr.ID, "reviewed", r.PullRequest.LatestCommit, "merged", pr.LatestCommit)
+			return nil, nil
+		}
+		var critical []domain.ReviewComment
+		for _, c := range r.Result.Comments {
+			if c.IsCritical() {
+				critical = append(critical, c)
+			}
+		}
+		return critical, nil
+	}
+	return nil, nil
+}
+
+func issueSummary(pr *domain.PullRequest, c domain.ReviewComment) string {
+	return fmt.Sprintf("[%s/%s] %s", pr.ProjectKey, pr.RepoSlug, taskText(c))
+}
+
+func issueDescription(pr *domain.PullRequest, c domain.ReviewComment) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "A CRITICAL review finding was still present when pull request #%s (%s) was merged.\n\n", pr.ID, pr.Title)
+	fmt.Fprintf(&sb, "*Location:* %s:%d\n", c.File, int(c.Line))
+	if pr.WebURL != "" {
+		// Same diff anchor format as the comment merger links
+		fmt.Fprintf(&sb, "*Code:* %s/diff#%s?t=%d\n", pr.WebURL, c.File, int(c.Line))
+	}
+	if pr.Author != "" {
+		fmt.Fprintf(&sb, "*Author:* %s\n", pr.Author)
+	}
+	if pr.LatestCommit != "" {
+		fmt.Fprintf(&sb, "*Commit:* %s\n", pr.LatestCommit)
+	}
+	sb.WriteString("\n")
+	sb.WriteString(c.Comment)
+	return sb.String()
+}
@@ -0,0 +1,119 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/storage"
+)
+
+// reviewStore serves stored reviews for a single PR
+type reviewStore struct {
+	storage.Repository
+	records []*storage.ReviewRecord
+}
+
+func (s *reviewStore) ListReviewsByPR(ctx context.Context, projectKey, repoSlug, prID string) ([]*storage.ReviewRecord, error) {
+	return s.records, nil
+}
+
+func TestJiraIssueFiler_HandleMerged(t *testing.T) {
+	critical := domain.ReviewComment{File: "db.go", Line: 12, Severity: "CRITICAL", Comment: "SQL injection via string concat"}
+	warning := domain.ReviewComment{File: "db.go", Line: 20, Severity: "WARNING", Comment: "Unchecked error"}
+
+	review := func(commit, status string, comments ...domain.ReviewComment) *storage.ReviewRecord {
+		return &storage.ReviewRecord{
+			PullRequest: &domain.PullRequest{LatestCommit: commit},
+			Result:      &domain.ReviewResult{Comments: comments},
+			Status:      status,
+		}
+	}
+
+	cfg := config.JiraIssueConfig{
+		Enabled: true,
+		Projects: []config.JiraProjectMapping{
+			{Repos: []string{"PAY/*"}, JiraProject: "PAYSEC", Labels: []string{"ai-review"}},
+		},
+	}
+
+	tests := []struct {
+		name       string
+		repo       string
+		records    []*storage.ReviewRecord
+		wantIssues int
+	}{
+		{name: "critical on merged commit", repo: "api", records: []*storage.ReviewRecord{review("abc", "success", critical, warning)}, wantIssues: 1},
+		{name: "latest failed review skipped", repo: "api", records: []*storage.ReviewRecord{review("abc", "error"), review("abc", "success", critical)}, wantIssues: 1},
+		{name: "review of older commit", repo: "api", records: []*storage.ReviewRecord{review("old", "success", critical)}, wantIssues: 0},
+		{name: "no critical findings", repo: "api", records: []*storage.ReviewRecord{review("abc", "success", warning)}, wantIssues: 0},
+		{name: "unmapped repository", repo: "other", records: []*storage.ReviewRecord{review("abc", "success", critical)}, wantIssues: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls []map[string]interface{}
+			c := &MockCommenter{
+				CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+					if serverName != config.MCPServerJira || toolName != config.ToolJiraCreateIssue {
+						t.Errorf("unexpected tool call %s/%s", serverName, toolName)
+					}
+					calls = append(calls, args)
+					return nil, nil
+				},
+			}
+			project := "PAY"
+			if tt.repo == "other" {
+				project = "OPS"
+			}
+			pr := &domain.PullRequest{ID: "5", ProjectKey: project, RepoSlug: tt.repo, LatestCommit: "abc", WebURL: "https://bb/pr/5"}
+
+			f := NewJiraIssueFiler(cfg, c, &reviewStore{records: tt.records})
+			if err := f.HandleMerged(context.Background(), pr); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(calls) != tt.wantIssues {
+				t.Fatalf("expected %d issues, got %d", tt.wantIssues, len(calls))
+			}
+			if tt.wantIssues == 0 {
+				return
+			}
+			args := calls[0]
+			if args["projectKey"] != "PAYSEC" || args["issueType"] != config.DefaultJiraIssueType {
+				t.Errorf("unexpected target: %v", args)
+			}
+			if desc, _ := args["description"].(string); !strings.Contains(desc, "https://bb/pr/5/diff#db.go?t=12") {
+				t.Errorf("description missing code link: %q", desc)
+			}
+
+			// Redelivered merge events do not file again
+			if err := f.HandleMerged(context.Background(), pr); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if len(calls) != tt.wantIssues {
+				t.Errorf("redelivery filed %d more issues", len(calls)-tt.wantIssues)
+			}
+		})
+	}
+}
+
+func TestJiraIssueFiler_CreateFailure(t *testing.T) {
+	c := &MockCommenter{
+		CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+			return nil, errors.New("jira down")
+		},
+	}
+	store := &reviewStore{records: []*storage.ReviewRecord{{
+		Result: &domain.ReviewResult{Comments: []domain.ReviewComment{{File: "a.go", Severity: "CRITICAL", Comment: "x"}}},
+		Status: "success",
+	}}}
+	cfg := config.JiraIssueConfig{Enabled: true, Projects: []config.JiraProjectMapping{{JiraProject: "OPS"}}}
+
+	f := NewJiraIssueFiler(cfg, c, store)
+	if err := f.HandleMerged(context.Background(), &domain.PullRequest{ID: "1", ProjectKey: "P", RepoSlug: "r"}); err == nil {
+		t.Error("expected error when issue creation fails")
+	}
+}
@@ -0,0 +1,87 @@
+package processor
+
+import (
+	"context"
+	"log/slog"
+	"slices"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/pipeline"
+	"pr-review-automation/internal/rules"
+	"pr-review-automation/internal/validator"
+)
+
+// Analyzer runs static analyzers on the changed files of a PR (pipeline.linters)
+type Analyzer interface {
+	// Matches reports whether an analyzer checks the file
+	Matches(path string) bool
+	// Analyze checks files (path to content) and returns its findings
+	Analyze(ctx context.Context, files map[string]string) []domain.ReviewComment
+}
+
+// SetAnalyzer merges the findings of static analyzers with the model's
+func (p *PRProcessor) SetAnalyzer(a Analyzer) {
+	p.analyzer = a
+}
+
+// startAnalysis fetches the changed files the analyzers check and runs them while the model
+// reviews. The returned function waits for the findings on added lines of diff.
+func (p *PRProcessor) startAnalysis(ctx context.Context, pr *domain.PullRequest, diff string) func() []domain.ReviewComment {
+	if p.analyzer == nil || diff == "" {
+		return func() []domain.ReviewComment { return nil }
+	}
+	done := make(chan []domain.ReviewComment, 1)
+	go func() {
+		done <- p.analyze(ctx, pr, diff)
+	}()
+	return func() []domain.ReviewComment { return <-done }
+}
+
+// analyze fetches the changed files at the PR's latest commit and runs the analyzers on them
+func (p *PRProcessor) analyze(ctx context.Context, pr *domain.PullRequest, diff string) []domain.ReviewComment {
+	files := make(map[string]string)
+	for _, c := range pipeline.ParseDiff(diff) {
+		if len(files) >= p.cfg.Pipeline.Linters.MaxFiles {
+			slog.Info("lint file limit reached", "pr_id", pr.ID, "max_files", p.cfg.Pipeline.Linters.MaxFiles)
+			break
+		}
+		if !p.analyzer.Matches(c.Path) {
+			continue
+		}
+		if rc := pr.RepoConfig; rc != nil && len(rc.Ignore) > 0 && rules.MatchAny(rc.Ignore, c.Path) {
+			continue
+		}
+		content, err := p.fetchFileContent(ctx, pr, c.Path)
+		if err != nil {
+			// Deleted files have no content at the latest commit
+			slog.Debug("fetch file for lint failed", "file", c.Path, "error", err)
+			continue
+		}
+		files[c.Path] = content
+	}
+	if len(files) == 0 {
+		return nil
+	}
+
+	// Only issues on added lines belong to this PR
+	v := validator.NewCommentValidator(diff)
+	var findings []domain.ReviewComment
+	for _, f := range p.analyzer.Analyze(ctx, files) {
+		if v.GetLineType(f.File, int(f.Line)) == "ADDED" {
+			findings = append(findings, f)
+		}
+	}
+	return findings
+}
+
+// addLinterFindings adds the analyzer findings on lines the model did not comment on
+func addLinterFindings(review *domain.ReviewResult, findings []domain.ReviewComment) {
+	for _, f := range findings {
+		commented := slices.ContainsFunc(review.Comments, func(c domain.ReviewComment) bool {
+			return c.File == f.File && c.Line == f.Line
+		})
+		if !commented {
+			review.Comments = append(review.Comments, f)
+		}
+	}
+}
@@ -0,0 +1,82 @@
+package processor
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+// fakeAnalyzer reports fixed findings for .go files
+type fakeAnalyzer struct {
+	findings []domain.ReviewComment
+	files    map[string]string
+}
+
+func (f *fakeAnalyzer) Matches(path string) bool { return strings.HasSuffix(path, ".go") }
+
+func (f *fakeAnalyzer) Analyze(ctx context.Context, files map[string]string) []domain.ReviewComment {
+	f.files = files
+	return f.findings
+}
+
+func TestPRProcessor_MergesLinterFindings(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Pipeline.Linters.MaxFiles = 10
+	diff := `diff --git a/a.go b/a.go
+--- a/a.go
++++ b/a.go
+@@ -1,2 +1,3 @@
+ package a
++func f() { g() }
++func h() {}
+diff --git a/notes.txt b/notes.txt
+--- a/notes.txt
++++ b/notes.txt
+@@ -0,0 +1 @@
++text
+`
+	var mu sync.Mutex
+	var posted []string
+	commenter := &MockCommenter{CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+		switch toolName {
+		case config.ToolBitbucketGetDiff:
+			return diff, nil
+		case config.ToolBitbucketGetFileContent:
+			return "package a\nfunc f() { g() }\nfunc h() {}\n", nil
+		case config.ToolBitbucketAddComment:
+			mu.Lock()
+			posted = append(posted, args["commentText"].(string))
+			mu.Unlock()
+		}
+		return `{"values": []}`, nil
+	}}
+	reviewer := &MockReviewer{ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+		return &domain.ReviewResult{Score: 90, Comments: []domain.ReviewComment{
+			{File: "a.go", Line: 3, Severity: "WARNING", Comment: "h does nothing"},
+		}}, nil
+	}}
+	analyzer := &fakeAnalyzer{findings: []domain.ReviewComment{
+		{File: "a.go", Line: 2, Severity: "WARNING", Comment: "**golangci-lint** (`errcheck`): error not checked"},
+		{File: "a.go", Line: 3, Severity: "INFO", Comment: "**golangci-lint** (`unused`): h is unused"},
+		{File: "a.go", Line: 1, Severity: "INFO", Comment: "**golangci-lint** (`stylecheck`): package comment"},
+	}}
+	p := NewPRProcessor(cfg, reviewer, commenter, nil)
+	p.SetAnalyzer(analyzer)
+
+	pr := &domain.PullRequest{ID: "1", ProjectKey: "PAY", RepoSlug: "api", LatestCommit: "abc"}
+	if err := p.ProcessPullRequest(context.Background(), pr); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := analyzer.files["a.go"]; !ok || len(analyzer.files) != 1 {
+		t.Errorf("analyzed files = %v", analyzer.files)
+	}
+	all := strings.Join(posted, "\n")
+	// The model's comment on line 3 wins; the context line 1 is not part of the PR
+	if !strings.Contains(all, "errcheck") || strings.Contains(all, "h is unused") || strings.Contains(all, "package comment") {
+		t.Errorf("posted:\n%s", all)
+	}
+}
@@ -0,0 +1,173 @@
+package processor
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"pr-review-automation/internal/config"
+)
+
+// markerSet resolves the hidden comment markers from configuration.
+// Markers written with a previous format are migrated to the current one
+// before parsing, so changing the format does not break deduplication.
+type markerSet struct {
+	prefix         string
+	suffix         string
+	legacyPrefixes []string // Sorted longest first
+}
+
+// newMarkerSet builds a markerSet, falling back to the built-in defaults
+func newMarkerSet(cfg config.MarkerConfig) markerSet {
+	m := markerSet{prefix: cfg.Prefix, suffix: cfg.Suffix}
+	if m.prefix == "" {
+		m.prefix = config.MarkerAIReviewPrefix
+	}
+	if m.suffix == "" {
+		m.suffix = config.MarkerAIReviewSuffix
+	}
+
+	// The built-in formats are always recognized as legacy once overridden
+	candidates := append([]string{}, cfg.LegacyPrefixes...)
+	candidates = append(candidates, config.MarkerAIReviewPrefix, config.MarkerAIReviewLegacyPrefix)
+
+	seen := make(map[string]bool)
+	for _, l := range candidates {
+		if l == "" || l == m.prefix || seen[l] {
+			continue
+		}
+		seen[l] = true
+		m.legacyPrefixes = append(m.legacyPrefixes, l)
+	}
+	// Longest first so "<!-- ai-review::" wins over "<!-- ai-review:"
+	sort.Slice(m.legacyPrefixes, func(i, j int) bool {
+		return len(m.legacyPrefixes[i]) > len(m.legacyPrefixes[j])
+	})
+	return m
+}
+
+// fileMarker returns the marker for a merged file comment
+func (m markerSet) fileMarker(path, commit string) string {
+	return fmt.Sprintf("%s%s:%s:%s%s", m.prefix, config.MarkerTypeFile, path, commit, m.suffix)
+}
+
+// summaryMarker returns the marker for the summary comment
+func (m markerSet) summaryMarker(commit string) string {
+	return fmt.Sprintf("%s%s:%s%s", m.prefix, config.MarkerTypeSummary, commit, m.suffix)
+}
+
+// skipMarker returns the marker for a skipped-review transparency note
+func (m markerSet) skipMarker(commit string) string {
+	return fmt.Sprintf("%s%s:%s%s", m.prefix, config.MarkerTypeSkip, commit, m.suffix)
+}
+
+// ignoreMarker returns the marker of the note pausing automatic reviews (/ai ignore)
+func (m markerSet) ignoreMarker() string {
+	return m.prefix + config.MarkerTypeIgnore + m.suffix
+}
+
+// replyMarker returns the marker for an answer to a slash command
+func (m markerSet) replyMarker() string {
+	return m.prefix + config.MarkerTypeReply + m.suffix
+}
+
+// securityMarker returns the marker added below the inline marker of a security finding
+func (m markerSet) securityMarker() string {
+	return m.prefix + config.MarkerTypeSecurity + m.suffix
+}
+
+// descriptionMarker returns the marker of the comment proposing a description
+func (m markerSet) descriptionMarker() string {
+	return m.prefix + config.MarkerTypeDescription + m.suffix
+}
+
+// policyMarker returns the marker of a policy warning; key identifies the violations it lists
+func (m markerSet) policyMarker(key string) string {
+	return fmt.Sprintf("%s%s:%s%s", m.prefix, config.MarkerTypePolicy, key, m.suffix)
+}
+
+// inlineMarker returns the marker for an individual inline comment
+func (m markerSet) inlineMarker(path string, line int, commit string) string {
+	return fmt.Sprintf("%s%s:%d:%s%s", m.prefix, path, line, commit, m.suffix)
+}
+
+// contains reports whether text carries a current or legacy marker
+func (m markerSet) contains(text string) bool {
+	if strings.Contains(text, m.prefix) {
+		return true
+	}
+	for _, l := range m.legacyPrefixes {
+		if strings.Contains(text, l) {
+			return true
+		}
+	}
+	return false
+}
+
+// strip removes current markers from text, for showing comments to the model
+func (m markerSet) strip(text string) string {
+	text = m.migrate(text)
+	for {
+		start := strings.Index(text, m.prefix)
+		if start < 0 {
+			break
+		}
+		end := strings.Index(text[start:], m.suffix)
+		if end < 0 {
+			break
+		}
+		text = text[:start] + text[start+end+len(m.suffix):]
+	}
+	return strings.TrimSpace(text)
+}
+
+// migrate rewrites legacy markers in text to the current format.
+// Current markers are copied unchanged; the legacy suffix is replaced as well
+// when the configured suffix differs from the built-in one.
+func (m markerSet) migrate(text string) string {
+	hasLegacy := false
+	for _, l := range m.legacyPrefixes {
+		if strings.Contains(text, l) {
+			hasLegacy = true
+			break
+		}
+	}
+	if !hasLegacy {
+		return text
+	}
+
+	var sb strings.Builder
+	sb.Grow(len(text))
+	for i := 0; i < len(text); {
+		rest := text[i:]
+		if strings.HasPrefix(rest, m.prefix) {
+			sb.WriteString(m.prefix)
+			i += len(m.prefix)
+			continue
+		}
+
+		matched := ""
+		for _, l := range m.legacyPrefixes {
+			if strings.HasPrefix(rest, l) {
+				matched = l
+				break
+			}
+		}
+		if matched == "" {
+			sb.WriteByte(text[i])
+			i++
+			continue
+		}
+
+		sb.WriteString(m.prefix)
+		i += len(matched)
+		if m.suffix != config.MarkerAIReviewSuffix {
+			if end := strings.Index(text[i:], config.MarkerAIReviewSuffix); end != -1 {
+				sb.WriteString(text[i : i+end])
+				sb.WriteString(m.suffix)
+				i += end + len(config.MarkerAIReviewSuffix)
+			}
+		}
+	}
+	return sb.String()
+}
@@ -0,0 +1,65 @@
+package processor
+
+import (
+	"testing"
+
+	"pr-review-automation/internal/config"
+)
+
+func TestMarkerSet_Migrate(t *testing.T) {
+	tests := []struct {
+		name     string
+		cfg      config.MarkerConfig
+		input    string
+		expected string
+	}{
+		{
+			name:     "current format unchanged",
+			cfg:      config.MarkerConfig{},
+			input:    "<!-- ai-review::file:a.go:abc-->\nbody",
+			expected: "<!-- ai-review::file:a.go:abc-->\nbody",
+		},
+		{
+			name:     "single-colon legacy prefix",
+			cfg:      config.MarkerConfig{},
+			input:    "<!-- ai-review:a.go:10:abc-->",
+			expected: "<!-- ai-review::a.go:10:abc-->",
+		},
+		{
+			name:     "default becomes legacy when overridden",
+			cfg:      config.MarkerConfig{Prefix: "<!-- bot-review::", Suffix: "-->"},
+			input:    "<!-- ai-review::summary:abc-->",
+			expected: "<!-- bot-review::summary:abc-->",
+		},
+		{
+			name:     "legacy suffix replaced with custom suffix",
+			cfg:      config.MarkerConfig{Prefix: "[//]: # (bot::", Suffix: ")"},
+			input:    "<!-- ai-review::file:a.go:abc-->\nrest",
+			expected: "[//]: # (bot::file:a.go:abc)\nrest",
+		},
+		{
+			name:     "configured legacy prefix",
+			cfg:      config.MarkerConfig{LegacyPrefixes: []string{"<!-- reviewbot::"}},
+			input:    "<!-- reviewbot::summary:abc-->",
+			expected: "<!-- ai-review::summary:abc-->",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			m := newMarkerSet(tt.cfg)
+			if got := m.migrate(tt.input); got != tt.expected {
+				t.Errorf("expected %q, got %q", tt.expected, got)
+			}
+		})
+	}
+}
+
+func TestMarkerSet_ParseMigratedMarker(t *testing.T) {
+	m := newMarkerSet(config.MarkerConfig{Prefix: "<!-- bot-review::"})
+
+	mType, key, commit, found := m.parseMarker(m.migrate("<!-- ai-review::file:src/a.go:abc123-->"))
+	if !found || mType != config.MarkerTypeFile || key != "src/a.go" || commit != "abc123" {
+		t.Errorf("unexpected parse result: %s %s %s %v", mType, key, commit, found)
+	}
+}
@@ -0,0 +1,47 @@
+package processor
+
+import (
+	"fmt"
+	"strings"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/rules"
+)
+
+// mentionLine returns the summary line that @mentions the author (and reviewers)
+// when the review has CRITICAL findings, or "" when nobody should be notified.
+func (p *PRProcessor) mentionLine(pr *domain.PullRequest, review *domain.ReviewResult) string {
+	mc := p.cfg.Pipeline.Mentions
+	if !mc.Enabled || !rules.MatchAny(mc.Repos, pr.ProjectKey+"/"+pr.RepoSlug) {
+		return ""
+	}
+
+	critical := 0
+	for _, c := range review.Comments {
+		if c.IsCritical() {
+			critical++
+		}
+	}
+	if critical == 0 {
+		return ""
+	}
+
+	var mentions []string
+	seen := make(map[string]bool)
+	add := func(m string) {
+		if m != "" && !seen[m] {
+			seen[m] = true
+			mentions = append(mentions, m)
+		}
+	}
+	add(pr.AuthorMention)
+	if mc.Reviewers {
+		for _, m := range pr.ReviewerMentions {
+			add(m)
+		}
+	}
+	if len(mentions) == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%s %d critical finding(s) need attention before merging.", strings.Join(mentions, " "), critical)
+}
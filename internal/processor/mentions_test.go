@@ -0,0 +1,42 @@
+package processor
+
+import (
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestPRProcessor_MentionLine(t *testing.T) {
+	pr := &domain.PullRequest{
+		ProjectKey:       "PAY",
+		RepoSlug:         "api",
+		AuthorMention:    "@alice",
+		ReviewerMentions: []string{"@bob", "@alice"},
+	}
+	critical := &domain.ReviewResult{Comments: []domain.ReviewComment{{Severity: "CRITICAL"}, {Severity: "INFO"}}}
+	minor := &domain.ReviewResult{Comments: []domain.ReviewComment{{Severity: "WARNING"}}}
+
+	tests := []struct {
+		name   string
+		cfg    config.MentionsConfig
+		review *domain.ReviewResult
+		want   string
+	}{
+		{"disabled", config.MentionsConfig{}, critical, ""},
+		{"author only", config.MentionsConfig{Enabled: true}, critical, "@alice 1 critical finding(s) need attention before merging."},
+		{"with reviewers", config.MentionsConfig{Enabled: true, Reviewers: true}, critical, "@alice @bob 1 critical finding(s) need attention before merging."},
+		{"no critical findings", config.MentionsConfig{Enabled: true}, minor, ""},
+		{"other repo", config.MentionsConfig{Enabled: true, Repos: []string{"OPS/*"}}, critical, ""},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &config.Config{}
+			cfg.Pipeline.Mentions = tt.cfg
+			p := NewPRProcessor(cfg, nil, nil, nil)
+			if got := p.mentionLine(pr, tt.review); got != tt.want {
+				t.Errorf("mentionLine() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
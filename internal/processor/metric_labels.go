@@ -0,0 +1,35 @@
+package processor
+
+import (
+	"slices"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/rules"
+)
+
+// otherLabel is the project and repo label of PRs outside metrics.projects and metrics.repos
+const otherLabel = "other"
+
+// repoLabels returns the project and repo labels of the PR's core metrics: its own for
+// repositories in metrics.repos, its project and "other" for projects in metrics.projects,
+// and "other" for both otherwise, so the number of series stays bounded
+func (p *PRProcessor) repoLabels(pr *domain.PullRequest) (project, repo string) {
+	if p.cfg == nil {
+		return otherLabel, otherLabel
+	}
+	m := p.cfg.Metrics
+	if len(m.Repos) > 0 && rules.MatchAny(m.Repos, pr.ProjectKey+"/"+pr.RepoSlug) {
+		return pr.ProjectKey, pr.RepoSlug
+	}
+	if slices.Contains(m.Projects, pr.ProjectKey) {
+		return pr.ProjectKey, otherLabel
+	}
+	return otherLabel, otherLabel
+}
+
+// countPR counts the PR in agent_pull_requests_total
+func (p *PRProcessor) countPR(pr *domain.PullRequest, status string) {
+	project, repo := p.repoLabels(pr)
+	metrics.PullRequestTotal.WithLabelValues(status, project, repo).Inc()
+}
@@ -0,0 +1,37 @@
+package processor
+
+import (
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestRepoLabels(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Metrics = config.MetricsConfig{Projects: []string{"PAY", "OPS"}, Repos: []string{"PAY/api", "CORE/*"}}
+	p := &PRProcessor{cfg: cfg}
+
+	tests := []struct {
+		project, repo         string
+		wantProject, wantRepo string
+	}{
+		{"PAY", "api", "PAY", "api"},
+		{"PAY", "web", "PAY", "other"},
+		{"CORE", "auth", "CORE", "auth"},
+		{"OPS", "infra", "OPS", "other"},
+		{"MISC", "tool", "other", "other"},
+	}
+	for _, tt := range tests {
+		project, repo := p.repoLabels(&domain.PullRequest{ProjectKey: tt.project, RepoSlug: tt.repo})
+		if project != tt.wantProject || repo != tt.wantRepo {
+			t.Errorf("repoLabels(%s/%s) = %s/%s, want %s/%s", tt.project, tt.repo, project, repo, tt.wantProject, tt.wantRepo)
+		}
+	}
+
+	// Without an allowlist every PR shares one series
+	p.cfg = &config.Config{}
+	if project, repo := p.repoLabels(&domain.PullRequest{ProjectKey: "PAY", RepoSlug: "api"}); project != "other" || repo != "other" {
+		t.Errorf("repoLabels without allowlist = %s/%s", project, repo)
+	}
+}
@@ -0,0 +1,25 @@
+package processor
+
+import (
+	"strings"
+
+	"pr-review-automation/internal/domain"
+)
+
+// optOut returns the title tag or label with which pr opts out of automated review, or ""
+func (p *PRProcessor) optOut(pr *domain.PullRequest) string {
+	title := strings.ToLower(pr.Title)
+	for _, tag := range p.cfg.Review.SkipTags {
+		if tag != "" && strings.Contains(title, strings.ToLower(tag)) {
+			return "title tag " + tag
+		}
+	}
+	for _, label := range pr.Labels {
+		for _, skip := range p.cfg.Review.SkipLabels {
+			if strings.EqualFold(label, skip) {
+				return "label " + label
+			}
+		}
+	}
+	return ""
+}
@@ -0,0 +1,46 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestPRProcessor_OptOut(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Review.SkipTags = []string{config.DefaultSkipTag}
+	cfg.Review.SkipLabels = []string{"no-ai-review"}
+
+	commenter := &MockCommenter{CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+		return `{"values": []}`, nil
+	}}
+	reviewed := false
+	reviewer := &MockReviewer{ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+		reviewed = true
+		return &domain.ReviewResult{Score: 100}, nil
+	}}
+	p := NewPRProcessor(cfg, reviewer, commenter, nil)
+
+	tests := []struct {
+		name   string
+		pr     *domain.PullRequest
+		review bool
+	}{
+		{"title tag", &domain.PullRequest{Title: "Bump deps [Skip AI]"}, false},
+		{"label", &domain.PullRequest{Title: "Bump deps", Labels: []string{"No-AI-Review"}}, false},
+		{"none", &domain.PullRequest{Title: "Bump deps", Labels: []string{"deps"}}, true},
+		{"requested", &domain.PullRequest{Title: "Bump deps [skip ai]", Overrides: &domain.ReviewOverrides{}}, true},
+	}
+	for _, tt := range tests {
+		reviewed = false
+		tt.pr.ID, tt.pr.ProjectKey, tt.pr.RepoSlug, tt.pr.LatestCommit = "1", "PAY", "api", "abc"
+		if err := p.ProcessPullRequest(context.Background(), tt.pr); err != nil {
+			t.Fatalf("%s: ProcessPullRequest: %v", tt.name, err)
+		}
+		if reviewed != tt.review {
+			t.Errorf("%s: reviewed = %v, want %v", tt.name, reviewed, tt.review)
+		}
+	}
+}
@@ -0,0 +1,49 @@
+package processor
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"time"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/validator"
+)
+
+// PatchRegistry serves submitted diffs to the pipeline under a pull request id of the local
+// provider (see client.PatchBackend)
+type PatchRegistry interface {
+	Add(diff string) (id string, release func())
+}
+
+// SetPatchRegistry sets the registry that serves diffs reviewed by ReviewPatch
+func (p *PRProcessor) SetPatchRegistry(r PatchRegistry) {
+	p.patches = r
+}
+
+// ReviewPatch reviews a raw unified diff that has no code host: the diff is split, reviewed and
+// its findings validated against the diff like a PR review. pr describes the change (title,
+// description, repository); its id and provider are assigned here. Nothing is posted or stored.
+func (p *PRProcessor) ReviewPatch(ctx context.Context, pr *domain.PullRequest, diff string) (*domain.ReviewResult, error) {
+	if p.patches == nil {
+		return nil, errors.New("patch reviews are not configured")
+	}
+	start := time.Now()
+	id, release := p.patches.Add(diff)
+	defer release()
+	pr.ID = id
+	pr.Provider = domain.ProviderLocal
+	ctx = domain.WithProvider(ctx, pr.Provider)
+	ctx = domain.WithProject(ctx, pr.ProjectKey)
+
+	cache := domain.NewReviewContext(func(context.Context) (string, error) { return diff, nil })
+	review, err := p.reviewer.ReviewPR(ctx, &domain.ReviewRequest{PR: pr, Cache: cache})
+	if err != nil {
+		return nil, err
+	}
+	review.RawComments = append([]domain.ReviewComment(nil), review.Comments...)
+	valid, invalid := p.validateComments(review.Comments, validator.NewCommentValidator(diff))
+	review.Comments = valid
+	slog.Info("patch reviewed", "repo", pr.RepoSlug, "comments", len(valid), "invalid", len(invalid), "duration", time.Since(start))
+	return review, nil
+}
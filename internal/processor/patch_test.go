@@ -0,0 +1,52 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+type fakePatchRegistry struct {
+	diffs map[string]string
+}
+
+func (r *fakePatchRegistry) Add(diff string) (string, func()) {
+	r.diffs["1"] = diff
+	return "1", func() { delete(r.diffs, "1") }
+}
+
+func TestPRProcessor_ReviewPatch(t *testing.T) {
+	const diff = "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -0,0 +1,2 @@\n+a\n+b\n"
+	registry := &fakePatchRegistry{diffs: map[string]string{}}
+	reviewer := &MockReviewer{
+		ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+			if domain.ProviderFromContext(ctx) != domain.ProviderLocal || registry.diffs[req.PR.ID] != diff {
+				t.Errorf("pipeline cannot reach the diff of %+v", req.PR)
+			}
+			return &domain.ReviewResult{Score: 80, Comments: []domain.ReviewComment{
+				{File: "main.go", Line: 2, Comment: "unchecked error"},
+				{File: "main.go", Line: 40, Comment: "outside the diff"},
+			}}, nil
+		},
+	}
+	p := NewPRProcessor(&config.Config{}, reviewer, &MockCommenter{}, nil)
+
+	if _, err := p.ReviewPatch(context.Background(), &domain.PullRequest{}, diff); err == nil {
+		t.Error("expected an error without a patch registry")
+	}
+
+	p.SetPatchRegistry(registry)
+	pr := &domain.PullRequest{ProjectKey: domain.ProviderLocal, RepoSlug: "api", Title: "Add retries"}
+	review, err := p.ReviewPatch(context.Background(), pr, diff)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(review.Comments) != 1 || review.Comments[0].Line != 2 || len(review.RawComments) != 2 {
+		t.Errorf("expected only the finding inside the diff, got %+v", review.Comments)
+	}
+	if len(registry.diffs) != 0 {
+		t.Error("patch not released after the review")
+	}
+}
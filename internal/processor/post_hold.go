@@ -0,0 +1,120 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/validator"
+)
+
+// heldPost is a review whose non-critical findings wait for working hours
+type heldPost struct {
+	pr     *domain.PullRequest
+	review *domain.ReviewResult
+}
+
+// postHold queues non-critical posts outside working hours and releases them at the next start.
+// Held posts live in memory; ReleaseHeld flushes them on shutdown.
+type postHold struct {
+	schedule *config.WorkingSchedule
+	now      func() time.Time
+
+	mu    sync.Mutex
+	posts map[string]heldPost // "PROJECT/repo/id" -> latest held review
+	timer *time.Timer
+}
+
+func newPostHold(schedule *config.WorkingSchedule) *postHold {
+	return &postHold{schedule: schedule, now: time.Now, posts: make(map[string]heldPost)}
+}
+
+// active reports whether posting is currently outside working hours
+func (h *postHold) active() bool {
+	return !h.schedule.Contains(h.now())
+}
+
+// add queues a post and schedules the release; a newer review of the same PR replaces the older one
+func (h *postHold) add(pr *domain.PullRequest, review *domain.ReviewResult, release func()) time.Time {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	h.posts[fmt.Sprintf("%s/%s/%s", pr.ProjectKey, pr.RepoSlug, pr.ID)] = heldPost{pr: pr, review: review}
+	metrics.HeldPosts.Set(float64(len(h.posts)))
+
+	at := h.schedule.NextStart(h.now())
+	if h.timer == nil {
+		h.timer = time.AfterFunc(at.Sub(h.now()), release)
+	}
+	return at
+}
+
+// take removes and returns all held posts
+func (h *postHold) take() []heldPost {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+
+	if h.timer != nil {
+		h.timer.Stop()
+		h.timer = nil
+	}
+	posts := make([]heldPost, 0, len(h.posts))
+	for k, p := range h.posts {
+		posts = append(posts, p)
+		delete(h.posts, k)
+	}
+	metrics.HeldPosts.Set(0)
+	return posts
+}
+
+// holdNonCritical posts CRITICAL findings now and queues the rest (including the summary).
+// Returns the error of the immediate post.
+func (p *PRProcessor) holdNonCritical(ctx context.Context, pr *domain.PullRequest, review *domain.ReviewResult, commentValidator *validator.CommentValidator) error {
+	var critical, rest []domain.ReviewComment
+	for _, c := range review.Comments {
+		if c.IsCritical() {
+			critical = append(critical, c)
+		} else {
+			rest = append(rest, c)
+		}
+	}
+
+	held := *review
+	held.Comments = rest
+	at := p.hold.add(pr, &held, func() { p.ReleaseHeld(context.Background()) })
+	slog.Info("outside working hours, holding non-critical findings",
+		"pr_id", pr.ID, "critical", len(critical), "held", len(rest), "release_at", at)
+
+	if len(critical) == 0 {
+		return nil
+	}
+	return p.postIndividualComments(ctx, pr, critical, commentValidator)
+}
+
+// ReleaseHeld posts all findings held for working hours. It is called by the release timer
+// and on shutdown so held findings are not lost.
+func (p *PRProcessor) ReleaseHeld(ctx context.Context) {
+	if p.hold == nil {
+		return
+	}
+	posts := p.hold.take()
+	if len(posts) > 0 {
+		slog.Info("releasing held findings", "prs", len(posts))
+	}
+	for _, h := range posts {
+		ctx := domain.WithProvider(ctx, h.pr.Provider)
+		// Re-read PR state: comments may have been posted and the diff may have moved since the hold
+		existing := p.fetchExistingAIComments(ctx, h.pr)
+		commentValidator := validator.NewCommentValidator(p.fetchDiff(ctx, h.pr))
+		h.review.Comments = p.filterDuplicates(h.review.Comments, existing)
+
+		if err := p.postComments(ctx, h.pr, h.review, existing, commentValidator); err != nil {
+			slog.Error("post held findings failed", "pr_id", h.pr.ID, "error", err)
+		}
+	}
+}
@@ -0,0 +1,89 @@
+package processor
+
+import (
+	"context"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestPRProcessor_HoldsNonCriticalOutsideWorkingHours(t *testing.T) {
+	reviewer := &MockReviewer{
+		ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+			return &domain.ReviewResult{
+				Comments: []domain.ReviewComment{
+					{File: "main.go", Line: 1, Severity: "CRITICAL", Comment: "Must fix"},
+					{File: "main.go", Line: 2, Severity: "INFO", Comment: "Consider renaming"},
+				},
+				Summary: "Summary",
+			}, nil
+		},
+	}
+
+	var mu sync.Mutex
+	var posted []string
+	commenter := &MockCommenter{
+		CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+			switch toolName {
+			case config.ToolBitbucketGetComments:
+				return `{"values":[]}`, nil
+			case config.ToolBitbucketGetDiff:
+				return "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -0,0 +1,2 @@\n+a\n+b", nil
+			case config.ToolBitbucketAddComment:
+				mu.Lock()
+				posted = append(posted, args["commentText"].(string))
+				mu.Unlock()
+			}
+			return nil, nil
+		},
+	}
+
+	cfg := &config.Config{}
+	cfg.Pipeline.CommentMerge.Enabled = false
+	cfg.Pipeline.WorkingHours = config.WorkingHoursConfig{Enabled: true, Timezone: "UTC", Start: "09:00", End: "18:00", Weekdays: []string{"mon", "tue", "wed", "thu", "fri"}}
+	p := NewPRProcessor(cfg, reviewer, commenter, nil)
+	if p.hold == nil {
+		t.Fatal("expected working hours hold to be enabled")
+	}
+	p.hold.now = func() time.Time { return time.Date(2026, 3, 2, 23, 0, 0, 0, time.UTC) }
+
+	pr := &domain.PullRequest{ID: "1", ProjectKey: "P", RepoSlug: "r"}
+	if err := p.ProcessPullRequest(context.Background(), pr); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(posted) != 1 || !strings.Contains(posted[0], "Must fix") {
+		t.Fatalf("expected only the critical finding to be posted, got %q", posted)
+	}
+
+	p.ReleaseHeld(context.Background())
+	if len(posted) != 2 || !strings.Contains(posted[1], "Consider renaming") {
+		t.Fatalf("expected held finding to be posted on release, got %q", posted)
+	}
+
+	// Released posts are not posted twice
+	p.ReleaseHeld(context.Background())
+	if len(posted) != 2 {
+		t.Errorf("expected no further posts, got %d", len(posted))
+	}
+}
+
+func TestPostHold_NewerReviewReplacesHeld(t *testing.T) {
+	schedule, err := config.WorkingHoursConfig{Start: "09:00", End: "18:00", Weekdays: []string{"mon"}}.Schedule()
+	if err != nil {
+		t.Fatal(err)
+	}
+	h := newPostHold(schedule)
+	pr := &domain.PullRequest{ID: "1", ProjectKey: "P", RepoSlug: "r"}
+
+	h.add(pr, &domain.ReviewResult{Summary: "old"}, func() {})
+	h.add(pr, &domain.ReviewResult{Summary: "new"}, func() {})
+
+	posts := h.take()
+	if len(posts) != 1 || posts[0].review.Summary != "new" {
+		t.Errorf("expected only the newest review to be held, got %+v", posts)
+	}
+}
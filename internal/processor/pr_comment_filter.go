@@ -14,6 +14,11 @@ import (
 	"github.com/tidwall/gjson"
 )
 
+// markers returns the configured comment markers
+func (p *PRProcessor) markers() markerSet {
+	return newMarkerSet(p.cfg.Pipeline.Markers)
+}
+
 // validateComments validates comments against diff ranges
 func (p *PRProcessor) validateComments(comments []domain.ReviewComment, v *validator.CommentValidator) (valid, invalid []domain.ReviewComment) {
 	for _, c := range comments {
@@ -83,20 +88,24 @@ func (p *PRProcessor) fetchExistingAIComments(ctx context.Context, pr *domain.Pu
 	jsonStr := string(jsonBytes)
 
 	var comments []domain.ReviewComment
+	markers := p.markers()
 
 	// Parse using gjson
-	// Assuming structure: { "values": [ { "content": { "raw": "..." }, "inline": { "path": "...", "from": 123 } } ] }
+	// Bitbucket Cloud shape: { "values": [ { "content": { "raw": "..." }, "inline": { "path": "...", "to": 123 } } ] }
+	// Bitbucket Server shape: { "values": [ { "text": "...", "anchor": { "path": "...", "line": 123 } } ] },
+	// or activities with the same fields under "comment"
 	gjson.Get(jsonStr, "values").ForEach(func(key, value gjson.Result) bool {
-		rawContent := value.Get("content.raw").String()
+		// Rewrite legacy markers so historical comments dedupe against the current format
+		rawContent := markers.migrate(firstOf(value, "content.raw", "text", "comment.text").String())
 
 		// Check for AI marker
-		if strings.Contains(rawContent, config.MarkerAIReviewPrefix) || strings.Contains(rawContent, config.MarkerAIReviewVisible) {
-			path := value.Get("inline.path").String()
+		if markers.contains(rawContent) || strings.Contains(rawContent, config.MarkerAIReviewVisible) {
+			path := firstOf(value, "inline.path", "anchor.path", "comment.anchor.path").String()
 			// 'to' is usually the line number in PR diffs for added/modified lines in Bitbucket
-			line := int(value.Get("inline.to").Int())
+			line := int(firstOf(value, "inline.to", "anchor.line", "comment.anchor.line").Int())
 
 			// Check if content contains a table (Merged Comment)
-			tableComments := parseTableComments(rawContent)
+			tableComments := markers.parseTableComments(rawContent)
 			if len(tableComments) > 0 {
 				comments = append(comments, tableComments...)
 			}
@@ -104,8 +113,8 @@ func (p *PRProcessor) fetchExistingAIComments(ctx context.Context, pr *domain.Pu
 			// If path/line not in inline (e.g. general comment), try to parse from marker
 			if path == "" {
 				// Parse from marker: <!-- ai-review:file:line -->
-				if start := strings.Index(rawContent, config.MarkerAIReviewPrefix); start != -1 {
-					end := strings.Index(rawContent[start:], config.MarkerAIReviewSuffix)
+				if start := strings.Index(rawContent, markers.prefix); start != -1 {
+					end := strings.Index(rawContent[start:], markers.suffix)
 					if end != -1 {
 						marker := rawContent[start : start+end]
 						parts := strings.Split(marker, ":")
@@ -122,17 +131,17 @@ func (p *PRProcessor) fetchExistingAIComments(ctx context.Context, pr *domain.Pu
 			// Clean comment content (remove marker)
 			cleanComment := rawContent
 			// Remove HTML comments
-			if idx := strings.Index(cleanComment, config.MarkerAIReviewSuffix); idx != -1 {
-				cleanComment = strings.TrimSpace(cleanComment[idx+len(config.MarkerAIReviewSuffix):])
+			if idx := strings.Index(cleanComment, markers.suffix); idx != -1 {
+				cleanComment = strings.TrimSpace(cleanComment[idx+len(markers.suffix):])
 			}
 
 			// Identify if this is a legacy/individual comment (not table)
 			if len(tableComments) == 0 && path != "" {
 				// Capture marker
 				var marker string
-				if start := strings.Index(rawContent, config.MarkerAIReviewPrefix); start != -1 {
-					if end := strings.Index(rawContent[start:], config.MarkerAIReviewSuffix); end != -1 {
-						marker = rawContent[start : start+end+len(config.MarkerAIReviewSuffix)]
+				if start := strings.Index(rawContent, markers.prefix); start != -1 {
+					if end := strings.Index(rawContent[start:], markers.suffix); end != -1 {
+						marker = rawContent[start : start+end+len(markers.suffix)]
 					}
 				}
 
@@ -150,15 +159,25 @@ func (p *PRProcessor) fetchExistingAIComments(ctx context.Context, pr *domain.Pu
 	return comments
 }
 
+// firstOf returns the first of paths that exists in v
+func firstOf(v gjson.Result, paths ...string) gjson.Result {
+	for _, p := range paths {
+		if r := v.Get(p); r.Exists() {
+			return r
+		}
+	}
+	return gjson.Result{}
+}
+
 // parseTableComments extracts comments from Markdown tables in the message
-func parseTableComments(content string) []domain.ReviewComment {
+func (m markerSet) parseTableComments(content string) []domain.ReviewComment {
 	var comments []domain.ReviewComment
 
 	// Check for file path in header/marker
 	// Default file from marker if present e.g. <!-- ai-review::file:src/main.go:commit -->
 	var defaultFile string
-	if start := strings.Index(content, config.MarkerAIReviewPrefix+config.MarkerTypeFile+":"); start != -1 {
-		rest := content[start+len(config.MarkerAIReviewPrefix+config.MarkerTypeFile+":"):]
+	if start := strings.Index(content, m.prefix+config.MarkerTypeFile+":"); start != -1 {
+		rest := content[start+len(m.prefix+config.MarkerTypeFile+":"):]
 		if idx := strings.Index(rest, ":"); idx != -1 {
 			defaultFile = rest[:idx]
 		}
@@ -280,7 +299,7 @@ func extractLineFromLink(text string) int {
 // hasExistingSummary checks if a summary comment exists for the commit
 func (p *PRProcessor) hasExistingSummary(comments []domain.ReviewComment, commit string) bool {
 	for _, c := range comments {
-		_, _, markerCommit, found := parseMarker(c.Marker)
+		_, _, markerCommit, found := p.markers().parseMarker(c.Marker)
 		if found && markerTypeFromMarker(c.Marker) == config.MarkerTypeSummary && markerCommit == commit {
 			return true
 		}
@@ -295,11 +314,12 @@ func (p *PRProcessor) filterExistingFileComments(
 	commit string,
 ) []MergedFileComment {
 	existingFiles := make(map[string]bool)
+	markers := p.markers()
 	for _, c := range existingComments {
 		if c.Marker == "" {
 			continue
 		}
-		mType, key, mCommit, found := parseMarker(c.Marker)
+		mType, key, mCommit, found := markers.parseMarker(c.Marker)
 		if found && mType == config.MarkerTypeFile && mCommit == commit {
 			existingFiles[key] = true
 		}
@@ -327,15 +347,15 @@ func markerTypeFromMarker(text string) string {
 }
 
 // parseMarker extracts marker type, key, and commit from comment text
-func parseMarker(text string) (mType, key, commit string, found bool) {
-	// MarkerPrefixFile = "<!-- ai-review::file:"
-	filePrefix := config.MarkerAIReviewPrefix + config.MarkerTypeFile + ":"
-	summaryPrefix := config.MarkerAIReviewPrefix + config.MarkerTypeSummary + ":"
+func (m markerSet) parseMarker(text string) (mType, key, commit string, found bool) {
+	// e.g. "<!-- ai-review::file:"
+	filePrefix := m.prefix + config.MarkerTypeFile + ":"
+	summaryPrefix := m.prefix + config.MarkerTypeSummary + ":"
 
 	if strings.HasPrefix(text, filePrefix) {
 		// e.g. "path:commit -->"
 		content := text[len(filePrefix):]
-		if idx := strings.Index(content, config.MarkerAIReviewSuffix); idx != -1 {
+		if idx := strings.Index(content, m.suffix); idx != -1 {
 			content = content[:idx]
 			// content is "path:commit"
 			lastColon := strings.LastIndex(content, ":")
@@ -349,7 +369,7 @@ func parseMarker(text string) (mType, key, commit string, found bool) {
 	} else if strings.HasPrefix(text, summaryPrefix) {
 		// e.g. "commit -->"
 		content := text[len(summaryPrefix):]
-		if idx := strings.Index(content, config.MarkerAIReviewSuffix); idx != -1 {
+		if idx := strings.Index(content, m.suffix); idx != -1 {
 			commit = content[:idx]
 			mType = config.MarkerTypeSummary
 			key = "summary"
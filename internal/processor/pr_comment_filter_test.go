@@ -160,3 +160,45 @@ func TestFetchExistingAIComments_TableParsing(t *testing.T) {
 	}
 	assert.True(t, found2, "Did not find comment on line 23")
 }
+
+func TestFetchExistingAIComments_ServerAndCloudShapes(t *testing.T) {
+	proc := &PRProcessor{cfg: &config.Config{}}
+	proc.commenter = &MockCommenter{
+		CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+			return map[string]interface{}{
+				"values": []interface{}{
+					// Bitbucket Cloud
+					map[string]interface{}{
+						"content": map[string]interface{}{"raw": "<!-- ai-review::a.go:3:c1 -->\nnil deref"},
+						"inline":  map[string]interface{}{"path": "a.go", "to": 3},
+					},
+					// Bitbucket Server comment
+					map[string]interface{}{
+						"text":   "<!-- ai-review::b.go:7:c1 -->\nunused var",
+						"anchor": map[string]interface{}{"path": "b.go", "line": 7},
+					},
+					// Bitbucket Server activity
+					map[string]interface{}{
+						"comment": map[string]interface{}{
+							"text":   "<!-- ai-review::c.go:9:c1 -->\nshadowed err",
+							"anchor": map[string]interface{}{"path": "c.go", "line": 9},
+						},
+					},
+				},
+			}, nil
+		},
+	}
+
+	comments := proc.fetchExistingAIComments(context.Background(), &domain.PullRequest{ID: "1", ProjectKey: "P", RepoSlug: "r"})
+
+	assert.Len(t, comments, 3)
+	for i, want := range []domain.ReviewComment{
+		{File: "a.go", Line: 3, Comment: "nil deref"},
+		{File: "b.go", Line: 7, Comment: "unused var"},
+		{File: "c.go", Line: 9, Comment: "shadowed err"},
+	} {
+		assert.Equal(t, want.File, comments[i].File)
+		assert.Equal(t, want.Line, comments[i].Line)
+		assert.Equal(t, want.Comment, comments[i].Comment)
+	}
+}
@@ -4,12 +4,14 @@ import (
 	"context"
 	"fmt"
 	"log/slog"
+	"sort"
 	"strconv"
 	"strings"
 
 	"pr-review-automation/internal/config"
 	"pr-review-automation/internal/domain"
 	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/types"
 	"pr-review-automation/internal/validator"
 
 	"golang.org/x/sync/errgroup"
@@ -19,12 +21,37 @@ func (p *PRProcessor) postComments(ctx context.Context, pr *domain.PullRequest,
 	if p.cfg.Pipeline.CommentMerge.Enabled {
 		return p.postMergedComments(ctx, pr, review, existingComments, validator)
 	}
-	return p.postIndividualComments(ctx, pr, review.Comments, validator)
+	// Without merging there is no summary to fold lower severities into
+	return p.postIndividualComments(ctx, pr, atOrAbove(review.Comments, p.cfg.Review.MinPostSeverity), validator)
+}
+
+// atOrAbove returns the comments of severity minSeverity or higher; all comments when it is empty
+func atOrAbove(comments []domain.ReviewComment, minSeverity string) []domain.ReviewComment {
+	if minSeverity == "" {
+		return comments
+	}
+	limit := severityRank(minSeverity)
+	var kept []domain.ReviewComment
+	for _, c := range comments {
+		if rank := severityRank(c.Severity); rank >= 0 && rank <= limit {
+			kept = append(kept, c)
+		}
+	}
+	if dropped := len(comments) - len(kept); dropped > 0 {
+		slog.Info("findings below min_post_severity not posted", "min_severity", minSeverity, "dropped", dropped)
+	}
+	return kept
 }
 
 func (p *PRProcessor) postMergedComments(ctx context.Context, pr *domain.PullRequest, review *domain.ReviewResult, existingComments []domain.ReviewComment, validator *validator.CommentValidator) error {
 	merger := NewCommentMerger(&p.cfg.Pipeline.CommentMerge, pr.WebURL)
-	result := merger.Merge(review.Comments, pr.LatestCommit)
+	merger.markers = p.markers()
+	merger.summary = p.summaryTemplate
+	merger.minPost = p.cfg.Review.MinPostSeverity
+	// Security findings are posted on their own, never merged with style and logic feedback
+	security, others := splitSecurityComments(review.Comments)
+	result := merger.Merge(others, pr.LatestCommit)
+	result.NotMerged = append(result.NotMerged, security...)
 
 	pullRequestId, _ := strconv.Atoi(pr.ID)
 
@@ -32,10 +59,31 @@ func (p *PRProcessor) postMergedComments(ctx context.Context, pr *domain.PullReq
 	// Filter existing file comments
 	toPostFiles := p.filterExistingFileComments(existingComments, result.FileComments, pr.LatestCommit)
 
+	// With update_in_place, the comment of an earlier commit is edited instead of posting another
+	var posted map[string]postedComment
+	if p.cfg.Pipeline.CommentMerge.UpdateInPlace && len(toPostFiles) > 0 {
+		posted = p.postedFileComments(ctx, pr)
+	}
+
 	for _, fc := range toPostFiles {
 		fc.ModelName = review.Model
 		commentText := merger.FormatFileComment(&fc)
 
+		if prev, ok := posted[fc.FilePath]; ok {
+			if prev.commit == pr.LatestCommit {
+				slog.Info("skipping existing file comment", "file", fc.FilePath)
+				continue
+			}
+			err := p.updateFileComment(ctx, pr, prev, commentText)
+			if err == nil {
+				slog.Debug("updated merged file comment", "file", fc.FilePath, "comment_id", prev.id)
+				metrics.FileCommentUpdates.WithLabelValues("success").Inc()
+				continue
+			}
+			slog.Warn("update file comment failed, posting a new one", "file", fc.FilePath, "error", err)
+			metrics.FileCommentUpdates.WithLabelValues("error").Inc()
+		}
+
 		args := map[string]interface{}{
 			"projectKey":    pr.ProjectKey,
 			"repoSlug":      pr.RepoSlug,
@@ -87,17 +135,50 @@ func (p *PRProcessor) postMergedComments(ctx context.Context, pr *domain.PullReq
 	}
 
 	// 2. Post summary with INFO/NIT appended
+	p.postSummary(ctx, pr, review, merger, result.SummaryAddons, existingComments)
+
+	return p.cleanupSession(pr.ID)
+}
+
+// postSummary posts the summary comment unless one exists for the PR's latest commit
+func (p *PRProcessor) postSummary(ctx context.Context, pr *domain.PullRequest, review *domain.ReviewResult, merger *CommentMerger, addons []domain.ReviewComment, existingComments []domain.ReviewComment) {
+	pullRequestId, _ := strconv.Atoi(pr.ID)
+
 	// Check if summary for this commit already exists
 	if !p.hasExistingSummary(existingComments, pr.LatestCommit) {
-		summaryText := cleanSummaryMarkdown(review.Summary)
-		addonsText := merger.FormatSummaryAddons(result.SummaryAddons)
+		fullSummary, err := merger.FormatSummary(review, addons, summaryLayout(p.cfg.Pipeline.Summary, pr))
+		if err != nil {
+			// A broken custom template must not lose the summary
+			slog.Warn("render summary failed, using classic layout", "error", err)
+			fullSummary, _ = merger.FormatSummary(review, addons, config.SummaryLayoutClassic)
+		}
 
-		fullSummary := fmt.Sprintf("**AI Review Summary (Model: %s)**\nScore: %d\n\n%s%s",
-			review.Model, review.Score, summaryText, addonsText)
+		if note := duplicateNote(review.Duplicates); note != "" {
+			fullSummary += "\n\n" + note
+		}
+
+		if note := assetNote(review.Assets); note != "" {
+			fullSummary += "\n\n" + note
+		}
+
+		if note := coverageNote(review.Coverage); note != "" {
+			fullSummary += "\n\n" + note
+		}
+
+		if review.SummaryOnly {
+			fullSummary += "\n\n" + summaryOnlyNote(review.Comments)
+		}
+
+		if mention := p.mentionLine(pr, review); mention != "" {
+			fullSummary += "\n\n" + mention
+		}
 
 		// Add marker
-		marker := fmt.Sprintf("%s%s:%s%s", config.MarkerAIReviewPrefix, config.MarkerTypeSummary, pr.LatestCommit, config.MarkerAIReviewSuffix)
+		marker := p.markers().summaryMarker(pr.LatestCommit)
 		footer := fmt.Sprintf("\n---\n*Automatically generated by %s*", review.Model)
+		if line := p.provenanceFooter(review.Provenance); line != "" {
+			footer += "\n" + line
+		}
 		fullSummary = marker + "\n\n" + fullSummary + footer
 
 		args := map[string]interface{}{
@@ -107,7 +188,7 @@ func (p *PRProcessor) postMergedComments(ctx context.Context, pr *domain.PullReq
 			"commentText":   fullSummary,
 		}
 
-		_, err := p.commenter.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketAddComment, args)
+		_, err = p.commenter.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketAddComment, args)
 		if err != nil {
 			slog.Error("post summary failed", "error", err)
 			metrics.CommentPostFailures.WithLabelValues("summary_error").Inc()
@@ -115,8 +196,6 @@ func (p *PRProcessor) postMergedComments(ctx context.Context, pr *domain.PullReq
 	} else {
 		slog.Info("summary for commit already exists, skipping", "commit", pr.LatestCommit)
 	}
-
-	return p.cleanupSession(pr.ID)
 }
 
 func (p *PRProcessor) postIndividualComments(ctx context.Context, pr *domain.PullRequest, comments []domain.ReviewComment, validator *validator.CommentValidator) error {
@@ -125,41 +204,44 @@ func (p *PRProcessor) postIndividualComments(ctx context.Context, pr *domain.Pul
 		return fmt.Errorf("invalid pr id: %s", pr.ID)
 	}
 
+	// Post in file/line order so repeated runs produce the same PR activity
+	comments = sortCommentsForPosting(comments)
+
+	// CRITICAL findings that need a blocking task are posted first, one by one
+	comments, taskComments := p.splitTaskComments(pr, comments)
+	if len(taskComments) > 0 {
+		p.postCommentsWithTasks(ctx, pr, pullRequestId, taskComments, validator)
+	}
+
+	// Prefer a single batch call when the MCP server exposes one
+	if len(comments) > 0 && p.supportsBatchComments() {
+		err := p.postCommentBatch(ctx, pr, pullRequestId, comments, validator)
+		if err == nil {
+			return p.cleanupSession(pr.ID)
+		}
+		slog.Warn("batch comment post failed, falling back to individual posting", "count", len(comments), "error", err)
+		metrics.CommentPostFailures.WithLabelValues("batch_fallback").Inc()
+		// The server may have applied the batch, or part of it, before the error
+		comments = p.filterDuplicates(comments, p.fetchExistingAIComments(ctx, pr))
+	}
+
 	// Use errgroup to post comments in parallel
 	limit := p.cfg.Pipeline.MaxConcurrentComments
 	if limit <= 0 {
 		limit = 5
 	}
+	if p.cfg.Pipeline.SerialCommentPosting {
+		// With a limit of 1, g.Go blocks until the previous post returns,
+		// so comments are posted strictly in sorted order
+		limit = 1
+	}
 	g, gCtx := errgroup.WithContext(ctx)
 	g.SetLimit(limit)
 
 	for _, comment := range comments {
 		comment := comment
 		g.Go(func() error {
-			args := map[string]interface{}{
-				"projectKey":    pr.ProjectKey,
-				"repoSlug":      pr.RepoSlug,
-				"pullRequestId": pullRequestId,
-				"commentText":   fmt.Sprintf("%s%s:%d:%s%s\n%s", config.MarkerAIReviewPrefix, comment.File, int(comment.Line), pr.LatestCommit, config.MarkerAIReviewSuffix, comment.Comment),
-			}
-
-			if comment.File != "" {
-				args["filePath"] = comment.File
-
-				// Determine line type dynamically
-				lineType := "ADDED" // Default fallback
-				if validator != nil {
-					lt := validator.GetLineType(comment.File, int(comment.Line))
-					if lt != "" {
-						lineType = lt
-					}
-				}
-				args["lineType"] = lineType
-
-				if comment.Line > 0 {
-					args["lineNumber"] = strconv.Itoa(int(comment.Line))
-				}
-			}
+			args := p.buildInlineCommentArgs(pr, pullRequestId, comment, validator)
 
 			slog.Debug("post comment", "file", comment.File, "line", int(comment.Line))
 			_, err := p.commenter.CallTool(gCtx, config.MCPServerBitbucket, config.ToolBitbucketAddComment, args)
@@ -178,6 +260,111 @@ func (p *PRProcessor) postIndividualComments(ctx context.Context, pr *domain.Pul
 	return p.cleanupSession(pr.ID)
 }
 
+// buildInlineCommentArgs builds the add-comment tool arguments for a single inline comment
+func (p *PRProcessor) buildInlineCommentArgs(pr *domain.PullRequest, pullRequestId int, comment domain.ReviewComment, validator *validator.CommentValidator) map[string]interface{} {
+	args := map[string]interface{}{
+		"projectKey":    pr.ProjectKey,
+		"repoSlug":      pr.RepoSlug,
+		"pullRequestId": pullRequestId,
+		"commentText":   p.markers().inlineMarker(comment.File, int(comment.Line), pr.LatestCommit) + "\n" + inlineCommentText(comment),
+	}
+	if comment.Category == domain.CommentCategorySecurity {
+		args["commentText"] = p.markers().inlineMarker(comment.File, int(comment.Line), pr.LatestCommit) + "\n" +
+			p.markers().securityMarker() + "\n🔒 **Security:** " + inlineCommentText(comment)
+	}
+
+	if comment.File != "" {
+		args["filePath"] = comment.File
+
+		// Determine line type dynamically
+		lineType := "ADDED" // Default fallback
+		if validator != nil {
+			lt := validator.GetLineType(comment.File, int(comment.Line))
+			if lt != "" {
+				lineType = lt
+			}
+		}
+		args["lineType"] = lineType
+
+		if comment.Line > 0 {
+			args["lineNumber"] = strconv.Itoa(int(comment.Line))
+		}
+	}
+	return args
+}
+
+// inlineCommentText returns the comment message with its suggested replacement (review
+// contract v2), rendered as a suggestion block the SCM can apply
+func inlineCommentText(c domain.ReviewComment) string {
+	if strings.TrimSpace(c.Suggestion) == "" || c.File == "" || c.Line <= 0 {
+		return c.Comment
+	}
+	return c.Comment + "\n\n```suggestion\n" + strings.TrimRight(c.Suggestion, "\n") + "\n```"
+}
+
+// supportsBatchComments reports whether the Bitbucket MCP server advertises the batch comment tool
+func (p *PRProcessor) supportsBatchComments() bool {
+	provider, ok := p.commenter.(types.RawSchemaProvider)
+	if !ok {
+		return false
+	}
+	for _, t := range provider.GetRawToolSchemas()[config.MCPServerBitbucket] {
+		if t.Name == config.ToolBitbucketAddComments {
+			return true
+		}
+	}
+	return false
+}
+
+// postCommentBatch posts all inline comments in one tool call.
+// Any error triggers the per-comment fallback, for the comments not found on the PR afterwards.
+func (p *PRProcessor) postCommentBatch(ctx context.Context, pr *domain.PullRequest, pullRequestId int, comments []domain.ReviewComment, validator *validator.CommentValidator) error {
+	items := make([]map[string]interface{}, 0, len(comments))
+	for _, c := range comments {
+		args := p.buildInlineCommentArgs(pr, pullRequestId, c, validator)
+		// Shared PR identity is sent once at the top level
+		delete(args, "projectKey")
+		delete(args, "repoSlug")
+		delete(args, "pullRequestId")
+		items = append(items, args)
+	}
+
+	slog.Debug("post comment batch", "count", len(items))
+	_, err := p.commenter.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketAddComments, map[string]interface{}{
+		"projectKey":    pr.ProjectKey,
+		"repoSlug":      pr.RepoSlug,
+		"pullRequestId": pullRequestId,
+		"comments":      items,
+	})
+	return err
+}
+
+// splitSecurityComments separates the findings of the security review pass from the others
+func splitSecurityComments(comments []domain.ReviewComment) (security, others []domain.ReviewComment) {
+	for _, c := range comments {
+		if c.Category == domain.CommentCategorySecurity {
+			security = append(security, c)
+		} else {
+			others = append(others, c)
+		}
+	}
+	return security, others
+}
+
+// sortCommentsForPosting returns a copy of comments ordered by file and line.
+// The sort is stable so comments on the same line keep the model's order.
+func sortCommentsForPosting(comments []domain.ReviewComment) []domain.ReviewComment {
+	sorted := make([]domain.ReviewComment, len(comments))
+	copy(sorted, comments)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		if sorted[i].File != sorted[j].File {
+			return sorted[i].File < sorted[j].File
+		}
+		return sorted[i].Line < sorted[j].Line
+	})
+	return sorted
+}
+
 func (p *PRProcessor) cleanupSession(prID string) error {
 	if cleaner, ok := p.commenter.(interface{ ClearSessionHistory(string) }); ok {
 		cleaner.ClearSessionHistory("pr-" + prID)
@@ -0,0 +1,92 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"log/slog"
+	"path"
+	"regexp"
+	"slices"
+	"strings"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/rules"
+)
+
+// policyViolation is a PR title or branch that breaks pipeline.pr_policy
+type policyViolation struct {
+	check   string // Metric label: title, branch, target_branch
+	message string
+}
+
+// checkPolicy posts a WARNING comment when the title or branches of pr break pipeline.pr_policy.
+// The same violations are reported once; a renamed title or retargeted PR that still breaks the
+// policy gets a new warning. Failures are logged only.
+func (p *PRProcessor) checkPolicy(ctx context.Context, pr *domain.PullRequest) {
+	cfg := p.cfg.Pipeline.PRPolicy
+	if !cfg.Enabled || (len(cfg.Repos) > 0 && !rules.MatchAny(cfg.Repos, pr.ProjectKey+"/"+pr.RepoSlug)) {
+		return
+	}
+	violations := policyViolations(cfg, pr)
+	if len(violations) == 0 {
+		return
+	}
+	text := policyWarning(violations)
+	if p.dryRun(pr) {
+		slog.Info("dry run, policy warning not posted", "pr_id", pr.ID, "repo", pr.RepoSlug, "violations", len(violations))
+		return
+	}
+
+	h := fnv.New32a()
+	h.Write([]byte(text))
+	marker := p.markers().policyMarker(fmt.Sprintf("%08x", h.Sum32()))
+	posted, err := p.hasComment(ctx, pr, marker)
+	if err != nil {
+		slog.Warn("fetch comments for policy warning failed", "pr_id", pr.ID, "error", err)
+		return
+	}
+	if posted {
+		return
+	}
+	if err := postReply(ctx, p.commenter, pr, 0, marker+"\n"+text); err != nil {
+		slog.Warn("post policy warning failed", "pr_id", pr.ID, "error", err)
+		metrics.CommentPostFailures.WithLabelValues("policy_warning").Inc()
+		return
+	}
+	for _, v := range violations {
+		metrics.PolicyViolations.WithLabelValues(v.check).Inc()
+	}
+}
+
+// policyViolations checks the title, source branch and target branch of pr. Branches the webhook
+// did not carry are not checked. Patterns are validated at startup; an invalid one is skipped.
+func policyViolations(cfg config.PRPolicyConfig, pr *domain.PullRequest) []policyViolation {
+	var violations []policyViolation
+	if re, err := regexp.Compile(cfg.TitlePattern); err == nil && cfg.TitlePattern != "" && !re.MatchString(pr.Title) {
+		violations = append(violations, policyViolation{"title", fmt.Sprintf("The title `%s` does not match the required format `%s`.", pr.Title, cfg.TitlePattern)})
+	}
+	if re, err := regexp.Compile(cfg.BranchPattern); err == nil && cfg.BranchPattern != "" && pr.SourceBranch != "" && !re.MatchString(pr.SourceBranch) {
+		violations = append(violations, policyViolation{"branch", fmt.Sprintf("The branch `%s` does not match the naming convention `%s`.", pr.SourceBranch, cfg.BranchPattern)})
+	}
+	if len(cfg.TargetBranches) > 0 && pr.TargetBranch != "" && !slices.ContainsFunc(cfg.TargetBranches, func(glob string) bool {
+		ok, _ := path.Match(glob, pr.TargetBranch)
+		return ok
+	}) {
+		violations = append(violations, policyViolation{"target_branch", fmt.Sprintf("PRs may not target `%s`; allowed target branches: `%s`.", pr.TargetBranch, strings.Join(cfg.TargetBranches, "`, `"))})
+	}
+	return violations
+}
+
+// policyWarning is the comment listing the violations
+func policyWarning(violations []policyViolation) string {
+	var sb strings.Builder
+	sb.WriteString("⚠️ **WARNING**: this pull request does not follow the team's conventions.")
+	for _, v := range violations {
+		sb.WriteString("\n- " + v.message)
+	}
+	sb.WriteString("\n\nUpdate the pull request to follow them; the review itself is not affected.")
+	return sb.String()
+}
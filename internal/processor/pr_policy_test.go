@@ -0,0 +1,71 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestPolicyViolations(t *testing.T) {
+	cfg := config.PRPolicyConfig{
+		Enabled:        true,
+		TitlePattern:   `^[A-Z][A-Z0-9]+-\d+ `,
+		BranchPattern:  `^(feature|bugfix)/`,
+		TargetBranches: []string{"main", "release/*"},
+	}
+	tests := []struct {
+		name string
+		pr   domain.PullRequest
+		want []string
+	}{
+		{"compliant", domain.PullRequest{Title: "PAY-12 Add refunds", SourceBranch: "feature/refunds", TargetBranch: "release/2.1"}, nil},
+		{"missing jira key", domain.PullRequest{Title: "Add refunds", SourceBranch: "feature/refunds", TargetBranch: "main"}, []string{"title"}},
+		{"all broken", domain.PullRequest{Title: "wip", SourceBranch: "refunds", TargetBranch: "develop"}, []string{"title", "branch", "target_branch"}},
+		{"branches unknown", domain.PullRequest{Title: "PAY-12 Add refunds"}, nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var got []string
+			for _, v := range policyViolations(cfg, &tt.pr) {
+				got = append(got, v.check)
+			}
+			if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("violations = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPRProcessor_CheckPolicy_WarnsOnce(t *testing.T) {
+	commenter := &commandCommenter{}
+	cfg := &config.Config{}
+	cfg.Pipeline.PRPolicy = config.PRPolicyConfig{Enabled: true, TitlePattern: `^[A-Z]+-\d+`, Repos: []string{"PAY/*"}}
+	p := NewPRProcessor(cfg, &MockReviewer{}, commenter, nil)
+	pr := &domain.PullRequest{ID: "7", ProjectKey: "PAY", RepoSlug: "api", Title: "Add refunds"}
+
+	p.checkPolicy(context.Background(), pr)
+	if len(commenter.posted) != 1 {
+		t.Fatalf("posted %d comments, want 1 warning", len(commenter.posted))
+	}
+	text := commenter.posted[0]["commentText"].(string)
+	if !strings.Contains(text, "**WARNING**") || !strings.Contains(text, "The title `Add refunds` does not match") {
+		t.Errorf("warning = %q", text)
+	}
+
+	// The next push finds the warning
+	commenter.comments = fmt.Sprintf(`{"values":[{"text":%q}]}`, text)
+	p.checkPolicy(context.Background(), pr)
+	if len(commenter.posted) != 1 {
+		t.Errorf("posted %d comments, want the warning once", len(commenter.posted))
+	}
+
+	// Other repositories are not checked
+	p.checkPolicy(context.Background(), &domain.PullRequest{ID: "8", ProjectKey: "OPS", RepoSlug: "infra", Title: "tweak"})
+	if len(commenter.posted) != 1 {
+		t.Errorf("posted %d comments, want no warning outside pr_policy.repos", len(commenter.posted))
+	}
+}
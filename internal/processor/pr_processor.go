@@ -3,16 +3,22 @@ package processor
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 
 	// "pr-review-automation/internal/agent" // Removed agent dependency for types
+	"pr-review-automation/internal/budget"
 	"pr-review-automation/internal/config"
 	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/fault"
+	"pr-review-automation/internal/llm"
 	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/progress"
 	"pr-review-automation/internal/storage"
 	"pr-review-automation/internal/validator"
 	"strconv"
+	"text/template"
 	"time"
 
 	"github.com/tidwall/gjson"
@@ -33,54 +39,193 @@ type Commenter interface {
 	CallTool(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error)
 }
 
+// EventPublisher receives review lifecycle events (see event.Bus)
+type EventPublisher interface {
+	Publish(evt domain.ReviewCompletedEvent)
+}
+
 // PRProcessor handles processing of pull requests
 type PRProcessor struct {
-	cfg       *config.Config
-	reviewer  Reviewer
-	commenter Commenter
-	storage   storage.Repository
+	cfg        *config.Config
+	reviewer   Reviewer
+	commenter  Commenter
+	storage    storage.Repository
+	hooks      hooks
+	events     EventPublisher
+	hold       *postHold       // Optional: holds non-critical findings outside working hours
+	vision     llm.Client      // Optional: sanity comments on added images
+	patches    PatchRegistry   // Optional: diffs reviewed without a code host
+	commandLLM llm.Client      // Optional: answers /ai explain
+	analyzer   Analyzer        // Optional: static analyzers (pipeline.linters)
+	describer  llm.Client      // Optional: writes missing PR descriptions (pipeline.description)
+	budget     *budget.Manager // Optional: skips reviews once a spend limit is reached (budgets)
+
+	summaryTemplate *template.Template // Two-view summary layouts
 }
 
 // NewPRProcessor creates a new PR processor with dependencies injected
 func NewPRProcessor(cfg *config.Config, reviewer Reviewer, commenter Commenter, storage storage.Repository) *PRProcessor {
-	return &PRProcessor{
+	p := &PRProcessor{
 		cfg:       cfg,
 		reviewer:  reviewer,
 		commenter: commenter,
 		storage:   storage,
 	}
+	tmpl, err := loadSummaryTemplate(cfg.Pipeline.Summary.Template)
+	if err != nil {
+		slog.Warn("load summary template failed, using built-in template", "error", err)
+		tmpl, _ = loadSummaryTemplate("")
+	}
+	p.summaryTemplate = tmpl
+
+	if cfg.Pipeline.WorkingHours.Enabled {
+		// Validated at startup; an invalid schedule disables holding instead of blocking reviews
+		if schedule, err := cfg.Pipeline.WorkingHours.Schedule(); err != nil {
+			slog.Warn("invalid working hours, posting immediately", "error", err)
+		} else {
+			p.hold = newPostHold(schedule)
+		}
+	}
+	return p
+}
+
+// SetEventPublisher sets the publisher notified when a review completes
+func (p *PRProcessor) SetEventPublisher(pub EventPublisher) {
+	p.events = pub
 }
 
 // ProcessPullRequest processes a pull request
-func (p *PRProcessor) ProcessPullRequest(ctx context.Context, pr *domain.PullRequest) error {
+func (p *PRProcessor) ProcessPullRequest(ctx context.Context, pr *domain.PullRequest) (err error) {
 	start := time.Now()
+	// SCM tool calls for this PR go to its provider; LLM usage counts against its project
+	ctx = domain.WithProvider(ctx, pr.Provider)
+	ctx = domain.WithProject(ctx, pr.ProjectKey)
+	ctx = fault.WithFaults(ctx, pr.Faults)
 	slog.Debug("process pr", "id", pr.ID, "repo", pr.RepoSlug, "title", pr.Title)
 	slog.Info("processing pr", "id", pr.ID)
 
-	metrics.PullRequestTotal.WithLabelValues("started").Inc()
+	p.countPR(pr, "started")
+
+	// API-triggered reviews (Overrides set) only carry the PR id
+	if pr.Overrides != nil && pr.LatestCommit == "" {
+		p.resolvePullRequest(ctx, pr)
+	}
+
+	// The build status is finished on every path out, including skips
+	var review *domain.ReviewResult
+	var findings []domain.ReviewComment
+	if p.buildStatusEnabled(pr) {
+		p.setBuildStatus(ctx, pr, config.BuildStateInProgress, "Review in progress", pr.WebURL)
+		defer func() { p.finishBuildStatus(ctx, pr, review, findings, err) }()
+	}
+
+	// "/ai ignore" pauses automatic reviews; requested ones still run
+	if p.cfg.Commands.Enabled && pr.Overrides == nil && p.reviewsPaused(ctx, pr) {
+		p.countPR(pr, "skipped")
+		p.RecordSkip(ctx, pr, domain.SkipReasonIgnored, "paused by a comment command")
+		return nil
+	}
+
+	// Opt-outs only apply to automatic reviews, like the pause
+	if detail := p.optOut(pr); detail != "" && pr.Overrides == nil {
+		p.countPR(pr, "skipped")
+		p.RecordSkip(ctx, pr, domain.SkipReasonOptOut, detail)
+		return nil
+	}
+	if p.draftPolicy(pr) == config.DraftPolicySkip {
+		p.countPR(pr, "skipped")
+		p.RecordSkip(ctx, pr, domain.SkipReasonDraft, "review.drafts: skip")
+		return nil
+	}
+
+	// The repository's own settings file; requested reviews run even when it disables reviews
+	p.loadRepoConfig(ctx, pr)
+	if pr.Overrides == nil && p.repoConfigDisables(pr) {
+		p.countPR(pr, "skipped")
+		p.RecordSkip(ctx, pr, domain.SkipReasonRepoConfig, p.cfg.RepoConfig.Path)
+		return nil
+	}
+
+	// Budgets are hard caps, for requested reviews too
+	if exceeded := p.overBudget(pr); exceeded != nil {
+		p.skipOverBudget(ctx, pr, exceeded)
+		return nil
+	}
+
+	// Title and branch conventions are checked without the model, whatever the review finds
+	p.checkPolicy(ctx, pr)
 
 	// 1. Fetch Existing AI Comments (Bitbucket Native Dedup)
+	progress.Stage(ctx, progress.StageComments)
 	existingComments := p.fetchExistingAIComments(ctx, pr)
 
 	// 2. Build Review Request
 	req := &domain.ReviewRequest{
 		PR:                 pr,
 		HistoricalComments: existingComments,
+		Cache:              p.newReviewContext(pr),
 	}
 
-	// 3. Review PR
-	review, err := p.reviewer.ReviewPR(ctx, req)
-	if err != nil {
-		metrics.PullRequestTotal.WithLabelValues("failed").Inc()
-		return fmt.Errorf("review pr: %w", err)
+	if err := p.hooks.runBeforeReview(ctx, req); err != nil {
+		return p.handleHookError(ctx, pr, nil, start, err)
+	}
+
+	// Duplicate detection, the size gate and the secret scan need the diff before the review;
+	// the reviewer and the validation reuse it from the review context
+	var diff string
+	var duplicates []domain.DuplicateMatch
+	if p.cfg.Pipeline.DuplicateDetection.Enabled || p.cfg.Pipeline.SizeGate.Enabled || p.cfg.Pipeline.SecretScan.Enabled || p.analyzer != nil {
+		diff = sharedDiff(ctx, req.Cache)
+	}
+	// Oversized PRs get an explanation instead of a truncated review; requested reviews still run
+	if p.cfg.Pipeline.SizeGate.Enabled && pr.Overrides == nil && diff != "" {
+		if size := measureDiff(pr, diff); oversized(p.cfg.Pipeline.SizeGate, size) {
+			p.skipOversized(ctx, pr, size)
+			return nil
+		}
 	}
+	if p.cfg.Pipeline.DuplicateDetection.Enabled {
+		duplicates = p.detectDuplicates(ctx, pr, diff)
+	}
+
+	secrets := p.scanSecrets(pr, diff)
+	lintFindings := p.startAnalysis(ctx, pr, diff)
+
+	// 3. Review PR; with secrets in the diff, an external model may not see it
+	if p.withholdDiff(pr, secrets) {
+		review = secretsOnlyReview(pr, secrets)
+	} else if review, err = p.reviewer.ReviewPR(ctx, req); err != nil {
+		err = fmt.Errorf("review pr: %w", err)
+		p.publishCompleted(ctx, pr, nil, start, err)
+		return err
+	}
+
+	cost := p.reviewCost(pr, review)
+	review.RawComments = append([]domain.ReviewComment(nil), review.Comments...)
+	review.Duplicates = duplicates
+	addLinterFindings(review, lintFindings())
 
-	// 4. Fetch Diff for Validation
-	diff := p.fetchDiff(ctx, pr)
+	if err := p.hooks.runAfterReview(ctx, pr, review); err != nil {
+		return p.handleHookError(ctx, pr, review, start, err)
+	}
+	p.applySeverityCaps(pr, review)
+	p.muteCategories(pr, review)
+	p.applyConfidence(pr, review)
+	applyRepoConfig(pr, review)
+	// Secrets stay CRITICAL whatever the model, caps and repository settings say
+	addSecretFindings(review, secrets)
+
+	// 4. Diff for Validation, as fetched for the review
+	progress.Stage(ctx, progress.StageValidate)
+	if diff == "" {
+		diff = sharedDiff(ctx, req.Cache)
+	}
 	commentValidator := validator.NewCommentValidator(diff)
+	review.Assets = p.reviewAssets(ctx, pr, diff)
 
 	// 5. Validate and Filter Comments
 	validComments, invalidComments := p.validateComments(review.Comments, commentValidator)
+	findings = validComments
 
 	// 6. Semantic Deduplication
 	newComments := p.filterDuplicates(validComments, existingComments)
@@ -91,52 +236,220 @@ func (p *PRProcessor) ProcessPullRequest(ctx context.Context, pr *domain.PullReq
 		"filtered_count", len(newComments),
 		"existing_count", len(existingComments))
 	review.Comments = newComments
+	review.SummaryOnly = p.summaryOnly(pr)
+	progress.Emit(ctx, progress.Event{Type: progress.EventSummary, Findings: len(newComments), Summary: review.Summary})
 
 	// Persist review result (Audit Only)
+	var reviewID string
+	if p.storage != nil {
+		reviewID = fmt.Sprintf("%s-%s-%s-%d", pr.ProjectKey, pr.RepoSlug, pr.ID, time.Now().UnixNano())
+	}
+	review.Provenance = p.provenance(review, reviewID)
 	if p.storage != nil {
 		// Save synchronously to ensure data safety on exit
 		saveCtx, cancel := context.WithTimeout(context.Background(), p.cfg.Storage.Timeout)
 		defer cancel()
 		record := &storage.ReviewRecord{
-			ID:          fmt.Sprintf("%s-%s-%s-%d", pr.ProjectKey, pr.RepoSlug, pr.ID, time.Now().UnixNano()),
+			ID:          reviewID,
 			PullRequest: pr,
 			Result:      review,
 			CreatedAt:   time.Now(),
 			DurationMs:  time.Since(start).Milliseconds(),
 			Status:      "success",
+			Cost:        cost,
 		}
 		if err := p.storage.SaveReview(saveCtx, record); err != nil {
 			slog.Warn("audit save failed", "error", err)
 		}
 	}
 
-	slog.Info("posting comments", "count", len(review.Comments))
+	if p.dryRun(pr) {
+		for _, c := range review.Comments {
+			slog.Info("dry run, comment not posted", "pr_id", pr.ID, "repo", pr.RepoSlug,
+				"file", c.File, "line", c.Line, "severity", c.Severity, "comment", c.Comment)
+		}
+		p.RecordSkip(ctx, pr, domain.SkipReasonDryRun, fmt.Sprintf("%d comments not posted", len(review.Comments)))
+		p.publishCompleted(ctx, pr, review, start, nil)
+		return nil
+	}
 
-	return p.postComments(ctx, pr, review, existingComments, commentValidator)
+	if err := p.hooks.runBeforePost(ctx, pr, review); err != nil {
+		return p.handleHookError(ctx, pr, review, start, err)
+	}
+	progress.Stage(ctx, progress.StagePost)
+	if diff != "" {
+		// Without the diff every file would look reverted
+		p.resolveOutdated(ctx, pr, review, validComments, commentValidator)
+	}
+
+	switch {
+	case review.SummaryOnly:
+		err = p.postSummaryOnly(ctx, pr, review, existingComments)
+	case p.hold != nil && p.hold.active():
+		err = p.holdNonCritical(ctx, pr, review, commentValidator)
+	default:
+		slog.Info("posting comments", "count", len(review.Comments))
+		err = p.postComments(ctx, pr, review, existingComments, commentValidator)
+	}
+	if err == nil {
+		p.setReviewerStatus(ctx, pr, review, validComments)
+		p.describe(ctx, pr, diff)
+	}
+	p.publishCompleted(ctx, pr, review, start, err)
+	return err
+}
+
+// publishCompleted counts a review that ran to the end, or failed on the way, and emits a
+// ReviewCompletedEvent if a publisher is configured
+func (p *PRProcessor) publishCompleted(ctx context.Context, pr *domain.PullRequest, review *domain.ReviewResult, start time.Time, err error) {
+	status, result := "success", "success"
+	// Posting logs and skips the comments that fail, so a timeout there may not reach err
+	timedOut := errors.Is(context.Cause(ctx), domain.ErrReviewTimeout)
+	if timedOut && err == nil {
+		err = domain.ErrReviewTimeout
+	}
+	switch {
+	case timedOut:
+		status, result = domain.ReviewStatusTimeout, domain.ReviewStatusTimeout
+	case err != nil:
+		status, result = "failed", "error"
+	}
+	p.countPR(pr, status)
+	project, repo := p.repoLabels(pr)
+	metrics.ProcessingDuration.WithLabelValues(result, project, repo).Observe(time.Since(start).Seconds())
+	if timedOut {
+		metrics.ReviewTimeouts.WithLabelValues(project, repo).Inc()
+		p.saveTimedOut(pr, review, start, err)
+	}
+
+	if p.events == nil {
+		return
+	}
+	evt := domain.ReviewCompletedEvent{
+		PR:        pr,
+		Result:    review,
+		Status:    domain.ReviewStatusSuccess,
+		StartedAt: start,
+		Duration:  time.Since(start),
+	}
+	if err != nil {
+		evt.Status = domain.ReviewStatusFailed
+		if timedOut {
+			evt.Status = domain.ReviewStatusTimeout
+		}
+		evt.Error = err.Error()
+	}
+	p.events.Publish(evt)
+}
+
+// saveTimedOut records a review stopped by its timeout. A review stored before the timeout,
+// such as one stopped while posting, gets the status timeout; otherwise a record with the
+// result the review had reached, if any, is stored.
+func (p *PRProcessor) saveTimedOut(pr *domain.PullRequest, review *domain.ReviewResult, start time.Time, err error) {
+	if p.storage == nil {
+		return
+	}
+	saveCtx, cancel := context.WithTimeout(context.Background(), p.cfg.Storage.Timeout)
+	defer cancel()
+	durationMs := time.Since(start).Milliseconds()
+
+	if review != nil && review.Provenance != nil && review.Provenance.ReviewID != "" {
+		store, ok := p.storage.(storage.ReviewStatusRepository)
+		if !ok {
+			return
+		}
+		if err := store.SetReviewStatus(saveCtx, review.Provenance.ReviewID, domain.ReviewStatusTimeout, durationMs); err != nil {
+			slog.Warn("audit save failed", "error", err)
+		}
+		return
+	}
+
+	if review == nil {
+		review = &domain.ReviewResult{Summary: err.Error()}
+	}
+	record := &storage.ReviewRecord{
+		ID:          fmt.Sprintf("%s-%s-%s-%d", pr.ProjectKey, pr.RepoSlug, pr.ID, time.Now().UnixNano()),
+		PullRequest: pr,
+		Result:      review,
+		CreatedAt:   time.Now(),
+		DurationMs:  durationMs,
+		Status:      domain.ReviewStatusTimeout,
+	}
+	if err := p.storage.SaveReview(saveCtx, record); err != nil {
+		slog.Warn("audit save failed", "error", err)
+	}
+}
+
+// handleHookError converts a hook failure into the processing result.
+// ErrSkip stops processing quietly; any other error fails the PR like a failed review, with
+// the result the review had reached, if any.
+func (p *PRProcessor) handleHookError(ctx context.Context, pr *domain.PullRequest, review *domain.ReviewResult, start time.Time, err error) error {
+	if errors.Is(err, ErrSkip) {
+		slog.Info("processing stopped by hook", "id", pr.ID, "reason", err)
+		p.countPR(pr, "skipped")
+		reason, detail := domain.SkipReasonHook, ""
+		var se *SkipError
+		if errors.As(err, &se) {
+			reason, detail = se.Reason, se.Detail
+		}
+		p.RecordSkip(ctx, pr, reason, detail)
+		return nil
+	}
+	p.publishCompleted(ctx, pr, review, start, err)
+	return err
 }
 
 // fetchDiff retrieves the PR diff from Bitbucket for comment validation
 func (p *PRProcessor) fetchDiff(ctx context.Context, pr *domain.PullRequest) string {
+	return fetchPRDiff(ctx, p.commenter, pr)
+}
+
+// fetchPRDiff retrieves the PR diff with the get-diff tool of tools; failures return ""
+func fetchPRDiff(ctx context.Context, tools Commenter, pr *domain.PullRequest) string {
+	diff, err := loadPRDiff(ctx, tools, pr)
+	if err != nil {
+		slog.Warn("fetch diff failed", "error", err)
+	}
+	return diff
+}
+
+// newReviewContext creates the review context that fetches the PR diff once per review
+func (p *PRProcessor) newReviewContext(pr *domain.PullRequest) *domain.ReviewContext {
+	return domain.NewReviewContext(func(ctx context.Context) (string, error) {
+		return loadPRDiff(ctx, p.commenter, pr)
+	})
+}
+
+// sharedDiff returns the diff of the review context; failures return ""
+func sharedDiff(ctx context.Context, rc *domain.ReviewContext) string {
+	diff, err := rc.Diff(ctx)
+	if err != nil {
+		slog.Warn("fetch diff failed", "error", err)
+	}
+	return diff
+}
+
+// loadPRDiff retrieves the PR diff with the get-diff tool of tools
+func loadPRDiff(ctx context.Context, tools Commenter, pr *domain.PullRequest) (string, error) {
 	prID, _ := strconv.Atoi(pr.ID)
-	result, err := p.commenter.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetDiff, map[string]interface{}{
+	result, err := tools.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetDiff, map[string]interface{}{
 		"projectKey":    pr.ProjectKey,
 		"repoSlug":      pr.RepoSlug,
 		"pullRequestId": prID,
 	})
 	if err != nil {
-		slog.Warn("fetch diff failed", "error", err)
-		return ""
+		return "", err
 	}
 
 	// Handle different result types
 	if s, ok := result.(string); ok {
-		return s
+		return s, nil
 	}
 
 	// Try to extract from MCP content structure
 	jsonBytes, err := json.Marshal(result)
 	if err != nil {
-		return ""
+		return "", err
 	}
 	res := gjson.GetBytes(jsonBytes, "content.0.text").String()
 	if res == "" {
@@ -149,8 +462,11 @@ func (p *PRProcessor) fetchDiff(ctx context.Context, pr *domain.PullRequest) str
 	if len(res) > 0 && res[0] == '{' {
 		diffField := gjson.Get(res, "diff")
 		if diffField.Exists() {
-			return diffField.String()
+			res = diffField.String()
 		}
 	}
-	return res
+	if res == "" {
+		return "", errors.New("empty diff")
+	}
+	return res, nil
 }
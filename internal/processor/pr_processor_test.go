@@ -3,10 +3,15 @@ package processor
 import (
 	"context"
 	"errors"
+	"path/filepath"
+	"strconv"
 	"testing"
+	"time"
 
 	"pr-review-automation/internal/config"
 	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/storage"
+	"pr-review-automation/internal/types"
 	"strings"
 )
 
@@ -190,3 +195,248 @@ func TestPRProcessor_ProcessPullRequest_SummaryHeaderCleaning(t *testing.T) {
 		t.Errorf("Summary should contain plain text. Got: %s", postedSummary)
 	}
 }
+
+func TestPRProcessor_SerialCommentPosting_Order(t *testing.T) {
+	mockReviewer := &MockReviewer{
+		ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+			return &domain.ReviewResult{
+				Comments: []domain.ReviewComment{
+					{File: "b.go", Line: 2, Comment: "B2"},
+					{File: "a.go", Line: 3, Comment: "A3"},
+					{File: "a.go", Line: 1, Comment: "A1"},
+				},
+			}, nil
+		},
+	}
+
+	var posted []string
+	mockCommenter := &MockCommenter{
+		CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+			switch toolName {
+			case config.ToolBitbucketGetComments:
+				return `{"values":[]}`, nil
+			case config.ToolBitbucketGetDiff:
+				return "diff --git a/a.go b/a.go\n--- a/a.go\n+++ b/a.go\n@@ -0,0 +1,3 @@\n+1\n+2\n+3\n" +
+					"diff --git a/b.go b/b.go\n--- a/b.go\n+++ b/b.go\n@@ -0,0 +1,2 @@\n+1\n+2\n", nil
+			case config.ToolBitbucketAddComment:
+				text, _ := args["commentText"].(string)
+				lines := strings.Split(text, "\n")
+				posted = append(posted, lines[len(lines)-1])
+			}
+			return nil, nil
+		},
+	}
+
+	cfg := &config.Config{}
+	cfg.Pipeline.SerialCommentPosting = true
+	p := NewPRProcessor(cfg, mockReviewer, mockCommenter, nil)
+
+	if err := p.ProcessPullRequest(context.Background(), &domain.PullRequest{ID: "1", ProjectKey: "P", RepoSlug: "r"}); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	expected := []string{"A1", "A3", "B2"}
+	if strings.Join(posted, ",") != strings.Join(expected, ",") {
+		t.Errorf("expected posting order %v, got %v", expected, posted)
+	}
+}
+
+// batchCommenter advertises the batch comment tool via RawSchemaProvider
+type batchCommenter struct {
+	MockCommenter
+}
+
+func (b *batchCommenter) GetRawToolSchemas() map[string][]types.RawToolSchema {
+	return map[string][]types.RawToolSchema{
+		config.MCPServerBitbucket: {{Name: config.ToolBitbucketAddComments}},
+	}
+}
+
+func TestPRProcessor_BatchCommentPosting(t *testing.T) {
+	comments := []domain.ReviewComment{
+		{File: "a.go", Line: 1, Comment: "A1"},
+		{File: "a.go", Line: 2, Comment: "A2"},
+	}
+
+	tests := []struct {
+		name            string
+		batchErr        error
+		applied         int // Batch items on the PR despite the error
+		wantBatchCalls  int
+		wantSingleCalls int
+	}{
+		{name: "batch succeeds", wantBatchCalls: 1, wantSingleCalls: 0},
+		{name: "batch fails falls back", batchErr: errors.New("boom"), wantBatchCalls: 1, wantSingleCalls: 2},
+		{name: "partly applied batch posts the rest", batchErr: errors.New("timeout"), applied: 1, wantBatchCalls: 1, wantSingleCalls: 1},
+		{name: "applied batch posts nothing", batchErr: errors.New("timeout"), applied: 2, wantBatchCalls: 1, wantSingleCalls: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var batchCalls, singleCalls int
+			var batchItems int
+			var onPR []map[string]any
+			c := &batchCommenter{MockCommenter{
+				CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+					switch toolName {
+					case config.ToolBitbucketGetComments:
+						return map[string]any{"values": onPR}, nil
+					case config.ToolBitbucketAddComments:
+						batchCalls++
+						if items, ok := args["comments"].([]map[string]interface{}); ok {
+							batchItems = len(items)
+							for _, item := range items[:tt.applied] {
+								line, _ := strconv.Atoi(item["lineNumber"].(string))
+								onPR = append(onPR, map[string]any{
+									"text":   item["commentText"],
+									"anchor": map[string]any{"path": item["filePath"], "line": line},
+								})
+							}
+						}
+						return nil, tt.batchErr
+					case config.ToolBitbucketAddComment:
+						singleCalls++
+					}
+					return nil, nil
+				},
+			}}
+
+			cfg := &config.Config{}
+			cfg.Pipeline.SerialCommentPosting = true
+			p := NewPRProcessor(cfg, nil, c, nil)
+
+			pr := &domain.PullRequest{ID: "1", ProjectKey: "P", RepoSlug: "r"}
+			if err := p.postIndividualComments(context.Background(), pr, comments, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if batchCalls != tt.wantBatchCalls {
+				t.Errorf("expected %d batch calls, got %d", tt.wantBatchCalls, batchCalls)
+			}
+			if batchItems != len(comments) {
+				t.Errorf("expected %d batch items, got %d", len(comments), batchItems)
+			}
+			if singleCalls != tt.wantSingleCalls {
+				t.Errorf("expected %d single calls, got %d", tt.wantSingleCalls, singleCalls)
+			}
+		})
+	}
+}
+
+func TestPRProcessor_APITriggeredDryRun(t *testing.T) {
+	var reviewed *domain.PullRequest
+	reviewer := &MockReviewer{
+		ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+			reviewed = req.PR
+			return &domain.ReviewResult{Comments: []domain.ReviewComment{{File: "main.go", Line: 1, Comment: "Fix this"}}, Summary: "One issue"}, nil
+		},
+	}
+
+	var posted []string
+	commenter := &MockCommenter{
+		CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+			switch toolName {
+			case config.ToolBitbucketGetPullRequest:
+				return map[string]any{
+					"title":   "Add retries",
+					"fromRef": map[string]any{"latestCommit": "abc123"},
+					"author":  map[string]any{"user": map[string]any{"displayName": "Jane"}},
+				}, nil
+			case config.ToolBitbucketGetComments:
+				return `{"values": []}`, nil
+			case config.ToolBitbucketGetDiff:
+				return "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -0,0 +1,1 @@\n+x\n", nil
+			default:
+				posted = append(posted, toolName)
+				return nil, nil
+			}
+		},
+	}
+
+	cfg := &config.Config{}
+	cfg.Pipeline.SkipNotes.Enabled = true
+	p := NewPRProcessor(cfg, reviewer, commenter, nil)
+
+	pr := &domain.PullRequest{ID: "7", ProjectKey: "PROJ", RepoSlug: "repo", Overrides: &domain.ReviewOverrides{DryRun: true}}
+	if err := p.ProcessPullRequest(context.Background(), pr); err != nil {
+		t.Fatal(err)
+	}
+	if reviewed == nil || reviewed.Title != "Add retries" || reviewed.LatestCommit != "abc123" || reviewed.Author != "Jane" {
+		t.Errorf("expected PR metadata to be resolved before the review, got %+v", reviewed)
+	}
+	if len(posted) != 0 {
+		t.Errorf("dry run must post nothing, got %v", posted)
+	}
+}
+
+func TestPRProcessor_ConfigDryRun(t *testing.T) {
+	reviewer := &MockReviewer{
+		ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+			return &domain.ReviewResult{Comments: []domain.ReviewComment{{File: "main.go", Line: 1, Comment: "Fix this"}}, Summary: "One issue"}, nil
+		},
+	}
+	var posted []string
+	commenter := &MockCommenter{
+		CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+			switch toolName {
+			case config.ToolBitbucketGetComments:
+				return `{"values": []}`, nil
+			case config.ToolBitbucketGetDiff:
+				return "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -0,0 +1,1 @@\n+x\n", nil
+			default:
+				posted = append(posted, toolName)
+				return nil, nil
+			}
+		},
+	}
+	store, err := storage.NewSQLiteRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	cfg := &config.Config{}
+	cfg.Review.DryRun = true
+	cfg.Pipeline.SkipNotes.Enabled = true
+	cfg.Storage.Timeout = time.Second
+	p := NewPRProcessor(cfg, reviewer, commenter, store)
+
+	pr := &domain.PullRequest{ID: "7", ProjectKey: "PROJ", RepoSlug: "repo", LatestCommit: "abc123"}
+	if err := p.ProcessPullRequest(context.Background(), pr); err != nil {
+		t.Fatal(err)
+	}
+	p.RecordSkip(context.Background(), pr, domain.SkipReasonSizeGate, "too large")
+	if len(posted) != 0 {
+		t.Errorf("dry run must post nothing, got %v", posted)
+	}
+
+	records, err := store.ListReviewsByPR(context.Background(), "PROJ", "repo", "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || len(records[0].Result.Comments) != 1 {
+		t.Fatalf("expected the review with its comment to be stored, got %+v", records)
+	}
+}
+
+func TestInlineCommentText(t *testing.T) {
+	tests := []struct {
+		name    string
+		comment domain.ReviewComment
+		want    string
+	}{
+		{name: "v1", comment: domain.ReviewComment{File: "a.go", Line: 3, Comment: "unchecked error"}, want: "unchecked error"},
+		{
+			name:    "suggestion",
+			comment: domain.ReviewComment{File: "a.go", Line: 3, Comment: "unchecked error", Suggestion: "if err != nil {\n\treturn err\n}\n"},
+			want:    "unchecked error\n\n```suggestion\nif err != nil {\n\treturn err\n}\n```",
+		},
+		{name: "general comment", comment: domain.ReviewComment{Comment: "split this PR", Suggestion: "x"}, want: "split this PR"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := inlineCommentText(tt.comment); got != tt.want {
+				t.Errorf("got %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strconv"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+
+	"github.com/tidwall/gjson"
+)
+
+// Paths of the pull request fields in the get_pull_request responses of the supported
+// providers: Bitbucket Server, GitHub/Gitea, GitLab and Bitbucket Cloud
+var (
+	prTitlePaths       = []string{"title"}
+	prDescriptionPaths = []string{"description", "body"}
+	prCommitPaths      = []string{"fromRef.latestCommit", "head.sha", "sha", "source.commit.hash"}
+	prAuthorPaths      = []string{"author.user.displayName", "user.login", "author.name", "author.display_name"}
+	prWebURLPaths      = []string{"links.self.0.href", "html_url", "web_url", "links.html.href"}
+	prSourcePaths      = []string{"fromRef.displayId", "head.ref", "source_branch", "source.branch.name"}
+	prTargetPaths      = []string{"toRef.displayId", "base.ref", "target_branch", "destination.branch.name"}
+)
+
+// resolvePullRequest fills the metadata of a pull request known only by its id, as for
+// reviews triggered through the API. Fields already set are kept; failures are logged only.
+func (p *PRProcessor) resolvePullRequest(ctx context.Context, pr *domain.PullRequest) {
+	ResolvePullRequest(ctx, p.commenter, pr)
+}
+
+// ResolvePullRequest fills the metadata of pr with the get_pull_request tool of tools
+func ResolvePullRequest(ctx context.Context, tools Commenter, pr *domain.PullRequest) {
+	prID, _ := strconv.Atoi(pr.ID)
+	result, err := tools.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketGetPullRequest, map[string]interface{}{
+		"projectKey":    pr.ProjectKey,
+		"repoSlug":      pr.RepoSlug,
+		"pullRequestId": prID,
+	})
+	if err != nil {
+		slog.Warn("fetch pull request failed", "pr_id", pr.ID, "error", err)
+		return
+	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return
+	}
+	// MCP servers wrap the JSON in a text content item
+	if text := gjson.GetBytes(data, "content.0.text"); text.Exists() {
+		data = []byte(text.String())
+	}
+
+	fill := func(field *string, paths []string) {
+		if *field != "" {
+			return
+		}
+		for _, path := range paths {
+			if v := gjson.GetBytes(data, path); v.Exists() && v.String() != "" {
+				*field = v.String()
+				return
+			}
+		}
+	}
+	fill(&pr.Title, prTitlePaths)
+	fill(&pr.Description, prDescriptionPaths)
+	fill(&pr.LatestCommit, prCommitPaths)
+	fill(&pr.Author, prAuthorPaths)
+	fill(&pr.WebURL, prWebURLPaths)
+	fill(&pr.SourceBranch, prSourcePaths)
+	fill(&pr.TargetBranch, prTargetPaths)
+}
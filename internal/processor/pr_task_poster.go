@@ -0,0 +1,111 @@
+package processor
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"strings"
+
+	"github.com/tidwall/gjson"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/rules"
+	"pr-review-automation/internal/validator"
+)
+
+// maxTaskTextLength caps the task text; the full finding stays in the comment
+const maxTaskTextLength = 200
+
+// tasksEnabled reports whether CRITICAL findings on this PR's repository get blocking tasks
+func (p *PRProcessor) tasksEnabled(pr *domain.PullRequest) bool {
+	tc := p.cfg.Pipeline.Tasks
+	return tc.Enabled && rules.MatchAny(tc.Repos, pr.ProjectKey+"/"+pr.RepoSlug)
+}
+
+// splitTaskComments separates the comments that need a task from the rest
+func (p *PRProcessor) splitTaskComments(pr *domain.PullRequest, comments []domain.ReviewComment) (rest, tasks []domain.ReviewComment) {
+	if !p.tasksEnabled(pr) {
+		return comments, nil
+	}
+	for _, c := range comments {
+		if c.IsCritical() && c.File != "" {
+			tasks = append(tasks, c)
+		} else {
+			rest = append(rest, c)
+		}
+	}
+	return rest, tasks
+}
+
+// postCommentsWithTasks posts each comment individually and attaches a task to it.
+// Tasks need the created comment ID, so these comments never go through the batch tool.
+func (p *PRProcessor) postCommentsWithTasks(ctx context.Context, pr *domain.PullRequest, pullRequestId int, comments []domain.ReviewComment, validator *validator.CommentValidator) {
+	for _, c := range comments {
+		args := p.buildInlineCommentArgs(pr, pullRequestId, c, validator)
+
+		slog.Debug("post comment with task", "file", c.File, "line", int(c.Line))
+		result, err := p.commenter.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketAddComment, args)
+		if err != nil {
+			slog.Error("post comment failed", "file", c.File, "error", err)
+			metrics.CommentPostFailures.WithLabelValues("api_error").Inc()
+			continue
+		}
+
+		commentID := extractCommentID(result)
+		if commentID == 0 {
+			slog.Warn("created comment id not found, task skipped", "file", c.File, "line", int(c.Line))
+			metrics.TasksCreated.WithLabelValues("no_comment_id").Inc()
+			continue
+		}
+
+		_, err = p.commenter.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketAddTask, map[string]interface{}{
+			"projectKey":    pr.ProjectKey,
+			"repoSlug":      pr.RepoSlug,
+			"pullRequestId": pullRequestId,
+			"commentId":     commentID,
+			"text":          taskText(c),
+		})
+		if err != nil {
+			slog.Error("create task failed", "file", c.File, "comment_id", commentID, "error", err)
+			metrics.TasksCreated.WithLabelValues("error").Inc()
+			continue
+		}
+		metrics.TasksCreated.WithLabelValues("success").Inc()
+	}
+}
+
+// taskText returns the first line of the finding, truncated for the task list
+func taskText(c domain.ReviewComment) string {
+	text := strings.TrimSpace(c.Comment)
+	if i := strings.IndexByte(text, '\n'); i != -1 {
+		text = strings.TrimSpace(text[:i])
+	}
+	if r := []rune(text); len(r) > maxTaskTextLength {
+		text = string(r[:maxTaskTextLength]) + "..."
+	}
+	return text
+}
+
+// extractCommentID finds the ID of a created comment in an add-comment tool result.
+// The result is either the comment JSON itself or an MCP result wrapping it as text content.
+func extractCommentID(result any) int64 {
+	var raw string
+	if s, ok := result.(string); ok {
+		raw = s
+	} else {
+		b, err := json.Marshal(result)
+		if err != nil {
+			return 0
+		}
+		raw = string(b)
+	}
+	if id := gjson.Get(raw, "id").Int(); id != 0 {
+		return id
+	}
+	if text := gjson.Get(raw, "content.0.text").String(); text != "" {
+		return gjson.Get(text, "id").Int()
+	}
+	return 0
+}
@@ -0,0 +1,96 @@
+package processor
+
+import (
+	"context"
+	"sync"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestPRProcessor_CriticalFindingTasks(t *testing.T) {
+	comments := []domain.ReviewComment{
+		{File: "a.go", Line: 1, Severity: "CRITICAL", Comment: "SQL injection\nUse bind parameters."},
+		{File: "a.go", Line: 2, Severity: "WARNING", Comment: "Unchecked error"},
+	}
+
+	tests := []struct {
+		name      string
+		repos     []string
+		wantTasks int
+	}{
+		{name: "enabled for all repos", wantTasks: 1},
+		{name: "enabled for matching repo", repos: []string{"P/*"}, wantTasks: 1},
+		{name: "other repo", repos: []string{"OTHER/*"}, wantTasks: 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var mu sync.Mutex
+			var commentCalls int
+			var taskArgs []map[string]interface{}
+			c := &MockCommenter{
+				CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+					mu.Lock()
+					defer mu.Unlock()
+					switch toolName {
+					case config.ToolBitbucketAddComment:
+						commentCalls++
+						return `{"id": 42}`, nil
+					case config.ToolBitbucketAddTask:
+						taskArgs = append(taskArgs, args)
+					}
+					return nil, nil
+				},
+			}
+
+			cfg := &config.Config{}
+			cfg.Pipeline.Tasks.Enabled = true
+			cfg.Pipeline.Tasks.Repos = tt.repos
+			p := NewPRProcessor(cfg, nil, c, nil)
+
+			pr := &domain.PullRequest{ID: "1", ProjectKey: "P", RepoSlug: "r"}
+			if err := p.postIndividualComments(context.Background(), pr, comments, nil); err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if commentCalls != len(comments) {
+				t.Errorf("expected %d comment calls, got %d", len(comments), commentCalls)
+			}
+			if len(taskArgs) != tt.wantTasks {
+				t.Fatalf("expected %d tasks, got %d", tt.wantTasks, len(taskArgs))
+			}
+			if tt.wantTasks > 0 {
+				if taskArgs[0]["commentId"] != int64(42) {
+					t.Errorf("commentId = %v, want 42", taskArgs[0]["commentId"])
+				}
+				if taskArgs[0]["text"] != "SQL injection" {
+					t.Errorf("text = %q, want first line of the finding", taskArgs[0]["text"])
+				}
+			}
+		})
+	}
+}
+
+func TestExtractCommentID(t *testing.T) {
+	tests := []struct {
+		name   string
+		result any
+		want   int64
+	}{
+		{"json string", `{"id": 7, "text": "x"}`, 7},
+		{"map", map[string]interface{}{"id": 8}, 8},
+		{"mcp text content", map[string]interface{}{
+			"content": []map[string]interface{}{{"type": "text", "text": `{"id": 9}`}},
+		}, 9},
+		{"missing", `{"ok": true}`, 0},
+		{"nil", nil, 0},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := extractCommentID(tt.result); got != tt.want {
+				t.Errorf("extractCommentID() = %d, want %d", got, tt.want)
+			}
+		})
+	}
+}
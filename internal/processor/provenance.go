@@ -0,0 +1,76 @@
+package processor
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/version"
+)
+
+// provenance records the build and configuration that produced review; reviewID is the id it
+// is stored under, empty when storage is disabled
+func (p *PRProcessor) provenance(review *domain.ReviewResult, reviewID string) *domain.Provenance {
+	return &domain.Provenance{
+		BotVersion:    version.String(),
+		PromptVersion: promptVersion(p.cfg.Prompts.Dir),
+		Model:         review.Model,
+		Profile:       p.cfg.Profile,
+		ConfigVersion: p.cfg.ConfigVersion,
+		ReviewID:      reviewID,
+	}
+}
+
+// promptVersion returns a short hash of the prompt files under dir. Prompts are reloaded when
+// they change on disk, so it is computed for every review. It is empty when dir is unreadable.
+func promptVersion(dir string) string {
+	h := sha256.New()
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, _ := filepath.Rel(dir, path)
+		h.Write([]byte(filepath.ToSlash(rel) + "\x00"))
+		h.Write(data)
+		return nil
+	})
+	if err != nil {
+		return ""
+	}
+	return hex.EncodeToString(h.Sum(nil)[:4])
+}
+
+// provenanceFooter renders the provenance line of the summary footer
+func (p *PRProcessor) provenanceFooter(prov *domain.Provenance) string {
+	if prov == nil || !p.cfg.Pipeline.Summary.Footer.Provenance {
+		return ""
+	}
+	parts := []string{"bot " + prov.BotVersion}
+	if prov.PromptVersion != "" {
+		parts = append(parts, "prompts "+prov.PromptVersion)
+	}
+	switch {
+	case prov.Profile != "" && prov.ConfigVersion != "":
+		parts = append(parts, "profile "+prov.Profile+" (config "+prov.ConfigVersion+")")
+	case prov.Profile != "":
+		parts = append(parts, "profile "+prov.Profile)
+	case prov.ConfigVersion != "":
+		parts = append(parts, "config "+prov.ConfigVersion)
+	}
+	if id := prov.ReviewID; id != "" {
+		if base := strings.TrimRight(p.cfg.Pipeline.Summary.Footer.ReportURL, "/"); base != "" {
+			parts = append(parts, "[execution report]("+base+"/api/v1/reviews/"+id+")")
+		} else {
+			parts = append(parts, "review `"+id+"`")
+		}
+	}
+	return "<sub>" + strings.Join(parts, " · ") + "</sub>"
+}
@@ -0,0 +1,93 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/storage"
+)
+
+func TestPRProcessor_ProvenanceFooter(t *testing.T) {
+	promptDir := t.TempDir()
+	if err := os.WriteFile(filepath.Join(promptDir, "stage3.md"), []byte("Review {{.RepoSlug}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	store, err := storage.NewSQLiteRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	var summary string
+	commenter := &MockCommenter{
+		CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+			switch toolName {
+			case config.ToolBitbucketGetComments:
+				return `{"values": []}`, nil
+			case config.ToolBitbucketGetDiff:
+				return "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -0,0 +1,1 @@\n+x\n", nil
+			case config.ToolBitbucketAddComment:
+				if _, inline := args["lineNumber"]; !inline {
+					summary = args["commentText"].(string)
+				}
+			}
+			return nil, nil
+		},
+	}
+	reviewer := &MockReviewer{
+		ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+			return &domain.ReviewResult{
+				Comments: []domain.ReviewComment{{File: "main.go", Line: 1, Severity: domain.CommentSeverityWarning, Comment: "Unused"}},
+				Summary:  "Fine",
+				Score:    90,
+				Model:    "gpt-4o",
+			}, nil
+		},
+	}
+
+	cfg := &config.Config{Profile: "prod", ConfigVersion: "5a6b7c8d"}
+	cfg.Prompts.Dir = promptDir
+	cfg.Storage.Timeout = 5 * time.Second
+	cfg.Pipeline.SerialCommentPosting = true
+	cfg.Pipeline.CommentMerge.Enabled = true
+	cfg.Pipeline.Summary.Footer = config.SummaryFooterConfig{Provenance: true, ReportURL: "https://review.example.com/"}
+	p := NewPRProcessor(cfg, reviewer, commenter, store)
+
+	pr := &domain.PullRequest{ID: "5", ProjectKey: "PROJ", RepoSlug: "repo", LatestCommit: "abc"}
+	if err := p.ProcessPullRequest(context.Background(), pr); err != nil {
+		t.Fatal(err)
+	}
+
+	records, err := store.ListReviewsByPR(context.Background(), "PROJ", "repo", "5")
+	if err != nil || len(records) != 1 {
+		t.Fatalf("expected one stored review, got %d: %v", len(records), err)
+	}
+	prov := records[0].Result.Provenance
+	if prov == nil || prov.ReviewID != records[0].ID || prov.PromptVersion != promptVersion(promptDir) || prov.Model != "gpt-4o" {
+		t.Fatalf("unexpected provenance %+v", prov)
+	}
+	for _, want := range []string{
+		"*Automatically generated by gpt-4o*",
+		"prompts " + prov.PromptVersion,
+		"profile prod (config 5a6b7c8d)",
+		"[execution report](https://review.example.com/api/v1/reviews/" + records[0].ID + ")",
+	} {
+		if !strings.Contains(summary, want) {
+			t.Errorf("summary footer missing %q:\n%s", want, summary)
+		}
+	}
+
+	// Editing a prompt changes the prompt version
+	if err := os.WriteFile(filepath.Join(promptDir, "stage3.md"), []byte("Review carefully"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if v := promptVersion(promptDir); v == prov.PromptVersion || v == "" {
+		t.Errorf("prompt version %q did not change", v)
+	}
+}
@@ -0,0 +1,188 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/rules"
+	"pr-review-automation/internal/storage"
+	"pr-review-automation/internal/validator"
+)
+
+// Replay stages, in pipeline order
+const (
+	ReplayStageRules    = "rules"    // Post-processing rules
+	ReplayStageValidate = "validate" // Diff line validation
+	ReplayStageMerge    = "merge"    // Comment merging
+)
+
+var replayStages = []string{ReplayStageRules, ReplayStageValidate, ReplayStageMerge}
+
+// ReplayOptions selects the stages to re-run and the candidate configuration.
+// Deduplication is never replayed: it depends on the PR comments at review time.
+type ReplayOptions struct {
+	Stages       []string                   // Default: all stages
+	RulesFile    string                     // Candidate rules file; empty runs no rules
+	CommentMerge *config.CommentMergeConfig // Candidate merge config; nil keeps the current one
+	Diff         string                     // PR diff for the validate stage; fetched from Bitbucket when empty
+}
+
+// ReplayReport compares the current configuration (baseline) with the candidate
+// on the stored raw reviewer output of one review
+type ReplayReport struct {
+	ReviewID  string                 `json:"reviewId"`
+	Stages    []string               `json:"stages"`
+	Baseline  ReplayOutcome          `json:"baseline"`
+	Candidate ReplayOutcome          `json:"candidate"`
+	Added     []domain.ReviewComment `json:"added"`   // Only in the candidate outcome
+	Removed   []domain.ReviewComment `json:"removed"` // Only in the baseline outcome
+	Changed   []CommentChange        `json:"changed"` // Same location, different severity or text
+	Notes     []string               `json:"notes,omitempty"`
+}
+
+// ReplayOutcome is the result of running the stages with one configuration
+type ReplayOutcome struct {
+	Comments []domain.ReviewComment `json:"comments"`
+	Merge    *MergeStats            `json:"merge,omitempty"`
+}
+
+// MergeStats summarizes how comments would be posted after merging
+type MergeStats struct {
+	FileComments  int `json:"fileComments"`  // Merged per-file comments
+	SummaryAddons int `json:"summaryAddons"` // INFO/NIT appended to the summary
+	Individual    int `json:"individual"`    // Posted as individual inline comments
+}
+
+// CommentChange is a finding whose severity or text differs between outcomes
+type CommentChange struct {
+	Before domain.ReviewComment `json:"before"`
+	After  domain.ReviewComment `json:"after"`
+}
+
+// Replay re-runs the selected stages on a stored review without calling the LLM
+func (p *PRProcessor) Replay(ctx context.Context, record *storage.ReviewRecord, opts ReplayOptions) (*ReplayReport, error) {
+	if record.Result == nil || record.PullRequest == nil {
+		return nil, fmt.Errorf("review %s has no result", record.ID)
+	}
+	if len(record.Result.RawComments) == 0 && len(record.Result.Comments) > 0 {
+		return nil, fmt.Errorf("review %s was stored without raw reviewer output", record.ID)
+	}
+
+	stages := opts.Stages
+	if len(stages) == 0 {
+		stages = replayStages
+	}
+	for _, s := range stages {
+		if !slices.Contains(replayStages, s) {
+			return nil, fmt.Errorf("unknown replay stage %q", s)
+		}
+	}
+	report := &ReplayReport{ReviewID: record.ID, Stages: stages}
+
+	baselineRules, err := loadReplayRules(p.cfg.Pipeline.PostProcessing.RulesFile)
+	if err != nil {
+		return nil, fmt.Errorf("baseline rules: %w", err)
+	}
+	candidateRules, err := loadReplayRules(opts.RulesFile)
+	if err != nil {
+		return nil, fmt.Errorf("candidate rules: %w", err)
+	}
+
+	var v *validator.CommentValidator
+	if slices.Contains(stages, ReplayStageValidate) {
+		diff := opts.Diff
+		if diff == "" && p.commenter != nil {
+			diff = p.fetchDiff(domain.WithProvider(ctx, record.PullRequest.Provider), record.PullRequest)
+			report.Notes = append(report.Notes, "validated against the current PR diff, which may differ from review time")
+		}
+		if diff == "" {
+			report.Notes = append(report.Notes, "validate stage skipped: no diff available")
+		} else {
+			v = validator.NewCommentValidator(diff)
+		}
+	}
+
+	candidateMerge := p.cfg.Pipeline.CommentMerge
+	if opts.CommentMerge != nil {
+		candidateMerge = *opts.CommentMerge
+	}
+
+	pr := record.PullRequest
+	raw := record.Result.RawComments
+	report.Baseline = p.replayOutcome(pr, raw, stages, baselineRules, v, p.cfg.Pipeline.CommentMerge)
+	report.Candidate = p.replayOutcome(pr, raw, stages, candidateRules, v, candidateMerge)
+	report.Added, report.Removed, report.Changed = diffComments(report.Baseline.Comments, report.Candidate.Comments)
+	return report, nil
+}
+
+func (p *PRProcessor) replayOutcome(pr *domain.PullRequest, raw []domain.ReviewComment, stages []string, engine *rules.Engine, v *validator.CommentValidator, mergeCfg config.CommentMergeConfig) ReplayOutcome {
+	comments := append([]domain.ReviewComment(nil), raw...)
+
+	if slices.Contains(stages, ReplayStageRules) {
+		if engine != nil {
+			comments = engine.Apply(pr, comments)
+		}
+		p.capSeverities(pr, comments)
+	}
+	if v != nil {
+		comments, _ = p.validateComments(comments, v)
+	}
+
+	out := ReplayOutcome{Comments: comments}
+	if out.Comments == nil {
+		out.Comments = []domain.ReviewComment{}
+	}
+	if slices.Contains(stages, ReplayStageMerge) {
+		if !mergeCfg.Enabled {
+			out.Merge = &MergeStats{Individual: len(atOrAbove(comments, p.cfg.Review.MinPostSeverity))}
+		} else {
+			merger := NewCommentMerger(&mergeCfg, pr.WebURL)
+			merger.minPost = p.cfg.Review.MinPostSeverity
+			res := merger.Merge(comments, pr.LatestCommit)
+			out.Merge = &MergeStats{FileComments: len(res.FileComments), SummaryAddons: len(res.SummaryAddons), Individual: len(res.NotMerged)}
+		}
+	}
+	return out
+}
+
+func loadReplayRules(path string) (*rules.Engine, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return rules.Load(path)
+}
+
+// diffComments pairs findings by file and line, in order, and reports the differences
+func diffComments(before, after []domain.ReviewComment) (added, removed []domain.ReviewComment, changed []CommentChange) {
+	key := func(c domain.ReviewComment) string {
+		return fmt.Sprintf(config.DedupeKeyFileLineFormat, c.File, int(c.Line))
+	}
+
+	pending := make(map[string][]domain.ReviewComment)
+	for _, c := range before {
+		pending[key(c)] = append(pending[key(c)], c)
+	}
+	for _, c := range after {
+		k := key(c)
+		if len(pending[k]) == 0 {
+			added = append(added, c)
+			continue
+		}
+		b := pending[k][0]
+		pending[k] = pending[k][1:]
+		if b.Severity != c.Severity || b.Comment != c.Comment {
+			changed = append(changed, CommentChange{Before: b, After: c})
+		}
+	}
+	for _, c := range before {
+		k := key(c)
+		if len(pending[k]) > 0 && pending[k][0] == c {
+			removed = append(removed, c)
+			pending[k] = pending[k][1:]
+		}
+	}
+	return added, removed, changed
+}
@@ -0,0 +1,86 @@
+package processor
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/storage"
+)
+
+func TestPRProcessor_Replay(t *testing.T) {
+	rulesFile := filepath.Join(t.TempDir(), "rules.yaml")
+	rulesYAML := `rules:
+  - name: no-nits
+    severities: [NIT]
+    action: drop
+  - name: soften-style
+    message: "(?i)style"
+    action: downgrade
+    severity: INFO
+`
+	if err := os.WriteFile(rulesFile, []byte(rulesYAML), 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	record := &storage.ReviewRecord{
+		ID:          "r1",
+		PullRequest: &domain.PullRequest{ID: "1", ProjectKey: "PROJ", RepoSlug: "repo", LatestCommit: "abc"},
+		Result: &domain.ReviewResult{
+			Comments: []domain.ReviewComment{{File: "a.go", Line: 1, Severity: domain.CommentSeverityCritical, Comment: "nil deref"}},
+			RawComments: []domain.ReviewComment{
+				{File: "a.go", Line: 1, Severity: domain.CommentSeverityCritical, Comment: "nil deref"},
+				{File: "a.go", Line: 5, Severity: domain.CommentSeverityWarning, Comment: "style: long line"},
+				{File: "b.go", Line: 2, Severity: domain.CommentSeverityNit, Comment: "typo"},
+			},
+		},
+	}
+
+	cfg := &config.Config{}
+	cfg.Pipeline.CommentMerge = config.CommentMergeConfig{Enabled: true, HighSeverityMerge: "none", LowSeverityMerge: "to_summary"}
+	p := NewPRProcessor(cfg, &MockReviewer{}, nil, nil)
+
+	report, err := p.Replay(context.Background(), record, ReplayOptions{
+		RulesFile:    rulesFile,
+		CommentMerge: &config.CommentMergeConfig{Enabled: false},
+	})
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+
+	if len(report.Baseline.Comments) != 3 || len(report.Candidate.Comments) != 2 {
+		t.Fatalf("comments: baseline %d, candidate %d", len(report.Baseline.Comments), len(report.Candidate.Comments))
+	}
+	if len(report.Removed) != 1 || report.Removed[0].File != "b.go" {
+		t.Errorf("removed = %+v", report.Removed)
+	}
+	if len(report.Changed) != 1 || report.Changed[0].After.Severity != domain.CommentSeverityInfo {
+		t.Errorf("changed = %+v", report.Changed)
+	}
+	if len(report.Added) != 0 {
+		t.Errorf("added = %+v", report.Added)
+	}
+	if report.Baseline.Merge == nil || report.Baseline.Merge.SummaryAddons != 1 {
+		t.Errorf("baseline merge = %+v", report.Baseline.Merge)
+	}
+	if report.Candidate.Merge == nil || report.Candidate.Merge.Individual != 2 {
+		t.Errorf("candidate merge = %+v", report.Candidate.Merge)
+	}
+	if len(report.Notes) != 1 || !strings.Contains(report.Notes[0], "no diff") {
+		t.Errorf("notes = %v", report.Notes)
+	}
+
+	if _, err := p.Replay(context.Background(), record, ReplayOptions{Stages: []string{"dedupe"}}); err == nil {
+		t.Error("expected error for unknown stage")
+	}
+
+	legacy := *record
+	legacy.Result = &domain.ReviewResult{Comments: record.Result.Comments}
+	if _, err := p.Replay(context.Background(), &legacy, ReplayOptions{}); err == nil {
+		t.Error("expected error for record without raw comments")
+	}
+}
@@ -0,0 +1,93 @@
+package processor
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"log/slog"
+	"path"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/rules"
+)
+
+// loadRepoConfig reads the repository's settings file at the PR's latest commit into
+// pr.RepoConfig. A missing or invalid file leaves the server configuration in effect.
+func (p *PRProcessor) loadRepoConfig(ctx context.Context, pr *domain.PullRequest) {
+	rc := p.cfg.RepoConfig
+	if !rc.Enabled || pr.LatestCommit == "" {
+		return
+	}
+	content, err := p.fetchFileContent(ctx, pr, rc.Path)
+	if err != nil || strings.TrimSpace(content) == "" {
+		slog.Debug("no repository settings file", "repo", pr.ProjectKey+"/"+pr.RepoSlug, "path", rc.Path, "error", err)
+		metrics.RepoConfigLoads.WithLabelValues("missing").Inc()
+		return
+	}
+	settings, err := parseRepoConfig([]byte(content))
+	if err != nil {
+		slog.Warn("invalid repository settings file, ignored", "repo", pr.ProjectKey+"/"+pr.RepoSlug, "path", rc.Path, "error", err)
+		metrics.RepoConfigLoads.WithLabelValues("invalid").Inc()
+		return
+	}
+	slog.Info("repository settings loaded", "repo", pr.ProjectKey+"/"+pr.RepoSlug, "path", rc.Path)
+	metrics.RepoConfigLoads.WithLabelValues("loaded").Inc()
+	pr.RepoConfig = settings
+}
+
+// parseRepoConfig decodes and checks a settings file. Unknown keys are errors, so typos do
+// not silently fall back to the server configuration.
+func parseRepoConfig(data []byte) (*domain.RepoConfig, error) {
+	var rc domain.RepoConfig
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true)
+	if err := dec.Decode(&rc); err != nil {
+		return nil, err
+	}
+	if rc.MinSeverity != "" {
+		rc.MinSeverity = strings.ToUpper(rc.MinSeverity)
+		if !slices.Contains(config.FindingSeverities, rc.MinSeverity) {
+			return nil, fmt.Errorf("invalid min_severity %q", rc.MinSeverity)
+		}
+	}
+	if rc.Prompt != "" && (path.IsAbs(rc.Prompt) || slices.Contains(strings.Split(rc.Prompt, "/"), "..")) {
+		return nil, fmt.Errorf("prompt %q must be a path under prompts.dir", rc.Prompt)
+	}
+	return &rc, nil
+}
+
+// repoConfigDisables reports whether the repository's settings file turns automatic reviews off
+func (p *PRProcessor) repoConfigDisables(pr *domain.PullRequest) bool {
+	rc := pr.RepoConfig
+	return rc != nil && rc.Enabled != nil && !*rc.Enabled && p.cfg.RepoConfig.AllowDisable
+}
+
+// applyRepoConfig drops the findings in files the repository ignores and those below its
+// minimum severity
+func applyRepoConfig(pr *domain.PullRequest, review *domain.ReviewResult) {
+	rc := pr.RepoConfig
+	if rc == nil || (len(rc.Ignore) == 0 && rc.MinSeverity == "") {
+		return
+	}
+	limit := severityRank(rc.MinSeverity)
+	kept := review.Comments[:0]
+	for _, c := range review.Comments {
+		if c.File != "" && len(rc.Ignore) > 0 && rules.MatchAny(rc.Ignore, c.File) {
+			continue
+		}
+		if rank := severityRank(c.Severity); limit >= 0 && rank > limit {
+			continue
+		}
+		kept = append(kept, c)
+	}
+	if dropped := len(review.Comments) - len(kept); dropped > 0 {
+		slog.Info("findings dropped by repository settings", "pr_id", pr.ID, "dropped", dropped)
+	}
+	review.Comments = kept
+}
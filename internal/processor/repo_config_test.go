@@ -0,0 +1,78 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestParseRepoConfig(t *testing.T) {
+	rc, err := parseRepoConfig([]byte("enabled: false\nignore: [\"docs/**\"]\nmin_severity: warning\nprompt: pipeline/stage3_strict.md\n"))
+	if err != nil {
+		t.Fatalf("parseRepoConfig: %v", err)
+	}
+	if rc.Enabled == nil || *rc.Enabled || rc.MinSeverity != "WARNING" || len(rc.Ignore) != 1 || rc.Prompt != "pipeline/stage3_strict.md" {
+		t.Errorf("parseRepoConfig = %+v", rc)
+	}
+
+	for _, bad := range []string{
+		"ignore_files: [\"docs/**\"]\n",
+		"min_severity: urgent\n",
+		"prompt: ../secrets.md\n",
+		"prompt: /etc/passwd\n",
+	} {
+		if _, err := parseRepoConfig([]byte(bad)); err == nil {
+			t.Errorf("parseRepoConfig(%q) accepted", bad)
+		}
+	}
+}
+
+func TestApplyRepoConfig(t *testing.T) {
+	pr := &domain.PullRequest{ID: "1", RepoConfig: &domain.RepoConfig{Ignore: []string{"docs/**"}, MinSeverity: "WARNING"}}
+	review := &domain.ReviewResult{Comments: []domain.ReviewComment{
+		{File: "docs/a.md", Severity: "CRITICAL", Comment: "ignored file"},
+		{File: "main.go", Severity: "INFO", Comment: "below minimum"},
+		{File: "main.go", Severity: "WARNING", Comment: "kept"},
+		{Severity: "CRITICAL", Comment: "general, kept"},
+	}}
+	applyRepoConfig(pr, review)
+	if len(review.Comments) != 2 || review.Comments[0].Comment != "kept" || review.Comments[1].Comment != "general, kept" {
+		t.Errorf("comments = %+v", review.Comments)
+	}
+}
+
+func TestPRProcessor_RepoConfigDisables(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.RepoConfig = config.RepoConfigConfig{Enabled: true, Path: config.DefaultRepoConfigPath, AllowDisable: true}
+
+	commenter := &MockCommenter{CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+		if toolName == config.ToolBitbucketGetFileContent && args["path"] == config.DefaultRepoConfigPath {
+			return "enabled: false\n", nil
+		}
+		return `{"values": []}`, nil
+	}}
+	reviewed := false
+	reviewer := &MockReviewer{ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+		reviewed = true
+		return &domain.ReviewResult{Score: 100}, nil
+	}}
+	p := NewPRProcessor(cfg, reviewer, commenter, nil)
+
+	if err := p.ProcessPullRequest(context.Background(), &domain.PullRequest{ID: "1", ProjectKey: "PAY", RepoSlug: "api", LatestCommit: "abc"}); err != nil {
+		t.Fatalf("ProcessPullRequest: %v", err)
+	}
+	if reviewed {
+		t.Error("review ran although the repository disabled it")
+	}
+
+	// Without allow_disable the server configuration wins
+	cfg.RepoConfig.AllowDisable = false
+	if err := p.ProcessPullRequest(context.Background(), &domain.PullRequest{ID: "2", ProjectKey: "PAY", RepoSlug: "api", LatestCommit: "abc"}); err != nil {
+		t.Fatalf("ProcessPullRequest: %v", err)
+	}
+	if !reviewed {
+		t.Error("review skipped without repo_config.allow_disable")
+	}
+}
@@ -0,0 +1,39 @@
+package processor
+
+import (
+	"context"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestPRProcessor_FetchesDiffOnce(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Pipeline.SizeGate = config.SizeGateConfig{Enabled: true, MaxFiles: 10}
+
+	fetches := 0
+	commenter := &MockCommenter{CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+		if toolName == config.ToolBitbucketGetDiff {
+			fetches++
+			return fileDiff("a.go", 3), nil
+		}
+		return `{"values": []}`, nil
+	}}
+	reviewer := &MockReviewer{ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+		diff, err := req.Cache.Diff(ctx)
+		if err != nil || diff != fileDiff("a.go", 3) {
+			t.Errorf("reviewer diff = %q, %v", diff, err)
+		}
+		return &domain.ReviewResult{Score: 100, Comments: []domain.ReviewComment{{File: "a.go", Line: 1, Comment: "x", Severity: "INFO"}}}, nil
+	}}
+	p := NewPRProcessor(cfg, reviewer, commenter, nil)
+
+	pr := &domain.PullRequest{ID: "1", ProjectKey: "PAY", RepoSlug: "api", LatestCommit: "abc"}
+	if err := p.ProcessPullRequest(context.Background(), pr); err != nil {
+		t.Fatalf("ProcessPullRequest: %v", err)
+	}
+	if fetches != 1 {
+		t.Errorf("diff fetched %d times, want once for size gate, review and validation", fetches)
+	}
+}
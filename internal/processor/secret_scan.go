@@ -0,0 +1,192 @@
+package processor
+
+import (
+	"fmt"
+	"log/slog"
+	"math"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/rules"
+)
+
+// secretRule detects one kind of secret in an added line
+type secretRule struct {
+	name    string // Metric label
+	label   string // Shown in the finding
+	pattern *regexp.Regexp
+	entropy bool // The first group is a value that must look random (pipeline.secret_scan.min_entropy)
+}
+
+// secretRules are the built-in rules, most specific first; a line is reported once
+var secretRules = []secretRule{
+	{name: "private_key", label: "private key", pattern: regexp.MustCompile(`-----BEGIN [A-Z ]*PRIVATE KEY-----`)},
+	{name: "aws_access_key", label: "AWS access key", pattern: regexp.MustCompile(`\b(?:AKIA|ASIA)[0-9A-Z]{16}\b`)},
+	{name: "github_token", label: "GitHub token", pattern: regexp.MustCompile(`\bgh[pousr]_[A-Za-z0-9]{30,}`)},
+	{name: "gitlab_token", label: "GitLab token", pattern: regexp.MustCompile(`\bglpat-[A-Za-z0-9_-]{20,}`)},
+	{name: "slack_token", label: "Slack token", pattern: regexp.MustCompile(`\bxox[abprs]-[A-Za-z0-9-]{10,}`)},
+	{name: "google_api_key", label: "Google API key", pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{name: "api_key", label: "API secret key", pattern: regexp.MustCompile(`\b(?:sk|rk)[-_](?:live_|test_|proj-)?[A-Za-z0-9_-]{20,}`)},
+	{name: "jwt", label: "JSON Web Token", pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]{10,}\.eyJ[A-Za-z0-9_-]{10,}\.[A-Za-z0-9_-]{10,}`)},
+	{name: "url_credentials", label: "password in a URL", pattern: regexp.MustCompile(`\b[a-z][a-z0-9+.-]*://[^/\s:@"']+:([^/\s:@"']{3,})@`)},
+	{name: "credential", label: "hard-coded credential", entropy: true, pattern: regexp.MustCompile(
+		`(?i)(?:password|passwd|pwd|secret|token|api[_-]?key|access[_-]?key|private[_-]?key|credentials?)[\w-]*["']?\s*(?::=|[:=])\s*["']([^"'\s]{8,})["']`)},
+}
+
+// secretPlaceholder matches values that stand for a secret instead of being one
+var secretPlaceholder = regexp.MustCompile(`(?i)\$\{|\{\{|<[^>]*>|%s|^x+$|\*\*\*|changeme|example|dummy|placeholder|your[_-]`)
+
+// diffHunkHeader matches "@@ -a,b +c,d @@" and captures the first new-file line
+var diffHunkHeader = regexp.MustCompile(`^@@ -\d+(?:,\d+)? \+(\d+)(?:,\d+)? @@`)
+
+// scanSecrets reports the secrets in the added lines of diff as CRITICAL findings, one per line.
+// Files matching pipeline.secret_scan.ignore or the repository's ignore globs are skipped.
+func (p *PRProcessor) scanSecrets(pr *domain.PullRequest, diff string) []domain.ReviewComment {
+	cfg := p.cfg.Pipeline.SecretScan
+	if !cfg.Enabled || diff == "" {
+		return nil
+	}
+	ruleSet := slices.Clone(secretRules)
+	for _, expr := range cfg.Patterns {
+		if re, err := regexp.Compile(expr); err == nil {
+			ruleSet = append(ruleSet, secretRule{name: "custom", label: "secret", pattern: re})
+		}
+	}
+	ignore := cfg.Ignore
+	if rc := pr.RepoConfig; rc != nil {
+		ignore = append(slices.Clone(ignore), rc.Ignore...)
+	}
+
+	var findings []domain.ReviewComment
+	var file string
+	line := 0
+	for _, l := range strings.Split(diff, "\n") {
+		switch {
+		case strings.HasPrefix(l, "+++ "):
+			file = strings.TrimPrefix(strings.TrimSpace(strings.TrimPrefix(l, "+++ ")), "b/")
+			if file == "/dev/null" || (len(ignore) > 0 && rules.MatchAny(ignore, file)) {
+				file = ""
+			}
+			line = 0
+		case strings.HasPrefix(l, "@@"):
+			if m := diffHunkHeader.FindStringSubmatch(l); m != nil {
+				line, _ = strconv.Atoi(m[1])
+			}
+		case line == 0 || strings.HasPrefix(l, "-"):
+		case strings.HasPrefix(l, "+"):
+			if file != "" {
+				if rule, ok := matchSecret(ruleSet, l[1:], cfg.MinEntropy); ok {
+					metrics.SecretFindings.WithLabelValues(rule.name).Inc()
+					findings = append(findings, secretFinding(file, line, rule))
+				}
+			}
+			line++
+		default:
+			line++
+		}
+	}
+	if len(findings) > 0 {
+		slog.Warn("secrets found in diff", "pr_id", pr.ID, "repo", pr.RepoSlug, "count", len(findings))
+	}
+	return findings
+}
+
+// matchSecret returns the first rule that finds a secret in text
+func matchSecret(ruleSet []secretRule, text string, minEntropy float64) (secretRule, bool) {
+	for _, r := range ruleSet {
+		for _, m := range r.pattern.FindAllStringSubmatch(text, -1) {
+			value := m[0]
+			if len(m) > 1 {
+				value = m[1]
+				if secretPlaceholder.MatchString(value) {
+					continue
+				}
+			}
+			if r.entropy && shannonEntropy(value) < minEntropy {
+				continue
+			}
+			return r, true
+		}
+	}
+	return secretRule{}, false
+}
+
+// shannonEntropy returns the entropy of s in bits per character
+func shannonEntropy(s string) float64 {
+	if s == "" {
+		return 0
+	}
+	counts := make(map[rune]int)
+	n := 0
+	for _, r := range s {
+		counts[r]++
+		n++
+	}
+	var h float64
+	for _, c := range counts {
+		f := float64(c) / float64(n)
+		h -= f * math.Log2(f)
+	}
+	return h
+}
+
+// secretFinding is the comment posted on a line with a secret. It never quotes the value.
+func secretFinding(file string, line int, rule secretRule) domain.ReviewComment {
+	return domain.ReviewComment{
+		File:     file,
+		Line:     domain.FlexibleLine(line),
+		Severity: domain.CommentSeverityCritical,
+		Comment: fmt.Sprintf("**Possible %s** added in this line (secret scan). Remove it from the change, "+
+			"rotate it since it is now in the repository history, and load it from a secret store or the environment instead.", rule.label),
+	}
+}
+
+// withholdDiff reports whether the review must not send the diff to the model: secrets were
+// found, pipeline.secret_scan.block_external is set and the model of the PR is not self-hosted
+func (p *PRProcessor) withholdDiff(pr *domain.PullRequest, secrets []domain.ReviewComment) bool {
+	return len(secrets) > 0 && p.cfg.Pipeline.SecretScan.BlockExternal && p.externalModel(pr)
+}
+
+// externalModel reports whether a model outside provider local may review pr: its llm.routes
+// entry, or llm.provider and the fallbacks
+func (p *PRProcessor) externalModel(pr *domain.PullRequest) bool {
+	for _, r := range p.cfg.LLM.Routes {
+		if slices.Contains(r.Projects, pr.ProjectKey) || (len(r.Repos) > 0 && rules.MatchAny(r.Repos, pr.ProjectKey+"/"+pr.RepoSlug)) {
+			return r.Provider != config.LLMProviderLocal
+		}
+	}
+	if p.cfg.LLM.Provider != config.LLMProviderLocal {
+		return true
+	}
+	return slices.ContainsFunc(p.cfg.LLM.Fallbacks, func(t config.LLMTarget) bool { return t.Provider != config.LLMProviderLocal })
+}
+
+// secretsOnlyReview is the result of a review whose diff was withheld from the model
+func secretsOnlyReview(pr *domain.PullRequest, secrets []domain.ReviewComment) *domain.ReviewResult {
+	slog.Warn("diff withheld from external model", "pr_id", pr.ID, "repo", pr.RepoSlug, "secrets", len(secrets))
+	metrics.SecretScanBlocks.Inc()
+	return &domain.ReviewResult{
+		Comments: slices.Clone(secrets),
+		Score:    0,
+		Summary: fmt.Sprintf("The secret scan found %d possible secrets in this pull request, so the diff was not sent to the review model. "+
+			"Remove them and rotate the credentials; the review runs again with the next push.", len(secrets)),
+	}
+}
+
+// addSecretFindings adds the secret scan findings the model did not already report as CRITICAL
+// on the same line
+func addSecretFindings(review *domain.ReviewResult, secrets []domain.ReviewComment) {
+	for _, s := range secrets {
+		reported := slices.ContainsFunc(review.Comments, func(c domain.ReviewComment) bool {
+			return c.File == s.File && c.Line == s.Line && c.IsCritical()
+		})
+		if !reported {
+			review.Comments = append(review.Comments, s)
+		}
+	}
+}
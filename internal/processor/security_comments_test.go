@@ -0,0 +1,48 @@
+package processor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestPRProcessor_PostMergedComments_SecurityFindingsSeparate(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Pipeline.CommentMerge = config.CommentMergeConfig{Enabled: true, HighSeverityMerge: "by_file", LowSeverityMerge: "to_summary"}
+
+	var texts []string
+	commenter := &MockCommenter{CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+		if toolName == config.ToolBitbucketAddComment {
+			texts = append(texts, args["commentText"].(string))
+		}
+		return nil, nil
+	}}
+	p := NewPRProcessor(cfg, &MockReviewer{}, commenter, nil)
+	pr := &domain.PullRequest{ID: "7", ProjectKey: "PAY", RepoSlug: "api", LatestCommit: "abc"}
+	review := &domain.ReviewResult{Comments: []domain.ReviewComment{
+		{File: "db.go", Line: 2, Comment: "rename this variable", Severity: "WARNING"},
+		{File: "db.go", Line: 5, Comment: "query built from user input", Severity: "CRITICAL", Category: domain.CommentCategorySecurity},
+	}}
+	if err := p.postMergedComments(context.Background(), pr, review, nil, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	var security, merged string
+	for _, text := range texts {
+		switch {
+		case strings.Contains(text, p.markers().securityMarker()):
+			security = text
+		case strings.Contains(text, "rename this variable"):
+			merged = text
+		}
+	}
+	if !strings.HasPrefix(security, p.markers().inlineMarker("db.go", 5, "abc")) || !strings.Contains(security, "**Security:** query built from user input") {
+		t.Errorf("security comment = %q", security)
+	}
+	if merged == "" || strings.Contains(merged, "user input") {
+		t.Errorf("file comment = %q, want the style finding without the security finding", merged)
+	}
+}
@@ -0,0 +1,115 @@
+package processor
+
+import (
+	"fmt"
+	"log/slog"
+	"slices"
+	"strings"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/rules"
+)
+
+// capSeverities lowers each finding to the highest severity its file allows under
+// post_processing.severity_caps; when several caps match, the strictest wins. It returns the
+// number of severity levels removed across all findings.
+func (p *PRProcessor) capSeverities(pr *domain.PullRequest, comments []domain.ReviewComment) int {
+	caps := p.cfg.Pipeline.PostProcessing.SeverityCaps
+	if len(caps) == 0 {
+		return 0
+	}
+	repo := pr.ProjectKey + "/" + pr.RepoSlug
+
+	levels := 0
+	for i := range comments {
+		c := &comments[i]
+		limit, capName := -1, ""
+		for j, sc := range caps {
+			if !rules.MatchAny(sc.Repos, repo) || !rules.MatchAny(sc.Files, c.File) {
+				continue
+			}
+			if r := severityRank(sc.MaxSeverity); r > limit {
+				limit, capName = r, fmt.Sprintf("severity-cap-%d", j+1)
+			}
+		}
+		if limit < 0 {
+			continue
+		}
+		cur := severityRank(c.Severity)
+		if cur >= limit {
+			continue
+		}
+		slog.Debug("finding severity capped", "file", c.File, "line", int(c.Line), "from", c.Severity, "to", config.FindingSeverities[limit])
+		metrics.PostRuleActions.WithLabelValues(capName, "cap").Inc()
+		levels += limit - max(cur, 0)
+		c.Severity = config.FindingSeverities[limit]
+	}
+	return levels
+}
+
+// muteCategories drops the findings of the categories disabled in post_processing.categories.
+// Findings without a category are kept.
+func (p *PRProcessor) muteCategories(pr *domain.PullRequest, review *domain.ReviewResult) {
+	categories := p.cfg.Pipeline.PostProcessing.Categories
+	if len(categories) == 0 {
+		return
+	}
+	kept := review.Comments[:0]
+	for _, c := range review.Comments {
+		if enabled, ok := categories[c.Category]; ok && !enabled {
+			metrics.PostRuleActions.WithLabelValues("category-"+c.Category, "drop").Inc()
+			continue
+		}
+		kept = append(kept, c)
+	}
+	if muted := len(review.Comments) - len(kept); muted > 0 {
+		slog.Info("findings of muted categories dropped", "pr_id", pr.ID, "muted", muted)
+	}
+	review.Comments = kept
+}
+
+// applyConfidence drops or labels the findings the model is less sure about than
+// post_processing.confidence.min. Findings without a confidence are kept.
+func (p *PRProcessor) applyConfidence(pr *domain.PullRequest, review *domain.ReviewResult) {
+	cfg := p.cfg.Pipeline.PostProcessing.Confidence
+	if cfg.Min <= 0 {
+		return
+	}
+	kept := review.Comments[:0]
+	for _, c := range review.Comments {
+		if c.Confidence <= 0 || float64(c.Confidence) >= cfg.Min {
+			kept = append(kept, c)
+			continue
+		}
+		if cfg.Action == config.ConfidenceActionLabel {
+			metrics.PostRuleActions.WithLabelValues("confidence", "label").Inc()
+			c.Comment = fmt.Sprintf("_Low confidence (%.0f%%), please verify:_ %s", float64(c.Confidence)*100, c.Comment)
+			kept = append(kept, c)
+			continue
+		}
+		metrics.PostRuleActions.WithLabelValues("confidence", "drop").Inc()
+	}
+	if dropped := len(review.Comments) - len(kept); dropped > 0 {
+		slog.Info("low confidence findings dropped", "pr_id", pr.ID, "dropped", dropped, "min", cfg.Min)
+	}
+	review.Comments = kept
+}
+
+// applySeverityCaps caps the findings of review and gives back the score the model deducted
+// for the severity levels removed
+func (p *PRProcessor) applySeverityCaps(pr *domain.PullRequest, review *domain.ReviewResult) {
+	levels := p.capSeverities(pr, review.Comments)
+	if levels == 0 {
+		return
+	}
+	review.Score = min(100, review.Score+levels*config.SeverityCapScoreRefund)
+	slog.Info("severity caps applied", "pr_id", pr.ID, "levels", levels, "score", review.Score)
+}
+
+// severityRank returns the position of s in config.FindingSeverities, highest first; unknown
+// severities rank above CRITICAL
+func severityRank(s string) int {
+	return slices.Index(config.FindingSeverities, strings.ToUpper(s))
+}
@@ -0,0 +1,95 @@
+package processor
+
+import (
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestPRProcessor_ApplySeverityCaps(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Pipeline.PostProcessing.SeverityCaps = []config.SeverityCap{
+		{Files: []string{"**/*_test.go", "examples/**"}, MaxSeverity: "warning"},
+		{Repos: []string{"PAY/*"}, Files: []string{"examples/**"}, MaxSeverity: "NIT"},
+	}
+	p := NewPRProcessor(cfg, &MockReviewer{}, &MockCommenter{}, nil)
+
+	review := &domain.ReviewResult{
+		Score: 60,
+		Comments: []domain.ReviewComment{
+			{File: "pkg/api_test.go", Severity: domain.CommentSeverityCritical},
+			{File: "pkg/api_test.go", Severity: domain.CommentSeverityInfo},
+			{File: "examples/demo/main.go", Severity: domain.CommentSeverityCritical},
+			{File: "pkg/api.go", Severity: domain.CommentSeverityCritical},
+		},
+	}
+	p.applySeverityCaps(&domain.PullRequest{ProjectKey: "PAY", RepoSlug: "api"}, review)
+
+	want := []string{domain.CommentSeverityWarning, domain.CommentSeverityInfo, domain.CommentSeverityNit, domain.CommentSeverityCritical}
+	for i, c := range review.Comments {
+		if c.Severity != want[i] {
+			t.Errorf("%s: severity %s, want %s", c.File, c.Severity, want[i])
+		}
+	}
+	// One level for the test finding, three for the example finding under the stricter cap
+	if wantScore := 60 + 4*config.SeverityCapScoreRefund; review.Score != wantScore {
+		t.Errorf("score %d, want %d", review.Score, wantScore)
+	}
+
+	// The stricter cap is limited to PAY repositories
+	other := &domain.ReviewResult{Comments: []domain.ReviewComment{{File: "examples/demo/main.go", Severity: domain.CommentSeverityCritical}}}
+	p.applySeverityCaps(&domain.PullRequest{ProjectKey: "OPS", RepoSlug: "api"}, other)
+	if other.Comments[0].Severity != domain.CommentSeverityWarning {
+		t.Errorf("severity %s outside PAY, want WARNING", other.Comments[0].Severity)
+	}
+}
+
+func TestPRProcessor_MuteCategories(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Pipeline.PostProcessing.Categories = map[string]bool{domain.CommentCategoryStyle: false, domain.CommentCategoryLogic: true}
+	p := NewPRProcessor(cfg, &MockReviewer{}, &MockCommenter{}, nil)
+
+	review := &domain.ReviewResult{Comments: []domain.ReviewComment{
+		{File: "a.go", Category: domain.CommentCategoryStyle},
+		{File: "b.go", Category: domain.CommentCategoryLogic},
+		{File: "c.go", Category: domain.CommentCategoryPerformance},
+		{File: "d.go"},
+	}}
+	p.muteCategories(&domain.PullRequest{ID: "1"}, review)
+
+	var files []string
+	for _, c := range review.Comments {
+		files = append(files, c.File)
+	}
+	if strings.Join(files, ",") != "b.go,c.go,d.go" {
+		t.Errorf("kept %v, want the style finding muted", files)
+	}
+}
+
+func TestPRProcessor_ApplyConfidence(t *testing.T) {
+	comments := func() []domain.ReviewComment {
+		return []domain.ReviewComment{
+			{File: "a.go", Comment: "Nil map write", Confidence: 0.9},
+			{File: "b.go", Comment: "Maybe racy", Confidence: 0.3},
+			{File: "c.go", Comment: "No confidence"},
+		}
+	}
+	cfg := &config.Config{}
+	cfg.Pipeline.PostProcessing.Confidence = config.ConfidenceConfig{Min: 0.6}
+	p := NewPRProcessor(cfg, &MockReviewer{}, &MockCommenter{}, nil)
+
+	review := &domain.ReviewResult{Comments: comments()}
+	p.applyConfidence(&domain.PullRequest{ID: "1"}, review)
+	if len(review.Comments) != 2 || review.Comments[0].File != "a.go" || review.Comments[1].File != "c.go" {
+		t.Errorf("kept %+v, want the low confidence finding dropped", review.Comments)
+	}
+
+	cfg.Pipeline.PostProcessing.Confidence.Action = config.ConfidenceActionLabel
+	review = &domain.ReviewResult{Comments: comments()}
+	p.applyConfidence(&domain.PullRequest{ID: "1"}, review)
+	if len(review.Comments) != 3 || review.Comments[1].Comment != "_Low confidence (30%), please verify:_ Maybe racy" || review.Comments[0].Comment != "Nil map write" {
+		t.Errorf("labeled %+v", review.Comments)
+	}
+}
@@ -0,0 +1,65 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/pipeline"
+	"pr-review-automation/internal/rules"
+)
+
+// diffSize is the size of a preprocessed diff, as the review would see it
+type diffSize struct {
+	files  int
+	tokens int
+}
+
+// measureDiff preprocesses diff like Stage 1 and measures the files the review would cover
+func measureDiff(pr *domain.PullRequest, diff string) diffSize {
+	var size diffSize
+	for _, c := range pipeline.ParseDiff(diff) {
+		if rc := pr.RepoConfig; rc != nil && len(rc.Ignore) > 0 && rules.MatchAny(rc.Ignore, c.Path) {
+			continue
+		}
+		size.files++
+		size.tokens += pipeline.EstimateTokens(strings.Join(c.HunkLines, "\n"))
+	}
+	return size
+}
+
+// oversized reports whether a diff exceeds pipeline.size_gate
+func oversized(gate config.SizeGateConfig, size diffSize) bool {
+	return (gate.MaxFiles > 0 && size.files > gate.MaxFiles) || (gate.MaxTokens > 0 && size.tokens > gate.MaxTokens)
+}
+
+// skipOversized records the review of a PR too large for pipeline.size_gate as skipped and
+// explains in a skip note why, instead of reviewing a truncated diff
+func (p *PRProcessor) skipOversized(ctx context.Context, pr *domain.PullRequest, size diffSize) {
+	p.countPR(pr, "skipped")
+	p.recordSkip(pr, domain.SkipReasonSizeGate, fmt.Sprintf("%d files, ~%d tokens", size.files, size.tokens))
+	p.postSkipNote(ctx, pr, domain.SkipReasonSizeGate, p.sizeGateNote(p.cfg.Pipeline.SizeGate, size))
+}
+
+// sizeGateNote explains why an oversized PR is not reviewed and suggests splitting it
+func (p *PRProcessor) sizeGateNote(gate config.SizeGateConfig, size diffSize) string {
+	var limits []string
+	if gate.MaxFiles > 0 {
+		limits = append(limits, fmt.Sprintf("%d files", gate.MaxFiles))
+	}
+	if gate.MaxTokens > 0 {
+		limits = append(limits, fmt.Sprintf("~%d tokens", gate.MaxTokens))
+	}
+
+	var sb strings.Builder
+	sb.WriteString("⚠️ **AI review skipped: this pull request is too large.**\n\n")
+	fmt.Fprintf(&sb, "It changes %d files (~%d tokens of diff); automated reviews are limited to %s. ", size.files, size.tokens, strings.Join(limits, " and "))
+	sb.WriteString("A review of a diff this size would have to be truncated, and its findings would be incomplete.\n\n")
+	sb.WriteString("Please consider splitting it into smaller pull requests, e.g. separating refactorings, generated code and feature changes.")
+	if p.cfg.Commands.Enabled {
+		fmt.Fprintf(&sb, " A review can still be requested with `%s %s`.", p.cfg.Commands.Prefix, domain.CommandReview)
+	}
+	return sb.String()
+}
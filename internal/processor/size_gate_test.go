@@ -0,0 +1,78 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+// fileDiff returns a unified diff adding lines lines to path
+func fileDiff(path string, lines int) string {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "diff --git a/%s b/%s\n--- a/%s\n+++ b/%s\n@@ -0,0 +1,%d @@\n", path, path, path, path, lines)
+	for i := 0; i < lines; i++ {
+		fmt.Fprintf(&sb, "+line %d of %s\n", i, path)
+	}
+	return sb.String()
+}
+
+func TestPRProcessor_SizeGate(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Pipeline.SizeGate = config.SizeGateConfig{Enabled: true, MaxFiles: 2}
+	cfg.Pipeline.SkipNotes.Enabled = true
+
+	var diff string
+	var notes []string
+	commenter := &MockCommenter{CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+		switch toolName {
+		case config.ToolBitbucketGetDiff:
+			return diff, nil
+		case config.ToolBitbucketAddComment:
+			notes = append(notes, args["commentText"].(string))
+		}
+		return `{"values": []}`, nil
+	}}
+	reviewed := false
+	reviewer := &MockReviewer{ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+		reviewed = true
+		return &domain.ReviewResult{Score: 100}, nil
+	}}
+	p := NewPRProcessor(cfg, reviewer, commenter, nil)
+	pr := func() *domain.PullRequest {
+		return &domain.PullRequest{ID: "1", ProjectKey: "PAY", RepoSlug: "api", LatestCommit: "abc"}
+	}
+
+	diff = fileDiff("a.go", 3) + fileDiff("b.go", 3) + fileDiff("c.go", 3)
+	if err := p.ProcessPullRequest(context.Background(), pr()); err != nil {
+		t.Fatalf("ProcessPullRequest: %v", err)
+	}
+	if reviewed || len(notes) != 1 || !strings.Contains(notes[0], "too large") || !strings.Contains(notes[0], "3 files") {
+		t.Errorf("oversized PR: reviewed = %v, notes = %q", reviewed, notes)
+	}
+
+	// Files the repository ignores do not count
+	notes = nil
+	ignored := pr()
+	ignored.RepoConfig = &domain.RepoConfig{Ignore: []string{"c.go"}}
+	if err := p.ProcessPullRequest(context.Background(), ignored); err != nil {
+		t.Fatalf("ProcessPullRequest: %v", err)
+	}
+	if !reviewed {
+		t.Errorf("PR within the limit was not reviewed, notes = %q", notes)
+	}
+
+	// Requested reviews are not gated
+	reviewed = false
+	requested := pr()
+	requested.Overrides = &domain.ReviewOverrides{}
+	if err := p.ProcessPullRequest(context.Background(), requested); err != nil {
+		t.Fatalf("ProcessPullRequest: %v", err)
+	}
+	if !reviewed {
+		t.Error("requested review of an oversized PR was skipped")
+	}
+}
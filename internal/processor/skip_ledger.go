@@ -0,0 +1,88 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/storage"
+)
+
+// RecordSkip records a skipped review in the ledger and optionally posts a transparency note.
+// Ledger and note failures are logged only; a skip never fails processing.
+func (p *PRProcessor) RecordSkip(ctx context.Context, pr *domain.PullRequest, reason, detail string) {
+	ctx = domain.WithProvider(ctx, pr.Provider)
+	p.recordSkip(pr, reason, detail)
+	p.postSkipNote(ctx, pr, reason, skipNote(reason))
+}
+
+// postSkipNote posts text as the note explaining why the review of pr was skipped, when
+// pipeline.skip_notes wants one for reason. The note is posted once per commit: a PR that already
+// carries the skip marker of its latest commit gets no second note. Failures are logged only.
+func (p *PRProcessor) postSkipNote(ctx context.Context, pr *domain.PullRequest, reason, text string) {
+	// A dry run posts nothing, not even the note; an ignored PR already has its pause note
+	notes := p.cfg.Pipeline.SkipNotes
+	if !notes.Enabled || p.dryRun(pr) || reason == domain.SkipReasonDryRun || reason == domain.SkipReasonIgnored || (len(notes.Reasons) > 0 && !slices.Contains(notes.Reasons, reason)) {
+		return
+	}
+	pullRequestId, err := strconv.Atoi(pr.ID)
+	if err != nil {
+		return
+	}
+	marker := p.markers().skipMarker(pr.LatestCommit)
+	posted, err := p.hasComment(ctx, pr, marker)
+	if err != nil {
+		slog.Warn("fetch comments for skip note failed", "pr_id", pr.ID, "error", err)
+		return
+	}
+	if posted {
+		return
+	}
+	_, err = p.commenter.CallTool(ctx, config.MCPServerBitbucket, config.ToolBitbucketAddComment, map[string]interface{}{
+		"projectKey":    pr.ProjectKey,
+		"repoSlug":      pr.RepoSlug,
+		"pullRequestId": pullRequestId,
+		"commentText":   marker + "\n" + text,
+	})
+	if err != nil {
+		slog.Warn("post skip note failed", "pr_id", pr.ID, "reason", reason, "error", err)
+		metrics.CommentPostFailures.WithLabelValues("skip_note").Inc()
+	}
+}
+
+// recordSkip counts and logs a skipped review and saves it in the ledger
+func (p *PRProcessor) recordSkip(pr *domain.PullRequest, reason, detail string) {
+	metrics.ReviewSkips.WithLabelValues(reason).Inc()
+	slog.Info("review skipped", "pr_id", pr.ID, "repo", pr.RepoSlug, "reason", reason, "detail", detail)
+
+	if ledger, ok := p.storage.(storage.SkipRepository); ok {
+		saveCtx, cancel := context.WithTimeout(context.Background(), p.cfg.Storage.Timeout)
+		defer cancel()
+		err := ledger.SaveSkip(saveCtx, &storage.SkipRecord{
+			ProjectKey: pr.ProjectKey,
+			RepoSlug:   pr.RepoSlug,
+			PRID:       pr.ID,
+			Commit:     pr.LatestCommit,
+			Author:     pr.Author,
+			Reason:     reason,
+			Detail:     detail,
+		})
+		if err != nil {
+			slog.Warn("save skip failed", "error", err)
+		}
+	}
+}
+
+// skipNote returns the one-line transparency note for a skip reason
+func skipNote(reason string) string {
+	desc, ok := domain.SkipReasonDescriptions[reason]
+	if !ok {
+		desc = reason
+	}
+	return fmt.Sprintf("ℹ️ AI review skipped: %s.", desc)
+}
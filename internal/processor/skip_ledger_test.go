@@ -0,0 +1,117 @@
+package processor
+
+import (
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/storage"
+)
+
+// skipStore records skip ledger entries
+type skipStore struct {
+	storage.Repository
+	skips []*storage.SkipRecord
+}
+
+func (s *skipStore) SaveSkip(ctx context.Context, sk *storage.SkipRecord) error {
+	s.skips = append(s.skips, sk)
+	return nil
+}
+
+func (s *skipStore) ListSkips(ctx context.Context, limit int) ([]*storage.SkipRecord, error) {
+	return s.skips, nil
+}
+
+func TestPRProcessor_SkipLedger(t *testing.T) {
+	tests := []struct {
+		name       string
+		hookErr    error
+		reasons    []string
+		wantReason string
+		wantNote   bool
+	}{
+		{name: "reason from hook", hookErr: Skip(domain.SkipReasonSizeGate, "4200 lines"), wantReason: domain.SkipReasonSizeGate, wantNote: true},
+		{name: "plain ErrSkip", hookErr: ErrSkip, wantReason: domain.SkipReasonHook, wantNote: true},
+		{name: "note not configured for reason", hookErr: Skip(domain.SkipReasonBudget, ""), reasons: []string{domain.SkipReasonSizeGate}, wantReason: domain.SkipReasonBudget},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var notes []string
+			commenter := &MockCommenter{
+				CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+					if toolName == config.ToolBitbucketAddComment {
+						notes = append(notes, args["commentText"].(string))
+					}
+					return `{"values":[]}`, nil
+				},
+			}
+			store := &skipStore{}
+			cfg := &config.Config{}
+			cfg.Storage.Timeout = time.Second
+			cfg.Pipeline.SkipNotes = config.SkipNotesConfig{Enabled: true, Reasons: tt.reasons}
+
+			p := NewPRProcessor(cfg, &MockReviewer{}, commenter, store)
+			p.OnBeforeReview(func(ctx context.Context, req *domain.ReviewRequest) error { return tt.hookErr })
+
+			pr := &domain.PullRequest{ID: "3", ProjectKey: "P", RepoSlug: "r", LatestCommit: "abc"}
+			if err := p.ProcessPullRequest(context.Background(), pr); err != nil {
+				t.Fatalf("skip must not fail processing: %v", err)
+			}
+			if len(store.skips) != 1 || store.skips[0].Reason != tt.wantReason || store.skips[0].Commit != "abc" {
+				t.Fatalf("unexpected ledger: %+v", store.skips)
+			}
+			if got := len(notes) == 1; got != tt.wantNote {
+				t.Fatalf("note posted = %v, want %v (%q)", got, tt.wantNote, notes)
+			}
+			if tt.wantNote && !strings.Contains(notes[0], "AI review skipped: "+domain.SkipReasonDescriptions[tt.wantReason]) {
+				t.Errorf("unexpected note: %q", notes[0])
+			}
+		})
+	}
+}
+
+func TestPRProcessor_PostSkipNote(t *testing.T) {
+	tests := []struct {
+		name     string
+		notes    config.SkipNotesConfig
+		comments string
+		wantNote bool
+	}{
+		{name: "first skip of the commit", notes: config.SkipNotesConfig{Enabled: true}, comments: `{"values":[]}`, wantNote: true},
+		{name: "note of the commit already posted", notes: config.SkipNotesConfig{Enabled: true}, comments: `{"values":[{"text":"<!-- ai-review::skip:abc-->\nskipped"}]}`},
+		{name: "note of an earlier commit", notes: config.SkipNotesConfig{Enabled: true}, comments: `{"values":[{"text":"<!-- ai-review::skip:old-->\nskipped"}]}`, wantNote: true},
+		{name: "skip notes disabled", comments: `{"values":[]}`},
+		{name: "reason not configured", notes: config.SkipNotesConfig{Enabled: true, Reasons: []string{domain.SkipReasonSizeGate}}, comments: `{"values":[]}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var notes []string
+			commenter := &MockCommenter{
+				CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+					if toolName == config.ToolBitbucketAddComment {
+						notes = append(notes, args["commentText"].(string))
+					}
+					return tt.comments, nil
+				},
+			}
+			cfg := &config.Config{}
+			cfg.Pipeline.SkipNotes = tt.notes
+			p := NewPRProcessor(cfg, &MockReviewer{}, commenter, nil)
+
+			pr := &domain.PullRequest{ID: "3", ProjectKey: "P", RepoSlug: "r", LatestCommit: "abc"}
+			p.postSkipNote(context.Background(), pr, domain.SkipReasonBudget, "budget is exhausted")
+			if got := len(notes) == 1; got != tt.wantNote {
+				t.Fatalf("note posted = %v, want %v (%q)", got, tt.wantNote, notes)
+			}
+			if tt.wantNote && notes[0] != "<!-- ai-review::skip:abc-->\nbudget is exhausted" {
+				t.Errorf("unexpected note: %q", notes[0])
+			}
+		})
+	}
+}
@@ -0,0 +1,47 @@
+package processor
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/rules"
+)
+
+// summaryOnly reports whether only the summary comment is posted on this PR's repository
+func (p *PRProcessor) summaryOnly(pr *domain.PullRequest) bool {
+	sc := p.cfg.Pipeline.SummaryOnly
+	return sc.Enabled && rules.MatchAny(sc.Repos, pr.ProjectKey+"/"+pr.RepoSlug)
+}
+
+// postSummaryOnly posts the summary comment without inline comments or INFO/NIT suggestions.
+// The findings stay in the stored review.
+func (p *PRProcessor) postSummaryOnly(ctx context.Context, pr *domain.PullRequest, review *domain.ReviewResult, existingComments []domain.ReviewComment) error {
+	slog.Info("summary-only repository, inline comments not posted", "repo", pr.ProjectKey+"/"+pr.RepoSlug, "count", len(review.Comments))
+	merger := NewCommentMerger(&p.cfg.Pipeline.CommentMerge, pr.WebURL)
+	merger.markers = p.markers()
+	merger.summary = p.summaryTemplate
+	p.postSummary(ctx, pr, review, merger, nil, existingComments)
+	return p.cleanupSession(pr.ID)
+}
+
+// summaryOnlyNote counts the findings that were not posted inline
+func summaryOnlyNote(comments []domain.ReviewComment) string {
+	if len(comments) == 0 {
+		return "_Summary-only repository: no inline findings._"
+	}
+	counts := map[string]int{}
+	for _, c := range comments {
+		counts[strings.ToUpper(c.Severity)]++
+	}
+	var parts []string
+	for _, sev := range []string{domain.CommentSeverityCritical, domain.CommentSeverityWarning, domain.CommentSeverityInfo, domain.CommentSeverityNit} {
+		if n := counts[sev]; n > 0 {
+			parts = append(parts, fmt.Sprintf("%d %s", n, sev))
+		}
+	}
+	return fmt.Sprintf("_Summary-only repository: %d findings (%s) were not posted inline; they are available in the stored review._",
+		len(comments), strings.Join(parts, ", "))
+}
@@ -0,0 +1,78 @@
+package processor
+
+import (
+	"context"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestPRProcessor_SummaryOnly(t *testing.T) {
+	reviewer := &MockReviewer{
+		ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+			return &domain.ReviewResult{
+				Comments: []domain.ReviewComment{
+					{File: "main.go", Line: 1, Severity: "CRITICAL", Comment: "Hardcoded secret"},
+					{File: "main.go", Line: 1, Severity: "NIT", Comment: "Typo"},
+				},
+				Summary: "Two issues",
+			}, nil
+		},
+	}
+
+	tests := []struct {
+		name        string
+		repos       []string
+		summaryOnly bool
+	}{
+		{name: "matching repo", repos: []string{"DOCS/*"}, summaryOnly: true},
+		{name: "other repo", repos: []string{"PAY/*"}, summaryOnly: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var posted []map[string]interface{}
+			commenter := &MockCommenter{
+				CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+					switch toolName {
+					case config.ToolBitbucketGetComments:
+						return `{"values": []}`, nil
+					case config.ToolBitbucketGetDiff:
+						return "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -0,0 +1,1 @@\n+x\n", nil
+					case config.ToolBitbucketAddComment:
+						posted = append(posted, args)
+					}
+					return nil, nil
+				},
+			}
+
+			cfg := &config.Config{}
+			cfg.Pipeline.SerialCommentPosting = true
+			cfg.Pipeline.SummaryOnly = config.SummaryOnlyConfig{Enabled: true, Repos: tt.repos}
+			p := NewPRProcessor(cfg, reviewer, commenter, nil)
+
+			pr := &domain.PullRequest{ID: "3", ProjectKey: "DOCS", RepoSlug: "handbook", LatestCommit: "abc"}
+			if err := p.ProcessPullRequest(context.Background(), pr); err != nil {
+				t.Fatal(err)
+			}
+
+			if !tt.summaryOnly {
+				if len(posted) != 2 {
+					t.Errorf("expected 2 inline comments, got %d", len(posted))
+				}
+				return
+			}
+			if len(posted) != 1 {
+				t.Fatalf("expected only the summary comment, got %d comments", len(posted))
+			}
+			if _, ok := posted[0]["lineNumber"]; ok {
+				t.Error("summary-only repository got an inline comment")
+			}
+			text := posted[0]["commentText"].(string)
+			if !strings.Contains(text, "Two issues") || !strings.Contains(text, "2 findings (1 CRITICAL, 1 NIT) were not posted inline") {
+				t.Errorf("unexpected summary:\n%s", text)
+			}
+		})
+	}
+}
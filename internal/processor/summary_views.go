@@ -0,0 +1,144 @@
+package processor
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"text/template"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/rules"
+)
+
+// maxHeadlineLength caps the executive headline taken from the model summary
+const maxHeadlineLength = 200
+
+// SummaryView is the data passed to the summary template
+type SummaryView struct {
+	Model          string
+	Score          int
+	Verdict        string // One-line verdict with severity counts
+	Headline       string // First sentence of the model summary
+	Details        string // Full summary, markdown cleaned
+	Suggestions    string // INFO/NIT table (may be empty)
+	ExecutiveFirst bool
+	Critical       int
+	Warning        int
+	Info           int
+}
+
+// defaultSummaryTemplate renders the executive verdict and the collapsible developer details
+const defaultSummaryTemplate = `{{define "executive"}}**{{.Verdict}}** (Score: {{.Score}}/100)
+{{.Headline}}{{end}}
+{{- define "developer"}}<details>
+<summary>Developer details</summary>
+
+{{.Details}}
+{{.Suggestions}}
+</details>{{end}}
+{{- /* layout */ -}}
+**AI Review Summary (Model: {{.Model}})**
+
+{{if .ExecutiveFirst}}{{template "executive" .}}
+
+{{template "developer" .}}{{else}}{{template "developer" .}}
+
+{{template "executive" .}}{{end}}`
+
+// loadSummaryTemplate parses the configured summary template, or the built-in one when path is empty
+func loadSummaryTemplate(path string) (*template.Template, error) {
+	text := defaultSummaryTemplate
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("read summary template: %w", err)
+		}
+		text = string(data)
+	}
+	return template.New("summary").Parse(text)
+}
+
+// summaryLayout resolves the layout for the PR's repository
+func summaryLayout(cfg config.SummaryConfig, pr *domain.PullRequest) string {
+	repo := pr.ProjectKey + "/" + pr.RepoSlug
+	for _, r := range cfg.Repos {
+		if rules.MatchAny(r.Repos, repo) {
+			return r.Layout
+		}
+	}
+	if cfg.Layout == "" {
+		return config.SummaryLayoutClassic
+	}
+	return cfg.Layout
+}
+
+// FormatSummary renders the summary comment body (without marker and footer) for the given layout
+func (m *CommentMerger) FormatSummary(review *domain.ReviewResult, addons []domain.ReviewComment, layout string) (string, error) {
+	details := cleanSummaryMarkdown(review.Summary)
+	suggestions := m.FormatSummaryAddons(addons)
+
+	if layout == config.SummaryLayoutClassic || layout == "" || m.summary == nil {
+		return fmt.Sprintf("**AI Review Summary (Model: %s)**\nScore: %d\n\n%s%s",
+			review.Model, review.Score, details, suggestions), nil
+	}
+
+	view := SummaryView{
+		Model:          review.Model,
+		Score:          review.Score,
+		Headline:       headline(review.Summary),
+		Details:        details,
+		Suggestions:    strings.TrimSpace(suggestions),
+		ExecutiveFirst: layout == config.SummaryLayoutExecutiveFirst,
+	}
+	for _, c := range review.Comments {
+		switch strings.ToUpper(c.Severity) {
+		case domain.CommentSeverityCritical:
+			view.Critical++
+		case domain.CommentSeverityWarning:
+			view.Warning++
+		default:
+			view.Info++
+		}
+	}
+	view.Verdict = verdict(view.Critical, view.Warning)
+
+	var sb strings.Builder
+	if err := m.summary.Execute(&sb, view); err != nil {
+		return "", fmt.Errorf("render summary: %w", err)
+	}
+	return sb.String(), nil
+}
+
+// verdict summarizes blocking findings in one line
+func verdict(critical, warning int) string {
+	switch {
+	case critical > 0 && warning > 0:
+		return fmt.Sprintf("🚫 Changes required: %d critical, %d warning findings", critical, warning)
+	case critical > 0:
+		return fmt.Sprintf("🚫 Changes required: %d critical findings", critical)
+	case warning > 0:
+		return fmt.Sprintf("⚠️ Review recommended: %d warning findings", warning)
+	default:
+		return "✅ No blocking issues found"
+	}
+}
+
+// headline returns the first sentence of the summary, skipping markdown headers
+func headline(summary string) string {
+	var text string
+	for _, line := range strings.Split(summary, "\n") {
+		line = strings.TrimSpace(line)
+		if line != "" && !strings.HasPrefix(line, "#") {
+			text = cleanSummaryMarkdown(line)
+			break
+		}
+	}
+	if i := strings.Index(text, ". "); i != -1 {
+		text = text[:i+1]
+	}
+	if r := []rune(text); len(r) > maxHeadlineLength {
+		text = string(r[:maxHeadlineLength]) + "..."
+	}
+	return text
+}
@@ -0,0 +1,97 @@
+package processor
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestCommentMerger_FormatSummary(t *testing.T) {
+	tmpl, err := loadSummaryTemplate("")
+	if err != nil {
+		t.Fatalf("load default template: %v", err)
+	}
+	m := NewCommentMerger(&config.CommentMergeConfig{}, "")
+	m.summary = tmpl
+
+	review := &domain.ReviewResult{
+		Model:   "gpt-4o",
+		Score:   62,
+		Summary: "## Overview\nThe change adds a cache. It misses invalidation on writes.",
+		Comments: []domain.ReviewComment{
+			{Severity: "CRITICAL"}, {Severity: "WARNING"}, {Severity: "WARNING"}, {Severity: "NIT"},
+		},
+	}
+	addons := []domain.ReviewComment{{File: "a.go", Line: 3, Severity: "NIT", Comment: "typo"}}
+
+	tests := []struct {
+		layout    string
+		wantFirst string
+		want      []string
+	}{
+		{config.SummaryLayoutClassic, "Score: 62", []string{"Overview", "typo"}},
+		{config.SummaryLayoutExecutiveFirst, "**🚫 Changes required: 1 critical, 2 warning findings**", []string{"(Score: 62/100)\nThe change adds a cache.\n", "<details>", "typo"}},
+		{config.SummaryLayoutDeveloperFirst, "<details>", []string{"🚫 Changes required", "typo"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.layout, func(t *testing.T) {
+			got, err := m.FormatSummary(review, addons, tt.layout)
+			if err != nil {
+				t.Fatalf("FormatSummary: %v", err)
+			}
+			if !strings.HasPrefix(got, "**AI Review Summary (Model: gpt-4o)**") {
+				t.Errorf("missing header: %q", got)
+			}
+			body := strings.TrimSpace(strings.SplitN(got, "\n", 2)[1])
+			if !strings.HasPrefix(body, tt.wantFirst) {
+				t.Errorf("expected body to start with %q, got %q", tt.wantFirst, body)
+			}
+			for _, w := range tt.want {
+				if !strings.Contains(got, w) {
+					t.Errorf("expected %q in %q", w, got)
+				}
+			}
+		})
+	}
+}
+
+func TestSummaryLayout_PerRepo(t *testing.T) {
+	cfg := config.SummaryConfig{
+		Layout: config.SummaryLayoutDeveloperFirst,
+		Repos:  []config.SummaryRepoLayout{{Repos: []string{"MGMT/*"}, Layout: config.SummaryLayoutExecutiveFirst}},
+	}
+	if got := summaryLayout(cfg, &domain.PullRequest{ProjectKey: "MGMT", RepoSlug: "plan"}); got != config.SummaryLayoutExecutiveFirst {
+		t.Errorf("override: got %s", got)
+	}
+	if got := summaryLayout(cfg, &domain.PullRequest{ProjectKey: "DEV", RepoSlug: "api"}); got != config.SummaryLayoutDeveloperFirst {
+		t.Errorf("default: got %s", got)
+	}
+	if got := summaryLayout(config.SummaryConfig{}, &domain.PullRequest{}); got != config.SummaryLayoutClassic {
+		t.Errorf("unset: got %s", got)
+	}
+}
+
+func TestLoadSummaryTemplate_Custom(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "summary.tmpl")
+	if err := os.WriteFile(path, []byte("{{.Verdict}} / {{.Score}}"), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	tmpl, err := loadSummaryTemplate(path)
+	if err != nil {
+		t.Fatalf("load: %v", err)
+	}
+	m := NewCommentMerger(&config.CommentMergeConfig{}, "")
+	m.summary = tmpl
+
+	got, err := m.FormatSummary(&domain.ReviewResult{Score: 90}, nil, config.SummaryLayoutExecutiveFirst)
+	if err != nil {
+		t.Fatalf("FormatSummary: %v", err)
+	}
+	if got != "✅ No blocking issues found / 90" {
+		t.Errorf("got %q", got)
+	}
+}
@@ -0,0 +1,99 @@
+package processor
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/storage"
+)
+
+func TestPRProcessor_ReviewTimeout(t *testing.T) {
+	commenter := &MockCommenter{CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+		return `{"values": []}`, nil
+	}}
+	reviewer := &MockReviewer{ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}}
+	store, err := storage.NewSQLiteRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	cfg := &config.Config{}
+	cfg.Storage.Timeout = time.Second
+	p := NewPRProcessor(cfg, reviewer, commenter, store)
+	events := &recordedEvents{}
+	p.SetEventPublisher(events)
+
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 10*time.Millisecond, domain.ErrReviewTimeout)
+	defer cancel()
+	pr := &domain.PullRequest{ID: "7", ProjectKey: "PROJ", RepoSlug: "repo", LatestCommit: "abc123"}
+	if err := p.ProcessPullRequest(ctx, pr); err == nil {
+		t.Fatal("expected the timed out review to fail")
+	}
+
+	records, err := store.ListReviewsByPR(context.Background(), "PROJ", "repo", "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Status != domain.ReviewStatusTimeout {
+		t.Fatalf("expected a timeout record, got %+v", records)
+	}
+	if len(events.got) != 1 || events.got[0].Status != domain.ReviewStatusTimeout {
+		t.Errorf("expected a timeout event, got %+v", events.got)
+	}
+}
+
+func TestPRProcessor_ReviewTimeoutWhilePosting(t *testing.T) {
+	commenter := &MockCommenter{CallToolFunc: func(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+		switch toolName {
+		case config.ToolBitbucketGetComments:
+			return `{"values": []}`, nil
+		case config.ToolBitbucketGetDiff:
+			return "diff --git a/main.go b/main.go\n--- a/main.go\n+++ b/main.go\n@@ -0,0 +1,1 @@\n+x\n", nil
+		default:
+			<-ctx.Done()
+			return nil, ctx.Err()
+		}
+	}}
+	reviewer := &MockReviewer{ReviewPRFunc: func(ctx context.Context, req *domain.ReviewRequest) (*domain.ReviewResult, error) {
+		return &domain.ReviewResult{Score: 80, Comments: []domain.ReviewComment{{File: "main.go", Line: 1, Severity: "WARNING", Comment: "check x"}}}, nil
+	}}
+	store, err := storage.NewSQLiteRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	cfg := &config.Config{}
+	cfg.Storage.Timeout = time.Second
+	p := NewPRProcessor(cfg, reviewer, commenter, store)
+
+	ctx, cancel := context.WithTimeoutCause(context.Background(), 50*time.Millisecond, domain.ErrReviewTimeout)
+	defer cancel()
+	pr := &domain.PullRequest{ID: "7", ProjectKey: "PROJ", RepoSlug: "repo", LatestCommit: "abc123"}
+	_ = p.ProcessPullRequest(ctx, pr) // Failed comments are logged, not returned
+
+	records, err := store.ListReviewsByPR(context.Background(), "PROJ", "repo", "7")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(records) != 1 || records[0].Status != domain.ReviewStatusTimeout || len(records[0].Result.Comments) != 1 {
+		t.Fatalf("expected the stored review to be marked timed out, got %+v", records)
+	}
+}
+
+// recordedEvents keeps the published events
+type recordedEvents struct {
+	got []domain.ReviewCompletedEvent
+}
+
+func (r *recordedEvents) Publish(evt domain.ReviewCompletedEvent) {
+	r.got = append(r.got, evt)
+}
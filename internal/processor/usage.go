@@ -0,0 +1,28 @@
+package processor
+
+import (
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+)
+
+// reviewCost observes the tokens of a review of pr by model and project label (see repoLabels),
+// charges them to the budgets and returns their cost in USD, 0 when the model has no
+// llm.pricing entry
+func (p *PRProcessor) reviewCost(pr *domain.PullRequest, review *domain.ReviewResult) float64 {
+	if review.Usage == nil {
+		return 0
+	}
+	u := review.Usage
+	tokens := u.PromptTokens + u.CompletionTokens
+	project, _ := p.repoLabels(pr)
+	metrics.ReviewTokens.WithLabelValues(review.Model, project).Observe(float64(tokens))
+	var cost float64
+	if pricing, ok := p.cfg.PricingFor(review.Model); ok {
+		cost = pricing.Cost(u.PromptTokens, u.CompletionTokens)
+		metrics.ReviewCost.WithLabelValues(review.Model, project).Observe(cost)
+	}
+	if p.budget != nil {
+		p.budget.Add(pr.ProjectKey, tokens, cost)
+	}
+	return cost
+}
@@ -0,0 +1,47 @@
+package processor
+
+import (
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+)
+
+// histogram returns the sample count and sum of one histogram series
+func histogram(t *testing.T, o prometheus.Observer) (count uint64, sum float64) {
+	t.Helper()
+	var m dto.Metric
+	if err := o.(prometheus.Metric).Write(&m); err != nil {
+		t.Fatalf("Write: %v", err)
+	}
+	return m.GetHistogram().GetSampleCount(), m.GetHistogram().GetSampleSum()
+}
+
+func TestReviewCost_ProjectLabel(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Metrics = config.MetricsConfig{Projects: []string{"PAY"}}
+	cfg.LLM.Pricing = []config.ModelPricing{{Models: []string{"usage-test-model"}, Prompt: 2, Completion: 8}}
+	p := &PRProcessor{cfg: cfg}
+
+	review := &domain.ReviewResult{Model: "usage-test-model", Usage: &domain.TokenUsage{PromptTokens: 1000, CompletionTokens: 500}}
+	if cost := p.reviewCost(&domain.PullRequest{ProjectKey: "PAY", RepoSlug: "api"}, review); cost != 0.006 {
+		t.Errorf("cost = %v, want 0.006", cost)
+	}
+	p.reviewCost(&domain.PullRequest{ProjectKey: "MISC", RepoSlug: "tool"}, review)
+
+	for _, project := range []string{"PAY", "other"} {
+		if count, sum := histogram(t, metrics.ReviewTokens.WithLabelValues("usage-test-model", project)); count != 1 || sum != 1500 {
+			t.Errorf("tokens of %s = %d samples summing %v, want 1 summing 1500", project, count, sum)
+		}
+		if count, sum := histogram(t, metrics.ReviewCost.WithLabelValues("usage-test-model", project)); count != 1 || sum != 0.006 {
+			t.Errorf("cost of %s = %d samples summing %v, want 1 summing 0.006", project, count, sum)
+		}
+	}
+	if count, _ := histogram(t, metrics.ReviewTokens.WithLabelValues("usage-test-model", "MISC")); count != 0 {
+		t.Errorf("project outside the allowlist got its own series")
+	}
+}
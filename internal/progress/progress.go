@@ -0,0 +1,153 @@
+// Package progress publishes the progress of running reviews (stage transitions, chunk
+// completions, partial summaries) to live subscribers such as the review events API.
+package progress
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Event types
+const (
+	EventStage   = "stage"   // A review stage started
+	EventChunk   = "chunk"   // A chunk of a chunked review finished
+	EventSummary = "summary" // A chunk summary, or the summary of the whole review (chunk 0)
+	EventDone    = "done"    // The review ended; no events follow
+)
+
+// Review stages, in order
+const (
+	StageComments = "comments" // Existing review comments are fetched
+	StageDiff     = "diff"     // The diff is fetched and split into file changes
+	StageContext  = "context"  // Related files are collected
+	StageReview   = "review"   // The model reviews the changes
+	StageSecurity = "security" // The security pass reviews the changes
+	StageValidate = "validate" // Findings are validated against the diff and deduplicated
+	StagePost     = "post"     // Comments are posted
+)
+
+// subscriberBuffer is the number of events a subscriber may lag behind; a slower subscriber
+// misses events
+const subscriberBuffer = 64
+
+// Event is one step of a running review
+type Event struct {
+	Type     string    `json:"type"`
+	Stage    string    `json:"stage,omitempty"`
+	Chunk    int       `json:"chunk,omitempty"`  // 1-based
+	Chunks   int       `json:"chunks,omitempty"` // Number of chunks of the review
+	Files    []string  `json:"files,omitempty"`
+	Findings int       `json:"findings,omitempty"`
+	Summary  string    `json:"summary,omitempty"`
+	Error    string    `json:"error,omitempty"`
+	Time     time.Time `json:"time"`
+}
+
+// review holds the events of one running review and its subscribers
+type review struct {
+	events []Event
+	subs   map[chan Event]struct{}
+}
+
+// Broker fans the events of running reviews out to subscribers by PR key. It keeps the events
+// of each running review, so a subscriber joining late first gets what it missed.
+type Broker struct {
+	mu      sync.Mutex
+	reviews map[string]*review
+}
+
+// NewBroker creates a broker without running reviews
+func NewBroker() *Broker {
+	return &Broker{reviews: make(map[string]*review)}
+}
+
+type reporterKey struct{}
+
+// reporter publishes the events emitted under a context to one review of a broker
+type reporter struct {
+	broker *Broker
+	review *review
+}
+
+// Start registers the review of the PR key running under ctx and returns a context whose
+// Emit calls publish to it. The returned func ends the review with a done event; a newer
+// review of the same PR replaces this one for new subscribers.
+func (b *Broker) Start(ctx context.Context, key string) (context.Context, func()) {
+	r := &review{subs: make(map[chan Event]struct{})}
+	b.mu.Lock()
+	b.reviews[key] = r
+	b.mu.Unlock()
+
+	rep := &reporter{broker: b, review: r}
+	return context.WithValue(ctx, reporterKey{}, rep), func() {
+		done := Event{Type: EventDone}
+		if cause := context.Cause(ctx); cause != nil {
+			done.Error = cause.Error()
+		}
+		b.publish(r, done)
+
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		for ch := range r.subs {
+			close(ch)
+		}
+		r.subs = nil
+		if b.reviews[key] == r {
+			delete(b.reviews, key)
+		}
+	}
+}
+
+// Subscribe returns the events published so far for the running review of the PR key and a
+// channel of the events that follow, closed when the review ends. The returned func stops the
+// subscription. ok is false when no review of the PR is running.
+func (b *Broker) Subscribe(key string) (past []Event, events <-chan Event, stop func(), ok bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	r, ok := b.reviews[key]
+	if !ok {
+		return nil, nil, nil, false
+	}
+	ch := make(chan Event, subscriberBuffer)
+	r.subs[ch] = struct{}{}
+	return append([]Event(nil), r.events...), ch, func() {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, ok := r.subs[ch]; ok {
+			delete(r.subs, ch)
+			close(ch)
+		}
+	}, true
+}
+
+// publish records e for the review and sends it to the subscribers that keep up
+func (b *Broker) publish(r *review, e Event) {
+	if e.Time.IsZero() {
+		e.Time = time.Now().UTC()
+	}
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if r.subs == nil {
+		return // Ended
+	}
+	r.events = append(r.events, e)
+	for ch := range r.subs {
+		select {
+		case ch <- e:
+		default:
+		}
+	}
+}
+
+// Emit publishes e to the review running under ctx, if a broker tracks it
+func Emit(ctx context.Context, e Event) {
+	if rep, ok := ctx.Value(reporterKey{}).(*reporter); ok {
+		rep.broker.publish(rep.review, e)
+	}
+}
+
+// Stage publishes the start of a review stage
+func Stage(ctx context.Context, stage string) {
+	Emit(ctx, Event{Type: EventStage, Stage: stage})
+}
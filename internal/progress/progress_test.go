@@ -0,0 +1,56 @@
+package progress
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestBroker(t *testing.T) {
+	b := NewBroker()
+	if _, _, _, ok := b.Subscribe("PROJ/repo/1"); ok {
+		t.Fatal("subscribed to a review that is not running")
+	}
+	// Without a running review, emitting is a no-op
+	Stage(context.Background(), StageDiff)
+
+	ctx, cancel := context.WithCancelCause(context.Background())
+	reviewCtx, finish := b.Start(ctx, "PROJ/repo/1")
+	Stage(reviewCtx, StageDiff)
+
+	past, events, _, ok := b.Subscribe("PROJ/repo/1")
+	if !ok || len(past) != 1 || past[0].Stage != StageDiff || past[0].Time.IsZero() {
+		t.Fatalf("late subscriber got %+v, ok=%v", past, ok)
+	}
+	Emit(reviewCtx, Event{Type: EventChunk, Chunk: 1, Chunks: 2, Findings: 3})
+	if e := <-events; e.Type != EventChunk || e.Findings != 3 {
+		t.Errorf("event = %+v", e)
+	}
+
+	cancel(errors.New("superseded"))
+	finish()
+	if e := <-events; e.Type != EventDone || e.Error != "superseded" {
+		t.Errorf("done event = %+v, want the cancellation cause", e)
+	}
+	if _, open := <-events; open {
+		t.Error("subscription must close when the review ends")
+	}
+	if _, _, _, ok := b.Subscribe("PROJ/repo/1"); ok {
+		t.Error("ended review still subscribable")
+	}
+}
+
+func TestBroker_NewerReviewKeepsKey(t *testing.T) {
+	b := NewBroker()
+	_, finishOld := b.Start(context.Background(), "PROJ/repo/1")
+	newCtx, _ := b.Start(context.Background(), "PROJ/repo/1")
+	finishOld()
+
+	Stage(newCtx, StageReview)
+	past, _, stop, ok := b.Subscribe("PROJ/repo/1")
+	if !ok || len(past) != 1 || past[0].Stage != StageReview {
+		t.Fatalf("expected the newer review, got %+v ok=%v", past, ok)
+	}
+	stop()
+	stop() // Idempotent
+}
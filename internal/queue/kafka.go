@@ -0,0 +1,179 @@
+package queue
+
+import (
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/storage"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl/plain"
+)
+
+// Kafka is a queue of reviews on a Kafka topic, keyed by PR so the events of one PR stay in
+// order. All workers join one consumer group. Reviews finish out of order, so the offset of a
+// partition is committed only up to the oldest review still running: after a crash the group
+// redelivers it and every review after it.
+type Kafka struct {
+	writer  *kafka.Writer
+	reader  *kafka.Reader
+	offsets *offsetTracker
+}
+
+// NewKafka connects to the configured brokers
+func NewKafka(ctx context.Context, cfg config.KafkaQueueConfig) (*Kafka, error) {
+	dialer := &kafka.Dialer{Timeout: 10 * time.Second, DualStack: true}
+	transport := &kafka.Transport{}
+	if cfg.TLS {
+		dialer.TLS = &tls.Config{MinVersion: tls.VersionTLS12}
+		transport.TLS = dialer.TLS
+	}
+	if cfg.Username != "" {
+		mechanism := plain.Mechanism{Username: cfg.Username, Password: cfg.Password}
+		dialer.SASLMechanism = mechanism
+		transport.SASL = mechanism
+	}
+
+	conn, err := dialer.DialContext(ctx, "tcp", cfg.Brokers[0])
+	if err != nil {
+		return nil, fmt.Errorf("connect kafka %s: %w", cfg.Brokers[0], err)
+	}
+	conn.Close()
+
+	return &Kafka{
+		writer: &kafka.Writer{
+			Addr:                   kafka.TCP(cfg.Brokers...),
+			Topic:                  cfg.Topic,
+			Balancer:               &kafka.Hash{},
+			RequiredAcks:           kafka.RequireAll,
+			AllowAutoTopicCreation: true,
+			Transport:              transport,
+		},
+		reader: kafka.NewReader(kafka.ReaderConfig{
+			Brokers: cfg.Brokers,
+			GroupID: cfg.GroupID,
+			Topic:   cfg.Topic,
+			Dialer:  dialer,
+		}),
+		offsets: newOffsetTracker(),
+	}, nil
+}
+
+// Push queues a review
+func (q *Kafka) Push(ctx context.Context, review *storage.QueuedReview) error {
+	if review.CreatedAt.IsZero() {
+		review.CreatedAt = time.Now().UTC()
+	}
+	data, err := json.Marshal(review)
+	if err != nil {
+		return fmt.Errorf("marshal review %s: %w", review.Key, err)
+	}
+	if err := q.writer.WriteMessages(ctx, kafka.Message{Key: []byte(review.Key), Value: data}); err != nil {
+		metrics.ReviewQueueOperations.WithLabelValues("push", "error").Inc()
+		return fmt.Errorf("push review %s: %w", review.Key, err)
+	}
+	metrics.ReviewQueueOperations.WithLabelValues("push", "success").Inc()
+	return nil
+}
+
+// Pop takes the next review, waiting up to timeout for one. It returns nil when none arrived.
+func (q *Kafka) Pop(ctx context.Context, timeout time.Duration) (*Delivery, error) {
+	fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	msg, err := q.reader.FetchMessage(fetchCtx)
+	if err != nil {
+		if errors.Is(err, context.DeadlineExceeded) || ctx.Err() != nil {
+			return nil, nil
+		}
+		metrics.ReviewQueueOperations.WithLabelValues("pop", "error").Inc()
+		return nil, fmt.Errorf("pop review: %w", err)
+	}
+	q.offsets.fetched(msg.Partition, msg.Offset)
+
+	var review storage.QueuedReview
+	if err := json.Unmarshal(msg.Value, &review); err != nil {
+		// Unreadable messages must not hold back the offset
+		q.commit(ctx, msg)
+		metrics.ReviewQueueOperations.WithLabelValues("pop", "invalid").Inc()
+		return nil, fmt.Errorf("parse queued review: %w", err)
+	}
+	metrics.ReviewQueueOperations.WithLabelValues("pop", "success").Inc()
+	return &Delivery{Review: &review, msg: msg}, nil
+}
+
+// Ack marks a review done and commits the offsets it no longer holds back
+func (q *Kafka) Ack(ctx context.Context, d *Delivery) error {
+	if err := q.commit(ctx, d.msg.(kafka.Message)); err != nil {
+		metrics.ReviewQueueOperations.WithLabelValues("ack", "error").Inc()
+		return fmt.Errorf("ack review %s: %w", d.Review.Key, err)
+	}
+	metrics.ReviewQueueOperations.WithLabelValues("ack", "success").Inc()
+	return nil
+}
+
+func (q *Kafka) commit(ctx context.Context, msg kafka.Message) error {
+	offset, ok := q.offsets.done(msg.Partition, msg.Offset)
+	if !ok {
+		return nil
+	}
+	msg.Offset = offset
+	return q.reader.CommitMessages(ctx, msg)
+}
+
+// Recover does nothing: the consumer group redelivers reviews after the committed offsets
+func (q *Kafka) Recover(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// Close leaves the consumer group and closes the connections
+func (q *Kafka) Close() error {
+	return errors.Join(q.reader.Close(), q.writer.Close())
+}
+
+// offsetTracker finds the offsets that can be committed when messages finish out of order
+type offsetTracker struct {
+	mu       sync.Mutex
+	running  map[int][]int64        // Fetched offsets not yet committable, ascending, per partition
+	finished map[int]map[int64]bool // Finished offsets among running
+}
+
+func newOffsetTracker() *offsetTracker {
+	return &offsetTracker{running: map[int][]int64{}, finished: map[int]map[int64]bool{}}
+}
+
+// fetched records a message taken from the partition
+func (t *offsetTracker) fetched(partition int, offset int64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.running[partition] = append(t.running[partition], offset)
+}
+
+// done marks a message finished. It returns the highest offset of the partition whose message
+// and all earlier ones are finished, if that advanced.
+func (t *offsetTracker) done(partition int, offset int64) (int64, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.finished[partition] == nil {
+		t.finished[partition] = map[int64]bool{}
+	}
+	t.finished[partition][offset] = true
+
+	var commit int64
+	advanced := false
+	running := t.running[partition]
+	for len(running) > 0 && t.finished[partition][running[0]] {
+		commit = running[0]
+		delete(t.finished[partition], commit)
+		running = running[1:]
+		advanced = true
+	}
+	t.running[partition] = running
+	return commit, advanced
+}
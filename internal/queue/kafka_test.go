@@ -0,0 +1,28 @@
+package queue
+
+import "testing"
+
+func TestOffsetTracker(t *testing.T) {
+	tr := newOffsetTracker()
+	for _, off := range []int64{10, 11, 12} {
+		tr.fetched(0, off)
+	}
+	tr.fetched(1, 5)
+
+	// A later review finishing first must not commit past a running one
+	if off, ok := tr.done(0, 11); ok {
+		t.Fatalf("committed %d while 10 is running", off)
+	}
+	if off, ok := tr.done(1, 5); !ok || off != 5 {
+		t.Errorf("partition 1: got %d, %v; want 5", off, ok)
+	}
+	if off, ok := tr.done(0, 10); !ok || off != 11 {
+		t.Errorf("after 10: got %d, %v; want 11", off, ok)
+	}
+	if off, ok := tr.done(0, 12); !ok || off != 12 {
+		t.Errorf("after 12: got %d, %v; want 12", off, ok)
+	}
+	if len(tr.running[0]) != 0 || len(tr.finished[0]) != 0 {
+		t.Errorf("tracker not emptied: running %v, finished %v", tr.running[0], tr.finished[0])
+	}
+}
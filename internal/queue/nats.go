@@ -0,0 +1,167 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/storage"
+
+	"github.com/nats-io/nats.go"
+	"github.com/nats-io/nats.go/jetstream"
+)
+
+// NATS is a queue of reviews on a JetStream work-queue stream. All workers pull from one durable
+// consumer; a review that is not acknowledged within ack_wait of the last progress report is
+// delivered again.
+type NATS struct {
+	conn     *nats.Conn
+	js       jetstream.JetStream
+	consumer jetstream.Consumer
+	subject  string
+	ackWait  time.Duration
+}
+
+// natsDelivery is a JetStream message whose worker reports progress until it is acknowledged
+type natsDelivery struct {
+	msg  jetstream.Msg
+	stop chan struct{}
+}
+
+// NewNATS connects to the configured server and creates the stream and consumer if missing
+func NewNATS(ctx context.Context, cfg config.NATSQueueConfig) (*NATS, error) {
+	opts := []nats.Option{nats.Name("pr-review-automation")}
+	if cfg.Token != "" {
+		opts = append(opts, nats.Token(cfg.Token))
+	}
+	conn, err := nats.Connect(cfg.URL, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("connect nats %s: %w", cfg.URL, err)
+	}
+	q, err := setupNATS(ctx, conn, cfg)
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+	return q, nil
+}
+
+func setupNATS(ctx context.Context, conn *nats.Conn, cfg config.NATSQueueConfig) (*NATS, error) {
+	js, err := jetstream.New(conn)
+	if err != nil {
+		return nil, fmt.Errorf("jetstream: %w", err)
+	}
+	stream, err := js.CreateOrUpdateStream(ctx, jetstream.StreamConfig{
+		Name:      cfg.Stream,
+		Subjects:  []string{cfg.Subject},
+		Retention: jetstream.WorkQueuePolicy,
+		Storage:   jetstream.FileStorage,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create stream %s: %w", cfg.Stream, err)
+	}
+	consumer, err := stream.CreateOrUpdateConsumer(ctx, jetstream.ConsumerConfig{
+		Durable:       cfg.Consumer,
+		AckPolicy:     jetstream.AckExplicitPolicy,
+		AckWait:       cfg.AckWait,
+		FilterSubject: cfg.Subject,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("create consumer %s: %w", cfg.Consumer, err)
+	}
+	return &NATS{conn: conn, js: js, consumer: consumer, subject: cfg.Subject, ackWait: cfg.AckWait}, nil
+}
+
+// Push queues a review
+func (q *NATS) Push(ctx context.Context, review *storage.QueuedReview) error {
+	if review.CreatedAt.IsZero() {
+		review.CreatedAt = time.Now().UTC()
+	}
+	data, err := json.Marshal(review)
+	if err != nil {
+		return fmt.Errorf("marshal review %s: %w", review.Key, err)
+	}
+	if _, err := q.js.Publish(ctx, q.subject, data); err != nil {
+		metrics.ReviewQueueOperations.WithLabelValues("push", "error").Inc()
+		return fmt.Errorf("push review %s: %w", review.Key, err)
+	}
+	metrics.ReviewQueueOperations.WithLabelValues("push", "success").Inc()
+	return nil
+}
+
+// Pop takes the next review, waiting up to timeout for one. It returns nil when none arrived.
+// Until the review is acknowledged, its worker reports progress so a long review is not
+// redelivered to another worker.
+func (q *NATS) Pop(ctx context.Context, timeout time.Duration) (*Delivery, error) {
+	fetchCtx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	batch, err := q.consumer.Fetch(1, jetstream.FetchContext(fetchCtx))
+	if err != nil {
+		metrics.ReviewQueueOperations.WithLabelValues("pop", "error").Inc()
+		return nil, fmt.Errorf("pop review: %w", err)
+	}
+	msg, ok := <-batch.Messages()
+	if !ok {
+		if err := batch.Error(); err != nil && !errors.Is(err, context.DeadlineExceeded) && !errors.Is(err, nats.ErrTimeout) && ctx.Err() == nil {
+			metrics.ReviewQueueOperations.WithLabelValues("pop", "error").Inc()
+			return nil, fmt.Errorf("pop review: %w", err)
+		}
+		return nil, nil
+	}
+
+	var review storage.QueuedReview
+	if err := json.Unmarshal(msg.Data(), &review); err != nil {
+		// Unreadable messages would be redelivered forever
+		msg.Term()
+		metrics.ReviewQueueOperations.WithLabelValues("pop", "invalid").Inc()
+		return nil, fmt.Errorf("parse queued review: %w", err)
+	}
+	d := &natsDelivery{msg: msg, stop: make(chan struct{})}
+	go q.reportProgress(d)
+	metrics.ReviewQueueOperations.WithLabelValues("pop", "success").Inc()
+	return &Delivery{Review: &review, msg: d}, nil
+}
+
+// reportProgress resets the redelivery timer of d every half ack_wait until it is acknowledged
+func (q *NATS) reportProgress(d *natsDelivery) {
+	ticker := time.NewTicker(q.ackWait / 2)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-d.stop:
+			return
+		case <-ticker.C:
+			if err := d.msg.InProgress(); err != nil {
+				slog.Warn("report review progress failed", "error", err)
+			}
+		}
+	}
+}
+
+// Ack removes a finished review from the stream
+func (q *NATS) Ack(ctx context.Context, d *Delivery) error {
+	nd := d.msg.(*natsDelivery)
+	close(nd.stop)
+	if err := nd.msg.DoubleAck(ctx); err != nil {
+		metrics.ReviewQueueOperations.WithLabelValues("ack", "error").Inc()
+		return fmt.Errorf("ack review %s: %w", d.Review.Key, err)
+	}
+	metrics.ReviewQueueOperations.WithLabelValues("ack", "success").Inc()
+	return nil
+}
+
+// Recover does nothing: JetStream redelivers unacknowledged reviews after ack_wait
+func (q *NATS) Recover(ctx context.Context) (int, error) {
+	return 0, nil
+}
+
+// Close closes the connection. Reviews taken but not acknowledged are redelivered.
+func (q *NATS) Close() error {
+	q.conn.Close()
+	return nil
+}
@@ -0,0 +1,47 @@
+// Package queue keeps queued reviews outside the process (queue.driver), so they survive
+// restarts, several server instances can consume them, and webhook intake and review workers
+// can run as separate deployments (queue.role).
+package queue
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/storage"
+)
+
+// Queue is an external review queue with at-least-once delivery: a review taken with Pop is
+// delivered again unless it is acknowledged.
+type Queue interface {
+	Push(ctx context.Context, review *storage.QueuedReview) error
+	// Pop takes the next review, waiting up to timeout for one. It returns nil when none arrived.
+	Pop(ctx context.Context, timeout time.Duration) (*Delivery, error)
+	Ack(ctx context.Context, d *Delivery) error
+	// Recover queues again the reviews this consumer was running when it stopped, for drivers
+	// that do not redeliver them by themselves. Call it before the first Pop.
+	Recover(ctx context.Context) (int, error)
+	Close() error
+}
+
+// Delivery is a review taken from the queue
+type Delivery struct {
+	Review *storage.QueuedReview
+	raw    string // Redis: the list entry
+	msg    any    // NATS, Kafka: the message to acknowledge
+}
+
+// New connects to the queue of the configured driver
+func New(ctx context.Context, cfg config.QueueConfig) (Queue, error) {
+	switch cfg.Driver {
+	case config.QueueDriverRedis:
+		return NewRedis(ctx, cfg.Redis)
+	case config.QueueDriverNATS:
+		return NewNATS(ctx, cfg.NATS)
+	case config.QueueDriverKafka:
+		return NewKafka(ctx, cfg.Kafka)
+	default:
+		return nil, fmt.Errorf("queue driver %q keeps no external queue", cfg.Driver)
+	}
+}
@@ -0,0 +1,117 @@
+package queue
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/storage"
+
+	"github.com/redis/go-redis/v9"
+)
+
+// Redis is a reliable queue of reviews on a Redis list. Reviews move from the pending list to
+// a per-consumer processing list while they run; a crash queues them again when the consumer
+// restarts.
+type Redis struct {
+	client     *redis.Client
+	pending    string
+	processing string
+}
+
+// NewRedis connects to the configured Redis server
+func NewRedis(ctx context.Context, cfg config.RedisQueueConfig) (*Redis, error) {
+	client := redis.NewClient(&redis.Options{Addr: cfg.Addr, Password: cfg.Password, DB: cfg.DB})
+	if err := client.Ping(ctx).Err(); err != nil {
+		client.Close()
+		return nil, fmt.Errorf("connect redis %s: %w", cfg.Addr, err)
+	}
+	return &Redis{
+		client:     client,
+		pending:    cfg.Key + ":pending",
+		processing: cfg.Key + ":processing:" + cfg.Consumer,
+	}, nil
+}
+
+// Push queues a review
+func (q *Redis) Push(ctx context.Context, review *storage.QueuedReview) error {
+	if review.CreatedAt.IsZero() {
+		review.CreatedAt = time.Now().UTC()
+	}
+	data, err := json.Marshal(review)
+	if err != nil {
+		return fmt.Errorf("marshal review %s: %w", review.Key, err)
+	}
+	if err := q.client.LPush(ctx, q.pending, data).Err(); err != nil {
+		metrics.ReviewQueueOperations.WithLabelValues("push", "error").Inc()
+		return fmt.Errorf("push review %s: %w", review.Key, err)
+	}
+	metrics.ReviewQueueOperations.WithLabelValues("push", "success").Inc()
+	return nil
+}
+
+// Pop takes the oldest review, waiting up to timeout for one. It returns nil when none arrived.
+func (q *Redis) Pop(ctx context.Context, timeout time.Duration) (*Delivery, error) {
+	raw, err := q.client.BLMove(ctx, q.pending, q.processing, "RIGHT", "LEFT", timeout).Result()
+	if errors.Is(err, redis.Nil) {
+		return nil, nil
+	}
+	if err != nil {
+		metrics.ReviewQueueOperations.WithLabelValues("pop", "error").Inc()
+		return nil, fmt.Errorf("pop review: %w", err)
+	}
+	var review storage.QueuedReview
+	if err := json.Unmarshal([]byte(raw), &review); err != nil {
+		// Unreadable entries would be retried forever
+		q.client.LRem(ctx, q.processing, 1, raw)
+		metrics.ReviewQueueOperations.WithLabelValues("pop", "invalid").Inc()
+		return nil, fmt.Errorf("parse queued review: %w", err)
+	}
+	metrics.ReviewQueueOperations.WithLabelValues("pop", "success").Inc()
+	return &Delivery{Review: &review, raw: raw}, nil
+}
+
+// Ack removes a finished review from the processing list
+func (q *Redis) Ack(ctx context.Context, d *Delivery) error {
+	if err := q.client.LRem(ctx, q.processing, 1, d.raw).Err(); err != nil {
+		metrics.ReviewQueueOperations.WithLabelValues("ack", "error").Inc()
+		return fmt.Errorf("ack review %s: %w", d.Review.Key, err)
+	}
+	metrics.ReviewQueueOperations.WithLabelValues("ack", "success").Inc()
+	return nil
+}
+
+// Recover queues again the reviews this consumer was running when it stopped. Call it before
+// the first Pop.
+func (q *Redis) Recover(ctx context.Context) (int, error) {
+	n := 0
+	for {
+		err := q.client.LMove(ctx, q.processing, q.pending, "LEFT", "RIGHT").Err()
+		if errors.Is(err, redis.Nil) {
+			break
+		}
+		if err != nil {
+			return n, fmt.Errorf("recover reviews: %w", err)
+		}
+		n++
+	}
+	if n > 0 {
+		metrics.ReviewQueueOperations.WithLabelValues("recover", "success").Add(float64(n))
+	}
+	return n, nil
+}
+
+// Len returns the number of reviews waiting in the queue
+func (q *Redis) Len(ctx context.Context) (int, error) {
+	n, err := q.client.LLen(ctx, q.pending).Result()
+	return int(n), err
+}
+
+// Close closes the connection
+func (q *Redis) Close() error {
+	return q.client.Close()
+}
@@ -0,0 +1,93 @@
+package queue
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/storage"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func newTestQueue(t *testing.T, addr, consumer string) *Redis {
+	t.Helper()
+	q, err := NewRedis(context.Background(), config.RedisQueueConfig{Addr: addr, Key: "test:queue", Consumer: consumer})
+	if err != nil {
+		t.Fatalf("connect: %v", err)
+	}
+	t.Cleanup(func() { q.Close() })
+	return q
+}
+
+func review(id string) *storage.QueuedReview {
+	return &storage.QueuedReview{Key: "PROJ/repo/" + id, PullRequest: &domain.PullRequest{ID: id, ProjectKey: "PROJ", RepoSlug: "repo"}}
+}
+
+func TestRedis_PushPopAck(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+	q := newTestQueue(t, mr.Addr(), "a")
+
+	for _, id := range []string{"1", "2"} {
+		if err := q.Push(ctx, review(id)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if n, _ := q.Len(ctx); n != 2 {
+		t.Fatalf("Len = %d, want 2", n)
+	}
+
+	d, err := q.Pop(ctx, time.Second)
+	if err != nil || d == nil {
+		t.Fatalf("Pop = %v, %v", d, err)
+	}
+	if d.Review.PullRequest.ID != "1" || d.Review.CreatedAt.IsZero() {
+		t.Errorf("popped %+v, want the oldest review with its queue time", d.Review)
+	}
+	if err := q.Ack(ctx, d); err != nil {
+		t.Fatal(err)
+	}
+	if items, _ := mr.List("test:queue:processing:a"); len(items) != 0 {
+		t.Errorf("acknowledged review still processing: %v", items)
+	}
+
+	if _, err := q.Pop(ctx, time.Second); err != nil {
+		t.Fatal(err)
+	}
+	if d, err := q.Pop(ctx, 100*time.Millisecond); d != nil || err != nil {
+		t.Errorf("Pop on an empty queue = %v, %v", d, err)
+	}
+}
+
+func TestRedis_Recover(t *testing.T) {
+	mr := miniredis.RunT(t)
+	ctx := context.Background()
+	a := newTestQueue(t, mr.Addr(), "a")
+	b := newTestQueue(t, mr.Addr(), "b")
+
+	for _, id := range []string{"1", "2", "3"} {
+		a.Push(ctx, review(id))
+	}
+	// Consumer a stops while running reviews 1 and 2; b runs 3
+	a.Pop(ctx, time.Second)
+	a.Pop(ctx, time.Second)
+	b.Pop(ctx, time.Second)
+
+	restarted := newTestQueue(t, mr.Addr(), "a")
+	n, err := restarted.Recover(ctx)
+	if err != nil || n != 2 {
+		t.Fatalf("Recover = %d, %v", n, err)
+	}
+	for _, want := range []string{"1", "2"} {
+		d, err := restarted.Pop(ctx, time.Second)
+		if err != nil || d == nil || d.Review.PullRequest.ID != want {
+			t.Fatalf("Pop = %+v, %v, want review %s", d, err, want)
+		}
+	}
+	if items, _ := mr.List("test:queue:processing:b"); len(items) != 1 {
+		t.Errorf("reviews of consumer b recovered: %v", items)
+	}
+}
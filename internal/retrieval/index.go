@@ -0,0 +1,109 @@
+package retrieval
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"sort"
+	"time"
+)
+
+// KindCode marks snippets of source code; documentation snippets have no kind
+const KindCode = "code"
+
+// Snippet is an indexed chunk of a document
+type Snippet struct {
+	Source string    `json:"source"`
+	Path   string    `json:"path"`
+	Kind   string    `json:"kind,omitempty"`
+	Text   string    `json:"text"`
+	Hash   string    `json:"hash"` // Of Path and Text; unchanged chunks keep their embedding on refresh
+	Vector []float64 `json:"vector"`
+}
+
+// Match is a snippet found for a query
+type Match struct {
+	Snippet
+	Score float64 // Cosine similarity with the query
+}
+
+// index is the local vector store: every snippet with its embedding, kept in memory and
+// saved as one JSON file
+type index struct {
+	Model     string    `json:"model"` // Embedding model; an index of another model is rebuilt
+	UpdatedAt time.Time `json:"updatedAt"`
+	Snippets  []Snippet `json:"snippets"`
+}
+
+// loadIndex reads the index file; a missing file is an empty index
+func loadIndex(path string) (*index, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return &index{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("read index: %w", err)
+	}
+	var idx index
+	if err := json.Unmarshal(data, &idx); err != nil {
+		return nil, fmt.Errorf("parse index %s: %w", path, err)
+	}
+	return &idx, nil
+}
+
+// save writes the index atomically, so a crash never leaves a truncated file
+func (idx *index) save(path string) error {
+	data, err := json.Marshal(idx)
+	if err != nil {
+		return fmt.Errorf("marshal index: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		return fmt.Errorf("create index dir: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o640); err != nil {
+		return fmt.Errorf("write index: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("replace index: %w", err)
+	}
+	return nil
+}
+
+// search returns the k snippets most similar to vector with a score of at least minScore,
+// best first, from the sources accepted by use
+func (idx *index) search(vector []float64, k int, minScore float64, use func(source string) bool) []Match {
+	var matches []Match
+	for _, s := range idx.Snippets {
+		if !use(s.Source) {
+			continue
+		}
+		if score := cosine(vector, s.Vector); score >= minScore {
+			matches = append(matches, Match{Snippet: s, Score: score})
+		}
+	}
+	sort.SliceStable(matches, func(i, j int) bool { return matches[i].Score > matches[j].Score })
+	if len(matches) > k {
+		matches = matches[:k]
+	}
+	return matches
+}
+
+// cosine returns the cosine similarity of two vectors, 0 when their lengths differ
+func cosine(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, na, nb float64
+	for i := range a {
+		dot += a[i] * b[i]
+		na += a[i] * a[i]
+		nb += b[i] * b[i]
+	}
+	if na == 0 || nb == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(na) * math.Sqrt(nb))
+}
@@ -0,0 +1,232 @@
+// Package retrieval indexes documentation and repository code into a local vector store and
+// finds the snippets relevant to a review.
+package retrieval
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/rules"
+)
+
+const (
+	embedBatchSize = 32  // Snippets per embeddings request
+	minChunkSize   = 200 // Smaller chunk_size values are raised to this
+	defaultTopK    = 4
+)
+
+// Embedder turns texts into embedding vectors
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// Retriever keeps the documentation index current and searches it
+type Retriever struct {
+	cfg      config.RetrievalConfig
+	embedder Embedder
+	sources  []source
+
+	refreshMu sync.Mutex // Serializes refreshes
+	mu        sync.RWMutex
+	index     *index
+}
+
+// New creates a retriever for the configured sources. tools reads Confluence sources and may be
+// nil without them. Load reads a previously saved index.
+func New(cfg config.RetrievalConfig, embedder Embedder, tools ToolInvoker) *Retriever {
+	if cfg.ChunkSize < minChunkSize {
+		cfg.ChunkSize = minChunkSize
+	}
+	if cfg.TopK <= 0 {
+		cfg.TopK = defaultTopK
+	}
+	r := &Retriever{cfg: cfg, embedder: embedder, index: &index{}}
+	for _, src := range cfg.Sources {
+		r.sources = append(r.sources, newSource(src, tools))
+	}
+	return r
+}
+
+// Load reads the saved index, so reviews use it before the first refresh completes.
+// An index built with another embedding model is ignored.
+func (r *Retriever) Load() error {
+	idx, err := loadIndex(r.cfg.IndexPath)
+	if err != nil {
+		return err
+	}
+	if idx.Model != r.cfg.Embedding.Model {
+		return nil
+	}
+	r.swap(idx)
+	slog.Info("documentation index loaded", "snippets", len(idx.Snippets), "updated_at", idx.UpdatedAt)
+	return nil
+}
+
+// Run refreshes the index immediately and then on every refresh interval until ctx is done
+func (r *Retriever) Run(ctx context.Context) {
+	interval := r.cfg.RefreshInterval
+	if interval <= 0 {
+		interval = 6 * time.Hour
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := r.Refresh(ctx); err != nil && ctx.Err() == nil {
+			slog.Warn("documentation index refresh failed", "error", err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// Refresh re-reads all sources and embeds the snippets that changed. A source that cannot be
+// read keeps its previous snippets; when embedding fails the previous index stays in use.
+func (r *Retriever) Refresh(ctx context.Context) error {
+	r.refreshMu.Lock()
+	defer r.refreshMu.Unlock()
+
+	r.mu.RLock()
+	old := r.index
+	r.mu.RUnlock()
+	known := make(map[string][]float64)
+	if old.Model == r.cfg.Embedding.Model {
+		for _, s := range old.Snippets {
+			known[s.Hash] = s.Vector
+		}
+	}
+
+	var snippets []Snippet
+	var errs []error
+	for _, src := range r.sources {
+		docs, err := src.documents(ctx)
+		if err != nil {
+			slog.Warn("read documentation source failed, keeping its snippets", "source", src.name, "error", err)
+			for _, s := range old.Snippets {
+				if s.Source == src.name {
+					snippets = append(snippets, s)
+				}
+			}
+			errs = append(errs, fmt.Errorf("source %s: %w", src.name, err))
+			continue
+		}
+		for _, d := range docs {
+			for _, text := range split(d.Text, r.cfg.ChunkSize) {
+				snippets = append(snippets, Snippet{Source: src.name, Path: d.Path, Kind: src.kind(), Text: text, Hash: hash(d.Path, text)})
+			}
+		}
+	}
+
+	var pending []int
+	for i := range snippets {
+		if v, ok := known[snippets[i].Hash]; ok {
+			snippets[i].Vector = v
+		} else {
+			pending = append(pending, i)
+		}
+	}
+	for start := 0; start < len(pending); start += embedBatchSize {
+		batch := pending[start:min(start+embedBatchSize, len(pending))]
+		texts := make([]string, len(batch))
+		for j, i := range batch {
+			texts[j] = embedText(snippets[i].Path, snippets[i].Text)
+		}
+		vectors, err := r.embedder.Embed(ctx, texts)
+		if err == nil && len(vectors) != len(texts) {
+			err = fmt.Errorf("%d embeddings for %d snippets", len(vectors), len(texts))
+		}
+		if err != nil {
+			return errors.Join(append(errs, fmt.Errorf("embed snippets: %w", err))...)
+		}
+		for j, i := range batch {
+			snippets[i].Vector = vectors[j]
+		}
+	}
+
+	idx := &index{Model: r.cfg.Embedding.Model, UpdatedAt: time.Now().UTC(), Snippets: snippets}
+	r.swap(idx)
+	slog.Info("documentation indexed", "snippets", len(snippets), "embedded", len(pending))
+	if err := idx.save(r.cfg.IndexPath); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// Search returns the top_k snippets most relevant to query from the sources used for repo
+// ("PROJECT/repo"), with the repository's overrides applied
+func (r *Retriever) Search(ctx context.Context, repo, query string) ([]Match, error) {
+	r.mu.RLock()
+	idx := r.index
+	r.mu.RUnlock()
+	topK, minScore, enabled := r.settings(repo)
+	if len(idx.Snippets) == 0 || !enabled {
+		return nil, nil
+	}
+
+	use := make(map[string]bool, len(r.sources))
+	for _, src := range r.sources {
+		use[src.name] = use[src.name] || src.appliesTo(repo)
+	}
+	vectors, err := r.embedder.Embed(ctx, []string{query})
+	if err == nil && len(vectors) != 1 {
+		err = fmt.Errorf("%d embeddings for 1 query", len(vectors))
+	}
+	if err != nil {
+		metrics.RetrievalQueries.WithLabelValues("error").Inc()
+		return nil, fmt.Errorf("embed query: %w", err)
+	}
+	matches := idx.search(vectors[0], topK, minScore, func(source string) bool { return use[source] })
+	if len(matches) == 0 {
+		metrics.RetrievalQueries.WithLabelValues("miss").Inc()
+	} else {
+		metrics.RetrievalQueries.WithLabelValues("hit").Inc()
+	}
+	return matches, nil
+}
+
+// settings returns the top_k, min_score and whether retrieval is on for the reviews of repo:
+// those of the first override matching it, or the configured ones
+func (r *Retriever) settings(repo string) (int, float64, bool) {
+	topK, minScore := r.cfg.TopK, r.cfg.MinScore
+	for _, o := range r.cfg.Overrides {
+		if !rules.MatchAny(o.Repos, repo) {
+			continue
+		}
+		if o.TopK > 0 {
+			topK = o.TopK
+		}
+		if o.MinScore > 0 {
+			minScore = o.MinScore
+		}
+		return topK, minScore, o.Enabled == nil || *o.Enabled
+	}
+	return topK, minScore, true
+}
+
+func (r *Retriever) swap(idx *index) {
+	r.mu.Lock()
+	r.index = idx
+	r.mu.Unlock()
+	metrics.RetrievalSnippets.Set(float64(len(idx.Snippets)))
+}
+
+// embedText is the text embedded for a snippet; the path adds the document's topic
+func embedText(path, text string) string {
+	return path + "\n\n" + text
+}
+
+func hash(path, text string) string {
+	sum := sha256.Sum256([]byte(embedText(path, text)))
+	return hex.EncodeToString(sum[:])
+}
@@ -0,0 +1,231 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"pr-review-automation/internal/config"
+)
+
+// wordEmbedder embeds a text as the counts of a few words, so similar topics score high
+type wordEmbedder struct {
+	embedded int
+	err      error
+}
+
+var vocabulary = []string{"retry", "timeout", "database", "migration", "logging"}
+
+func (e *wordEmbedder) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	if e.err != nil {
+		return nil, e.err
+	}
+	vectors := make([][]float64, len(texts))
+	for i, text := range texts {
+		v := make([]float64, len(vocabulary))
+		for j, w := range vocabulary {
+			v[j] = float64(strings.Count(strings.ToLower(text), w))
+		}
+		vectors[i] = v
+	}
+	e.embedded += len(texts)
+	return vectors, nil
+}
+
+// fakeTools answers the Confluence search with a fixed MCP result
+type fakeTools struct {
+	result any
+	err    error
+	args   map[string]interface{}
+}
+
+func (f *fakeTools) CallTool(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error) {
+	f.args = args
+	return f.result, f.err
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0o750); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0o640); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func testConfig(t *testing.T, sources ...config.RetrievalSource) config.RetrievalConfig {
+	t.Helper()
+	return config.RetrievalConfig{
+		Enabled:   true,
+		Embedding: config.LLMTarget{Model: "nomic-embed-text"},
+		IndexPath: filepath.Join(t.TempDir(), "index.json"),
+		TopK:      2,
+		MinScore:  0.3,
+		ChunkSize: 1500,
+		Sources:   sources,
+	}
+}
+
+func TestRetriever_RefreshAndSearch(t *testing.T) {
+	docs := t.TempDir()
+	writeFile(t, filepath.Join(docs, "http.md"), "# HTTP clients\n\nEvery retry uses backoff. A retry after a timeout is capped at 3.")
+	writeFile(t, filepath.Join(docs, "db/migrations.md"), "# Database\n\nEach database migration must be reversible.")
+	writeFile(t, filepath.Join(docs, "notes.go"), "package notes // retry timeout")
+	writeFile(t, filepath.Join(docs, ".git/HEAD.md"), "retry retry retry")
+
+	embedder := &wordEmbedder{}
+	r := New(testConfig(t, config.RetrievalSource{Name: "guides", Type: config.RetrievalSourceDir, Path: docs}), embedder, nil)
+	ctx := context.Background()
+	if err := r.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	matches, err := r.Search(ctx, "PAY/api", "Raise the retry limit on timeout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Path != "http.md" || matches[0].Source != "guides" {
+		t.Fatalf("matches = %+v", matches)
+	}
+
+	// Unchanged snippets keep their embedding
+	embedder.embedded = 0
+	writeFile(t, filepath.Join(docs, "logging.md"), "Use structured logging.")
+	if err := r.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if embedder.embedded != 1 {
+		t.Errorf("embedded %d snippets on refresh, want only the new one", embedder.embedded)
+	}
+
+	// A restart reuses the saved index
+	reloaded := New(r.cfg, &wordEmbedder{}, nil)
+	if err := reloaded.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if matches, _ := reloaded.Search(ctx, "PAY/api", "database migration"); len(matches) != 1 || matches[0].Path != "db/migrations.md" {
+		t.Errorf("matches after reload = %+v", matches)
+	}
+
+	// An index of another embedding model is not used
+	cfg := r.cfg
+	cfg.Embedding.Model = "text-embedding-3-small"
+	other := New(cfg, &wordEmbedder{}, nil)
+	if err := other.Load(); err != nil {
+		t.Fatal(err)
+	}
+	if matches, _ := other.Search(ctx, "PAY/api", "database migration"); len(matches) != 0 {
+		t.Errorf("index of another model used: %+v", matches)
+	}
+}
+
+func TestRetriever_SourceRepos(t *testing.T) {
+	payDocs, coreDocs := t.TempDir(), t.TempDir()
+	writeFile(t, filepath.Join(payDocs, "retry.md"), "PAY retry policy")
+	writeFile(t, filepath.Join(coreDocs, "retry.md"), "CORE retry policy")
+
+	r := New(testConfig(t,
+		config.RetrievalSource{Type: config.RetrievalSourceDir, Path: payDocs, Repos: []string{"PAY/*"}},
+		config.RetrievalSource{Type: config.RetrievalSourceDir, Path: coreDocs, Repos: []string{"CORE/*"}},
+	), &wordEmbedder{}, nil)
+	ctx := context.Background()
+	if err := r.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	matches, err := r.Search(ctx, "CORE/api", "retry")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Text != "CORE retry policy" {
+		t.Errorf("matches = %+v", matches)
+	}
+}
+
+func TestRetriever_CodeSourceAndOverrides(t *testing.T) {
+	repo := t.TempDir()
+	writeFile(t, filepath.Join(repo, "client/retry.go"), "package client\n\n// Backoff doubles the retry delay")
+	writeFile(t, filepath.Join(repo, "vendor/lib/retry.go"), "package lib // retry delay")
+	writeFile(t, filepath.Join(repo, "README.md"), "retry delay")
+
+	off := false
+	cfg := testConfig(t, config.RetrievalSource{Name: "api", Type: config.RetrievalSourceCode, Path: repo})
+	cfg.Overrides = []config.RetrievalOverride{
+		{Repos: []string{"LEGACY/*"}, Enabled: &off},
+		{Repos: []string{"PAY/*"}, MinScore: 0.99},
+	}
+	r := New(cfg, &wordEmbedder{}, nil)
+	ctx := context.Background()
+	if err := r.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+
+	matches, err := r.Search(ctx, "CORE/api", "retry on timeout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Path != "client/retry.go" || matches[0].Kind != KindCode {
+		t.Errorf("matches = %+v", matches)
+	}
+	for _, repo := range []string{"LEGACY/app", "PAY/api"} {
+		if matches, _ := r.Search(ctx, repo, "retry on timeout"); len(matches) != 0 {
+			t.Errorf("%s: override ignored, matches = %+v", repo, matches)
+		}
+	}
+}
+
+func TestRetriever_FailedSourceKeepsSnippets(t *testing.T) {
+	tools := &fakeTools{result: map[string]any{
+		"content": []any{map[string]any{"type": "text", "text": `[
+			{"title": "Timeouts", "content": {"value": "<p>Every call sets a timeout &amp; a retry budget.</p>"}},
+			{"title": "Empty"}
+		]`}},
+	}}
+	r := New(testConfig(t, config.RetrievalSource{Type: config.RetrievalSourceConfluence, Space: "ENG"}), &wordEmbedder{}, tools)
+	ctx := context.Background()
+	if err := r.Refresh(ctx); err != nil {
+		t.Fatal(err)
+	}
+	if q := tools.args["query"]; q != `space = "ENG" AND type = page` {
+		t.Errorf("query = %v", q)
+	}
+
+	tools.err = errors.New("confluence unavailable")
+	if err := r.Refresh(ctx); err == nil {
+		t.Error("failed source not reported")
+	}
+	matches, err := r.Search(ctx, "PAY/api", "timeout")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(matches) != 1 || matches[0].Path != "Timeouts" || matches[0].Text != "Every call sets a timeout & a retry budget." {
+		t.Errorf("matches = %+v", matches)
+	}
+}
+
+func TestSplit(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		size int
+		want []string
+	}{
+		{name: "short", text: "one\n\ntwo", size: 20, want: []string{"one\n\ntwo"}},
+		{name: "paragraphs", text: "aaaa\n\nbbbb\n\ncccc", size: 10, want: []string{"aaaa\n\nbbbb", "cccc"}},
+		{name: "long paragraph", text: "aaaa\nbbbb\ncccc", size: 9, want: []string{"aaaa\nbbbb", "cccc"}},
+		{name: "long line", text: "abcdefghij", size: 4, want: []string{"abcd", "efgh", "ij"}},
+		{name: "multibyte", text: "ääää", size: 3, want: []string{"ä", "ä", "ä", "ä"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := split(tt.text, tt.size)
+			if strings.Join(got, "|") != strings.Join(tt.want, "|") {
+				t.Errorf("split = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,252 @@
+package retrieval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/rules"
+
+	"github.com/tidwall/gjson"
+)
+
+// defaultIncludes are the files of a dir source indexed when include is not set
+var defaultIncludes = []string{"*.md", "*.txt", "*.rst", "*.adoc"}
+
+// defaultCodeIncludes are the files of a code source indexed when include is not set
+var defaultCodeIncludes = []string{
+	"*.go", "*.py", "*.java", "*.kt", "*.scala", "*.js", "*.jsx", "*.ts", "*.tsx", "*.rs", "*.rb",
+	"*.php", "*.cs", "*.c", "*.h", "*.cc", "*.cpp", "*.hpp", "*.swift", "*.sql", "*.proto",
+}
+
+// vendoredDirs are not indexed by code sources; their code is not the project's own
+var vendoredDirs = map[string]bool{"vendor": true, "node_modules": true, "third_party": true}
+
+const (
+	defaultConfluenceLimit = 50
+	maxDocumentSize        = 1 << 20 // Larger files are skipped, they are rarely prose
+)
+
+var htmlTag = regexp.MustCompile(`<[^>]+>`)
+
+// ToolInvoker calls MCP tools; the Confluence source searches pages through it
+type ToolInvoker interface {
+	CallTool(ctx context.Context, serverName, toolName string, args map[string]interface{}) (any, error)
+}
+
+// Document is one file or page of a documentation source
+type Document struct {
+	Path string // File path relative to the source directory, or the page title
+	Text string
+}
+
+// source reads the documents of one configured documentation source
+type source struct {
+	cfg   config.RetrievalSource
+	name  string
+	tools ToolInvoker
+}
+
+func newSource(cfg config.RetrievalSource, tools ToolInvoker) source {
+	name := cfg.Name
+	if name == "" {
+		name = cfg.Path
+		if cfg.Type == config.RetrievalSourceConfluence {
+			name = cfg.Space
+		}
+	}
+	return source{cfg: cfg, name: name, tools: tools}
+}
+
+// appliesTo reports whether the source is used for reviews of repo ("PROJECT/repo")
+func (s source) appliesTo(repo string) bool {
+	return rules.MatchAny(s.cfg.Repos, repo)
+}
+
+// documents reads all documents of the source
+func (s source) documents(ctx context.Context) ([]Document, error) {
+	switch s.cfg.Type {
+	case config.RetrievalSourceDir, config.RetrievalSourceCode:
+		return s.dirDocuments()
+	case config.RetrievalSourceConfluence:
+		return s.confluenceDocuments(ctx)
+	default:
+		return nil, fmt.Errorf("unknown source type %q", s.cfg.Type)
+	}
+}
+
+// kind is the Snippet.Kind of the source's snippets
+func (s source) kind() string {
+	if s.cfg.Type == config.RetrievalSourceCode {
+		return KindCode
+	}
+	return ""
+}
+
+// dirDocuments reads the matching text files under the source directory
+func (s source) dirDocuments() ([]Document, error) {
+	includes := s.cfg.Include
+	if len(includes) == 0 {
+		includes = defaultIncludes
+		if s.cfg.Type == config.RetrievalSourceCode {
+			includes = defaultCodeIncludes
+		}
+	}
+
+	var docs []Document
+	err := filepath.WalkDir(s.cfg.Path, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != s.cfg.Path && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			if s.cfg.Type == config.RetrievalSourceCode && vendoredDirs[d.Name()] {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(s.cfg.Path, path)
+		if err != nil {
+			return err
+		}
+		rel = filepath.ToSlash(rel)
+		if !rules.MatchAny(includes, rel) {
+			return nil
+		}
+		if info, err := d.Info(); err != nil || info.Size() > maxDocumentSize {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		docs = append(docs, Document{Path: rel, Text: string(data)})
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("read %s: %w", s.cfg.Path, err)
+	}
+	return docs, nil
+}
+
+// confluenceDocuments searches the pages of the space through the Confluence MCP server.
+// The result is the page list itself or an MCP result wrapping it as text content.
+func (s source) confluenceDocuments(ctx context.Context) ([]Document, error) {
+	if s.tools == nil {
+		return nil, fmt.Errorf("confluence source %s: no MCP client", s.name)
+	}
+	tool := s.cfg.Tool
+	if tool == "" {
+		tool = config.ToolConfluenceSearch
+	}
+	limit := s.cfg.Limit
+	if limit <= 0 {
+		limit = defaultConfluenceLimit
+	}
+
+	result, err := s.tools.CallTool(ctx, config.MCPServerConfluence, tool, map[string]interface{}{
+		"query": fmt.Sprintf("space = %q AND type = page", s.cfg.Space),
+		"limit": limit,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("search confluence space %s: %w", s.cfg.Space, err)
+	}
+
+	var raw string
+	if str, ok := result.(string); ok {
+		raw = str
+	} else {
+		b, err := json.Marshal(result)
+		if err != nil {
+			return nil, fmt.Errorf("search confluence space %s: %w", s.cfg.Space, err)
+		}
+		raw = string(b)
+	}
+	if text := gjson.Get(raw, "content.0.text").String(); text != "" {
+		raw = text
+	}
+	pages := gjson.Parse(raw)
+	if !pages.IsArray() {
+		pages = gjson.Get(raw, "results")
+	}
+
+	var docs []Document
+	for _, page := range pages.Array() {
+		text := firstString(page, "content.value", "body.storage.value", "body.view.value", "content", "excerpt")
+		if text == "" {
+			continue
+		}
+		docs = append(docs, Document{
+			Path: firstString(page, "title", "url", "id"),
+			Text: strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(text, " "))),
+		})
+	}
+	return docs, nil
+}
+
+// firstString returns the first non-empty string value of the paths
+func firstString(v gjson.Result, paths ...string) string {
+	for _, p := range paths {
+		if r := v.Get(p); r.Type == gjson.String && r.String() != "" {
+			return r.String()
+		}
+	}
+	return ""
+}
+
+// split cuts text into chunks of at most size characters at paragraph, then line boundaries
+func split(text string, size int) []string {
+	var chunks []string
+	var cur strings.Builder
+	flush := func() {
+		if s := strings.TrimSpace(cur.String()); s != "" {
+			chunks = append(chunks, s)
+		}
+		cur.Reset()
+	}
+	add := func(part, sep string) {
+		if cur.Len() > 0 && cur.Len()+len(sep)+len(part) > size {
+			flush()
+		}
+		if cur.Len() > 0 {
+			cur.WriteString(sep)
+		}
+		cur.WriteString(part)
+	}
+
+	for _, para := range strings.Split(text, "\n\n") {
+		if strings.TrimSpace(para) == "" {
+			continue
+		}
+		if len(para) <= size {
+			add(para, "\n\n")
+			continue
+		}
+		for _, line := range strings.Split(para, "\n") {
+			for len(line) > size {
+				cut := size
+				for cut > 0 && !isRuneStart(line[cut]) {
+					cut--
+				}
+				add(line[:cut], "\n")
+				line = line[cut:]
+			}
+			add(line, "\n")
+		}
+	}
+	flush()
+	return chunks
+}
+
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
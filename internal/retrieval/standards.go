@@ -0,0 +1,157 @@
+package retrieval
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"html"
+	"log/slog"
+	"strings"
+	"sync"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/rules"
+
+	"github.com/tidwall/gjson"
+)
+
+// Standards provides the team coding standards of a repository from Confluence pages
+// (pipeline.standards). Pages are fetched through the Confluence MCP server and cached.
+type Standards struct {
+	cfg   config.StandardsConfig
+	tools ToolInvoker
+	now   func() time.Time
+
+	mu    sync.Mutex
+	pages map[string]standardsPage
+}
+
+// standardsPage is a cached page
+type standardsPage struct {
+	title     string
+	text      string
+	fetchedAt time.Time
+}
+
+// NewStandards creates the standards provider
+func NewStandards(cfg config.StandardsConfig, tools ToolInvoker) *Standards {
+	if cfg.Tool == "" {
+		cfg.Tool = config.ToolConfluenceGetPage
+	}
+	return &Standards{cfg: cfg, tools: tools, now: time.Now, pages: make(map[string]standardsPage)}
+}
+
+// For returns the standards text for the reviews of repo ("PROJECT/repo"), or "" when no
+// mapping matches. A page that cannot be fetched is served from the cache when it was fetched
+// before, and left out otherwise.
+func (s *Standards) For(ctx context.Context, repo string) string {
+	var pages []string
+	for _, m := range s.cfg.Projects {
+		if rules.MatchAny(m.Repos, repo) {
+			pages = m.Pages
+			break
+		}
+	}
+
+	var b strings.Builder
+	for _, id := range pages {
+		p, ok := s.page(ctx, id)
+		if !ok {
+			continue
+		}
+		if b.Len() > 0 {
+			b.WriteString("\n\n")
+		}
+		fmt.Fprintf(&b, "### %s\n\n%s", p.title, p.text)
+	}
+	text := b.String()
+	if s.cfg.MaxChars > 0 && len(text) > s.cfg.MaxChars {
+		slog.Warn("team standards truncated", "repo", repo, "chars", len(text), "max_chars", s.cfg.MaxChars)
+		text = strings.ToValidUTF8(text[:s.cfg.MaxChars], "")
+	}
+	return text
+}
+
+// page returns a page from the cache while it is fresh, and fetches it otherwise
+func (s *Standards) page(ctx context.Context, id string) (standardsPage, bool) {
+	s.mu.Lock()
+	cached, ok := s.pages[id]
+	s.mu.Unlock()
+	if ok && s.now().Sub(cached.fetchedAt) < s.cfg.CacheTTL {
+		metrics.StandardsPages.WithLabelValues("cached").Inc()
+		return cached, true
+	}
+
+	p, err := s.fetch(ctx, id)
+	if err != nil {
+		if ok {
+			slog.Warn("fetch standards page failed, using cached copy", "page_id", id, "error", err)
+			metrics.StandardsPages.WithLabelValues("stale").Inc()
+			return cached, true
+		}
+		slog.Warn("fetch standards page failed", "page_id", id, "error", err)
+		metrics.StandardsPages.WithLabelValues("error").Inc()
+		return standardsPage{}, false
+	}
+	s.mu.Lock()
+	s.pages[id] = p
+	s.mu.Unlock()
+	metrics.StandardsPages.WithLabelValues("fetched").Inc()
+	return p, true
+}
+
+// fetch reads a page through the Confluence MCP server. The result is the page itself or an
+// MCP result wrapping it as text content; the body may be storage-format HTML or plain text.
+func (s *Standards) fetch(ctx context.Context, id string) (standardsPage, error) {
+	if s.tools == nil {
+		return standardsPage{}, fmt.Errorf("no MCP client")
+	}
+	result, err := s.tools.CallTool(ctx, config.MCPServerConfluence, s.cfg.Tool, map[string]interface{}{
+		"page_id": id,
+	})
+	if err != nil {
+		return standardsPage{}, err
+	}
+
+	var raw string
+	if str, ok := result.(string); ok {
+		raw = str
+	} else {
+		b, err := json.Marshal(result)
+		if err != nil {
+			return standardsPage{}, err
+		}
+		raw = string(b)
+	}
+	if text := gjson.Get(raw, "content.0.text").String(); text != "" {
+		raw = text
+	}
+
+	page := gjson.Parse(raw)
+	if !page.IsObject() {
+		// A plain-text page body
+		raw = strings.TrimSpace(raw)
+		if raw == "" {
+			return standardsPage{}, fmt.Errorf("empty page")
+		}
+		return standardsPage{title: "Page " + id, text: raw, fetchedAt: s.now()}, nil
+	}
+	if p := page.Get("metadata"); p.IsObject() {
+		page = p // Some servers wrap the page in a metadata object
+	}
+	text := firstString(page, "content.value", "body.storage.value", "body.view.value", "content", "body")
+	if text == "" {
+		return standardsPage{}, fmt.Errorf("page has no content")
+	}
+	title := firstString(page, "title")
+	if title == "" {
+		title = "Page " + id
+	}
+	return standardsPage{
+		title:     title,
+		text:      strings.TrimSpace(html.UnescapeString(htmlTag.ReplaceAllString(text, " "))),
+		fetchedAt: s.now(),
+	}, nil
+}
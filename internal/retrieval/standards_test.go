@@ -0,0 +1,61 @@
+package retrieval
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+)
+
+func TestStandards_CachesPagesPerProject(t *testing.T) {
+	tools := &fakeTools{result: map[string]any{
+		"content": []any{map[string]any{"type": "text", "text": `{"metadata": {"title": "Go Style", "content": {"value": "<p>Wrap errors with %w &amp; context.</p>"}}}`}},
+	}}
+	s := NewStandards(config.StandardsConfig{
+		CacheTTL: time.Hour,
+		MaxChars: 1000,
+		Projects: []config.StandardsMapping{{Repos: []string{"PAY/*"}, Pages: []string{"42"}}},
+	}, tools)
+	now := time.Now()
+	s.now = func() time.Time { return now }
+	ctx := context.Background()
+
+	if got := s.For(ctx, "WEB/site"); got != "" {
+		t.Errorf("unmapped repository got %q", got)
+	}
+	got := s.For(ctx, "PAY/api")
+	if want := "### Go Style\n\nWrap errors with %w & context."; got != want {
+		t.Errorf("standards = %q, want %q", got, want)
+	}
+	if id := tools.args["page_id"]; id != "42" {
+		t.Errorf("page_id = %v", id)
+	}
+
+	// Within the TTL the cached copy is used; after it, a failed fetch keeps the stale copy
+	tools.args = nil
+	s.For(ctx, "PAY/api")
+	if tools.args != nil {
+		t.Error("fresh page fetched again")
+	}
+	now = now.Add(2 * time.Hour)
+	tools.err = errors.New("confluence unavailable")
+	if got := s.For(ctx, "PAY/api"); !strings.Contains(got, "Wrap errors") {
+		t.Errorf("stale page not served: %q", got)
+	}
+}
+
+func TestStandards_TruncatesToMaxChars(t *testing.T) {
+	tools := &fakeTools{result: strings.Repeat("Use small functions. ", 20)}
+	s := NewStandards(config.StandardsConfig{
+		CacheTTL: time.Hour,
+		MaxChars: 50,
+		Projects: []config.StandardsMapping{{Repos: []string{}, Pages: []string{"7"}}},
+	}, tools)
+	got := s.For(context.Background(), "PAY/api")
+	if len(got) != 50 || !strings.HasPrefix(got, "### Page 7\n\nUse small functions.") {
+		t.Errorf("standards = %q", got)
+	}
+}
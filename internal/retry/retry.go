@@ -0,0 +1,108 @@
+// Package retry runs operations with exponential backoff and jitter, so every retry loop in
+// the service follows the same policy format and reports the same metrics.
+package retry
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"math/rand/v2"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/types"
+)
+
+// Classifier reports whether a failed attempt is worth retrying
+type Classifier func(error) bool
+
+// permanentError marks an error that no retry can fix
+type permanentError struct {
+	err error
+}
+
+func (e *permanentError) Error() string { return e.err.Error() }
+
+func (e *permanentError) Unwrap() error { return e.err }
+
+// Permanent wraps err so that Do returns it without retrying, whatever the classifier says
+func Permanent(err error) error {
+	if err == nil {
+		return nil
+	}
+	return &permanentError{err: err}
+}
+
+// Transient reports whether err is a transient failure: a RetryableError from an adapter
+// (rate limits, server errors) or a timeout of the attempt itself
+func Transient(err error) bool {
+	var retryErr *types.RetryableError
+	return errors.As(err, &retryErr) || errors.Is(err, context.DeadlineExceeded)
+}
+
+// Do calls fn until it succeeds, the policy's attempts are used up, its max elapsed time would
+// be exceeded, retryable rejects the error or ctx is done, and returns the last error.
+// attempt is 0 for the first call. A nil retryable retries every error. op names the
+// operation in logs and metrics.
+func Do(ctx context.Context, op string, policy config.RetryConfig, retryable Classifier, fn func(attempt int) error) error {
+	attempts := max(policy.Attempts, 1)
+	start := time.Now()
+
+	for attempt := 0; ; attempt++ {
+		err := fn(attempt)
+		if err == nil {
+			metrics.RetryCalls.WithLabelValues(op, "success").Inc()
+			return nil
+		}
+
+		var permanent *permanentError
+		switch {
+		case ctx.Err() != nil:
+			metrics.RetryCalls.WithLabelValues(op, "cancelled").Inc()
+			return err
+		case errors.As(err, &permanent):
+			metrics.RetryCalls.WithLabelValues(op, "permanent").Inc()
+			return permanent.err
+		case retryable != nil && !retryable(err):
+			metrics.RetryCalls.WithLabelValues(op, "permanent").Inc()
+			return err
+		}
+
+		wait := delay(policy, attempt+1)
+		if attempt+1 >= attempts || (policy.MaxElapsed > 0 && time.Since(start)+wait > policy.MaxElapsed) {
+			metrics.RetryCalls.WithLabelValues(op, "exhausted").Inc()
+			return err
+		}
+
+		slog.Warn("retrying", "operation", op, "attempt", attempt+2, "max", attempts, "delay", wait, "error", err)
+		metrics.RetryAttempts.WithLabelValues(op).Inc()
+		timer := time.NewTimer(wait)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			metrics.RetryCalls.WithLabelValues(op, "cancelled").Inc()
+			return err
+		case <-timer.C:
+		}
+	}
+}
+
+// delay returns the wait before retry n (1 for the first retry): Backoff doubled n-1 times,
+// capped at MaxBackoff, then randomized by ±Jitter
+func delay(policy config.RetryConfig, n int) time.Duration {
+	d := policy.Backoff
+	for i := 1; i < n && d > 0; i++ {
+		if policy.MaxBackoff > 0 && d >= policy.MaxBackoff {
+			break
+		}
+		d *= 2
+	}
+	if policy.MaxBackoff > 0 && d > policy.MaxBackoff {
+		d = policy.MaxBackoff
+	}
+	if policy.Jitter > 0 && d > 0 {
+		d += time.Duration(float64(d) * policy.Jitter * (2*rand.Float64() - 1))
+	}
+	return d
+}
@@ -0,0 +1,97 @@
+package retry
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/types"
+)
+
+func TestDo(t *testing.T) {
+	fail := errors.New("boom")
+	policy := config.RetryConfig{Attempts: 3, Backoff: time.Millisecond}
+
+	tests := []struct {
+		name      string
+		policy    config.RetryConfig
+		retryable Classifier
+		failures  int   // Attempts that fail before one succeeds
+		err       error // Error of a failing attempt
+		wantCalls int
+		wantErr   bool
+	}{
+		{name: "first attempt", policy: policy, failures: 0, err: fail, wantCalls: 1},
+		{name: "succeeds on retry", policy: policy, failures: 2, err: fail, wantCalls: 3},
+		{name: "exhausted", policy: policy, failures: 5, err: fail, wantCalls: 3, wantErr: true},
+		{name: "zero attempts run once", policy: config.RetryConfig{}, failures: 5, err: fail, wantCalls: 1, wantErr: true},
+		{name: "classifier rejects", policy: policy, retryable: Transient, failures: 5, err: fail, wantCalls: 1, wantErr: true},
+		{name: "classifier accepts", policy: policy, retryable: Transient, failures: 1, err: types.NewRetryableError(fail), wantCalls: 2},
+		{name: "permanent", policy: policy, failures: 5, err: Permanent(fail), wantCalls: 1, wantErr: true},
+		{name: "max elapsed", policy: config.RetryConfig{Attempts: 5, Backoff: time.Hour, MaxElapsed: time.Minute}, failures: 5, err: fail, wantCalls: 1, wantErr: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			calls := 0
+			err := Do(context.Background(), "test", tt.policy, tt.retryable, func(attempt int) error {
+				if attempt != calls {
+					t.Errorf("attempt %d on call %d", attempt, calls)
+				}
+				calls++
+				if calls <= tt.failures {
+					return tt.err
+				}
+				return nil
+			})
+			if calls != tt.wantCalls {
+				t.Errorf("calls = %d, want %d", calls, tt.wantCalls)
+			}
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("err = %v, wantErr %v", err, tt.wantErr)
+			}
+			if err != nil && !errors.Is(err, fail) {
+				t.Errorf("err = %v, want the attempt's error", err)
+			}
+			var permanent *permanentError
+			if errors.As(err, &permanent) {
+				t.Error("Permanent wrapper leaked to the caller")
+			}
+		})
+	}
+}
+
+func TestDo_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	policy := config.RetryConfig{Attempts: 3, Backoff: time.Hour}
+	start := time.Now()
+	calls := 0
+	err := Do(ctx, "test", policy, nil, func(int) error {
+		calls++
+		time.AfterFunc(10*time.Millisecond, cancel)
+		return errors.New("boom")
+	})
+	if err == nil || calls != 1 {
+		t.Fatalf("calls = %d, err = %v", calls, err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancelled backoff returned after %v", elapsed)
+	}
+}
+
+func TestDelay(t *testing.T) {
+	policy := config.RetryConfig{Backoff: time.Second, MaxBackoff: 5 * time.Second}
+	for n, want := range map[int]time.Duration{1: time.Second, 2: 2 * time.Second, 3: 4 * time.Second, 4: 5 * time.Second, 40: 5 * time.Second} {
+		if got := delay(policy, n); got != want {
+			t.Errorf("delay(%d) = %v, want %v", n, got, want)
+		}
+	}
+
+	policy.Jitter = 0.2
+	for range 100 {
+		if got := delay(policy, 2); got < 1600*time.Millisecond || got > 2400*time.Millisecond {
+			t.Fatalf("jittered delay %v outside ±20%% of 2s", got)
+		}
+	}
+}
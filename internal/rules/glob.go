@@ -0,0 +1,79 @@
+package rules
+
+import (
+	"regexp"
+	"strings"
+	"sync"
+)
+
+var (
+	globMu    sync.RWMutex
+	globCache = make(map[string]*regexp.Regexp)
+)
+
+// MatchGlob reports whether name matches pattern.
+// Supported syntax: "*" matches within one path segment, "**" matches across segments,
+// "?" matches one character. A pattern without "/" matches the base name only,
+// so "*.pb.go" matches "api/v1/x.pb.go".
+func MatchGlob(pattern, name string) bool {
+	if !strings.Contains(pattern, "/") {
+		if i := strings.LastIndex(name, "/"); i >= 0 {
+			name = name[i+1:]
+		}
+	}
+	return compileGlob(pattern).MatchString(name)
+}
+
+// MatchAny reports whether name matches any of the patterns. An empty list matches everything.
+func MatchAny(patterns []string, name string) bool {
+	if len(patterns) == 0 {
+		return true
+	}
+	for _, p := range patterns {
+		if MatchGlob(p, name) {
+			return true
+		}
+	}
+	return false
+}
+
+func compileGlob(pattern string) *regexp.Regexp {
+	globMu.RLock()
+	re, ok := globCache[pattern]
+	globMu.RUnlock()
+	if ok {
+		return re
+	}
+
+	var sb strings.Builder
+	sb.WriteString("^")
+	for i := 0; i < len(pattern); i++ {
+		c := pattern[i]
+		switch c {
+		case '*':
+			if i+1 < len(pattern) && pattern[i+1] == '*' {
+				i++
+				// "**/" also matches zero directories
+				if i+1 < len(pattern) && pattern[i+1] == '/' {
+					i++
+					sb.WriteString("(?:.*/)?")
+				} else {
+					sb.WriteString(".*")
+				}
+			} else {
+				sb.WriteString("[^/]*")
+			}
+		case '?':
+			sb.WriteString("[^/]")
+		default:
+			sb.WriteString(regexp.QuoteMeta(string(c)))
+		}
+	}
+	sb.WriteString("$")
+
+	re = regexp.MustCompile(sb.String())
+	globMu.Lock()
+	globCache[pattern] = re
+	globMu.Unlock()
+	return re
+}
@@ -0,0 +1,40 @@
+package rules
+
+import "testing"
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern string
+		name    string
+		want    bool
+	}{
+		{"*.go", "main.go", true},
+		{"*.go", "internal/api/server.go", true},
+		{"*.go", "main.py", false},
+		{"internal/*.go", "internal/api/server.go", false},
+		{"internal/**/*.go", "internal/api/server.go", true},
+		{"internal/**/*.go", "internal/server.go", true},
+		{"**/*.pb.go", "api/v1/types.pb.go", true},
+		{"**/*.pb.go", "types.pb.go", true},
+		{"vendor/**", "vendor/a/b/c.go", true},
+		{"PROJ/*", "PROJ/repo", true},
+		{"PROJ/*", "OTHER/repo", false},
+		{"file?.txt", "file1.txt", true},
+		{"file?.txt", "file10.txt", false},
+		{"a.b", "axb", false},
+	}
+	for _, tt := range tests {
+		if got := MatchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestMatchAny_EmptyMatchesAll(t *testing.T) {
+	if !MatchAny(nil, "anything") {
+		t.Error("empty pattern list should match")
+	}
+	if MatchAny([]string{"*.go"}, "a.py") {
+		t.Error("unexpected match")
+	}
+}
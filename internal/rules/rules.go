@@ -0,0 +1,172 @@
+// Package rules implements operator-defined post-processing of review findings
+// (drop, downgrade, rewrite or tag) before they are posted.
+package rules
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+)
+
+// Rule actions
+const (
+	ActionDrop      = "drop"      // Remove the finding
+	ActionDowngrade = "downgrade" // Lower the severity (one level, or to Severity)
+	ActionRewrite   = "rewrite"   // Replace Message matches with Replace
+	ActionTag       = "tag"       // Prefix the message with Tag
+)
+
+// severityOrder lists severities from highest to lowest
+var severityOrder = []string{
+	domain.CommentSeverityCritical,
+	domain.CommentSeverityWarning,
+	domain.CommentSeverityInfo,
+	domain.CommentSeverityNit,
+}
+
+// Rule matches findings and applies one action. Empty match fields match everything.
+type Rule struct {
+	Name       string   `yaml:"name"`
+	Repos      []string `yaml:"repos"`      // "PROJECT/repo" globs, e.g. "PROJ/*"
+	Files      []string `yaml:"files"`      // File path globs, e.g. "**/*.pb.go"
+	Severities []string `yaml:"severities"` // Finding severities to match
+	Message    string   `yaml:"message"`    // Regex on the finding message
+	Action     string   `yaml:"action"`     // drop, downgrade, rewrite, tag
+	Severity   string   `yaml:"severity"`   // downgrade: target severity (default: one level lower)
+	Replace    string   `yaml:"replace"`    // rewrite: replacement for Message matches ($1 expands groups)
+	Tag        string   `yaml:"tag"`        // tag: prefix added to the message
+
+	message *regexp.Regexp
+}
+
+// File is the structure of the rules file
+type File struct {
+	Rules []Rule `yaml:"rules"`
+}
+
+// Engine applies rules in file order; a drop stops further rules for that finding
+type Engine struct {
+	rules []Rule
+}
+
+// Load reads and validates a rules file
+func Load(path string) (*Engine, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("read rules file: %w", err)
+	}
+	var f File
+	if err := yaml.Unmarshal(data, &f); err != nil {
+		return nil, fmt.Errorf("parse rules file %s: %w", path, err)
+	}
+	return New(f.Rules)
+}
+
+// New validates rules and creates an Engine
+func New(rules []Rule) (*Engine, error) {
+	for i := range rules {
+		r := &rules[i]
+		if r.Name == "" {
+			r.Name = fmt.Sprintf("rule-%d", i+1)
+		}
+		switch r.Action {
+		case ActionDrop, ActionDowngrade:
+		case ActionRewrite:
+			if r.Message == "" {
+				return nil, fmt.Errorf("rule %s: rewrite requires message", r.Name)
+			}
+		case ActionTag:
+			if r.Tag == "" {
+				return nil, fmt.Errorf("rule %s: tag requires tag", r.Name)
+			}
+		default:
+			return nil, fmt.Errorf("rule %s: unknown action %q", r.Name, r.Action)
+		}
+		if r.Severity != "" && !slices.Contains(severityOrder, strings.ToUpper(r.Severity)) {
+			return nil, fmt.Errorf("rule %s: unknown severity %q", r.Name, r.Severity)
+		}
+		if r.Message != "" {
+			re, err := regexp.Compile(r.Message)
+			if err != nil {
+				return nil, fmt.Errorf("rule %s: invalid message regex: %w", r.Name, err)
+			}
+			r.message = re
+		}
+	}
+	return &Engine{rules: rules}, nil
+}
+
+// Len returns the number of loaded rules
+func (e *Engine) Len() int {
+	return len(e.rules)
+}
+
+// Apply runs the rules over the findings of a PR and returns the remaining findings
+func (e *Engine) Apply(pr *domain.PullRequest, comments []domain.ReviewComment) []domain.ReviewComment {
+	repo := pr.ProjectKey + "/" + pr.RepoSlug
+	out := comments[:0:0]
+
+	for _, c := range comments {
+		dropped := false
+		for i := range e.rules {
+			r := &e.rules[i]
+			if !r.matches(repo, c) {
+				continue
+			}
+			metrics.PostRuleActions.WithLabelValues(r.Name, r.Action).Inc()
+			slog.Debug("post rule matched", "rule", r.Name, "action", r.Action, "file", c.File, "line", int(c.Line))
+
+			switch r.Action {
+			case ActionDrop:
+				dropped = true
+			case ActionDowngrade:
+				c.Severity = r.downgrade(c.Severity)
+			case ActionRewrite:
+				c.Comment = r.message.ReplaceAllString(c.Comment, r.Replace)
+			case ActionTag:
+				c.Comment = r.Tag + " " + c.Comment
+			}
+			if dropped {
+				break
+			}
+		}
+		if !dropped {
+			out = append(out, c)
+		}
+	}
+	return out
+}
+
+func (r *Rule) matches(repo string, c domain.ReviewComment) bool {
+	if !MatchAny(r.Repos, repo) || !MatchAny(r.Files, c.File) {
+		return false
+	}
+	if len(r.Severities) > 0 && !slices.ContainsFunc(r.Severities, func(s string) bool { return strings.EqualFold(s, c.Severity) }) {
+		return false
+	}
+	return r.message == nil || r.message.MatchString(c.Comment)
+}
+
+// downgrade returns the target severity; severities never move up
+func (r *Rule) downgrade(current string) string {
+	cur := slices.Index(severityOrder, strings.ToUpper(current))
+	if r.Severity != "" {
+		target := slices.Index(severityOrder, strings.ToUpper(r.Severity))
+		if target > cur {
+			return severityOrder[target]
+		}
+		return current
+	}
+	if cur >= 0 && cur < len(severityOrder)-1 {
+		return severityOrder[cur+1]
+	}
+	return current
+}
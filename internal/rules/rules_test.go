@@ -0,0 +1,105 @@
+package rules
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"pr-review-automation/internal/domain"
+)
+
+func testPR() *domain.PullRequest {
+	return &domain.PullRequest{ID: "1", ProjectKey: "PROJ", RepoSlug: "repo"}
+}
+
+func TestEngine_Apply(t *testing.T) {
+	engine, err := New([]Rule{
+		{Name: "generated", Files: []string{"**/*.pb.go"}, Action: ActionDrop},
+		{Name: "other-repo", Repos: []string{"OTHER/*"}, Action: ActionDrop},
+		{Name: "style", Message: "(?i)naming", Action: ActionDowngrade},
+		{Name: "to-nit", Severities: []string{"WARNING"}, Message: "magic number", Action: ActionDowngrade, Severity: "NIT"},
+		{Name: "rewrite", Message: "foo(\\d)", Action: ActionRewrite, Replace: "bar$1"},
+		{Name: "tag", Severities: []string{"CRITICAL"}, Action: ActionTag, Tag: "[security]"},
+	})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+
+	in := []domain.ReviewComment{
+		{File: "api/types.pb.go", Line: 1, Severity: "WARNING", Comment: "x"},
+		{File: "main.go", Line: 2, Severity: "WARNING", Comment: "Naming is off"},
+		{File: "main.go", Line: 3, Severity: "WARNING", Comment: "magic number"},
+		{File: "main.go", Line: 4, Severity: "INFO", Comment: "use foo1"},
+		{File: "main.go", Line: 5, Severity: "CRITICAL", Comment: "SQL injection"},
+	}
+	out := engine.Apply(testPR(), in)
+
+	if len(out) != 4 {
+		t.Fatalf("got %d comments, want 4: %+v", len(out), out)
+	}
+	if out[0].Severity != "INFO" {
+		t.Errorf("downgrade: got %s, want INFO", out[0].Severity)
+	}
+	if out[1].Severity != "NIT" {
+		t.Errorf("downgrade to target: got %s, want NIT", out[1].Severity)
+	}
+	if out[2].Comment != "use bar1" {
+		t.Errorf("rewrite: got %q", out[2].Comment)
+	}
+	if out[3].Comment != "[security] SQL injection" || out[3].Severity != "CRITICAL" {
+		t.Errorf("tag: got %+v", out[3])
+	}
+	if in[1].Severity != "WARNING" {
+		t.Error("input comments should not be modified")
+	}
+}
+
+func TestEngine_DowngradeNeverRaises(t *testing.T) {
+	engine, err := New([]Rule{{Action: ActionDowngrade, Severity: "WARNING"}})
+	if err != nil {
+		t.Fatalf("New: %v", err)
+	}
+	out := engine.Apply(testPR(), []domain.ReviewComment{{Severity: "NIT"}, {Severity: "CRITICAL"}})
+	if out[0].Severity != "NIT" || out[1].Severity != "WARNING" {
+		t.Errorf("got %s, %s", out[0].Severity, out[1].Severity)
+	}
+}
+
+func TestNew_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		rule Rule
+	}{
+		{"unknown action", Rule{Action: "explode"}},
+		{"bad regex", Rule{Action: ActionDrop, Message: "("}},
+		{"rewrite without message", Rule{Action: ActionRewrite}},
+		{"tag without tag", Rule{Action: ActionTag}},
+		{"bad severity", Rule{Action: ActionDowngrade, Severity: "HUGE"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := New([]Rule{tt.rule}); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rules.yaml")
+	data := "rules:\n  - name: gen\n    files: [\"**/*.pb.go\"]\n    action: drop\n"
+	if err := os.WriteFile(path, []byte(data), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	engine, err := Load(path)
+	if err != nil {
+		t.Fatalf("Load: %v", err)
+	}
+	if engine.Len() != 1 {
+		t.Errorf("got %d rules, want 1", engine.Len())
+	}
+
+	if _, err := Load(filepath.Join(t.TempDir(), "missing.yaml")); err == nil {
+		t.Error("expected error for missing file")
+	}
+}
@@ -0,0 +1,111 @@
+// Package scope decides which repositories are reviewed, from configured
+// allow/deny lists and runtime overrides set through the admin API.
+package scope
+
+import (
+	"sort"
+	"sync"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/rules"
+)
+
+// Decision reasons
+const (
+	ReasonOverride        = "override"         // Runtime override set through the admin API
+	ReasonProjectDisabled = "project_disabled" // Project not in enabled_projects
+	ReasonRepoDisabled    = "repo_disabled"    // Repository matches disabled_repos
+)
+
+// Override is a runtime enable/disable of one repository
+type Override struct {
+	ProjectKey string    `json:"projectKey"`
+	RepoSlug   string    `json:"repoSlug"`
+	Enabled    bool      `json:"enabled"`
+	SetBy      string    `json:"setBy,omitempty"`
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// Gate checks repositories against the review scope. Overrides win over the configured lists
+// and are kept in memory; they reset to the configuration on restart.
+type Gate struct {
+	enabledProjects []string // Project key globs; empty = all projects
+	disabledRepos   []string // "PROJECT/repo" globs
+
+	mu        sync.RWMutex
+	overrides map[string]Override // "PROJECT/repo" -> override
+}
+
+// NewGate creates a Gate from configuration
+func NewGate(cfg config.ReviewScopeConfig) *Gate {
+	return &Gate{
+		enabledProjects: cfg.EnabledProjects,
+		disabledRepos:   cfg.DisabledRepos,
+		overrides:       make(map[string]Override),
+	}
+}
+
+// Allowed reports whether the repository is reviewed; reason is set when it is not,
+// or when an override decided
+func (g *Gate) Allowed(projectKey, repoSlug string) (bool, string) {
+	repo := projectKey + "/" + repoSlug
+
+	g.mu.RLock()
+	o, ok := g.overrides[repo]
+	g.mu.RUnlock()
+	if ok {
+		return o.Enabled, ReasonOverride
+	}
+
+	if !rules.MatchAny(g.enabledProjects, projectKey) {
+		return false, ReasonProjectDisabled
+	}
+	if len(g.disabledRepos) > 0 && rules.MatchAny(g.disabledRepos, repo) {
+		return false, ReasonRepoDisabled
+	}
+	return true, ""
+}
+
+// SetOverride enables or disables a repository at runtime
+func (g *Gate) SetOverride(projectKey, repoSlug string, enabled bool, setBy string) Override {
+	o := Override{ProjectKey: projectKey, RepoSlug: repoSlug, Enabled: enabled, SetBy: setBy, UpdatedAt: time.Now()}
+	g.mu.Lock()
+	g.overrides[projectKey+"/"+repoSlug] = o
+	g.mu.Unlock()
+	return o
+}
+
+// ClearOverride returns a repository to the configured lists; it reports whether an override existed
+func (g *Gate) ClearOverride(projectKey, repoSlug string) bool {
+	key := projectKey + "/" + repoSlug
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	_, ok := g.overrides[key]
+	delete(g.overrides, key)
+	return ok
+}
+
+// Overrides returns the runtime overrides sorted by repository
+func (g *Gate) Overrides() []Override {
+	g.mu.RLock()
+	out := make([]Override, 0, len(g.overrides))
+	for _, o := range g.overrides {
+		out = append(out, o)
+	}
+	g.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].ProjectKey != out[j].ProjectKey {
+			return out[i].ProjectKey < out[j].ProjectKey
+		}
+		return out[i].RepoSlug < out[j].RepoSlug
+	})
+	return out
+}
+
+// EnabledProjects returns the configured project globs
+func (g *Gate) EnabledProjects() []string { return g.enabledProjects }
+
+// DisabledRepos returns the configured repository globs
+func (g *Gate) DisabledRepos() []string { return g.disabledRepos }
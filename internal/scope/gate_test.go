@@ -0,0 +1,62 @@
+package scope
+
+import (
+	"testing"
+
+	"pr-review-automation/internal/config"
+)
+
+func TestGate_Allowed(t *testing.T) {
+	g := NewGate(config.ReviewScopeConfig{
+		EnabledProjects: []string{"PAY", "CORE*"},
+		DisabledRepos:   []string{"PAY/legacy-*", "*/docs"},
+	})
+
+	tests := []struct {
+		project, repo string
+		want          bool
+		wantReason    string
+	}{
+		{"PAY", "api", true, ""},
+		{"CORE2", "lib", true, ""},
+		{"OPS", "infra", false, ReasonProjectDisabled},
+		{"PAY", "legacy-billing", false, ReasonRepoDisabled},
+		{"CORE", "docs", false, ReasonRepoDisabled},
+	}
+	for _, tt := range tests {
+		got, reason := g.Allowed(tt.project, tt.repo)
+		if got != tt.want || reason != tt.wantReason {
+			t.Errorf("Allowed(%s/%s) = %v, %q; want %v, %q", tt.project, tt.repo, got, reason, tt.want, tt.wantReason)
+		}
+	}
+}
+
+func TestGate_Overrides(t *testing.T) {
+	g := NewGate(config.ReviewScopeConfig{DisabledRepos: []string{"PAY/legacy"}})
+
+	if ok, _ := g.Allowed("PAY", "legacy"); ok {
+		t.Fatal("expected repo to be disabled by config")
+	}
+	g.SetOverride("PAY", "legacy", true, "ops")
+	g.SetOverride("OPS", "infra", false, "ops")
+
+	if ok, reason := g.Allowed("PAY", "legacy"); !ok || reason != ReasonOverride {
+		t.Errorf("override enable: got %v, %q", ok, reason)
+	}
+	if ok, _ := g.Allowed("OPS", "infra"); ok {
+		t.Error("override disable: expected disabled")
+	}
+	if o := g.Overrides(); len(o) != 2 || o[0].ProjectKey != "OPS" || o[0].SetBy != "ops" {
+		t.Errorf("unexpected overrides: %+v", o)
+	}
+
+	if !g.ClearOverride("PAY", "legacy") {
+		t.Error("expected override to be cleared")
+	}
+	if g.ClearOverride("PAY", "legacy") {
+		t.Error("second clear should report no override")
+	}
+	if ok, _ := g.Allowed("PAY", "legacy"); ok {
+		t.Error("expected config to apply after clearing")
+	}
+}
@@ -0,0 +1,118 @@
+package splitter
+
+import (
+	"path"
+	"regexp"
+	"strings"
+)
+
+// declarationPatterns match the lines that start a function, method or type, by file extension.
+// They run on the new-file content of a diff line (without its +/space prefix).
+var declarationPatterns = map[string]*regexp.Regexp{
+	".go":    regexp.MustCompile(`^(func|type)\s`),
+	".py":    regexp.MustCompile(`^\s*(async\s+def|def|class)\s`),
+	".js":    jsDeclaration,
+	".jsx":   jsDeclaration,
+	".mjs":   jsDeclaration,
+	".ts":    jsDeclaration,
+	".tsx":   jsDeclaration,
+	".java":  jvmDeclaration,
+	".kt":    jvmDeclaration,
+	".scala": jvmDeclaration,
+	".cs":    jvmDeclaration,
+	".rs":    regexp.MustCompile(`^\s*(pub(\([^)]*\))?\s+)?(async\s+|unsafe\s+|const\s+)*(fn|struct|enum|trait|impl|mod)\b`),
+	".rb":    regexp.MustCompile(`^\s*(def|class|module)\s`),
+	".php":   regexp.MustCompile(`^\s*((public|private|protected|static|abstract|final)\s+)*(function|class|interface|trait)\s`),
+	".swift": regexp.MustCompile(`^\s*((public|private|internal|fileprivate|open|static|override|final)\s+)*(func|class|struct|enum|protocol|extension)\s`),
+}
+
+var (
+	jsDeclaration  = regexp.MustCompile(`^\s*(export\s+)?(default\s+)?(async\s+)?(function\*?|class)\s|^\s*(export\s+)?(const|let)\s+\w+\s*=\s*(async\s*)?(\([^)]*\)|\w+)\s*=>`)
+	jvmDeclaration = regexp.MustCompile(`^\s*(@\w+\s+)*((public|private|protected|internal|static|final|abstract|override|suspend|open|data|sealed)\s+)*(class|interface|enum|record|object|fun)\s|^\s*(public|private|protected|internal)\s+[\w<>\[\], ?]+\s+\w+\s*\(`)
+	// Without a known language, an unindented line after a blank line starts a new top-level block
+	genericDeclaration = regexp.MustCompile(`^[A-Za-z_#]`)
+)
+
+// boundaryFinder marks the diff lines of a file where a cut keeps functions and types whole
+type boundaryFinder struct {
+	pattern *regexp.Regexp
+	generic bool
+}
+
+// newBoundaryFinder picks the declaration pattern for a file path
+func newBoundaryFinder(file string) boundaryFinder {
+	if p, ok := declarationPatterns[strings.ToLower(path.Ext(file))]; ok {
+		return boundaryFinder{pattern: p}
+	}
+	return boundaryFinder{pattern: genericDeclaration, generic: true}
+}
+
+// boundaries reports for each line whether a chunk may start there. A cut moves above the
+// comments, annotations and decorators that belong to the declaration.
+func (b boundaryFinder) boundaries(lines []string) []bool {
+	marks := make([]bool, len(lines))
+	for i, line := range lines {
+		text, ok := newSideText(line)
+		if !ok || !b.pattern.MatchString(text) {
+			continue
+		}
+		if b.generic && (i == 0 || !blankLine(lines[i-1])) {
+			continue
+		}
+		start := i
+		for start > 0 && leadingDecoration(lines[start-1]) {
+			start--
+		}
+		marks[start] = true
+	}
+	return marks
+}
+
+// newSideText returns the new-file content of a diff line; removed lines and hunk headers
+// have none
+func newSideText(line string) (string, bool) {
+	if line == "" {
+		return "", true
+	}
+	switch line[0] {
+	case '+', ' ':
+		return line[1:], true
+	}
+	return "", false
+}
+
+// blankLine reports whether a diff line is empty in the new file
+func blankLine(line string) bool {
+	text, ok := newSideText(line)
+	return ok && strings.TrimSpace(text) == ""
+}
+
+// leadingDecoration reports whether a diff line is a comment, annotation or decorator that
+// belongs to the declaration below it
+func leadingDecoration(line string) bool {
+	text, ok := newSideText(line)
+	if !ok {
+		return false
+	}
+	text = strings.TrimSpace(text)
+	for _, prefix := range []string{"//", "/*", "*", "#", "@", "///"} {
+		if strings.HasPrefix(text, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// cutPoint returns where the chunk of lines[start:end] should end: the last boundary in its
+// second half, so chunks stay near the token budget, or end when there is none
+func cutPoint(marks []bool, start, end int) int {
+	if end >= len(marks) {
+		return end
+	}
+	for i := end; i > start+(end-start)/2; i-- {
+		if marks[i] {
+			return i
+		}
+	}
+	return end
+}
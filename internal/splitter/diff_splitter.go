@@ -3,6 +3,7 @@ package splitter
 import (
 	"log/slog"
 	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/tokenizer"
 	"regexp"
 	"strings"
 )
@@ -53,7 +54,7 @@ func NewDiffSplitterWithContext(maxTokens, maxFiles, contextLines int) *DiffSpli
 }
 
 // Split parses a unified diff and splits it into chunks
-// Strategy: Prioritize file boundaries, then split by hunks with context preservation
+// Strategy: Prioritize file boundaries, then hunks, then function and type declarations within a hunk
 func (s *DiffSplitter) Split(fullDiff string) []DiffChunk {
 	files := s.ParseFiles(fullDiff)
 	if len(files) == 0 {
@@ -242,7 +243,7 @@ func (s *DiffSplitter) splitLargeFileByHunks(file FileDiff) []FileDiff {
 			}
 
 			// Split the large hunk by lines
-			subHunks := s.splitLargeHunk(hunk, s.MaxTokensPerChunk-estimateTokens(fileHeader))
+			subHunks := s.splitLargeHunk(file.Path, hunk, s.MaxTokensPerChunk-estimateTokens(fileHeader))
 			for _, sh := range subHunks {
 				content := fileHeader + sh
 				result = append(result, FileDiff{
@@ -322,8 +323,9 @@ func (s *DiffSplitter) extractFileHeader(content string) string {
 	return content[:loc[0]]
 }
 
-// splitLargeHunk splits a single large hunk into smaller pieces with context
-func (s *DiffSplitter) splitLargeHunk(hunk string, maxTokens int) []string {
+// splitLargeHunk splits a single large hunk into smaller pieces with context. Pieces end
+// before a function or type declaration when one falls in the second half of the window.
+func (s *DiffSplitter) splitLargeHunk(path, hunk string, maxTokens int) []string {
 	lines := strings.Split(hunk, "\n")
 	if len(lines) == 0 {
 		return nil
@@ -352,11 +354,13 @@ func (s *DiffSplitter) splitLargeHunk(hunk string, maxTokens int) []string {
 		linesPerChunk = 20
 	}
 
+	marks := newBoundaryFinder(path).boundaries(lines)
 	for i := startLine; i < len(lines); {
 		end := i + linesPerChunk
 		if end > len(lines) {
 			end = len(lines)
 		}
+		end = cutPoint(marks, i, end)
 
 		// Build sub-chunk with context
 		var chunkLines []string
@@ -404,7 +408,8 @@ func (s *DiffSplitter) splitLargeHunk(hunk string, maxTokens int) []string {
 	return result
 }
 
-// splitLargeFileByLines is the fallback method when hunk parsing fails
+// splitLargeFileByLines is the fallback method when hunk parsing fails. Like hunks, the
+// windows end at declarations where possible.
 func (s *DiffSplitter) splitLargeFileByLines(file FileDiff) []FileDiff {
 	lines := strings.Split(file.Content, "\n")
 	var result []FileDiff
@@ -414,11 +419,13 @@ func (s *DiffSplitter) splitLargeFileByLines(file FileDiff) []FileDiff {
 		linesPerChunk = 50
 	}
 
+	marks := newBoundaryFinder(file.Path).boundaries(lines)
 	for i := 0; i < len(lines); {
 		end := i + linesPerChunk
 		if end > len(lines) {
 			end = len(lines)
 		}
+		end = cutPoint(marks, i, end)
 
 		// Add context lines
 		actualStart := i
@@ -450,9 +457,9 @@ func (s *DiffSplitter) splitLargeFileByLines(file FileDiff) []FileDiff {
 	return result
 }
 
-// estimateTokens estimates token count (roughly 4 chars per token)
+// estimateTokens counts tokens with the tokenizer of llm.model
 func estimateTokens(text string) int {
-	return len(text) / 4
+	return tokenizer.Count(text)
 }
 
 // CombineContent creates a single diff string from a chunk
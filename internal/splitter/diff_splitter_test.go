@@ -0,0 +1,65 @@
+package splitter
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDiffSplitter_SplitLargeHunkAtDeclarations(t *testing.T) {
+	var lines []string
+	for f := 0; f < 4; f++ {
+		lines = append(lines, fmt.Sprintf("+func f%d() {", f))
+		for i := 0; i < 10; i++ {
+			lines = append(lines, fmt.Sprintf("+\tx += %d", i))
+		}
+		lines = append(lines, "+}", "+")
+	}
+	hunk := "@@ -0,0 +1,52 @@\n" + strings.Join(lines, "\n")
+
+	s := NewDiffSplitterWithContext(100, 10, 1)
+	pieces := s.splitLargeHunk("main.go", hunk, 100)
+	if len(pieces) != 4 {
+		t.Fatalf("pieces = %d, want one per function", len(pieces))
+	}
+	for i, p := range pieces {
+		body := strings.Split(p, "\n")[1:]
+		if i > 0 {
+			body = body[1:] // leading context line
+		}
+		if want := fmt.Sprintf("+func f%d() {", i); body[0] != want {
+			t.Errorf("piece %d starts with %q, want %q", i, body[0], want)
+		}
+	}
+
+	// Without declarations the fixed windows remain
+	flat := "@@ -0,0 +1,52 @@\n" + strings.Repeat("+x++\n", 51) + "+x++"
+	if got := len(s.splitLargeHunk("main.go", flat, 100)); got != 3 {
+		t.Errorf("flat pieces = %d, want 3", got)
+	}
+}
+
+func TestBoundaryFinder(t *testing.T) {
+	tests := []struct {
+		path  string
+		lines []string
+		want  []int
+	}{
+		{"app.py", []string{"+x = 1", "+", "+@cached", "+def f():", "+    pass", "-def g():"}, []int{2}},
+		{"App.java", []string{"+  /** Docs */", "+  public int size() {", "+    return n;", "+  }"}, []int{0}},
+		{"lib.rs", []string{" }", "+pub(crate) fn parse() {", "+    let x = 1;"}, []int{1}},
+		{"main.c", []string{"+int a;", "+", "+int main(void) {", "+  return 0;", "+}"}, []int{2}},
+	}
+	for _, tt := range tests {
+		marks := newBoundaryFinder(tt.path).boundaries(tt.lines)
+		var got []int
+		for i, m := range marks {
+			if m {
+				got = append(got, i)
+			}
+		}
+		if fmt.Sprint(got) != fmt.Sprint(tt.want) {
+			t.Errorf("%s: boundaries = %v, want %v", tt.path, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,101 @@
+package storage
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// encryptedPrefix marks values encrypted with the v1 AES-GCM scheme.
+// Values without it are treated as plaintext so existing rows stay readable.
+const encryptedPrefix = "enc:v1:"
+
+// ErrMissingKey is returned when an encrypted value is read without a configured key
+var ErrMissingKey = errors.New("encrypted record found but no encryption key configured")
+
+// KeyProvider supplies the data encryption key (e.g. from env or a KMS)
+type KeyProvider interface {
+	Key() ([]byte, error)
+}
+
+// StaticKey is a KeyProvider backed by a base64-encoded key, typically read from the environment
+type StaticKey string
+
+// Key decodes the base64 key; it must decode to 16, 24 or 32 bytes
+func (k StaticKey) Key() ([]byte, error) {
+	key, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(k)))
+	if err != nil {
+		return nil, fmt.Errorf("decode key: %w", err)
+	}
+	switch len(key) {
+	case 16, 24, 32:
+		return key, nil
+	default:
+		return nil, fmt.Errorf("invalid key length %d (want 16, 24 or 32 bytes)", len(key))
+	}
+}
+
+// Cipher encrypts stored review content with AES-GCM
+type Cipher struct {
+	aead cipher.AEAD
+}
+
+// NewCipher creates a Cipher using the key from the provider
+func NewCipher(kp KeyProvider) (*Cipher, error) {
+	key, err := kp.Key()
+	if err != nil {
+		return nil, err
+	}
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("new aes cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("new gcm: %w", err)
+	}
+	return &Cipher{aead: aead}, nil
+}
+
+// Encrypt seals plaintext and returns a prefixed base64 string.
+// The additional data binds the ciphertext to its record so rows cannot be swapped.
+func (c *Cipher) Encrypt(plaintext, additionalData string) (string, error) {
+	nonce := make([]byte, c.aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", fmt.Errorf("generate nonce: %w", err)
+	}
+	sealed := c.aead.Seal(nonce, nonce, []byte(plaintext), []byte(additionalData))
+	return encryptedPrefix + base64.StdEncoding.EncodeToString(sealed), nil
+}
+
+// Decrypt opens a value produced by Encrypt. Plaintext values are returned unchanged.
+func (c *Cipher) Decrypt(value, additionalData string) (string, error) {
+	if !isEncrypted(value) {
+		return value, nil
+	}
+	if c == nil {
+		return "", ErrMissingKey
+	}
+	raw, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(value, encryptedPrefix))
+	if err != nil {
+		return "", fmt.Errorf("decode ciphertext: %w", err)
+	}
+	nonceSize := c.aead.NonceSize()
+	if len(raw) < nonceSize {
+		return "", fmt.Errorf("ciphertext too short")
+	}
+	plain, err := c.aead.Open(nil, raw[:nonceSize], raw[nonceSize:], []byte(additionalData))
+	if err != nil {
+		return "", fmt.Errorf("decrypt: %w", err)
+	}
+	return string(plain), nil
+}
+
+func isEncrypted(value string) bool {
+	return strings.HasPrefix(value, encryptedPrefix)
+}
@@ -0,0 +1,178 @@
+package storage
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/domain"
+)
+
+func testKey() StaticKey {
+	return StaticKey(base64.StdEncoding.EncodeToString([]byte("0123456789abcdef0123456789abcdef")))
+}
+
+func TestCipher_RoundTrip(t *testing.T) {
+	c, err := NewCipher(testKey())
+	if err != nil {
+		t.Fatalf("NewCipher failed: %v", err)
+	}
+
+	enc, err := c.Encrypt("secret code", "record-1")
+	if err != nil {
+		t.Fatalf("Encrypt failed: %v", err)
+	}
+	if !strings.HasPrefix(enc, encryptedPrefix) || strings.Contains(enc, "secret") {
+		t.Fatalf("unexpected ciphertext: %s", enc)
+	}
+
+	dec, err := c.Decrypt(enc, "record-1")
+	if err != nil || dec != "secret code" {
+		t.Fatalf("Decrypt = %q, %v", dec, err)
+	}
+
+	// Ciphertext is bound to the record ID
+	if _, err := c.Decrypt(enc, "record-2"); err == nil {
+		t.Error("expected error decrypting with wrong additional data")
+	}
+
+	// Plaintext passes through for rows written before encryption was enabled
+	if dec, _ := c.Decrypt(`{"summary":"x"}`, "record-1"); dec != `{"summary":"x"}` {
+		t.Errorf("expected plaintext passthrough, got %q", dec)
+	}
+}
+
+func TestStaticKey_InvalidLength(t *testing.T) {
+	if _, err := NewCipher(StaticKey(base64.StdEncoding.EncodeToString([]byte("short")))); err == nil {
+		t.Error("expected error for short key")
+	}
+}
+
+func TestSQLiteRepository_Encrypted(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "enc.db")
+	repo, err := NewSQLiteRepository(dbPath)
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	c, err := NewCipher(testKey())
+	if err != nil {
+		t.Fatalf("NewCipher failed: %v", err)
+	}
+	repo.SetCipher(c)
+
+	ctx := context.Background()
+	record := &ReviewRecord{
+		ID:          "enc-1",
+		PullRequest: &domain.PullRequest{ID: "1", ProjectKey: "P", RepoSlug: "r"},
+		Result: &domain.ReviewResult{
+			Comments: []domain.ReviewComment{{File: "main.go", Line: 1, Comment: "password := \"hunter2\""}},
+		},
+		CreatedAt: time.Now().UTC(),
+		Status:    "success",
+	}
+	if err := repo.SaveReview(ctx, record); err != nil {
+		t.Fatalf("SaveReview failed: %v", err)
+	}
+
+	// Raw column must not contain the comment text
+	var raw string
+	if err := repo.db.QueryRowContext(ctx, "SELECT result_data FROM reviews WHERE id = ?", "enc-1").Scan(&raw); err != nil {
+		t.Fatalf("raw query failed: %v", err)
+	}
+	if strings.Contains(raw, "hunter2") {
+		t.Errorf("result stored in plaintext: %s", raw)
+	}
+
+	saved, err := repo.GetReview(ctx, "enc-1")
+	if err != nil {
+		t.Fatalf("GetReview failed: %v", err)
+	}
+	if saved.Result.Comments[0].Comment != record.Result.Comments[0].Comment {
+		t.Errorf("unexpected decrypted comment: %s", saved.Result.Comments[0].Comment)
+	}
+
+	// Without a key, encrypted rows fail loudly instead of returning garbage
+	repo.SetCipher(nil)
+	if _, err := repo.GetReview(ctx, "enc-1"); !errors.Is(err, ErrMissingKey) {
+		t.Errorf("expected missing key error, got %v", err)
+	}
+}
+
+func TestSQLiteRepository_EncryptedQueuesAndFingerprints(t *testing.T) {
+	repo, err := NewSQLiteRepository(filepath.Join(t.TempDir(), "enc.db"))
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	defer repo.Close()
+
+	c, err := NewCipher(testKey())
+	if err != nil {
+		t.Fatalf("NewCipher failed: %v", err)
+	}
+	repo.SetCipher(c)
+
+	ctx := context.Background()
+	pr := &domain.PullRequest{ID: "1", ProjectKey: "P", RepoSlug: "r", Title: "Rotate hunter2"}
+	if err := repo.SaveQueuedReviews(ctx, []*QueuedReview{{Key: "P/r/1", PullRequest: pr}}); err != nil {
+		t.Fatalf("SaveQueuedReviews failed: %v", err)
+	}
+	if err := repo.SaveJob(ctx, &QueuedJob{Key: "P/r/1", Seq: 1, ProjectKey: "P", RepoSlug: "r", PRID: "1", Payload: []byte(`{"title":"Rotate hunter2"}`)}); err != nil {
+		t.Fatalf("SaveJob failed: %v", err)
+	}
+	if err := repo.SaveJob(ctx, &QueuedJob{Key: "P/r/2", Seq: 1, ProjectKey: "P", RepoSlug: "r", PRID: "2", PullRequest: pr}); err != nil {
+		t.Fatalf("SaveJob failed: %v", err)
+	}
+	if err := repo.SaveFingerprint(ctx, &FingerprintRecord{ProjectKey: "P", RepoSlug: "r", PRID: "1", Title: "Rotate hunter2", Lines: []string{"h"}}); err != nil {
+		t.Fatalf("SaveFingerprint failed: %v", err)
+	}
+
+	// No raw column may contain the PR title
+	for _, q := range []string{
+		"SELECT pr_data FROM queued_reviews",
+		"SELECT COALESCE(CAST(payload AS TEXT), '') || COALESCE(pr_data, '') FROM queued_jobs",
+		"SELECT title FROM diff_fingerprints",
+	} {
+		rows, err := repo.db.QueryContext(ctx, q)
+		if err != nil {
+			t.Fatalf("%s: %v", q, err)
+		}
+		for rows.Next() {
+			var raw string
+			if err := rows.Scan(&raw); err != nil {
+				t.Fatalf("%s: %v", q, err)
+			}
+			if strings.Contains(raw, "hunter2") {
+				t.Errorf("%s stored in plaintext: %s", q, raw)
+			}
+		}
+		rows.Close()
+	}
+
+	jobs, err := repo.ListJobs(ctx)
+	if err != nil {
+		t.Fatalf("ListJobs failed: %v", err)
+	}
+	if len(jobs) != 2 || string(jobs[0].Payload) != `{"title":"Rotate hunter2"}` || jobs[1].PullRequest == nil || jobs[1].PullRequest.Title != pr.Title {
+		t.Errorf("unexpected decrypted jobs: %+v", jobs)
+	}
+	fps, err := repo.ListFingerprints(ctx, "P", "r", time.Time{})
+	if err != nil || len(fps) != 1 || fps[0].Title != pr.Title {
+		t.Errorf("unexpected decrypted fingerprints: %+v, %v", fps, err)
+	}
+	queued, err := repo.TakeQueuedReviews(ctx)
+	if err != nil || len(queued) != 1 || queued[0].PullRequest.Title != pr.Title {
+		t.Errorf("unexpected decrypted queued reviews: %+v, %v", queued, err)
+	}
+
+	// Without a key, encrypted rows fail loudly
+	repo.SetCipher(nil)
+	if _, err := repo.ListJobs(ctx); !errors.Is(err, ErrMissingKey) {
+		t.Errorf("expected missing key error, got %v", err)
+	}
+}
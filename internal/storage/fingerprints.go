@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// FingerprintRecord is the diff fingerprint of the latest reviewed revision of a PR
+type FingerprintRecord struct {
+	ProjectKey string    `json:"projectKey"`
+	RepoSlug   string    `json:"repoSlug"`
+	PRID       string    `json:"prId"`
+	Commit     string    `json:"commit,omitempty"`
+	Title      string    `json:"title,omitempty"`
+	WebURL     string    `json:"webUrl,omitempty"`
+	Lines      []string  `json:"lines"` // Hashes of the normalized changed lines
+	UpdatedAt  time.Time `json:"updatedAt"`
+}
+
+// FingerprintRepository persists diff fingerprints for duplicate-PR detection
+type FingerprintRepository interface {
+	// SaveFingerprint replaces the fingerprint of the PR
+	SaveFingerprint(ctx context.Context, f *FingerprintRecord) error
+	// ListFingerprints returns the fingerprints of a repository updated since the given time
+	ListFingerprints(ctx context.Context, projectKey, repoSlug string, since time.Time) ([]*FingerprintRecord, error)
+}
@@ -0,0 +1,39 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSQLiteRepository_Fingerprints(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	records := []*FingerprintRecord{
+		{ProjectKey: "PROJ", RepoSlug: "repo", PRID: "1", Title: "old", Lines: []string{"a"}, UpdatedAt: time.Now().Add(-30 * 24 * time.Hour)},
+		{ProjectKey: "PROJ", RepoSlug: "repo", PRID: "2", Title: "first push", Lines: []string{"a"}},
+		{ProjectKey: "PROJ", RepoSlug: "repo", PRID: "2", Commit: "c2", Title: "second push", Lines: []string{"a", "b"}},
+		{ProjectKey: "PROJ", RepoSlug: "other", PRID: "3", Lines: []string{"a"}},
+	}
+	for _, f := range records {
+		if err := repo.SaveFingerprint(ctx, f); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+
+	got, err := repo.ListFingerprints(ctx, "PROJ", "repo", time.Now().Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(got) != 1 || got[0].PRID != "2" || got[0].Commit != "c2" || len(got[0].Lines) != 2 {
+		t.Fatalf("unexpected fingerprints: %+v", got)
+	}
+
+	if _, err := repo.Purge(ctx, PurgeFilter{ProjectKey: "PROJ", RepoSlug: "repo"}, "test", "repository removed"); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if got, _ := repo.ListFingerprints(ctx, "PROJ", "repo", time.Time{}); len(got) != 0 {
+		t.Errorf("expected no fingerprints after purge, got %d", len(got))
+	}
+}
@@ -0,0 +1,74 @@
+package storage
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// sqliteTimeLayout is how the driver stores time.Time values; aggregates such as MAX return
+// them as text
+const sqliteTimeLayout = "2006-01-02 15:04:05.999999999 -0700 MST"
+
+// RepoStats summarizes the stored reviews of one repository
+type RepoStats struct {
+	ProjectKey    string    `json:"project_key"`
+	RepoSlug      string    `json:"repo_slug"`
+	Reviews       int       `json:"reviews"`
+	Failed        int       `json:"failed"`
+	PullRequests  int       `json:"pull_requests"`
+	AvgDurationMs int64     `json:"avg_duration_ms"`
+	LastReview    time.Time `json:"last_review"`
+	Cost          float64   `json:"cost"` // LLM cost in USD of the reviews
+}
+
+// RepoStats returns per-repository review counts, busiest repository first. An empty
+// filter covers all projects.
+func (r *SQLiteRepository) RepoStats(ctx context.Context, filter ReviewFilter) ([]*RepoStats, error) {
+	query := `
+        SELECT project_key, repo_slug, COUNT(*), SUM(status = 'error'),
+               COUNT(DISTINCT pr_id), CAST(COALESCE(AVG(duration_ms), 0) AS INTEGER), MAX(created_at), SUM(cost)
+        FROM reviews`
+	var args []any
+	if filter.ProjectKey != "" {
+		query += " WHERE project_key = ?"
+		args = append(args, filter.ProjectKey)
+		if filter.RepoSlug != "" {
+			query += " AND repo_slug = ?"
+			args = append(args, filter.RepoSlug)
+		}
+	}
+	query += " GROUP BY project_key, repo_slug ORDER BY COUNT(*) DESC, project_key, repo_slug"
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var stats []*RepoStats
+	for rows.Next() {
+		var s RepoStats
+		var last string
+		if err := rows.Scan(&s.ProjectKey, &s.RepoSlug, &s.Reviews, &s.Failed, &s.PullRequests, &s.AvgDurationMs, &last, &s.Cost); err != nil {
+			return nil, err
+		}
+		s.LastReview, _ = time.Parse(sqliteTimeLayout, last)
+		stats = append(stats, &s)
+	}
+	return stats, rows.Err()
+}
+
+// DeleteReview deletes a single review record and records an audit entry
+func (r *SQLiteRepository) DeleteReview(ctx context.Context, id, requestedBy, reason string) error {
+	res, err := r.db.ExecContext(ctx, "DELETE FROM reviews WHERE id = ?", id)
+	if err != nil {
+		return fmt.Errorf("delete review: %w", err)
+	}
+	if n, _ := res.RowsAffected(); n == 0 {
+		return ErrNotFound
+	}
+	filterJSON, _ := json.Marshal(map[string]string{"id": id})
+	return r.writeAudit(ctx, PurgeAudit{Scope: "record", Filter: string(filterJSON), Rows: 1, RequestedBy: requestedBy, Reason: reason})
+}
@@ -0,0 +1,70 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/domain"
+)
+
+func TestSQLiteRepository_RepoStats(t *testing.T) {
+	repo, err := NewSQLiteRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer repo.Close()
+
+	ctx := context.Background()
+	now := time.Now().UTC().Truncate(time.Second)
+	for i, r := range []struct{ project, repo, pr, status string }{
+		{"PAY", "api", "1", "success"},
+		{"PAY", "api", "1", "error"},
+		{"PAY", "api", "2", "success"},
+		{"PAY", "web", "3", "success"},
+		{"OPS", "infra", "4", "success"},
+	} {
+		if err := repo.SaveReview(ctx, &ReviewRecord{
+			ID:          string(rune('a' + i)),
+			PullRequest: &domain.PullRequest{ID: r.pr, ProjectKey: r.project, RepoSlug: r.repo},
+			Result:      &domain.ReviewResult{},
+			CreatedAt:   now.Add(time.Duration(i) * time.Minute),
+			DurationMs:  int64(1000 * (i + 1)),
+			Status:      r.status,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	stats, err := repo.RepoStats(ctx, ReviewFilter{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(stats) != 3 {
+		t.Fatalf("expected 3 repositories, got %d", len(stats))
+	}
+	api := stats[0]
+	if api.ProjectKey != "PAY" || api.RepoSlug != "api" || api.Reviews != 3 || api.Failed != 1 || api.PullRequests != 2 || api.AvgDurationMs != 2000 {
+		t.Errorf("unexpected stats %+v", api)
+	}
+	if !api.LastReview.Equal(now.Add(2 * time.Minute)) {
+		t.Errorf("last review %v, want %v", api.LastReview, now.Add(2*time.Minute))
+	}
+
+	stats, err = repo.RepoStats(ctx, ReviewFilter{ProjectKey: "PAY", RepoSlug: "web"})
+	if err != nil || len(stats) != 1 || stats[0].Reviews != 1 {
+		t.Fatalf("filtered stats %+v, %v", stats, err)
+	}
+
+	if err := repo.DeleteReview(ctx, "a", "tester", "test"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := repo.GetReview(ctx, "a"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("deleted review still readable: %v", err)
+	}
+	if err := repo.DeleteReview(ctx, "a", "tester", "test"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound deleting twice, got %v", err)
+	}
+}
@@ -0,0 +1,47 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"pr-review-automation/internal/domain"
+)
+
+// QueuedReview is a pending review saved while intake is paused for maintenance
+type QueuedReview struct {
+	Key         string              `json:"key"` // Debounce key of the webhook queue
+	PullRequest *domain.PullRequest `json:"pullRequest"`
+	CreatedAt   time.Time           `json:"createdAt"`
+}
+
+// QueueRepository persists pending reviews across maintenance windows and restarts
+type QueueRepository interface {
+	// SaveQueuedReviews adds reviews to the snapshot, replacing entries with the same key
+	SaveQueuedReviews(ctx context.Context, reviews []*QueuedReview) error
+	// TakeQueuedReviews returns and removes all saved reviews, oldest first
+	TakeQueuedReviews(ctx context.Context) ([]*QueuedReview, error)
+}
+
+// QueuedJob is an accepted webhook review journaled until it finishes, so reviews waiting in
+// memory survive a crash or restart
+type QueuedJob struct {
+	Key        string `json:"key"` // Debounce key of the webhook queue
+	Seq        int64  `json:"seq"` // Version; a newer event for the same key replaces the job
+	ProjectKey string `json:"projectKey"`
+	RepoSlug   string `json:"repoSlug"`
+	PRID       string `json:"prId"`
+
+	Payload     []byte              `json:"payload,omitempty"`     // Raw Bitbucket webhook body, parsed when the review runs
+	PullRequest *domain.PullRequest `json:"pullRequest,omitempty"` // Set instead of Payload when the event was parsed on receipt
+	CreatedAt   time.Time           `json:"createdAt"`
+}
+
+// JobRepository journals accepted webhook reviews until they finish
+type JobRepository interface {
+	// SaveJob journals a job, replacing the job with the same key
+	SaveJob(ctx context.Context, job *QueuedJob) error
+	// DeleteJob removes the job of key if it still has version seq
+	DeleteJob(ctx context.Context, key string, seq int64) error
+	// ListJobs returns the journaled jobs, oldest first
+	ListJobs(ctx context.Context) ([]*QueuedJob, error)
+}
@@ -0,0 +1,114 @@
+package storage
+
+import (
+	"context"
+	"testing"
+
+	"pr-review-automation/internal/domain"
+)
+
+func TestQueuedReviews(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	pr := func(id, title string) *domain.PullRequest {
+		return &domain.PullRequest{ID: id, ProjectKey: "PROJ", RepoSlug: "repo", Title: title}
+	}
+	if err := repo.SaveQueuedReviews(ctx, []*QueuedReview{
+		{Key: "PROJ/repo/1", PullRequest: pr("1", "first")},
+		{Key: "PROJ/repo/2", PullRequest: pr("2", "second")},
+	}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	// A later snapshot replaces the queued payload of the same pull request
+	if err := repo.SaveQueuedReviews(ctx, []*QueuedReview{{Key: "PROJ/repo/1", PullRequest: pr("1", "updated")}}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+
+	queued, err := repo.TakeQueuedReviews(ctx)
+	if err != nil {
+		t.Fatalf("take: %v", err)
+	}
+	if len(queued) != 2 {
+		t.Fatalf("expected 2 queued reviews, got %d", len(queued))
+	}
+	titles := map[string]string{}
+	for _, q := range queued {
+		titles[q.Key] = q.PullRequest.Title
+	}
+	if titles["PROJ/repo/1"] != "updated" || titles["PROJ/repo/2"] != "second" {
+		t.Errorf("unexpected queued reviews: %v", titles)
+	}
+
+	if queued, err := repo.TakeQueuedReviews(ctx); err != nil || len(queued) != 0 {
+		t.Errorf("take must empty the queue, got %d, %v", len(queued), err)
+	}
+}
+
+func TestPurge_QueuedReviews(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	if err := repo.SaveQueuedReviews(ctx, []*QueuedReview{
+		{Key: "PROJ/repo/1", PullRequest: &domain.PullRequest{ID: "1", ProjectKey: "PROJ", RepoSlug: "repo"}},
+		{Key: "OTHER/repo/1", PullRequest: &domain.PullRequest{ID: "1", ProjectKey: "OTHER", RepoSlug: "repo"}},
+	}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if _, err := repo.Purge(ctx, PurgeFilter{ProjectKey: "PROJ"}, "dpo", ""); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+
+	queued, err := repo.TakeQueuedReviews(ctx)
+	if err != nil || len(queued) != 1 || queued[0].PullRequest.ProjectKey != "OTHER" {
+		t.Errorf("purge must drop queued reviews of the project, left %d, %v", len(queued), err)
+	}
+}
+
+func TestQueuedJobs(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	if err := repo.SaveJob(ctx, &QueuedJob{Key: "PROJ/repo/1", Seq: 1, ProjectKey: "PROJ", RepoSlug: "repo", PRID: "1", Payload: []byte(`{"a":1}`)}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	pr := &domain.PullRequest{ID: "2", ProjectKey: "OTHER", RepoSlug: "repo", Title: "parsed"}
+	if err := repo.SaveJob(ctx, &QueuedJob{Key: "OTHER/repo/2", Seq: 1, ProjectKey: "OTHER", RepoSlug: "repo", PRID: "2", PullRequest: pr}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	// A newer event replaces the job; finishing the older one must not remove it
+	if err := repo.SaveJob(ctx, &QueuedJob{Key: "PROJ/repo/1", Seq: 2, ProjectKey: "PROJ", RepoSlug: "repo", PRID: "1", Payload: []byte(`{"a":2}`)}); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	if err := repo.DeleteJob(ctx, "PROJ/repo/1", 1); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+
+	jobs, err := repo.ListJobs(ctx)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(jobs) != 2 {
+		t.Fatalf("expected 2 jobs, got %d", len(jobs))
+	}
+	byKey := map[string]*QueuedJob{}
+	for _, j := range jobs {
+		byKey[j.Key] = j
+	}
+	if j := byKey["PROJ/repo/1"]; j == nil || j.Seq != 2 || string(j.Payload) != `{"a":2}` || j.PullRequest != nil {
+		t.Errorf("unexpected payload job: %+v", j)
+	}
+	if j := byKey["OTHER/repo/2"]; j == nil || j.PullRequest == nil || j.PullRequest.Title != "parsed" {
+		t.Errorf("unexpected parsed job: %+v", j)
+	}
+
+	if err := repo.DeleteJob(ctx, "PROJ/repo/1", 2); err != nil {
+		t.Fatalf("delete: %v", err)
+	}
+	if _, err := repo.Purge(ctx, PurgeFilter{ProjectKey: "OTHER"}, "dpo", ""); err != nil {
+		t.Fatalf("purge: %v", err)
+	}
+	if jobs, err := repo.ListJobs(ctx); err != nil || len(jobs) != 0 {
+		t.Errorf("expected an empty journal, got %d, %v", len(jobs), err)
+	}
+}
@@ -0,0 +1,484 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/fault"
+	"pr-review-automation/internal/metrics"
+)
+
+// ErrUnavailable is returned by reads while the storage circuit is open
+var ErrUnavailable = errors.New("storage unavailable")
+
+// pendingWrite is a buffered write, replayed when storage recovers
+type pendingWrite struct {
+	seq   uint64
+	kind  string // review, skip, fingerprint, tuning
+	write func(ctx context.Context) error
+}
+
+// ResilientRepository wraps a Repository with a circuit breaker and a write buffer so storage
+// outages (SQLite lock contention, a database restart) never fail or stall a review. Writes that
+// fail, or arrive while the circuit is open, are buffered and flushed in order once storage
+// recovers; reads fail fast with ErrUnavailable while the circuit is open.
+type ResilientRepository struct {
+	repo    Repository
+	cfg     config.StorageResilienceConfig
+	timeout time.Duration // Per-write timeout when flushing
+	kick    chan struct{} // Wakes Run when a write is buffered
+
+	mu        sync.Mutex
+	failures  int
+	openUntil time.Time
+	buffer    []pendingWrite
+	seq       uint64
+
+	flushMu sync.Mutex // Serializes flushes so buffered writes stay in order
+
+	faults *fault.Injector // Optional: simulated failures (fault_injection)
+}
+
+// NewResilientRepository wraps repo. timeout bounds each buffered write when it is flushed.
+func NewResilientRepository(repo Repository, cfg config.StorageResilienceConfig, timeout time.Duration) *ResilientRepository {
+	if cfg.FailureThreshold <= 0 {
+		cfg.FailureThreshold = 3
+	}
+	if cfg.OpenDuration <= 0 {
+		cfg.OpenDuration = 30 * time.Second
+	}
+	if cfg.FlushInterval <= 0 {
+		cfg.FlushInterval = 10 * time.Second
+	}
+	if cfg.MaxBuffered <= 0 {
+		cfg.MaxBuffered = 1000
+	}
+	if timeout <= 0 {
+		timeout = 5 * time.Second
+	}
+	return &ResilientRepository{
+		repo:    repo,
+		cfg:     cfg,
+		timeout: timeout,
+		kick:    make(chan struct{}, 1),
+	}
+}
+
+// Run flushes buffered writes on every flush interval, and soon after a write is buffered,
+// until ctx is done
+func (r *ResilientRepository) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.FlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-r.kick:
+		}
+		if r.Buffered() == 0 {
+			continue
+		}
+		if left := r.Flush(ctx); left == 0 {
+			slog.Info("storage buffer flushed")
+		}
+	}
+}
+
+// Buffered returns the number of writes waiting for storage
+func (r *ResilientRepository) Buffered() int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return len(r.buffer)
+}
+
+// Flush writes buffered records in order and stops at the first failure.
+// It returns the number of writes still buffered.
+func (r *ResilientRepository) Flush(ctx context.Context) int {
+	r.flushMu.Lock()
+	defer r.flushMu.Unlock()
+
+	for {
+		r.mu.Lock()
+		if len(r.buffer) == 0 {
+			r.mu.Unlock()
+			return 0
+		}
+		next := r.buffer[0]
+		r.mu.Unlock()
+
+		if !r.allow() {
+			return r.Buffered()
+		}
+		writeCtx, cancel := context.WithTimeout(ctx, r.timeout)
+		err := r.faults.Storage(writeCtx, fault.OpWrite)
+		if err == nil {
+			err = next.write(writeCtx)
+		}
+		cancel()
+		r.record(err)
+		if err != nil {
+			slog.Debug("flush buffered write failed", "kind", next.kind, "error", err)
+			return r.Buffered()
+		}
+
+		r.mu.Lock()
+		// The write may have been dropped meanwhile by a full buffer
+		if len(r.buffer) > 0 && r.buffer[0].seq == next.seq {
+			r.buffer = r.buffer[1:]
+		}
+		metrics.StorageBuffered.Set(float64(len(r.buffer)))
+		r.mu.Unlock()
+		metrics.StorageOperations.WithLabelValues("flushed").Inc()
+	}
+}
+
+// allow reports whether an operation may reach storage. Once the open duration has passed,
+// operations are let through again; the first failure reopens the circuit.
+func (r *ResilientRepository) allow() bool {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return !time.Now().Before(r.openUntil)
+}
+
+// record updates the circuit with the result of an operation. ErrNotFound is a success.
+func (r *ResilientRepository) record(err error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if err == nil || errors.Is(err, ErrNotFound) {
+		if r.failures >= r.cfg.FailureThreshold {
+			slog.Info("storage recovered", "buffered", len(r.buffer))
+		}
+		r.failures = 0
+		r.openUntil = time.Time{}
+		metrics.StorageOperations.WithLabelValues("success").Inc()
+		return
+	}
+
+	r.failures++
+	metrics.StorageOperations.WithLabelValues("error").Inc()
+	if r.failures >= r.cfg.FailureThreshold {
+		r.openUntil = time.Now().Add(r.cfg.OpenDuration)
+		if r.failures == r.cfg.FailureThreshold {
+			slog.Warn("storage circuit open", "failures", r.failures, "open_for", r.cfg.OpenDuration, "error", err)
+		}
+	}
+}
+
+// SetFaultInjector makes storage operations fail as configured in fault_injection
+func (r *ResilientRepository) SetFaultInjector(f *fault.Injector) {
+	r.faults = f
+}
+
+// guard runs an operation through the circuit without buffering; reads, purges and snapshots.
+// target is the operation's fault injection target, fault.OpRead or fault.OpWrite.
+func (r *ResilientRepository) guard(ctx context.Context, target string, op func() error) error {
+	if !r.allow() {
+		metrics.StorageOperations.WithLabelValues("rejected").Inc()
+		return ErrUnavailable
+	}
+	err := r.faults.Storage(ctx, target)
+	if err == nil {
+		err = op()
+	}
+	r.record(err)
+	return err
+}
+
+// write runs a write through the circuit and buffers it when storage is unavailable, so the
+// caller never sees a storage error. While writes are buffered, new ones queue behind them to
+// keep their order.
+func (r *ResilientRepository) write(ctx context.Context, kind string, write func(ctx context.Context) error) error {
+	if r.Buffered() == 0 && r.allow() {
+		err := r.faults.Storage(ctx, fault.OpWrite)
+		if err == nil {
+			err = write(ctx)
+		}
+		r.record(err)
+		if err == nil {
+			return nil
+		}
+		slog.Warn("storage write failed, buffering", "kind", kind, "error", err)
+	}
+	r.enqueue(kind, write)
+	return nil
+}
+
+func (r *ResilientRepository) enqueue(kind string, write func(ctx context.Context) error) {
+	r.mu.Lock()
+	if len(r.buffer) >= r.cfg.MaxBuffered {
+		slog.Warn("storage buffer full, dropping oldest write", "kind", r.buffer[0].kind, "max_buffered", r.cfg.MaxBuffered)
+		r.buffer = r.buffer[1:]
+		metrics.StorageOperations.WithLabelValues("dropped").Inc()
+	}
+	r.seq++
+	r.buffer = append(r.buffer, pendingWrite{seq: r.seq, kind: kind, write: write})
+	metrics.StorageBuffered.Set(float64(len(r.buffer)))
+	r.mu.Unlock()
+	metrics.StorageOperations.WithLabelValues("buffered").Inc()
+
+	select {
+	case r.kick <- struct{}{}:
+	default:
+	}
+}
+
+// SaveReview saves the review or buffers it while storage is unavailable
+func (r *ResilientRepository) SaveReview(ctx context.Context, record *ReviewRecord) error {
+	snapshot := *record
+	return r.write(ctx, "review", func(ctx context.Context) error {
+		return r.repo.SaveReview(ctx, &snapshot)
+	})
+}
+
+// SetReviewStatus sets the status of a stored review or buffers the change while storage is
+// unavailable, after the buffered save of the review
+func (r *ResilientRepository) SetReviewStatus(ctx context.Context, id, status string, durationMs int64) error {
+	store, ok := r.repo.(ReviewStatusRepository)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	return r.write(ctx, "review", func(ctx context.Context) error {
+		return store.SetReviewStatus(ctx, id, status, durationMs)
+	})
+}
+
+// GetReview retrieves a review by ID
+func (r *ResilientRepository) GetReview(ctx context.Context, id string) (*ReviewRecord, error) {
+	var record *ReviewRecord
+	err := r.guard(ctx, fault.OpRead, func() (err error) {
+		record, err = r.repo.GetReview(ctx, id)
+		return err
+	})
+	return record, err
+}
+
+// ListReviewsByPR lists the stored reviews of a PR
+func (r *ResilientRepository) ListReviewsByPR(ctx context.Context, projectKey, repoSlug, prID string) ([]*ReviewRecord, error) {
+	var records []*ReviewRecord
+	err := r.guard(ctx, fault.OpRead, func() (err error) {
+		records, err = r.repo.ListReviewsByPR(ctx, projectKey, repoSlug, prID)
+		return err
+	})
+	return records, err
+}
+
+// ListRecentReviews lists the most recent reviews
+func (r *ResilientRepository) ListRecentReviews(ctx context.Context, limit int) ([]*ReviewRecord, error) {
+	var records []*ReviewRecord
+	err := r.guard(ctx, fault.OpRead, func() (err error) {
+		records, err = r.repo.ListRecentReviews(ctx, limit)
+		return err
+	})
+	return records, err
+}
+
+// ListReviews lists the most recent reviews matching the filter
+func (r *ResilientRepository) ListReviews(ctx context.Context, filter ReviewFilter, limit int) ([]*ReviewRecord, error) {
+	var records []*ReviewRecord
+	err := r.guard(ctx, fault.OpRead, func() (err error) {
+		records, err = r.repo.ListReviews(ctx, filter, limit)
+		return err
+	})
+	return records, err
+}
+
+// PurgeOlderThan deletes records of a data class created before cutoff
+func (r *ResilientRepository) PurgeOlderThan(ctx context.Context, dataClass string, cutoff time.Time) (int64, error) {
+	var n int64
+	err := r.guard(ctx, fault.OpWrite, func() (err error) {
+		n, err = r.repo.PurgeOlderThan(ctx, dataClass, cutoff)
+		return err
+	})
+	return n, err
+}
+
+// Purge deletes all stored data matching the filter. Buffered writes are flushed first so
+// they cannot reintroduce purged data later.
+func (r *ResilientRepository) Purge(ctx context.Context, filter PurgeFilter, requestedBy, reason string) (int64, error) {
+	if left := r.Flush(ctx); left > 0 {
+		return 0, ErrUnavailable
+	}
+	var n int64
+	err := r.guard(ctx, fault.OpWrite, func() (err error) {
+		n, err = r.repo.Purge(ctx, filter, requestedBy, reason)
+		return err
+	})
+	return n, err
+}
+
+// Close flushes what it can and closes the wrapped repository
+func (r *ResilientRepository) Close() error {
+	// One last attempt regardless of the circuit
+	r.mu.Lock()
+	r.openUntil = time.Time{}
+	r.mu.Unlock()
+
+	ctx, cancel := context.WithTimeout(context.Background(), r.timeout)
+	defer cancel()
+	if left := r.Flush(ctx); left > 0 {
+		slog.Error("storage closed with unsaved records", "records", left)
+	}
+	return r.repo.Close()
+}
+
+// SaveSkip saves a skip ledger entry or buffers it while storage is unavailable
+func (r *ResilientRepository) SaveSkip(ctx context.Context, s *SkipRecord) error {
+	ledger, ok := r.repo.(SkipRepository)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	snapshot := *s
+	return r.write(ctx, "skip", func(ctx context.Context) error {
+		return ledger.SaveSkip(ctx, &snapshot)
+	})
+}
+
+// ListSkips returns the most recent skip ledger entries first
+func (r *ResilientRepository) ListSkips(ctx context.Context, limit int) ([]*SkipRecord, error) {
+	ledger, ok := r.repo.(SkipRepository)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	var skips []*SkipRecord
+	err := r.guard(ctx, fault.OpRead, func() (err error) {
+		skips, err = ledger.ListSkips(ctx, limit)
+		return err
+	})
+	return skips, err
+}
+
+// SaveFingerprint replaces the fingerprint of the PR or buffers it while storage is unavailable
+func (r *ResilientRepository) SaveFingerprint(ctx context.Context, f *FingerprintRecord) error {
+	store, ok := r.repo.(FingerprintRepository)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	snapshot := *f
+	return r.write(ctx, "fingerprint", func(ctx context.Context) error {
+		return store.SaveFingerprint(ctx, &snapshot)
+	})
+}
+
+// ListFingerprints returns the fingerprints of a repository updated since the given time
+func (r *ResilientRepository) ListFingerprints(ctx context.Context, projectKey, repoSlug string, since time.Time) ([]*FingerprintRecord, error) {
+	store, ok := r.repo.(FingerprintRepository)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	var records []*FingerprintRecord
+	err := r.guard(ctx, fault.OpRead, func() (err error) {
+		records, err = store.ListFingerprints(ctx, projectKey, repoSlug, since)
+		return err
+	})
+	return records, err
+}
+
+// GetTuning returns the tuning state of a repository
+func (r *ResilientRepository) GetTuning(ctx context.Context, projectKey, repoSlug string) (*ChunkTuning, error) {
+	store, ok := r.repo.(TuningRepository)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	var tuning *ChunkTuning
+	err := r.guard(ctx, fault.OpRead, func() (err error) {
+		tuning, err = store.GetTuning(ctx, projectKey, repoSlug)
+		return err
+	})
+	return tuning, err
+}
+
+// SaveTuning saves the tuning state of a repository or buffers it while storage is unavailable
+func (r *ResilientRepository) SaveTuning(ctx context.Context, t *ChunkTuning) error {
+	store, ok := r.repo.(TuningRepository)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	snapshot := *t
+	return r.write(ctx, "tuning", func(ctx context.Context) error {
+		return store.SaveTuning(ctx, &snapshot)
+	})
+}
+
+// ReviewSpend returns the LLM usage of the reviews created since the given time, by project
+func (r *ResilientRepository) ReviewSpend(ctx context.Context, since time.Time) ([]*ProjectSpend, error) {
+	store, ok := r.repo.(SpendRepository)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	var spend []*ProjectSpend
+	err := r.guard(ctx, fault.OpRead, func() (err error) {
+		spend, err = store.ReviewSpend(ctx, since)
+		return err
+	})
+	return spend, err
+}
+
+// SaveQueuedReviews saves the pending review snapshot. It is not buffered: the caller must know
+// whether the snapshot is durable.
+func (r *ResilientRepository) SaveQueuedReviews(ctx context.Context, reviews []*QueuedReview) error {
+	store, ok := r.repo.(QueueRepository)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	return r.guard(ctx, fault.OpWrite, func() error {
+		return store.SaveQueuedReviews(ctx, reviews)
+	})
+}
+
+// TakeQueuedReviews returns and removes the pending review snapshot
+func (r *ResilientRepository) TakeQueuedReviews(ctx context.Context) ([]*QueuedReview, error) {
+	store, ok := r.repo.(QueueRepository)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	var reviews []*QueuedReview
+	err := r.guard(ctx, fault.OpWrite, func() (err error) {
+		reviews, err = store.TakeQueuedReviews(ctx)
+		return err
+	})
+	return reviews, err
+}
+
+// SaveJob journals an accepted review. It is not buffered: a buffered journal entry would not
+// survive the crash it guards against.
+func (r *ResilientRepository) SaveJob(ctx context.Context, job *QueuedJob) error {
+	store, ok := r.repo.(JobRepository)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	return r.guard(ctx, fault.OpWrite, func() error {
+		return store.SaveJob(ctx, job)
+	})
+}
+
+// DeleteJob removes a finished review from the journal
+func (r *ResilientRepository) DeleteJob(ctx context.Context, key string, seq int64) error {
+	store, ok := r.repo.(JobRepository)
+	if !ok {
+		return errors.ErrUnsupported
+	}
+	return r.guard(ctx, fault.OpWrite, func() error {
+		return store.DeleteJob(ctx, key, seq)
+	})
+}
+
+// ListJobs returns the journaled reviews
+func (r *ResilientRepository) ListJobs(ctx context.Context) ([]*QueuedJob, error) {
+	store, ok := r.repo.(JobRepository)
+	if !ok {
+		return nil, errors.ErrUnsupported
+	}
+	var jobs []*QueuedJob
+	err := r.guard(ctx, fault.OpRead, func() (err error) {
+		jobs, err = store.ListJobs(ctx)
+		return err
+	})
+	return jobs, err
+}
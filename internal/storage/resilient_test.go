@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/fault"
+)
+
+// flakyRepo fails writes and reads with a lock error while down is set
+type flakyRepo struct {
+	*SQLiteRepository
+	down  atomic.Bool
+	calls atomic.Int32
+}
+
+var errLocked = errors.New("database is locked")
+
+func (f *flakyRepo) SaveReview(ctx context.Context, r *ReviewRecord) error {
+	f.calls.Add(1)
+	if f.down.Load() {
+		return errLocked
+	}
+	return f.SQLiteRepository.SaveReview(ctx, r)
+}
+
+func (f *flakyRepo) SaveSkip(ctx context.Context, s *SkipRecord) error {
+	f.calls.Add(1)
+	if f.down.Load() {
+		return errLocked
+	}
+	return f.SQLiteRepository.SaveSkip(ctx, s)
+}
+
+func (f *flakyRepo) ListRecentReviews(ctx context.Context, limit int) ([]*ReviewRecord, error) {
+	f.calls.Add(1)
+	if f.down.Load() {
+		return nil, errLocked
+	}
+	return f.SQLiteRepository.ListRecentReviews(ctx, limit)
+}
+
+func reviewRecord(id string) *ReviewRecord {
+	return &ReviewRecord{
+		ID:          id,
+		PullRequest: &domain.PullRequest{ProjectKey: "PROJ", RepoSlug: "repo", ID: "1"},
+		Result:      &domain.ReviewResult{Summary: id},
+		CreatedAt:   time.Now(),
+		Status:      "success",
+	}
+}
+
+func TestResilientRepository_BuffersUntilRecovery(t *testing.T) {
+	flaky := &flakyRepo{SQLiteRepository: newTestRepo(t)}
+	repo := NewResilientRepository(flaky, config.StorageResilienceConfig{FailureThreshold: 2, OpenDuration: 20 * time.Millisecond, MaxBuffered: 10}, time.Second)
+	ctx := context.Background()
+
+	flaky.down.Store(true)
+	for _, id := range []string{"r1", "r2"} {
+		if err := repo.SaveReview(ctx, reviewRecord(id)); err != nil {
+			t.Fatalf("save must not fail while storage is down: %v", err)
+		}
+	}
+	if err := repo.SaveSkip(ctx, &SkipRecord{ProjectKey: "PROJ", RepoSlug: "repo", PRID: "1", Reason: "budget"}); err != nil {
+		t.Fatal(err)
+	}
+	if n := repo.Buffered(); n != 3 {
+		t.Fatalf("buffered = %d, want 3", n)
+	}
+
+	// The first save failed; later ones queue behind it without touching storage
+	if calls := flaky.calls.Load(); calls != 1 {
+		t.Errorf("storage calls = %d, want 1", calls)
+	}
+	if left := repo.Flush(ctx); left != 3 {
+		t.Fatalf("flush while down left %d, want 3", left)
+	}
+	if _, err := repo.ListRecentReviews(ctx, 10); !errors.Is(err, ErrUnavailable) {
+		t.Fatalf("read with open circuit = %v, want ErrUnavailable", err)
+	}
+
+	flaky.down.Store(false)
+	time.Sleep(30 * time.Millisecond)
+	if left := repo.Flush(ctx); left != 0 {
+		t.Fatalf("flush after recovery left %d", left)
+	}
+
+	records, err := repo.ListRecentReviews(ctx, 10)
+	if err != nil || len(records) != 2 {
+		t.Fatalf("records = %d, err = %v", len(records), err)
+	}
+	if skips, err := repo.ListSkips(ctx, 10); err != nil || len(skips) != 1 {
+		t.Errorf("skips = %v, err = %v", skips, err)
+	}
+}
+
+func TestResilientRepository_DropsOldestWhenFull(t *testing.T) {
+	flaky := &flakyRepo{SQLiteRepository: newTestRepo(t)}
+	repo := NewResilientRepository(flaky, config.StorageResilienceConfig{FailureThreshold: 1, OpenDuration: time.Millisecond, MaxBuffered: 2}, time.Second)
+	ctx := context.Background()
+
+	flaky.down.Store(true)
+	for _, id := range []string{"r1", "r2", "r3"} {
+		repo.SaveReview(ctx, reviewRecord(id))
+	}
+	if n := repo.Buffered(); n != 2 {
+		t.Fatalf("buffered = %d, want 2", n)
+	}
+
+	flaky.down.Store(false)
+	time.Sleep(5 * time.Millisecond)
+	repo.Flush(ctx)
+	if _, err := repo.GetReview(ctx, "r1"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("oldest write must be dropped, got %v", err)
+	}
+	if r, err := repo.GetReview(ctx, "r3"); err != nil || r.Result.Summary != "r3" {
+		t.Errorf("newest write = %v, %v", r, err)
+	}
+}
+
+func TestResilientRepository_NotFoundKeepsCircuitClosed(t *testing.T) {
+	repo := NewResilientRepository(newTestRepo(t), config.StorageResilienceConfig{FailureThreshold: 1, OpenDuration: time.Hour}, time.Second)
+	ctx := context.Background()
+
+	for i := 0; i < 3; i++ {
+		if _, err := repo.GetReview(ctx, "missing"); !errors.Is(err, ErrNotFound) {
+			t.Fatalf("attempt %d: err = %v, want ErrNotFound", i, err)
+		}
+	}
+}
+
+func TestResilientRepository_InjectedFaults(t *testing.T) {
+	repo := NewResilientRepository(newTestRepo(t), config.StorageResilienceConfig{FailureThreshold: 1, OpenDuration: time.Hour}, time.Second)
+	repo.SetFaultInjector(fault.New(config.FaultInjectionConfig{Enabled: true}))
+	ctx := context.Background()
+
+	// Only the review that asked for the fault sees it
+	if _, err := repo.ListRecentReviews(ctx, 10); err != nil {
+		t.Fatalf("read without fault failed: %v", err)
+	}
+	faulty := fault.WithFaults(ctx, []string{config.FaultStorageError})
+	if err := repo.SaveReview(faulty, reviewRecord("r1")); err != nil {
+		t.Fatalf("save must be buffered, got %v", err)
+	}
+	if n := repo.Buffered(); n != 1 {
+		t.Errorf("buffered = %d, want 1", n)
+	}
+	if _, err := repo.ListRecentReviews(ctx, 10); !errors.Is(err, ErrUnavailable) {
+		t.Errorf("injected failure must open the circuit, got %v", err)
+	}
+}
@@ -0,0 +1,90 @@
+package storage
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Data classes subject to retention policies
+const (
+	DataClassReviews = "reviews" // Review records (PR metadata, comments, summaries)
+	DataClassSkips   = "skips"   // Skip ledger entries
+)
+
+// PurgeFilter selects the records to delete in a data subject purge.
+// Empty fields are ignored; at least one field must be set.
+type PurgeFilter struct {
+	ProjectKey string `json:"projectKey,omitempty"`
+	RepoSlug   string `json:"repoSlug,omitempty"`
+	Author     string `json:"author,omitempty"`
+}
+
+// IsEmpty reports whether the filter has no criteria
+func (f PurgeFilter) IsEmpty() bool {
+	return f.ProjectKey == "" && f.RepoSlug == "" && f.Author == ""
+}
+
+// PurgeAudit describes a purge for the audit trail
+type PurgeAudit struct {
+	Scope       string    `json:"scope"` // retention:<class> or subject
+	Filter      string    `json:"filter"`
+	Rows        int64     `json:"rows"`
+	RequestedBy string    `json:"requested_by"`
+	Reason      string    `json:"reason"`
+	CreatedAt   time.Time `json:"created_at"`
+}
+
+// RetentionManager periodically deletes data older than the configured age per data class
+type RetentionManager struct {
+	repo     Repository
+	policies map[string]time.Duration
+	interval time.Duration
+}
+
+// NewRetentionManager creates a RetentionManager. Classes with a zero or negative age are kept forever.
+func NewRetentionManager(repo Repository, policies map[string]time.Duration, interval time.Duration) *RetentionManager {
+	if interval <= 0 {
+		interval = time.Hour
+	}
+	return &RetentionManager{
+		repo:     repo,
+		policies: policies,
+		interval: interval,
+	}
+}
+
+// Run applies the policies immediately and then on every interval until ctx is done
+func (m *RetentionManager) Run(ctx context.Context) {
+	if len(m.policies) == 0 {
+		return
+	}
+	ticker := time.NewTicker(m.interval)
+	defer ticker.Stop()
+
+	for {
+		m.apply(ctx)
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (m *RetentionManager) apply(ctx context.Context) {
+	for class, maxAge := range m.policies {
+		if maxAge <= 0 {
+			continue
+		}
+		cutoff := time.Now().Add(-maxAge)
+		n, err := m.repo.PurgeOlderThan(ctx, class, cutoff)
+		if err != nil {
+			slog.Warn("retention purge failed", "class", class, "error", err)
+			continue
+		}
+		if n > 0 {
+			slog.Info("retention purge", "class", class, "rows", n, "cutoff", cutoff)
+		}
+	}
+}
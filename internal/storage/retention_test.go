@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"context"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/domain"
+)
+
+func newTestRepo(t *testing.T) *SQLiteRepository {
+	t.Helper()
+	repo, err := NewSQLiteRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("failed to create repository: %v", err)
+	}
+	t.Cleanup(func() { repo.Close() })
+	return repo
+}
+
+func saveTestReview(t *testing.T, repo *SQLiteRepository, id, project, repoSlug, author string, createdAt time.Time) {
+	t.Helper()
+	err := repo.SaveReview(context.Background(), &ReviewRecord{
+		ID:          id,
+		PullRequest: &domain.PullRequest{ID: id, ProjectKey: project, RepoSlug: repoSlug, Author: author},
+		Result:      &domain.ReviewResult{Summary: "ok"},
+		CreatedAt:   createdAt,
+		Status:      "success",
+	})
+	if err != nil {
+		t.Fatalf("SaveReview failed: %v", err)
+	}
+}
+
+func TestSQLiteRepository_PurgeOlderThan(t *testing.T) {
+	repo := newTestRepo(t)
+	now := time.Now()
+	saveTestReview(t, repo, "old", "P", "r", "alice", now.Add(-48*time.Hour))
+	saveTestReview(t, repo, "new", "P", "r", "alice", now)
+
+	n, err := repo.PurgeOlderThan(context.Background(), DataClassReviews, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatalf("PurgeOlderThan failed: %v", err)
+	}
+	if n != 1 {
+		t.Errorf("expected 1 purged record, got %d", n)
+	}
+	if _, err := repo.GetReview(context.Background(), "new"); err != nil {
+		t.Errorf("recent record should be kept: %v", err)
+	}
+
+	if _, err := repo.PurgeOlderThan(context.Background(), "unknown", now); err == nil {
+		t.Error("expected error for unknown data class")
+	}
+}
+
+func TestSQLiteRepository_Purge(t *testing.T) {
+	repo := newTestRepo(t)
+	now := time.Now()
+	saveTestReview(t, repo, "1", "P", "r1", "alice", now)
+	saveTestReview(t, repo, "2", "P", "r2", "bob", now)
+	saveTestReview(t, repo, "3", "Q", "r1", "alice", now)
+
+	ctx := context.Background()
+	if _, err := repo.Purge(ctx, PurgeFilter{}, "admin", ""); err == nil {
+		t.Error("expected error for empty filter")
+	}
+
+	n, err := repo.Purge(ctx, PurgeFilter{Author: "alice"}, "dpo@example.com", "erasure request")
+	if err != nil {
+		t.Fatalf("Purge failed: %v", err)
+	}
+	if n != 2 {
+		t.Errorf("expected 2 purged records, got %d", n)
+	}
+
+	var audits int
+	if err := repo.db.QueryRowContext(ctx, "SELECT COUNT(*) FROM purge_audit WHERE requested_by = ?", "dpo@example.com").Scan(&audits); err != nil {
+		t.Fatalf("audit query failed: %v", err)
+	}
+	if audits != 1 {
+		t.Errorf("expected 1 audit entry, got %d", audits)
+	}
+
+	remaining, err := repo.ListRecentReviews(ctx, 10)
+	if err != nil {
+		t.Fatalf("ListRecentReviews failed: %v", err)
+	}
+	if len(remaining) != 1 || remaining[0].ID != "2" {
+		t.Errorf("expected only record 2 to remain, got %d records", len(remaining))
+	}
+}
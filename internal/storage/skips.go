@@ -0,0 +1,25 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// SkipRecord is a skip ledger entry: a PR event that did not get a review
+type SkipRecord struct {
+	ProjectKey string    `json:"projectKey"`
+	RepoSlug   string    `json:"repoSlug"`
+	PRID       string    `json:"prId"`
+	Commit     string    `json:"commit,omitempty"`
+	Author     string    `json:"author,omitempty"`
+	Reason     string    `json:"reason"` // domain.SkipReason*
+	Detail     string    `json:"detail,omitempty"`
+	CreatedAt  time.Time `json:"createdAt"`
+}
+
+// SkipRepository persists the review skip ledger
+type SkipRepository interface {
+	SaveSkip(ctx context.Context, s *SkipRecord) error
+	// ListSkips returns the most recent entries first
+	ListSkips(ctx context.Context, limit int) ([]*SkipRecord, error)
+}
@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+func TestSQLiteRepository_Skips(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	old := &SkipRecord{ProjectKey: "PROJ", RepoSlug: "repo", PRID: "1", Reason: "size_gate", CreatedAt: time.Now().Add(-48 * time.Hour)}
+	recent := &SkipRecord{ProjectKey: "PROJ", RepoSlug: "repo", PRID: "2", Author: "alice", Reason: "budget", Detail: "daily limit"}
+	for _, s := range []*SkipRecord{old, recent} {
+		if err := repo.SaveSkip(ctx, s); err != nil {
+			t.Fatalf("save: %v", err)
+		}
+	}
+
+	skips, err := repo.ListSkips(ctx, 10)
+	if err != nil {
+		t.Fatalf("list: %v", err)
+	}
+	if len(skips) != 2 || skips[0].PRID != "2" || skips[0].Detail != "daily limit" {
+		t.Fatalf("unexpected skips: %+v", skips)
+	}
+
+	n, err := repo.PurgeOlderThan(ctx, DataClassSkips, time.Now().Add(-24*time.Hour))
+	if err != nil || n != 1 {
+		t.Fatalf("retention: n=%d err=%v", n, err)
+	}
+
+	n, err = repo.Purge(ctx, PurgeFilter{Author: "alice"}, "test", "subject request")
+	if err != nil || n != 1 {
+		t.Fatalf("purge: n=%d err=%v", n, err)
+	}
+	if skips, _ := repo.ListSkips(ctx, 10); len(skips) != 0 {
+		t.Errorf("expected empty ledger, got %+v", skips)
+	}
+}
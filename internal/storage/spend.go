@@ -0,0 +1,41 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// ProjectSpend is the LLM usage of the reviews of one project
+type ProjectSpend struct {
+	ProjectKey string  `json:"projectKey"`
+	Tokens     int64   `json:"tokens"` // Prompt and completion tokens
+	Cost       float64 `json:"cost"`   // USD
+}
+
+// SpendRepository sums the LLM usage of stored reviews
+type SpendRepository interface {
+	// ReviewSpend returns the usage of the reviews created since the given time, by project
+	ReviewSpend(ctx context.Context, since time.Time) ([]*ProjectSpend, error)
+}
+
+func (r *SQLiteRepository) ReviewSpend(ctx context.Context, since time.Time) ([]*ProjectSpend, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT project_key, SUM(tokens), SUM(cost)
+        FROM reviews WHERE created_at >= ?
+        GROUP BY project_key ORDER BY project_key
+    `, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var spend []*ProjectSpend
+	for rows.Next() {
+		var s ProjectSpend
+		if err := rows.Scan(&s.ProjectKey, &s.Tokens, &s.Cost); err != nil {
+			return nil, err
+		}
+		spend = append(spend, &s)
+	}
+	return spend, rows.Err()
+}
@@ -0,0 +1,52 @@
+package storage
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/domain"
+)
+
+func TestSQLiteRepository_ReviewSpend(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	now := time.Now().UTC()
+	for i, r := range []struct {
+		project string
+		age     time.Duration
+		tokens  int64
+		cost    float64
+	}{
+		{"PAY", time.Hour, 1000, 0.5},
+		{"PAY", 2 * time.Hour, 500, 0.25},
+		{"PAY", 48 * time.Hour, 9000, 9},
+		{"OPS", time.Minute, 100, 0.1},
+	} {
+		if err := repo.SaveReview(ctx, &ReviewRecord{
+			ID:          string(rune('a' + i)),
+			PullRequest: &domain.PullRequest{ID: "1", ProjectKey: r.project, RepoSlug: "repo"},
+			Result:      &domain.ReviewResult{Usage: &domain.TokenUsage{PromptTokens: r.tokens - 10, CompletionTokens: 10}},
+			CreatedAt:   now.Add(-r.age),
+			Status:      "success",
+			Cost:        r.cost,
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	spend, err := repo.ReviewSpend(ctx, now.Add(-24*time.Hour))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(spend) != 2 {
+		t.Fatalf("expected 2 projects, got %+v", spend)
+	}
+	if s := spend[0]; s.ProjectKey != "OPS" || s.Tokens != 100 || s.Cost != 0.1 {
+		t.Errorf("unexpected spend %+v", s)
+	}
+	if s := spend[1]; s.ProjectKey != "PAY" || s.Tokens != 1500 || s.Cost != 0.75 {
+		t.Errorf("unexpected spend %+v", s)
+	}
+}
@@ -4,16 +4,19 @@ import (
 	"context"
 	"database/sql"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log/slog"
 	"pr-review-automation/internal/domain"
+	"strings"
 	"time"
 
 	_ "modernc.org/sqlite" // Pure Go driver, CGO-free, compatible with CGO_ENABLED=0
 )
 
 type SQLiteRepository struct {
-	db *sql.DB
+	db     *sql.DB
+	cipher *Cipher // Optional: encrypts review results, queued PRs and webhook bodies and PR titles at rest
 }
 
 func NewSQLiteRepository(dsn string) (*SQLiteRepository, error) {
@@ -56,11 +59,115 @@ func migrate(db *sql.DB) error {
     );
     CREATE INDEX IF NOT EXISTS idx_reviews_pr ON reviews(project_key, repo_slug, pr_id);
     CREATE INDEX IF NOT EXISTS idx_reviews_created ON reviews(created_at);
+
+    CREATE TABLE IF NOT EXISTS purge_audit (
+        id           INTEGER PRIMARY KEY AUTOINCREMENT,
+        scope        TEXT NOT NULL,
+        filter       TEXT NOT NULL,
+        rows         INTEGER NOT NULL,
+        requested_by TEXT,
+        reason       TEXT,
+        created_at   DATETIME DEFAULT CURRENT_TIMESTAMP
+    );
+
+    CREATE TABLE IF NOT EXISTS chunk_tuning (
+        project_key   TEXT NOT NULL,
+        repo_slug     TEXT NOT NULL,
+        max_tokens    INTEGER NOT NULL,
+        context_lines INTEGER NOT NULL,
+        chunks        INTEGER NOT NULL DEFAULT 0,
+        overflows     INTEGER NOT NULL DEFAULT 0,
+        empties       INTEGER NOT NULL DEFAULT 0,
+        updated_at    DATETIME,
+        PRIMARY KEY (project_key, repo_slug)
+    );
+
+    CREATE TABLE IF NOT EXISTS review_skips (
+        id          INTEGER PRIMARY KEY AUTOINCREMENT,
+        project_key TEXT NOT NULL,
+        repo_slug   TEXT NOT NULL,
+        pr_id       TEXT NOT NULL,
+        commit_id   TEXT,
+        author      TEXT,
+        reason      TEXT NOT NULL,
+        detail      TEXT,
+        created_at  DATETIME NOT NULL
+    );
+    CREATE INDEX IF NOT EXISTS idx_review_skips_created ON review_skips(created_at);
+
+    CREATE TABLE IF NOT EXISTS diff_fingerprints (
+        project_key TEXT NOT NULL,
+        repo_slug   TEXT NOT NULL,
+        pr_id       TEXT NOT NULL,
+        commit_id   TEXT,
+        title       TEXT,
+        web_url     TEXT,
+        lines       TEXT NOT NULL,
+        updated_at  DATETIME NOT NULL,
+        PRIMARY KEY (project_key, repo_slug, pr_id)
+    );
+
+    CREATE TABLE IF NOT EXISTS queued_reviews (
+        queue_key   TEXT PRIMARY KEY,
+        project_key TEXT NOT NULL,
+        repo_slug   TEXT NOT NULL,
+        pr_id       TEXT NOT NULL,
+        pr_data     TEXT NOT NULL,
+        created_at  DATETIME NOT NULL
+    );
+
+    CREATE TABLE IF NOT EXISTS queued_jobs (
+        queue_key   TEXT PRIMARY KEY,
+        seq         INTEGER NOT NULL,
+        project_key TEXT NOT NULL,
+        repo_slug   TEXT NOT NULL,
+        pr_id       TEXT NOT NULL,
+        payload     BLOB,
+        pr_data     TEXT,
+        created_at  DATETIME NOT NULL
+    );
     `
-	_, err := db.Exec(schema)
+	if _, err := db.Exec(schema); err != nil {
+		return err
+	}
+	// Columns added after the first release
+	if err := addColumn(db, "reviews", "cost", "REAL NOT NULL DEFAULT 0"); err != nil {
+		return err
+	}
+	return addColumn(db, "reviews", "tokens", "INTEGER NOT NULL DEFAULT 0")
+}
+
+// addColumn adds a column to a table created by an older version
+func addColumn(db *sql.DB, table, column, definition string) error {
+	var exists bool
+	err := db.QueryRow("SELECT COUNT(*) > 0 FROM pragma_table_info(?) WHERE name = ?", table, column).Scan(&exists)
+	if err != nil || exists {
+		return err
+	}
+	_, err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s %s", table, column, definition))
 	return err
 }
 
+// SetCipher enables encryption at rest. It covers reviews.result_data, queued_reviews.pr_data,
+// queued_jobs.payload and pr_data, and diff_fingerprints.title; reviews.pr_data stays plaintext
+// for the author filter of ListReviews. Existing plaintext rows remain readable.
+func (r *SQLiteRepository) SetCipher(c *Cipher) {
+	r.cipher = c
+}
+
+// seal encrypts value bound to additionalData when a cipher is set
+func (r *SQLiteRepository) seal(value, additionalData string) (string, error) {
+	if r.cipher == nil || value == "" {
+		return value, nil
+	}
+	return r.cipher.Encrypt(value, additionalData)
+}
+
+// fingerprintID binds an encrypted fingerprint column to its PR
+func fingerprintID(projectKey, repoSlug, prID string) string {
+	return projectKey + "/" + repoSlug + "/" + prID
+}
+
 func (r *SQLiteRepository) SaveReview(ctx context.Context, record *ReviewRecord) error {
 	prData, err := json.Marshal(record.PullRequest)
 	if err != nil {
@@ -72,25 +179,48 @@ func (r *SQLiteRepository) SaveReview(ctx context.Context, record *ReviewRecord)
 		return fmt.Errorf("marshal result: %w", err)
 	}
 
+	storedResult := string(resultData)
+	if r.cipher != nil {
+		storedResult, err = r.cipher.Encrypt(storedResult, record.ID)
+		if err != nil {
+			return fmt.Errorf("encrypt result: %w", err)
+		}
+	}
+
+	// Token usage is kept next to the result, which may be encrypted, for ReviewSpend
+	var tokens int64
+	if record.Result != nil && record.Result.Usage != nil {
+		tokens = record.Result.Usage.PromptTokens + record.Result.Usage.CompletionTokens
+	}
+
 	_, err = r.db.ExecContext(ctx, `
-        INSERT INTO reviews (id, project_key, repo_slug, pr_id, pr_data, result_data, duration_ms, status, created_at)
-        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?)
+        INSERT INTO reviews (id, project_key, repo_slug, pr_id, pr_data, result_data, duration_ms, status, created_at, cost, tokens)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)
     `, record.ID, record.PullRequest.ProjectKey, record.PullRequest.RepoSlug,
-		record.PullRequest.ID, string(prData), string(resultData), record.DurationMs, record.Status, record.CreatedAt)
+		record.PullRequest.ID, string(prData), storedResult, record.DurationMs, record.Status, record.CreatedAt.UTC(), record.Cost, tokens)
+	return err
+}
+
+func (r *SQLiteRepository) SetReviewStatus(ctx context.Context, id, status string, durationMs int64) error {
+	_, err := r.db.ExecContext(ctx, `UPDATE reviews SET status = ?, duration_ms = ? WHERE id = ?`, status, durationMs, id)
 	return err
 }
 
 func (r *SQLiteRepository) GetReview(ctx context.Context, id string) (*ReviewRecord, error) {
 	row := r.db.QueryRowContext(ctx, `
-        SELECT id, pr_data, result_data, created_at, duration_ms, status
+        SELECT id, pr_data, result_data, created_at, duration_ms, status, cost
         FROM reviews WHERE id = ?
     `, id)
-	return scanReview(row)
+	record, err := r.scanReview(row)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	return record, err
 }
 
 func (r *SQLiteRepository) ListReviewsByPR(ctx context.Context, projectKey, repoSlug, prID string) ([]*ReviewRecord, error) {
 	rows, err := r.db.QueryContext(ctx, `
-        SELECT id, pr_data, result_data, created_at, duration_ms, status
+        SELECT id, pr_data, result_data, created_at, duration_ms, status, cost
         FROM reviews 
         WHERE project_key = ? AND repo_slug = ? AND pr_id = ?
         ORDER BY created_at DESC
@@ -102,7 +232,7 @@ func (r *SQLiteRepository) ListReviewsByPR(ctx context.Context, projectKey, repo
 
 	var reviews []*ReviewRecord
 	for rows.Next() {
-		record, err := scanReview(rows)
+		record, err := r.scanReview(rows)
 		if err != nil {
 			slog.Warn("scan review failed", "error", err)
 			continue
@@ -114,7 +244,7 @@ func (r *SQLiteRepository) ListReviewsByPR(ctx context.Context, projectKey, repo
 
 func (r *SQLiteRepository) ListRecentReviews(ctx context.Context, limit int) ([]*ReviewRecord, error) {
 	rows, err := r.db.QueryContext(ctx, `
-        SELECT id, pr_data, result_data, created_at, duration_ms, status
+        SELECT id, pr_data, result_data, created_at, duration_ms, status, cost
         FROM reviews 
         ORDER BY created_at DESC
         LIMIT ?
@@ -126,7 +256,36 @@ func (r *SQLiteRepository) ListRecentReviews(ctx context.Context, limit int) ([]
 
 	var reviews []*ReviewRecord
 	for rows.Next() {
-		record, err := scanReview(rows)
+		record, err := r.scanReview(rows)
+		if err != nil {
+			slog.Warn("scan review failed", "error", err)
+			continue
+		}
+		reviews = append(reviews, record)
+	}
+	return reviews, rows.Err()
+}
+
+func (r *SQLiteRepository) ListReviews(ctx context.Context, filter ReviewFilter, limit int) ([]*ReviewRecord, error) {
+	query := `
+        SELECT id, pr_data, result_data, created_at, duration_ms, status, cost
+        FROM reviews
+        WHERE project_key = ?`
+	args := []any{filter.ProjectKey}
+	if filter.RepoSlug != "" {
+		query += " AND repo_slug = ?"
+		args = append(args, filter.RepoSlug)
+	}
+	query += " ORDER BY created_at DESC LIMIT ?"
+	rows, err := r.db.QueryContext(ctx, query, append(args, limit)...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var reviews []*ReviewRecord
+	for rows.Next() {
+		record, err := r.scanReview(rows)
 		if err != nil {
 			slog.Warn("scan review failed", "error", err)
 			continue
@@ -136,6 +295,401 @@ func (r *SQLiteRepository) ListRecentReviews(ctx context.Context, limit int) ([]
 	return reviews, rows.Err()
 }
 
+func (r *SQLiteRepository) PurgeOlderThan(ctx context.Context, dataClass string, cutoff time.Time) (int64, error) {
+	var query string
+	switch dataClass {
+	case DataClassReviews:
+		query = "DELETE FROM reviews WHERE created_at < ?"
+	case DataClassSkips:
+		query = "DELETE FROM review_skips WHERE created_at < ?"
+	default:
+		return 0, fmt.Errorf("unknown data class: %s", dataClass)
+	}
+
+	// Timestamps are stored in UTC so text comparison orders correctly
+	res, err := r.db.ExecContext(ctx, query, cutoff.UTC())
+	if err != nil {
+		return 0, err
+	}
+	n, _ := res.RowsAffected()
+	if n > 0 {
+		if err := r.writeAudit(ctx, PurgeAudit{
+			Scope:       "retention:" + dataClass,
+			Filter:      "created_at < " + cutoff.UTC().Format(time.RFC3339),
+			Rows:        n,
+			RequestedBy: "retention",
+		}); err != nil {
+			slog.Warn("write purge audit failed", "error", err)
+		}
+	}
+	return n, nil
+}
+
+func (r *SQLiteRepository) Purge(ctx context.Context, filter PurgeFilter, requestedBy, reason string) (int64, error) {
+	if filter.IsEmpty() {
+		return 0, fmt.Errorf("purge filter must not be empty")
+	}
+
+	var conds []string
+	var args []any
+	if filter.ProjectKey != "" {
+		conds = append(conds, "project_key = ?")
+		args = append(args, filter.ProjectKey)
+	}
+	if filter.RepoSlug != "" {
+		conds = append(conds, "repo_slug = ?")
+		args = append(args, filter.RepoSlug)
+	}
+	if filter.Author != "" {
+		conds = append(conds, "json_extract(pr_data, '$.Author') = ?")
+		args = append(args, filter.Author)
+	}
+
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	res, err := tx.ExecContext(ctx, "DELETE FROM reviews WHERE "+strings.Join(conds, " AND "), args...)
+	if err != nil {
+		return 0, fmt.Errorf("delete reviews: %w", err)
+	}
+	n, _ := res.RowsAffected()
+
+	// The skip ledger stores the author in its own column
+	skipConds := strings.ReplaceAll(strings.Join(conds, " AND "), "json_extract(pr_data, '$.Author')", "author")
+	res, err = tx.ExecContext(ctx, "DELETE FROM review_skips WHERE "+skipConds, args...)
+	if err != nil {
+		return 0, fmt.Errorf("delete skips: %w", err)
+	}
+	skipped, _ := res.RowsAffected()
+	n += skipped
+
+	// Fingerprints carry no author; they go with their project or repository
+	if filter.Author == "" {
+		res, err = tx.ExecContext(ctx, "DELETE FROM diff_fingerprints WHERE "+strings.Join(conds, " AND "), args...)
+		if err != nil {
+			return 0, fmt.Errorf("delete fingerprints: %w", err)
+		}
+		fingerprints, _ := res.RowsAffected()
+		n += fingerprints
+	}
+
+	res, err = tx.ExecContext(ctx, "DELETE FROM queued_reviews WHERE "+strings.Join(conds, " AND "), args...)
+	if err != nil {
+		return 0, fmt.Errorf("delete queued reviews: %w", err)
+	}
+	queued, _ := res.RowsAffected()
+	n += queued
+
+	res, err = tx.ExecContext(ctx, "DELETE FROM queued_jobs WHERE "+strings.Join(conds, " AND "), args...)
+	if err != nil {
+		return 0, fmt.Errorf("delete queued jobs: %w", err)
+	}
+	jobs, _ := res.RowsAffected()
+	n += jobs
+
+	filterJSON, _ := json.Marshal(filter)
+	if _, err := tx.ExecContext(ctx, `
+        INSERT INTO purge_audit (scope, filter, rows, requested_by, reason)
+        VALUES (?, ?, ?, ?, ?)
+    `, "subject", string(filterJSON), n, requestedBy, reason); err != nil {
+		return 0, fmt.Errorf("write purge audit: %w", err)
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	slog.Info("data purged", "filter", string(filterJSON), "rows", n, "requested_by", requestedBy, "reason", reason)
+	return n, nil
+}
+
+func (r *SQLiteRepository) writeAudit(ctx context.Context, a PurgeAudit) error {
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO purge_audit (scope, filter, rows, requested_by, reason)
+        VALUES (?, ?, ?, ?, ?)
+    `, a.Scope, a.Filter, a.Rows, a.RequestedBy, a.Reason)
+	return err
+}
+
+func (r *SQLiteRepository) GetTuning(ctx context.Context, projectKey, repoSlug string) (*ChunkTuning, error) {
+	t := &ChunkTuning{ProjectKey: projectKey, RepoSlug: repoSlug}
+	err := r.db.QueryRowContext(ctx, `
+        SELECT max_tokens, context_lines, chunks, overflows, empties, updated_at
+        FROM chunk_tuning WHERE project_key = ? AND repo_slug = ?
+    `, projectKey, repoSlug).Scan(&t.MaxTokens, &t.ContextLines, &t.Chunks, &t.Overflows, &t.Empties, &t.UpdatedAt)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+func (r *SQLiteRepository) SaveTuning(ctx context.Context, t *ChunkTuning) error {
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO chunk_tuning (project_key, repo_slug, max_tokens, context_lines, chunks, overflows, empties, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT(project_key, repo_slug) DO UPDATE SET
+            max_tokens = excluded.max_tokens,
+            context_lines = excluded.context_lines,
+            chunks = excluded.chunks,
+            overflows = excluded.overflows,
+            empties = excluded.empties,
+            updated_at = excluded.updated_at
+    `, t.ProjectKey, t.RepoSlug, t.MaxTokens, t.ContextLines, t.Chunks, t.Overflows, t.Empties, t.UpdatedAt.UTC())
+	return err
+}
+
+func (r *SQLiteRepository) SaveSkip(ctx context.Context, sk *SkipRecord) error {
+	if sk.CreatedAt.IsZero() {
+		sk.CreatedAt = time.Now()
+	}
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO review_skips (project_key, repo_slug, pr_id, commit_id, author, reason, detail, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+    `, sk.ProjectKey, sk.RepoSlug, sk.PRID, sk.Commit, sk.Author, sk.Reason, sk.Detail, sk.CreatedAt.UTC())
+	return err
+}
+
+func (r *SQLiteRepository) ListSkips(ctx context.Context, limit int) ([]*SkipRecord, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT project_key, repo_slug, pr_id, commit_id, author, reason, detail, created_at
+        FROM review_skips
+        ORDER BY created_at DESC, id DESC
+        LIMIT ?
+    `, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var skips []*SkipRecord
+	for rows.Next() {
+		var sk SkipRecord
+		var commit, author, detail sql.NullString
+		if err := rows.Scan(&sk.ProjectKey, &sk.RepoSlug, &sk.PRID, &commit, &author, &sk.Reason, &detail, &sk.CreatedAt); err != nil {
+			return nil, err
+		}
+		sk.Commit, sk.Author, sk.Detail = commit.String, author.String, detail.String
+		skips = append(skips, &sk)
+	}
+	return skips, rows.Err()
+}
+
+func (r *SQLiteRepository) SaveFingerprint(ctx context.Context, f *FingerprintRecord) error {
+	if f.UpdatedAt.IsZero() {
+		f.UpdatedAt = time.Now()
+	}
+	lines, err := json.Marshal(f.Lines)
+	if err != nil {
+		return fmt.Errorf("marshal fingerprint: %w", err)
+	}
+	title, err := r.seal(f.Title, fingerprintID(f.ProjectKey, f.RepoSlug, f.PRID))
+	if err != nil {
+		return fmt.Errorf("encrypt title: %w", err)
+	}
+	_, err = r.db.ExecContext(ctx, `
+        INSERT INTO diff_fingerprints (project_key, repo_slug, pr_id, commit_id, title, web_url, lines, updated_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT(project_key, repo_slug, pr_id) DO UPDATE SET
+            commit_id = excluded.commit_id,
+            title = excluded.title,
+            web_url = excluded.web_url,
+            lines = excluded.lines,
+            updated_at = excluded.updated_at
+    `, f.ProjectKey, f.RepoSlug, f.PRID, f.Commit, title, f.WebURL, string(lines), f.UpdatedAt.UTC())
+	return err
+}
+
+func (r *SQLiteRepository) ListFingerprints(ctx context.Context, projectKey, repoSlug string, since time.Time) ([]*FingerprintRecord, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT pr_id, commit_id, title, web_url, lines, updated_at
+        FROM diff_fingerprints
+        WHERE project_key = ? AND repo_slug = ? AND updated_at >= ?
+        ORDER BY updated_at DESC
+    `, projectKey, repoSlug, since.UTC())
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*FingerprintRecord
+	for rows.Next() {
+		f := FingerprintRecord{ProjectKey: projectKey, RepoSlug: repoSlug}
+		var commit, title, webURL sql.NullString
+		var lines string
+		if err := rows.Scan(&f.PRID, &commit, &title, &webURL, &lines, &f.UpdatedAt); err != nil {
+			return nil, err
+		}
+		if err := json.Unmarshal([]byte(lines), &f.Lines); err != nil {
+			return nil, fmt.Errorf("unmarshal fingerprint: %w", err)
+		}
+		if f.Title, err = r.cipher.Decrypt(title.String, fingerprintID(projectKey, repoSlug, f.PRID)); err != nil {
+			return nil, fmt.Errorf("decrypt title: %w", err)
+		}
+		f.Commit, f.WebURL = commit.String, webURL.String
+		out = append(out, &f)
+	}
+	return out, rows.Err()
+}
+
+func (r *SQLiteRepository) SaveQueuedReviews(ctx context.Context, reviews []*QueuedReview) error {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	for _, q := range reviews {
+		if q.CreatedAt.IsZero() {
+			q.CreatedAt = time.Now()
+		}
+		data, err := json.Marshal(q.PullRequest)
+		if err != nil {
+			return fmt.Errorf("marshal pr: %w", err)
+		}
+		prData, err := r.seal(string(data), q.Key)
+		if err != nil {
+			return fmt.Errorf("encrypt pr: %w", err)
+		}
+		if _, err := tx.ExecContext(ctx, `
+            INSERT INTO queued_reviews (queue_key, project_key, repo_slug, pr_id, pr_data, created_at)
+            VALUES (?, ?, ?, ?, ?, ?)
+            ON CONFLICT(queue_key) DO UPDATE SET
+                pr_data = excluded.pr_data,
+                created_at = excluded.created_at
+        `, q.Key, q.PullRequest.ProjectKey, q.PullRequest.RepoSlug, q.PullRequest.ID, prData, q.CreatedAt.UTC()); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+func (r *SQLiteRepository) TakeQueuedReviews(ctx context.Context) ([]*QueuedReview, error) {
+	tx, err := r.db.BeginTx(ctx, nil)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback()
+
+	rows, err := tx.QueryContext(ctx, `
+        SELECT queue_key, pr_data, created_at FROM queued_reviews ORDER BY created_at, queue_key
+    `)
+	if err != nil {
+		return nil, err
+	}
+	var out []*QueuedReview
+	for rows.Next() {
+		var q QueuedReview
+		var prData string
+		if err := rows.Scan(&q.Key, &prData, &q.CreatedAt); err != nil {
+			rows.Close()
+			return nil, err
+		}
+		if prData, err = r.cipher.Decrypt(prData, q.Key); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("decrypt pr: %w", err)
+		}
+		if err := json.Unmarshal([]byte(prData), &q.PullRequest); err != nil {
+			rows.Close()
+			return nil, fmt.Errorf("unmarshal pr: %w", err)
+		}
+		out = append(out, &q)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if _, err := tx.ExecContext(ctx, "DELETE FROM queued_reviews"); err != nil {
+		return nil, err
+	}
+	return out, tx.Commit()
+}
+
+func (r *SQLiteRepository) SaveJob(ctx context.Context, job *QueuedJob) error {
+	if job.CreatedAt.IsZero() {
+		job.CreatedAt = time.Now()
+	}
+	var prData sql.NullString
+	if job.PullRequest != nil {
+		data, err := json.Marshal(job.PullRequest)
+		if err != nil {
+			return fmt.Errorf("marshal pr: %w", err)
+		}
+		sealed, err := r.seal(string(data), job.Key)
+		if err != nil {
+			return fmt.Errorf("encrypt pr: %w", err)
+		}
+		prData = sql.NullString{String: sealed, Valid: true}
+	}
+	payload := job.Payload
+	if len(payload) > 0 && r.cipher != nil {
+		sealed, err := r.seal(string(payload), job.Key)
+		if err != nil {
+			return fmt.Errorf("encrypt payload: %w", err)
+		}
+		payload = []byte(sealed)
+	}
+	_, err := r.db.ExecContext(ctx, `
+        INSERT INTO queued_jobs (queue_key, seq, project_key, repo_slug, pr_id, payload, pr_data, created_at)
+        VALUES (?, ?, ?, ?, ?, ?, ?, ?)
+        ON CONFLICT(queue_key) DO UPDATE SET
+            seq = excluded.seq,
+            payload = excluded.payload,
+            pr_data = excluded.pr_data,
+            created_at = excluded.created_at
+    `, job.Key, job.Seq, job.ProjectKey, job.RepoSlug, job.PRID, payload, prData, job.CreatedAt.UTC())
+	return err
+}
+
+func (r *SQLiteRepository) DeleteJob(ctx context.Context, key string, seq int64) error {
+	_, err := r.db.ExecContext(ctx, "DELETE FROM queued_jobs WHERE queue_key = ? AND seq = ?", key, seq)
+	return err
+}
+
+func (r *SQLiteRepository) ListJobs(ctx context.Context) ([]*QueuedJob, error) {
+	rows, err := r.db.QueryContext(ctx, `
+        SELECT queue_key, seq, project_key, repo_slug, pr_id, payload, pr_data, created_at
+        FROM queued_jobs ORDER BY created_at, queue_key
+    `)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var out []*QueuedJob
+	for rows.Next() {
+		var j QueuedJob
+		var prData sql.NullString
+		if err := rows.Scan(&j.Key, &j.Seq, &j.ProjectKey, &j.RepoSlug, &j.PRID, &j.Payload, &prData, &j.CreatedAt); err != nil {
+			return nil, err
+		}
+		if isEncrypted(string(j.Payload)) {
+			payload, err := r.cipher.Decrypt(string(j.Payload), j.Key)
+			if err != nil {
+				return nil, fmt.Errorf("decrypt payload: %w", err)
+			}
+			j.Payload = []byte(payload)
+		}
+		if prData.Valid {
+			data, err := r.cipher.Decrypt(prData.String, j.Key)
+			if err != nil {
+				return nil, fmt.Errorf("decrypt pr: %w", err)
+			}
+			if err := json.Unmarshal([]byte(data), &j.PullRequest); err != nil {
+				return nil, fmt.Errorf("unmarshal pr: %w", err)
+			}
+		}
+		out = append(out, &j)
+	}
+	return out, rows.Err()
+}
+
 func (r *SQLiteRepository) Close() error {
 	return r.db.Close()
 }
@@ -145,12 +699,13 @@ type Scanner interface {
 	Scan(dest ...any) error
 }
 
-func scanReview(s Scanner) (*ReviewRecord, error) {
+func (r *SQLiteRepository) scanReview(s Scanner) (*ReviewRecord, error) {
 	var id, prData, resultData, status string
 	var createdAt time.Time
 	var durationMs int64
+	var cost float64
 
-	if err := s.Scan(&id, &prData, &resultData, &createdAt, &durationMs, &status); err != nil {
+	if err := s.Scan(&id, &prData, &resultData, &createdAt, &durationMs, &status, &cost); err != nil {
 		return nil, err
 	}
 
@@ -159,6 +714,11 @@ func scanReview(s Scanner) (*ReviewRecord, error) {
 		return nil, fmt.Errorf("unmarshal pr: %w", err)
 	}
 
+	resultData, err := r.cipher.Decrypt(resultData, id)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt result: %w", err)
+	}
+
 	var result domain.ReviewResult
 	if err := json.Unmarshal([]byte(resultData), &result); err != nil {
 		return nil, fmt.Errorf("unmarshal result: %w", err)
@@ -171,5 +731,6 @@ func scanReview(s Scanner) (*ReviewRecord, error) {
 		CreatedAt:   createdAt,
 		DurationMs:  durationMs,
 		Status:      status,
+		Cost:        cost,
 	}, nil
 }
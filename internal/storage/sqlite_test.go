@@ -2,8 +2,10 @@ package storage
 
 import (
 	"context"
+	"database/sql"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -51,6 +53,7 @@ func TestSQLiteRepository(t *testing.T) {
 		CreatedAt:   time.Now().UTC(),
 		DurationMs:  1500,
 		Status:      "success",
+		Cost:        0.042,
 	}
 
 	// Test Save
@@ -76,4 +79,75 @@ func TestSQLiteRepository(t *testing.T) {
 	if saved.Result.Summary != result.Summary {
 		t.Errorf("expected summary %s, got %s", result.Summary, saved.Result.Summary)
 	}
+	if saved.Cost != record.Cost {
+		t.Errorf("expected cost %v, got %v", record.Cost, saved.Cost)
+	}
+}
+
+func TestSQLiteRepository_MigratesCost(t *testing.T) {
+	dbPath := filepath.Join(t.TempDir(), "old.db")
+	db, err := sql.Open("sqlite", dbPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = db.Exec(`
+        CREATE TABLE reviews (id TEXT PRIMARY KEY, project_key TEXT NOT NULL, repo_slug TEXT NOT NULL, pr_id TEXT NOT NULL,
+            pr_data TEXT NOT NULL, result_data TEXT NOT NULL, created_at DATETIME DEFAULT CURRENT_TIMESTAMP, duration_ms INTEGER, status TEXT NOT NULL);
+        INSERT INTO reviews (id, project_key, repo_slug, pr_id, pr_data, result_data, duration_ms, status)
+            VALUES ('old', 'PROJ', 'repo', '1', '{}', '{}', 10, 'success');`)
+	db.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	repo, err := NewSQLiteRepository(dbPath)
+	if err != nil {
+		t.Fatalf("open an older database: %v", err)
+	}
+	defer repo.Close()
+	record, err := repo.GetReview(context.Background(), "old")
+	if err != nil || record.Cost != 0 {
+		t.Fatalf("GetReview = %+v, %v", record, err)
+	}
+}
+
+func TestSQLiteRepository_ListReviews(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+	base := time.Now().UTC()
+	for i, pr := range []domain.PullRequest{
+		{ID: "1", ProjectKey: "PAY", RepoSlug: "api"},
+		{ID: "2", ProjectKey: "PAY", RepoSlug: "api"},
+		{ID: "3", ProjectKey: "PAY", RepoSlug: "web"},
+		{ID: "4", ProjectKey: "OPS", RepoSlug: "api"},
+	} {
+		record := &ReviewRecord{ID: "r" + pr.ID, PullRequest: &pr, Result: &domain.ReviewResult{}, CreatedAt: base.Add(time.Duration(i) * time.Second), Status: "success"}
+		if err := repo.SaveReview(ctx, record); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	tests := []struct {
+		filter ReviewFilter
+		limit  int
+		want   []string
+	}{
+		{filter: ReviewFilter{ProjectKey: "PAY"}, limit: 10, want: []string{"r3", "r2", "r1"}},
+		{filter: ReviewFilter{ProjectKey: "PAY", RepoSlug: "api"}, limit: 10, want: []string{"r2", "r1"}},
+		{filter: ReviewFilter{ProjectKey: "PAY"}, limit: 1, want: []string{"r3"}},
+		{filter: ReviewFilter{ProjectKey: "NONE"}, limit: 10},
+	}
+	for _, tt := range tests {
+		records, err := repo.ListReviews(ctx, tt.filter, tt.limit)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var got []string
+		for _, r := range records {
+			got = append(got, r.ID)
+		}
+		if strings.Join(got, ",") != strings.Join(tt.want, ",") {
+			t.Errorf("ListReviews(%+v, %d) = %v, want %v", tt.filter, tt.limit, got, tt.want)
+		}
+	}
 }
@@ -2,10 +2,14 @@ package storage
 
 import (
 	"context"
+	"errors"
 	"pr-review-automation/internal/domain"
 	"time"
 )
 
+// ErrNotFound is returned when a requested record does not exist
+var ErrNotFound = errors.New("record not found")
+
 // ReviewRecord Review persistence record
 type ReviewRecord struct {
 	ID          string               `json:"id"`
@@ -13,7 +17,21 @@ type ReviewRecord struct {
 	Result      *domain.ReviewResult `json:"result"`
 	CreatedAt   time.Time            `json:"created_at"`
 	DurationMs  int64                `json:"duration_ms"`
-	Status      string               `json:"status"` // success, error
+	Status      string               `json:"status"`         // success, error, timeout
+	Cost        float64              `json:"cost,omitempty"` // LLM cost in USD, 0 for models without llm.pricing
+}
+
+// ReviewStatusRepository changes the outcome of a stored review
+type ReviewStatusRepository interface {
+	// SetReviewStatus sets the status and duration of the review with the ID; it does nothing
+	// when no review has it
+	SetReviewStatus(ctx context.Context, id, status string, durationMs int64) error
+}
+
+// ReviewFilter selects the reviews of a project, or of one repository when RepoSlug is set
+type ReviewFilter struct {
+	ProjectKey string
+	RepoSlug   string
 }
 
 // Repository Storage interface
@@ -22,5 +40,11 @@ type Repository interface {
 	GetReview(ctx context.Context, id string) (*ReviewRecord, error)
 	ListReviewsByPR(ctx context.Context, projectKey, repoSlug, prID string) ([]*ReviewRecord, error)
 	ListRecentReviews(ctx context.Context, limit int) ([]*ReviewRecord, error)
+	// ListReviews lists the most recent reviews matching the filter
+	ListReviews(ctx context.Context, filter ReviewFilter, limit int) ([]*ReviewRecord, error)
+	// PurgeOlderThan deletes records of a data class created before cutoff
+	PurgeOlderThan(ctx context.Context, dataClass string, cutoff time.Time) (int64, error)
+	// Purge deletes all stored data matching the filter and records an audit entry
+	Purge(ctx context.Context, filter PurgeFilter, requestedBy, reason string) (int64, error)
 	Close() error
 }
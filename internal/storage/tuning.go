@@ -0,0 +1,26 @@
+package storage
+
+import (
+	"context"
+	"time"
+)
+
+// ChunkTuning holds the adaptive review chunking parameters of one repository
+// and the observations collected since the last adjustment
+type ChunkTuning struct {
+	ProjectKey   string    `json:"projectKey"`
+	RepoSlug     string    `json:"repoSlug"`
+	MaxTokens    int       `json:"maxTokens"`    // Token budget per review call
+	ContextLines int       `json:"contextLines"` // L1 context lines kept around changes
+	Chunks       int       `json:"chunks"`       // Chunks observed in the current window
+	Overflows    int       `json:"overflows"`    // Chunks rejected by the LLM for exceeding its context
+	Empties      int       `json:"empties"`      // Chunks reviewed without findings
+	UpdatedAt    time.Time `json:"updatedAt"`
+}
+
+// TuningRepository persists adaptive tuning state per repository
+type TuningRepository interface {
+	// GetTuning returns ErrNotFound when the repository has no tuning yet
+	GetTuning(ctx context.Context, projectKey, repoSlug string) (*ChunkTuning, error)
+	SaveTuning(ctx context.Context, t *ChunkTuning) error
+}
@@ -0,0 +1,34 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestSQLiteRepository_Tuning(t *testing.T) {
+	repo := newTestRepo(t)
+	ctx := context.Background()
+
+	if _, err := repo.GetTuning(ctx, "PROJ", "repo"); !errors.Is(err, ErrNotFound) {
+		t.Fatalf("expected ErrNotFound, got %v", err)
+	}
+
+	tuning := &ChunkTuning{ProjectKey: "PROJ", RepoSlug: "repo", MaxTokens: 1000, ContextLines: 50, Chunks: 3, UpdatedAt: time.Now()}
+	if err := repo.SaveTuning(ctx, tuning); err != nil {
+		t.Fatalf("save: %v", err)
+	}
+	tuning.MaxTokens = 900
+	if err := repo.SaveTuning(ctx, tuning); err != nil {
+		t.Fatalf("update: %v", err)
+	}
+
+	got, err := repo.GetTuning(ctx, "PROJ", "repo")
+	if err != nil {
+		t.Fatalf("get: %v", err)
+	}
+	if got.MaxTokens != 900 || got.ContextLines != 50 || got.Chunks != 3 {
+		t.Errorf("unexpected tuning: %+v", got)
+	}
+}
@@ -0,0 +1,100 @@
+package tokenizer
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"math"
+	"os"
+	"regexp"
+	"strconv"
+)
+
+// pretokenize splits text into the pieces BPE merges within, approximating the cl100k and
+// o200k patterns without their lookaheads, which RE2 does not support
+var pretokenize = regexp.MustCompile(`(?i:'s|'t|'re|'ve|'m|'ll|'d)|[^\r\n\p{L}\p{N}]?\p{L}+|\p{N}{1,3}| ?[^\s\p{L}\p{N}]+[\r\n]*|\s*[\r\n]+|\s+`)
+
+// maxPieceBytes bounds the pieces merged by rank, which takes quadratic time. Longer pieces,
+// such as a minified line or a row of "=", are estimated by Heuristic instead.
+const maxPieceBytes = 1000
+
+// BPE counts tokens with a byte-pair encoding vocabulary in tiktoken format, as used by
+// OpenAI models (cl100k_base, o200k_base) and Llama 3
+type BPE struct {
+	ranks map[string]int
+}
+
+// LoadBPE reads a tiktoken rank file: one base64-encoded token and its rank per line
+func LoadBPE(file string) (*BPE, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	ranks := make(map[string]int)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for n := 1; scanner.Scan(); n++ {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+		token, rank, ok := bytes.Cut(line, []byte(" "))
+		if !ok {
+			return nil, fmt.Errorf("%s:%d: expected token and rank", file, n)
+		}
+		decoded, err := base64.StdEncoding.DecodeString(string(token))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, n, err)
+		}
+		r, err := strconv.Atoi(string(rank))
+		if err != nil {
+			return nil, fmt.Errorf("%s:%d: %w", file, n, err)
+		}
+		ranks[string(decoded)] = r
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if len(ranks) == 0 {
+		return nil, fmt.Errorf("%s: no tokens", file)
+	}
+	return &BPE{ranks: ranks}, nil
+}
+
+// Count implements Tokenizer
+func (b *BPE) Count(text string) int {
+	count := 0
+	for _, piece := range pretokenize.FindAllString(text, -1) {
+		count += b.countPiece(piece)
+	}
+	return count
+}
+
+// countPiece merges the bytes of a piece by rank, lowest first, and returns the number of
+// tokens left. Bytes missing from the vocabulary count as one token each.
+func (b *BPE) countPiece(piece string) int {
+	if _, ok := b.ranks[piece]; ok {
+		return 1
+	}
+	if len(piece) > maxPieceBytes {
+		return max(Heuristic{}.Count(piece), 1)
+	}
+	// bounds[i] is the start of the i-th part; the last entry is len(piece)
+	bounds := make([]int, len(piece)+1)
+	for i := range bounds {
+		bounds[i] = i
+	}
+	for len(bounds) > 2 {
+		best, at := math.MaxInt, -1
+		for i := 0; i+2 < len(bounds); i++ {
+			if rank, ok := b.ranks[piece[bounds[i]:bounds[i+2]]]; ok && rank < best {
+				best, at = rank, i
+			}
+		}
+		if at < 0 {
+			break
+		}
+		bounds = append(bounds[:at+1], bounds[at+2:]...)
+	}
+	return len(bounds) - 1
+}
@@ -0,0 +1,90 @@
+// Package tokenizer counts the tokens of prompt text for chunk sizing and degradation.
+// Models with a configured BPE vocabulary (llm.tokenizers) are counted exactly; others use a
+// script-aware estimate, so CJK-heavy diffs are not undercounted.
+package tokenizer
+
+import (
+	"path"
+	"sync"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Tokenizer counts the tokens a model reads for a text
+type Tokenizer interface {
+	Count(text string) int
+}
+
+// Heuristic estimates tokens from the scripts of a text: about 3.5 ASCII characters per token,
+// one token per CJK character and two characters per token for other scripts
+type Heuristic struct{}
+
+// Count implements Tokenizer
+func (Heuristic) Count(text string) int {
+	var ascii, cjk, other int
+	for _, r := range text {
+		switch {
+		case r < utf8.RuneSelf:
+			ascii++
+		case unicode.In(r, unicode.Han, unicode.Hiragana, unicode.Katakana, unicode.Hangul):
+			cjk++
+		default:
+			other++
+		}
+	}
+	return int(float64(ascii)/3.5) + cjk + other/2
+}
+
+// rule assigns a tokenizer to the models matching one of its globs
+type rule struct {
+	models    []string
+	tokenizer Tokenizer
+}
+
+var (
+	mu       sync.RWMutex
+	rules    []rule
+	fallback Tokenizer = Heuristic{}
+)
+
+// Register counts the tokens of the models matching one of the globs (e.g. "gpt-4o*") with t.
+// Rules are tried in the order they were registered.
+func Register(models []string, t Tokenizer) {
+	mu.Lock()
+	defer mu.Unlock()
+	rules = append(rules, rule{models: models, tokenizer: t})
+}
+
+// For returns the tokenizer of model: the first registered rule matching it, or the
+// Heuristic estimate
+func For(model string) Tokenizer {
+	mu.RLock()
+	defer mu.RUnlock()
+	for _, r := range rules {
+		for _, glob := range r.models {
+			if ok, _ := path.Match(glob, model); ok {
+				return r.tokenizer
+			}
+		}
+	}
+	return Heuristic{}
+}
+
+// SetDefault sets the tokenizer used by Count, normally that of llm.model
+func SetDefault(t Tokenizer) {
+	mu.Lock()
+	defer mu.Unlock()
+	fallback = t
+}
+
+// Default returns the tokenizer used by Count
+func Default() Tokenizer {
+	mu.RLock()
+	defer mu.RUnlock()
+	return fallback
+}
+
+// Count counts the tokens of text with the default tokenizer
+func Count(text string) int {
+	return Default().Count(text)
+}
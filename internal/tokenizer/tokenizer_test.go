@@ -0,0 +1,98 @@
+package tokenizer
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestHeuristic(t *testing.T) {
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"", 0},
+		{"return nil, err", 4},  // 15 ASCII characters
+		{"// 检查用户权限", 6},        // 3 ASCII characters and 6 CJK characters
+		{"// проверка прав", 7}, // 4 ASCII characters and 12 Cyrillic letters
+	}
+	for _, tt := range tests {
+		if got := (Heuristic{}).Count(tt.text); got != tt.want {
+			t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+}
+
+// writeRanks writes a tiktoken rank file with the given tokens, ranked in order
+func writeRanks(t *testing.T, tokens ...string) string {
+	var sb strings.Builder
+	for rank, token := range tokens {
+		fmt.Fprintf(&sb, "%s %d\n", base64.StdEncoding.EncodeToString([]byte(token)), rank)
+	}
+	file := filepath.Join(t.TempDir(), "test.tiktoken")
+	if err := os.WriteFile(file, []byte(sb.String()), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return file
+}
+
+func TestBPE(t *testing.T) {
+	bpe, err := LoadBPE(writeRanks(t, "a", "b", "c", " ", "ab", "abc", " abc"))
+	if err != nil {
+		t.Fatalf("LoadBPE: %v", err)
+	}
+	tests := []struct {
+		text string
+		want int
+	}{
+		{"abc", 1},     // Whole piece in the vocabulary
+		{"abcab", 2},   // abc + ab
+		{"abc abc", 2}, // abc + " abc"
+		{"cab", 2},     // c + ab
+		{"xyz", 3},     // Unknown bytes count one each
+	}
+	for _, tt := range tests {
+		if got := bpe.Count(tt.text); got != tt.want {
+			t.Errorf("Count(%q) = %d, want %d", tt.text, got, tt.want)
+		}
+	}
+
+	if _, err := LoadBPE(writeRanks(t)); err == nil {
+		t.Error("LoadBPE accepted an empty file")
+	}
+}
+
+func TestBPE_LongPiece(t *testing.T) {
+	bpe, err := LoadBPE(writeRanks(t, "=", "==", "===="))
+	if err != nil {
+		t.Fatalf("LoadBPE: %v", err)
+	}
+	// One piece of 100k "=" would take minutes to merge; it is estimated instead
+	text := strings.Repeat("=", 100000)
+	if got, want := bpe.Count(text), (Heuristic{}).Count(text); got != want {
+		t.Errorf("Count(100k =) = %d, want %d", got, want)
+	}
+	// Pieces up to the bound are still merged
+	if got := bpe.Count(strings.Repeat("=", maxPieceBytes)); got != maxPieceBytes/4 {
+		t.Errorf("Count(%d =) = %d, want %d", maxPieceBytes, got, maxPieceBytes/4)
+	}
+}
+
+func TestFor(t *testing.T) {
+	defer func() { rules = nil }()
+	bpe, err := LoadBPE(writeRanks(t, "a"))
+	if err != nil {
+		t.Fatalf("LoadBPE: %v", err)
+	}
+	Register([]string{"gpt-4o*"}, bpe)
+
+	if got := For("gpt-4o-mini"); got != bpe {
+		t.Errorf("For(gpt-4o-mini) = %T, want the registered BPE", got)
+	}
+	if _, ok := For("qwen2.5-coder").(Heuristic); !ok {
+		t.Error("unmatched model does not use the heuristic")
+	}
+}
@@ -0,0 +1,35 @@
+// Package version reports the version of the running build.
+package version
+
+import "runtime/debug"
+
+// Version is set at build time with -ldflags "-X pr-review-automation/internal/version.Version=v1.2.3"
+var Version = "dev"
+
+// String returns Version, followed by the VCS revision for development builds
+func String() string {
+	if Version != "dev" {
+		return Version
+	}
+	info, ok := debug.ReadBuildInfo()
+	if !ok {
+		return Version
+	}
+	var revision, modified string
+	for _, s := range info.Settings {
+		switch s.Key {
+		case "vcs.revision":
+			revision = s.Value
+		case "vcs.modified":
+			modified = s.Value
+		}
+	}
+	if revision == "" {
+		return Version
+	}
+	v := Version + "+" + revision[:min(7, len(revision))]
+	if modified == "true" {
+		v += "-dirty"
+	}
+	return v
+}
@@ -5,6 +5,7 @@ import (
 	"crypto/hmac"
 	"crypto/sha256"
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"io"
 	"log/slog"
@@ -16,8 +17,13 @@ import (
 	"unicode/utf8"
 
 	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/fault"
 	"pr-review-automation/internal/metrics"
 	"pr-review-automation/internal/processor"
+	"pr-review-automation/internal/progress"
+	"pr-review-automation/internal/scope"
+	"pr-review-automation/internal/storage"
 	internal_sync "pr-review-automation/internal/sync" // Custom sync package
 
 	"github.com/tidwall/gjson"
@@ -31,7 +37,23 @@ type BitbucketWebhookHandler struct {
 	workerPool     *WorkerPool
 	debouncer      *internal_sync.Debouncer
 	keyLock        *internal_sync.KeyLock
-	latestPayloads sync.Map // Map[string][]byte: PR-ID -> Latest Payload
+	latestPayloads sync.Map                 // Map[string]parseFunc: PR key -> parser of the latest payload
+	running        sync.Map                 // Map[string]*runningReview: PR key -> review in progress
+	mergeHandler   processor.MergeHandler   // Optional: follow-up actions on pr:merged
+	commands       processor.CommandHandler // Optional: slash commands in pr:comment:added
+	conversations  processor.ReplyHandler   // Optional: answers to replies in AI comment threads
+	gate           *scope.Gate              // Optional: repository allow/deny lists
+	queueStore     storage.QueueRepository  // Optional: pending queue snapshots during maintenance
+	jobStore       storage.JobRepository    // Optional: journal of accepted reviews
+	jobSeqs        sync.Map                 // Map[string]int64: PR key -> journal version of the latest payload
+	faults         *fault.Injector          // Set when fault_injection is enabled
+	reviewQueue    ReviewQueue              // Optional: external queue of reviews (queue.driver)
+	progress       *progress.Broker         // Optional: live progress of running reviews
+	limiter        *rateLimiter             // Optional: per-project and per-repository review rates
+	priorities     sync.Map                 // Map[string]Priority: PR key -> priority of the latest payload
+	providers      ProviderResolver         // Optional: LLM provider of each review, for server.review_timeout
+	consumer       consumer
+	intake         intake
 }
 
 // NewBitbucketWebhookHandler creates a new webhook handler
@@ -47,6 +69,8 @@ func NewBitbucketWebhookHandler(cfg *config.Config, prProcessor processor.Proces
 	}
 
 	wp := NewWorkerPool(workerCount, queueSize)
+	wp.SetAutoscale(cfg.Server.Autoscale)
+	wp.SetPriorities(cfg.Server.Priorities)
 	wp.Start()
 
 	// Initialize Debouncer
@@ -64,11 +88,30 @@ func NewBitbucketWebhookHandler(cfg *config.Config, prProcessor processor.Proces
 		workerPool:  wp,
 		debouncer:   debouncer,
 		keyLock:     keyLock,
+		faults:      fault.New(cfg.FaultInjection),
+		limiter:     newRateLimiter(cfg.Server.RateLimit),
 	}
 }
 
+// SetGate restricts reviews to the repositories allowed by gate
+func (h *BitbucketWebhookHandler) SetGate(gate *scope.Gate) {
+	h.gate = gate
+}
+
+// SetMergeHandler enables processing of pr:merged events
+func (h *BitbucketWebhookHandler) SetMergeHandler(mh processor.MergeHandler) {
+	h.mergeHandler = mh
+}
+
+// SetProgress publishes the progress of the reviews this handler runs to broker
+func (h *BitbucketWebhookHandler) SetProgress(broker *progress.Broker) {
+	h.progress = broker
+}
+
 // WaitForCompletion blocks until all background PR processing tasks complete
 func (h *BitbucketWebhookHandler) WaitForCompletion() {
+	h.limiter.stop()
+	h.stopConsuming()
 	h.workerPool.Stop()
 }
 
@@ -81,6 +124,9 @@ func (h *BitbucketWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Reque
 		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
 		return
 	}
+	if h.rejectPaused(w) {
+		return
+	}
 
 	// 1. Security: Limit request body size
 	r.Body = http.MaxBytesReader(w, r.Body, h.config.Server.MaxBodySize)
@@ -123,8 +169,24 @@ func (h *BitbucketWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Reque
 	// 3. Extract PR ID for Debouncing/Queueing
 	// We do a quick parse or GJSON lookup to get the ID/EventKey without full parsing
 	eventKey := gjson.GetBytes(body, "eventKey").String()
+	if eventKey == "pr:merged" && h.mergeHandler != nil {
+		if err := h.submitMerged(body); err != nil {
+			h.tooBusy(w)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "Merge event queued")
+		return
+	}
+
+	if eventKey == "pr:comment:added" && (h.commands != nil || h.conversations != nil) {
+		h.handleComment(w, body)
+		return
+	}
+
 	// Only process specific events
-	if eventKey != "pr:opened" && eventKey != "pr:from_ref_updated" {
+	reviewable := eventKey == "pr:opened" || eventKey == "pr:from_ref_updated" || (eventKey == "pr:modified" && h.leftDraft(body))
+	if !reviewable {
 		slog.Debug("ignoring event type for processing", "event_key", eventKey)
 		// We still return 200 as we accepted the hook
 		w.WriteHeader(http.StatusOK)
@@ -141,6 +203,19 @@ func (h *BitbucketWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Reque
 	projectKey := gjson.GetBytes(body, "pullRequest.fromRef.repository.project.key").String()
 	repoSlug := gjson.GetBytes(body, "pullRequest.fromRef.repository.slug").String()
 
+	if h.ignoreBot(w, append(bitbucketActors(body), bitbucketAuthors(body)...)...) {
+		return
+	}
+	if !h.allowed(body) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "Repository not enabled for review")
+		return
+	}
+
+	if h.rejectFull(w) {
+		return
+	}
+
 	var uniqueKey string
 	if prID != "" && projectKey != "" && repoSlug != "" {
 		uniqueKey = fmt.Sprintf("%s/%s/%s", projectKey, repoSlug, prID)
@@ -151,22 +226,75 @@ func (h *BitbucketWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Reque
 		uniqueKey = fmt.Sprintf("unknown-%d", time.Now().UnixNano())
 	}
 
-	// 4. Update the latest payload for this PR
-	h.latestPayloads.Store(uniqueKey, body)
-
-	// 5. Schedule via Debouncer
-	h.debouncer.Add(uniqueKey, func() {
-		h.submitJob(uniqueKey)
-	})
+	// 4. Queue the latest payload for this PR
+	h.enqueue(uniqueKey, h.withFaults(r, func(ctx context.Context) (*domain.PullRequest, error) {
+		return h.parser.Parse(ctx, body)
+	}), payloadJob(body, projectKey, repoSlug, prID), h.reviewPriority(eventKey != "pr:from_ref_updated", 0))
 
 	// Always return 200 OK immediately to Bitbucket
 	w.WriteHeader(http.StatusOK)
 	fmt.Fprintln(w, "Pull request queued for review")
 }
 
+// leftDraft reports whether a pr:modified event takes the PR out of draft, so that the review
+// held back by review.drafts runs now
+func (h *BitbucketWebhookHandler) leftDraft(body []byte) bool {
+	policy := h.config.Review.Drafts
+	if policy == "" || policy == config.DraftPolicyReview {
+		return false
+	}
+	return gjson.GetBytes(body, "previousDraft").Bool() && !gjson.GetBytes(body, "pullRequest.draft").Bool()
+}
+
+// parseFunc turns a queued payload into a PullRequest inside the worker
+type parseFunc func(ctx context.Context) (*domain.PullRequest, error)
+
+// withFaults makes the review fail with the faults named in the fault injection header
+func (h *BitbucketWebhookHandler) withFaults(r *http.Request, parse parseFunc) parseFunc {
+	header := h.faults.Header()
+	if header == "" {
+		return parse
+	}
+	kinds := fault.ParseHeader(r.Header.Get(header))
+	if len(kinds) == 0 {
+		return parse
+	}
+	slog.Warn("faults requested for review", "faults", kinds)
+	return func(ctx context.Context) (*domain.PullRequest, error) {
+		pr, err := parse(ctx)
+		if pr != nil {
+			pr.Faults = kinds
+		}
+		return pr, err
+	}
+}
+
+// enqueue records the latest payload of a PR and schedules its review via the debouncer.
+// With a job store, job is journaled until the review finishes; nil is not journaled.
+// The review waits in the worker pool with the priority of the latest payload.
+func (h *BitbucketWebhookHandler) enqueue(uniqueKey string, parse parseFunc, job *storage.QueuedJob, priority Priority) {
+	h.journal(uniqueKey, job)
+	h.priorities.Store(uniqueKey, priority)
+	h.latestPayloads.Store(uniqueKey, parse)
+	h.debouncer.Add(uniqueKey, func() {
+		h.submitJob(uniqueKey)
+	})
+}
+
 func (h *BitbucketWebhookHandler) submitJob(uniqueKey string) {
+	// While paused, pending reviews stay in latestPayloads for the snapshot
+	if h.paused() {
+		return
+	}
+
+	seq := h.jobSeq(uniqueKey)
+
 	// 1. Retrieve Payload
 	val, ok := h.latestPayloads.Load(uniqueKey) // Don't Delete yet, wait until processed? No, Load is fine.
+	// A PR over the rate limit keeps its payload queued until its retry
+	if ok && h.rateLimited(uniqueKey) {
+		return
+	}
 	// Actually LoadAndDelete might be safer to ensure we process exactly what we have?
 	// But if a new one comes in *while* we are submitting?
 	// Let's LoadAndDelete. If a new one comes, it re-adds to map and schedules debouncer.
@@ -178,12 +306,41 @@ func (h *BitbucketWebhookHandler) submitJob(uniqueKey string) {
 	if !ok {
 		return
 	}
-	payload := val.([]byte)
+	parse := val.(parseFunc)
+	priority := h.takePriority(uniqueKey)
+
+	// With an external queue the review waits there instead of in the worker pool
+	if h.reviewQueue != nil {
+		h.pushReview(uniqueKey, seq, parse, priority)
+		return
+	}
 
 	// 2. Submit to WorkerPool
-	err := h.workerPool.Submit(func(ctx context.Context) error {
+	err := h.workerPool.SubmitPriority(h.journaled(uniqueKey, seq, h.reviewJob(uniqueKey, parse)), priority)
+
+	if err == nil {
+		h.supersede(uniqueKey)
+	} else {
+		if err == ErrQueueFull {
+			slog.Warn("worker pool queue full, dropping request", "pr", uniqueKey)
+			metrics.WebhookRequests.WithLabelValues("dropped_full").Inc()
+			h.finishJob(uniqueKey, seq)
+		} else {
+			slog.Error("submit job failed", "error", err)
+		}
+	}
+}
+
+// reviewJob is the worker pool job reviewing the PR of uniqueKey from its parsed payload
+func (h *BitbucketWebhookHandler) reviewJob(uniqueKey string, parse parseFunc) Job {
+	return func(ctx context.Context) error {
 		// Acquire PR-level Lock to ensure serial processing for this PR
 		// This protects against multiple workers picking up different debounced events for same PR (rare but possible)
+		if h.paused() {
+			h.park(uniqueKey, parse)
+			return nil
+		}
+
 		h.keyLock.Lock(uniqueKey)
 		defer h.keyLock.Unlock(uniqueKey)
 
@@ -194,12 +351,18 @@ func (h *BitbucketWebhookHandler) submitJob(uniqueKey string) {
 			}
 		}()
 
-		// Full Parse inside worker
-		// Calculate timeout for actual processing
-		procCtx, cancel := context.WithTimeout(ctx, 15*time.Minute)
-		defer cancel()
-
-		pr, err := h.parser.Parse(procCtx, payload)
+		// Full Parse inside worker. The PR is not known yet, so the parse gets the shortest
+		// timeout any PR may have.
+		start := time.Now()
+		trackCtx, done := h.trackReview(ctx, uniqueKey)
+		defer done()
+		parseCtx, cancelParse := context.WithTimeoutCause(trackCtx, h.config.ShortestReviewTimeout(), domain.ErrReviewTimeout)
+		pr, err := parse(parseCtx)
+		cancelParse()
+		if cause := stopped(trackCtx); cause != nil {
+			slog.Info("review stopped", "pr", uniqueKey, "reason", cause)
+			return nil
+		}
 		if err != nil {
 			slog.Error("payload parse failed", "error", err)
 			metrics.PayloadParseFailures.WithLabelValues("both").Inc()
@@ -212,28 +375,134 @@ func (h *BitbucketWebhookHandler) submitJob(uniqueKey string) {
 			return fmt.Errorf("invalid pr")
 		}
 
+		// Timeout for actual processing, counted from the start of the parse
+		timeout := h.reviewTimeout(pr)
+		procCtx, cancel := context.WithDeadlineCause(trackCtx, start.Add(timeout), domain.ErrReviewTimeout)
+		defer cancel()
+
 		slog.Info("processing pr", "pr_id", pr.ID, "repo", pr.RepoSlug)
 		if err := h.prProcessor.ProcessPullRequest(procCtx, pr); err != nil {
+			if cause := stopped(procCtx); cause != nil {
+				slog.Info("review stopped", "pr", uniqueKey, "reason", cause)
+				return nil
+			}
+			// The processor recorded the timeout; returning the deadline error would requeue
+			// the review and let it run past its timeout again
+			if errors.Is(context.Cause(procCtx), domain.ErrReviewTimeout) {
+				slog.Error("review timed out", "pr", uniqueKey, "timeout", timeout)
+				return nil
+			}
 			slog.Error("process pr failed", "error", err, "pr_id", pr.ID)
 			return err
 		}
 		return nil
+	}
+}
+
+// allowed checks the target repository of the event against the review scope.
+// Denied events are recorded in the skip ledger when the processor keeps one.
+func (h *BitbucketWebhookHandler) allowed(body []byte) bool {
+	if h.gate == nil {
+		return true
+	}
+	// The target repository decides, as for the parsed PullRequest
+	projectKey := gjson.GetBytes(body, "pullRequest.toRef.repository.project.key").String()
+	repoSlug := gjson.GetBytes(body, "pullRequest.toRef.repository.slug").String()
+	if projectKey == "" || repoSlug == "" {
+		projectKey = gjson.GetBytes(body, "pullRequest.fromRef.repository.project.key").String()
+		repoSlug = gjson.GetBytes(body, "pullRequest.fromRef.repository.slug").String()
+	}
+	if projectKey == "" || repoSlug == "" {
+		// Unknown structures are left to the parser
+		return true
+	}
+
+	return h.allowedPR(&domain.PullRequest{
+		ID:           gjson.GetBytes(body, "pullRequest.id").String(),
+		ProjectKey:   projectKey,
+		RepoSlug:     repoSlug,
+		LatestCommit: gjson.GetBytes(body, "pullRequest.fromRef.latestCommit").String(),
 	})
+}
 
-	if err != nil {
-		if err == ErrQueueFull {
-			slog.Warn("worker pool queue full, dropping request", "pr", uniqueKey)
-			metrics.WebhookRequests.WithLabelValues("dropped_full").Inc()
-			// We can't return 429 here because this is async.
-			// Ideally we would return 429 in ServeHTTP if we checked queue size there.
-			// Implementing "Fail Fast" in ServeHTTP:
-			// len(p.Queue) == cap(p.Queue) -> return 429.
-			// But since we debounce, we might not know if queue is full until later.
-			// However, dropping here is the fallback safety.
-		} else {
-			slog.Error("submit job failed", "error", err)
+// allowedPR checks pr against the review scope and records denied PRs in the skip ledger
+func (h *BitbucketWebhookHandler) allowedPR(pr *domain.PullRequest) bool {
+	if h.gate == nil {
+		return true
+	}
+	ok, reason := h.gate.Allowed(pr.ProjectKey, pr.RepoSlug)
+	if ok {
+		return true
+	}
+	slog.Debug("repository not enabled for review", "project", pr.ProjectKey, "repo", pr.RepoSlug, "reason", reason)
+	metrics.WebhookRequests.WithLabelValues("ignored_repo").Inc()
+
+	if recorder, ok := h.prProcessor.(skipRecorder); ok {
+		// Recording may post a transparency note, so it runs off the request path
+		if err := h.workerPool.SubmitPriority(func(ctx context.Context) error {
+			recorder.RecordSkip(ctx, pr, domain.SkipReasonEventFilter, reason)
+			return nil
+		}, PriorityLow); err != nil {
+			slog.Warn("record skip failed", "error", err)
 		}
 	}
+	return false
+}
+
+// skipRecorder is implemented by processors that keep a skip ledger
+type skipRecorder interface {
+	RecordSkip(ctx context.Context, pr *domain.PullRequest, reason, detail string)
+}
+
+// SubmitReview queues a review requested through the API, a comment command or the poller. The
+// review scope is not checked, since the PR was asked for explicitly; debouncing and the worker
+// pool are shared with webhooks.
+func (h *BitbucketWebhookHandler) SubmitReview(pr *domain.PullRequest) {
+	uniqueKey := fmt.Sprintf("%s/%s/%s", pr.ProjectKey, pr.RepoSlug, pr.ID)
+	if pr.Provider != "" && pr.Provider != domain.ProviderBitbucket {
+		uniqueKey = pr.Provider + "/" + uniqueKey
+	}
+	slog.Info("review requested", "key", uniqueKey, "overrides", pr.Overrides)
+	h.enqueue(uniqueKey, func(ctx context.Context) (*domain.PullRequest, error) {
+		return pr, nil
+	}, prJob(pr), queuedPriority(pr))
+}
+
+// submitMerged queues merge follow-up actions. Merge events are final, so they skip the debouncer.
+// It returns ErrQueueFull when the worker pool has no room, for the sender to retry.
+func (h *BitbucketWebhookHandler) submitMerged(payload []byte) error {
+	err := h.workerPool.Submit(func(ctx context.Context) error {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("Panic recovered in merge worker", "panic", r, "stack", string(debug.Stack()))
+			}
+		}()
+
+		procCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+
+		pr, err := h.parser.Parse(procCtx, payload)
+		if err != nil {
+			slog.Error("merge payload parse failed", "error", err)
+			metrics.PayloadParseFailures.WithLabelValues("both").Inc()
+			return err
+		}
+		if !pr.IsValid() {
+			metrics.WebhookRequests.WithLabelValues("invalid_payload").Inc()
+			return fmt.Errorf("invalid pr")
+		}
+
+		slog.Info("processing merged pr", "pr_id", pr.ID, "repo", pr.RepoSlug)
+		if err := h.mergeHandler.HandleMerged(procCtx, pr); err != nil {
+			slog.Error("merge handler failed", "error", err, "pr_id", pr.ID)
+			return err
+		}
+		return nil
+	})
+	if err == ErrQueueFull {
+		slog.Warn("worker pool queue full, rejecting merge event")
+	}
+	return err
 }
 
 // verifySignature validates the HMAC-SHA256 signature of a webhook request
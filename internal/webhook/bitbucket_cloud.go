@@ -0,0 +1,146 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+
+	"github.com/tidwall/gjson"
+)
+
+// BitbucketCloudWebhookHandler handles Bitbucket Cloud pull request events on their own path,
+// so one deployment serves Bitbucket Server (/webhook) and Cloud side by side. Reviews are
+// queued on the Bitbucket Server handler's worker pool and review scope.
+type BitbucketCloudWebhookHandler struct {
+	config *config.Config
+	queue  *BitbucketWebhookHandler
+}
+
+// NewBitbucketCloudWebhookHandler creates a Bitbucket Cloud webhook handler that queues reviews on queue
+func NewBitbucketCloudWebhookHandler(cfg *config.Config, queue *BitbucketWebhookHandler) *BitbucketCloudWebhookHandler {
+	return &BitbucketCloudWebhookHandler{config: cfg, queue: queue}
+}
+
+// ServeHTTP handles incoming Bitbucket Cloud webhook requests
+func (h *BitbucketCloudWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	metrics.WebhookRequests.WithLabelValues("received").Inc()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.queue.rejectPaused(w) {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.config.Server.MaxBodySize)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		slog.Warn("read body failed", "error", err)
+		http.Error(w, "Error reading request body", http.StatusBadRequest)
+		metrics.WebhookRequests.WithLabelValues("error_read").Inc()
+		return
+	}
+
+	if secret := h.config.BitbucketCloud.WebhookSecret; secret != "" {
+		signature := r.Header.Get("X-Hub-Signature")
+		if signature == "" || !verifySignature(body, signature, secret) {
+			slog.Warn("invalid bitbucket cloud signature", "present", signature != "")
+			http.Error(w, "Invalid signature", http.StatusUnauthorized)
+			metrics.WebhookRequests.WithLabelValues("invalid_signature").Inc()
+			return
+		}
+	}
+
+	metrics.WebhookRequests.WithLabelValues("accepted").Inc()
+
+	event := r.Header.Get("X-Event-Key")
+	if event == "pullrequest:fulfilled" && h.queue.mergeHandler != nil {
+		if err := h.queue.submitMerged(body); err != nil {
+			h.queue.tooBusy(w)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "Merge event queued")
+		return
+	}
+	if event != "pullrequest:created" && event != "pullrequest:updated" {
+		slog.Debug("ignoring bitbucket cloud event", "event", event)
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "Event ignored")
+		metrics.WebhookRequests.WithLabelValues("ignored_event").Inc()
+		return
+	}
+
+	pr := probeBitbucketCloud(body)
+	if h.queue.ignoreBot(w, gjson.GetBytes(body, "actor.nickname").String(), gjson.GetBytes(body, "pullrequest.author.nickname").String()) {
+		return
+	}
+	if pr.IsValid() && !h.queue.allowedPR(pr) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "Repository not enabled for review")
+		return
+	}
+	if h.queue.rejectFull(w) {
+		return
+	}
+
+	parse := func(ctx context.Context) (*domain.PullRequest, error) {
+		if pr.IsValid() || h.queue.parser == nil {
+			return pr, nil
+		}
+		extracted, err := h.queue.parser.Parse(ctx, body)
+		if err != nil {
+			return nil, err
+		}
+		extracted.Provider = domain.ProviderBitbucketCloud
+		return extracted, nil
+	}
+
+	uniqueKey := fmt.Sprintf("%s/%s/%s/%s", domain.ProviderBitbucketCloud, pr.ProjectKey, pr.RepoSlug, pr.ID)
+	if !pr.IsValid() {
+		uniqueKey = fmt.Sprintf("%s/unknown-%d", domain.ProviderBitbucketCloud, time.Now().UnixNano())
+	}
+	h.queue.enqueue(uniqueKey, h.queue.withFaults(r, parse), prJob(pr), h.queue.reviewPriority(event == "pullrequest:created", 0))
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "Pull request queued for review")
+}
+
+// probeBitbucketCloud maps a Bitbucket Cloud pull request event to a PullRequest.
+// The workspace takes the place of the Bitbucket Server project key; both come from the
+// destination repository's full name ("workspace/repo-slug").
+func probeBitbucketCloud(body []byte) *domain.PullRequest {
+	if !gjson.ValidBytes(body) {
+		return &domain.PullRequest{Provider: domain.ProviderBitbucketCloud}
+	}
+	get := func(path string) string { return gjson.GetBytes(body, path).String() }
+
+	fullName := probe(body, []string{"pullrequest.destination.repository.full_name", "repository.full_name"}).String()
+	workspace, repoSlug, _ := strings.Cut(fullName, "/")
+	authorMention, reviewerMentions := probeMentions(body)
+
+	return &domain.PullRequest{
+		ID:               get("pullrequest.id"),
+		ProjectKey:       workspace,
+		RepoSlug:         repoSlug,
+		Title:            get("pullrequest.title"),
+		Description:      get("pullrequest.description"),
+		Author:           probe(body, []string{"pullrequest.author.display_name", "pullrequest.author.nickname"}).String(),
+		LatestCommit:     get("pullrequest.source.commit.hash"),
+		WebURL:           get("pullrequest.links.html.href"),
+		SourceBranch:     get("pullrequest.source.branch.name"),
+		TargetBranch:     get("pullrequest.destination.branch.name"),
+		Provider:         domain.ProviderBitbucketCloud,
+		AuthorMention:    authorMention,
+		ReviewerMentions: reviewerMentions,
+	}
+}
@@ -0,0 +1,106 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+const bitbucketCloudPayload = `{
+	"actor": {"display_name": "Jane Doe", "account_id": "557058:jane"},
+	"pullrequest": {
+		"id": 12,
+		"title": "Add retries",
+		"description": "Retries flaky calls",
+		"author": {"display_name": "Jane Doe", "account_id": "557058:jane"},
+		"reviewers": [{"display_name": "Bob", "account_id": "557058:bob"}],
+		"source": {"commit": {"hash": "1a2b3c4d5e6f"}, "repository": {"full_name": "jdoe/api"}},
+		"destination": {"repository": {"full_name": "acme/api"}},
+		"links": {"html": {"href": "https://bitbucket.org/acme/api/pull-requests/12"}}
+	},
+	"repository": {"type": "repository", "full_name": "acme/api", "name": "API"}
+}`
+
+func TestBitbucketCloudWebhookHandler(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.MaxBodySize = 2 * 1024 * 1024
+	cfg.Server.ConcurrencyLimit = 1
+	cfg.Server.QueueSize = 10
+	cfg.Server.DebounceWindow = 10 * time.Millisecond
+	cfg.BitbucketCloud.WebhookSecret = "s3cret"
+
+	processed := make(chan *domain.PullRequest, 1)
+	queue := NewBitbucketWebhookHandler(cfg, &MockProcessor{
+		ProcessFunc: func(ctx context.Context, pr *domain.PullRequest) error {
+			processed <- pr
+			return nil
+		},
+	}, createTestParser(t, &MockLLM{}))
+	handler := NewBitbucketCloudWebhookHandler(cfg, queue)
+
+	mac := hmac.New(sha256.New, []byte("s3cret"))
+	mac.Write([]byte(bitbucketCloudPayload))
+	valid := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+
+	tests := []struct {
+		name       string
+		event      string
+		signature  string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "invalid signature", event: "pullrequest:created", signature: "sha256=00", wantStatus: http.StatusUnauthorized},
+		{name: "ignored event", event: "repo:push", signature: valid, wantStatus: http.StatusOK, wantBody: "Event ignored\n"},
+		{name: "created", event: "pullrequest:created", signature: valid, wantStatus: http.StatusOK, wantBody: "Pull request queued for review\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/webhook/bitbucket-cloud", bytes.NewBufferString(bitbucketCloudPayload))
+			req.Header.Set("X-Event-Key", tt.event)
+			req.Header.Set("X-Hub-Signature", tt.signature)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+
+	select {
+	case pr := <-processed:
+		if pr.ID != "12" || pr.ProjectKey != "acme" || pr.RepoSlug != "api" || pr.LatestCommit != "1a2b3c4d5e6f" ||
+			pr.Provider != domain.ProviderBitbucketCloud || pr.Author != "Jane Doe" || pr.WebURL != "https://bitbucket.org/acme/api/pull-requests/12" {
+			t.Errorf("unexpected pr: %+v", pr)
+		}
+		if pr.AuthorMention != "@{557058:jane}" || len(pr.ReviewerMentions) != 1 || pr.ReviewerMentions[0] != "@{557058:bob}" {
+			t.Errorf("mentions = %q %v", pr.AuthorMention, pr.ReviewerMentions)
+		}
+	case <-time.After(time.Second):
+		t.Error("timeout waiting for pull request to be processed")
+	}
+	queue.WaitForCompletion()
+}
+
+func TestPayloadParser_BitbucketCloud(t *testing.T) {
+	parser := createTestParser(t, &MockLLM{})
+	pr, err := parser.Parse(context.Background(), []byte(bitbucketCloudPayload))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pr.ProjectKey != "acme" || pr.RepoSlug != "api" || pr.ID != "12" || pr.Provider != domain.ProviderBitbucketCloud {
+		t.Errorf("unexpected pr: %+v", pr)
+	}
+}
@@ -16,6 +16,7 @@ import (
 	"pr-review-automation/internal/config"
 	"pr-review-automation/internal/domain"
 	"pr-review-automation/internal/pipeline"
+	"pr-review-automation/internal/scope"
 
 	"github.com/openai/openai-go"
 )
@@ -66,15 +67,19 @@ func createTestParser(t *testing.T, llm *MockLLM) *PayloadParser {
 func TestBitbucketWebhookHandler_MethodNotAllowed(t *testing.T) {
 	cfg := &config.Config{
 		Server: struct {
-			Port             int           `yaml:"port"`
-			ConcurrencyLimit int64         `yaml:"concurrency_limit"`
-			ReadTimeout      time.Duration `yaml:"read_timeout"`
-			WriteTimeout     time.Duration `yaml:"write_timeout"`
-			ShutdownTimeout  time.Duration `yaml:"shutdown_timeout"`
-			MaxBodySize      int64         `yaml:"max_body_size"`
-			QueueSize        int           `yaml:"queue_size"`
-			DebounceWindow   time.Duration `yaml:"debounce_window"`
-			WebhookSecret    string        `yaml:"-"`
+			Port             int                    `yaml:"port"`
+			ConcurrencyLimit int64                  `yaml:"concurrency_limit"`
+			ReadTimeout      time.Duration          `yaml:"read_timeout"`
+			WriteTimeout     time.Duration          `yaml:"write_timeout"`
+			ShutdownTimeout  time.Duration          `yaml:"shutdown_timeout"`
+			MaxBodySize      int64                  `yaml:"max_body_size"`
+			QueueSize        int                    `yaml:"queue_size"`
+			DebounceWindow   time.Duration          `yaml:"debounce_window"`
+			WebhookSecret    string                 `yaml:"-"`
+			Autoscale        config.AutoscaleConfig `yaml:"autoscale"`
+			RateLimit        config.RateLimitConfig `yaml:"rate_limit"`
+			Priorities       config.PriorityConfig  `yaml:"priorities"`
+			ReviewTimeout    config.TimeoutConfig   `yaml:"review_timeout"`
 		}{
 			MaxBodySize:      2 * 1024 * 1024,
 			ConcurrencyLimit: 10,
@@ -98,15 +103,19 @@ func TestBitbucketWebhookHandler_MethodNotAllowed(t *testing.T) {
 func TestBitbucketWebhookHandler_InvalidJSON(t *testing.T) {
 	cfg := &config.Config{
 		Server: struct {
-			Port             int           `yaml:"port"`
-			ConcurrencyLimit int64         `yaml:"concurrency_limit"`
-			ReadTimeout      time.Duration `yaml:"read_timeout"`
-			WriteTimeout     time.Duration `yaml:"write_timeout"`
-			ShutdownTimeout  time.Duration `yaml:"shutdown_timeout"`
-			MaxBodySize      int64         `yaml:"max_body_size"`
-			QueueSize        int           `yaml:"queue_size"`
-			DebounceWindow   time.Duration `yaml:"debounce_window"`
-			WebhookSecret    string        `yaml:"-"`
+			Port             int                    `yaml:"port"`
+			ConcurrencyLimit int64                  `yaml:"concurrency_limit"`
+			ReadTimeout      time.Duration          `yaml:"read_timeout"`
+			WriteTimeout     time.Duration          `yaml:"write_timeout"`
+			ShutdownTimeout  time.Duration          `yaml:"shutdown_timeout"`
+			MaxBodySize      int64                  `yaml:"max_body_size"`
+			QueueSize        int                    `yaml:"queue_size"`
+			DebounceWindow   time.Duration          `yaml:"debounce_window"`
+			WebhookSecret    string                 `yaml:"-"`
+			Autoscale        config.AutoscaleConfig `yaml:"autoscale"`
+			RateLimit        config.RateLimitConfig `yaml:"rate_limit"`
+			Priorities       config.PriorityConfig  `yaml:"priorities"`
+			ReviewTimeout    config.TimeoutConfig   `yaml:"review_timeout"`
 		}{
 			MaxBodySize:      2 * 1024 * 1024,
 			ConcurrencyLimit: 10,
@@ -138,15 +147,19 @@ func TestBitbucketWebhookHandler_InvalidJSON(t *testing.T) {
 func TestBitbucketWebhookHandler_PROpenedEvent_L1(t *testing.T) {
 	cfg := &config.Config{
 		Server: struct {
-			Port             int           `yaml:"port"`
-			ConcurrencyLimit int64         `yaml:"concurrency_limit"`
-			ReadTimeout      time.Duration `yaml:"read_timeout"`
-			WriteTimeout     time.Duration `yaml:"write_timeout"`
-			ShutdownTimeout  time.Duration `yaml:"shutdown_timeout"`
-			MaxBodySize      int64         `yaml:"max_body_size"`
-			QueueSize        int           `yaml:"queue_size"`
-			DebounceWindow   time.Duration `yaml:"debounce_window"`
-			WebhookSecret    string        `yaml:"-"`
+			Port             int                    `yaml:"port"`
+			ConcurrencyLimit int64                  `yaml:"concurrency_limit"`
+			ReadTimeout      time.Duration          `yaml:"read_timeout"`
+			WriteTimeout     time.Duration          `yaml:"write_timeout"`
+			ShutdownTimeout  time.Duration          `yaml:"shutdown_timeout"`
+			MaxBodySize      int64                  `yaml:"max_body_size"`
+			QueueSize        int                    `yaml:"queue_size"`
+			DebounceWindow   time.Duration          `yaml:"debounce_window"`
+			WebhookSecret    string                 `yaml:"-"`
+			Autoscale        config.AutoscaleConfig `yaml:"autoscale"`
+			RateLimit        config.RateLimitConfig `yaml:"rate_limit"`
+			Priorities       config.PriorityConfig  `yaml:"priorities"`
+			ReviewTimeout    config.TimeoutConfig   `yaml:"review_timeout"`
 		}{
 			MaxBodySize:      2 * 1024 * 1024,
 			ConcurrencyLimit: 10,
@@ -210,15 +223,19 @@ func TestBitbucketWebhookHandler_PROpenedEvent_L1(t *testing.T) {
 func TestBitbucketWebhookHandler_PROpenedEvent_L2(t *testing.T) {
 	cfg := &config.Config{
 		Server: struct {
-			Port             int           `yaml:"port"`
-			ConcurrencyLimit int64         `yaml:"concurrency_limit"`
-			ReadTimeout      time.Duration `yaml:"read_timeout"`
-			WriteTimeout     time.Duration `yaml:"write_timeout"`
-			ShutdownTimeout  time.Duration `yaml:"shutdown_timeout"`
-			MaxBodySize      int64         `yaml:"max_body_size"`
-			QueueSize        int           `yaml:"queue_size"`
-			DebounceWindow   time.Duration `yaml:"debounce_window"`
-			WebhookSecret    string        `yaml:"-"`
+			Port             int                    `yaml:"port"`
+			ConcurrencyLimit int64                  `yaml:"concurrency_limit"`
+			ReadTimeout      time.Duration          `yaml:"read_timeout"`
+			WriteTimeout     time.Duration          `yaml:"write_timeout"`
+			ShutdownTimeout  time.Duration          `yaml:"shutdown_timeout"`
+			MaxBodySize      int64                  `yaml:"max_body_size"`
+			QueueSize        int                    `yaml:"queue_size"`
+			DebounceWindow   time.Duration          `yaml:"debounce_window"`
+			WebhookSecret    string                 `yaml:"-"`
+			Autoscale        config.AutoscaleConfig `yaml:"autoscale"`
+			RateLimit        config.RateLimitConfig `yaml:"rate_limit"`
+			Priorities       config.PriorityConfig  `yaml:"priorities"`
+			ReviewTimeout    config.TimeoutConfig   `yaml:"review_timeout"`
 		}{
 			MaxBodySize:      2 * 1024 * 1024,
 			ConcurrencyLimit: 10,
@@ -287,15 +304,19 @@ func TestBitbucketWebhookHandler_PROpenedEvent_L2(t *testing.T) {
 func TestBitbucketWebhookHandler_BodySizeLimit(t *testing.T) {
 	cfg := &config.Config{
 		Server: struct {
-			Port             int           `yaml:"port"`
-			ConcurrencyLimit int64         `yaml:"concurrency_limit"`
-			ReadTimeout      time.Duration `yaml:"read_timeout"`
-			WriteTimeout     time.Duration `yaml:"write_timeout"`
-			ShutdownTimeout  time.Duration `yaml:"shutdown_timeout"`
-			MaxBodySize      int64         `yaml:"max_body_size"`
-			QueueSize        int           `yaml:"queue_size"`
-			DebounceWindow   time.Duration `yaml:"debounce_window"`
-			WebhookSecret    string        `yaml:"-"`
+			Port             int                    `yaml:"port"`
+			ConcurrencyLimit int64                  `yaml:"concurrency_limit"`
+			ReadTimeout      time.Duration          `yaml:"read_timeout"`
+			WriteTimeout     time.Duration          `yaml:"write_timeout"`
+			ShutdownTimeout  time.Duration          `yaml:"shutdown_timeout"`
+			MaxBodySize      int64                  `yaml:"max_body_size"`
+			QueueSize        int                    `yaml:"queue_size"`
+			DebounceWindow   time.Duration          `yaml:"debounce_window"`
+			WebhookSecret    string                 `yaml:"-"`
+			Autoscale        config.AutoscaleConfig `yaml:"autoscale"`
+			RateLimit        config.RateLimitConfig `yaml:"rate_limit"`
+			Priorities       config.PriorityConfig  `yaml:"priorities"`
+			ReviewTimeout    config.TimeoutConfig   `yaml:"review_timeout"`
 		}{
 			MaxBodySize:      10, // Very small limit
 			ConcurrencyLimit: 10,
@@ -356,3 +377,229 @@ func TestVerifySignature_WrongAlgorithm(t *testing.T) {
 		t.Error("expected wrong algorithm to be rejected")
 	}
 }
+
+// mergeHandlerFunc adapts a function to processor.MergeHandler
+type mergeHandlerFunc func(ctx context.Context, pr *domain.PullRequest) error
+
+func (f mergeHandlerFunc) HandleMerged(ctx context.Context, pr *domain.PullRequest) error {
+	return f(ctx, pr)
+}
+
+func TestBitbucketWebhookHandler_PRMergedEvent(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.MaxBodySize = 2 * 1024 * 1024
+	cfg.Server.ConcurrencyLimit = 1
+	cfg.Server.QueueSize = 10
+
+	mockProc := &MockProcessor{
+		ProcessFunc: func(ctx context.Context, pr *domain.PullRequest) error {
+			t.Error("merge events must not trigger a review")
+			return nil
+		},
+	}
+	merged := make(chan *domain.PullRequest, 1)
+
+	handler := NewBitbucketWebhookHandler(cfg, mockProc, createTestParser(t, &MockLLM{}))
+	handler.SetMergeHandler(mergeHandlerFunc(func(ctx context.Context, pr *domain.PullRequest) error {
+		merged <- pr
+		return nil
+	}))
+
+	jsonBody := `{
+		"eventKey": "pr:merged",
+		"pullRequest": {
+			"id": 7,
+			"toRef": {"repository": {"slug": "my-repo", "project": {"key": "PROJ"}}}
+		}
+	}`
+	w := httptest.NewRecorder()
+	handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(jsonBody)))
+
+	if w.Code != http.StatusOK {
+		t.Errorf("expected status %d, got %d", http.StatusOK, w.Code)
+	}
+	select {
+	case pr := <-merged:
+		if pr.ID != "7" || pr.ProjectKey != "PROJ" {
+			t.Errorf("unexpected pr: %+v", pr)
+		}
+	case <-time.After(time.Second):
+		t.Error("timeout waiting for merge handler")
+	}
+	handler.WaitForCompletion()
+}
+
+func TestProbeMentions(t *testing.T) {
+	tests := []struct {
+		name          string
+		body          string
+		wantAuthor    string
+		wantReviewers []string
+	}{
+		{
+			name: "server",
+			body: `{"pullRequest": {
+				"author": {"user": {"name": "alice", "displayName": "Alice A"}},
+				"reviewers": [{"user": {"name": "bob"}}, {"user": {"name": "carol smith"}}]
+			}}`,
+			wantAuthor:    "@alice",
+			wantReviewers: []string{"@bob", `@"carol smith"`},
+		},
+		{
+			name: "cloud",
+			body: `{"pullrequest": {
+				"author": {"account_id": "557058:abc"},
+				"reviewers": [{"account_id": "557058:def"}]
+			}}`,
+			wantAuthor:    "@{557058:abc}",
+			wantReviewers: []string{"@{557058:def}"},
+		},
+		{name: "missing", body: `{"pullRequest": {}}`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			author, reviewers := probeMentions([]byte(tt.body))
+			if author != tt.wantAuthor {
+				t.Errorf("author = %q, want %q", author, tt.wantAuthor)
+			}
+			if len(reviewers) != len(tt.wantReviewers) {
+				t.Fatalf("reviewers = %q, want %q", reviewers, tt.wantReviewers)
+			}
+			for i := range reviewers {
+				if reviewers[i] != tt.wantReviewers[i] {
+					t.Errorf("reviewer[%d] = %q, want %q", i, reviewers[i], tt.wantReviewers[i])
+				}
+			}
+		})
+	}
+}
+
+func TestBitbucketWebhookHandler_RepoGate(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.MaxBodySize = 2 * 1024 * 1024
+	cfg.Server.ConcurrencyLimit = 1
+	cfg.Server.QueueSize = 10
+	cfg.Server.DebounceWindow = 10 * time.Millisecond
+
+	processed := make(chan *domain.PullRequest, 1)
+	handler := NewBitbucketWebhookHandler(cfg, &MockProcessor{
+		ProcessFunc: func(ctx context.Context, pr *domain.PullRequest) error {
+			processed <- pr
+			return nil
+		},
+	}, createTestParser(t, &MockLLM{}))
+	gate := scope.NewGate(config.ReviewScopeConfig{EnabledProjects: []string{"PROJ"}})
+	handler.SetGate(gate)
+
+	send := func(project string) string {
+		body := `{"eventKey": "pr:opened", "pullRequest": {"id": 1,
+			"toRef": {"repository": {"slug": "repo", "project": {"key": "` + project + `"}}}}}`
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(body)))
+		return w.Body.String()
+	}
+
+	if got := send("OTHER"); got != "Repository not enabled for review\n" {
+		t.Errorf("denied project: got %q", got)
+	}
+	gate.SetOverride("OTHER", "repo", true, "test")
+	send("OTHER")
+
+	select {
+	case pr := <-processed:
+		if pr.ProjectKey != "OTHER" {
+			t.Errorf("unexpected pr: %+v", pr)
+		}
+	case <-time.After(time.Second):
+		t.Error("timeout waiting for enabled repository to be processed")
+	}
+	handler.WaitForCompletion()
+}
+
+func TestBitbucketWebhookHandler_LeftDraft(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.MaxBodySize = 2 * 1024 * 1024
+	cfg.Server.ConcurrencyLimit = 1
+	cfg.Server.QueueSize = 10
+	cfg.Server.DebounceWindow = 10 * time.Millisecond
+	cfg.Review.Drafts = config.DraftPolicySkip
+
+	processed := make(chan *domain.PullRequest, 1)
+	handler := NewBitbucketWebhookHandler(cfg, &MockProcessor{
+		ProcessFunc: func(ctx context.Context, pr *domain.PullRequest) error {
+			processed <- pr
+			return nil
+		},
+	}, createTestParser(t, &MockLLM{}))
+
+	send := func(previousDraft, draft string) string {
+		body := `{"eventKey": "pr:modified", "previousDraft": ` + previousDraft + `, "pullRequest": {"id": 1, "draft": ` + draft + `,
+			"toRef": {"repository": {"slug": "repo", "project": {"key": "PROJ"}}}}}`
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(body)))
+		return w.Body.String()
+	}
+
+	if got := send("false", "true"); got != "Event ignored\n" {
+		t.Errorf("PR moved to draft: got %q", got)
+	}
+	if got := send("true", "false"); got != "Pull request queued for review\n" {
+		t.Errorf("PR left draft: got %q", got)
+	}
+	select {
+	case pr := <-processed:
+		if pr.Draft {
+			t.Errorf("ready PR parsed as draft: %+v", pr)
+		}
+	case <-time.After(time.Second):
+		t.Error("timeout waiting for the ready PR to be processed")
+	}
+	handler.WaitForCompletion()
+
+	// Drafts that were reviewed need no second review
+	cfg.Review.Drafts = config.DraftPolicyReview
+	if got := send("true", "false"); got != "Event ignored\n" {
+		t.Errorf("review policy: got %q", got)
+	}
+}
+
+func TestBitbucketWebhookHandler_BotAccounts(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.MaxBodySize = 2 * 1024 * 1024
+	cfg.Server.ConcurrencyLimit = 1
+	cfg.Server.QueueSize = 10
+	cfg.Server.DebounceWindow = 10 * time.Millisecond
+	cfg.Review.BotAccounts = []string{"ci-bot"}
+
+	processed := make(chan *domain.PullRequest, 1)
+	handler := NewBitbucketWebhookHandler(cfg, &MockProcessor{
+		ProcessFunc: func(ctx context.Context, pr *domain.PullRequest) error {
+			processed <- pr
+			return nil
+		},
+	}, createTestParser(t, &MockLLM{}))
+	send := func(actor, author string) string {
+		body := `{"eventKey": "pr:from_ref_updated", "actor": {"slug": "` + actor + `"}, "pullRequest": {"id": 1,
+			"author": {"user": {"slug": "` + author + `"}},
+			"toRef": {"repository": {"slug": "repo", "project": {"key": "PROJ"}}}}}`
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(body)))
+		return w.Body.String()
+	}
+
+	if got := send("CI-Bot", "alice"); got != "Bot event ignored\n" {
+		t.Errorf("push by bot: got %q", got)
+	}
+	if got := send("alice", "ci-bot"); got != "Bot event ignored\n" {
+		t.Errorf("PR by bot: got %q", got)
+	}
+	if got := send("alice", "bob"); got != "Pull request queued for review\n" {
+		t.Errorf("push by person: got %q", got)
+	}
+	select {
+	case <-processed:
+	case <-time.After(time.Second):
+		t.Error("timeout waiting for the person's push to be processed")
+	}
+	handler.WaitForCompletion()
+}
@@ -0,0 +1,43 @@
+package webhook
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	"pr-review-automation/internal/metrics"
+
+	"github.com/tidwall/gjson"
+)
+
+// ignoreBot acknowledges an event when one of the accounts behind it (the actor or the PR
+// author) is listed in review.bot_accounts, so pushes and comments by automation do not
+// trigger reviews in a loop. It reports whether the event was ignored.
+func (h *BitbucketWebhookHandler) ignoreBot(w http.ResponseWriter, accounts ...string) bool {
+	for _, account := range accounts {
+		if account == "" {
+			continue
+		}
+		for _, bot := range h.config.Review.BotAccounts {
+			if strings.EqualFold(account, bot) {
+				slog.Debug("ignoring event of bot account", "account", account)
+				w.WriteHeader(http.StatusOK)
+				fmt.Fprintln(w, "Bot event ignored")
+				metrics.WebhookRequests.WithLabelValues("ignored_bot").Inc()
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// bitbucketActors returns the slug and name of the actor of a Bitbucket Server event
+func bitbucketActors(body []byte) []string {
+	return []string{gjson.GetBytes(body, "actor.slug").String(), gjson.GetBytes(body, "actor.name").String()}
+}
+
+// bitbucketAuthors returns the slug and name of the author of the event's pull request
+func bitbucketAuthors(body []byte) []string {
+	return []string{gjson.GetBytes(body, "pullRequest.author.user.slug").String(), gjson.GetBytes(body, "pullRequest.author.user.name").String()}
+}
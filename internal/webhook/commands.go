@@ -0,0 +1,129 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"slices"
+	"time"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/processor"
+
+	"github.com/tidwall/gjson"
+)
+
+// SetCommandHandler enables slash commands in pull request comments (pr:comment:added events)
+func (h *BitbucketWebhookHandler) SetCommandHandler(ch processor.CommandHandler) {
+	h.commands = ch
+}
+
+// SetConversationHandler enables answers to developer replies in AI comment threads (pr:comment:added events)
+func (h *BitbucketWebhookHandler) SetConversationHandler(rh processor.ReplyHandler) {
+	h.conversations = rh
+}
+
+// handleComment dispatches a slash command or a thread reply posted in a pull request comment.
+// Other comments, and comments by service accounts or ignored users, are acknowledged only.
+// "/ai review" is queued like an API-triggered review; other commands and replies run in the worker pool.
+func (h *BitbucketWebhookHandler) handleComment(w http.ResponseWriter, body []byte) {
+	text := gjson.GetBytes(body, "comment.text").String()
+	parentID := gjson.GetBytes(body, "commentParentId").Int()
+	cmd, isCommand := domain.ParseCommand(h.config.Commands.Prefix, text)
+	isCommand = isCommand && h.commands != nil
+	isReply := !isCommand && parentID > 0 && h.conversations != nil
+	actor := gjson.GetBytes(body, "actor")
+	if (!isCommand && !isReply) || actor.Get("type").String() == "SERVICE" || slices.Contains(h.config.Commands.IgnoreUsers, actor.Get("slug").String()) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "Comment ignored")
+		metrics.WebhookRequests.WithLabelValues("ignored_event").Inc()
+		return
+	}
+	// Only the commenter counts: people can still ask for reviews of a bot's PRs
+	if h.ignoreBot(w, bitbucketActors(body)...) {
+		return
+	}
+	if !h.allowed(body) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "Repository not enabled for review")
+		return
+	}
+
+	pr := h.parser.probePayload(body)
+	if !pr.IsValid() {
+		slog.Warn("could not extract pr identity from comment event")
+		http.Error(w, "Invalid pull request", http.StatusBadRequest)
+		metrics.WebhookRequests.WithLabelValues("invalid_payload").Inc()
+		return
+	}
+	author := actor.Get("displayName").String()
+	if author == "" {
+		author = actor.Get("name").String()
+	}
+	commentID := gjson.GetBytes(body, "comment.id").Int()
+
+	if isReply {
+		reply := domain.CommentReply{CommentID: commentID, ParentID: parentID, Text: text, Author: author}
+		h.submitCommentJob(w, "reply", "Reply queued", func(ctx context.Context) error {
+			if err := h.conversations.HandleReply(ctx, pr, reply); err != nil {
+				slog.Error("reply answer failed", "error", err, "pr_id", pr.ID, "comment_id", commentID)
+				return err
+			}
+			return nil
+		})
+		return
+	}
+
+	cmd.Author = author
+	cmd.CommentID = commentID
+	if cmd.Name == domain.CommandReview {
+		if h.rejectFull(w) {
+			return
+		}
+		// Requested reviews run even while automatic reviews are paused
+		pr.Overrides = &domain.ReviewOverrides{}
+		h.SubmitReview(pr)
+		metrics.SlashCommands.WithLabelValues(cmd.Name, "queued").Inc()
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "Pull request queued for review")
+		return
+	}
+	h.submitCommentJob(w, "command", "Command queued", func(ctx context.Context) error {
+		if err := h.commands.HandleCommand(ctx, pr, cmd); err != nil {
+			slog.Error("slash command failed", "error", err, "pr_id", pr.ID, "command", cmd.Name)
+			return err
+		}
+		return nil
+	})
+}
+
+// submitCommentJob runs job in the worker pool with a timeout and answers the webhook request.
+// Someone is waiting for the answer, so the job goes ahead of automatic reviews.
+func (h *BitbucketWebhookHandler) submitCommentJob(w http.ResponseWriter, kind, accepted string, job func(ctx context.Context) error) {
+	err := h.workerPool.SubmitPriority(func(ctx context.Context) error {
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("Panic recovered in comment worker", "kind", kind, "panic", r, "stack", string(debug.Stack()))
+			}
+		}()
+
+		jobCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+		defer cancel()
+		return job(jobCtx)
+	}, PriorityManual)
+	if err != nil {
+		if err == ErrQueueFull {
+			slog.Warn("worker pool queue full, rejecting comment job", "kind", kind)
+			h.tooBusy(w)
+			return
+		}
+		slog.Error("submit comment job failed", "error", err, "kind", kind)
+		http.Error(w, "Comment not accepted", http.StatusInternalServerError)
+		return
+	}
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, accepted)
+}
@@ -0,0 +1,148 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+type commandHandlerFunc func(ctx context.Context, pr *domain.PullRequest, cmd domain.Command) error
+
+func (f commandHandlerFunc) HandleCommand(ctx context.Context, pr *domain.PullRequest, cmd domain.Command) error {
+	return f(ctx, pr, cmd)
+}
+
+func commentEvent(text, actorType string) string {
+	return fmt.Sprintf(`{
+		"eventKey": "pr:comment:added",
+		"actor": {"name": "alice", "slug": "alice", "displayName": "Alice A", "type": %q},
+		"pullRequest": {
+			"id": 7,
+			"title": "Add cache",
+			"fromRef": {"latestCommit": "abc", "repository": {"slug": "my-repo", "project": {"key": "PROJ"}}},
+			"toRef": {"repository": {"slug": "my-repo", "project": {"key": "PROJ"}}}
+		},
+		"comment": {"id": 42, "text": %q}
+	}`, actorType, text)
+}
+
+func TestBitbucketWebhookHandler_SlashCommands(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.MaxBodySize = 2 * 1024 * 1024
+	cfg.Server.ConcurrencyLimit = 1
+	cfg.Server.QueueSize = 10
+	cfg.Server.DebounceWindow = 10 * time.Millisecond
+	cfg.Commands = config.CommandConfig{Enabled: true, Prefix: "/ai", IgnoreUsers: []string{"ci-bot"}}
+
+	reviewed := make(chan *domain.PullRequest, 1)
+	commands := make(chan domain.Command, 1)
+	handler := NewBitbucketWebhookHandler(cfg, &MockProcessor{ProcessFunc: func(ctx context.Context, pr *domain.PullRequest) error {
+		reviewed <- pr
+		return nil
+	}}, createTestParser(t, &MockLLM{}))
+	handler.SetCommandHandler(commandHandlerFunc(func(ctx context.Context, pr *domain.PullRequest, cmd domain.Command) error {
+		if pr.ID != "7" || pr.ProjectKey != "PROJ" {
+			t.Errorf("unexpected pr: %+v", pr)
+		}
+		commands <- cmd
+		return nil
+	}))
+	defer handler.WaitForCompletion()
+
+	post := func(body string) string {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(body)))
+		if w.Code != http.StatusOK {
+			t.Errorf("status = %d, want 200", w.Code)
+		}
+		return w.Body.String()
+	}
+
+	// Plain comments and comments by bots are not commands
+	for _, body := range []string{commentEvent("looks good", "NORMAL"), commentEvent("/ai review", "SERVICE")} {
+		if got := post(body); got != "Comment ignored\n" {
+			t.Errorf("response = %q, want Comment ignored", got)
+		}
+	}
+
+	post(commentEvent("/ai explain main.go:10", "NORMAL"))
+	select {
+	case cmd := <-commands:
+		want := domain.Command{Name: domain.CommandExplain, Args: "main.go:10", Author: "Alice A", CommentID: 42}
+		if cmd != want {
+			t.Errorf("command = %+v, want %+v", cmd, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for command")
+	}
+
+	// Review commands are queued as requested reviews
+	post(commentEvent("/ai review", "NORMAL"))
+	select {
+	case pr := <-reviewed:
+		if pr.Overrides == nil || pr.LatestCommit != "abc" {
+			t.Errorf("review = %+v, want a requested review of abc", pr)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for review")
+	}
+}
+
+type replyHandlerFunc func(ctx context.Context, pr *domain.PullRequest, reply domain.CommentReply) error
+
+func (f replyHandlerFunc) HandleReply(ctx context.Context, pr *domain.PullRequest, reply domain.CommentReply) error {
+	return f(ctx, pr, reply)
+}
+
+func TestBitbucketWebhookHandler_ThreadReplies(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.MaxBodySize = 2 * 1024 * 1024
+	cfg.Server.ConcurrencyLimit = 1
+	cfg.Server.QueueSize = 10
+	cfg.Server.DebounceWindow = 10 * time.Millisecond
+
+	replies := make(chan domain.CommentReply, 1)
+	handler := NewBitbucketWebhookHandler(cfg, &MockProcessor{}, createTestParser(t, &MockLLM{}))
+	handler.SetConversationHandler(replyHandlerFunc(func(ctx context.Context, pr *domain.PullRequest, reply domain.CommentReply) error {
+		replies <- reply
+		return nil
+	}))
+	defer handler.WaitForCompletion()
+
+	post := func(body string) string {
+		w := httptest.NewRecorder()
+		handler.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(body)))
+		return w.Body.String()
+	}
+	withParent := func(body string) string {
+		return strings.Replace(body, `"comment":`, `"commentParentId": 40, "comment":`, 1)
+	}
+
+	// Top-level comments and replies by bots are not answered
+	for _, body := range []string{commentEvent("why?", "NORMAL"), withParent(commentEvent("why?", "SERVICE"))} {
+		if got := post(body); got != "Comment ignored\n" {
+			t.Errorf("response = %q, want Comment ignored", got)
+		}
+	}
+
+	if got := post(withParent(commentEvent("why?", "NORMAL"))); got != "Reply queued\n" {
+		t.Errorf("response = %q, want Reply queued", got)
+	}
+	select {
+	case reply := <-replies:
+		want := domain.CommentReply{CommentID: 42, ParentID: 40, Text: "why?", Author: "Alice A"}
+		if reply != want {
+			t.Errorf("reply = %+v, want %+v", reply, want)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("timeout waiting for reply")
+	}
+}
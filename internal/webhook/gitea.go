@@ -0,0 +1,144 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+
+	"github.com/tidwall/gjson"
+)
+
+// GiteaWebhookHandler handles Gitea and Forgejo pull request events. Forgejo sends the same
+// payload under X-Forgejo-* headers. Reviews are queued on the Bitbucket handler's worker pool.
+type GiteaWebhookHandler struct {
+	config *config.Config
+	queue  *BitbucketWebhookHandler
+}
+
+// NewGiteaWebhookHandler creates a Gitea webhook handler that queues reviews on queue
+func NewGiteaWebhookHandler(cfg *config.Config, queue *BitbucketWebhookHandler) *GiteaWebhookHandler {
+	return &GiteaWebhookHandler{config: cfg, queue: queue}
+}
+
+// ServeHTTP handles incoming Gitea webhook requests
+func (h *GiteaWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	metrics.WebhookRequests.WithLabelValues("received").Inc()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.queue.rejectPaused(w) {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.config.Server.MaxBodySize)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		slog.Warn("read body failed", "error", err)
+		http.Error(w, "Error reading request body", http.StatusBadRequest)
+		metrics.WebhookRequests.WithLabelValues("error_read").Inc()
+		return
+	}
+
+	if secret := h.config.Gitea.WebhookSecret; secret != "" {
+		// The signature is the bare hex HMAC-SHA256 of the body
+		signature := giteaHeader(r, "Signature")
+		if signature == "" || !verifySignature(body, "sha256="+signature, secret) {
+			slog.Warn("invalid gitea signature", "present", signature != "")
+			http.Error(w, "Invalid signature", http.StatusUnauthorized)
+			metrics.WebhookRequests.WithLabelValues("invalid_signature").Inc()
+			return
+		}
+	}
+
+	metrics.WebhookRequests.WithLabelValues("accepted").Inc()
+
+	event := giteaHeader(r, "Event")
+	action := gjson.GetBytes(body, "action").String()
+	if event != "pull_request" || (action != "opened" && action != "reopened" && action != "synchronized") {
+		slog.Debug("ignoring gitea event", "event", event, "action", action)
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "Event ignored")
+		metrics.WebhookRequests.WithLabelValues("ignored_event").Inc()
+		return
+	}
+
+	pr := parseGiteaPullRequest(body)
+	if !pr.IsValid() {
+		slog.Warn("gitea payload missing pull request identity")
+		http.Error(w, "Invalid pull request payload", http.StatusBadRequest)
+		metrics.WebhookRequests.WithLabelValues("invalid_payload").Inc()
+		return
+	}
+
+	if h.queue.ignoreBot(w, gjson.GetBytes(body, "sender.login").String(), pr.Author) {
+		return
+	}
+	if !h.queue.allowedPR(pr) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "Repository not enabled for review")
+		return
+	}
+	if h.queue.rejectFull(w) {
+		return
+	}
+
+	uniqueKey := fmt.Sprintf("%s/%s/%s/%s", domain.ProviderGitea, pr.ProjectKey, pr.RepoSlug, pr.ID)
+	changed := gjson.GetBytes(body, "pull_request.additions").Int() + gjson.GetBytes(body, "pull_request.deletions").Int()
+	h.queue.enqueue(uniqueKey, h.queue.withFaults(r, func(ctx context.Context) (*domain.PullRequest, error) {
+		return pr, nil
+	}), prJob(pr), h.queue.reviewPriority(action != "synchronized", int(changed)))
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "Pull request queued for review")
+}
+
+// giteaHeader returns the X-Forgejo-<name> header, falling back to X-Gitea-<name>
+func giteaHeader(r *http.Request, name string) string {
+	if v := r.Header.Get("X-Forgejo-" + name); v != "" {
+		return v
+	}
+	return r.Header.Get("X-Gitea-" + name)
+}
+
+// parseGiteaPullRequest maps a pull request event to a PullRequest.
+// The owner takes the place of the Bitbucket project key.
+func parseGiteaPullRequest(body []byte) *domain.PullRequest {
+	if !gjson.ValidBytes(body) {
+		return &domain.PullRequest{}
+	}
+	get := func(path string) string { return gjson.GetBytes(body, path).String() }
+
+	pr := &domain.PullRequest{
+		ID:           get("pull_request.number"),
+		ProjectKey:   get("repository.owner.login"),
+		RepoSlug:     get("repository.name"),
+		Title:        get("pull_request.title"),
+		Description:  get("pull_request.body"),
+		Author:       get("pull_request.user.login"),
+		LatestCommit: get("pull_request.head.sha"),
+		WebURL:       get("pull_request.html_url"),
+		SourceBranch: get("pull_request.head.ref"),
+		TargetBranch: get("pull_request.base.ref"),
+		Provider:     domain.ProviderGitea,
+	}
+	if pr.Author != "" {
+		pr.AuthorMention = "@" + pr.Author
+	}
+	gjson.GetBytes(body, "pull_request.requested_reviewers.#.login").ForEach(func(_, v gjson.Result) bool {
+		pr.ReviewerMentions = append(pr.ReviewerMentions, "@"+v.String())
+		return true
+	})
+	gjson.GetBytes(body, "pull_request.labels.#.name").ForEach(func(_, v gjson.Result) bool {
+		pr.Labels = append(pr.Labels, v.String())
+		return true
+	})
+	return pr
+}
@@ -0,0 +1,102 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+const giteaPRPayload = `{
+	"action": "%s",
+	"number": 7,
+	"pull_request": {
+		"number": 7,
+		"title": "Fix login",
+		"body": "Handles expired sessions",
+		"html_url": "https://git.example.com/acme/web/pulls/7",
+		"user": {"login": "alice"},
+		"head": {"sha": "def456"},
+		"requested_reviewers": [{"login": "bob"}]
+	},
+	"repository": {"name": "web", "owner": {"login": "acme"}}
+}`
+
+func TestGiteaWebhookHandler(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.MaxBodySize = 2 * 1024 * 1024
+	cfg.Server.ConcurrencyLimit = 1
+	cfg.Server.QueueSize = 10
+	cfg.Server.DebounceWindow = 10 * time.Millisecond
+	cfg.Gitea.WebhookSecret = "s3cret"
+
+	processed := make(chan *domain.PullRequest, 1)
+	queue := NewBitbucketWebhookHandler(cfg, &MockProcessor{
+		ProcessFunc: func(ctx context.Context, pr *domain.PullRequest) error {
+			processed <- pr
+			return nil
+		},
+	}, createTestParser(t, &MockLLM{}))
+	handler := NewGiteaWebhookHandler(cfg, queue)
+
+	sign := func(body string) string {
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write([]byte(body))
+		return hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name       string
+		prefix     string // Header prefix: X-Gitea- or X-Forgejo-
+		event      string
+		action     string
+		signature  func(string) string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "invalid signature", prefix: "X-Gitea-", event: "pull_request", action: "opened", signature: func(string) string { return "00" }, wantStatus: http.StatusUnauthorized},
+		{name: "ignored action", prefix: "X-Gitea-", event: "pull_request", action: "closed", signature: sign, wantStatus: http.StatusOK, wantBody: "Event ignored\n"},
+		{name: "ignored event", prefix: "X-Forgejo-", event: "push", action: "", signature: sign, wantStatus: http.StatusOK, wantBody: "Event ignored\n"},
+		{name: "forgejo synchronized", prefix: "X-Forgejo-", event: "pull_request", action: "synchronized", signature: sign, wantStatus: http.StatusOK, wantBody: "Pull request queued for review\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := fmt.Sprintf(giteaPRPayload, tt.action)
+			req := httptest.NewRequest(http.MethodPost, "/webhook/gitea", bytes.NewBufferString(body))
+			req.Header.Set(tt.prefix+"Event", tt.event)
+			req.Header.Set(tt.prefix+"Signature", tt.signature(body))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+
+	select {
+	case pr := <-processed:
+		if pr.ID != "7" || pr.ProjectKey != "acme" || pr.RepoSlug != "web" || pr.LatestCommit != "def456" ||
+			pr.Provider != domain.ProviderGitea || pr.Author != "alice" || pr.WebURL != "https://git.example.com/acme/web/pulls/7" {
+			t.Errorf("unexpected pr: %+v", pr)
+		}
+		if pr.AuthorMention != "@alice" || len(pr.ReviewerMentions) != 1 || pr.ReviewerMentions[0] != "@bob" {
+			t.Errorf("mentions = %q %v", pr.AuthorMention, pr.ReviewerMentions)
+		}
+	case <-time.After(time.Second):
+		t.Error("timeout waiting for pull request to be processed")
+	}
+	queue.WaitForCompletion()
+}
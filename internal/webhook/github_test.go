@@ -0,0 +1,142 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+const githubPRPayload = `{
+	"action": "%s",
+	"pull_request": {
+		"number": 42,
+		"title": "Add cache",
+		"body": "Speeds up lookups",
+		"html_url": "https://github.com/acme/api/pull/42",
+		"user": {"login": "octocat"},
+		"head": {"sha": "abc123", "ref": "feature/cache"},
+		"base": {"ref": "main"},
+		"requested_reviewers": [{"login": "hubot"}],
+		"labels": [{"name": "backend"}]
+	},
+	"repository": {"name": "api", "owner": {"login": "acme"}}
+}`
+
+func TestGitHubWebhookHandler(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.MaxBodySize = 2 * 1024 * 1024
+	cfg.Server.ConcurrencyLimit = 1
+	cfg.Server.QueueSize = 10
+	cfg.Server.DebounceWindow = 10 * time.Millisecond
+	cfg.GitHub.WebhookSecret = "s3cret"
+
+	processed := make(chan *domain.PullRequest, 1)
+	queue := NewBitbucketWebhookHandler(cfg, &MockProcessor{
+		ProcessFunc: func(ctx context.Context, pr *domain.PullRequest) error {
+			processed <- pr
+			return nil
+		},
+	}, createTestParser(t, &MockLLM{}))
+	handler := NewGitHubWebhookHandler(cfg, queue)
+
+	sign := func(body string) string {
+		mac := hmac.New(sha256.New, []byte("s3cret"))
+		mac.Write([]byte(body))
+		return "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	}
+
+	tests := []struct {
+		name       string
+		event      string
+		action     string
+		signature  func(string) string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "invalid signature", event: "pull_request", action: "opened", signature: func(string) string { return "sha256=00" }, wantStatus: http.StatusUnauthorized},
+		{name: "ignored action", event: "pull_request", action: "closed", signature: sign, wantStatus: http.StatusOK, wantBody: "Event ignored\n"},
+		{name: "ignored event", event: "push", action: "", signature: sign, wantStatus: http.StatusOK, wantBody: "Event ignored\n"},
+		{name: "opened", event: "pull_request", action: "opened", signature: sign, wantStatus: http.StatusOK, wantBody: "Pull request queued for review\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := fmt.Sprintf(githubPRPayload, tt.action)
+			req := httptest.NewRequest(http.MethodPost, "/webhook/github", bytes.NewBufferString(body))
+			req.Header.Set("X-GitHub-Event", tt.event)
+			req.Header.Set("X-Hub-Signature-256", tt.signature(body))
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+
+	select {
+	case pr := <-processed:
+		want := domain.PullRequest{ID: "42", ProjectKey: "acme", RepoSlug: "api", Title: "Add cache", Description: "Speeds up lookups",
+			Author: "octocat", LatestCommit: "abc123", WebURL: "https://github.com/acme/api/pull/42", Provider: domain.ProviderGitHub,
+			SourceBranch: "feature/cache", TargetBranch: "main"}
+		if pr.ID != want.ID || pr.ProjectKey != want.ProjectKey || pr.RepoSlug != want.RepoSlug || pr.LatestCommit != want.LatestCommit ||
+			pr.Provider != want.Provider || pr.WebURL != want.WebURL || pr.Author != want.Author ||
+			pr.SourceBranch != want.SourceBranch || pr.TargetBranch != want.TargetBranch {
+			t.Errorf("pr = %+v, want %+v", pr, want)
+		}
+		if pr.AuthorMention != "@octocat" || len(pr.ReviewerMentions) != 1 || pr.ReviewerMentions[0] != "@hubot" {
+			t.Errorf("mentions = %q %v", pr.AuthorMention, pr.ReviewerMentions)
+		}
+		if len(pr.Labels) != 1 || pr.Labels[0] != "backend" {
+			t.Errorf("labels = %v", pr.Labels)
+		}
+	case <-time.After(time.Second):
+		t.Error("timeout waiting for pull request to be processed")
+	}
+	queue.WaitForCompletion()
+}
+
+func TestGitHubWebhookHandler_FaultHeader(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.MaxBodySize = 2 * 1024 * 1024
+	cfg.Server.ConcurrencyLimit = 1
+	cfg.Server.QueueSize = 10
+	cfg.Server.DebounceWindow = 10 * time.Millisecond
+	cfg.FaultInjection = config.FaultInjectionConfig{Enabled: true, Header: "X-Inject-Faults"}
+
+	processed := make(chan *domain.PullRequest, 1)
+	queue := NewBitbucketWebhookHandler(cfg, &MockProcessor{
+		ProcessFunc: func(ctx context.Context, pr *domain.PullRequest) error {
+			processed <- pr
+			return nil
+		},
+	}, createTestParser(t, &MockLLM{}))
+	handler := NewGitHubWebhookHandler(cfg, queue)
+
+	req := httptest.NewRequest(http.MethodPost, "/webhook/github", bytes.NewBufferString(fmt.Sprintf(githubPRPayload, "opened")))
+	req.Header.Set("X-GitHub-Event", "pull_request")
+	req.Header.Set("X-Inject-Faults", "llm_rate_limit, unknown")
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	select {
+	case pr := <-processed:
+		if len(pr.Faults) != 1 || pr.Faults[0] != config.FaultLLMRateLimit {
+			t.Errorf("faults = %v", pr.Faults)
+		}
+	case <-time.After(time.Second):
+		t.Error("timeout waiting for pull request to be processed")
+	}
+	queue.WaitForCompletion()
+}
@@ -0,0 +1,163 @@
+package webhook
+
+import (
+	"context"
+	"crypto/subtle"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+
+	"github.com/tidwall/gjson"
+)
+
+// GitLabWebhookHandler handles GitLab merge request events. Like the GitHub handler,
+// it queues reviews on the Bitbucket handler's worker pool and review scope.
+type GitLabWebhookHandler struct {
+	config *config.Config
+	queue  *BitbucketWebhookHandler
+	parser *PayloadParser // Optional: L2 extraction for payloads the fast path cannot map
+}
+
+// NewGitLabWebhookHandler creates a GitLab webhook handler that queues reviews on queue.
+// parser should use the GitLab payload filter; nil disables the LLM fallback.
+func NewGitLabWebhookHandler(cfg *config.Config, queue *BitbucketWebhookHandler, parser *PayloadParser) *GitLabWebhookHandler {
+	return &GitLabWebhookHandler{config: cfg, queue: queue, parser: parser}
+}
+
+// ServeHTTP handles incoming GitLab webhook requests
+func (h *GitLabWebhookHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	metrics.WebhookRequests.WithLabelValues("received").Inc()
+
+	if r.Method != http.MethodPost {
+		http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+		return
+	}
+	if h.queue.rejectPaused(w) {
+		return
+	}
+
+	r.Body = http.MaxBytesReader(w, r.Body, h.config.Server.MaxBodySize)
+	body, err := io.ReadAll(r.Body)
+	if err != nil {
+		slog.Warn("read body failed", "error", err)
+		http.Error(w, "Error reading request body", http.StatusBadRequest)
+		metrics.WebhookRequests.WithLabelValues("error_read").Inc()
+		return
+	}
+
+	// GitLab sends the configured secret token as is, not an HMAC
+	if secret := h.config.GitLab.WebhookSecret; secret != "" {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Gitlab-Token")), []byte(secret)) != 1 {
+			slog.Warn("invalid gitlab token")
+			http.Error(w, "Invalid token", http.StatusUnauthorized)
+			metrics.WebhookRequests.WithLabelValues("invalid_signature").Inc()
+			return
+		}
+	}
+
+	metrics.WebhookRequests.WithLabelValues("accepted").Inc()
+
+	event := r.Header.Get("X-Gitlab-Event")
+	if event != "Merge Request Hook" || !reviewableMergeRequestAction(body) {
+		slog.Debug("ignoring gitlab event", "event", event, "action", gjson.GetBytes(body, "object_attributes.action").String())
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "Event ignored")
+		metrics.WebhookRequests.WithLabelValues("ignored_event").Inc()
+		return
+	}
+
+	pr := parseGitLabMergeRequest(body)
+	if h.queue.ignoreBot(w, pr.Author) {
+		return
+	}
+	if pr.IsValid() && !h.queue.allowedPR(pr) {
+		w.WriteHeader(http.StatusOK)
+		fmt.Fprintln(w, "Repository not enabled for review")
+		return
+	}
+	if h.queue.rejectFull(w) {
+		return
+	}
+
+	parse := func(ctx context.Context) (*domain.PullRequest, error) {
+		if pr.IsValid() || h.parser == nil {
+			return pr, nil
+		}
+		extracted, err := h.parser.Parse(ctx, body)
+		if err != nil {
+			return nil, err
+		}
+		extracted.Provider = domain.ProviderGitLab
+		return extracted, nil
+	}
+
+	uniqueKey := fmt.Sprintf("%s/%s/%s/%s", domain.ProviderGitLab, pr.ProjectKey, pr.RepoSlug, pr.ID)
+	if !pr.IsValid() {
+		uniqueKey = fmt.Sprintf("%s/unknown-%d", domain.ProviderGitLab, time.Now().UnixNano())
+	}
+	opened := gjson.GetBytes(body, "object_attributes.action").String() != "update"
+	h.queue.enqueue(uniqueKey, h.queue.withFaults(r, parse), prJob(pr), h.queue.reviewPriority(opened, 0))
+
+	w.WriteHeader(http.StatusOK)
+	fmt.Fprintln(w, "Merge request queued for review")
+}
+
+// reviewableMergeRequestAction reports whether the event opens a merge request or pushes new commits.
+// Updates without oldrev only change metadata (title, labels, assignees).
+func reviewableMergeRequestAction(body []byte) bool {
+	switch gjson.GetBytes(body, "object_attributes.action").String() {
+	case "open", "reopen":
+		return true
+	case "update":
+		return gjson.GetBytes(body, "object_attributes.oldrev").String() != ""
+	default:
+		return false
+	}
+}
+
+// parseGitLabMergeRequest maps a merge request event to a PullRequest.
+// The namespace takes the place of the Bitbucket project key.
+func parseGitLabMergeRequest(body []byte) *domain.PullRequest {
+	if !gjson.ValidBytes(body) {
+		return &domain.PullRequest{Provider: domain.ProviderGitLab}
+	}
+	get := func(path string) string { return gjson.GetBytes(body, path).String() }
+
+	namespace := get("project.path_with_namespace")
+	if i := strings.LastIndex(namespace, "/"); i >= 0 {
+		namespace = namespace[:i]
+	}
+
+	pr := &domain.PullRequest{
+		ID:           get("object_attributes.iid"),
+		ProjectKey:   namespace,
+		RepoSlug:     get("project.path"),
+		Title:        get("object_attributes.title"),
+		Description:  get("object_attributes.description"),
+		Author:       get("user.username"), // The event sends only the author's ID; user is who opened or pushed
+		LatestCommit: get("object_attributes.last_commit.id"),
+		WebURL:       get("object_attributes.url"),
+		SourceBranch: get("object_attributes.source_branch"),
+		TargetBranch: get("object_attributes.target_branch"),
+		Provider:     domain.ProviderGitLab,
+	}
+	if pr.Author != "" {
+		pr.AuthorMention = "@" + pr.Author
+	}
+	gjson.GetBytes(body, "reviewers.#.username").ForEach(func(_, v gjson.Result) bool {
+		pr.ReviewerMentions = append(pr.ReviewerMentions, "@"+v.String())
+		return true
+	})
+	gjson.GetBytes(body, "labels.#.title").ForEach(func(_, v gjson.Result) bool {
+		pr.Labels = append(pr.Labels, v.String())
+		return true
+	})
+	return pr
+}
@@ -0,0 +1,92 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+const gitlabMRPayload = `{
+	"object_kind": "merge_request",
+	"user": {"username": "jdoe"},
+	"project": {"path": "api", "path_with_namespace": "acme/backend/api"},
+	"object_attributes": {
+		"iid": 7,
+		"title": "Add cache",
+		"url": "https://gitlab.com/acme/backend/api/-/merge_requests/7",
+		"action": "%s",
+		"oldrev": "%s",
+		"last_commit": {"id": "abc123"}
+	},
+	"reviewers": [{"username": "rev1"}]
+}`
+
+func TestGitLabWebhookHandler(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.MaxBodySize = 2 * 1024 * 1024
+	cfg.Server.ConcurrencyLimit = 1
+	cfg.Server.QueueSize = 10
+	cfg.Server.DebounceWindow = 10 * time.Millisecond
+	cfg.GitLab.WebhookSecret = "s3cret"
+
+	processed := make(chan *domain.PullRequest, 1)
+	queue := NewBitbucketWebhookHandler(cfg, &MockProcessor{
+		ProcessFunc: func(ctx context.Context, pr *domain.PullRequest) error {
+			processed <- pr
+			return nil
+		},
+	}, createTestParser(t, &MockLLM{}))
+	handler := NewGitLabWebhookHandler(cfg, queue, nil)
+
+	tests := []struct {
+		name       string
+		token      string
+		event      string
+		action     string
+		oldrev     string
+		wantStatus int
+		wantBody   string
+	}{
+		{name: "invalid token", token: "wrong", event: "Merge Request Hook", action: "open", wantStatus: http.StatusUnauthorized},
+		{name: "metadata update", token: "s3cret", event: "Merge Request Hook", action: "update", wantStatus: http.StatusOK, wantBody: "Event ignored\n"},
+		{name: "push hook", token: "s3cret", event: "Push Hook", action: "", wantStatus: http.StatusOK, wantBody: "Event ignored\n"},
+		{name: "new commits", token: "s3cret", event: "Merge Request Hook", action: "update", oldrev: "def456", wantStatus: http.StatusOK, wantBody: "Merge request queued for review\n"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			body := fmt.Sprintf(gitlabMRPayload, tt.action, tt.oldrev)
+			req := httptest.NewRequest(http.MethodPost, "/webhook/gitlab", bytes.NewBufferString(body))
+			req.Header.Set("X-Gitlab-Event", tt.event)
+			req.Header.Set("X-Gitlab-Token", tt.token)
+			w := httptest.NewRecorder()
+			handler.ServeHTTP(w, req)
+
+			if w.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d", w.Code, tt.wantStatus)
+			}
+			if tt.wantBody != "" && w.Body.String() != tt.wantBody {
+				t.Errorf("body = %q, want %q", w.Body.String(), tt.wantBody)
+			}
+		})
+	}
+
+	select {
+	case pr := <-processed:
+		if pr.ID != "7" || pr.ProjectKey != "acme/backend" || pr.RepoSlug != "api" || pr.LatestCommit != "abc123" || pr.Provider != domain.ProviderGitLab {
+			t.Errorf("unexpected pr: %+v", pr)
+		}
+		if pr.AuthorMention != "@jdoe" || len(pr.ReviewerMentions) != 1 || pr.ReviewerMentions[0] != "@rev1" {
+			t.Errorf("mentions = %q %v", pr.AuthorMention, pr.ReviewerMentions)
+		}
+	case <-time.After(time.Second):
+		t.Error("timeout waiting for merge request to be processed")
+	}
+	queue.WaitForCompletion()
+}
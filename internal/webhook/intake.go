@@ -0,0 +1,253 @@
+package webhook
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/storage"
+)
+
+// DefaultRetryAfter is sent with 503 responses when a pause does not set one
+const DefaultRetryAfter = 5 * time.Minute
+
+// QueueFullRetryAfter is sent with 429 responses while the worker pool queue is full
+const QueueFullRetryAfter = time.Minute
+
+const (
+	drainPollInterval = 200 * time.Millisecond
+	snapshotTimeout   = 30 * time.Second // Per pending review, bounds payload parsing
+)
+
+// intake is the maintenance state of the webhook queue
+type intake struct {
+	mu          sync.Mutex
+	paused      bool
+	pausedAt    time.Time
+	retryAfter  time.Duration
+	drained     bool
+	snapshotted int
+	cancel      context.CancelFunc // Stops the drain of the current pause
+	snapshotMu  sync.Mutex         // Held while snapshotting, so a resume restores the whole snapshot
+}
+
+// SetQueueStore enables snapshots of the pending queue when intake is paused
+func (h *BitbucketWebhookHandler) SetQueueStore(store storage.QueueRepository) {
+	h.queueStore = store
+}
+
+// PauseIntake stops accepting webhooks until ResumeIntake. Webhooks get 503 with Retry-After;
+// reviews already running finish, and pending reviews are parked, then saved to storage so a
+// restart during the maintenance window loses nothing.
+func (h *BitbucketWebhookHandler) PauseIntake(retryAfter time.Duration) domain.IntakeStatus {
+	if retryAfter <= 0 {
+		retryAfter = DefaultRetryAfter
+	}
+
+	h.intake.mu.Lock()
+	h.intake.retryAfter = retryAfter
+	if !h.intake.paused {
+		ctx, cancel := context.WithCancel(context.Background())
+		h.intake.paused = true
+		h.intake.pausedAt = time.Now()
+		h.intake.drained = false
+		h.intake.snapshotted = 0
+		h.intake.cancel = cancel
+		go h.drain(ctx)
+		slog.Info("intake paused", "retry_after", retryAfter)
+	}
+	h.intake.mu.Unlock()
+
+	return h.IntakeStatus()
+}
+
+// ResumeIntake accepts webhooks again and queues the saved and parked reviews.
+// At startup it restores the snapshot of a previous maintenance window.
+func (h *BitbucketWebhookHandler) ResumeIntake(ctx context.Context) (int, error) {
+	h.intake.mu.Lock()
+	if h.intake.cancel != nil {
+		h.intake.cancel()
+		h.intake.cancel = nil
+	}
+	wasPaused := h.intake.paused
+	h.intake.paused = false
+	h.intake.drained = false
+	h.intake.mu.Unlock()
+	if wasPaused {
+		slog.Info("intake resumed")
+	}
+
+	h.intake.snapshotMu.Lock()
+	defer h.intake.snapshotMu.Unlock()
+
+	restored := 0
+	var err error
+	if h.queueStore != nil {
+		var queued []*storage.QueuedReview
+		queued, err = h.queueStore.TakeQueuedReviews(ctx)
+		for _, q := range queued {
+			// A payload that arrived after the snapshot is newer
+			if _, ok := h.latestPayloads.Load(q.Key); ok || q.PullRequest == nil {
+				continue
+			}
+			pr := q.PullRequest
+			h.latestPayloads.Store(q.Key, parseFunc(func(ctx context.Context) (*domain.PullRequest, error) {
+				return pr, nil
+			}))
+			restored++
+		}
+		if err != nil {
+			err = fmt.Errorf("restore queued reviews: %w", err)
+		}
+	}
+	if restored > 0 {
+		slog.Info("queued reviews restored", "count", restored)
+	}
+
+	// Parked and restored reviews go through the debouncer again
+	h.latestPayloads.Range(func(key, _ any) bool {
+		uniqueKey := key.(string)
+		h.debouncer.Add(uniqueKey, func() {
+			h.submitJob(uniqueKey)
+		})
+		return true
+	})
+	return restored, err
+}
+
+// IntakeStatus reports the maintenance state of the queue
+func (h *BitbucketWebhookHandler) IntakeStatus() domain.IntakeStatus {
+	pending := 0
+	h.latestPayloads.Range(func(_, _ any) bool {
+		pending++
+		return true
+	})
+
+	h.intake.mu.Lock()
+	defer h.intake.mu.Unlock()
+	status := domain.IntakeStatus{
+		Paused:      h.intake.paused,
+		Drained:     h.intake.drained,
+		InFlight:    h.workerPool.InFlight(),
+		Pending:     pending,
+		Snapshotted: h.intake.snapshotted,
+	}
+	if h.intake.paused {
+		pausedAt := h.intake.pausedAt
+		status.PausedAt = &pausedAt
+		status.RetryAfter = int(h.intake.retryAfter.Seconds())
+	}
+	return status
+}
+
+// paused reports whether intake is paused
+func (h *BitbucketWebhookHandler) paused() bool {
+	h.intake.mu.Lock()
+	defer h.intake.mu.Unlock()
+	return h.intake.paused
+}
+
+// rejectPaused answers 503 with Retry-After while intake is paused
+func (h *BitbucketWebhookHandler) rejectPaused(w http.ResponseWriter) bool {
+	h.intake.mu.Lock()
+	paused, retryAfter := h.intake.paused, h.intake.retryAfter
+	h.intake.mu.Unlock()
+	if !paused {
+		return false
+	}
+	w.Header().Set("Retry-After", strconv.Itoa(int(retryAfter.Seconds())))
+	http.Error(w, "Intake paused for maintenance", http.StatusServiceUnavailable)
+	metrics.WebhookRequests.WithLabelValues("paused").Inc()
+	return true
+}
+
+// rejectFull answers 429 with Retry-After while the worker pool queue is full, so the sender
+// retries the event later instead of its review being dropped when the debouncer submits it.
+// With an external review queue, reviews do not wait in the worker pool.
+func (h *BitbucketWebhookHandler) rejectFull(w http.ResponseWriter) bool {
+	if h.reviewQueue != nil || !h.workerPool.Full() {
+		return false
+	}
+	slog.Warn("worker pool queue full, rejecting webhook")
+	h.tooBusy(w)
+	return true
+}
+
+// tooBusy answers 429 with Retry-After for an event the worker pool has no room for
+func (h *BitbucketWebhookHandler) tooBusy(w http.ResponseWriter) {
+	w.Header().Set("Retry-After", strconv.Itoa(int(QueueFullRetryAfter.Seconds())))
+	http.Error(w, "Too many pending reviews", http.StatusTooManyRequests)
+	metrics.WebhookRequests.WithLabelValues("rejected_full").Inc()
+}
+
+// park puts a review back in the pending set, unless a newer payload arrived meanwhile
+func (h *BitbucketWebhookHandler) park(uniqueKey string, parse parseFunc) {
+	h.latestPayloads.LoadOrStore(uniqueKey, parse)
+}
+
+// drain waits for running jobs to finish, then saves the pending reviews to storage
+func (h *BitbucketWebhookHandler) drain(ctx context.Context) {
+	ticker := time.NewTicker(drainPollInterval)
+	defer ticker.Stop()
+	for h.workerPool.InFlight() > 0 {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+
+	h.intake.snapshotMu.Lock()
+	n := h.snapshot(ctx)
+	h.intake.snapshotMu.Unlock()
+
+	h.intake.mu.Lock()
+	defer h.intake.mu.Unlock()
+	if ctx.Err() != nil {
+		return
+	}
+	h.intake.drained = true
+	h.intake.snapshotted = n
+	slog.Info("intake drained", "snapshotted", n)
+}
+
+// snapshot saves pending reviews to storage and drops them from memory. Reviews whose payload
+// cannot be parsed stay in memory and run after resume.
+func (h *BitbucketWebhookHandler) snapshot(ctx context.Context) int {
+	if h.queueStore == nil {
+		return 0
+	}
+
+	var queued []*storage.QueuedReview
+	h.latestPayloads.Range(func(key, val any) bool {
+		parseCtx, cancel := context.WithTimeout(ctx, snapshotTimeout)
+		pr, err := val.(parseFunc)(parseCtx)
+		cancel()
+		if err != nil || pr == nil || !pr.IsValid() {
+			slog.Warn("pending review kept in memory", "key", key, "error", err)
+			return ctx.Err() == nil
+		}
+		queued = append(queued, &storage.QueuedReview{Key: key.(string), PullRequest: pr})
+		return ctx.Err() == nil
+	})
+	if len(queued) == 0 || ctx.Err() != nil {
+		return 0
+	}
+
+	saveCtx, cancel := context.WithTimeout(ctx, snapshotTimeout)
+	defer cancel()
+	if err := h.queueStore.SaveQueuedReviews(saveCtx, queued); err != nil {
+		slog.Error("save queued reviews failed, keeping them in memory", "count", len(queued), "error", err)
+		return 0
+	}
+	for _, q := range queued {
+		h.latestPayloads.Delete(q.Key)
+	}
+	return len(queued)
+}
@@ -0,0 +1,128 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/storage"
+)
+
+func TestBitbucketWebhookHandler_PauseIntake(t *testing.T) {
+	store, err := storage.NewSQLiteRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer store.Close()
+
+	newHandler := func(debounce time.Duration, processed chan *domain.PullRequest) *BitbucketWebhookHandler {
+		cfg := &config.Config{}
+		cfg.Server.MaxBodySize = 2 * 1024 * 1024
+		cfg.Server.ConcurrencyLimit = 1
+		cfg.Server.QueueSize = 10
+		cfg.Server.DebounceWindow = debounce
+		h := NewBitbucketWebhookHandler(cfg, &MockProcessor{
+			ProcessFunc: func(ctx context.Context, pr *domain.PullRequest) error {
+				processed <- pr
+				return nil
+			},
+		}, createTestParser(t, &MockLLM{}))
+		h.SetQueueStore(store)
+		return h
+	}
+	send := func(h *BitbucketWebhookHandler) *httptest.ResponseRecorder {
+		body := `{"eventKey": "pr:opened", "pullRequest": {"id": 7,
+			"toRef": {"repository": {"slug": "repo", "project": {"key": "PROJ"}}}}}`
+		w := httptest.NewRecorder()
+		h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(body)))
+		return w
+	}
+
+	// The debounce window keeps the review pending until the pause snapshots it
+	processed := make(chan *domain.PullRequest, 1)
+	handler := newHandler(time.Hour, processed)
+	if w := send(handler); w.Code != http.StatusOK {
+		t.Fatalf("expected 200 before pause, got %d", w.Code)
+	}
+	handler.PauseIntake(time.Minute)
+
+	w := send(handler)
+	if w.Code != http.StatusServiceUnavailable || w.Header().Get("Retry-After") != "60" {
+		t.Errorf("expected 503 with Retry-After 60 while paused, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for !handler.IntakeStatus().Drained && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	status := handler.IntakeStatus()
+	if !status.Drained || status.Snapshotted != 1 || status.Pending != 0 || !status.Paused {
+		t.Fatalf("unexpected status after drain: %+v", status)
+	}
+
+	// A restarted server restores the snapshot
+	processed = make(chan *domain.PullRequest, 1)
+	restarted := newHandler(10*time.Millisecond, processed)
+	restored, err := restarted.ResumeIntake(context.Background())
+	if err != nil || restored != 1 {
+		t.Fatalf("ResumeIntake = %d, %v", restored, err)
+	}
+	select {
+	case pr := <-processed:
+		if pr.ID != "7" || pr.ProjectKey != "PROJ" {
+			t.Errorf("unexpected pr: %+v", pr)
+		}
+	case <-time.After(time.Second):
+		t.Error("timeout waiting for restored review to be processed")
+	}
+	restarted.WaitForCompletion()
+}
+
+func TestBitbucketWebhookHandler_QueueFull(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.MaxBodySize = 2 * 1024 * 1024
+	cfg.Server.ConcurrencyLimit = 1
+	cfg.Server.QueueSize = 1
+	cfg.Server.DebounceWindow = time.Hour
+	h := NewBitbucketWebhookHandler(cfg, &MockProcessor{}, createTestParser(t, &MockLLM{}))
+	defer h.WaitForCompletion()
+
+	// One job runs and one waits: the queue has no room left
+	started, release := make(chan struct{}), make(chan struct{})
+	block := func(ctx context.Context) error {
+		started <- struct{}{}
+		<-release
+		return nil
+	}
+	h.workerPool.Submit(block)
+	<-started
+	h.workerPool.Submit(func(ctx context.Context) error { return nil })
+
+	body := `{"eventKey": "pr:opened", "pullRequest": {"id": 7,
+		"fromRef": {"repository": {"slug": "repo", "project": {"key": "PROJ"}}}}}`
+	w := httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(body)))
+	if w.Code != http.StatusTooManyRequests || w.Header().Get("Retry-After") != "60" {
+		t.Errorf("expected 429 with Retry-After 60 while the queue is full, got %d %q", w.Code, w.Header().Get("Retry-After"))
+	}
+	if status := h.IntakeStatus(); status.Pending != 0 {
+		t.Errorf("rejected event left %d pending reviews", status.Pending)
+	}
+
+	close(release)
+	deadline := time.Now().Add(2 * time.Second)
+	for h.workerPool.Full() && time.Now().Before(deadline) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	w = httptest.NewRecorder()
+	h.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(body)))
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 once the queue has room, got %d", w.Code)
+	}
+}
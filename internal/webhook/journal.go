@@ -0,0 +1,127 @@
+package webhook
+
+import (
+	"context"
+	"log/slog"
+	"time"
+
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/storage"
+)
+
+// journalTimeout bounds a journal write on the webhook request path
+const journalTimeout = 5 * time.Second
+
+// SetJobStore journals accepted reviews in store until they finish, so reviews waiting in
+// memory are not lost when the process stops. RestoreJobs queues them again at startup.
+func (h *BitbucketWebhookHandler) SetJobStore(store storage.JobRepository) {
+	h.jobStore = store
+}
+
+// payloadJob is the journal entry of a Bitbucket payload parsed when its review runs
+func payloadJob(body []byte, projectKey, repoSlug, prID string) *storage.QueuedJob {
+	return &storage.QueuedJob{Payload: body, ProjectKey: projectKey, RepoSlug: repoSlug, PRID: prID}
+}
+
+// prJob is the journal entry of a PR parsed on receipt; nil (not journaled) when parsing is
+// left to the LLM parser
+func prJob(pr *domain.PullRequest) *storage.QueuedJob {
+	if !pr.IsValid() {
+		return nil
+	}
+	return &storage.QueuedJob{PullRequest: pr, ProjectKey: pr.ProjectKey, RepoSlug: pr.RepoSlug, PRID: pr.ID}
+}
+
+// journal saves job as the latest accepted review of uniqueKey, replacing older ones
+func (h *BitbucketWebhookHandler) journal(uniqueKey string, job *storage.QueuedJob) {
+	if h.jobStore == nil || job == nil {
+		return
+	}
+	job.Key = uniqueKey
+	job.Seq = time.Now().UnixNano()
+	ctx, cancel := context.WithTimeout(context.Background(), journalTimeout)
+	defer cancel()
+	if err := h.jobStore.SaveJob(ctx, job); err != nil {
+		// The review still runs; only a crash before it finishes would lose it
+		slog.Warn("journal review failed", "pr", uniqueKey, "error", err)
+		return
+	}
+	h.jobSeqs.Store(uniqueKey, job.Seq)
+}
+
+// jobSeq returns the journal version of the latest review of uniqueKey, 0 if not journaled.
+// Read it before taking the payload: a newer payload taken with an older version is merely
+// journaled a little longer than needed.
+func (h *BitbucketWebhookHandler) jobSeq(uniqueKey string) int64 {
+	if v, ok := h.jobSeqs.Load(uniqueKey); ok {
+		return v.(int64)
+	}
+	return 0
+}
+
+// finishJob removes a finished review from the journal unless a newer one replaced it
+func (h *BitbucketWebhookHandler) finishJob(uniqueKey string, seq int64) {
+	if h.jobStore == nil || seq == 0 {
+		return
+	}
+	h.jobSeqs.CompareAndDelete(uniqueKey, seq)
+	ctx, cancel := context.WithTimeout(context.Background(), journalTimeout)
+	defer cancel()
+	if err := h.jobStore.DeleteJob(ctx, uniqueKey, seq); err != nil {
+		slog.Warn("remove journaled review failed", "pr", uniqueKey, "error", err)
+	}
+}
+
+// journaled removes the review from the journal once job ends. Reviews interrupted by a
+// shutdown, or parked by a maintenance pause, stay journaled.
+func (h *BitbucketWebhookHandler) journaled(uniqueKey string, seq int64, job Job) Job {
+	if h.jobStore == nil || seq == 0 {
+		return job
+	}
+	return func(ctx context.Context) error {
+		err := job(ctx)
+		if ctx.Err() == nil && !h.paused() {
+			h.finishJob(uniqueKey, seq)
+		}
+		return err
+	}
+}
+
+// RestoreJobs queues the reviews journaled before the process last stopped. Call it once at
+// startup, before webhooks are accepted.
+func (h *BitbucketWebhookHandler) RestoreJobs(ctx context.Context) (int, error) {
+	if h.jobStore == nil {
+		return 0, nil
+	}
+	jobs, err := h.jobStore.ListJobs(ctx)
+	if err != nil {
+		return 0, err
+	}
+	restored := 0
+	for _, j := range jobs {
+		var parse parseFunc
+		switch {
+		case j.PullRequest != nil:
+			pr := j.PullRequest
+			parse = func(context.Context) (*domain.PullRequest, error) { return pr, nil }
+		case len(j.Payload) > 0:
+			body := j.Payload
+			parse = func(ctx context.Context) (*domain.PullRequest, error) { return h.parser.Parse(ctx, body) }
+		default:
+			continue
+		}
+		if _, loaded := h.latestPayloads.LoadOrStore(j.Key, parse); loaded {
+			continue
+		}
+		h.jobSeqs.Store(j.Key, j.Seq)
+		uniqueKey := j.Key
+		h.debouncer.Add(uniqueKey, func() {
+			h.submitJob(uniqueKey)
+		})
+		restored++
+	}
+	if restored > 0 {
+		slog.Info("journaled reviews restored", "count", restored)
+	}
+	return restored, nil
+}
@@ -0,0 +1,75 @@
+package webhook
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/storage"
+)
+
+func TestBitbucketWebhookHandler_JournaledJobsSurviveRestart(t *testing.T) {
+	store, err := storage.NewSQLiteRepository(filepath.Join(t.TempDir(), "test.db"))
+	if err != nil {
+		t.Fatalf("open store: %v", err)
+	}
+	defer store.Close()
+	ctx := context.Background()
+
+	newHandler := func(debounce time.Duration, processed chan *domain.PullRequest) *BitbucketWebhookHandler {
+		cfg := &config.Config{}
+		cfg.Server.MaxBodySize = 2 * 1024 * 1024
+		cfg.Server.ConcurrencyLimit = 1
+		cfg.Server.QueueSize = 10
+		cfg.Server.DebounceWindow = debounce
+		h := NewBitbucketWebhookHandler(cfg, &MockProcessor{
+			ProcessFunc: func(ctx context.Context, pr *domain.PullRequest) error {
+				processed <- pr
+				return nil
+			},
+		}, createTestParser(t, &MockLLM{}))
+		h.SetJobStore(store)
+		return h
+	}
+
+	// The first instance accepts the webhook and stops before the debounce window ends
+	body := `{"eventKey": "pr:opened", "pullRequest": {"id": 7, "title": "Add retries",
+		"fromRef": {"latestCommit": "abc", "repository": {"slug": "repo", "project": {"key": "PROJ"}}},
+		"toRef": {"repository": {"slug": "repo", "project": {"key": "PROJ"}}}}}`
+	first := newHandler(time.Hour, make(chan *domain.PullRequest, 1))
+	w := httptest.NewRecorder()
+	first.ServeHTTP(w, httptest.NewRequest(http.MethodPost, "/webhook", bytes.NewBufferString(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	jobs, err := store.ListJobs(ctx)
+	if err != nil || len(jobs) != 1 || jobs[0].Key != "PROJ/repo/7" {
+		t.Fatalf("expected the review to be journaled, got %v, %v", jobs, err)
+	}
+
+	// The restarted instance reviews it and clears the journal
+	processed := make(chan *domain.PullRequest, 1)
+	second := newHandler(10*time.Millisecond, processed)
+	defer second.WaitForCompletion()
+	if n, err := second.RestoreJobs(ctx); err != nil || n != 1 {
+		t.Fatalf("RestoreJobs = %d, %v; want 1", n, err)
+	}
+	select {
+	case pr := <-processed:
+		if pr.ID != "7" || pr.Title != "Add retries" {
+			t.Errorf("unexpected restored review: %+v", pr)
+		}
+	case <-time.After(3 * time.Second):
+		t.Fatal("restored review not processed")
+	}
+	waitFor(t, func() bool {
+		jobs, err := store.ListJobs(ctx)
+		return err == nil && len(jobs) == 0
+	})
+}
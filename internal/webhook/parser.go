@@ -3,10 +3,9 @@ package webhook
 import (
 	"context"
 	"encoding/json"
-	"errors"
 	"fmt"
 	"log/slog"
-	"time"
+	"strings"
 
 	"pr-review-automation/internal/config"
 	"pr-review-automation/internal/domain"
@@ -14,6 +13,7 @@ import (
 	"pr-review-automation/internal/llm"
 	"pr-review-automation/internal/metrics"
 	"pr-review-automation/internal/pipeline"
+	"pr-review-automation/internal/retry"
 	"pr-review-automation/internal/types"
 
 	"github.com/tidwall/gjson"
@@ -60,6 +60,11 @@ func (p *PayloadParser) probePayload(body []byte) *domain.PullRequest {
 		return &domain.PullRequest{}
 	}
 
+	// Bitbucket Cloud has its own schema (lowercase "pullrequest", workspace/slug full names)
+	if gjson.GetBytes(body, "pullrequest.id").Exists() {
+		return probeBitbucketCloud(body)
+	}
+
 	// Define candidate paths for each field, prioritized from left to right.
 	pathsProjectKey := []string{
 		"pullRequest.toRef.repository.project.key",   // Bitbucket Server (New)
@@ -126,6 +131,8 @@ func (p *PayloadParser) probePayload(body []byte) *domain.PullRequest {
 		"links.html.href",
 	}
 
+	authorMention, reviewerMentions := probeMentions(body)
+
 	return &domain.PullRequest{
 		ID:           probeID(pathsID),
 		ProjectKey:   probeString(pathsProjectKey),
@@ -135,6 +142,47 @@ func (p *PayloadParser) probePayload(body []byte) *domain.PullRequest {
 		Author:       probeString(pathsAuthor),
 		LatestCommit: probeString(pathsLatestCommit),
 		WebURL:       probeString(pathsWebURL),
+		SourceBranch: probeString([]string{"pullRequest.fromRef.displayId", "fromRef.displayId"}),
+		TargetBranch: probeString([]string{"pullRequest.toRef.displayId", "toRef.displayId"}),
+		Draft:        gjson.GetBytes(body, "pullRequest.draft").Bool(),
+
+		AuthorMention:    authorMention,
+		ReviewerMentions: reviewerMentions,
+	}
+}
+
+// probeMentions resolves mention markup for the author and reviewers.
+// Bitbucket Server mentions by user name (@name, quoted when it contains spaces);
+// Bitbucket Cloud mentions by account ID (@{account_id}).
+func probeMentions(body []byte) (string, []string) {
+	if id := probe(body, []string{"pullrequest.author.account_id", "pullRequest.author.account_id"}).String(); id != "" {
+		var reviewers []string
+		for _, r := range probe(body, []string{"pullrequest.reviewers", "pullRequest.reviewers"}).Array() {
+			if rid := r.Get("account_id").String(); rid != "" {
+				reviewers = append(reviewers, "@{"+rid+"}")
+			}
+		}
+		return "@{" + id + "}", reviewers
+	}
+
+	author := serverMention(probe(body, []string{"pullRequest.author.user.name", "pullRequest.author.user.slug"}).String())
+	var reviewers []string
+	for _, r := range gjson.GetBytes(body, "pullRequest.reviewers").Array() {
+		if m := serverMention(r.Get("user.name").String()); m != "" {
+			reviewers = append(reviewers, m)
+		}
+	}
+	return author, reviewers
+}
+
+func serverMention(name string) string {
+	switch {
+	case name == "":
+		return ""
+	case strings.ContainsAny(name, " @\"\t"):
+		return `@"` + strings.ReplaceAll(name, `"`, "") + `"`
+	default:
+		return "@" + name
 	}
 }
 
@@ -161,49 +209,36 @@ func (p *PayloadParser) askLLMToExtract(ctx context.Context, body []byte) (*doma
 	// 2. Truncate Body
 	truncated := p.truncateForLLM(body)
 
-	// 3. Retry Logic
-	maxRetries := p.cfg.MaxRetries
-	if maxRetries <= 0 {
-		maxRetries = 2
-	}
-	var lastErr error
-
-	for attempt := 0; attempt <= maxRetries; attempt++ {
-		if attempt > 0 {
-			backoff := time.Duration(attempt) * time.Second
-			select {
-			case <-time.After(backoff):
-			case <-ctx.Done():
-				return nil, ctx.Err()
-			}
-			slog.Warn("retrying llm call", "attempt", attempt+1, "max", maxRetries+1)
+	// 3. Retry transient failures and malformed JSON
+	policy := p.cfg.Retry
+	if policy.Attempts <= 0 {
+		maxRetries := p.cfg.MaxRetries
+		if maxRetries <= 0 {
+			maxRetries = 2
 		}
+		policy.Attempts = maxRetries + 1
+	}
 
-		// Execute LLM Call
+	var pr domain.PullRequest
+	err = retry.Do(ctx, "webhook_l2", policy, retry.Transient, func(attempt int) error {
 		respText, err := p.llm.SimpleTextQuery(ctx, sysPrompt, truncated)
-		if err == nil {
-			// Clean up response (sometimes LLMs include markdown blocks)
-			respText = types.CleanJSONFromMarkdown(respText)
-
-			var pr domain.PullRequest
-			if err := json.Unmarshal([]byte(respText), &pr); err != nil {
-				lastErr = fmt.Errorf("unmarshal llm response: %w", err)
-				continue // Retry on malformed JSON
-			}
-			return &pr, nil
+		if err != nil {
+			slog.Warn("llm call failed", "attempt", attempt+1, "error", err)
+			return err
 		}
-
-		lastErr = err
-		slog.Warn("llm call failed", "attempt", attempt+1, "error", err)
-
-		if !p.isRetryableError(err) {
-			slog.Error("llm permanent error", "error", err)
-			break
+		// Clean up response (sometimes LLMs include markdown blocks)
+		respText = types.CleanJSONFromMarkdown(respText)
+		pr = domain.PullRequest{}
+		if err := json.Unmarshal([]byte(respText), &pr); err != nil {
+			return types.NewRetryableError(fmt.Errorf("unmarshal llm response: %w", err))
 		}
+		return nil
+	})
+	if err != nil {
+		metrics.PayloadParseFailures.WithLabelValues("l2").Inc()
+		return nil, fmt.Errorf("l2 extraction failed: %w", err)
 	}
-
-	metrics.PayloadParseFailures.WithLabelValues("l2").Inc()
-	return nil, fmt.Errorf("l2 extraction failed: %w", lastErr)
+	return &pr, nil
 }
 
 func (p *PayloadParser) truncateForLLM(body []byte) string {
@@ -219,22 +254,3 @@ func (p *PayloadParser) truncateForLLM(body []byte) string {
 
 	return string(body)
 }
-
-func (p *PayloadParser) isRetryableError(err error) bool {
-	if err == nil {
-		return false
-	}
-
-	// Check for explicit RetryableError (from adapter)
-	var retryErr *types.RetryableError
-	if errors.As(err, &retryErr) {
-		return true
-	}
-
-	// Check for standard context errors
-	if errors.Is(err, context.DeadlineExceeded) || errors.Is(err, context.Canceled) {
-		return true
-	}
-
-	return false
-}
@@ -0,0 +1,96 @@
+package webhook
+
+import (
+	"time"
+
+	"pr-review-automation/internal/domain"
+)
+
+// Priority orders the jobs waiting in the worker pool (server.priorities)
+type Priority int
+
+// Priorities, lowest first
+const (
+	PriorityLow    Priority = iota // Large PRs, skip notes
+	PriorityNormal                 // New commits, merge follow-ups
+	PriorityHigh                   // Newly opened PRs
+	PriorityManual                 // Requested reviews, slash commands and replies
+)
+
+// String returns the metric label of the priority
+func (p Priority) String() string {
+	switch p {
+	case PriorityLow:
+		return "low"
+	case PriorityHigh:
+		return "high"
+	case PriorityManual:
+		return "manual"
+	default:
+		return "normal"
+	}
+}
+
+// queuedJob is a job waiting in the worker pool
+type queuedJob struct {
+	job      Job
+	priority Priority
+	queuedAt time.Time
+	order    time.Time // queuedAt, moved earlier by the priority's head start
+	seq      uint64    // Arrival order among jobs with the same order
+}
+
+// jobQueue is a heap of waiting jobs, earliest order first
+type jobQueue []*queuedJob
+
+func (q jobQueue) Len() int { return len(q) }
+
+func (q jobQueue) Less(i, j int) bool {
+	if !q[i].order.Equal(q[j].order) {
+		return q[i].order.Before(q[j].order)
+	}
+	return q[i].seq < q[j].seq
+}
+
+func (q jobQueue) Swap(i, j int) { q[i], q[j] = q[j], q[i] }
+
+func (q *jobQueue) Push(x any) { *q = append(*q, x.(*queuedJob)) }
+
+func (q *jobQueue) Pop() any {
+	old := *q
+	n := len(old)
+	item := old[n-1]
+	old[n-1] = nil
+	*q = old[:n-1]
+	return item
+}
+
+// reviewPriority returns the priority of the review of a PR: opened tells whether the event
+// opened it (or took it out of draft), changedLines is its size when the payload has it, else 0
+func (h *BitbucketWebhookHandler) reviewPriority(opened bool, changedLines int) Priority {
+	if large := h.config.Server.Priorities.LargePRLines; large > 0 && changedLines > large {
+		return PriorityLow
+	}
+	if opened {
+		return PriorityHigh
+	}
+	return PriorityNormal
+}
+
+// queuedPriority returns the priority of a review known only by its PR: requested reviews
+// (Overrides set) are manual, others normal
+func queuedPriority(pr *domain.PullRequest) Priority {
+	if pr.Overrides != nil {
+		return PriorityManual
+	}
+	return PriorityNormal
+}
+
+// takePriority returns and forgets the priority of the latest payload of a PR, normal for
+// payloads restored from a snapshot or the journal
+func (h *BitbucketWebhookHandler) takePriority(uniqueKey string) Priority {
+	if v, ok := h.priorities.LoadAndDelete(uniqueKey); ok {
+		return v.(Priority)
+	}
+	return PriorityNormal
+}
@@ -0,0 +1,99 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+// runOrder queues jobs behind a busy worker and returns the order they ran in
+func runOrder(t *testing.T, boost time.Duration, priorities []Priority, gap time.Duration) []Priority {
+	t.Helper()
+	p := NewWorkerPool(1, len(priorities)+1)
+	p.SetPriorities(config.PriorityConfig{Enabled: boost > 0, Boost: boost})
+	p.Start()
+
+	started, release := make(chan struct{}), make(chan struct{})
+	p.Submit(func(ctx context.Context) error {
+		close(started)
+		<-release
+		return nil
+	})
+	<-started
+
+	ran := make(chan Priority, len(priorities))
+	for _, priority := range priorities {
+		if err := p.SubmitPriority(func(ctx context.Context) error {
+			ran <- priority
+			return nil
+		}, priority); err != nil {
+			t.Fatal(err)
+		}
+		time.Sleep(gap)
+	}
+	close(release)
+	p.Stop()
+	close(ran)
+
+	var order []Priority
+	for priority := range ran {
+		order = append(order, priority)
+	}
+	return order
+}
+
+func TestWorkerPool_Priorities(t *testing.T) {
+	queued := []Priority{PriorityLow, PriorityNormal, PriorityManual, PriorityNormal, PriorityHigh}
+	tests := []struct {
+		name  string
+		boost time.Duration
+		gap   time.Duration
+		want  []Priority
+	}{
+		{"arrival order without priorities", 0, 0, queued},
+		{"highest priority first", time.Hour, 0, []Priority{PriorityManual, PriorityHigh, PriorityNormal, PriorityNormal, PriorityLow}},
+		{"long waits beat the head start", time.Millisecond, 10 * time.Millisecond, queued},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := runOrder(t, tt.boost, queued, tt.gap)
+			if len(got) != len(tt.want) {
+				t.Fatalf("ran %v, want %v", got, tt.want)
+			}
+			for i := range got {
+				if got[i] != tt.want[i] {
+					t.Fatalf("ran %v, want %v", got, tt.want)
+				}
+			}
+		})
+	}
+}
+
+func TestReviewPriority(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.Priorities = config.PriorityConfig{Enabled: true, Boost: time.Minute, LargePRLines: 1000}
+	h := &BitbucketWebhookHandler{config: cfg}
+
+	tests := []struct {
+		opened  bool
+		changed int
+		want    Priority
+	}{
+		{true, 0, PriorityHigh},
+		{false, 0, PriorityNormal},
+		{true, 500, PriorityHigh},
+		{true, 5000, PriorityLow},
+		{false, 5000, PriorityLow},
+	}
+	for _, tt := range tests {
+		if got := h.reviewPriority(tt.opened, tt.changed); got != tt.want {
+			t.Errorf("reviewPriority(%v, %d) = %v, want %v", tt.opened, tt.changed, got, tt.want)
+		}
+	}
+	if got := queuedPriority(&domain.PullRequest{Overrides: &domain.ReviewOverrides{}}); got != PriorityManual {
+		t.Errorf("requested review has priority %v", got)
+	}
+}
@@ -0,0 +1,180 @@
+package webhook
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/metrics"
+	"pr-review-automation/internal/queue"
+	"pr-review-automation/internal/storage"
+)
+
+const (
+	popTimeout      = time.Second     // Bounds how long the consumer takes to notice a shutdown
+	queueRetryDelay = 5 * time.Second // After a failed pop
+)
+
+// ReviewQueue keeps queued reviews outside the process (see queue.Queue)
+type ReviewQueue interface {
+	Push(ctx context.Context, review *storage.QueuedReview) error
+	Pop(ctx context.Context, timeout time.Duration) (*queue.Delivery, error)
+	Ack(ctx context.Context, d *queue.Delivery) error
+	Recover(ctx context.Context) (int, error)
+}
+
+// consumer is the loop taking reviews from the external queue
+type consumer struct {
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// SetReviewQueue queues debounced reviews in q instead of the worker pool and starts consuming
+// it: this instance takes a review whenever one of its workers is free, so several instances
+// share the queue. Reviews the instance was running when it last stopped are queued again.
+// An instance with queue role ingest only queues reviews.
+func (h *BitbucketWebhookHandler) SetReviewQueue(q ReviewQueue) {
+	h.reviewQueue = q
+	if h.config.Queue.Role == config.QueueRoleIngest {
+		return
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	h.consumer = consumer{cancel: cancel, done: make(chan struct{})}
+
+	if n, err := q.Recover(ctx); err != nil {
+		slog.Error("recover interrupted reviews failed", "error", err)
+	} else if n > 0 {
+		slog.Info("interrupted reviews queued again", "count", n)
+	}
+
+	slots := int(h.config.Server.ConcurrencyLimit)
+	if a := h.config.Server.Autoscale; a.Enabled {
+		slots = a.MaxWorkers
+	}
+	go h.consume(ctx, max(slots, 1))
+}
+
+// stopConsuming stops taking reviews from the external queue; reviews already taken still run
+func (h *BitbucketWebhookHandler) stopConsuming() {
+	if h.consumer.cancel == nil {
+		return
+	}
+	h.consumer.cancel()
+	<-h.consumer.done
+}
+
+// pushReview parses a debounced payload and queues its review. Only the parsed pull request
+// can be queued, so parsing happens here rather than in the worker.
+func (h *BitbucketWebhookHandler) pushReview(uniqueKey string, seq int64, parse parseFunc, priority Priority) {
+	ctx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+	defer cancel()
+
+	pr, err := parse(ctx)
+	if err != nil {
+		slog.Error("payload parse failed", "error", err)
+		metrics.PayloadParseFailures.WithLabelValues("both").Inc()
+		h.finishJob(uniqueKey, seq)
+		return
+	}
+	if !pr.IsValid() {
+		slog.Error("parsed pr invalid", "pr", pr)
+		metrics.WebhookRequests.WithLabelValues("invalid_payload").Inc()
+		h.finishJob(uniqueKey, seq)
+		return
+	}
+	parsed := func(context.Context) (*domain.PullRequest, error) { return pr, nil }
+
+	if err := h.reviewQueue.Push(ctx, &storage.QueuedReview{Key: uniqueKey, PullRequest: pr}); err != nil {
+		// The review must not be lost while the queue is unavailable
+		slog.Warn("queue review failed, running it on this instance", "pr", uniqueKey, "error", err)
+		if err := h.workerPool.SubmitPriority(h.journaled(uniqueKey, seq, h.reviewJob(uniqueKey, parsed)), priority); err != nil {
+			slog.Warn("worker pool queue full, dropping request", "pr", uniqueKey)
+			metrics.WebhookRequests.WithLabelValues("dropped_full").Inc()
+			h.finishJob(uniqueKey, seq)
+		}
+		return
+	}
+	// The external queue holds the review from here on
+	h.finishJob(uniqueKey, seq)
+	h.supersede(uniqueKey)
+}
+
+// consume hands reviews from the external queue to the worker pool, at most slots at a time
+func (h *BitbucketWebhookHandler) consume(ctx context.Context, slots int) {
+	defer close(h.consumer.done)
+	sem := make(chan struct{}, slots)
+	for {
+		select {
+		case sem <- struct{}{}:
+		case <-ctx.Done():
+			return
+		}
+		d := h.pop(ctx)
+		if d == nil {
+			return
+		}
+
+		var once sync.Once
+		finish := func() {
+			once.Do(func() {
+				ackCtx, cancel := context.WithTimeout(context.Background(), snapshotTimeout)
+				defer cancel()
+				if err := h.reviewQueue.Ack(ackCtx, d); err != nil {
+					slog.Warn("acknowledge review failed", "pr", d.Review.Key, "error", err)
+				}
+				<-sem
+			})
+		}
+		pr := d.Review.PullRequest
+		if pr == nil {
+			finish()
+			continue
+		}
+		job := h.reviewJob(d.Review.Key, func(context.Context) (*domain.PullRequest, error) { return pr, nil })
+		if err := h.workerPool.SubmitPriority(func(ctx context.Context) error {
+			defer finish()
+			return job(ctx)
+		}, queuedPriority(pr)); err != nil {
+			// Leave the review to another worker or instance
+			slog.Warn("worker pool queue full, returning review to the queue", "pr", d.Review.Key)
+			if err := h.reviewQueue.Push(ctx, d.Review); err != nil {
+				slog.Error("return review to the queue failed", "pr", d.Review.Key, "error", err)
+			}
+			finish()
+		}
+	}
+}
+
+// pop waits for the next review while intake is not paused. It returns nil once ctx is done.
+func (h *BitbucketWebhookHandler) pop(ctx context.Context) *queue.Delivery {
+	for ctx.Err() == nil {
+		if h.paused() {
+			sleepCtx(ctx, drainPollInterval)
+			continue
+		}
+		d, err := h.reviewQueue.Pop(ctx, popTimeout)
+		if err != nil {
+			if ctx.Err() == nil {
+				slog.Warn("take review from queue failed", "error", err)
+				sleepCtx(ctx, queueRetryDelay)
+			}
+			continue
+		}
+		if d != nil {
+			return d
+		}
+	}
+	return nil
+}
+
+func sleepCtx(ctx context.Context, d time.Duration) {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+	case <-timer.C:
+	}
+}
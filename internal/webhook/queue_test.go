@@ -0,0 +1,73 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+	"pr-review-automation/internal/queue"
+
+	"github.com/alicebob/miniredis/v2"
+)
+
+func TestBitbucketWebhookHandler_RedisQueue(t *testing.T) {
+	mr := miniredis.RunT(t)
+	redisCfg := config.RedisQueueConfig{Addr: mr.Addr(), Key: "test:queue"}
+
+	newInstance := func(consumer string, processed chan string) *BitbucketWebhookHandler {
+		cfg := &config.Config{}
+		cfg.Server.ConcurrencyLimit = 1
+		cfg.Server.QueueSize = 10
+		cfg.Server.DebounceWindow = 10 * time.Millisecond
+		h := NewBitbucketWebhookHandler(cfg, &MockProcessor{
+			ProcessFunc: func(ctx context.Context, pr *domain.PullRequest) error {
+				processed <- consumer + ":" + pr.ID
+				return nil
+			},
+		}, createTestParser(t, &MockLLM{}))
+		rc := redisCfg
+		rc.Consumer = consumer
+		q, err := queue.NewRedis(context.Background(), rc)
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { q.Close() })
+		h.SetReviewQueue(q)
+		return h
+	}
+
+	// A review left in the processing list of a stopped instance runs when it restarts
+	mr.Lpush("test:queue:processing:a", `{"key":"PROJ/repo/1","pullRequest":{"ID":"1","ProjectKey":"PROJ","RepoSlug":"repo"}}`)
+
+	processed := make(chan string, 4)
+	a := newInstance("a", processed)
+	defer a.WaitForCompletion()
+	// Reviews submitted to instance a go through Redis
+	a.SubmitReview(&domain.PullRequest{ID: "2", ProjectKey: "PROJ", RepoSlug: "repo"})
+
+	got := map[string]bool{}
+	for i := 0; i < 2; i++ {
+		select {
+		case r := <-processed:
+			got[r] = true
+		case <-time.After(3 * time.Second):
+			t.Fatalf("reviews processed: %v", got)
+		}
+	}
+	if !got["a:1"] || !got["a:2"] {
+		t.Errorf("reviews processed: %v", got)
+	}
+
+	deadline := time.Now().Add(2 * time.Second)
+	for time.Now().Before(deadline) {
+		if items, _ := mr.List("test:queue:processing:a"); len(items) == 0 {
+			break
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	if items, _ := mr.List("test:queue:processing:a"); len(items) != 0 {
+		t.Errorf("finished reviews not acknowledged: %v", items)
+	}
+}
@@ -0,0 +1,197 @@
+package webhook
+
+import (
+	"log/slog"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/metrics"
+)
+
+// Scopes of a review rate limit
+const (
+	rateScopeProject = "project"
+	rateScopeRepo    = "repo"
+)
+
+// bucket is the token bucket of one project or repository
+type bucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// tokenBuckets are the buckets of one scope, created full on first use. A bucket idle for its
+// refill time is full again and is dropped, so keys seen once do not stay in memory.
+type tokenBuckets struct {
+	perSecond float64
+	burst     float64
+	refill    time.Duration // Time an empty bucket takes to fill up
+	buckets   map[string]*bucket
+	swept     time.Time
+}
+
+func newTokenBuckets(limit config.RateLimit) *tokenBuckets {
+	if limit.PerHour <= 0 {
+		return nil
+	}
+	perSecond := limit.PerHour / time.Hour.Seconds()
+	burst := float64(max(limit.Burst, 1))
+	return &tokenBuckets{
+		perSecond: perSecond,
+		burst:     burst,
+		refill:    time.Duration(math.Ceil(burst / perSecond * float64(time.Second))),
+		buckets:   make(map[string]*bucket),
+	}
+}
+
+// wait refills the bucket of key and returns how long until it holds a token, 0 if it does
+func (t *tokenBuckets) wait(key string, now time.Time) time.Duration {
+	if t == nil {
+		return 0
+	}
+	t.evict(now)
+	b, ok := t.buckets[key]
+	if !ok {
+		b = &bucket{tokens: t.burst, last: now}
+		t.buckets[key] = b
+	}
+	b.tokens = min(t.burst, b.tokens+now.Sub(b.last).Seconds()*t.perSecond)
+	b.last = now
+	if b.tokens >= 1 {
+		return 0
+	}
+	return time.Duration(math.Ceil((1 - b.tokens) / t.perSecond * float64(time.Second)))
+}
+
+// evict drops the buckets idle for their refill time, at most once per refill time. They are
+// full, like a bucket created on the next use.
+func (t *tokenBuckets) evict(now time.Time) {
+	if now.Sub(t.swept) < t.refill {
+		return
+	}
+	t.swept = now
+	for key, b := range t.buckets {
+		if now.Sub(b.last) >= t.refill {
+			delete(t.buckets, key)
+		}
+	}
+}
+
+// take removes a token from the bucket of key, after wait returned 0
+func (t *tokenBuckets) take(key string) {
+	if t != nil {
+		t.buckets[key].tokens--
+	}
+}
+
+// rateLimiter limits how often reviews start per project and per repository (server.rate_limit)
+type rateLimiter struct {
+	mu      sync.Mutex
+	project *tokenBuckets
+	repo    *tokenBuckets
+	retries map[string]*time.Timer // PR key -> scheduled retry of its deferred review
+	stopped bool
+	now     func() time.Time
+}
+
+func newRateLimiter(cfg config.RateLimitConfig) *rateLimiter {
+	if !cfg.Enabled {
+		return nil
+	}
+	return &rateLimiter{
+		project: newTokenBuckets(cfg.Project),
+		repo:    newTokenBuckets(cfg.Repo),
+		retries: make(map[string]*time.Timer),
+		now:     time.Now,
+	}
+}
+
+// reserve takes a token from the project and repository buckets of the PR key when both have
+// one. Otherwise it takes none and returns the scope that is out of tokens and how long until
+// it has one. Keys without a repository, such as those of unidentified payloads, are not limited.
+func (l *rateLimiter) reserve(uniqueKey string) (string, time.Duration) {
+	if l == nil {
+		return "", 0
+	}
+	project, repo, ok := rateKeys(uniqueKey)
+	if !ok {
+		return "", 0
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	now := l.now()
+	if wait := l.project.wait(project, now); wait > 0 {
+		return rateScopeProject, wait
+	}
+	if wait := l.repo.wait(repo, now); wait > 0 {
+		return rateScopeRepo, wait
+	}
+	l.project.take(project)
+	l.repo.take(repo)
+	return "", 0
+}
+
+// retry schedules fn after wait, once per PR key: it reports false when a retry of uniqueKey is
+// already scheduled or the limiter is stopped
+func (l *rateLimiter) retry(uniqueKey string, wait time.Duration, fn func()) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, ok := l.retries[uniqueKey]; ok || l.stopped {
+		return false
+	}
+	l.retries[uniqueKey] = time.AfterFunc(wait, func() {
+		l.mu.Lock()
+		delete(l.retries, uniqueKey)
+		l.mu.Unlock()
+		fn()
+	})
+	return true
+}
+
+// stop cancels the scheduled retries and schedules no new ones; the deferred payloads stay
+// queued
+func (l *rateLimiter) stop() {
+	if l == nil {
+		return
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.stopped = true
+	for key, timer := range l.retries {
+		timer.Stop()
+		delete(l.retries, key)
+	}
+}
+
+// rateKeys splits a PR key ("PROJ/repo/1", or "github/org/app/7" on other providers) into the
+// keys of its project and repository
+func rateKeys(uniqueKey string) (project, repo string, ok bool) {
+	i := strings.LastIndex(uniqueKey, "/")
+	if i < 0 {
+		return "", "", false
+	}
+	repo = uniqueKey[:i]
+	j := strings.LastIndex(repo, "/")
+	if j < 0 {
+		return "", "", false
+	}
+	return repo[:j], repo, true
+}
+
+// rateLimited defers the review of uniqueKey while its project or repository is over
+// server.rate_limit, so the reviews of other repositories go first. The latest payload stays
+// queued; one retry per PR is scheduled for when a token is free.
+func (h *BitbucketWebhookHandler) rateLimited(uniqueKey string) bool {
+	scope, wait := h.limiter.reserve(uniqueKey)
+	if wait == 0 {
+		return false
+	}
+	if h.limiter.retry(uniqueKey, wait, func() { h.submitJob(uniqueKey) }) {
+		metrics.ReviewsRateLimited.WithLabelValues(scope).Inc()
+		slog.Info("review rate limited", "pr", uniqueKey, "scope", scope, "wait", wait)
+	}
+	return true
+}
@@ -0,0 +1,149 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+func TestRateLimiter(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newRateLimiter(config.RateLimitConfig{
+		Enabled: true,
+		Project: config.RateLimit{PerHour: 6, Burst: 3},
+		Repo:    config.RateLimit{PerHour: 60, Burst: 2},
+	})
+	l.now = func() time.Time { return now }
+
+	for i := range 2 {
+		if scope, wait := l.reserve("PAY/api/1"); wait != 0 {
+			t.Fatalf("review %d: %s limited for %v", i, scope, wait)
+		}
+	}
+	if scope, wait := l.reserve("PAY/api/2"); scope != rateScopeRepo || wait != time.Minute {
+		t.Errorf("third review of the repo: %s, %v", scope, wait)
+	}
+	if _, wait := l.reserve("PAY/web/3"); wait != 0 {
+		t.Errorf("other repository limited for %v", wait)
+	}
+	if scope, wait := l.reserve("PAY/web/4"); scope != rateScopeProject || wait != 10*time.Minute {
+		t.Errorf("fourth review of the project: %s, %v", scope, wait)
+	}
+	if _, wait := l.reserve("github/org/app/5"); wait != 0 {
+		t.Errorf("other project limited for %v", wait)
+	}
+	if _, wait := l.reserve("unknown-1"); wait != 0 {
+		t.Errorf("unidentified payload limited for %v", wait)
+	}
+
+	// A limited review took no token, so the repository has one after a minute
+	now = now.Add(time.Minute)
+	if scope, wait := l.reserve("PAY/api/2"); scope != rateScopeProject {
+		t.Errorf("after a minute: %s, %v; the project bucket still needs %v", scope, wait, 9*time.Minute)
+	}
+	now = now.Add(9 * time.Minute)
+	if _, wait := l.reserve("PAY/api/2"); wait != 0 {
+		t.Errorf("after ten minutes: limited for %v", wait)
+	}
+
+	if newRateLimiter(config.RateLimitConfig{}) != nil {
+		t.Error("disabled rate limit created a limiter")
+	}
+}
+
+func TestRateLimiter_EvictsIdleBuckets(t *testing.T) {
+	now := time.Date(2026, 1, 1, 12, 0, 0, 0, time.UTC)
+	l := newRateLimiter(config.RateLimitConfig{Enabled: true, Repo: config.RateLimit{PerHour: 60, Burst: 2}})
+	l.now = func() time.Time { return now }
+
+	l.reserve("PAY/api/1")
+	l.reserve("PAY/api/2")
+	now = now.Add(time.Minute)
+	l.reserve("OPS/infra/3")
+	if len(l.repo.buckets) != 2 {
+		t.Fatalf("buckets before the refill time: %d, want 2", len(l.repo.buckets))
+	}
+
+	// PAY/api refilled its 2 tokens after 2 minutes; OPS/infra has not
+	now = now.Add(time.Minute)
+	l.reserve("OPS/infra/3")
+	if _, ok := l.repo.buckets["PAY/api"]; ok || len(l.repo.buckets) != 1 {
+		t.Errorf("idle full bucket kept: %v", l.repo.buckets)
+	}
+	if scope, wait := l.reserve("PAY/api/4"); wait != 0 {
+		t.Errorf("evicted bucket not full again: %s limited for %v", scope, wait)
+	}
+}
+
+func TestRateLimiter_Stop(t *testing.T) {
+	l := newRateLimiter(config.RateLimitConfig{Enabled: true, Repo: config.RateLimit{PerHour: 60}})
+	fired := make(chan string, 2)
+	if !l.retry("PAY/api/1", 20*time.Millisecond, func() { fired <- "PAY/api/1" }) {
+		t.Fatal("first retry not scheduled")
+	}
+	if l.retry("PAY/api/1", time.Millisecond, func() { fired <- "second" }) {
+		t.Error("second retry of the PR scheduled")
+	}
+
+	l.stop()
+	if l.retry("PAY/api/2", time.Millisecond, func() { fired <- "PAY/api/2" }) {
+		t.Error("retry scheduled after stop")
+	}
+	select {
+	case key := <-fired:
+		t.Errorf("retry of %s ran after stop", key)
+	case <-time.After(50 * time.Millisecond):
+	}
+	if len(l.retries) != 0 {
+		t.Errorf("retries kept after stop: %v", l.retries)
+	}
+}
+
+func TestRateKeys(t *testing.T) {
+	for key, want := range map[string][2]string{
+		"PAY/api/1":        {"PAY", "PAY/api"},
+		"github/org/app/7": {"github/org", "github/org/app"},
+	} {
+		project, repo, ok := rateKeys(key)
+		if !ok || project != want[0] || repo != want[1] {
+			t.Errorf("rateKeys(%q) = %q, %q, %v", key, project, repo, ok)
+		}
+	}
+	if _, _, ok := rateKeys("unknown-1"); ok {
+		t.Error("unidentified key split")
+	}
+}
+
+func TestBitbucketWebhookHandler_RateLimit(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.ConcurrencyLimit = 2
+	cfg.Server.QueueSize = 10
+	cfg.Server.DebounceWindow = 10 * time.Millisecond
+	cfg.Server.RateLimit = config.RateLimitConfig{Enabled: true, Repo: config.RateLimit{PerHour: 36000, Burst: 1}}
+
+	started := make(chan string, 3)
+	proc := &MockProcessor{ProcessFunc: func(ctx context.Context, pr *domain.PullRequest) error {
+		started <- pr.ProjectKey + "/" + pr.RepoSlug + "/" + pr.ID
+		return nil
+	}}
+	h := NewBitbucketWebhookHandler(cfg, proc, createTestParser(t, &MockLLM{}))
+	defer h.WaitForCompletion()
+
+	h.SubmitReview(&domain.PullRequest{ID: "1", ProjectKey: "PAY", RepoSlug: "api"})
+	if got := receive(t, started, "first review"); got != "PAY/api/1" {
+		t.Fatalf("started %q", got)
+	}
+	// The second review of the repository waits for a token; another repository does not
+	h.SubmitReview(&domain.PullRequest{ID: "2", ProjectKey: "PAY", RepoSlug: "api"})
+	time.Sleep(30 * time.Millisecond)
+	h.SubmitReview(&domain.PullRequest{ID: "3", ProjectKey: "OPS", RepoSlug: "infra"})
+	if got := receive(t, started, "review of another repository"); got != "OPS/infra/3" {
+		t.Fatalf("started %q, want OPS/infra/3 before the rate limited review", got)
+	}
+	if got := receive(t, started, "rate limited review"); got != "PAY/api/2" {
+		t.Errorf("started %q", got)
+	}
+}
@@ -0,0 +1,112 @@
+package webhook
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"slices"
+
+	"pr-review-automation/internal/metrics"
+)
+
+// Cancellation causes of reviews stopped before they finished
+var (
+	errSuperseded = errors.New("review superseded by a newer event")
+	errCancelled  = errors.New("review cancelled through the API")
+)
+
+// runningReview is a review in progress; a newer event for its PR cancels it
+type runningReview struct {
+	ctx    context.Context
+	cancel context.CancelCauseFunc
+}
+
+// trackReview registers the review of the PR key running under ctx. The returned func must be
+// called when the review ends.
+func (h *BitbucketWebhookHandler) trackReview(ctx context.Context, key string) (context.Context, func()) {
+	ctx, cancel := context.WithCancelCause(ctx)
+	r := &runningReview{ctx: ctx, cancel: cancel}
+	h.running.Store(key, r)
+	finish := func() {}
+	if h.progress != nil {
+		ctx, finish = h.progress.Start(ctx, key)
+	}
+	return ctx, func() {
+		h.running.CompareAndDelete(key, r)
+		finish() // Before cancel, so the done event tells why a stopped review ended
+		cancel(nil)
+	}
+}
+
+// supersede cancels the running review of the PR key once a review of a newer event is queued.
+// The old review would only post findings for outdated commits, and it holds the PR lock the
+// new one waits for.
+func (h *BitbucketWebhookHandler) supersede(key string) {
+	v, ok := h.running.Load(key)
+	if !ok {
+		return
+	}
+	r := v.(*runningReview)
+	if r.ctx.Err() != nil {
+		return
+	}
+	slog.Info("cancelling superseded review", "pr", key)
+	metrics.ReviewsCancelled.WithLabelValues("superseded").Inc()
+	r.cancel(errSuperseded)
+}
+
+// stopped returns why the review running under ctx was stopped early, nil unless it was
+// superseded or cancelled through the API
+func stopped(ctx context.Context) error {
+	if cause := context.Cause(ctx); errors.Is(cause, errSuperseded) || errors.Is(cause, errCancelled) {
+		return cause
+	}
+	return nil
+}
+
+// RunningReviews returns the keys of the PRs under review, e.g. "PROJ/repo/42", sorted
+func (h *BitbucketWebhookHandler) RunningReviews() []string {
+	var keys []string
+	h.running.Range(func(k, v any) bool {
+		if v.(*runningReview).ctx.Err() == nil {
+			keys = append(keys, k.(string))
+		}
+		return true
+	})
+	slices.Sort(keys)
+	return keys
+}
+
+// CancelReview stops the review of the PR key: the running review is cancelled and an event
+// still waiting in the debouncer is dropped. Reviews already queued for a worker still run.
+// It reports whether there was anything to stop.
+func (h *BitbucketWebhookHandler) CancelReview(key string) bool {
+	found := false
+	if _, ok := h.latestPayloads.LoadAndDelete(key); ok {
+		h.finishJob(key, h.jobSeq(key))
+		found = true
+	}
+	if v, ok := h.running.Load(key); ok {
+		if r := v.(*runningReview); r.ctx.Err() == nil {
+			metrics.ReviewsCancelled.WithLabelValues("api").Inc()
+			r.cancel(errCancelled)
+			found = true
+		}
+	}
+	if found {
+		slog.Info("review cancelled", "pr", key)
+	}
+	return found
+}
+
+// CancelInFlight cancels the running and queued reviews, for a shutdown that cannot wait for
+// them to finish. Cancelled reviews release their workers within seconds.
+func (h *BitbucketWebhookHandler) CancelInFlight() {
+	h.running.Range(func(_, v any) bool {
+		if v.(*runningReview).ctx.Err() == nil {
+			metrics.ReviewsCancelled.WithLabelValues("shutdown").Inc()
+		}
+		return true
+	})
+	h.workerPool.Cancel()
+}
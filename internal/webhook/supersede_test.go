@@ -0,0 +1,125 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+// blockingProcessor holds every review until its context is done, except for commit "fast"
+type blockingProcessor struct {
+	started  chan string
+	released chan string
+}
+
+func (p *blockingProcessor) ProcessPullRequest(ctx context.Context, pr *domain.PullRequest) error {
+	p.started <- pr.LatestCommit
+	if pr.LatestCommit != "fast" {
+		<-ctx.Done()
+	}
+	p.released <- pr.LatestCommit
+	return ctx.Err()
+}
+
+func newCancelTestHandler(t *testing.T) (*BitbucketWebhookHandler, *blockingProcessor) {
+	t.Helper()
+	cfg := &config.Config{}
+	cfg.Server.ConcurrencyLimit = 2
+	cfg.Server.QueueSize = 10
+	cfg.Server.DebounceWindow = 10 * time.Millisecond
+	proc := &blockingProcessor{started: make(chan string, 4), released: make(chan string, 4)}
+	return NewBitbucketWebhookHandler(cfg, proc, createTestParser(t, &MockLLM{})), proc
+}
+
+func receive(t *testing.T, ch chan string, what string) string {
+	t.Helper()
+	select {
+	case v := <-ch:
+		return v
+	case <-time.After(3 * time.Second):
+		t.Fatalf("timed out waiting for %s", what)
+		return ""
+	}
+}
+
+func TestBitbucketWebhookHandler_SupersededReviewCancelled(t *testing.T) {
+	h, proc := newCancelTestHandler(t)
+	defer h.WaitForCompletion()
+
+	pr := func(commit string) *domain.PullRequest {
+		return &domain.PullRequest{ID: "7", ProjectKey: "PROJ", RepoSlug: "repo", LatestCommit: commit}
+	}
+	h.SubmitReview(pr("old"))
+	if got := receive(t, proc.started, "first review"); got != "old" {
+		t.Fatalf("started %q, want old", got)
+	}
+
+	start := time.Now()
+	h.SubmitReview(pr("fast"))
+	if got := receive(t, proc.released, "superseded review"); got != "old" {
+		t.Fatalf("released %q first, want old", got)
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("superseded review released its worker after %v", elapsed)
+	}
+	if got := receive(t, proc.started, "newer review"); got != "fast" {
+		t.Errorf("started %q, want fast", got)
+	}
+	receive(t, proc.released, "newer review")
+}
+
+func TestBitbucketWebhookHandler_CancelReview(t *testing.T) {
+	h, proc := newCancelTestHandler(t)
+	defer h.WaitForCompletion()
+
+	h.SubmitReview(&domain.PullRequest{ID: "3", ProjectKey: "PROJ", RepoSlug: "repo", LatestCommit: "a"})
+	receive(t, proc.started, "review")
+	if got := h.RunningReviews(); len(got) != 1 || got[0] != "PROJ/repo/3" {
+		t.Fatalf("running reviews %v, want [PROJ/repo/3]", got)
+	}
+
+	if h.CancelReview("PROJ/repo/4") {
+		t.Error("cancelled a review that is not running")
+	}
+	if !h.CancelReview("PROJ/repo/3") {
+		t.Fatal("running review not cancelled")
+	}
+	receive(t, proc.released, "cancelled review")
+	if got := h.RunningReviews(); len(got) != 0 {
+		t.Errorf("running reviews %v after cancellation", got)
+	}
+	if h.CancelReview("PROJ/repo/3") {
+		t.Error("cancelled the same review twice")
+	}
+}
+
+func TestBitbucketWebhookHandler_CancelInFlight(t *testing.T) {
+	h, proc := newCancelTestHandler(t)
+
+	h.SubmitReview(&domain.PullRequest{ID: "1", ProjectKey: "PROJ", RepoSlug: "repo", LatestCommit: "a"})
+	h.SubmitReview(&domain.PullRequest{ID: "2", ProjectKey: "PROJ", RepoSlug: "repo", LatestCommit: "b"})
+	receive(t, proc.started, "first review")
+	receive(t, proc.started, "second review")
+
+	start := time.Now()
+	h.CancelInFlight()
+	done := make(chan struct{})
+	go func() {
+		h.WaitForCompletion()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-time.After(3 * time.Second):
+		t.Fatal("workers not released after cancellation")
+	}
+	if elapsed := time.Since(start); elapsed > 2*time.Second {
+		t.Errorf("cancelled reviews released their workers after %v", elapsed)
+	}
+	if n := h.workerPool.InFlight(); n != 0 {
+		t.Errorf("%d jobs still in flight", n)
+	}
+}
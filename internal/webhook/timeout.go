@@ -0,0 +1,29 @@
+package webhook
+
+import (
+	"time"
+
+	"pr-review-automation/internal/domain"
+)
+
+// ProviderResolver tells which LLM provider serves the review of a PR, as the reviewer routes
+// it (see pipeline.PipelineAdapter)
+type ProviderResolver interface {
+	Provider(pr *domain.PullRequest) string
+}
+
+// SetProviderResolver picks the per-backend review timeout by the provider the reviewer routes
+// each PR to; without it llm.provider is used
+func (h *BitbucketWebhookHandler) SetProviderResolver(r ProviderResolver) {
+	h.providers = r
+}
+
+// reviewTimeout returns how long the review of the PR may run (server.review_timeout), by its
+// project or the LLM provider serving its review
+func (h *BitbucketWebhookHandler) reviewTimeout(pr *domain.PullRequest) time.Duration {
+	provider := h.config.LLM.Provider
+	if h.providers != nil {
+		provider = h.providers.Provider(pr)
+	}
+	return h.config.ReviewTimeoutFor(provider, pr.ProjectKey)
+}
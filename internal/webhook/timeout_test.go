@@ -0,0 +1,68 @@
+package webhook
+
+import (
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/domain"
+)
+
+// repoProviders serves the reviews of the listed repositories with a local model
+type repoProviders map[string]bool
+
+func (r repoProviders) Provider(pr *domain.PullRequest) string {
+	if r[pr.ProjectKey+"/"+pr.RepoSlug] {
+		return config.LLMProviderLocal
+	}
+	return config.LLMProviderOpenAI
+}
+
+func TestReviewTimeout(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.LLM.Provider = config.LLMProviderOpenAI
+	cfg.Server.ReviewTimeout = config.TimeoutConfig{
+		Default:  10 * time.Minute,
+		Backends: map[string]time.Duration{config.LLMProviderLocal: 45 * time.Minute},
+		Projects: map[string]time.Duration{"MONO": 30 * time.Minute},
+	}
+	h := &BitbucketWebhookHandler{config: cfg}
+	if got := h.reviewTimeout(&domain.PullRequest{ProjectKey: "TOOLS", RepoSlug: "cli-go"}); got != 10*time.Minute {
+		t.Errorf("without a resolver: got %v, want llm.provider's 10m", got)
+	}
+
+	h.SetProviderResolver(repoProviders{"TOOLS/cli-go": true, "MONO/app": true})
+	tests := []struct {
+		project, repo string
+		want          time.Duration
+	}{
+		{"PAY", "api", 10 * time.Minute},
+		{"TOOLS", "cli-go", 45 * time.Minute},
+		{"MONO", "app", 30 * time.Minute},
+	}
+	for _, tt := range tests {
+		pr := &domain.PullRequest{ProjectKey: tt.project, RepoSlug: tt.repo}
+		if got := h.reviewTimeout(pr); got != tt.want {
+			t.Errorf("reviewTimeout(%s/%s) = %v, want %v", tt.project, tt.repo, got, tt.want)
+		}
+	}
+}
+
+func TestBitbucketWebhookHandler_TimedOutReviewNotRequeued(t *testing.T) {
+	cfg := &config.Config{}
+	cfg.Server.ConcurrencyLimit = 1
+	cfg.Server.QueueSize = 10
+	cfg.Server.ReviewTimeout.Default = 20 * time.Millisecond
+	proc := &blockingProcessor{started: make(chan string, 4), released: make(chan string, 4)}
+	h := NewBitbucketWebhookHandler(cfg, proc, createTestParser(t, &MockLLM{}))
+	defer h.WaitForCompletion()
+
+	h.SubmitReview(&domain.PullRequest{ID: "7", ProjectKey: "PROJ", RepoSlug: "repo", LatestCommit: "slow"})
+	receive(t, proc.started, "review")
+	receive(t, proc.released, "timed out review")
+	select {
+	case <-proc.started:
+		t.Error("timed out review was requeued")
+	case <-time.After(200 * time.Millisecond):
+	}
+}
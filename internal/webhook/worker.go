@@ -1,25 +1,52 @@
 package webhook
 
 import (
+	"container/heap"
 	"context"
 	"errors"
 	"fmt"
 	"log/slog"
+	"math"
 	"strings"
 	"sync"
+	"sync/atomic"
+	"time"
+
+	"pr-review-automation/internal/config"
+	"pr-review-automation/internal/metrics"
 )
 
+// jobDurationWeight is the weight of the latest job in the average job duration
+const jobDurationWeight = 0.2
+
 // Job represents a task to be executed by a worker
 type Job func(ctx context.Context) error
 
-// WorkerPool manages a pool of workers to execute jobs
+// WorkerPool manages a pool of workers to execute jobs. Waiting jobs run in arrival order or,
+// with priorities, highest priority first.
 type WorkerPool struct {
-	Queue   chan Job
-	Workers int
+	Workers int          // Workers started by Start; with autoscaling the count changes, see WorkerCount
+	active  atomic.Int32 // Jobs being executed
+	running atomic.Int32 // Workers
+	nextID  atomic.Int32
 	wg      sync.WaitGroup
 	quit    chan struct{}
 	ctx     context.Context
 	cancel  context.CancelFunc
+
+	mu        sync.Mutex // Guards starting workers against Stop
+	stopped   bool
+	shrink    chan struct{} // An idle worker receiving from it exits
+	autoscale config.AutoscaleConfig
+	avgJob    time.Duration // Moving average of job duration, guarded by mu
+
+	qmu    sync.Mutex    // Guards queue, seq and closed
+	queue  jobQueue      // Waiting jobs
+	size   int           // Capacity of queue
+	seq    uint64        // Jobs queued so far
+	closed bool          // Set by Stop; no more jobs are queued
+	ready  chan struct{} // One token per waiting job; closed by Stop
+	boost  time.Duration // Head start per priority level, 0 for arrival order
 }
 
 // ErrQueueFull is returned when the job queue is full
@@ -29,20 +56,41 @@ var ErrQueueFull = errors.New("worker pool queue is full")
 func NewWorkerPool(workers, queueSize int) *WorkerPool {
 	ctx, cancel := context.WithCancel(context.Background())
 	return &WorkerPool{
-		Queue:   make(chan Job, queueSize),
+		size:    queueSize,
+		ready:   make(chan struct{}, queueSize),
 		Workers: workers,
 		quit:    make(chan struct{}),
+		shrink:  make(chan struct{}),
 		ctx:     ctx,
 		cancel:  cancel,
 	}
 }
 
+// SetAutoscale lets the pool grow and shrink between the configured bounds; call it before Start.
+// Start then launches min_workers workers instead of Workers.
+func (p *WorkerPool) SetAutoscale(cfg config.AutoscaleConfig) {
+	if !cfg.Enabled {
+		return
+	}
+	p.autoscale = cfg
+	p.Workers = cfg.MinWorkers
+}
+
+// SetPriorities orders waiting jobs by priority instead of arrival; call it before Start
+func (p *WorkerPool) SetPriorities(cfg config.PriorityConfig) {
+	if cfg.Enabled {
+		p.boost = cfg.Boost
+	}
+}
+
 // Start launches the workers
 func (p *WorkerPool) Start() {
-	slog.Info("Starting worker pool", "workers", p.Workers, "queue_size", cap(p.Queue))
+	slog.Info("Starting worker pool", "workers", p.Workers, "queue_size", p.size, "autoscale", p.autoscale.Enabled, "priorities", p.boost > 0)
 	for i := 0; i < p.Workers; i++ {
-		p.wg.Add(1)
-		go p.worker(i)
+		p.addWorker()
+	}
+	if p.autoscale.Enabled {
+		go p.runAutoscaler()
 	}
 }
 
@@ -51,14 +99,20 @@ func (p *WorkerPool) Stop() {
 	slog.Info("Stopping worker pool...")
 
 	// Signal workers to stop accepting new jobs from closed channel
+	p.mu.Lock()
+	p.stopped = true
+	p.mu.Unlock()
 	close(p.quit) // Wait, we can just close Queue if we want to drain.
 	// But usually standard pattern is:
 	// 1. Stop accepting new submissions (caller responsibility usually, or we close a submission channel)
 	// 2. Wait for workers to drain Queue.
 
 	// In this implementation:
-	// We close Queue to signal no more jobs.
-	close(p.Queue)
+	// We close ready to signal no more jobs; workers still run the jobs waiting.
+	p.qmu.Lock()
+	p.closed = true
+	close(p.ready)
+	p.qmu.Unlock()
 
 	// Wait for all workers to finish
 	p.wg.Wait()
@@ -66,58 +120,232 @@ func (p *WorkerPool) Stop() {
 	slog.Info("Worker pool stopped")
 }
 
-// Submit adds a job to the queue. Returns ErrQueueFull if the queue is full.
+// Cancel cancels the context of running and queued jobs so they return promptly, for a
+// shutdown that cannot wait for them. Stop still waits for the workers.
+func (p *WorkerPool) Cancel() {
+	p.cancel()
+}
+
+// Submit adds a job of normal priority to the queue. Returns ErrQueueFull if the queue is full.
 func (p *WorkerPool) Submit(job Job) error {
+	return p.SubmitPriority(job, PriorityNormal)
+}
+
+// SubmitPriority adds a job to the queue. Returns ErrQueueFull if the queue is full or the
+// pool is stopping.
+func (p *WorkerPool) SubmitPriority(job Job, priority Priority) error {
+	now := time.Now()
+	return p.push(&queuedJob{job: job, priority: priority, queuedAt: now, order: now.Add(-time.Duration(priority) * p.boost)})
+}
+
+// push queues a job and hands a token to the workers
+func (p *WorkerPool) push(item *queuedJob) error {
+	p.qmu.Lock()
+	defer p.qmu.Unlock()
+	if p.closed || len(p.queue) >= p.size {
+		return ErrQueueFull
+	}
+	p.seq++
+	item.seq = p.seq
+	heap.Push(&p.queue, item)
+	p.ready <- struct{}{} // Never blocks: there are no more tokens than waiting jobs
+	return nil
+}
+
+// pop takes the first waiting job, after its worker took a token
+func (p *WorkerPool) pop() *queuedJob {
+	p.qmu.Lock()
+	defer p.qmu.Unlock()
+	return heap.Pop(&p.queue).(*queuedJob)
+}
+
+// queued returns the number of jobs waiting
+func (p *WorkerPool) queued() int {
+	p.qmu.Lock()
+	defer p.qmu.Unlock()
+	return len(p.queue)
+}
+
+// Full reports whether the queue has no room for another job
+func (p *WorkerPool) Full() bool {
+	return p.queued() >= p.size
+}
+
+// InFlight returns the number of jobs running or waiting in the queue
+func (p *WorkerPool) InFlight() int {
+	return int(p.active.Load()) + p.queued()
+}
+
+// WorkerCount returns the number of running workers
+func (p *WorkerPool) WorkerCount() int {
+	return int(p.running.Load())
+}
+
+// AvgJobDuration returns the moving average of job duration, 0 before the first job finished
+func (p *WorkerPool) AvgJobDuration() time.Duration {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.avgJob
+}
+
+// addWorker starts one more worker unless the pool is stopping
+func (p *WorkerPool) addWorker() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.stopped {
+		return false
+	}
+	p.wg.Add(1)
+	metrics.WorkerPoolWorkers.Set(float64(p.running.Add(1)))
+	go p.worker(int(p.nextID.Add(1)) - 1)
+	return true
+}
+
+// removeWorker stops one idle worker; it reports false when every worker is busy
+func (p *WorkerPool) removeWorker() bool {
 	select {
-	case p.Queue <- job:
-		return nil
+	case p.shrink <- struct{}{}:
+		metrics.WorkerPoolWorkers.Set(float64(p.running.Add(-1)))
+		return true
 	default:
-		return ErrQueueFull
+		return false
 	}
 }
 
 func (p *WorkerPool) worker(id int) {
 	defer p.wg.Done()
-	for job := range p.Queue {
-		// Prepare a context for the job that is cancelled if the pool stops forceully?
-		// or just pass background?
-		// Usually we want the job to respect the pool's context or a per-request context?
-		// Pr-processor creates its own timeout context.
-
-		func() {
-			defer func() {
-				if r := recover(); r != nil {
-					slog.Error("Panic in worker", "worker_id", id, "panic", r)
-				}
-			}()
-
-			if err := job(p.ctx); err != nil {
-				// Smart Requeue Strategy
-				// If error is timeout and queue has plenty of space (>50% free), requeue it.
-				isTimeout := errors.Is(err, context.DeadlineExceeded) ||
-					(err != nil && (strings.Contains(err.Error(), "timeout") || strings.Contains(err.Error(), "deadline")))
-
-				if isTimeout {
-					cap := float64(cap(p.Queue))
-					len := float64(len(p.Queue))
-					free := cap - len
-
-					if free > cap*0.5 {
-						slog.Warn("Job timed out, requeuing due to healthy system load", "worker_id", id, "queue_usage", fmt.Sprintf("%.1f%%", (len/cap)*100))
-
-						// Non-blocking requeue attempt
-						select {
-						case p.Queue <- job:
-							return // Successfully requeued, skip error logging
-						default:
-							// Should not happen given the check, but race conditions exist
-							slog.Warn("Failed to requeue timed out job: queue became full")
-						}
-					}
-				}
+	for {
+		select {
+		case _, ok := <-p.ready:
+			if !ok {
+				metrics.WorkerPoolWorkers.Set(float64(p.running.Add(-1)))
+				return
+			}
+			p.run(id, p.pop())
+		case <-p.shrink: // Counted out by removeWorker
+			return
+		}
+	}
+}
 
-				slog.Error("Job execution failed", "worker_id", id, "error", err)
+// run executes one job, requeuing it when it timed out while the queue is mostly free
+func (p *WorkerPool) run(id int, item *queuedJob) {
+	job := item.job
+	metrics.WorkerPoolWait.WithLabelValues(item.priority.String()).Observe(time.Since(item.queuedAt).Seconds())
+	p.active.Add(1)
+	// Prepare a context for the job that is cancelled if the pool stops forceully?
+	// or just pass background?
+	// Usually we want the job to respect the pool's context or a per-request context?
+	// Pr-processor creates its own timeout context.
+
+	start := time.Now()
+	func() {
+		defer p.active.Add(-1)
+		defer func() {
+			if r := recover(); r != nil {
+				slog.Error("Panic in worker", "worker_id", id, "panic", r)
 			}
 		}()
+
+		if err := job(p.ctx); err != nil {
+			// Smart Requeue Strategy
+			// If error is timeout and queue has plenty of space (>50% free), requeue it.
+			isTimeout := errors.Is(err, context.DeadlineExceeded) ||
+				(err != nil && (strings.Contains(err.Error(), "timeout") || strings.Contains(err.Error(), "deadline")))
+
+			// Cancelled jobs are not requeued: the pool is shutting down
+			if isTimeout && p.ctx.Err() == nil {
+				cap := float64(p.size)
+				len := float64(p.queued())
+				free := cap - len
+
+				if free > cap*0.5 {
+					slog.Warn("Job timed out, requeuing due to healthy system load", "worker_id", id, "queue_usage", fmt.Sprintf("%.1f%%", (len/cap)*100))
+
+					// Non-blocking requeue attempt, keeping the job's priority
+					if p.SubmitPriority(job, item.priority) == nil {
+						return // Successfully requeued, skip error logging
+					}
+					// Should not happen given the check, but race conditions exist
+					slog.Warn("Failed to requeue timed out job: queue became full")
+				}
+			}
+
+			slog.Error("Job execution failed", "worker_id", id, "error", err)
+		}
+	}()
+	p.recordJob(time.Since(start))
+}
+
+// recordJob adds a finished job to the average job duration
+func (p *WorkerPool) recordJob(d time.Duration) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	if p.avgJob == 0 {
+		p.avgJob = d
+		return
+	}
+	p.avgJob = time.Duration(jobDurationWeight*float64(d) + (1-jobDurationWeight)*float64(p.avgJob))
+}
+
+// runAutoscaler adjusts the worker count every interval until the pool stops
+func (p *WorkerPool) runAutoscaler() {
+	ticker := time.NewTicker(p.autoscale.Interval)
+	defer ticker.Stop()
+	lastBusy := time.Now()
+	for {
+		select {
+		case <-p.quit:
+			return
+		case now := <-ticker.C:
+			lastBusy = p.scale(now, lastBusy)
+		}
+	}
+}
+
+// scale grows the pool at once to the workers the queue needs, and shrinks it by one idle worker
+// per interval once the queue has been empty for scale_down_delay. It returns when the queue was
+// last seen non-empty.
+func (p *WorkerPool) scale(now, lastBusy time.Time) time.Time {
+	queued := p.queued()
+	metrics.WorkerPoolQueueDepth.Set(float64(queued))
+	if queued > 0 {
+		lastBusy = now
+	}
+	current := p.WorkerCount()
+	avg := p.AvgJobDuration()
+	want := desiredWorkers(p.autoscale, int(p.active.Load()), queued, avg)
+
+	switch {
+	case want > current:
+		added := 0
+		for added < want-current && p.addWorker() {
+			added++
+		}
+		if added > 0 {
+			metrics.WorkerPoolScaling.WithLabelValues("up").Inc()
+			slog.Info("worker pool scaled up", "workers", current+added, "queued", queued, "avg_job", avg)
+		}
+	case want < current && now.Sub(lastBusy) >= p.autoscale.ScaleDownDelay:
+		if p.removeWorker() {
+			metrics.WorkerPoolScaling.WithLabelValues("down").Inc()
+			slog.Info("worker pool scaled down", "workers", current-1)
+		}
+	}
+	return lastBusy
+}
+
+// desiredWorkers returns the workers needed for the running jobs and for the queued jobs to
+// start within target_wait, given the average job duration, bounded by min and max workers.
+// An unknown duration asks for one worker per queued job.
+func desiredWorkers(cfg config.AutoscaleConfig, active, queued int, avgJob time.Duration) int {
+	want := active
+	if queued > 0 {
+		need := active + queued
+		if avgJob > 0 {
+			need = min(need, int(math.Ceil(float64(queued)*float64(avgJob)/float64(cfg.TargetWait))))
+		}
+		want = max(want, need)
 	}
+	return max(cfg.MinWorkers, min(want, cfg.MaxWorkers))
 }
@@ -0,0 +1,100 @@
+package webhook
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"pr-review-automation/internal/config"
+)
+
+func TestDesiredWorkers(t *testing.T) {
+	cfg := config.AutoscaleConfig{Enabled: true, MinWorkers: 2, MaxWorkers: 10, TargetWait: time.Minute}
+
+	tests := []struct {
+		name           string
+		active, queued int
+		avgJob         time.Duration
+		want           int
+	}{
+		{"idle keeps min", 0, 0, time.Minute, 2},
+		{"busy without queue keeps active", 5, 0, time.Minute, 5},
+		{"short jobs drain the queue", 3, 4, 10 * time.Second, 3},
+		{"long jobs add workers", 3, 4, 2 * time.Minute, 7},
+		{"no more workers than jobs", 1, 2, 10 * time.Minute, 3},
+		{"unknown duration adds one per job", 1, 3, 0, 4},
+		{"bounded by max", 8, 50, 5 * time.Minute, 10},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := desiredWorkers(cfg, tt.active, tt.queued, tt.avgJob); got != tt.want {
+				t.Errorf("desiredWorkers(%d, %d, %v) = %d, want %d", tt.active, tt.queued, tt.avgJob, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestWorkerPool_Autoscale(t *testing.T) {
+	p := NewWorkerPool(10, 20)
+	p.SetAutoscale(config.AutoscaleConfig{
+		Enabled: true, MinWorkers: 1, MaxWorkers: 4,
+		Interval: time.Hour, TargetWait: time.Minute, ScaleDownDelay: time.Minute,
+	})
+	p.Start()
+	defer p.Stop()
+
+	if got := p.WorkerCount(); got != 1 {
+		t.Fatalf("started %d workers, want min_workers 1", got)
+	}
+
+	release := make(chan struct{})
+	for i := 0; i < 6; i++ {
+		if err := p.Submit(func(ctx context.Context) error {
+			<-release
+			return nil
+		}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	waitFor(t, func() bool { return p.active.Load() == 1 })
+
+	now := time.Now()
+	lastBusy := p.scale(now, now)
+	if got := p.WorkerCount(); got != 4 {
+		t.Fatalf("after scale up: %d workers, want max_workers 4", got)
+	}
+	waitFor(t, func() bool { return p.active.Load() == 4 })
+
+	close(release)
+	waitFor(t, func() bool { return p.InFlight() == 0 })
+
+	// The queue emptied just now: idle workers stay until scale_down_delay has passed
+	lastBusy = p.scale(now.Add(time.Second), lastBusy)
+	if got := p.WorkerCount(); got != 4 {
+		t.Fatalf("scaled down before scale_down_delay: %d workers", got)
+	}
+	for want := 3; want >= 1; want-- {
+		waitFor(t, func() bool {
+			lastBusy = p.scale(now.Add(2*time.Minute), lastBusy)
+			return p.WorkerCount() == want
+		})
+	}
+	p.scale(now.Add(2*time.Minute), lastBusy)
+	if got := p.WorkerCount(); got != 1 {
+		t.Errorf("after scale down: %d workers, want min_workers 1", got)
+	}
+	if p.AvgJobDuration() <= 0 {
+		t.Error("expected job durations to be recorded")
+	}
+}
+
+func waitFor(t *testing.T, cond func() bool) {
+	t.Helper()
+	deadline := time.Now().Add(2 * time.Second)
+	for !cond() {
+		if time.Now().After(deadline) {
+			t.Fatal("condition not met in time")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+}